@@ -0,0 +1,389 @@
+// Package asm 是一个类似Jasmin的汇编器，把.j文本汇编成class文件，
+// 用来在没有javac的环境下构造测试用的类
+//
+// 源文件的格式：
+//
+//	.bytecode 52.0
+//	.source Hello.java
+//	.class public Hello
+//	.super java/lang/Object
+//
+//	.field private static count I = 0
+//
+//	.method public static main([Ljava/lang/String;)V
+//	    .limit stack 2
+//	    getstatic java/lang/System/out Ljava/io/PrintStream;
+//	    ldc "Hello, world"
+//	    invokevirtual java/io/PrintStream/println(Ljava/lang/String;)V
+//	    return
+//	.end method
+//
+// 省略.limit stack/locals时自动计算；Options.ComputeFrames为true时自动生成StackMapTable
+package asm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// Options 汇编选项
+type Options struct {
+	// ComputeFrames 自动计算StackMapTable，50及以上版本的class需要
+	ComputeFrames bool
+	// CommonSuperClass 计算两个类的最近公共超类，合并栈帧时用；
+	// 为nil时除了两个类相同的情况都当作java/lang/Object
+	CommonSuperClass func(class1, class2 string) string
+}
+
+// Error 汇编错误，带有文件名和行号
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Assemble 汇编一个.j源文件，返回类名（内部形式，如java/lang/Object）和class文件数据
+func Assemble(fileName string, source []byte, options Options) (className string, classData []byte, err error) {
+	cf, err := AssembleClassFile(fileName, source, options)
+	if err != nil {
+		return "", nil, err
+	}
+	classData, err = cf.Bytes()
+	if err != nil {
+		return "", nil, &Error{fileName, 0, err.Error()}
+	}
+	return cf.ClassName(), classData, nil
+}
+
+// AssembleClassFile 和Assemble一样，但返回ClassFile，方便进一步修改
+func AssembleClassFile(fileName string, source []byte, options Options) (cf *classfile.ClassFile, err error) {
+	assembler := &assembler{
+		fileName: fileName,
+		options:  options,
+		minor:    3,
+		major:    45,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if asmError, ok := r.(*Error); ok {
+				cf, err = nil, asmError
+				return
+			}
+			panic(r)
+		}
+	}()
+	assembler.assemble(string(source))
+	return assembler.cf, nil
+}
+
+type assembler struct {
+	fileName string
+	options  Options
+	line     int
+
+	minor, major uint16
+	cf           *classfile.ClassFile
+	cp           *classfile.ConstantPool
+	className    string
+	accessFlags  uint16
+	superName    string
+	hasSuper     bool
+	interfaces   []uint16
+	sourceFile   string
+	fields       []*classfile.MemberInfo
+	methods      []*classfile.MemberInfo
+	attributes   []classfile.AttributeInfo
+	bootstrap    *classfile.BootstrapMethodsAttribute
+
+	method *methodBuilder // 正在汇编的方法
+}
+
+func (assembler *assembler) fail(format string, args ...interface{}) {
+	panic(&Error{assembler.fileName, assembler.line, fmt.Sprintf(format, args...)})
+}
+
+func (assembler *assembler) assemble(source string) {
+	// 版本号要在建ClassFile之前确定，.bytecode只能出现在.class之前
+	for i, line := range strings.Split(source, "\n") {
+		assembler.line = i + 1
+		tokens, err := splitLine(line)
+		if err != nil {
+			assembler.fail("%v", err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if assembler.method != nil {
+			assembler.method.statement(tokens)
+		} else {
+			assembler.directive(tokens)
+		}
+	}
+	assembler.line++
+	if assembler.method != nil {
+		assembler.fail("missing .end method")
+	}
+	if assembler.cf == nil {
+		assembler.fail("missing .class or .interface")
+	}
+	assembler.finish()
+}
+
+// 方法体之外的指令
+func (assembler *assembler) directive(tokens []token) {
+	args := tokens[1:]
+	if assembler.cf == nil {
+		switch tokens[0].text {
+		case ".bytecode":
+			assembler.expectArgs(args, 1, 1)
+			assembler.minor, assembler.major = assembler.parseVersion(args[0].text)
+			return
+		case ".source":
+			assembler.expectArgs(args, 1, 1)
+			assembler.sourceFile = args[0].text
+			return
+		case ".class", ".interface":
+			assembler.classDirective(tokens[0].text, args)
+			return
+		}
+		assembler.fail("%s before .class", tokens[0].text)
+	}
+
+	switch tokens[0].text {
+	case ".source":
+		assembler.expectArgs(args, 1, 1)
+		assembler.sourceFile = args[0].text
+	case ".super":
+		assembler.expectArgs(args, 1, 1)
+		if assembler.hasSuper {
+			assembler.fail("duplicate .super")
+		}
+		assembler.superName, assembler.hasSuper = args[0].text, true
+	case ".implements":
+		assembler.expectArgs(args, 1, 1)
+		assembler.interfaces = append(assembler.interfaces, assembler.cp.AddClass(args[0].text))
+	case ".signature":
+		assembler.expectArgs(args, 1, 1)
+		assembler.attributes = append(assembler.attributes, classfile.NewSignatureAttribute(assembler.cp, args[0].text))
+	case ".deprecated":
+		assembler.expectArgs(args, 0, 0)
+		assembler.attributes = append(assembler.attributes, classfile.NewDeprecatedAttribute())
+	case ".field":
+		assembler.fieldDirective(args)
+	case ".method":
+		assembler.methodDirective(args)
+	case ".bytecode", ".class", ".interface":
+		assembler.fail("%s must come before any other class content", tokens[0].text)
+	default:
+		assembler.fail("unknown directive %s", tokens[0].text)
+	}
+}
+
+func (assembler *assembler) expectArgs(args []token, min, max int) {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			assembler.fail("expected %d operand(s), got %d", min, len(args))
+		}
+		assembler.fail("expected %d to %d operands, got %d", min, max, len(args))
+	}
+}
+
+func (assembler *assembler) parseVersion(s string) (uint16, uint16) {
+	majorStr, minorStr := s, "0"
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		majorStr, minorStr = s[:i], s[i+1:]
+	}
+	major, err1 := strconv.ParseUint(majorStr, 10, 16)
+	minor, err2 := strconv.ParseUint(minorStr, 10, 16)
+	if err1 != nil || err2 != nil {
+		assembler.fail("invalid class file version %q", s)
+	}
+	return uint16(minor), uint16(major)
+}
+
+var accessFlagNames = map[string]uint16{
+	"public":       classfile.ACC_PUBLIC,
+	"private":      classfile.ACC_PRIVATE,
+	"protected":    classfile.ACC_PROTECTED,
+	"static":       classfile.ACC_STATIC,
+	"final":        classfile.ACC_FINAL,
+	"super":        classfile.ACC_SUPER,
+	"synchronized": classfile.ACC_SYNCHRONIZED,
+	"volatile":     classfile.ACC_VOLATILE,
+	"bridge":       classfile.ACC_BRIDGE,
+	"transient":    classfile.ACC_TRANSIENT,
+	"varargs":      classfile.ACC_VARARGS,
+	"native":       classfile.ACC_NATIVE,
+	"interface":    classfile.ACC_INTERFACE,
+	"abstract":     classfile.ACC_ABSTRACT,
+	"strict":       classfile.ACC_STRICT,
+	"synthetic":    classfile.ACC_SYNTHETIC,
+	"annotation":   classfile.ACC_ANNOTATION,
+	"enum":         classfile.ACC_ENUM,
+}
+
+// parseAccessFlags 读开头的访问标志，至少留下keep个词给名字和描述符
+func (assembler *assembler) parseAccessFlags(args []token, keep int) (uint16, []token) {
+	var flags uint16
+	for len(args) > keep {
+		flag, ok := accessFlagNames[args[0].text]
+		if !ok || args[0].quoted {
+			break
+		}
+		flags |= flag
+		args = args[1:]
+	}
+	return flags, args
+}
+
+// .class [access] name
+func (assembler *assembler) classDirective(directive string, args []token) {
+	flags, args := assembler.parseAccessFlags(args, 1)
+	assembler.expectArgs(args, 1, 1)
+	if directive == ".interface" {
+		flags |= classfile.ACC_INTERFACE | classfile.ACC_ABSTRACT
+	} else if flags&classfile.ACC_INTERFACE == 0 {
+		// 和javac一样，类默认带ACC_SUPER
+		flags |= classfile.ACC_SUPER
+	}
+	assembler.cf = classfile.NewClassFile(assembler.minor, assembler.major)
+	assembler.cp = assembler.cf.ConstantPool()
+	assembler.className = args[0].text
+	assembler.accessFlags = flags
+}
+
+// .field access name descriptor [signature "sig"] [= value]
+func (assembler *assembler) fieldDirective(args []token) {
+	flags, args := assembler.parseAccessFlags(args, 2)
+	if len(args) < 2 {
+		assembler.fail("expected field name and descriptor")
+	}
+	name, descriptor := args[0].text, args[1].text
+	field := classfile.NewMemberInfo(assembler.cp, flags, name, descriptor)
+	var attributes []classfile.AttributeInfo
+	args = args[2:]
+	if len(args) >= 2 && args[0].text == "signature" {
+		attributes = append(attributes, classfile.NewSignatureAttribute(assembler.cp, args[1].text))
+		args = args[2:]
+	}
+	if len(args) > 0 {
+		if args[0].text != "=" || len(args) != 2 {
+			assembler.fail("expected '= value' after field descriptor")
+		}
+		index := assembler.fieldConstant(descriptor, args[1])
+		attributes = append(attributes, classfile.NewConstantValueAttribute(index))
+	}
+	field.SetAttributes(attributes)
+	assembler.fields = append(assembler.fields, field)
+}
+
+// ConstantValue的常量类型由字段类型决定
+func (assembler *assembler) fieldConstant(descriptor string, value token) uint16 {
+	switch descriptor {
+	case "I", "S", "B", "C", "Z":
+		return assembler.cp.AddInteger(assembler.parseInt32(value.text))
+	case "J":
+		return assembler.cp.AddLong(assembler.parseInt64(value.text))
+	case "F":
+		return assembler.cp.AddFloat(float32(assembler.parseFloat(value.text, 32)))
+	case "D":
+		return assembler.cp.AddDouble(assembler.parseFloat(value.text, 64))
+	case "Ljava/lang/String;":
+		if !value.quoted {
+			assembler.fail("expected a string constant")
+		}
+		return assembler.cp.AddString(value.text)
+	}
+	assembler.fail("field of type %s cannot have a constant value", descriptor)
+	return 0
+}
+
+// .method access name(descriptor)
+func (assembler *assembler) methodDirective(args []token) {
+	flags, args := assembler.parseAccessFlags(args, 1)
+	assembler.expectArgs(args, 1, 1)
+	nameAndDescriptor := args[0].text
+	i := strings.IndexByte(nameAndDescriptor, '(')
+	if i <= 0 {
+		assembler.fail("expected method name and descriptor, got %q", nameAndDescriptor)
+	}
+	assembler.method = newMethodBuilder(assembler, flags, nameAndDescriptor[:i], nameAndDescriptor[i:])
+}
+
+// 方法结束，把方法加到类里
+func (assembler *assembler) endMethod(method *classfile.MemberInfo) {
+	assembler.methods = append(assembler.methods, method)
+	assembler.method = nil
+}
+
+// addBootstrapMethod 加一个引导方法，返回它在BootstrapMethods属性中的下标
+func (assembler *assembler) addBootstrapMethod(methodHandle uint16, arguments []uint16) uint16 {
+	if assembler.bootstrap == nil {
+		assembler.bootstrap = classfile.NewBootstrapMethodsAttribute(nil)
+		assembler.attributes = append(assembler.attributes, assembler.bootstrap)
+	}
+	return assembler.bootstrap.AddBootstrapMethod(methodHandle, arguments)
+}
+
+func (assembler *assembler) finish() {
+	cf := assembler.cf
+	cf.SetAccessFlags(assembler.accessFlags)
+	cf.SetThisClass(assembler.cp.AddClass(assembler.className))
+	switch {
+	case assembler.hasSuper:
+		cf.SetSuperClass(assembler.cp.AddClass(assembler.superName))
+	case assembler.className != "java/lang/Object":
+		cf.SetSuperClass(assembler.cp.AddClass("java/lang/Object"))
+	}
+	cf.SetInterfaces(assembler.interfaces)
+	cf.SetFields(assembler.fields)
+	cf.SetMethods(assembler.methods)
+
+	sourceFile := assembler.sourceFile
+	if sourceFile == "" && assembler.fileName != "" {
+		sourceFile = filepath.Base(assembler.fileName)
+	}
+	attributes := assembler.attributes
+	if sourceFile != "" {
+		attributes = append([]classfile.AttributeInfo{classfile.NewSourceFileAttribute(assembler.cp, sourceFile)}, attributes...)
+	}
+	cf.SetAttributes(attributes)
+}
+
+func (assembler *assembler) parseInt32(s string) int32 {
+	val, err := strconv.ParseInt(s, 0, 32)
+	if err != nil {
+		assembler.fail("invalid int constant %q", s)
+	}
+	return int32(val)
+}
+
+func (assembler *assembler) parseInt64(s string) int64 {
+	val, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSuffix(s, "L"), "l"), 0, 64)
+	if err != nil {
+		assembler.fail("invalid long constant %q", s)
+	}
+	return val
+}
+
+func (assembler *assembler) parseFloat(s string, bitSize int) float64 {
+	// 去掉Java的f/d后缀；十六进制浮点数和Infinity等的末尾字母不是后缀
+	trimmed := s
+	if n := len(s); n > 1 && strings.IndexByte("fFdD", s[n-1]) >= 0 && strings.IndexByte("0123456789.", s[n-2]) >= 0 &&
+		!strings.Contains(s, "0x") && !strings.Contains(s, "0X") {
+		trimmed = s[:n-1]
+	}
+	val, err := strconv.ParseFloat(trimmed, bitSize)
+	if err != nil {
+		assembler.fail("invalid floating-point constant %q", s)
+	}
+	return val
+}
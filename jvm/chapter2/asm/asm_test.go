@@ -0,0 +1,100 @@
+package asm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+const maxSource = `.bytecode 52.0
+.source Max.java
+.class public final Max
+.super java/lang/Object
+
+.field public static final LIMIT I = 100
+
+; 省略.limit，由汇编器计算
+.method public static max(II)I
+    iload_0
+    iload_1
+    if_icmpge First
+    iload_1
+    ireturn
+First:
+    iload_0
+    ireturn
+.end method
+`
+
+func TestAssemble(t *testing.T) {
+	className, data, err := Assemble("Max.j", []byte(maxSource), Options{ComputeFrames: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if className != "Max" {
+		t.Errorf("className = %q", className)
+	}
+	cf, err := classfile.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cf.MajorVersion() != 52 || cf.AccessFlags() != classfile.ACC_PUBLIC|classfile.ACC_FINAL|classfile.ACC_SUPER {
+		t.Errorf("version %d, flags %#x", cf.MajorVersion(), cf.AccessFlags())
+	}
+	if cf.SuperClassName() != "java/lang/Object" || cf.SourceFileAttribute().FileName() != "Max.java" {
+		t.Errorf("super %q, source %q", cf.SuperClassName(), cf.SourceFileAttribute().FileName())
+	}
+
+	field := cf.Fields()[0]
+	constant := field.ConstantValueAttribute()
+	if field.Name() != "LIMIT" || field.Descriptor() != "I" || constant == nil {
+		t.Fatalf("field %s %s, ConstantValue %v", field.Name(), field.Descriptor(), constant)
+	}
+	if v := cf.ConstantPool().GetConstantInfo(constant.ConstantValueIndex()).(*classfile.ConstantIntegerInfo).Value(); v != 100 {
+		t.Errorf("LIMIT = %d", v)
+	}
+
+	code := cf.Methods()[0].CodeAttribute()
+	want := []byte{0x1A, 0x1B, 0xA2, 0x00, 0x05, 0x1B, 0xAC, 0x1A, 0xAC}
+	if !bytes.Equal(code.Code(), want) {
+		t.Errorf("code = % X, want % X", code.Code(), want)
+	}
+	if code.MaxStack() != 2 || code.MaxLocals() != 2 {
+		t.Errorf("max stack %d, max locals %d", code.MaxStack(), code.MaxLocals())
+	}
+	// 分支目标First处有一个帧
+	if frames := code.StackMapTableAttribute(); frames == nil || len(frames.Entries()) != 1 {
+		t.Errorf("StackMapTable = %v", frames)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		options Options
+		line    int
+		msg     string
+	}{
+		{".super java/lang/Object\n", Options{}, 1, ".super before .class"},
+		{".class A\n.super java/lang/Object\n.super java/lang/Object\n", Options{}, 3, "duplicate .super"},
+		{".class A\n.field x I = 1.5\n", Options{}, 2, `invalid int constant "1.5"`},
+		{".class A\n.method m()V\n    frob\n.end method\n", Options{}, 3, "unknown instruction frob"},
+		// 计算StackMapTable时才检查返回指令和返回类型是否匹配
+		{".class A\n.method m()I\n    return\n.end method\n", Options{ComputeFrames: true}, 3, "return in a method returning I"},
+		{".class A\n.method m()V\n    pop\n    return\n.end method\n", Options{}, 3, "stack underflow in pop at 0"},
+		{".class A\n.method m()V\n    return\n", Options{}, 5, "missing .end method"},
+	}
+	for _, test := range tests {
+		_, _, err := Assemble("A.j", []byte(test.source), test.options)
+		asmError, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Assemble(%q) error = %v, want *Error", test.source, err)
+			continue
+		}
+		if asmError.File != "A.j" || asmError.Line != test.line || !strings.Contains(asmError.Msg, test.msg) {
+			t.Errorf("Assemble(%q) = %v, want A.j:%d: %s", test.source, err, test.line, test.msg)
+		}
+	}
+}
@@ -0,0 +1,929 @@
+package asm
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// vtype 数据流分析中的类型，对应JVMS 4.10.1.2的验证类型
+// long和double占两个slot，第二个slot是Top
+type vtype struct {
+	tag    uint8  // classfile.ITEM_*
+	class  string // ITEM_Object的类名，数组是描述符
+	offset int    // ITEM_Uninitialized的new指令地址
+}
+
+var (
+	topType     = vtype{tag: classfile.ITEM_Top}
+	intType     = vtype{tag: classfile.ITEM_Integer}
+	floatType   = vtype{tag: classfile.ITEM_Float}
+	longType    = vtype{tag: classfile.ITEM_Long}
+	doubleType  = vtype{tag: classfile.ITEM_Double}
+	nullType    = vtype{tag: classfile.ITEM_Null}
+	uninitThis  = vtype{tag: classfile.ITEM_UninitializedThis}
+	objectClass = "java/lang/Object"
+)
+
+func objectType(class string) vtype {
+	return vtype{tag: classfile.ITEM_Object, class: class}
+}
+
+func (t vtype) isWide() bool {
+	return t.tag == classfile.ITEM_Long || t.tag == classfile.ITEM_Double
+}
+
+func (t vtype) isReference() bool {
+	switch t.tag {
+	case classfile.ITEM_Null, classfile.ITEM_Object, classfile.ITEM_UninitializedThis, classfile.ITEM_Uninitialized:
+		return true
+	}
+	return false
+}
+
+// 字段描述符对应的类型，V返回空
+func descriptorTypes(descriptor string) []vtype {
+	switch descriptor[0] {
+	case 'V':
+		return nil
+	case 'B', 'C', 'S', 'Z', 'I':
+		return []vtype{intType}
+	case 'F':
+		return []vtype{floatType}
+	case 'J':
+		return []vtype{longType, topType}
+	case 'D':
+		return []vtype{doubleType, topType}
+	case 'L':
+		return []vtype{objectType(descriptor[1 : len(descriptor)-1])}
+	}
+	return []vtype{objectType(descriptor)}
+}
+
+// 类名或数组描述符转成数组元素的描述符
+func elementDescriptor(class string) string {
+	if strings.HasPrefix(class, "[") {
+		return class
+	}
+	return "L" + class + ";"
+}
+
+type frame struct {
+	locals []vtype
+	stack  []vtype
+}
+
+func (f *frame) copy() *frame {
+	return &frame{
+		locals: append([]vtype(nil), f.locals...),
+		stack:  append([]vtype(nil), f.stack...),
+	}
+}
+
+type analysisResult struct {
+	maxStack, maxLocals int
+	code                []byte
+	exceptionTable      []*classfile.ExceptionTableEntry
+	frames              []*classfile.StackMapFrame
+}
+
+// analyzer 按指令做类型推导，计算max_stack、max_locals和每个基本块入口的栈帧
+type analyzer struct {
+	methodBuilder *methodBuilder
+	strict        bool // 计算StackMapTable时类型必须正确，否则只关心栈深度
+	frames        []*frame
+	needsFrame    []bool
+	byPC          map[int]int // pc -> 指令下标
+	newClasses    map[int]string
+	handlers      []*catchEntry
+	worklist      []int
+	maxStack      int
+	maxLocals     int
+}
+
+// analyze 分析已经编码好的方法
+func (methodBuilder *methodBuilder) analyze(code []byte, exceptionTable []*classfile.ExceptionTableEntry) analysisResult {
+	instrs := methodBuilder.instrs
+	a := &analyzer{
+		methodBuilder: methodBuilder,
+		strict:        methodBuilder.assembler.options.ComputeFrames,
+		frames:        make([]*frame, len(instrs)),
+		needsFrame:    make([]bool, len(instrs)),
+		byPC:          make(map[int]int, len(instrs)),
+		newClasses:    map[int]string{},
+		handlers:      methodBuilder.catches,
+		maxLocals:     methodBuilder.argSlots,
+	}
+	for i, in := range instrs {
+		a.byPC[in.pc] = i
+		if in.opcode == opcodes.New {
+			a.newClasses[in.pc] = in.owner
+		}
+	}
+	for _, c := range a.handlers {
+		_, startOK := a.byPC[c.start.pc]
+		_, endOK := a.byPC[c.end.pc]
+		_, handlerOK := a.byPC[c.handler.pc]
+		if !startOK || !endOK && c.end.pc != len(code) || !handlerOK {
+			methodBuilder.assembler.line = c.line
+			methodBuilder.fail(".catch labels must be at instructions")
+		}
+	}
+	// max_locals要覆盖所有指令用到的局部变量，包括执行不到的代码
+	for _, in := range instrs {
+		a.maxLocals = max(a.maxLocals, localsUsed(in))
+	}
+
+	initial := a.initialFrame()
+	a.merge(0, initial, false)
+	for len(a.worklist) > 0 {
+		i := a.worklist[len(a.worklist)-1]
+		a.worklist = a.worklist[:len(a.worklist)-1]
+		a.execute(i)
+	}
+
+	result := analysisResult{maxStack: a.maxStack, maxLocals: a.maxLocals, code: code, exceptionTable: exceptionTable}
+	if a.strict {
+		result.code, result.exceptionTable = a.removeDeadCode(code, exceptionTable)
+		result.frames = a.encodeFrames(initial)
+	}
+	return result
+}
+
+func (a *analyzer) fail(in *instr, format string, args ...interface{}) {
+	a.methodBuilder.assembler.line = in.line
+	a.methodBuilder.fail(format, args...)
+}
+
+func (a *analyzer) initialFrame() *frame {
+	methodBuilder := a.methodBuilder
+	f := &frame{}
+	if methodBuilder.accessFlags&classfile.ACC_STATIC == 0 {
+		className := methodBuilder.assembler.className
+		if methodBuilder.name == "<init>" && className != objectClass {
+			f.locals = append(f.locals, uninitThis)
+		} else {
+			f.locals = append(f.locals, objectType(className))
+		}
+	}
+	md, _ := signature.ParseMethodDescriptor(methodBuilder.descriptor)
+	for _, param := range md.Params {
+		f.locals = append(f.locals, descriptorTypes(param.Signature())...)
+	}
+	return f
+}
+
+// merge 把in合并到第i条指令的入口帧，有变化时放进工作表
+func (a *analyzer) merge(i int, in *frame, isTarget bool) {
+	if isTarget {
+		a.needsFrame[i] = true
+	}
+	instr := a.methodBuilder.instrs[i]
+	old := a.frames[i]
+	if old == nil {
+		a.frames[i] = in.copy()
+		a.worklist = append(a.worklist, i)
+		return
+	}
+	if len(old.stack) != len(in.stack) {
+		a.fail(instr, "inconsistent stack height at %d: %d != %d", instr.pc, len(old.stack), len(in.stack))
+	}
+	changed := false
+	for j := range old.stack {
+		t := a.mergeType(old.stack[j], in.stack[j])
+		if t.tag == classfile.ITEM_Top && old.stack[j] != in.stack[j] && a.strict {
+			a.fail(instr, "incompatible stack types at %d", instr.pc)
+		}
+		if t != old.stack[j] {
+			old.stack[j], changed = t, true
+		}
+	}
+	for j := range old.locals {
+		t := topType
+		if j < len(in.locals) {
+			t = a.mergeType(old.locals[j], in.locals[j])
+		}
+		if t != old.locals[j] {
+			old.locals[j], changed = t, true
+		}
+	}
+	// 合并后long/double的一半变成Top时另一半也不能用
+	for j, t := range old.locals {
+		if t.isWide() && (j+1 >= len(old.locals) || old.locals[j+1] != topType) {
+			old.locals[j], changed = topType, true
+		}
+	}
+	if changed {
+		a.worklist = append(a.worklist, i)
+	}
+}
+
+func (a *analyzer) mergeType(t1, t2 vtype) vtype {
+	switch {
+	case t1 == t2:
+		return t1
+	case t1.tag == classfile.ITEM_Null && t2.tag == classfile.ITEM_Object:
+		return t2
+	case t2.tag == classfile.ITEM_Null && t1.tag == classfile.ITEM_Object:
+		return t1
+	case t1.tag == classfile.ITEM_Object && t2.tag == classfile.ITEM_Object:
+		return objectType(a.commonSuperClass(t1.class, t2.class))
+	}
+	return topType
+}
+
+func (a *analyzer) commonSuperClass(class1, class2 string) string {
+	array1, array2 := strings.HasPrefix(class1, "["), strings.HasPrefix(class2, "[")
+	if array1 && array2 {
+		// 引用类型的数组按元素合并，基本类型的数组只能合并成Object
+		e1, e2 := class1[1:], class2[1:]
+		if (e1[0] == 'L' || e1[0] == '[') && (e2[0] == 'L' || e2[0] == '[') {
+			t := a.mergeType(descriptorTypes(e1)[0], descriptorTypes(e2)[0])
+			return "[" + elementDescriptor(t.class)
+		}
+		return objectClass
+	}
+	if array1 || array2 {
+		return objectClass
+	}
+	if commonSuperClass := a.methodBuilder.assembler.options.CommonSuperClass; commonSuperClass != nil {
+		return commonSuperClass(class1, class2)
+	}
+	return objectClass
+}
+
+// execute 模拟执行第i条指令，把结果合并到所有后继
+func (a *analyzer) execute(i int) {
+	in := a.methodBuilder.instrs[i]
+	before := a.frames[i]
+	f := before.copy()
+	s := &state{analyzer: a, in: in, frame: f}
+	s.run()
+
+	// 异常处理器的入口帧：局部变量来自指令执行前后，栈上只有异常对象
+	for _, c := range a.handlers {
+		if in.pc < c.start.pc || in.pc >= c.end.pc {
+			continue
+		}
+		exception := objectType("java/lang/Throwable")
+		if c.className != "" {
+			exception = objectType(c.className)
+		}
+		handler := a.byPC[c.handler.pc]
+		a.merge(handler, &frame{locals: before.locals, stack: []vtype{exception}}, true)
+		a.merge(handler, &frame{locals: f.locals, stack: []vtype{exception}}, true)
+	}
+
+	if len(f.stack) > a.maxStack {
+		a.maxStack = len(f.stack)
+	}
+	if s.maxStack > a.maxStack {
+		a.maxStack = s.maxStack
+	}
+	if len(f.locals) > a.maxLocals {
+		a.maxLocals = len(f.locals)
+	}
+
+	for _, target := range s.targets {
+		a.merge(a.byPC[target.pc], f, true)
+	}
+	if s.fallThrough {
+		if i+1 >= len(a.methodBuilder.instrs) {
+			a.fail(in, "execution falls off the end of the code")
+		}
+		a.merge(i+1, f, false)
+	}
+}
+
+// state 执行一条指令时的操作数栈和局部变量
+type state struct {
+	analyzer    *analyzer
+	in          *instr
+	frame       *frame
+	targets     []*label
+	fallThrough bool
+	maxStack    int
+}
+
+func (s *state) fail(format string, args ...interface{}) {
+	s.analyzer.fail(s.in, format, args...)
+}
+
+func (s *state) push(types ...vtype) {
+	s.frame.stack = append(s.frame.stack, types...)
+	if len(s.frame.stack) > s.maxStack {
+		s.maxStack = len(s.frame.stack)
+	}
+}
+
+func (s *state) pushDescriptor(descriptor string) {
+	s.push(descriptorTypes(descriptor)...)
+}
+
+// pop 弹出n个slot
+func (s *state) pop(n int) []vtype {
+	stack := s.frame.stack
+	if len(stack) < n {
+		s.fail("stack underflow in %s at %d", opcodes.Name(s.in.opcode), s.in.pc)
+	}
+	popped := stack[len(stack)-n:]
+	s.frame.stack = stack[:len(stack)-n]
+	return popped
+}
+
+// popType 弹出一个指定类型的值，long/double弹出两个slot
+func (s *state) popType(t vtype) {
+	if t.isWide() {
+		popped := s.pop(2)
+		if s.analyzer.strict && popped[0] != t {
+			s.fail("expected %s on stack in %s at %d", typeName(t), opcodes.Name(s.in.opcode), s.in.pc)
+		}
+		return
+	}
+	popped := s.pop(1)[0]
+	if !s.analyzer.strict {
+		return
+	}
+	if t.tag == classfile.ITEM_Object && !popped.isReference() || t.tag != classfile.ITEM_Object && popped != t {
+		s.fail("expected %s on stack in %s at %d", typeName(t), opcodes.Name(s.in.opcode), s.in.pc)
+	}
+}
+
+func (s *state) popDescriptor(descriptor string) {
+	types := descriptorTypes(descriptor)
+	if len(types) > 0 {
+		s.popType(types[0])
+	}
+}
+
+func (s *state) popReference() vtype {
+	t := s.pop(1)[0]
+	if s.analyzer.strict && !t.isReference() {
+		s.fail("expected a reference on stack in %s at %d", opcodes.Name(s.in.opcode), s.in.pc)
+	}
+	return t
+}
+
+func typeName(t vtype) string {
+	switch t.tag {
+	case classfile.ITEM_Integer:
+		return "int"
+	case classfile.ITEM_Float:
+		return "float"
+	case classfile.ITEM_Long:
+		return "long"
+	case classfile.ITEM_Double:
+		return "double"
+	}
+	return "reference"
+}
+
+func (s *state) load(index int, t vtype) {
+	locals := s.frame.locals
+	if index >= len(locals) || t.isWide() && index+1 >= len(locals) {
+		if s.analyzer.strict {
+			s.fail("local variable %d is not initialized at %d", index, s.in.pc)
+		}
+		s.growLocals(index + 2)
+		locals = s.frame.locals
+	}
+	actual := locals[index]
+	if t.tag == classfile.ITEM_Object {
+		if s.analyzer.strict && !actual.isReference() {
+			s.fail("local variable %d is not a reference at %d", index, s.in.pc)
+		}
+		s.push(actual)
+		return
+	}
+	if s.analyzer.strict && actual != t {
+		s.fail("local variable %d is not %s at %d", index, typeName(t), s.in.pc)
+	}
+	s.push(t)
+	if t.isWide() {
+		s.push(topType)
+	}
+}
+
+func (s *state) growLocals(n int) {
+	for len(s.frame.locals) < n {
+		s.frame.locals = append(s.frame.locals, topType)
+	}
+}
+
+func (s *state) store(index int, t vtype) {
+	var value vtype
+	if t.tag == classfile.ITEM_Object {
+		value = s.popReference()
+	} else {
+		s.popType(t)
+		value = t
+	}
+	size := 1
+	if value.isWide() {
+		size = 2
+	}
+	s.growLocals(index + size)
+	locals := s.frame.locals
+	// 覆盖了long/double的后一半
+	if index > 0 && locals[index-1].isWide() {
+		locals[index-1] = topType
+	}
+	locals[index] = value
+	if size == 2 {
+		locals[index+1] = topType
+	}
+}
+
+// 按opcode排列的类型：I J F D A
+var loadStoreTypes = []vtype{intType, longType, floatType, doubleType, objectType(objectClass)}
+
+// 数组元素：I J F D A B C S
+var arrayElementTypes = []vtype{intType, longType, floatType, doubleType, objectType(objectClass), intType, intType, intType}
+
+var conversions = map[uint8][2]vtype{
+	opcodes.I2l: {intType, longType},
+	opcodes.I2f: {intType, floatType},
+	opcodes.I2d: {intType, doubleType},
+	opcodes.L2i: {longType, intType},
+	opcodes.L2f: {longType, floatType},
+	opcodes.L2d: {longType, doubleType},
+	opcodes.F2i: {floatType, intType},
+	opcodes.F2l: {floatType, longType},
+	opcodes.F2d: {floatType, doubleType},
+	opcodes.D2i: {doubleType, intType},
+	opcodes.D2l: {doubleType, longType},
+	opcodes.D2f: {doubleType, floatType},
+	opcodes.I2b: {intType, intType},
+	opcodes.I2c: {intType, intType},
+	opcodes.I2s: {intType, intType},
+}
+
+func (s *state) pushType(t vtype) {
+	s.push(t)
+	if t.isWide() {
+		s.push(topType)
+	}
+}
+
+func (s *state) run() {
+	in := s.in
+	op := in.opcode
+	s.fallThrough = true
+	switch {
+	case op == opcodes.Nop:
+	case op == opcodes.AconstNull:
+		s.push(nullType)
+	case op >= opcodes.IconstM1 && op <= opcodes.Iconst5, op == opcodes.Bipush, op == opcodes.Sipush:
+		s.push(intType)
+	case op == opcodes.Lconst0 || op == opcodes.Lconst1:
+		s.pushType(longType)
+	case op >= opcodes.Fconst0 && op <= opcodes.Fconst2:
+		s.push(floatType)
+	case op == opcodes.Dconst0 || op == opcodes.Dconst1:
+		s.pushType(doubleType)
+	case op == opcodes.Ldc || op == opcodes.LdcW || op == opcodes.Ldc2W:
+		s.ldc()
+	case op >= opcodes.Iload && op <= opcodes.Aload:
+		s.load(in.index, loadStoreTypes[op-opcodes.Iload])
+	case op >= opcodes.Iload0 && op <= opcodes.Aload3:
+		n := int(op - opcodes.Iload0)
+		s.load(n%4, loadStoreTypes[n/4])
+	case op >= opcodes.Iaload && op <= opcodes.Saload:
+		s.popType(intType)
+		array := s.popReference()
+		if op == opcodes.Aaload {
+			s.push(s.componentType(array))
+		} else {
+			s.pushType(arrayElementTypes[op-opcodes.Iaload])
+		}
+	case op >= opcodes.Istore && op <= opcodes.Astore:
+		s.store(in.index, loadStoreTypes[op-opcodes.Istore])
+	case op >= opcodes.Istore0 && op <= opcodes.Astore3:
+		n := int(op - opcodes.Istore0)
+		s.store(n%4, loadStoreTypes[n/4])
+	case op >= opcodes.Iastore && op <= opcodes.Sastore:
+		t := arrayElementTypes[op-opcodes.Iastore]
+		if t.tag == classfile.ITEM_Object {
+			s.popReference()
+		} else {
+			s.popType(t)
+		}
+		s.popType(intType)
+		s.popReference()
+	case op >= opcodes.Pop && op <= opcodes.Swap:
+		s.stackOp(op)
+	case op >= opcodes.Iadd && op <= opcodes.Drem:
+		t := loadStoreTypes[(op-opcodes.Iadd)%4]
+		s.popType(t)
+		s.popType(t)
+		s.pushType(t)
+	case op >= opcodes.Ineg && op <= opcodes.Dneg:
+		t := loadStoreTypes[(op-opcodes.Ineg)%4]
+		s.popType(t)
+		s.pushType(t)
+	case op >= opcodes.Ishl && op <= opcodes.Lxor:
+		t := loadStoreTypes[(op-opcodes.Ishl)%2]
+		if op <= opcodes.Lushr {
+			s.popType(intType) // 移位的位数
+		} else {
+			s.popType(t)
+		}
+		s.popType(t)
+		s.pushType(t)
+	case op == opcodes.Iinc:
+		s.load(in.index, intType)
+		s.pop(1)
+	case op >= opcodes.I2l && op <= opcodes.I2s:
+		conversion := conversions[op]
+		s.popType(conversion[0])
+		s.pushType(conversion[1])
+	case op == opcodes.Lcmp:
+		s.popType(longType)
+		s.popType(longType)
+		s.push(intType)
+	case op == opcodes.Fcmpl || op == opcodes.Fcmpg:
+		s.popType(floatType)
+		s.popType(floatType)
+		s.push(intType)
+	case op == opcodes.Dcmpl || op == opcodes.Dcmpg:
+		s.popType(doubleType)
+		s.popType(doubleType)
+		s.push(intType)
+	case op >= opcodes.Ifeq && op <= opcodes.Ifle:
+		s.popType(intType)
+		s.targets = []*label{in.target}
+	case op >= opcodes.IfIcmpeq && op <= opcodes.IfIcmple:
+		s.popType(intType)
+		s.popType(intType)
+		s.targets = []*label{in.target}
+	case op == opcodes.IfAcmpeq || op == opcodes.IfAcmpne:
+		s.popReference()
+		s.popReference()
+		s.targets = []*label{in.target}
+	case op == opcodes.Ifnull || op == opcodes.Ifnonnull:
+		s.popReference()
+		s.targets = []*label{in.target}
+	case op == opcodes.Goto || op == opcodes.GotoW:
+		s.targets, s.fallThrough = []*label{in.target}, false
+	case op == opcodes.Jsr || op == opcodes.JsrW || op == opcodes.Ret:
+		// 子程序没法用StackMapTable描述；只算栈深度时近似为跳过子程序
+		if s.analyzer.strict {
+			s.fail("%s cannot be used when computing stack map frames", opcodes.Name(op))
+		}
+		if op != opcodes.Ret {
+			s.push(topType)
+			s.targets = []*label{in.target}
+			s.pop(1)
+		} else {
+			s.fallThrough = false
+		}
+	case op == opcodes.Tableswitch || op == opcodes.Lookupswitch:
+		s.popType(intType)
+		s.targets = append([]*label{in.dflt}, in.targets...)
+		s.fallThrough = false
+	case op >= opcodes.Ireturn && op <= opcodes.Return:
+		s.returnValue(op)
+		s.fallThrough = false
+	case op == opcodes.Getstatic:
+		s.pushDescriptor(in.descriptor)
+	case op == opcodes.Putstatic:
+		s.popDescriptor(in.descriptor)
+	case op == opcodes.Getfield:
+		s.popReference()
+		s.pushDescriptor(in.descriptor)
+	case op == opcodes.Putfield:
+		s.popDescriptor(in.descriptor)
+		s.popReference()
+	case op >= opcodes.Invokevirtual && op <= opcodes.Invokedynamic:
+		s.invoke()
+	case op == opcodes.New:
+		s.push(vtype{tag: classfile.ITEM_Uninitialized, offset: in.pc})
+	case op == opcodes.Newarray:
+		s.popType(intType)
+		s.push(objectType("[" + newarrayDescriptors[in.value]))
+	case op == opcodes.Anewarray:
+		s.popType(intType)
+		s.push(objectType("[" + elementDescriptor(in.owner)))
+	case op == opcodes.Arraylength:
+		s.popReference()
+		s.push(intType)
+	case op == opcodes.Athrow:
+		s.popReference()
+		s.fallThrough = false
+	case op == opcodes.Checkcast:
+		s.popReference()
+		s.push(objectType(in.owner))
+	case op == opcodes.Instanceof:
+		s.popReference()
+		s.push(intType)
+	case op == opcodes.Monitorenter || op == opcodes.Monitorexit:
+		s.popReference()
+	case op == opcodes.Multianewarray:
+		for n := 0; n < in.value; n++ {
+			s.popType(intType)
+		}
+		s.push(objectType(in.descriptor))
+	default:
+		s.fail("%s is not allowed in method code", opcodes.Name(op))
+	}
+}
+
+var newarrayDescriptors = map[int]string{
+	opcodes.TBoolean: "Z",
+	opcodes.TChar:    "C",
+	opcodes.TFloat:   "F",
+	opcodes.TDouble:  "D",
+	opcodes.TByte:    "B",
+	opcodes.TShort:   "S",
+	opcodes.TInt:     "I",
+	opcodes.TLong:    "J",
+}
+
+func (s *state) ldc() {
+	switch s.in.cpTag {
+	case classfile.CONSTANT_Integer:
+		s.push(intType)
+	case classfile.CONSTANT_Float:
+		s.push(floatType)
+	case classfile.CONSTANT_Long:
+		s.pushType(longType)
+	case classfile.CONSTANT_Double:
+		s.pushType(doubleType)
+	case classfile.CONSTANT_String:
+		s.push(objectType("java/lang/String"))
+	case classfile.CONSTANT_Class:
+		s.push(objectType("java/lang/Class"))
+	case classfile.CONSTANT_MethodType:
+		s.push(objectType("java/lang/invoke/MethodType"))
+	case classfile.CONSTANT_MethodHandle:
+		s.push(objectType("java/lang/invoke/MethodHandle"))
+	}
+}
+
+// aaload取出的元素类型
+func (s *state) componentType(array vtype) vtype {
+	if array.tag == classfile.ITEM_Null {
+		return nullType
+	}
+	if array.tag != classfile.ITEM_Object || !strings.HasPrefix(array.class, "[") {
+		if s.analyzer.strict {
+			s.fail("aaload on a non-array at %d", s.in.pc)
+		}
+		return objectType(objectClass)
+	}
+	return descriptorTypes(array.class[1:])[0]
+}
+
+func (s *state) stackOp(op uint8) {
+	switch op {
+	case opcodes.Pop:
+		s.pop(1)
+	case opcodes.Pop2:
+		s.pop(2)
+	case opcodes.Dup:
+		v := s.pop(1)[0]
+		s.push(v, v)
+	case opcodes.DupX1:
+		v := append([]vtype(nil), s.pop(2)...)
+		s.push(v[1], v[0], v[1])
+	case opcodes.DupX2:
+		v := append([]vtype(nil), s.pop(3)...)
+		s.push(v[2], v[0], v[1], v[2])
+	case opcodes.Dup2:
+		v := append([]vtype(nil), s.pop(2)...)
+		s.push(v[0], v[1], v[0], v[1])
+	case opcodes.Dup2X1:
+		v := append([]vtype(nil), s.pop(3)...)
+		s.push(v[1], v[2], v[0], v[1], v[2])
+	case opcodes.Dup2X2:
+		v := append([]vtype(nil), s.pop(4)...)
+		s.push(v[2], v[3], v[0], v[1], v[2], v[3])
+	case opcodes.Swap:
+		v := append([]vtype(nil), s.pop(2)...)
+		s.push(v[1], v[0])
+	}
+}
+
+func (s *state) returnValue(op uint8) {
+	md, _ := signature.ParseMethodDescriptor(s.analyzer.methodBuilder.descriptor)
+	returnType := md.Return.Signature()
+	if op == opcodes.Return {
+		if s.analyzer.strict && returnType != "V" {
+			s.fail("return in a method returning %s", returnType)
+		}
+		return
+	}
+	if op == opcodes.Areturn {
+		s.popReference()
+	} else {
+		s.popType(loadStoreTypes[op-opcodes.Ireturn])
+	}
+	if s.analyzer.strict && (returnType == "V" || descriptorTypes(returnType)[0].tag != loadStoreTypes[op-opcodes.Ireturn].tag) {
+		s.fail("%s in a method returning %s", opcodes.Name(op), returnType)
+	}
+}
+
+func (s *state) invoke() {
+	in := s.in
+	md, _ := signature.ParseMethodDescriptor(in.descriptor)
+	for i := len(md.Params) - 1; i >= 0; i-- {
+		param := md.Params[i].Signature()
+		if t := descriptorTypes(param)[0]; t.tag == classfile.ITEM_Object {
+			s.popReference()
+		} else {
+			s.popType(t)
+		}
+	}
+	if in.opcode != opcodes.Invokestatic && in.opcode != opcodes.Invokedynamic {
+		receiver := s.popReference()
+		if in.opcode == opcodes.Invokespecial && in.name == "<init>" {
+			s.initialize(receiver)
+		}
+	}
+	s.pushDescriptor(md.Return.Signature())
+}
+
+// 构造函数调用之后，栈和局部变量中所有同一个未初始化对象都变成已初始化
+func (s *state) initialize(receiver vtype) {
+	var initialized vtype
+	switch receiver.tag {
+	case classfile.ITEM_UninitializedThis:
+		initialized = objectType(s.analyzer.methodBuilder.assembler.className)
+	case classfile.ITEM_Uninitialized:
+		initialized = objectType(s.analyzer.newClasses[receiver.offset])
+	default:
+		if s.analyzer.strict {
+			s.fail("<init> called on an initialized object at %d", s.in.pc)
+		}
+		return
+	}
+	for _, types := range [][]vtype{s.frame.locals, s.frame.stack} {
+		for i, t := range types {
+			if t == receiver {
+				types[i] = initialized
+			}
+		}
+	}
+}
+
+// removeDeadCode 把执行不到的代码换成nop...athrow，并从异常表中去掉这些范围，和ASM的做法一样
+func (a *analyzer) removeDeadCode(code []byte, exceptionTable []*classfile.ExceptionTableEntry) ([]byte, []*classfile.ExceptionTableEntry) {
+	instrs := a.methodBuilder.instrs
+	type pcRange struct{ start, end int }
+	var dead []pcRange
+	for i := 0; i < len(instrs); i++ {
+		if a.frames[i] != nil {
+			continue
+		}
+		j := i
+		for j < len(instrs) && a.frames[j] == nil {
+			j++
+		}
+		end := len(code)
+		if j < len(instrs) {
+			end = instrs[j].pc
+		}
+		dead = append(dead, pcRange{instrs[i].pc, end})
+		a.frames[i] = &frame{stack: []vtype{objectType("java/lang/Throwable")}}
+		a.needsFrame[i] = true
+		i = j
+	}
+	if len(dead) == 0 {
+		return code, exceptionTable
+	}
+
+	code = append([]byte(nil), code...)
+	for _, r := range dead {
+		for pc := r.start; pc < r.end-1; pc++ {
+			code[pc] = opcodes.Nop
+		}
+		code[r.end-1] = opcodes.Athrow
+	}
+
+	var table []*classfile.ExceptionTableEntry
+	for _, entry := range exceptionTable {
+		start, end := int(entry.StartPc()), int(entry.EndPc())
+		for _, r := range dead {
+			if r.end <= start || r.start >= end {
+				continue
+			}
+			if r.start > start {
+				table = append(table, classfile.NewExceptionTableEntry(uint16(start), uint16(r.start), entry.HandlerPc(), entry.CatchType()))
+			}
+			start = max(start, r.end)
+		}
+		if start < end {
+			table = append(table, classfile.NewExceptionTableEntry(uint16(start), uint16(end), entry.HandlerPc(), entry.CatchType()))
+		}
+	}
+	return code, table
+}
+
+// encodeFrames 把需要的栈帧按JVMS 4.7.4压缩编码
+func (a *analyzer) encodeFrames(initial *frame) []*classfile.StackMapFrame {
+	var frames []*classfile.StackMapFrame
+	prevLocals := a.compact(initial.locals, true)
+	prevPC := -1
+	for i, in := range a.methodBuilder.instrs {
+		if !a.needsFrame[i] {
+			continue
+		}
+		f := a.frames[i]
+		locals := a.compact(f.locals, true)
+		stack := a.compact(f.stack, false)
+		delta := in.pc - prevPC - 1
+		frames = append(frames, compressFrame(delta, prevLocals, locals, stack))
+		prevLocals, prevPC = locals, in.pc
+	}
+	return frames
+}
+
+// localsUsed 返回指令用到的局部变量的上界
+func localsUsed(in *instr) int {
+	op := in.opcode
+	switch {
+	case op >= opcodes.Iload && op <= opcodes.Aload:
+		return in.index + localSize(op-opcodes.Iload)
+	case op >= opcodes.Istore && op <= opcodes.Astore:
+		return in.index + localSize(op-opcodes.Istore)
+	case op >= opcodes.Iload0 && op <= opcodes.Aload3:
+		n := op - opcodes.Iload0
+		return int(n%4) + localSize(n/4)
+	case op >= opcodes.Istore0 && op <= opcodes.Astore3:
+		n := op - opcodes.Istore0
+		return int(n%4) + localSize(n/4)
+	case op == opcodes.Iinc, op == opcodes.Ret:
+		return in.index + 1
+	}
+	return 0
+}
+
+// 按I J F D A的顺序，long和double占两个slot
+func localSize(typeIndex uint8) int {
+	if typeIndex == 1 || typeIndex == 3 {
+		return 2
+	}
+	return 1
+}
+
+// compact 把slot形式的类型转成verification_type_info：long/double只占一项，局部变量去掉末尾的Top
+func (a *analyzer) compact(types []vtype, trimTop bool) []classfile.VerificationTypeInfo {
+	cp := a.methodBuilder.assembler.cp
+	var infos []classfile.VerificationTypeInfo
+	for i := 0; i < len(types); i++ {
+		t := types[i]
+		switch t.tag {
+		case classfile.ITEM_Object:
+			infos = append(infos, classfile.NewVerificationTypeInfo(t.tag, cp.AddClass(t.class)))
+		case classfile.ITEM_Uninitialized:
+			infos = append(infos, classfile.NewVerificationTypeInfo(t.tag, uint16(t.offset)))
+		default:
+			infos = append(infos, classfile.NewVerificationTypeInfo(t.tag, 0))
+		}
+		if t.isWide() {
+			i++
+		}
+	}
+	if trimTop {
+		for len(infos) > 0 && infos[len(infos)-1].Tag() == classfile.ITEM_Top {
+			infos = infos[:len(infos)-1]
+		}
+	}
+	return infos
+}
+
+func compressFrame(delta int, prevLocals, locals, stack []classfile.VerificationTypeInfo) *classfile.StackMapFrame {
+	sameLocals := equalTypes(prevLocals, locals)
+	switch {
+	case sameLocals && len(stack) == 0 && delta <= classfile.SameFrameMax:
+		return classfile.NewStackMapFrame(uint8(delta), 0, nil, nil)
+	case sameLocals && len(stack) == 0:
+		return classfile.NewStackMapFrame(classfile.SameFrameExtended, uint16(delta), nil, nil)
+	case sameLocals && len(stack) == 1 && delta <= classfile.SameFrameMax:
+		return classfile.NewStackMapFrame(uint8(classfile.SameFrameMax+1+delta), 0, nil, stack)
+	case sameLocals && len(stack) == 1:
+		return classfile.NewStackMapFrame(classfile.SameLocals1StackItemFrameExtend, uint16(delta), nil, stack)
+	case len(stack) == 0 && len(locals) < len(prevLocals) && len(prevLocals)-len(locals) <= 3 &&
+		equalTypes(prevLocals[:len(locals)], locals):
+		return classfile.NewStackMapFrame(uint8(classfile.SameFrameExtended-(len(prevLocals)-len(locals))), uint16(delta), nil, nil)
+	case len(stack) == 0 && len(locals) > len(prevLocals) && len(locals)-len(prevLocals) <= 3 &&
+		equalTypes(prevLocals, locals[:len(prevLocals)]):
+		return classfile.NewStackMapFrame(uint8(classfile.SameFrameExtended+(len(locals)-len(prevLocals))), uint16(delta),
+			locals[len(prevLocals):], nil)
+	}
+	return classfile.NewStackMapFrame(classfile.FullFrame, uint16(delta), locals, stack)
+}
+
+func equalTypes(types1, types2 []classfile.VerificationTypeInfo) bool {
+	if len(types1) != len(types2) {
+		return false
+	}
+	for i := range types1 {
+		if types1[i] != types2[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,513 @@
+package asm
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// instr 一条指令；常量都已经加到常量池，跳转目标在编码时才解析
+type instr struct {
+	pc     int
+	line   int
+	opcode uint8
+	wide   bool
+
+	index int // 常量池索引或局部变量索引
+	value int // bipush/sipush的值、iinc的增量、newarray的atype、维数、invokeinterface的count
+
+	target  *label   // 跳转指令的目标
+	low     int32    // tableswitch
+	keys    []int32  // lookupswitch
+	targets []*label // switch的各个分支
+	dflt    *label   // switch的default
+
+	// 计算栈帧时用到的信息
+	owner      string // 字段或方法所属的类，new/checkcast等指令的类
+	name       string
+	descriptor string // 字段、方法或multianewarray的描述符
+	cpTag      uint8  // ldc的常量类型
+}
+
+func (in *instr) size() int {
+	switch opcodes.Kind(in.opcode) {
+	case opcodes.NoOperands:
+		return 1
+	case opcodes.Byte, opcodes.CPRefByte, opcodes.ArrayType:
+		return 2
+	case opcodes.Short, opcodes.CPRef, opcodes.Branch:
+		return 3
+	case opcodes.CPRefDims:
+		return 4
+	case opcodes.CPRefCountZero, opcodes.CPRefZeroZero, opcodes.BranchWide:
+		return 5
+	case opcodes.Local:
+		if in.wide {
+			return 4
+		}
+		return 2
+	case opcodes.LocalByte:
+		if in.wide {
+			return 6
+		}
+		return 3
+	case opcodes.TableSwitch:
+		return 1 + padding(in.pc) + 12 + 4*len(in.targets)
+	case opcodes.LookupSwitch:
+		return 1 + padding(in.pc) + 8 + 8*len(in.keys)
+	}
+	return 1
+}
+
+// switch指令操作码之后补0，使操作数4字节对齐
+func padding(pc int) int {
+	return (4 - (pc+1)%4) % 4
+}
+
+// instruction 解析一条指令
+func (methodBuilder *methodBuilder) instruction(tokens []token) {
+	assembler := methodBuilder.assembler
+	cp := assembler.cp
+	name := tokens[0].text
+	opcode, ok := opcodes.Lookup(name)
+	if !ok || tokens[0].quoted {
+		methodBuilder.fail("unknown instruction %s", name)
+	}
+	args := tokens[1:]
+	in := &instr{opcode: opcode}
+
+	switch opcodes.Kind(opcode) {
+	case opcodes.NoOperands:
+		assembler.expectArgs(args, 0, 0)
+	case opcodes.Byte:
+		assembler.expectArgs(args, 1, 1)
+		in.value = methodBuilder.parseIntRange(args[0].text, math.MinInt8, math.MaxInt8)
+	case opcodes.Short:
+		assembler.expectArgs(args, 1, 1)
+		in.value = methodBuilder.parseIntRange(args[0].text, math.MinInt16, math.MaxInt16)
+	case opcodes.CPRefByte, opcodes.CPRef:
+		methodBuilder.cpOperand(in, args)
+	case opcodes.CPRefCountZero:
+		// invokeinterface Owner/name(desc)ret [count]，省略count时按描述符计算
+		assembler.expectArgs(args, 1, 2)
+		methodBuilder.methodOperand(in, args[:1], true)
+		slots, _ := signature.ArgSlotCount(in.descriptor)
+		in.value = slots + 1
+		if len(args) == 2 {
+			in.value = methodBuilder.parseIntRange(args[1].text, 1, 255)
+		}
+	case opcodes.CPRefZeroZero:
+		methodBuilder.invokeDynamic(in, args)
+	case opcodes.CPRefDims:
+		// multianewarray desc dims
+		assembler.expectArgs(args, 2, 2)
+		in.owner = args[0].text
+		in.descriptor = args[0].text
+		in.index = int(cp.AddClass(args[0].text))
+		in.value = methodBuilder.parseIntRange(args[1].text, 1, 255)
+		if !strings.HasPrefix(in.descriptor, strings.Repeat("[", in.value)) {
+			methodBuilder.fail("%s has fewer than %d dimensions", in.descriptor, in.value)
+		}
+	case opcodes.Local:
+		assembler.expectArgs(args, 1, 1)
+		in.index = methodBuilder.parseIntRange(args[0].text, 0, math.MaxUint16)
+		in.wide = in.index > math.MaxUint8
+	case opcodes.LocalByte:
+		// iinc index delta
+		assembler.expectArgs(args, 2, 2)
+		in.index = methodBuilder.parseIntRange(args[0].text, 0, math.MaxUint16)
+		in.value = methodBuilder.parseIntRange(args[1].text, math.MinInt16, math.MaxInt16)
+		in.wide = in.index > math.MaxUint8 || in.value < math.MinInt8 || in.value > math.MaxInt8
+	case opcodes.Branch, opcodes.BranchWide:
+		assembler.expectArgs(args, 1, 1)
+		in.target = methodBuilder.label(args[0].text)
+	case opcodes.ArrayType:
+		assembler.expectArgs(args, 1, 1)
+		atype, ok := opcodes.ArrayTypeByName(args[0].text)
+		if !ok {
+			methodBuilder.fail("invalid newarray type %s", args[0].text)
+		}
+		in.value = atype
+	case opcodes.TableSwitch:
+		// tableswitch low [high]，后面每行一个分支标签，最后是default : label
+		assembler.expectArgs(args, 1, 2)
+		in.low = int32(methodBuilder.parseIntRange(args[0].text, math.MinInt32, math.MaxInt32))
+		in.value = -1
+		if len(args) == 2 {
+			high := methodBuilder.parseIntRange(args[1].text, int64(in.low), math.MaxInt32)
+			in.value = high - int(in.low) + 1 // 分支数，读到default时检查
+		}
+		methodBuilder.switchInstr = in
+		return
+	case opcodes.LookupSwitch:
+		// lookupswitch，后面每行一个 key : label，最后是default : label
+		assembler.expectArgs(args, 0, 0)
+		methodBuilder.switchInstr = in
+		return
+	case opcodes.WidePrefix:
+		methodBuilder.fail("wide is added automatically; write the instruction without it")
+	}
+	methodBuilder.add(in)
+}
+
+// switch的case行
+func (methodBuilder *methodBuilder) switchCase(tokens []token) {
+	in := methodBuilder.switchInstr
+	texts := make([]string, len(tokens))
+	for i, t := range tokens {
+		texts[i] = t.text
+	}
+	// 把 "key : label"、"key: label"、"key :label" 都规范成 key : label
+	parts := strings.Fields(strings.Replace(strings.Join(texts, " "), ":", " : ", 1))
+
+	if len(parts) == 3 && parts[0] == "default" && parts[1] == ":" {
+		in.dflt = methodBuilder.label(parts[2])
+		if in.opcode == opcodes.Tableswitch && (len(in.targets) == 0 || in.value >= 0 && in.value != len(in.targets)) {
+			methodBuilder.fail("tableswitch has %d targets, expected %d", len(in.targets), max(in.value, 1))
+		}
+		methodBuilder.switchInstr = nil
+		methodBuilder.add(in)
+		return
+	}
+	if in.opcode == opcodes.Tableswitch {
+		if len(parts) != 1 {
+			methodBuilder.fail("expected a label or default : label in tableswitch")
+		}
+		in.targets = append(in.targets, methodBuilder.label(parts[0]))
+		if int64(in.low)+int64(len(in.targets))-1 > math.MaxInt32 {
+			methodBuilder.fail("tableswitch range too large")
+		}
+		return
+	}
+	if len(parts) != 3 || parts[1] != ":" {
+		methodBuilder.fail("expected key : label or default : label in lookupswitch")
+	}
+	key := int32(methodBuilder.parseIntRange(parts[0], math.MinInt32, math.MaxInt32))
+	if n := len(in.keys); n > 0 && key <= in.keys[n-1] {
+		methodBuilder.fail("lookupswitch keys must be in increasing order")
+	}
+	in.keys = append(in.keys, key)
+	in.targets = append(in.targets, methodBuilder.label(parts[2]))
+}
+
+func (methodBuilder *methodBuilder) parseIntRange(s string, min, max int64) int {
+	val, err := strconv.ParseInt(s, 0, 64)
+	if err != nil || val < min || val > max {
+		methodBuilder.fail("expected an integer in [%d, %d], got %q", min, max, s)
+	}
+	return int(val)
+}
+
+// 带u2常量池索引的指令
+func (methodBuilder *methodBuilder) cpOperand(in *instr, args []token) {
+	assembler := methodBuilder.assembler
+	cp := assembler.cp
+	switch in.opcode {
+	case opcodes.Ldc, opcodes.LdcW, opcodes.Ldc2W:
+		wide := in.opcode == opcodes.Ldc2W
+		index, tag, className := methodBuilder.constant(args, wide)
+		in.index, in.cpTag, in.owner = int(index), tag, className
+		if wide != (tag == classfile.CONSTANT_Long || tag == classfile.CONSTANT_Double) {
+			methodBuilder.fail("%s cannot load this constant", opcodes.Name(in.opcode))
+		}
+		// 索引放不进u1时自动换成ldc_w
+		if in.opcode == opcodes.Ldc && in.index > math.MaxUint8 {
+			in.opcode = opcodes.LdcW
+		}
+	case opcodes.Getstatic, opcodes.Putstatic, opcodes.Getfield, opcodes.Putfield:
+		// getfield Owner/name descriptor
+		assembler.expectArgs(args, 2, 2)
+		ref := args[0].text
+		i := strings.LastIndexByte(ref, '/')
+		if i <= 0 {
+			methodBuilder.fail("expected Owner/name, got %q", ref)
+		}
+		in.owner, in.name, in.descriptor = ref[:i], ref[i+1:], args[1].text
+		in.index = int(cp.AddFieldref(in.owner, in.name, in.descriptor))
+	case opcodes.Invokevirtual:
+		assembler.expectArgs(args, 1, 1)
+		methodBuilder.methodOperand(in, args, false)
+	case opcodes.Invokespecial, opcodes.Invokestatic:
+		// 调用接口方法时写成 invokestatic interface Owner/name(desc)ret
+		assembler.expectArgs(args, 1, 2)
+		isInterface := false
+		if len(args) == 2 {
+			if args[0].text != "interface" {
+				methodBuilder.fail("unexpected %s", args[0].text)
+			}
+			isInterface, args = true, args[1:]
+		}
+		methodBuilder.methodOperand(in, args, isInterface)
+	case opcodes.New, opcodes.Anewarray, opcodes.Checkcast, opcodes.Instanceof:
+		assembler.expectArgs(args, 1, 1)
+		in.owner = args[0].text
+		in.index = int(cp.AddClass(in.owner))
+	default:
+		methodBuilder.fail("unsupported instruction %s", opcodes.Name(in.opcode))
+	}
+}
+
+// Owner/name(desc)ret形式的方法引用
+func (methodBuilder *methodBuilder) methodOperand(in *instr, args []token, isInterface bool) {
+	in.owner, in.name, in.descriptor = methodBuilder.parseMethodRef(args[0].text)
+	cp := methodBuilder.assembler.cp
+	if isInterface || in.opcode == opcodes.Invokeinterface {
+		in.index = int(cp.AddInterfaceMethodref(in.owner, in.name, in.descriptor))
+	} else {
+		in.index = int(cp.AddMethodref(in.owner, in.name, in.descriptor))
+	}
+}
+
+func (methodBuilder *methodBuilder) parseMethodRef(ref string) (owner, name, descriptor string) {
+	paren := strings.IndexByte(ref, '(')
+	if paren < 0 {
+		methodBuilder.fail("expected Owner/name(descriptor), got %q", ref)
+	}
+	slash := strings.LastIndexByte(ref[:paren], '/')
+	if slash <= 0 || slash == paren-1 {
+		methodBuilder.fail("expected Owner/name(descriptor), got %q", ref)
+	}
+	descriptor = ref[paren:]
+	if _, err := signature.ParseMethodDescriptor(descriptor); err != nil {
+		methodBuilder.fail("invalid method descriptor %s: %v", descriptor, err)
+	}
+	return ref[:slash], ref[slash+1 : paren], descriptor
+}
+
+// invokedynamic name(desc)ret BsmOwner/bsmName(bsmDesc)ret [args...]
+// 引导方法是invokestatic的方法句柄，参数的写法和ldc的常量相同
+func (methodBuilder *methodBuilder) invokeDynamic(in *instr, args []token) {
+	assembler := methodBuilder.assembler
+	cp := assembler.cp
+	if len(args) < 2 {
+		methodBuilder.fail("expected invokedynamic name(descriptor) Owner/bootstrap(descriptor) [arguments...]")
+	}
+	nameAndDescriptor := args[0].text
+	paren := strings.IndexByte(nameAndDescriptor, '(')
+	if paren <= 0 {
+		methodBuilder.fail("expected name(descriptor), got %q", nameAndDescriptor)
+	}
+	in.name, in.descriptor = nameAndDescriptor[:paren], nameAndDescriptor[paren:]
+	if _, err := signature.ParseMethodDescriptor(in.descriptor); err != nil {
+		methodBuilder.fail("invalid method descriptor %s: %v", in.descriptor, err)
+	}
+	bsmOwner, bsmName, bsmDescriptor := methodBuilder.parseMethodRef(args[1].text)
+	bsm := cp.AddMethodHandle(classfile.REF_invokeStatic, cp.AddMethodref(bsmOwner, bsmName, bsmDescriptor))
+
+	var bsmArgs []uint16
+	for rest := args[2:]; len(rest) > 0; {
+		n := constantTokens(rest)
+		index, _, _ := methodBuilder.constant(rest[:n], false)
+		bsmArgs = append(bsmArgs, index)
+		rest = rest[n:]
+	}
+	bsmIndex := assembler.addBootstrapMethod(bsm, bsmArgs)
+	in.index = int(cp.AddInvokeDynamic(bsmIndex, in.name, in.descriptor))
+}
+
+// constantTokens 返回一个常量占用的词数
+func constantTokens(tokens []token) int {
+	if tokens[0].quoted {
+		return 1
+	}
+	switch tokens[0].text {
+	case "int", "float", "long", "double", "class", "string", "methodtype":
+		return min(2, len(tokens))
+	case "methodhandle":
+		// methodhandle kind [interface] ref，字段的描述符是单独的一个词
+		n := 3
+		if len(tokens) > 2 && tokens[2].text == "interface" {
+			n++
+		}
+		if kind := refKinds[tokens[min(1, len(tokens)-1)].text]; kind != 0 && kind <= classfile.REF_putStatic {
+			n++
+		}
+		return min(n, len(tokens))
+	}
+	return 1
+}
+
+var refKinds = map[string]uint8{
+	"getfield":         classfile.REF_getField,
+	"getstatic":        classfile.REF_getStatic,
+	"putfield":         classfile.REF_putField,
+	"putstatic":        classfile.REF_putStatic,
+	"invokevirtual":    classfile.REF_invokeVirtual,
+	"invokestatic":     classfile.REF_invokeStatic,
+	"invokespecial":    classfile.REF_invokeSpecial,
+	"newinvokespecial": classfile.REF_newInvokeSpecial,
+	"invokeinterface":  classfile.REF_invokeInterface,
+}
+
+// constant 解析ldc和引导方法参数里的常量，返回常量池索引、常量类型和（Class常量的）类名
+//
+//	"string"  123  1.5  int 1  float 1  long 1  double 1  class Name  string "s"
+//	methodtype (I)V  methodhandle invokestatic [interface] Owner/name(desc)ret
+//	methodhandle getfield Owner/name desc
+//
+// 不带类型的数字在wide为true时是long或double
+func (methodBuilder *methodBuilder) constant(args []token, wide bool) (uint16, uint8, string) {
+	assembler := methodBuilder.assembler
+	cp := assembler.cp
+	if len(args) == 0 {
+		methodBuilder.fail("expected a constant")
+	}
+	first := args[0]
+	if first.quoted {
+		assembler.expectArgs(args, 1, 1)
+		return cp.AddString(first.text), classfile.CONSTANT_String, ""
+	}
+	switch first.text {
+	case "int", "float", "long", "double", "class", "string", "methodtype":
+		assembler.expectArgs(args, 2, 2)
+		value := args[1].text
+		switch first.text {
+		case "int":
+			return cp.AddInteger(assembler.parseInt32(value)), classfile.CONSTANT_Integer, ""
+		case "float":
+			return cp.AddFloat(float32(assembler.parseFloat(value, 32))), classfile.CONSTANT_Float, ""
+		case "long":
+			return cp.AddLong(assembler.parseInt64(value)), classfile.CONSTANT_Long, ""
+		case "double":
+			return cp.AddDouble(assembler.parseFloat(value, 64)), classfile.CONSTANT_Double, ""
+		case "class":
+			return cp.AddClass(value), classfile.CONSTANT_Class, value
+		case "string":
+			return cp.AddString(value), classfile.CONSTANT_String, ""
+		default:
+			if _, err := signature.ParseMethodDescriptor(value); err != nil {
+				methodBuilder.fail("invalid method descriptor %s: %v", value, err)
+			}
+			return cp.AddMethodType(value), classfile.CONSTANT_MethodType, ""
+		}
+	case "methodhandle":
+		return methodBuilder.methodHandle(args[1:]), classfile.CONSTANT_MethodHandle, ""
+	}
+
+	assembler.expectArgs(args, 1, 1)
+	text := first.text
+	isFloat := looksFloat(text)
+	switch {
+	case isFloat && wide:
+		return cp.AddDouble(assembler.parseFloat(text, 64)), classfile.CONSTANT_Double, ""
+	case isFloat:
+		return cp.AddFloat(float32(assembler.parseFloat(text, 32))), classfile.CONSTANT_Float, ""
+	case wide:
+		return cp.AddLong(assembler.parseInt64(text)), classfile.CONSTANT_Long, ""
+	default:
+		return cp.AddInteger(assembler.parseInt32(text)), classfile.CONSTANT_Integer, ""
+	}
+}
+
+// looksFloat 判断不带类型的数字是不是浮点数：1.5、1e3、NaN、Infinity、0x1p3
+func looksFloat(text string) bool {
+	if strings.HasPrefix(strings.TrimPrefix(text, "-"), "0x") {
+		return strings.ContainsAny(text, "pP")
+	}
+	return strings.ContainsAny(text, ".eEN") || strings.Contains(text, "Infinity")
+}
+
+func (methodBuilder *methodBuilder) methodHandle(args []token) uint16 {
+	cp := methodBuilder.assembler.cp
+	if len(args) < 2 {
+		methodBuilder.fail("expected methodhandle <kind> <reference>")
+	}
+	kind, ok := refKinds[args[0].text]
+	if !ok {
+		methodBuilder.fail("invalid method handle kind %s", args[0].text)
+	}
+	args = args[1:]
+	switch kind {
+	case classfile.REF_getField, classfile.REF_getStatic, classfile.REF_putField, classfile.REF_putStatic:
+		methodBuilder.assembler.expectArgs(args, 2, 2)
+		ref := args[0].text
+		i := strings.LastIndexByte(ref, '/')
+		if i <= 0 {
+			methodBuilder.fail("expected Owner/name, got %q", ref)
+		}
+		return cp.AddMethodHandle(kind, cp.AddFieldref(ref[:i], ref[i+1:], args[1].text))
+	}
+	isInterface := kind == classfile.REF_invokeInterface
+	if args[0].text == "interface" && len(args) > 1 {
+		isInterface, args = true, args[1:]
+	}
+	methodBuilder.assembler.expectArgs(args, 1, 1)
+	owner, name, descriptor := methodBuilder.parseMethodRef(args[0].text)
+	if isInterface {
+		return cp.AddMethodHandle(kind, cp.AddInterfaceMethodref(owner, name, descriptor))
+	}
+	return cp.AddMethodHandle(kind, cp.AddMethodref(owner, name, descriptor))
+}
+
+// encode 把指令追加到code后面，这时所有标签都已定义
+func (in *instr) encode(methodBuilder *methodBuilder, code []byte) []byte {
+	if in.wide {
+		code = append(code, opcodes.Wide)
+	}
+	code = append(code, in.opcode)
+	switch opcodes.Kind(in.opcode) {
+	case opcodes.Byte, opcodes.ArrayType:
+		code = append(code, byte(in.value))
+	case opcodes.CPRefByte:
+		code = append(code, byte(in.index))
+	case opcodes.Short:
+		code = binary.BigEndian.AppendUint16(code, uint16(in.value))
+	case opcodes.CPRef:
+		code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+	case opcodes.CPRefCountZero:
+		code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+		code = append(code, byte(in.value), 0)
+	case opcodes.CPRefZeroZero:
+		code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+		code = append(code, 0, 0)
+	case opcodes.CPRefDims:
+		code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+		code = append(code, byte(in.value))
+	case opcodes.Local:
+		if in.wide {
+			code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+		} else {
+			code = append(code, byte(in.index))
+		}
+	case opcodes.LocalByte:
+		if in.wide {
+			code = binary.BigEndian.AppendUint16(code, uint16(in.index))
+			code = binary.BigEndian.AppendUint16(code, uint16(in.value))
+		} else {
+			code = append(code, byte(in.index), byte(in.value))
+		}
+	case opcodes.Branch:
+		offset := in.target.pc - in.pc
+		if offset < math.MinInt16 || offset > math.MaxInt16 {
+			methodBuilder.fail("branch to %s is too far for %s; use goto_w", in.target.name, opcodes.Name(in.opcode))
+		}
+		code = binary.BigEndian.AppendUint16(code, uint16(int16(offset)))
+	case opcodes.BranchWide:
+		code = binary.BigEndian.AppendUint32(code, uint32(int32(in.target.pc-in.pc)))
+	case opcodes.TableSwitch, opcodes.LookupSwitch:
+		for i := 0; i < padding(in.pc); i++ {
+			code = append(code, 0)
+		}
+		code = binary.BigEndian.AppendUint32(code, uint32(int32(in.dflt.pc-in.pc)))
+		if in.opcode == opcodes.Tableswitch {
+			high := in.low + int32(len(in.targets)) - 1
+			code = binary.BigEndian.AppendUint32(code, uint32(in.low))
+			code = binary.BigEndian.AppendUint32(code, uint32(high))
+			for _, target := range in.targets {
+				code = binary.BigEndian.AppendUint32(code, uint32(int32(target.pc-in.pc)))
+			}
+		} else {
+			code = binary.BigEndian.AppendUint32(code, uint32(len(in.keys)))
+			for i, key := range in.keys {
+				code = binary.BigEndian.AppendUint32(code, uint32(key))
+				code = binary.BigEndian.AppendUint32(code, uint32(int32(in.targets[i].pc-in.pc)))
+			}
+		}
+	}
+	return code
+}
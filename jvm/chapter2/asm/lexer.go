@@ -0,0 +1,124 @@
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// token 一行中的一个词；带引号的字符串已经去掉引号并处理了转义
+type token struct {
+	text   string
+	quoted bool
+}
+
+// splitLine 按空白切分一行源码
+// 分号只有出现在词首时才表示注释，这样描述符里的分号（Ljava/lang/String;）不受影响
+func splitLine(line string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case c == ';':
+			return tokens, nil
+		case c == '"':
+			str, n, err := readQuoted(line[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{str, true})
+			i += n
+		default:
+			start := i
+			for i < len(line) && !isSpace(line[i]) {
+				i++
+			}
+			tokens = append(tokens, token{line[start:i], false})
+		}
+	}
+	return tokens, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\f'
+}
+
+// readQuoted 读一个带引号的字符串，返回内容和消耗的字节数
+// 支持Java的转义：\n \t \r \b \f \" \' \\ \uXXXX 和八进制
+func readQuoted(s string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); {
+		c := s[i]
+		switch c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			switch e := s[i]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case '"', '\'', '\\':
+				sb.WriteByte(e)
+			case 'u':
+				if i+5 > len(s) {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid unicode escape \\u%s", s[i+1:i+5])
+				}
+				i += 4
+				// 增补字符写成两个\u转义的代理对
+				if utf16.IsSurrogate(rune(code)) {
+					if i+7 > len(s) || s[i+1:i+3] != "\\u" {
+						return "", 0, fmt.Errorf("unpaired surrogate \\u%04X", code)
+					}
+					low, err := strconv.ParseUint(s[i+3:i+7], 16, 16)
+					r := utf16.DecodeRune(rune(code), rune(low))
+					if err != nil || r == utf8.RuneError {
+						return "", 0, fmt.Errorf("unpaired surrogate \\u%04X", code)
+					}
+					sb.WriteRune(r)
+					i += 6
+				} else {
+					sb.WriteRune(rune(code))
+				}
+			default:
+				if e < '0' || e > '7' {
+					return "", 0, fmt.Errorf("invalid escape \\%c", e)
+				}
+				j := i
+				for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+					j++
+				}
+				code, _ := strconv.ParseUint(s[i:j], 8, 16)
+				if code > 0xFF {
+					return "", 0, fmt.Errorf("octal escape out of range")
+				}
+				sb.WriteRune(rune(code))
+				i = j - 1
+			}
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			sb.WriteRune(r)
+			i += size
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,300 @@
+package asm
+
+import (
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+type label struct {
+	name    string
+	pc      int
+	defined bool
+	line    int // 第一次引用或定义的行，报错用
+}
+
+type catchEntry struct {
+	start, end, handler *label
+	catchType           uint16
+	className           string // 空串表示捕获所有异常
+	line                int
+}
+
+type lineEntry struct {
+	pc, line int
+}
+
+type varEntry struct {
+	index            int
+	name, descriptor string
+	start, end       *label // 为nil时表示整个方法
+	line             int
+}
+
+// methodBuilder 收集一个方法的指令和指令，.end method时生成Code属性
+type methodBuilder struct {
+	assembler   *assembler
+	accessFlags uint16
+	name        string
+	descriptor  string
+	argSlots    int
+
+	maxStack, maxLocals int
+	hasMaxStack         bool
+	hasMaxLocals        bool
+
+	instrs      []*instr
+	pc          int
+	labels      map[string]*label
+	catches     []*catchEntry
+	lines       []lineEntry
+	pendingLine int
+	vars        []*varEntry
+	throws      []uint16
+	attributes  []classfile.AttributeInfo
+	switchInstr *instr // 正在读取case的tableswitch/lookupswitch
+}
+
+func newMethodBuilder(assembler *assembler, accessFlags uint16, name, descriptor string) *methodBuilder {
+	argSlots, err := signature.ArgSlotCount(descriptor)
+	if err != nil {
+		assembler.fail("invalid method descriptor %s: %v", descriptor, err)
+	}
+	if accessFlags&classfile.ACC_STATIC == 0 {
+		argSlots++
+	}
+	return &methodBuilder{
+		assembler:   assembler,
+		accessFlags: accessFlags,
+		name:        name,
+		descriptor:  descriptor,
+		argSlots:    argSlots,
+		labels:      map[string]*label{},
+		pendingLine: -1,
+	}
+}
+
+func (methodBuilder *methodBuilder) fail(format string, args ...interface{}) {
+	methodBuilder.assembler.fail(format, args...)
+}
+
+func (methodBuilder *methodBuilder) label(name string) *label {
+	l, ok := methodBuilder.labels[name]
+	if !ok {
+		l = &label{name: name, line: methodBuilder.assembler.line}
+		methodBuilder.labels[name] = l
+	}
+	return l
+}
+
+// statement 处理方法体中的一行：标签、指令或者方法内的伪指令
+func (methodBuilder *methodBuilder) statement(tokens []token) {
+	if methodBuilder.switchInstr != nil {
+		methodBuilder.switchCase(tokens)
+		return
+	}
+	// 行首的 Label: 定义标签，后面可以接指令
+	if first := tokens[0]; !first.quoted && len(first.text) > 1 && strings.HasSuffix(first.text, ":") {
+		l := methodBuilder.label(strings.TrimSuffix(first.text, ":"))
+		if l.defined {
+			methodBuilder.fail("duplicate label %s", l.name)
+		}
+		l.defined, l.pc = true, methodBuilder.pc
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return
+		}
+	}
+	if strings.HasPrefix(tokens[0].text, ".") {
+		methodBuilder.directive(tokens)
+		return
+	}
+	methodBuilder.instruction(tokens)
+}
+
+func (methodBuilder *methodBuilder) directive(tokens []token) {
+	assembler := methodBuilder.assembler
+	args := tokens[1:]
+	switch tokens[0].text {
+	case ".limit":
+		assembler.expectArgs(args, 2, 2)
+		n, err := strconv.ParseUint(args[1].text, 10, 16)
+		if err != nil {
+			methodBuilder.fail("invalid .limit value %q", args[1].text)
+		}
+		switch args[0].text {
+		case "stack":
+			methodBuilder.maxStack, methodBuilder.hasMaxStack = int(n), true
+		case "locals":
+			methodBuilder.maxLocals, methodBuilder.hasMaxLocals = int(n), true
+		default:
+			methodBuilder.fail("unknown .limit %s", args[0].text)
+		}
+	case ".line":
+		assembler.expectArgs(args, 1, 1)
+		n, err := strconv.ParseUint(args[0].text, 10, 16)
+		if err != nil {
+			methodBuilder.fail("invalid line number %q", args[0].text)
+		}
+		methodBuilder.pendingLine = int(n)
+	case ".catch":
+		// .catch <class|all> from L1 to L2 using L3
+		assembler.expectArgs(args, 7, 7)
+		if args[1].text != "from" || args[3].text != "to" || args[5].text != "using" {
+			methodBuilder.fail("expected .catch <class> from <label> to <label> using <label>")
+		}
+		entry := &catchEntry{
+			start:   methodBuilder.label(args[2].text),
+			end:     methodBuilder.label(args[4].text),
+			handler: methodBuilder.label(args[6].text),
+			line:    assembler.line,
+		}
+		if args[0].text != "all" {
+			entry.className = args[0].text
+			entry.catchType = assembler.cp.AddClass(args[0].text)
+		}
+		methodBuilder.catches = append(methodBuilder.catches, entry)
+	case ".var":
+		// .var <index> is <name> <descriptor> [from L1 to L2]
+		if len(args) != 4 && len(args) != 8 || args[1].text != "is" {
+			methodBuilder.fail("expected .var <index> is <name> <descriptor> [from <label> to <label>]")
+		}
+		index, err := strconv.ParseUint(args[0].text, 10, 16)
+		if err != nil {
+			methodBuilder.fail("invalid local variable index %q", args[0].text)
+		}
+		entry := &varEntry{index: int(index), name: args[2].text, descriptor: args[3].text, line: assembler.line}
+		if len(args) == 8 {
+			if args[4].text != "from" || args[6].text != "to" {
+				methodBuilder.fail("expected from <label> to <label>")
+			}
+			entry.start, entry.end = methodBuilder.label(args[5].text), methodBuilder.label(args[7].text)
+		}
+		methodBuilder.vars = append(methodBuilder.vars, entry)
+	case ".throws":
+		assembler.expectArgs(args, 1, 1)
+		methodBuilder.throws = append(methodBuilder.throws, assembler.cp.AddClass(args[0].text))
+	case ".signature":
+		assembler.expectArgs(args, 1, 1)
+		methodBuilder.attributes = append(methodBuilder.attributes, classfile.NewSignatureAttribute(assembler.cp, args[0].text))
+	case ".deprecated":
+		assembler.expectArgs(args, 0, 0)
+		methodBuilder.attributes = append(methodBuilder.attributes, classfile.NewDeprecatedAttribute())
+	case ".end":
+		assembler.expectArgs(args, 1, 1)
+		if args[0].text != "method" {
+			methodBuilder.fail("unexpected .end %s", args[0].text)
+		}
+		methodBuilder.end()
+	default:
+		methodBuilder.fail("unknown directive %s in method", tokens[0].text)
+	}
+}
+
+// 加一条指令，pc往后移
+func (methodBuilder *methodBuilder) add(in *instr) {
+	in.pc = methodBuilder.pc
+	in.line = methodBuilder.assembler.line
+	if methodBuilder.pendingLine >= 0 {
+		methodBuilder.lines = append(methodBuilder.lines, lineEntry{in.pc, methodBuilder.pendingLine})
+		methodBuilder.pendingLine = -1
+	}
+	methodBuilder.instrs = append(methodBuilder.instrs, in)
+	methodBuilder.pc += in.size()
+}
+
+func (methodBuilder *methodBuilder) end() {
+	assembler := methodBuilder.assembler
+	member := classfile.NewMemberInfo(assembler.cp, methodBuilder.accessFlags, methodBuilder.name, methodBuilder.descriptor)
+	var attributes []classfile.AttributeInfo
+	if len(methodBuilder.instrs) > 0 {
+		attributes = append(attributes, methodBuilder.codeAttribute())
+	} else if methodBuilder.accessFlags&(classfile.ACC_ABSTRACT|classfile.ACC_NATIVE) == 0 {
+		methodBuilder.fail("method %s%s has no code", methodBuilder.name, methodBuilder.descriptor)
+	}
+	if len(methodBuilder.throws) > 0 {
+		attributes = append(attributes, classfile.NewExceptionsAttribute(methodBuilder.throws))
+	}
+	attributes = append(attributes, methodBuilder.attributes...)
+	member.SetAttributes(attributes)
+	assembler.endMethod(member)
+}
+
+func (methodBuilder *methodBuilder) codeAttribute() *classfile.CodeAttribute {
+	assembler := methodBuilder.assembler
+	for _, l := range methodBuilder.labels {
+		if !l.defined {
+			assembler.line = l.line
+			methodBuilder.fail("undefined label %s", l.name)
+		}
+	}
+	endLine := assembler.line
+	code := make([]byte, 0, methodBuilder.pc)
+	for _, in := range methodBuilder.instrs {
+		assembler.line = in.line
+		code = in.encode(methodBuilder, code)
+	}
+
+	var exceptionTable []*classfile.ExceptionTableEntry
+	for _, c := range methodBuilder.catches {
+		assembler.line = c.line
+		if c.start.pc >= c.end.pc {
+			methodBuilder.fail(".catch range %s to %s is empty", c.start.name, c.end.name)
+		}
+		exceptionTable = append(exceptionTable, classfile.NewExceptionTableEntry(
+			uint16(c.start.pc), uint16(c.end.pc), uint16(c.handler.pc), c.catchType))
+	}
+	assembler.line = endLine
+
+	maxStack, maxLocals := methodBuilder.maxStack, methodBuilder.maxLocals
+	var frames *classfile.StackMapTableAttribute
+	if assembler.options.ComputeFrames || !methodBuilder.hasMaxStack || !methodBuilder.hasMaxLocals {
+		result := methodBuilder.analyze(code, exceptionTable)
+		if !methodBuilder.hasMaxStack {
+			maxStack = result.maxStack
+		}
+		if !methodBuilder.hasMaxLocals {
+			maxLocals = result.maxLocals
+		}
+		if assembler.options.ComputeFrames {
+			code, exceptionTable = result.code, result.exceptionTable
+			if len(result.frames) > 0 {
+				frames = classfile.NewStackMapTableAttribute(result.frames)
+			}
+		}
+	}
+
+	codeAttr := classfile.NewCodeAttribute(assembler.cp, uint16(maxStack), uint16(maxLocals), code)
+	codeAttr.SetExceptionTable(exceptionTable)
+	var attributes []classfile.AttributeInfo
+	if len(methodBuilder.lines) > 0 {
+		entries := make([]*classfile.LineNumberTableEntry, len(methodBuilder.lines))
+		for i, entry := range methodBuilder.lines {
+			entries[i] = classfile.NewLineNumberTableEntry(uint16(entry.pc), uint16(entry.line))
+		}
+		attributes = append(attributes, classfile.NewLineNumberTableAttribute(entries))
+	}
+	if len(methodBuilder.vars) > 0 {
+		entries := make([]*classfile.LocalVariableTableEntry, len(methodBuilder.vars))
+		for i, v := range methodBuilder.vars {
+			start, end := 0, len(code)
+			if v.start != nil {
+				start, end = v.start.pc, v.end.pc
+			}
+			if end < start {
+				assembler.line = v.line
+				methodBuilder.fail(".var range of %s ends before it starts", v.name)
+			}
+			entries[i] = classfile.NewLocalVariableTableEntry(uint16(start), uint16(end-start),
+				assembler.cp.AddUtf8(v.name), assembler.cp.AddUtf8(v.descriptor), uint16(v.index))
+		}
+		attributes = append(attributes, classfile.NewLocalVariableTableAttribute(entries))
+	}
+	if frames != nil {
+		attributes = append(attributes, frames)
+	}
+	codeAttr.SetAttributes(attributes)
+	return codeAttr
+}
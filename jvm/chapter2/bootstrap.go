@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Go的GOOS、GOARCH到Java的os.name、os.arch
+var (
+	javaOSNames   = map[string]string{"linux": "Linux", "darwin": "Mac OS X", "windows": "Windows"}
+	javaArchNames = map[string]string{"amd64": "amd64", "386": "i386", "arm64": "aarch64"}
+)
+
+// systemProperties 返回System.initProperties放进System.props的系统属性，和HotSpot提供的一样。
+// JDK 8的类库在启动时由Version.init等再补充java.version之类的属性。
+// 不支持字节码生成的反射访问器（它们继承MagicAccessorImpl绕过访问检查），
+// 所以把sun.reflect.inflationThreshold设成最大，一直用本地方法实现的访问器
+func systemProperties(cp *classpath.Classpath) map[string]string {
+	jreDir := cp.JreDir()
+	osName, arch := javaOSNames[runtime.GOOS], javaArchNames[runtime.GOARCH]
+	if osName == "" {
+		osName = runtime.GOOS
+	}
+	if arch == "" {
+		arch = runtime.GOARCH
+	}
+	bootJars, _ := filepath.Glob(filepath.Join(jreDir, "lib", "*.jar"))
+	userDir, _ := os.Getwd()
+	userHome, _ := os.UserHomeDir()
+	userName := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		userName = u.Username
+	}
+	osVersion := "unknown"
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		osVersion = strings.TrimSpace(string(release))
+	}
+	pathSeparator := string(os.PathListSeparator)
+	return map[string]string{
+		"java.home":                      jreDir,
+		"java.class.path":                cp.String(),
+		"java.class.version":             "52.0",
+		"java.ext.dirs":                  filepath.Join(jreDir, "lib", "ext"),
+		"java.io.tmpdir":                 os.TempDir(),
+		"java.library.path":              strings.Join([]string{"/usr/java/packages/lib/" + arch, "/usr/lib64", "/lib64", "/lib", "/usr/lib"}, pathSeparator),
+		"java.specification.name":        "Java Platform API Specification",
+		"java.specification.vendor":      "Oracle Corporation",
+		"java.specification.version":     "1.8",
+		"java.vm.info":                   "interpreted mode",
+		"java.vm.name":                   "jvm",
+		"java.vm.specification.name":     "Java Virtual Machine Specification",
+		"java.vm.specification.vendor":   "Oracle Corporation",
+		"java.vm.specification.version":  "1.8",
+		"java.vm.vendor":                 "go.buppt.cn",
+		"java.vm.version":                "0.0.1",
+		"file.encoding":                  "UTF-8",
+		"file.encoding.pkg":              "sun.io",
+		"file.separator":                 string(os.PathSeparator),
+		"line.separator":                 "\n",
+		"path.separator":                 pathSeparator,
+		"os.arch":                        arch,
+		"os.name":                        osName,
+		"os.version":                     osVersion,
+		"sun.arch.data.model":            strconv.Itoa(strconv.IntSize),
+		"sun.boot.class.path":            strings.Join(bootJars, pathSeparator),
+		"sun.boot.library.path":          filepath.Join(jreDir, "lib", arch),
+		"sun.cpu.endian":                 "little",
+		"sun.io.unicode.encoding":        "UnicodeLittle",
+		"sun.java.launcher":              "SUN_STANDARD",
+		"sun.jnu.encoding":               "UTF-8",
+		"sun.reflect.inflationThreshold": "2147483647",
+		"user.dir":                       userDir,
+		"user.home":                      userHome,
+		"user.language":                  "en",
+		"user.name":                      userName,
+	}
+}
+
+/*
+initVM 按HotSpot的顺序初始化JDK 8的类库：创建system和main线程组、代表main线程的Thread对象，
+执行System.initializeSystemClass（创建System.out等），然后调用ClassLoader.getSystemClassLoader，
+让平台和应用类加载器使用sun.misc.Launcher创建的加载器对象。
+类库里没有initializeSystemClass时（比如只有几个类的简化类库）什么也不做。
+初始化失败时像HotSpot一样打印错误，返回false
+*/
+func initVM(thread *rtda.Thread, loader *heap.ClassLoader) (ok bool) {
+	boot := loader.Bootstrap()
+	systemClass := boot.FindClass(thread, "java/lang/System")
+	if systemClass == nil || systemClass.GetStaticMethod("initializeSystemClass", "()V") == nil {
+		return true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintln(os.Stderr, "Error occurred during initialization of VM")
+			if ex := base.ToThrowable(thread, r); ex != nil {
+				printStackTrace(ex)
+			} else {
+				fmt.Fprintln(os.Stderr, r)
+			}
+			ok = false
+		}
+	}()
+	for _, name := range []string{"java/lang/String", "java/lang/System", "java/lang/ThreadGroup"} {
+		base.InitClass(thread, boot.LoadClass(thread, name))
+	}
+	threadGroupClass := boot.LoadClass(thread, "java/lang/ThreadGroup")
+	systemGroup := base.NewObject(thread, threadGroupClass, "()V")
+	mainGroup := base.NewObject(thread, threadGroupClass, "(Ljava/lang/ThreadGroup;Ljava/lang/String;)V",
+		systemGroup, heap.JString(thread, boot, "main"))
+	createMainThread(thread, boot, mainGroup)
+	for _, name := range []string{"java/lang/Class", "java/lang/reflect/Method", "java/lang/ref/Finalizer"} {
+		base.InitClass(thread, boot.LoadClass(thread, name))
+	}
+	base.RunMethod(thread, systemClass.GetStaticMethod("initializeSystemClass", "()V"))
+
+	classLoaderClass := boot.LoadClass(thread, "java/lang/ClassLoader")
+	if getSystemClassLoader := classLoaderClass.GetStaticMethod("getSystemClassLoader", "()Ljava/lang/ClassLoader;"); getSystemClassLoader != nil {
+		base.InitClass(thread, classLoaderClass)
+		systemLoader := base.RunMethod(thread, getSystemClassLoader).PopRef()
+		if systemLoader != nil {
+			loader.BindJavaLoader(thread, systemLoader)
+			loader.Parent().BindJavaLoader(thread, systemLoader.GetRefVar("parent", "Ljava/lang/ClassLoader;"))
+		}
+	}
+	return true
+}
+
+// createMainThread 和HotSpot一样在执行构造函数之前把Thread对象和线程关联起来，
+// 并设置优先级：构造函数里的Thread.currentThread()返回的就是它自己
+func createMainThread(thread *rtda.Thread, boot *heap.ClassLoader, group *heap.Object) {
+	threadClass := boot.LoadClass(thread, "java/lang/Thread")
+	base.InitClass(thread, threadClass)
+	jThread := threadClass.NewObject()
+	jThread.SetIntVar("priority", "I", 5) // Thread.NORM_PRIORITY
+	thread.SetJThread(jThread)
+	base.RunConstructor(thread, jThread, "(Ljava/lang/ThreadGroup;Ljava/lang/String;)V", group, heap.JString(thread, boot, "main"))
+	thread.SetStatus(rtda.ThreadRunnable)
+}
+
+// shutdown 像HotSpot的DestroyJavaVM一样执行Shutdown.shutdown，运行关闭钩子；
+// 类库里没有这个方法时什么也不做，钩子抛出的异常忽略
+func shutdown(thread *rtda.Thread, loader *heap.ClassLoader) {
+	shutdownClass := loader.Bootstrap().FindClass(thread, "java/lang/Shutdown")
+	if shutdownClass == nil {
+		return
+	}
+	method := shutdownClass.GetStaticMethod("shutdown", "()V")
+	if method == nil {
+		return
+	}
+	defer func() {
+		recover()
+	}()
+	base.InitClass(thread, shutdownClass)
+	base.RunMethod(thread, method)
+}
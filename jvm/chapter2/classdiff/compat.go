@@ -0,0 +1,299 @@
+package classdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// checker 比较同一个类的两个版本，按JLS第13章判断哪些变化破坏二进制兼容
+// 只有public和protected的成员算作API，其他成员的变化不报告
+type checker struct {
+	oldSide, newSide   *side
+	oldClass, newClass *classfile.ClassFile
+	changes            []*Change
+}
+
+func (checker *checker) add(change *Change) {
+	checker.changes = append(checker.changes, change)
+}
+
+// 破坏兼容的变化只对public类报告，非public的类本来就不能被其他包里的代码使用
+func (checker *checker) breaks(change *Change, reason string) {
+	if isPublic(checker.oldClass.AccessFlags()) {
+		change.Breaking = true
+	}
+	change.Reason = reason
+	checker.add(change)
+}
+
+func (checker *checker) compare() {
+	checker.compareClassFlags()
+	checker.compareSupertypes()
+	checker.compareFields()
+	checker.compareMethods()
+}
+
+func (checker *checker) compareClassFlags() {
+	oldClass, newClass := checker.oldClass, checker.newClass
+	oldFlags, newFlags := oldClass.AccessFlags(), newClass.AccessFlags()
+	if classKind(oldFlags) != classKind(newFlags) {
+		change := &Change{Kind: ClassKind, Old: classKind(oldFlags), New: classKind(newFlags)}
+		if (oldFlags^newFlags)&classfile.ACC_INTERFACE != 0 {
+			checker.breaks(change, "JLS 13.4.1, 13.5.1: clients get IncompatibleClassChangeError")
+		} else {
+			checker.add(change)
+		}
+	}
+	// 类的种类上面已经比较过了
+	const ignored = classfile.ACC_SUPER | classfile.ACC_INTERFACE | classfile.ACC_ANNOTATION | classfile.ACC_ENUM
+	if oldFlags&^ignored != newFlags&^ignored {
+		change := &Change{Kind: ClassFlags, Old: classFlagString(oldFlags), New: classFlagString(newFlags)}
+		added := newFlags &^ oldFlags
+		switch {
+		case isPublic(oldFlags) && !isPublic(newFlags):
+			checker.breaks(change, "JLS 13.4.3: clients in other packages get IllegalAccessError")
+		case added&classfile.ACC_ABSTRACT != 0 && newFlags&classfile.ACC_INTERFACE == 0:
+			checker.breaks(change, "JLS 13.4.1: creating instances fails with InstantiationError")
+		case added&classfile.ACC_FINAL != 0:
+			checker.breaks(change, "JLS 13.4.2: existing subclasses get VerifyError")
+		default:
+			checker.add(change)
+		}
+	}
+	if oldClass.MajorVersion() != newClass.MajorVersion() || oldClass.MinorVersion() != newClass.MinorVersion() {
+		change := &Change{Kind: ClassVersion,
+			Old: fmt.Sprintf("%d.%d", oldClass.MajorVersion(), oldClass.MinorVersion()),
+			New: fmt.Sprintf("%d.%d", newClass.MajorVersion(), newClass.MinorVersion())}
+		if newClass.MajorVersion() > oldClass.MajorVersion() {
+			change.Reason = "older JVMs reject the class with UnsupportedClassVersionError"
+		}
+		checker.add(change)
+	}
+	if oldSig, newSig := classSignature(oldClass), classSignature(newClass); oldSig != newSig {
+		checker.add(&Change{Kind: ClassSignature, Old: oldSig, New: newSig})
+	}
+}
+
+// 超类和超接口按整个继承链比较：类从继承链上去掉的类型不能再当作那个类型使用
+func (checker *checker) compareSupertypes() {
+	className := checker.oldClass.ClassName()
+	if oldSuper, newSuper := checker.oldClass.SuperClassName(), checker.newClass.SuperClassName(); oldSuper != newSuper {
+		checker.add(&Change{Kind: Superclass, Old: oldSuper, New: newSuper})
+	}
+	oldTypes, newTypes := checker.oldSide.supertypes(className), checker.newSide.supertypes(className)
+	for _, supertype := range sortedKeys(oldTypes) {
+		if !newTypes[supertype] {
+			checker.breaks(&Change{Kind: SupertypeRemoved, Old: supertype},
+				"JLS 13.4.4: clients using the class as "+supertype+" get VerifyError or ClassCastException")
+		}
+	}
+	for _, supertype := range sortedKeys(newTypes) {
+		if !oldTypes[supertype] {
+			checker.add(&Change{Kind: SupertypeAdded, New: supertype})
+		}
+	}
+}
+
+func (checker *checker) compareFields() {
+	newFields := map[string]*classfile.MemberInfo{}
+	for _, field := range checker.newClass.Fields() {
+		newFields[field.Name()] = field
+	}
+	oldAPI := map[string]bool{}
+	for _, oldField := range checker.oldClass.Fields() {
+		if !isAPI(oldField.AccessFlags()) {
+			continue
+		}
+		name := oldField.Name()
+		oldAPI[name] = true
+		newField := newFields[name]
+		if newField == nil {
+			checker.breaks(&Change{Kind: FieldRemoved, Member: name, Old: fieldString(oldField)},
+				"JLS 13.4.8: clients get NoSuchFieldError")
+			continue
+		}
+		checker.compareField(name, oldField, newField)
+	}
+	for _, newField := range checker.newClass.Fields() {
+		if isAPI(newField.AccessFlags()) && !oldAPI[newField.Name()] {
+			checker.add(&Change{Kind: FieldAdded, Member: newField.Name(), New: fieldString(newField)})
+		}
+	}
+}
+
+func (checker *checker) compareField(name string, oldField, newField *classfile.MemberInfo) {
+	if oldField.Descriptor() != newField.Descriptor() {
+		checker.breaks(&Change{Kind: FieldType, Member: name, Old: oldField.Descriptor(), New: newField.Descriptor()},
+			"JLS 13.4.8: the field is looked up by type, clients get NoSuchFieldError")
+	}
+	oldFlags, newFlags := oldField.AccessFlags(), newField.AccessFlags()
+	if oldFlags != newFlags {
+		change := &Change{Kind: FieldFlags, Member: name, Old: fieldFlagString(oldFlags), New: fieldFlagString(newFlags)}
+		switch {
+		case accessRank(newFlags) < accessRank(oldFlags):
+			checker.breaks(change, "JLS 13.4.7: clients get IllegalAccessError")
+		case (oldFlags^newFlags)&classfile.ACC_STATIC != 0:
+			checker.breaks(change, "JLS 13.4.10: clients get IncompatibleClassChangeError")
+		case (newFlags&^oldFlags)&classfile.ACC_FINAL != 0:
+			checker.breaks(change, "JLS 13.4.9: clients assigning the field get IllegalAccessError")
+		default:
+			checker.add(change)
+		}
+	}
+	if oldSig, newSig := memberSignature(oldField), memberSignature(newField); oldSig != newSig {
+		checker.add(&Change{Kind: FieldSignature, Member: name, Old: oldSig, New: newSig})
+	}
+	oldValue := constantValue(checker.oldClass, oldField)
+	newValue := constantValue(checker.newClass, newField)
+	if oldValue != newValue {
+		change := &Change{Kind: FieldConstant, Member: name, Old: oldValue, New: newValue}
+		if oldValue != "" {
+			change.Reason = "JLS 13.4.9: clients compiled against the old class keep the inlined value " + oldValue
+		}
+		checker.add(change)
+	}
+}
+
+func methodKey(method *classfile.MemberInfo) string {
+	return method.Name() + method.Descriptor()
+}
+
+func (checker *checker) compareMethods() {
+	newMethods := map[string]*classfile.MemberInfo{}
+	for _, method := range checker.newClass.Methods() {
+		newMethods[methodKey(method)] = method
+	}
+	oldAPI := map[string]bool{}
+	for _, oldMethod := range checker.oldClass.Methods() {
+		if !isAPI(oldMethod.AccessFlags()) {
+			continue
+		}
+		key := methodKey(oldMethod)
+		oldAPI[key] = true
+		if newMethod := newMethods[key]; newMethod != nil {
+			checker.compareMethod(key, oldMethod, newMethod)
+			continue
+		}
+		change := &Change{Kind: MethodRemoved, Member: key, Old: methodString(oldMethod)}
+		// 从超类继承来的同名方法可以代替被删除的方法，构造器和静态方法除外
+		name := oldMethod.Name()
+		if name != "<init>" && name != "<clinit>" && oldMethod.AccessFlags()&classfile.ACC_STATIC == 0 {
+			if declaringClass := checker.newSide.inheritedMethod(checker.newClass.ClassName(), name, oldMethod.Descriptor()); declaringClass != "" {
+				change.Reason = "JLS 13.4.12: still inherited from " + declaringClass
+				checker.add(change)
+				continue
+			}
+		}
+		checker.breaks(change, "JLS 13.4.12: clients get NoSuchMethodError")
+	}
+	isInterface := checker.newClass.AccessFlags()&classfile.ACC_INTERFACE != 0
+	for _, newMethod := range checker.newClass.Methods() {
+		flags := newMethod.AccessFlags()
+		if !isAPI(flags) || oldAPI[methodKey(newMethod)] {
+			continue
+		}
+		change := &Change{Kind: MethodAdded, Member: methodKey(newMethod), New: methodString(newMethod)}
+		if flags&classfile.ACC_ABSTRACT != 0 {
+			if isInterface {
+				change.Reason = "JLS 13.5.3: existing implementations get AbstractMethodError when it is invoked"
+			} else {
+				change.Reason = "JLS 13.4.16: existing subclasses get AbstractMethodError when it is invoked"
+			}
+		}
+		checker.add(change)
+	}
+}
+
+func (checker *checker) compareMethod(key string, oldMethod, newMethod *classfile.MemberInfo) {
+	oldFlags, newFlags := oldMethod.AccessFlags(), newMethod.AccessFlags()
+	if oldFlags != newFlags {
+		change := &Change{Kind: MethodFlags, Member: key, Old: methodFlagString(oldFlags), New: methodFlagString(newFlags)}
+		added := newFlags &^ oldFlags
+		classFlags := checker.newClass.AccessFlags()
+		switch {
+		case accessRank(newFlags) < accessRank(oldFlags):
+			checker.breaks(change, "JLS 13.4.7: clients get IllegalAccessError")
+		case (oldFlags^newFlags)&classfile.ACC_STATIC != 0:
+			checker.breaks(change, "JLS 13.4.19: clients get IncompatibleClassChangeError")
+		case added&classfile.ACC_ABSTRACT != 0 && classFlags&classfile.ACC_INTERFACE != 0:
+			checker.breaks(change, "JLS 13.5.6: implementations relying on the default method get AbstractMethodError")
+		case added&classfile.ACC_ABSTRACT != 0:
+			checker.breaks(change, "JLS 13.4.16: clients invoking the method get AbstractMethodError")
+		case added&classfile.ACC_FINAL != 0 && newFlags&classfile.ACC_STATIC == 0 && classFlags&classfile.ACC_FINAL == 0:
+			checker.breaks(change, "JLS 13.4.17: existing overriding methods get VerifyError")
+		default:
+			checker.add(change)
+		}
+	}
+	if oldSig, newSig := memberSignature(oldMethod), memberSignature(newMethod); oldSig != newSig {
+		checker.add(&Change{Kind: MethodSignature, Member: key, Old: oldSig, New: newSig})
+	}
+	oldThrows, newThrows := exceptions(checker.oldClass, oldMethod), exceptions(checker.newClass, newMethod)
+	if oldThrows != newThrows {
+		checker.add(&Change{Kind: MethodExceptions, Member: key, Old: oldThrows, New: newThrows,
+			Reason: "JLS 13.4.21: throws clauses are not checked at link time"})
+	}
+}
+
+func classSignature(cf *classfile.ClassFile) string {
+	for _, attrInfo := range cf.Attributes() {
+		if attr, ok := attrInfo.(*classfile.SignatureAttribute); ok {
+			return attr.Signature()
+		}
+	}
+	return ""
+}
+
+func memberSignature(member *classfile.MemberInfo) string {
+	if attr := member.SignatureAttribute(); attr != nil {
+		return attr.Signature()
+	}
+	return ""
+}
+
+// constantValue 返回字段ConstantValue属性的值，没有时返回空串
+func constantValue(cf *classfile.ClassFile, field *classfile.MemberInfo) string {
+	attr := field.ConstantValueAttribute()
+	if attr == nil {
+		return ""
+	}
+	switch c := cf.ConstantPool().GetConstantInfo(attr.ConstantValueIndex()).(type) {
+	case *classfile.ConstantIntegerInfo:
+		return fmt.Sprint(c.Value())
+	case *classfile.ConstantLongInfo:
+		return fmt.Sprint(c.Value())
+	case *classfile.ConstantFloatInfo:
+		return fmt.Sprint(c.Value())
+	case *classfile.ConstantDoubleInfo:
+		return fmt.Sprint(c.Value())
+	case *classfile.ConstantStringInfo:
+		return fmt.Sprintf("%q", c.String())
+	}
+	return ""
+}
+
+// exceptions 返回throws的类名，排好序用逗号分隔
+func exceptions(cf *classfile.ClassFile, method *classfile.MemberInfo) string {
+	attr := method.ExceptionsAttribute()
+	if attr == nil {
+		return ""
+	}
+	var names []string
+	for _, index := range attr.ExceptionIndexTable() {
+		names = append(names, cf.ConstantPool().GetClassName(index))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package classdiff
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/classpath"
+)
+
+// Kind 变化的种类
+type Kind string
+
+const (
+	ClassAdded       Kind = "class-added"
+	ClassRemoved     Kind = "class-removed"
+	ClassFlags       Kind = "class-flags"
+	ClassKind        Kind = "class-kind" // 类、接口、注解、枚举之间的变化
+	Superclass       Kind = "superclass"
+	SupertypeAdded   Kind = "supertype-added"
+	SupertypeRemoved Kind = "supertype-removed"
+	ClassSignature   Kind = "class-signature"
+	ClassVersion     Kind = "class-version"
+	FieldAdded       Kind = "field-added"
+	FieldRemoved     Kind = "field-removed"
+	FieldType        Kind = "field-type"
+	FieldFlags       Kind = "field-flags"
+	FieldSignature   Kind = "field-signature"
+	FieldConstant    Kind = "field-constant"
+	MethodAdded      Kind = "method-added"
+	MethodRemoved    Kind = "method-removed"
+	MethodFlags      Kind = "method-flags"
+	MethodSignature  Kind = "method-signature"
+	MethodExceptions Kind = "method-exceptions"
+)
+
+// Change 一处变化；Breaking表示按JLS第13章会破坏已编译的客户代码，
+// Reason给出JLS的章节和原因，不破坏兼容但值得注意的变化也可能有Reason
+type Change struct {
+	Kind     Kind   `json:"kind"`
+	Member   string `json:"member,omitempty"` // 字段是名字，方法是名字加描述符
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	Breaking bool   `json:"breaking"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// 类的状态
+const (
+	StatusAdded   = "added"
+	StatusRemoved = "removed"
+	StatusChanged = "changed"
+)
+
+// ClassDiff 一个类的全部变化
+type ClassDiff struct {
+	ClassName string    `json:"class"`
+	Status    string    `json:"status"`
+	Changes   []*Change `json:"changes"`
+}
+
+// Report 两个类路径的比较结果，只包含有变化的类
+type Report struct {
+	Old      string       `json:"old"`
+	New      string       `json:"new"`
+	Classes  []*ClassDiff `json:"classes"`
+	Breaking int          `json:"breaking"` // 破坏二进制兼容的变化数
+}
+
+// Compare 比较两个类路径上的类；classNames为空时比较两边用户类路径上的所有类
+// 被比较的类只从用户类路径读；超类和接口沿继承链查找时才用到JRE的类，没有JRE时继承链在JRE的类处中断
+func Compare(oldCp, newCp *classpath.Classpath, classNames []string) (*Report, error) {
+	if len(classNames) == 0 {
+		var err error
+		if classNames, err = allClassNames(oldCp, newCp); err != nil {
+			return nil, err
+		}
+	}
+	report := &Report{Old: oldCp.String(), New: newCp.String()}
+	oldSide, newSide := newSide(oldCp), newSide(newCp)
+	for _, className := range classNames {
+		classDiff, err := compareClass(oldSide, newSide, className)
+		if err != nil {
+			return nil, err
+		}
+		if classDiff == nil {
+			continue
+		}
+		report.Classes = append(report.Classes, classDiff)
+		for _, change := range classDiff.Changes {
+			if change.Breaking {
+				report.Breaking++
+			}
+		}
+	}
+	return report, nil
+}
+
+func allClassNames(oldCp, newCp *classpath.Classpath) ([]string, error) {
+	oldNames, err := oldCp.UserClassNames()
+	if err != nil {
+		return nil, err
+	}
+	newNames, err := newCp.UserClassNames()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, className := range append(oldNames, newNames...) {
+		seen[className] = true
+	}
+	classNames := make([]string, 0, len(seen))
+	for className := range seen {
+		classNames = append(classNames, className)
+	}
+	sort.Strings(classNames)
+	return classNames, nil
+}
+
+// side 比较的一边：类路径，以及查找超类时用到的ClassView缓存
+type side struct {
+	cp    *classpath.Classpath
+	views map[string]*classfile.ClassView // 找不到的类也缓存为nil
+}
+
+func newSide(cp *classpath.Classpath) *side {
+	return &side{cp: cp, views: map[string]*classfile.ClassView{}}
+}
+
+// view 先在用户类路径上找，和parse读到的是同一个类，找不到再到JRE里找
+func (side *side) view(className string) *classfile.ClassView {
+	if view, ok := side.views[className]; ok {
+		return view
+	}
+	var view *classfile.ClassView
+	data, _, err := side.cp.ReadClassFrom(classpath.User, className)
+	if err != nil {
+		data, _, err = side.cp.ReadClass(className)
+	}
+	if err == nil {
+		view, _ = classfile.NewClassView(data)
+	}
+	side.views[className] = view
+	return view
+}
+
+// supertypes 返回所有的超类和超接口；继承链上找不到的类只记下名字，不再往上找
+func (side *side) supertypes(className string) map[string]bool {
+	supertypes := map[string]bool{}
+	queue := []string{className}
+	for len(queue) > 0 {
+		view := side.view(queue[0])
+		queue = queue[1:]
+		if view == nil {
+			continue
+		}
+		for _, name := range append([]string{view.SuperClassName()}, view.InterfaceNames()...) {
+			if name != "" && !supertypes[name] {
+				supertypes[name] = true
+				queue = append(queue, name)
+			}
+		}
+	}
+	return supertypes
+}
+
+// inheritedMethod 在超类和超接口中找非private的同名同描述符方法，返回声明它的类
+func (side *side) inheritedMethod(className, name, descriptor string) string {
+	supertypes := side.supertypes(className)
+	names := make([]string, 0, len(supertypes))
+	for supertype := range supertypes {
+		names = append(names, supertype)
+	}
+	sort.Strings(names)
+	for _, supertype := range names {
+		if view := side.view(supertype); view != nil {
+			if method, ok := view.Method(name, descriptor); ok && method.AccessFlags()&classfile.ACC_PRIVATE == 0 {
+				return supertype
+			}
+		}
+	}
+	return ""
+}
+
+// parse 读用户类路径上的类，JRE里的同名类不算；找不到时返回nil
+func (side *side) parse(className string) (*classfile.ClassFile, error) {
+	data, _, err := side.cp.ReadClassFrom(classpath.User, className)
+	if err != nil {
+		return nil, nil
+	}
+	cf, err := classfile.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", className, err)
+	}
+	return cf, nil
+}
+
+// compareClass 没有变化时返回nil
+func compareClass(oldSide, newSide *side, className string) (*ClassDiff, error) {
+	oldClass, err := oldSide.parse(className)
+	if err != nil {
+		return nil, fmt.Errorf("old classpath: %v", err)
+	}
+	newClass, err := newSide.parse(className)
+	if err != nil {
+		return nil, fmt.Errorf("new classpath: %v", err)
+	}
+	classDiff := &ClassDiff{ClassName: className, Status: StatusChanged}
+	switch {
+	case oldClass == nil && newClass == nil:
+		return nil, errors.New("class not found in either classpath: " + className)
+	case oldClass == nil:
+		classDiff.Status = StatusAdded
+		classDiff.Changes = []*Change{{Kind: ClassAdded, New: classFlagString(newClass.AccessFlags())}}
+	case newClass == nil:
+		classDiff.Status = StatusRemoved
+		change := &Change{Kind: ClassRemoved, Old: classFlagString(oldClass.AccessFlags())}
+		if isPublic(oldClass.AccessFlags()) {
+			change.Breaking, change.Reason = true, "JLS 13.4: clients linked against the class get NoClassDefFoundError"
+		}
+		classDiff.Changes = []*Change{change}
+	default:
+		checker := &checker{oldSide: oldSide, newSide: newSide, oldClass: oldClass, newClass: newClass}
+		checker.compare()
+		if len(checker.changes) == 0 {
+			return nil, nil
+		}
+		classDiff.Changes = checker.changes
+	}
+	return classDiff, nil
+}
@@ -0,0 +1,191 @@
+package classdiff
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+/*
+testdata/old和testdata/new是同一组类的两个版本，每个类对应JLS第13章的一条或几条规则。
+类汇编到MemoryEntry里，比较时不需要JRE，继承链在java/lang/Object处中断
+*/
+func compareTestdata(t *testing.T) *Report {
+	t.Helper()
+	t.Setenv("JAVA_HOME", "")
+	report, err := Compare(testClasspath(t, "", "old"), testClasspath(t, "", "new"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.Old, report.New = "old", "new" // 类路径里有临时目录
+	return report
+}
+
+// testClasspath 用户类路径上是testdata下version目录里的类，jreOption为空时没有JRE
+func testClasspath(t *testing.T, jreOption, version string) *classpath.Classpath {
+	t.Helper()
+	entry := classpath.NewMemoryEntry(version)
+	for className, data := range assemble(t, filepath.Join("testdata", version)) {
+		entry.Add(className, data)
+	}
+	cp := classpath.ParseOptionalJre(jreOption, t.TempDir())
+	cp.AddEntry(entry)
+	return cp
+}
+
+func assemble(t *testing.T, srcDir string) map[string][]byte {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(srcDir, "*.j"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no .j files in %s: %v", srcDir, err)
+	}
+	classes := map[string][]byte{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		className, data, err := asm.Assemble(file, source, asm.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes[className] = data
+	}
+	return classes
+}
+
+func TestCompat(t *testing.T) {
+	report := compareTestdata(t)
+	changes := map[string]*Change{}
+	for _, classDiff := range report.Classes {
+		for _, change := range classDiff.Changes {
+			changes[classDiff.ClassName+" "+string(change.Kind)+" "+change.Member] = change
+		}
+	}
+	tests := []struct {
+		class    string
+		kind     Kind
+		member   string
+		breaking bool
+		reason   string // Reason的前缀
+	}{
+		{"api/Removed", ClassRemoved, "", true, "JLS 13.4:"},
+		{"api/Hidden", ClassRemoved, "", false, ""},
+		{"api/Added", ClassAdded, "", false, ""},
+		{"api/KindChange", ClassKind, "", true, "JLS 13.4.1, 13.5.1:"},
+		{"api/Narrowed", ClassFlags, "", true, "JLS 13.4.3:"},
+		{"api/MadeAbstract", ClassFlags, "", true, "JLS 13.4.1:"},
+		{"api/MadeFinal", ClassFlags, "", true, "JLS 13.4.2:"},
+		{"api/LostSupertype", Superclass, "", false, ""},
+		{"api/LostSupertype", SupertypeRemoved, "", true, "JLS 13.4.4:"},
+		{"api/Fields", ClassVersion, "", false, "older JVMs"},
+		{"api/Fields", FieldRemoved, "removed", true, "JLS 13.4.8:"},
+		{"api/Fields", FieldType, "retyped", true, "JLS 13.4.8:"},
+		{"api/Fields", FieldFlags, "narrowed", true, "JLS 13.4.7:"},
+		{"api/Fields", FieldFlags, "madeStatic", true, "JLS 13.4.10:"},
+		{"api/Fields", FieldFlags, "madeFinal", true, "JLS 13.4.9:"},
+		{"api/Fields", FieldConstant, "LIMIT", false, "JLS 13.4.9:"},
+		{"api/Fields", FieldAdded, "added", false, ""},
+		{"api/Methods", MethodRemoved, "removed()V", true, "JLS 13.4.12: clients get NoSuchMethodError"},
+		{"api/Methods", MethodRemoved, "describe()Ljava/lang/String;", false, "JLS 13.4.12: still inherited from api/Base"},
+		{"api/Methods", MethodFlags, "narrowed()V", true, "JLS 13.4.7:"},
+		{"api/Methods", MethodFlags, "madeStatic()V", true, "JLS 13.4.19:"},
+		{"api/Methods", MethodFlags, "madeAbstract()V", true, "JLS 13.4.16:"},
+		{"api/Methods", MethodFlags, "madeFinal()V", true, "JLS 13.4.17:"},
+		{"api/Methods", MethodExceptions, "io()V", false, "JLS 13.4.21:"},
+		{"api/Methods", MethodAdded, "added()V", false, "JLS 13.4.16:"},
+		{"api/Defaults", MethodFlags, "run()V", true, "JLS 13.5.6:"},
+		{"api/Defaults", MethodAdded, "stop()V", false, "JLS 13.5.3:"},
+	}
+	for _, test := range tests {
+		key := test.class + " " + string(test.kind) + " " + test.member
+		change := changes[key]
+		if change == nil {
+			t.Errorf("%s: not reported", key)
+			continue
+		}
+		if change.Breaking != test.breaking || !strings.HasPrefix(change.Reason, test.reason) {
+			t.Errorf("%s: breaking = %v, reason %q; want %v, %q", key, change.Breaking, change.Reason, test.breaking, test.reason)
+		}
+	}
+	// private字段的变化不报告，没变的类不出现
+	for key := range changes {
+		if strings.Contains(key, "internal") || strings.HasPrefix(key, "api/Base ") || strings.HasPrefix(key, "api/Marker ") {
+			t.Errorf("unexpected change %s", key)
+		}
+	}
+	if report.Breaking != 18 {
+		t.Errorf("Breaking = %d, want 18", report.Breaking)
+	}
+}
+
+// 文本和JSON的输出和testdata下的golden文件逐字节比较；改了规则或格式之后用 go test -update 重新生成
+func TestOutput(t *testing.T) {
+	report := compareTestdata(t)
+	for golden, write := range map[string]func(*bytes.Buffer) error{
+		"diff.txt":  func(out *bytes.Buffer) error { return report.WriteText(out) },
+		"diff.json": func(out *bytes.Buffer) error { return report.WriteJSON(out) },
+	} {
+		var out bytes.Buffer
+		if err := write(&out); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join("testdata", golden)
+		if *update {
+			if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), want) {
+			t.Errorf("output differs from %s:\n%s", path, out.String())
+		}
+	}
+}
+
+// 被比较的类只从用户类路径读：JRE里有旧版本的同名类时，比较的仍然是用户类路径上的两个版本，
+// 新类路径上删掉的类也不会因为JRE里还有而被当作没变
+func TestUserLayerOnly(t *testing.T) {
+	oldClasses := assemble(t, filepath.Join("testdata", "old"))
+	jreDir := filepath.Join(t.TempDir(), "jre")
+	if err := os.MkdirAll(filepath.Join(jreDir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := os.Create(filepath.Join(jreDir, "lib", "rt.jar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(jar)
+	for _, className := range []string{"api/Narrowed", "api/Removed"} {
+		f, err := w.Create(className + ".class")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(oldClasses[className])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	jar.Close()
+
+	oldCp, newCp := testClasspath(t, jreDir, "old"), testClasspath(t, jreDir, "new")
+	report, err := Compare(oldCp, newCp, []string{"api/Narrowed", "api/Removed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Classes) != 2 || report.Classes[0].Changes[0].Kind != ClassFlags || report.Classes[1].Status != StatusRemoved {
+		t.Errorf("got %d classes, want api/Narrowed changed and api/Removed removed", len(report.Classes))
+	}
+}
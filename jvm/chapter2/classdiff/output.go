@@ -0,0 +1,166 @@
+package classdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// WriteText 按类打印变化，破坏二进制兼容的行以!开头
+func (report *Report) WriteText(out io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", report.Old, report.New)
+	for _, classDiff := range report.Classes {
+		fmt.Fprintf(&b, "%s %s\n", classDiff.Status, classDiff.ClassName)
+		for _, change := range classDiff.Changes {
+			b.WriteString("  ")
+			if change.Breaking {
+				b.WriteString("! ")
+			} else {
+				b.WriteString("  ")
+			}
+			b.WriteString(string(change.Kind))
+			if change.Member != "" {
+				b.WriteString(" " + change.Member)
+			}
+			b.WriteString(":")
+			switch {
+			case change.Old != "" && change.New != "":
+				fmt.Fprintf(&b, " %s -> %s", change.Old, change.New)
+			case change.Old != "":
+				fmt.Fprintf(&b, " %s", change.Old)
+			case change.New != "":
+				fmt.Fprintf(&b, " %s", change.New)
+			}
+			if change.Reason != "" {
+				fmt.Fprintf(&b, " (%s)", change.Reason)
+			}
+			b.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&b, "%d classes differ, %d binary-incompatible changes\n", len(report.Classes), report.Breaking)
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (report *Report) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if report.Classes == nil {
+		report.Classes = []*ClassDiff{} // 输出[]而不是null
+	}
+	return encoder.Encode(report)
+}
+
+type flagName struct {
+	flag uint16
+	name string
+}
+
+var classFlagNames = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_INTERFACE, "interface"},
+	{classfile.ACC_ABSTRACT, "abstract"},
+	{classfile.ACC_SYNTHETIC, "synthetic"},
+	{classfile.ACC_ANNOTATION, "annotation"},
+	{classfile.ACC_ENUM, "enum"},
+}
+
+var fieldFlagNames = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_PRIVATE, "private"},
+	{classfile.ACC_PROTECTED, "protected"},
+	{classfile.ACC_STATIC, "static"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_VOLATILE, "volatile"},
+	{classfile.ACC_TRANSIENT, "transient"},
+	{classfile.ACC_SYNTHETIC, "synthetic"},
+	{classfile.ACC_ENUM, "enum"},
+}
+
+var methodFlagNames = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_PRIVATE, "private"},
+	{classfile.ACC_PROTECTED, "protected"},
+	{classfile.ACC_STATIC, "static"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_SYNCHRONIZED, "synchronized"},
+	{classfile.ACC_BRIDGE, "bridge"},
+	{classfile.ACC_VARARGS, "varargs"},
+	{classfile.ACC_NATIVE, "native"},
+	{classfile.ACC_ABSTRACT, "abstract"},
+	{classfile.ACC_STRICT, "strictfp"},
+	{classfile.ACC_SYNTHETIC, "synthetic"},
+}
+
+// 没有任何修饰符时（包访问权限）返回"package"
+func flagString(flags uint16, table []flagName) string {
+	var names []string
+	for _, fn := range table {
+		if flags&fn.flag != 0 {
+			names = append(names, fn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "package"
+	}
+	return strings.Join(names, " ")
+}
+
+func classFlagString(flags uint16) string {
+	return flagString(flags, classFlagNames)
+}
+
+func fieldFlagString(flags uint16) string {
+	return flagString(flags, fieldFlagNames)
+}
+
+func methodFlagString(flags uint16) string {
+	return flagString(flags, methodFlagNames)
+}
+
+func fieldString(field *classfile.MemberInfo) string {
+	return fieldFlagString(field.AccessFlags()) + " " + field.Descriptor()
+}
+
+func methodString(method *classfile.MemberInfo) string {
+	return methodFlagString(method.AccessFlags())
+}
+
+func classKind(flags uint16) string {
+	switch {
+	case flags&classfile.ACC_ANNOTATION != 0:
+		return "annotation"
+	case flags&classfile.ACC_INTERFACE != 0:
+		return "interface"
+	case flags&classfile.ACC_ENUM != 0:
+		return "enum"
+	}
+	return "class"
+}
+
+func isPublic(flags uint16) bool {
+	return flags&classfile.ACC_PUBLIC != 0
+}
+
+// public和protected的成员是API
+func isAPI(flags uint16) bool {
+	return flags&(classfile.ACC_PUBLIC|classfile.ACC_PROTECTED) != 0
+}
+
+// 访问权限从小到大：private、包、protected、public
+func accessRank(flags uint16) int {
+	switch {
+	case flags&classfile.ACC_PUBLIC != 0:
+		return 3
+	case flags&classfile.ACC_PROTECTED != 0:
+		return 2
+	case flags&classfile.ACC_PRIVATE != 0:
+		return 0
+	}
+	return 1
+}
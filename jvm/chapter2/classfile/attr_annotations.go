@@ -0,0 +1,264 @@
+package classfile
+
+/*
+	RuntimeVisibleAnnotations_attribute {
+	    u2         attribute_name_index;
+	    u4         attribute_length;
+	    u2         num_annotations;
+	    annotation annotations[num_annotations];
+	}
+
+RuntimeInvisibleAnnotations的结构相同
+*/
+type AnnotationsAttribute struct {
+	name        string
+	annotations []*Annotation
+}
+
+/*
+	annotation {
+	    u2 type_index;
+	    u2 num_element_value_pairs;
+	    {   u2            element_name_index;
+	        element_value value;
+	    } element_value_pairs[num_element_value_pairs];
+	}
+*/
+type Annotation struct {
+	typeIndex         uint16
+	elementValuePairs []*ElementValuePair
+}
+
+type ElementValuePair struct {
+	elementNameIndex uint16
+	value            *ElementValue
+}
+
+/*
+	element_value {
+	    u1 tag;
+	    union {
+	        u2 const_value_index;
+	        {   u2 type_name_index;
+	            u2 const_name_index;
+	        } enum_const_value;
+	        u2 class_info_index;
+	        annotation annotation_value;
+	        {   u2            num_values;
+	            element_value values[num_values];
+	        } array_value;
+	    } value;
+	}
+*/
+type ElementValue struct {
+	tag             uint8
+	constValueIndex uint16 // 基本类型和String是const_value_index，'c'是class_info_index，'e'是type_name_index
+	constNameIndex  uint16 // 'e'
+	annotationValue *Annotation
+	arrayValue      []*ElementValue
+}
+
+// 注解可以嵌套，限制一下深度，防止恶意的class文件把栈撑爆
+const maxAnnotationDepth = 64
+
+func (annotationsAttribute *AnnotationsAttribute) readInfo(reader *ClassReader) {
+	annotationsAttribute.annotations = readAnnotations(reader)
+}
+
+func (annotationsAttribute *AnnotationsAttribute) writeInfo(writer *ClassWriter) {
+	writeAnnotations(writer, annotationsAttribute.annotations)
+}
+
+func readAnnotations(reader *ClassReader) []*Annotation {
+	numAnnotations := int(reader.readUint16())
+	reader.need(numAnnotations * 4)
+	annotations := make([]*Annotation, numAnnotations)
+	for i := range annotations {
+		annotations[i] = readAnnotation(reader, 0)
+	}
+	return annotations
+}
+
+func readAnnotation(reader *ClassReader, depth int) *Annotation {
+	if depth > maxAnnotationDepth {
+		reader.fail("annotations nested too deeply")
+	}
+	annotation := &Annotation{typeIndex: reader.readUint16()}
+	numPairs := int(reader.readUint16())
+	reader.need(numPairs * 5)
+	annotation.elementValuePairs = make([]*ElementValuePair, numPairs)
+	for i := range annotation.elementValuePairs {
+		annotation.elementValuePairs[i] = &ElementValuePair{
+			elementNameIndex: reader.readUint16(),
+			value:            readElementValue(reader, depth+1),
+		}
+	}
+	return annotation
+}
+
+func readElementValue(reader *ClassReader, depth int) *ElementValue {
+	if depth > maxAnnotationDepth {
+		reader.fail("annotations nested too deeply")
+	}
+	value := &ElementValue{tag: reader.readUint8()}
+	switch value.tag {
+	case 'B', 'C', 'D', 'F', 'I', 'J', 'S', 'Z', 's', 'c':
+		value.constValueIndex = reader.readUint16()
+	case 'e':
+		value.constValueIndex = reader.readUint16()
+		value.constNameIndex = reader.readUint16()
+	case '@':
+		value.annotationValue = readAnnotation(reader, depth+1)
+	case '[':
+		numValues := int(reader.readUint16())
+		reader.need(numValues * 3)
+		value.arrayValue = make([]*ElementValue, numValues)
+		for i := range value.arrayValue {
+			value.arrayValue[i] = readElementValue(reader, depth+1)
+		}
+	default:
+		reader.pos--
+		reader.fail("invalid element_value tag %d", value.tag)
+	}
+	return value
+}
+
+func (annotationsAttribute *AnnotationsAttribute) Name() string {
+	return annotationsAttribute.name
+}
+
+func (annotationsAttribute *AnnotationsAttribute) Annotations() []*Annotation {
+	return annotationsAttribute.annotations
+}
+
+/*
+	RuntimeVisibleParameterAnnotations_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u1 num_parameters;
+	    {   u2         num_annotations;
+	        annotation annotations[num_annotations];
+	    } parameter_annotations[num_parameters];
+	}
+*/
+type ParameterAnnotationsAttribute struct {
+	name                 string
+	parameterAnnotations [][]*Annotation
+}
+
+func (parameterAnnotationsAttribute *ParameterAnnotationsAttribute) readInfo(reader *ClassReader) {
+	numParameters := int(reader.readUint8())
+	reader.need(numParameters * 2)
+	parameterAnnotationsAttribute.parameterAnnotations = make([][]*Annotation, numParameters)
+	for i := range parameterAnnotationsAttribute.parameterAnnotations {
+		parameterAnnotationsAttribute.parameterAnnotations[i] = readAnnotations(reader)
+	}
+}
+
+func (parameterAnnotationsAttribute *ParameterAnnotationsAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount8(len(parameterAnnotationsAttribute.parameterAnnotations), "parameters")
+	for _, annotations := range parameterAnnotationsAttribute.parameterAnnotations {
+		writeAnnotations(writer, annotations)
+	}
+}
+
+func (parameterAnnotationsAttribute *ParameterAnnotationsAttribute) Name() string {
+	return parameterAnnotationsAttribute.name
+}
+
+func (parameterAnnotationsAttribute *ParameterAnnotationsAttribute) ParameterAnnotations() [][]*Annotation {
+	return parameterAnnotationsAttribute.parameterAnnotations
+}
+
+/*
+	AnnotationDefault_attribute {
+	    u2            attribute_name_index;
+	    u4            attribute_length;
+	    element_value default_value;
+	}
+*/
+type AnnotationDefaultAttribute struct {
+	defaultValue *ElementValue
+}
+
+func (annotationDefaultAttribute *AnnotationDefaultAttribute) readInfo(reader *ClassReader) {
+	annotationDefaultAttribute.defaultValue = readElementValue(reader, 0)
+}
+
+func (annotationDefaultAttribute *AnnotationDefaultAttribute) writeInfo(writer *ClassWriter) {
+	annotationDefaultAttribute.defaultValue.write(writer)
+}
+
+func (annotationDefaultAttribute *AnnotationDefaultAttribute) DefaultValue() *ElementValue {
+	return annotationDefaultAttribute.defaultValue
+}
+
+func (annotation *Annotation) TypeIndex() uint16 {
+	return annotation.typeIndex
+}
+
+func (annotation *Annotation) ElementValuePairs() []*ElementValuePair {
+	return annotation.elementValuePairs
+}
+
+func (elementValuePair *ElementValuePair) ElementNameIndex() uint16 {
+	return elementValuePair.elementNameIndex
+}
+
+func (elementValuePair *ElementValuePair) Value() *ElementValue {
+	return elementValuePair.value
+}
+
+func (elementValue *ElementValue) Tag() uint8 {
+	return elementValue.tag
+}
+
+func (elementValue *ElementValue) ConstValueIndex() uint16 {
+	return elementValue.constValueIndex
+}
+
+func (elementValue *ElementValue) ConstNameIndex() uint16 {
+	return elementValue.constNameIndex
+}
+
+func (elementValue *ElementValue) AnnotationValue() *Annotation {
+	return elementValue.annotationValue
+}
+
+func (elementValue *ElementValue) ArrayValue() []*ElementValue {
+	return elementValue.arrayValue
+}
+
+func writeAnnotations(writer *ClassWriter, annotations []*Annotation) {
+	writer.writeCount(len(annotations), "annotations")
+	for _, annotation := range annotations {
+		annotation.write(writer)
+	}
+}
+
+func (annotation *Annotation) write(writer *ClassWriter) {
+	writer.writeUint16(annotation.typeIndex)
+	writer.writeCount(len(annotation.elementValuePairs), "element value pairs")
+	for _, pair := range annotation.elementValuePairs {
+		writer.writeUint16(pair.elementNameIndex)
+		pair.value.write(writer)
+	}
+}
+
+func (elementValue *ElementValue) write(writer *ClassWriter) {
+	writer.writeUint8(elementValue.tag)
+	switch elementValue.tag {
+	case 'e':
+		writer.writeUint16(elementValue.constValueIndex)
+		writer.writeUint16(elementValue.constNameIndex)
+	case '@':
+		elementValue.annotationValue.write(writer)
+	case '[':
+		writer.writeCount(len(elementValue.arrayValue), "array element values")
+		for _, value := range elementValue.arrayValue {
+			value.write(writer)
+		}
+	default:
+		writer.writeUint16(elementValue.constValueIndex)
+	}
+}
@@ -0,0 +1,88 @@
+package classfile
+
+/*
+	BootstrapMethods_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 num_bootstrap_methods;
+	    {   u2 bootstrap_method_ref;
+	        u2 num_bootstrap_arguments;
+	        u2 bootstrap_arguments[num_bootstrap_arguments];
+	    } bootstrap_methods[num_bootstrap_methods];
+	}
+*/
+type BootstrapMethodsAttribute struct {
+	bootstrapMethods []*BootstrapMethod
+}
+
+type BootstrapMethod struct {
+	bootstrapMethodRef uint16
+	bootstrapArguments []uint16
+}
+
+func (bootstrapMethodsAttribute *BootstrapMethodsAttribute) readInfo(reader *ClassReader) {
+	numBootstrapMethods := int(reader.readUint16())
+	reader.need(numBootstrapMethods * 4)
+	bootstrapMethodsAttribute.bootstrapMethods = make([]*BootstrapMethod, numBootstrapMethods)
+	for i := range bootstrapMethodsAttribute.bootstrapMethods {
+		bootstrapMethodsAttribute.bootstrapMethods[i] = &BootstrapMethod{
+			bootstrapMethodRef: reader.readUint16(),
+			bootstrapArguments: reader.readUint16s(),
+		}
+	}
+}
+
+func (bootstrapMethodsAttribute *BootstrapMethodsAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount(len(bootstrapMethodsAttribute.bootstrapMethods), "bootstrap methods")
+	for _, bootstrapMethod := range bootstrapMethodsAttribute.bootstrapMethods {
+		writer.writeUint16(bootstrapMethod.bootstrapMethodRef)
+		writer.writeUint16s(bootstrapMethod.bootstrapArguments, "bootstrap arguments")
+	}
+}
+
+func (bootstrapMethodsAttribute *BootstrapMethodsAttribute) BootstrapMethods() []*BootstrapMethod {
+	return bootstrapMethodsAttribute.bootstrapMethods
+}
+
+// BootstrapMethodRef 是MethodHandle常量的索引
+func (bootstrapMethod *BootstrapMethod) BootstrapMethodRef() uint16 {
+	return bootstrapMethod.bootstrapMethodRef
+}
+
+func (bootstrapMethod *BootstrapMethod) BootstrapArguments() []uint16 {
+	return bootstrapMethod.bootstrapArguments
+}
+
+func NewBootstrapMethodsAttribute(bootstrapMethods []*BootstrapMethod) *BootstrapMethodsAttribute {
+	return &BootstrapMethodsAttribute{bootstrapMethods: bootstrapMethods}
+}
+
+// NewBootstrapMethod bootstrapMethodRef指向MethodHandle常量，参数都是可以ldc的常量
+func NewBootstrapMethod(bootstrapMethodRef uint16, bootstrapArguments []uint16) *BootstrapMethod {
+	return &BootstrapMethod{bootstrapMethodRef: bootstrapMethodRef, bootstrapArguments: bootstrapArguments}
+}
+
+// AddBootstrapMethod 追加一个引导方法并返回它的下标；已有相同的引导方法时返回原来的下标
+func (bootstrapMethodsAttribute *BootstrapMethodsAttribute) AddBootstrapMethod(bootstrapMethodRef uint16, bootstrapArguments []uint16) uint16 {
+	for i, bootstrapMethod := range bootstrapMethodsAttribute.bootstrapMethods {
+		if bootstrapMethod.bootstrapMethodRef == bootstrapMethodRef &&
+			equalUint16s(bootstrapMethod.bootstrapArguments, bootstrapArguments) {
+			return uint16(i)
+		}
+	}
+	bootstrapMethodsAttribute.bootstrapMethods = append(bootstrapMethodsAttribute.bootstrapMethods,
+		NewBootstrapMethod(bootstrapMethodRef, bootstrapArguments))
+	return uint16(len(bootstrapMethodsAttribute.bootstrapMethods) - 1)
+}
+
+func equalUint16s(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
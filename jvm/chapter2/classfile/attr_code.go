@@ -0,0 +1,159 @@
+package classfile
+
+/*
+	Code_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 max_stack;
+	    u2 max_locals;
+	    u4 code_length;
+	    u1 code[code_length];
+	    u2 exception_table_length;
+	    {   u2 start_pc;
+	        u2 end_pc;
+	        u2 handler_pc;
+	        u2 catch_type;
+	    } exception_table[exception_table_length];
+	    u2 attributes_count;
+	    attribute_info attributes[attributes_count];
+	}
+*/
+type CodeAttribute struct {
+	cp             *ConstantPool
+	maxStack       uint16
+	maxLocals      uint16
+	code           []byte
+	exceptionTable []*ExceptionTableEntry
+	attributes     []AttributeInfo
+}
+
+// NewCodeAttribute 新建Code属性，异常表和子属性用Set方法设置
+func NewCodeAttribute(cp *ConstantPool, maxStack, maxLocals uint16, code []byte) *CodeAttribute {
+	return &CodeAttribute{cp: cp, maxStack: maxStack, maxLocals: maxLocals, code: code}
+}
+
+func (codeAttribute *CodeAttribute) readInfo(reader *ClassReader) {
+	codeAttribute.maxStack = reader.readUint16()
+	codeAttribute.maxLocals = reader.readUint16()
+	codeLength := reader.readUint32()
+	codeAttribute.code = reader.readBytes(codeLength)
+	codeAttribute.exceptionTable = readExceptionTable(reader)
+	codeAttribute.attributes = readAttributes(reader, codeAttribute.cp)
+}
+
+func (codeAttribute *CodeAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(codeAttribute.maxStack)
+	writer.writeUint16(codeAttribute.maxLocals)
+	writer.writeUint32(uint32(len(codeAttribute.code)))
+	writer.writeBytes(codeAttribute.code)
+	writer.writeCount(len(codeAttribute.exceptionTable), "exception table entries")
+	for _, entry := range codeAttribute.exceptionTable {
+		writer.writeUint16(entry.startPc)
+		writer.writeUint16(entry.endPc)
+		writer.writeUint16(entry.handlerPc)
+		writer.writeUint16(entry.catchType)
+	}
+	writeAttributes(writer, codeAttribute.attributes)
+}
+
+func (codeAttribute *CodeAttribute) MaxStack() uint {
+	return uint(codeAttribute.maxStack)
+}
+
+func (codeAttribute *CodeAttribute) MaxLocals() uint {
+	return uint(codeAttribute.maxLocals)
+}
+
+func (codeAttribute *CodeAttribute) Code() []byte {
+	return codeAttribute.code
+}
+
+func (codeAttribute *CodeAttribute) ExceptionTable() []*ExceptionTableEntry {
+	return codeAttribute.exceptionTable
+}
+
+func (codeAttribute *CodeAttribute) Attributes() []AttributeInfo {
+	return codeAttribute.attributes
+}
+
+func (codeAttribute *CodeAttribute) SetMaxStack(maxStack uint16) {
+	codeAttribute.maxStack = maxStack
+}
+
+func (codeAttribute *CodeAttribute) SetMaxLocals(maxLocals uint16) {
+	codeAttribute.maxLocals = maxLocals
+}
+
+func (codeAttribute *CodeAttribute) SetCode(code []byte) {
+	codeAttribute.code = code
+}
+
+func (codeAttribute *CodeAttribute) SetExceptionTable(exceptionTable []*ExceptionTableEntry) {
+	codeAttribute.exceptionTable = exceptionTable
+}
+
+func (codeAttribute *CodeAttribute) SetAttributes(attributes []AttributeInfo) {
+	codeAttribute.attributes = attributes
+}
+
+func (codeAttribute *CodeAttribute) LineNumberTableAttribute() *LineNumberTableAttribute {
+	for _, attrInfo := range codeAttribute.attributes {
+		if attr, ok := attrInfo.(*LineNumberTableAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (codeAttribute *CodeAttribute) StackMapTableAttribute() *StackMapTableAttribute {
+	for _, attrInfo := range codeAttribute.attributes {
+		if attr, ok := attrInfo.(*StackMapTableAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+type ExceptionTableEntry struct {
+	startPc   uint16
+	endPc     uint16
+	handlerPc uint16
+	catchType uint16
+}
+
+// NewExceptionTableEntry catchType为0时捕获所有异常（finally）
+func NewExceptionTableEntry(startPc, endPc, handlerPc, catchType uint16) *ExceptionTableEntry {
+	return &ExceptionTableEntry{startPc: startPc, endPc: endPc, handlerPc: handlerPc, catchType: catchType}
+}
+
+func readExceptionTable(reader *ClassReader) []*ExceptionTableEntry {
+	exceptionTableLength := int(reader.readUint16())
+	reader.need(exceptionTableLength * 8)
+	exceptionTable := make([]*ExceptionTableEntry, exceptionTableLength)
+	for i := range exceptionTable {
+		exceptionTable[i] = &ExceptionTableEntry{
+			startPc:   reader.readUint16(),
+			endPc:     reader.readUint16(),
+			handlerPc: reader.readUint16(),
+			catchType: reader.readUint16(),
+		}
+	}
+	return exceptionTable
+}
+
+func (exceptionTableEntry *ExceptionTableEntry) StartPc() uint16 {
+	return exceptionTableEntry.startPc
+}
+
+func (exceptionTableEntry *ExceptionTableEntry) EndPc() uint16 {
+	return exceptionTableEntry.endPc
+}
+
+func (exceptionTableEntry *ExceptionTableEntry) HandlerPc() uint16 {
+	return exceptionTableEntry.handlerPc
+}
+
+// CatchType 是Class常量的索引，0表示捕获所有异常（finally）
+func (exceptionTableEntry *ExceptionTableEntry) CatchType() uint16 {
+	return exceptionTableEntry.catchType
+}
@@ -0,0 +1,29 @@
+package classfile
+
+/*
+	ConstantValue_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 constantvalue_index;
+	}
+*/
+type ConstantValueAttribute struct {
+	constantValueIndex uint16
+}
+
+func (constantValueAttribute *ConstantValueAttribute) readInfo(reader *ClassReader) {
+	constantValueAttribute.constantValueIndex = reader.readUint16()
+}
+
+func (constantValueAttribute *ConstantValueAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantValueAttribute.constantValueIndex)
+}
+
+func (constantValueAttribute *ConstantValueAttribute) ConstantValueIndex() uint16 {
+	return constantValueAttribute.constantValueIndex
+}
+
+// NewConstantValueAttribute constantValueIndex指向Integer/Float/Long/Double/String常量
+func NewConstantValueAttribute(constantValueIndex uint16) *ConstantValueAttribute {
+	return &ConstantValueAttribute{constantValueIndex: constantValueIndex}
+}
@@ -0,0 +1,30 @@
+package classfile
+
+/*
+	Exceptions_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 number_of_exceptions;
+	    u2 exception_index_table[number_of_exceptions];
+	}
+*/
+type ExceptionsAttribute struct {
+	exceptionIndexTable []uint16
+}
+
+func (exceptionsAttribute *ExceptionsAttribute) readInfo(reader *ClassReader) {
+	exceptionsAttribute.exceptionIndexTable = reader.readUint16s()
+}
+
+func (exceptionsAttribute *ExceptionsAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16s(exceptionsAttribute.exceptionIndexTable, "exceptions")
+}
+
+func (exceptionsAttribute *ExceptionsAttribute) ExceptionIndexTable() []uint16 {
+	return exceptionsAttribute.exceptionIndexTable
+}
+
+// NewExceptionsAttribute exceptionIndexTable里是Class常量的索引
+func NewExceptionsAttribute(exceptionIndexTable []uint16) *ExceptionsAttribute {
+	return &ExceptionsAttribute{exceptionIndexTable: exceptionIndexTable}
+}
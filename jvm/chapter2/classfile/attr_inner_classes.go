@@ -0,0 +1,112 @@
+package classfile
+
+/*
+	InnerClasses_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 number_of_classes;
+	    {   u2 inner_class_info_index;
+	        u2 outer_class_info_index;
+	        u2 inner_name_index;
+	        u2 inner_class_access_flags;
+	    } classes[number_of_classes];
+	}
+*/
+type InnerClassesAttribute struct {
+	classes []*InnerClassInfo
+}
+
+type InnerClassInfo struct {
+	innerClassInfoIndex   uint16
+	outerClassInfoIndex   uint16
+	innerNameIndex        uint16
+	innerClassAccessFlags uint16
+}
+
+func (innerClassesAttribute *InnerClassesAttribute) readInfo(reader *ClassReader) {
+	numberOfClasses := int(reader.readUint16())
+	reader.need(numberOfClasses * 8)
+	innerClassesAttribute.classes = make([]*InnerClassInfo, numberOfClasses)
+	for i := range innerClassesAttribute.classes {
+		innerClassesAttribute.classes[i] = &InnerClassInfo{
+			innerClassInfoIndex:   reader.readUint16(),
+			outerClassInfoIndex:   reader.readUint16(),
+			innerNameIndex:        reader.readUint16(),
+			innerClassAccessFlags: reader.readUint16(),
+		}
+	}
+}
+
+func (innerClassesAttribute *InnerClassesAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount(len(innerClassesAttribute.classes), "inner classes")
+	for _, info := range innerClassesAttribute.classes {
+		writer.writeUint16(info.innerClassInfoIndex)
+		writer.writeUint16(info.outerClassInfoIndex)
+		writer.writeUint16(info.innerNameIndex)
+		writer.writeUint16(info.innerClassAccessFlags)
+	}
+}
+
+func (innerClassesAttribute *InnerClassesAttribute) Classes() []*InnerClassInfo {
+	return innerClassesAttribute.classes
+}
+
+func (innerClassInfo *InnerClassInfo) InnerClassInfoIndex() uint16 {
+	return innerClassInfo.innerClassInfoIndex
+}
+
+func (innerClassInfo *InnerClassInfo) OuterClassInfoIndex() uint16 {
+	return innerClassInfo.outerClassInfoIndex
+}
+
+func (innerClassInfo *InnerClassInfo) InnerNameIndex() uint16 {
+	return innerClassInfo.innerNameIndex
+}
+
+func (innerClassInfo *InnerClassInfo) InnerClassAccessFlags() uint16 {
+	return innerClassInfo.innerClassAccessFlags
+}
+
+/*
+	EnclosingMethod_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 class_index;
+	    u2 method_index;
+	}
+*/
+type EnclosingMethodAttribute struct {
+	cp          *ConstantPool
+	classIndex  uint16
+	methodIndex uint16
+}
+
+func (enclosingMethodAttribute *EnclosingMethodAttribute) readInfo(reader *ClassReader) {
+	enclosingMethodAttribute.classIndex = reader.readUint16()
+	enclosingMethodAttribute.methodIndex = reader.readUint16()
+}
+
+func (enclosingMethodAttribute *EnclosingMethodAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(enclosingMethodAttribute.classIndex)
+	writer.writeUint16(enclosingMethodAttribute.methodIndex)
+}
+
+func (enclosingMethodAttribute *EnclosingMethodAttribute) ClassIndex() uint16 {
+	return enclosingMethodAttribute.classIndex
+}
+
+// MethodIndex 是NameAndType常量的索引，类不在方法里时为0
+func (enclosingMethodAttribute *EnclosingMethodAttribute) MethodIndex() uint16 {
+	return enclosingMethodAttribute.methodIndex
+}
+
+func (enclosingMethodAttribute *EnclosingMethodAttribute) ClassName() string {
+	return enclosingMethodAttribute.cp.GetClassName(enclosingMethodAttribute.classIndex)
+}
+
+func (enclosingMethodAttribute *EnclosingMethodAttribute) MethodNameAndDescriptor() (string, string) {
+	if enclosingMethodAttribute.methodIndex > 0 {
+		return enclosingMethodAttribute.cp.GetNameAndType(enclosingMethodAttribute.methodIndex)
+	}
+	return "", ""
+}
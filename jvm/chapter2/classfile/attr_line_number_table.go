@@ -0,0 +1,71 @@
+package classfile
+
+/*
+	LineNumberTable_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 line_number_table_length;
+	    {   u2 start_pc;
+	        u2 line_number;
+	    } line_number_table[line_number_table_length];
+	}
+*/
+type LineNumberTableAttribute struct {
+	lineNumberTable []*LineNumberTableEntry
+}
+
+type LineNumberTableEntry struct {
+	startPc    uint16
+	lineNumber uint16
+}
+
+func (lineNumberTableAttribute *LineNumberTableAttribute) readInfo(reader *ClassReader) {
+	lineNumberTableLength := int(reader.readUint16())
+	reader.need(lineNumberTableLength * 4)
+	lineNumberTableAttribute.lineNumberTable = make([]*LineNumberTableEntry, lineNumberTableLength)
+	for i := range lineNumberTableAttribute.lineNumberTable {
+		lineNumberTableAttribute.lineNumberTable[i] = &LineNumberTableEntry{
+			startPc:    reader.readUint16(),
+			lineNumber: reader.readUint16(),
+		}
+	}
+}
+
+func (lineNumberTableAttribute *LineNumberTableAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount(len(lineNumberTableAttribute.lineNumberTable), "line numbers")
+	for _, entry := range lineNumberTableAttribute.lineNumberTable {
+		writer.writeUint16(entry.startPc)
+		writer.writeUint16(entry.lineNumber)
+	}
+}
+
+func (lineNumberTableAttribute *LineNumberTableAttribute) Entries() []*LineNumberTableEntry {
+	return lineNumberTableAttribute.lineNumberTable
+}
+
+// GetLineNumber 查找pc对应的源码行号，找不到返回-1
+func (lineNumberTableAttribute *LineNumberTableAttribute) GetLineNumber(pc int) int {
+	for i := len(lineNumberTableAttribute.lineNumberTable) - 1; i >= 0; i-- {
+		entry := lineNumberTableAttribute.lineNumberTable[i]
+		if pc >= int(entry.startPc) {
+			return int(entry.lineNumber)
+		}
+	}
+	return -1
+}
+
+func (lineNumberTableEntry *LineNumberTableEntry) StartPc() uint16 {
+	return lineNumberTableEntry.startPc
+}
+
+func (lineNumberTableEntry *LineNumberTableEntry) LineNumber() uint16 {
+	return lineNumberTableEntry.lineNumber
+}
+
+func NewLineNumberTableAttribute(entries []*LineNumberTableEntry) *LineNumberTableAttribute {
+	return &LineNumberTableAttribute{lineNumberTable: entries}
+}
+
+func NewLineNumberTableEntry(startPc, lineNumber uint16) *LineNumberTableEntry {
+	return &LineNumberTableEntry{startPc: startPc, lineNumber: lineNumber}
+}
@@ -0,0 +1,97 @@
+package classfile
+
+/*
+	LocalVariableTable_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 local_variable_table_length;
+	    {   u2 start_pc;
+	        u2 length;
+	        u2 name_index;
+	        u2 descriptor_index;
+	        u2 index;
+	    } local_variable_table[local_variable_table_length];
+	}
+
+LocalVariableTypeTable的结构相同，descriptor_index换成了signature_index
+*/
+type LocalVariableTableAttribute struct {
+	localVariableTable []*LocalVariableTableEntry
+}
+
+type LocalVariableTypeTableAttribute struct {
+	LocalVariableTableAttribute
+}
+
+type LocalVariableTableEntry struct {
+	startPc         uint16
+	length          uint16
+	nameIndex       uint16
+	descriptorIndex uint16
+	index           uint16
+}
+
+func (localVariableTableAttribute *LocalVariableTableAttribute) readInfo(reader *ClassReader) {
+	localVariableTableLength := int(reader.readUint16())
+	reader.need(localVariableTableLength * 10)
+	localVariableTableAttribute.localVariableTable = make([]*LocalVariableTableEntry, localVariableTableLength)
+	for i := range localVariableTableAttribute.localVariableTable {
+		localVariableTableAttribute.localVariableTable[i] = &LocalVariableTableEntry{
+			startPc:         reader.readUint16(),
+			length:          reader.readUint16(),
+			nameIndex:       reader.readUint16(),
+			descriptorIndex: reader.readUint16(),
+			index:           reader.readUint16(),
+		}
+	}
+}
+
+func (localVariableTableAttribute *LocalVariableTableAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount(len(localVariableTableAttribute.localVariableTable), "local variables")
+	for _, entry := range localVariableTableAttribute.localVariableTable {
+		writer.writeUint16(entry.startPc)
+		writer.writeUint16(entry.length)
+		writer.writeUint16(entry.nameIndex)
+		writer.writeUint16(entry.descriptorIndex)
+		writer.writeUint16(entry.index)
+	}
+}
+
+func (localVariableTableAttribute *LocalVariableTableAttribute) Entries() []*LocalVariableTableEntry {
+	return localVariableTableAttribute.localVariableTable
+}
+
+func (localVariableTableEntry *LocalVariableTableEntry) StartPc() uint16 {
+	return localVariableTableEntry.startPc
+}
+
+func (localVariableTableEntry *LocalVariableTableEntry) Length() uint16 {
+	return localVariableTableEntry.length
+}
+
+func (localVariableTableEntry *LocalVariableTableEntry) NameIndex() uint16 {
+	return localVariableTableEntry.nameIndex
+}
+
+// DescriptorIndex 在LocalVariableTypeTable中是signature_index
+func (localVariableTableEntry *LocalVariableTableEntry) DescriptorIndex() uint16 {
+	return localVariableTableEntry.descriptorIndex
+}
+
+func (localVariableTableEntry *LocalVariableTableEntry) Index() uint16 {
+	return localVariableTableEntry.index
+}
+
+func NewLocalVariableTableAttribute(entries []*LocalVariableTableEntry) *LocalVariableTableAttribute {
+	return &LocalVariableTableAttribute{localVariableTable: entries}
+}
+
+func NewLocalVariableTableEntry(startPc, length, nameIndex, descriptorIndex, index uint16) *LocalVariableTableEntry {
+	return &LocalVariableTableEntry{
+		startPc:         startPc,
+		length:          length,
+		nameIndex:       nameIndex,
+		descriptorIndex: descriptorIndex,
+		index:           index,
+	}
+}
@@ -0,0 +1,43 @@
+package classfile
+
+/*
+	Deprecated_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	}
+*/
+type DeprecatedAttribute struct {
+	MarkerAttribute
+}
+
+/*
+	Synthetic_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	}
+*/
+type SyntheticAttribute struct {
+	MarkerAttribute
+}
+
+// MarkerAttribute 没有内容的属性。不能是零大小的结构体：零大小的对象可能共用一个地址，
+// 几个属性作为attrNames的键时会变成同一个，写回时用错attribute_name_index
+type MarkerAttribute struct {
+	_ byte
+}
+
+func (markerAttribute *MarkerAttribute) readInfo(reader *ClassReader) {
+	// read nothing
+}
+
+func (markerAttribute *MarkerAttribute) writeInfo(writer *ClassWriter) {
+	// write nothing
+}
+
+func NewDeprecatedAttribute() *DeprecatedAttribute {
+	return &DeprecatedAttribute{}
+}
+
+func NewSyntheticAttribute() *SyntheticAttribute {
+	return &SyntheticAttribute{}
+}
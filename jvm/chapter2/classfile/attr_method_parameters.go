@@ -0,0 +1,53 @@
+package classfile
+
+/*
+	MethodParameters_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u1 parameters_count;
+	    {   u2 name_index;
+	        u2 access_flags;
+	    } parameters[parameters_count];
+	}
+*/
+type MethodParametersAttribute struct {
+	parameters []*MethodParameter
+}
+
+type MethodParameter struct {
+	nameIndex   uint16
+	accessFlags uint16
+}
+
+func (methodParametersAttribute *MethodParametersAttribute) readInfo(reader *ClassReader) {
+	parametersCount := int(reader.readUint8())
+	reader.need(parametersCount * 4)
+	methodParametersAttribute.parameters = make([]*MethodParameter, parametersCount)
+	for i := range methodParametersAttribute.parameters {
+		methodParametersAttribute.parameters[i] = &MethodParameter{
+			nameIndex:   reader.readUint16(),
+			accessFlags: reader.readUint16(),
+		}
+	}
+}
+
+func (methodParametersAttribute *MethodParametersAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount8(len(methodParametersAttribute.parameters), "method parameters")
+	for _, parameter := range methodParametersAttribute.parameters {
+		writer.writeUint16(parameter.nameIndex)
+		writer.writeUint16(parameter.accessFlags)
+	}
+}
+
+func (methodParametersAttribute *MethodParametersAttribute) Parameters() []*MethodParameter {
+	return methodParametersAttribute.parameters
+}
+
+// NameIndex 为0表示参数没有名字
+func (methodParameter *MethodParameter) NameIndex() uint16 {
+	return methodParameter.nameIndex
+}
+
+func (methodParameter *MethodParameter) AccessFlags() uint16 {
+	return methodParameter.accessFlags
+}
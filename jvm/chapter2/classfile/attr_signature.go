@@ -0,0 +1,33 @@
+package classfile
+
+/*
+	Signature_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 signature_index;
+	}
+*/
+type SignatureAttribute struct {
+	cp             *ConstantPool
+	signatureIndex uint16
+}
+
+func (signatureAttribute *SignatureAttribute) readInfo(reader *ClassReader) {
+	signatureAttribute.signatureIndex = reader.readUint16()
+}
+
+func (signatureAttribute *SignatureAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(signatureAttribute.signatureIndex)
+}
+
+func (signatureAttribute *SignatureAttribute) SignatureIndex() uint16 {
+	return signatureAttribute.signatureIndex
+}
+
+func (signatureAttribute *SignatureAttribute) Signature() string {
+	return signatureAttribute.cp.GetUtf8(signatureAttribute.signatureIndex)
+}
+
+func NewSignatureAttribute(cp *ConstantPool, signature string) *SignatureAttribute {
+	return &SignatureAttribute{cp: cp, signatureIndex: cp.AddUtf8(signature)}
+}
@@ -0,0 +1,33 @@
+package classfile
+
+/*
+	SourceFile_attribute {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u2 sourcefile_index;
+	}
+*/
+type SourceFileAttribute struct {
+	cp              *ConstantPool
+	sourceFileIndex uint16
+}
+
+func (sourceFileAttribute *SourceFileAttribute) readInfo(reader *ClassReader) {
+	sourceFileAttribute.sourceFileIndex = reader.readUint16()
+}
+
+func (sourceFileAttribute *SourceFileAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(sourceFileAttribute.sourceFileIndex)
+}
+
+func (sourceFileAttribute *SourceFileAttribute) SourceFileIndex() uint16 {
+	return sourceFileAttribute.sourceFileIndex
+}
+
+func (sourceFileAttribute *SourceFileAttribute) FileName() string {
+	return sourceFileAttribute.cp.GetUtf8(sourceFileAttribute.sourceFileIndex)
+}
+
+func NewSourceFileAttribute(cp *ConstantPool, fileName string) *SourceFileAttribute {
+	return &SourceFileAttribute{cp: cp, sourceFileIndex: cp.AddUtf8(fileName)}
+}
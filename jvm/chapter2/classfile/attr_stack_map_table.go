@@ -0,0 +1,211 @@
+package classfile
+
+/*
+	StackMapTable_attribute {
+	    u2              attribute_name_index;
+	    u4              attribute_length;
+	    u2              number_of_entries;
+	    stack_map_frame entries[number_of_entries];
+	}
+*/
+type StackMapTableAttribute struct {
+	entries []*StackMapFrame
+}
+
+// verification_type_info的tag
+const (
+	ITEM_Top               = 0
+	ITEM_Integer           = 1
+	ITEM_Float             = 2
+	ITEM_Double            = 3
+	ITEM_Long              = 4
+	ITEM_Null              = 5
+	ITEM_UninitializedThis = 6
+	ITEM_Object            = 7
+	ITEM_Uninitialized     = 8
+)
+
+// frame_type的取值范围
+const (
+	SameFrameMax                    = 63
+	SameLocals1StackItemFrameMax    = 127
+	SameLocals1StackItemFrameExtend = 247
+	ChopFrameMax                    = 250
+	SameFrameExtended               = 251
+	AppendFrameMax                  = 254
+	FullFrame                       = 255
+)
+
+/*
+	union verification_type_info {
+	    Top_variable_info;
+	    ...
+	    Object_variable_info;        // u1 tag = ITEM_Object; u2 cpool_index
+	    Uninitialized_variable_info; // u1 tag = ITEM_Uninitialized; u2 offset
+	}
+*/
+type VerificationTypeInfo struct {
+	tag   uint8
+	value uint16 // ITEM_Object时是cpool_index，ITEM_Uninitialized时是new指令的offset
+}
+
+// StackMapFrame 统一表示各种stack_map_frame
+// 对same/chop等没有显式locals/stack的帧，locals和stack为空
+type StackMapFrame struct {
+	frameType   uint8
+	offsetDelta uint16
+	locals      []VerificationTypeInfo
+	stack       []VerificationTypeInfo
+}
+
+func (stackMapTableAttribute *StackMapTableAttribute) readInfo(reader *ClassReader) {
+	numberOfEntries := int(reader.readUint16())
+	reader.need(numberOfEntries)
+	stackMapTableAttribute.entries = make([]*StackMapFrame, numberOfEntries)
+	for i := range stackMapTableAttribute.entries {
+		stackMapTableAttribute.entries[i] = readStackMapFrame(reader)
+	}
+}
+
+func (stackMapTableAttribute *StackMapTableAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeCount(len(stackMapTableAttribute.entries), "stack map frames")
+	for _, frame := range stackMapTableAttribute.entries {
+		frame.write(writer)
+	}
+}
+
+func readStackMapFrame(reader *ClassReader) *StackMapFrame {
+	frame := &StackMapFrame{frameType: reader.readUint8()}
+	switch t := frame.frameType; {
+	case t <= SameFrameMax:
+		frame.offsetDelta = uint16(t)
+	case t <= SameLocals1StackItemFrameMax:
+		frame.offsetDelta = uint16(t - 64)
+		frame.stack = readVerificationTypeInfos(reader, 1)
+	case t < SameLocals1StackItemFrameExtend:
+		reader.pos--
+		reader.fail("reserved stack_map_frame type %d", t)
+	case t == SameLocals1StackItemFrameExtend:
+		frame.offsetDelta = reader.readUint16()
+		frame.stack = readVerificationTypeInfos(reader, 1)
+	case t <= SameFrameExtended:
+		frame.offsetDelta = reader.readUint16()
+	case t <= AppendFrameMax:
+		frame.offsetDelta = reader.readUint16()
+		frame.locals = readVerificationTypeInfos(reader, int(t-SameFrameExtended))
+	default:
+		frame.offsetDelta = reader.readUint16()
+		frame.locals = readVerificationTypeInfos(reader, int(reader.readUint16()))
+		frame.stack = readVerificationTypeInfos(reader, int(reader.readUint16()))
+	}
+	return frame
+}
+
+func readVerificationTypeInfos(reader *ClassReader, n int) []VerificationTypeInfo {
+	reader.need(n)
+	infos := make([]VerificationTypeInfo, n)
+	for i := range infos {
+		infos[i].tag = reader.readUint8()
+		switch infos[i].tag {
+		case ITEM_Object, ITEM_Uninitialized:
+			infos[i].value = reader.readUint16()
+		default:
+			if infos[i].tag > ITEM_Uninitialized {
+				reader.pos--
+				reader.fail("invalid verification_type_info tag %d", infos[i].tag)
+			}
+		}
+	}
+	return infos
+}
+
+func (stackMapTableAttribute *StackMapTableAttribute) Entries() []*StackMapFrame {
+	return stackMapTableAttribute.entries
+}
+
+func (stackMapFrame *StackMapFrame) FrameType() uint8 {
+	return stackMapFrame.frameType
+}
+
+func (stackMapFrame *StackMapFrame) OffsetDelta() uint16 {
+	return stackMapFrame.offsetDelta
+}
+
+// Locals 对append帧是追加的局部变量，对full帧是全部局部变量
+func (stackMapFrame *StackMapFrame) Locals() []VerificationTypeInfo {
+	return stackMapFrame.locals
+}
+
+func (stackMapFrame *StackMapFrame) Stack() []VerificationTypeInfo {
+	return stackMapFrame.stack
+}
+
+// ChopCount 返回chop帧去掉的局部变量个数
+func (stackMapFrame *StackMapFrame) ChopCount() int {
+	if stackMapFrame.frameType > SameLocals1StackItemFrameExtend && stackMapFrame.frameType <= ChopFrameMax {
+		return SameFrameExtended - int(stackMapFrame.frameType)
+	}
+	return 0
+}
+
+func (verificationTypeInfo VerificationTypeInfo) Tag() uint8 {
+	return verificationTypeInfo.tag
+}
+
+// CpoolIndex ITEM_Object的类常量索引
+func (verificationTypeInfo VerificationTypeInfo) CpoolIndex() uint16 {
+	return verificationTypeInfo.value
+}
+
+// Offset ITEM_Uninitialized对应的new指令地址
+func (verificationTypeInfo VerificationTypeInfo) Offset() uint16 {
+	return verificationTypeInfo.value
+}
+
+func (stackMapFrame *StackMapFrame) write(writer *ClassWriter) {
+	t := stackMapFrame.frameType
+	writer.writeUint8(t)
+	switch {
+	case t <= SameFrameMax:
+	case t <= SameLocals1StackItemFrameMax:
+		writeVerificationTypeInfos(writer, stackMapFrame.stack)
+	case t == SameLocals1StackItemFrameExtend:
+		writer.writeUint16(stackMapFrame.offsetDelta)
+		writeVerificationTypeInfos(writer, stackMapFrame.stack)
+	case t <= SameFrameExtended:
+		writer.writeUint16(stackMapFrame.offsetDelta)
+	case t <= AppendFrameMax:
+		writer.writeUint16(stackMapFrame.offsetDelta)
+		writeVerificationTypeInfos(writer, stackMapFrame.locals)
+	default:
+		writer.writeUint16(stackMapFrame.offsetDelta)
+		writer.writeCount(len(stackMapFrame.locals), "locals in stack map frame")
+		writeVerificationTypeInfos(writer, stackMapFrame.locals)
+		writer.writeCount(len(stackMapFrame.stack), "stack items in stack map frame")
+		writeVerificationTypeInfos(writer, stackMapFrame.stack)
+	}
+}
+
+func writeVerificationTypeInfos(writer *ClassWriter, infos []VerificationTypeInfo) {
+	for _, info := range infos {
+		writer.writeUint8(info.tag)
+		switch info.tag {
+		case ITEM_Object, ITEM_Uninitialized:
+			writer.writeUint16(info.value)
+		}
+	}
+}
+
+func NewStackMapTableAttribute(entries []*StackMapFrame) *StackMapTableAttribute {
+	return &StackMapTableAttribute{entries: entries}
+}
+
+// NewStackMapFrame 对same和same_locals_1_stack_item帧，offsetDelta由frameType决定
+func NewStackMapFrame(frameType uint8, offsetDelta uint16, locals, stack []VerificationTypeInfo) *StackMapFrame {
+	return &StackMapFrame{frameType: frameType, offsetDelta: offsetDelta, locals: locals, stack: stack}
+}
+
+// NewVerificationTypeInfo value只对ITEM_Object（cpool_index）和ITEM_Uninitialized（offset）有意义
+func NewVerificationTypeInfo(tag uint8, value uint16) VerificationTypeInfo {
+	return VerificationTypeInfo{tag: tag, value: value}
+}
@@ -0,0 +1,30 @@
+package classfile
+
+/*
+	attribute_info {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u1 info[attribute_length];
+	}
+*/
+type UnparsedAttribute struct {
+	name   string
+	length uint32
+	info   []byte
+}
+
+func (unparsedAttribute *UnparsedAttribute) readInfo(reader *ClassReader) {
+	unparsedAttribute.info = reader.readBytes(unparsedAttribute.length)
+}
+
+func (unparsedAttribute *UnparsedAttribute) writeInfo(writer *ClassWriter) {
+	writer.writeBytes(unparsedAttribute.info)
+}
+
+func (unparsedAttribute *UnparsedAttribute) Name() string {
+	return unparsedAttribute.name
+}
+
+func (unparsedAttribute *UnparsedAttribute) Info() []byte {
+	return unparsedAttribute.info
+}
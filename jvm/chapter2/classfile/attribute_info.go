@@ -0,0 +1,183 @@
+package classfile
+
+import "fmt"
+
+/*
+	attribute_info {
+	    u2 attribute_name_index;
+	    u4 attribute_length;
+	    u1 info[attribute_length];
+	}
+*/
+type AttributeInfo interface {
+	readInfo(reader *ClassReader)
+	writeInfo(writer *ClassWriter)
+}
+
+func readAttributes(reader *ClassReader, cp *ConstantPool) []AttributeInfo {
+	attributesCount := int(reader.readUint16())
+	reader.need(attributesCount * 6)
+	attributes := make([]AttributeInfo, attributesCount)
+	for i := range attributes {
+		attributes[i] = readAttribute(reader, cp)
+	}
+	return attributes
+}
+
+// 只有Code属性里面还有属性，再深的嵌套只可能来自恶意构造的class文件，
+// 不加限制的话每层都要递归并复制一遍数据
+const maxAttributeDepth = 2
+
+// 每个属性在自己的长度范围内解析，长度和内容对不上时报格式错误
+func readAttribute(reader *ClassReader, cp *ConstantPool) AttributeInfo {
+	if reader.depth >= maxAttributeDepth {
+		reader.fail("attributes nested too deeply")
+	}
+	attrNameIndex := reader.readUint16()
+	attrName, ok := cp.lookupUtf8(attrNameIndex)
+	if !ok {
+		reader.pos -= 2
+		reader.fail("attribute name index %d is not a CONSTANT_Utf8", attrNameIndex)
+	}
+	attrLen := reader.readUint32()
+	start := reader.pos
+	info := reader.readBytes(attrLen)
+
+	attrInfo := newAttributeInfo(attrName, attrLen, cp)
+	if reader.attrNames != nil {
+		reader.attrNames[attrInfo] = attrNameIndex
+	}
+	sub := &ClassReader{data: reader.data[:start+len(info)], pos: start, attrNames: reader.attrNames, depth: reader.depth + 1}
+	attrInfo.readInfo(sub)
+	if sub.remaining() != 0 {
+		sub.fail("%s attribute has %d unparsed bytes", attrName, sub.remaining())
+	}
+	return attrInfo
+}
+
+func newAttributeInfo(attrName string, attrLen uint32, cp *ConstantPool) AttributeInfo {
+	switch attrName {
+	case "Code":
+		return &CodeAttribute{cp: cp}
+	case "ConstantValue":
+		return &ConstantValueAttribute{}
+	case "Deprecated":
+		return &DeprecatedAttribute{}
+	case "Exceptions":
+		return &ExceptionsAttribute{}
+	case "LineNumberTable":
+		return &LineNumberTableAttribute{}
+	case "LocalVariableTable":
+		return &LocalVariableTableAttribute{}
+	case "LocalVariableTypeTable":
+		return &LocalVariableTypeTableAttribute{}
+	case "SourceFile":
+		return &SourceFileAttribute{cp: cp}
+	case "Synthetic":
+		return &SyntheticAttribute{}
+	case "Signature":
+		return &SignatureAttribute{cp: cp}
+	case "StackMapTable":
+		return &StackMapTableAttribute{}
+	case "InnerClasses":
+		return &InnerClassesAttribute{}
+	case "EnclosingMethod":
+		return &EnclosingMethodAttribute{cp: cp}
+	case "BootstrapMethods":
+		return &BootstrapMethodsAttribute{}
+	case "MethodParameters":
+		return &MethodParametersAttribute{}
+	case "RuntimeVisibleAnnotations", "RuntimeInvisibleAnnotations":
+		return &AnnotationsAttribute{name: attrName}
+	case "RuntimeVisibleParameterAnnotations", "RuntimeInvisibleParameterAnnotations":
+		return &ParameterAnnotationsAttribute{name: attrName}
+	case "AnnotationDefault":
+		return &AnnotationDefaultAttribute{}
+	default:
+		return &UnparsedAttribute{attrName, attrLen, nil}
+	}
+}
+
+// attributeName 和newAttributeInfo对应，由属性结构体得到属性名
+func attributeName(attrInfo AttributeInfo) string {
+	switch attr := attrInfo.(type) {
+	case *CodeAttribute:
+		return "Code"
+	case *ConstantValueAttribute:
+		return "ConstantValue"
+	case *DeprecatedAttribute:
+		return "Deprecated"
+	case *ExceptionsAttribute:
+		return "Exceptions"
+	case *LineNumberTableAttribute:
+		return "LineNumberTable"
+	case *LocalVariableTableAttribute:
+		return "LocalVariableTable"
+	case *LocalVariableTypeTableAttribute:
+		return "LocalVariableTypeTable"
+	case *SourceFileAttribute:
+		return "SourceFile"
+	case *SyntheticAttribute:
+		return "Synthetic"
+	case *SignatureAttribute:
+		return "Signature"
+	case *StackMapTableAttribute:
+		return "StackMapTable"
+	case *InnerClassesAttribute:
+		return "InnerClasses"
+	case *EnclosingMethodAttribute:
+		return "EnclosingMethod"
+	case *BootstrapMethodsAttribute:
+		return "BootstrapMethods"
+	case *MethodParametersAttribute:
+		return "MethodParameters"
+	case *AnnotationsAttribute:
+		return attr.name
+	case *ParameterAnnotationsAttribute:
+		return attr.name
+	case *AnnotationDefaultAttribute:
+		return "AnnotationDefault"
+	case *UnparsedAttribute:
+		return attr.name
+	}
+	panic(fmt.Sprintf("unknown attribute type %T", attrInfo))
+}
+
+func writeAttributes(writer *ClassWriter, attributes []AttributeInfo) {
+	writer.writeCount(len(attributes), "attributes")
+	for _, attrInfo := range attributes {
+		writeAttribute(writer, attrInfo)
+	}
+}
+
+// 属性内容先写到子writer里，再据此填attribute_length
+func writeAttribute(writer *ClassWriter, attrInfo AttributeInfo) {
+	name := attributeName(attrInfo)
+	writer.writeUint16(writer.attrNameIndex(attrInfo, name))
+	sub := writer.sub()
+	attrInfo.writeInfo(sub)
+	if uint64(len(sub.data)) > 0xFFFFFFFF {
+		writer.fail("%s attribute too long: %d bytes", name, len(sub.data))
+	}
+	writer.writeUint32(uint32(len(sub.data)))
+	writer.writeBytes(sub.data)
+}
+
+// 尽量沿用解析时的attribute_name_index，常量池里有重复的Utf8时也能原样写回
+func (classWriter *ClassWriter) attrNameIndex(attrInfo AttributeInfo, name string) uint16 {
+	if index, ok := classWriter.attrNames[attrInfo]; ok {
+		if str, ok := classWriter.cp.lookupUtf8(index); ok && str == name {
+			return index
+		}
+	}
+	return classWriter.cp.AddUtf8(name)
+}
+
+func findSignatureAttribute(attributes []AttributeInfo) *SignatureAttribute {
+	for _, attrInfo := range attributes {
+		if attr, ok := attrInfo.(*SignatureAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
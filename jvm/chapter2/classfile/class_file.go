@@ -0,0 +1,253 @@
+package classfile
+
+/*
+	ClassFile {
+	    u4             magic;
+	    u2             minor_version;
+	    u2             major_version;
+	    u2             constant_pool_count;
+	    cp_info        constant_pool[constant_pool_count-1];
+	    u2             access_flags;
+	    u2             this_class;
+	    u2             super_class;
+	    u2             interfaces_count;
+	    u2             interfaces[interfaces_count];
+	    u2             fields_count;
+	    field_info     fields[fields_count];
+	    u2             methods_count;
+	    method_info    methods[methods_count];
+	    u2             attributes_count;
+	    attribute_info attributes[attributes_count];
+	}
+*/
+type ClassFile struct {
+	magic        uint32
+	minorVersion uint16
+	majorVersion uint16
+	constantPool *ConstantPool
+	accessFlags  uint16
+	thisClass    uint16
+	superClass   uint16
+	interfaces   []uint16
+	fields       []*MemberInfo
+	methods      []*MemberInfo
+	attributes   []AttributeInfo
+
+	attrNameIndexes map[AttributeInfo]uint16 // 解析时各属性的attribute_name_index
+}
+
+const classMagic = 0xCAFEBABE
+
+// Parse 把class文件数据解析成ClassFile结构体
+func Parse(classData []byte) (cf *ClassFile, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if formatError, ok := r.(*FormatError); ok {
+				cf, err = nil, formatError
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	cr := &ClassReader{data: classData, attrNames: map[AttributeInfo]uint16{}}
+	cf = &ClassFile{attrNameIndexes: cr.attrNames}
+	cf.read(cr)
+	return
+}
+
+// NewClassFile 新建一个空的ClassFile，常量池里只有不使用的0号位置
+func NewClassFile(minorVersion, majorVersion uint16) *ClassFile {
+	return &ClassFile{
+		magic:        classMagic,
+		minorVersion: minorVersion,
+		majorVersion: majorVersion,
+		constantPool: &ConstantPool{infos: []ConstantInfo{nil}},
+	}
+}
+
+func (classFile *ClassFile) read(reader *ClassReader) {
+	classFile.readAndCheckMagic(reader)
+	classFile.readAndCheckVersion(reader)
+	classFile.constantPool = readConstantPool(reader)
+	classFile.accessFlags = reader.readUint16()
+	classFile.thisClass = reader.readUint16()
+	classFile.superClass = reader.readUint16()
+	classFile.interfaces = reader.readUint16s()
+	classFile.fields = readMembers(reader, classFile.constantPool)
+	classFile.methods = readMembers(reader, classFile.constantPool)
+	classFile.attributes = readAttributes(reader, classFile.constantPool)
+	if reader.remaining() != 0 {
+		reader.fail("%d extra bytes at end of class file", reader.remaining())
+	}
+}
+
+func (classFile *ClassFile) readAndCheckMagic(reader *ClassReader) {
+	classFile.magic = reader.readUint32()
+	if classFile.magic != classMagic {
+		reader.fail("incompatible magic value %#x", classFile.magic)
+	}
+}
+
+// 版本号是否受支持留给格式检查去判断，这里只读出来
+func (classFile *ClassFile) readAndCheckVersion(reader *ClassReader) {
+	classFile.minorVersion = reader.readUint16()
+	classFile.majorVersion = reader.readUint16()
+}
+
+func (classFile *ClassFile) MinorVersion() uint16 {
+	return classFile.minorVersion
+}
+
+func (classFile *ClassFile) MajorVersion() uint16 {
+	return classFile.majorVersion
+}
+
+// ConstantPool 返回常量池；修改class时通过它的Add方法添加常量
+func (classFile *ClassFile) ConstantPool() *ConstantPool {
+	return classFile.constantPool
+}
+
+func (classFile *ClassFile) AccessFlags() uint16 {
+	return classFile.accessFlags
+}
+
+func (classFile *ClassFile) ThisClass() uint16 {
+	return classFile.thisClass
+}
+
+func (classFile *ClassFile) SuperClass() uint16 {
+	return classFile.superClass
+}
+
+func (classFile *ClassFile) Interfaces() []uint16 {
+	return classFile.interfaces
+}
+
+func (classFile *ClassFile) Fields() []*MemberInfo {
+	return classFile.fields
+}
+
+func (classFile *ClassFile) Methods() []*MemberInfo {
+	return classFile.methods
+}
+
+func (classFile *ClassFile) Attributes() []AttributeInfo {
+	return classFile.attributes
+}
+
+func (classFile *ClassFile) SetVersion(minorVersion, majorVersion uint16) {
+	classFile.minorVersion = minorVersion
+	classFile.majorVersion = majorVersion
+}
+
+func (classFile *ClassFile) SetAccessFlags(accessFlags uint16) {
+	classFile.accessFlags = accessFlags
+}
+
+func (classFile *ClassFile) SetThisClass(thisClass uint16) {
+	classFile.thisClass = thisClass
+}
+
+func (classFile *ClassFile) SetSuperClass(superClass uint16) {
+	classFile.superClass = superClass
+}
+
+func (classFile *ClassFile) SetInterfaces(interfaces []uint16) {
+	classFile.interfaces = interfaces
+}
+
+func (classFile *ClassFile) SetFields(fields []*MemberInfo) {
+	classFile.fields = fields
+}
+
+func (classFile *ClassFile) SetMethods(methods []*MemberInfo) {
+	classFile.methods = methods
+}
+
+func (classFile *ClassFile) SetAttributes(attributes []AttributeInfo) {
+	classFile.attributes = attributes
+}
+
+func (classFile *ClassFile) ClassName() string {
+	return classFile.constantPool.getClassName(classFile.thisClass)
+}
+
+// SuperClassName 返回超类名，java/lang/Object没有超类，返回空串
+func (classFile *ClassFile) SuperClassName() string {
+	if classFile.superClass > 0 {
+		return classFile.constantPool.getClassName(classFile.superClass)
+	}
+	return ""
+}
+
+func (classFile *ClassFile) InterfaceNames() []string {
+	interfaceNames := make([]string, len(classFile.interfaces))
+	for i, cpIndex := range classFile.interfaces {
+		interfaceNames[i] = classFile.constantPool.getClassName(cpIndex)
+	}
+	return interfaceNames
+}
+
+func (classFile *ClassFile) SourceFileAttribute() *SourceFileAttribute {
+	for _, attrInfo := range classFile.attributes {
+		if attr, ok := attrInfo.(*SourceFileAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (classFile *ClassFile) SignatureAttribute() *SignatureAttribute {
+	return findSignatureAttribute(classFile.attributes)
+}
+
+func (classFile *ClassFile) BootstrapMethodsAttribute() *BootstrapMethodsAttribute {
+	for _, attrInfo := range classFile.attributes {
+		if attr, ok := attrInfo.(*BootstrapMethodsAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (classFile *ClassFile) InnerClassesAttribute() *InnerClassesAttribute {
+	for _, attrInfo := range classFile.attributes {
+		if attr, ok := attrInfo.(*InnerClassesAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+// Bytes 把ClassFile序列化成class文件数据
+// 常量池中原有常量的索引保持不变，属性长度重新计算；没有修改过的class写出的数据和解析前完全一样
+func (classFile *ClassFile) Bytes() (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if writeError, ok := r.(*WriteError); ok {
+				data, err = nil, writeError
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	// 先写常量池后面的部分，写属性时可能还要往常量池里加属性名
+	body := &ClassWriter{cp: classFile.constantPool, attrNames: classFile.attrNameIndexes}
+	body.writeUint16(classFile.accessFlags)
+	body.writeUint16(classFile.thisClass)
+	body.writeUint16(classFile.superClass)
+	body.writeUint16s(classFile.interfaces, "interfaces")
+	writeMembers(body, classFile.fields, "fields")
+	writeMembers(body, classFile.methods, "methods")
+	writeAttributes(body, classFile.attributes)
+
+	writer := &ClassWriter{}
+	writer.writeUint32(classMagic)
+	writer.writeUint16(classFile.minorVersion)
+	writer.writeUint16(classFile.majorVersion)
+	classFile.constantPool.write(writer)
+	writer.writeBytes(body.data)
+	return writer.data, nil
+}
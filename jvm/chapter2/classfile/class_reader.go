@@ -0,0 +1,90 @@
+package classfile
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ClassReader 按大端序从class文件数据中读取u1/u2/u4
+// 数据不够时panic一个*FormatError，由Parse统一recover
+type ClassReader struct {
+	data      []byte
+	pos       int
+	attrNames map[AttributeInfo]uint16 // 记下每个属性的attribute_name_index，写回时沿用
+	depth     int                      // 属性的嵌套层数
+}
+
+// FormatError class文件结构错误
+type FormatError struct {
+	Offset int
+	Msg    string
+}
+
+func (formatError *FormatError) Error() string {
+	return fmt.Sprintf("malformed class file at offset %d: %s", formatError.Offset, formatError.Msg)
+}
+
+func (classReader *ClassReader) fail(format string, args ...interface{}) {
+	panic(&FormatError{classReader.pos, fmt.Sprintf(format, args...)})
+}
+
+func (classReader *ClassReader) remaining() int {
+	return len(classReader.data) - classReader.pos
+}
+
+func (classReader *ClassReader) need(n int) {
+	if n < 0 || classReader.remaining() < n {
+		classReader.fail("truncated class file: need %d bytes, have %d", n, classReader.remaining())
+	}
+}
+
+// u1
+func (classReader *ClassReader) readUint8() uint8 {
+	classReader.need(1)
+	val := classReader.data[classReader.pos]
+	classReader.pos++
+	return val
+}
+
+// u2
+func (classReader *ClassReader) readUint16() uint16 {
+	classReader.need(2)
+	val := binary.BigEndian.Uint16(classReader.data[classReader.pos:])
+	classReader.pos += 2
+	return val
+}
+
+// u4
+func (classReader *ClassReader) readUint32() uint32 {
+	classReader.need(4)
+	val := binary.BigEndian.Uint32(classReader.data[classReader.pos:])
+	classReader.pos += 4
+	return val
+}
+
+func (classReader *ClassReader) readUint64() uint64 {
+	classReader.need(8)
+	val := binary.BigEndian.Uint64(classReader.data[classReader.pos:])
+	classReader.pos += 8
+	return val
+}
+
+// 读取u2表，表的大小由开头的u2决定
+func (classReader *ClassReader) readUint16s() []uint16 {
+	n := int(classReader.readUint16())
+	classReader.need(n * 2)
+	s := make([]uint16, n)
+	for i := range s {
+		s[i] = classReader.readUint16()
+	}
+	return s
+}
+
+func (classReader *ClassReader) readBytes(n uint32) []byte {
+	if uint64(n) > uint64(classReader.remaining()) {
+		classReader.fail("truncated class file: need %d bytes, have %d", n, classReader.remaining())
+	}
+	bytes := classReader.data[classReader.pos : classReader.pos+int(n)]
+	classReader.pos += int(n)
+	return bytes
+}
@@ -0,0 +1,393 @@
+package classfile
+
+import "encoding/binary"
+
+// ClassView 直接建立在class文件数据上的只读视图，扫描大量类时代替Parse使用。
+// NewClassView只记下各个常量、字段、方法和属性的位置，名字、描述符和属性内容
+// 都是用到时才从原始数据中解码，不复制方法体。数据在视图使用期间不能修改。
+type ClassView struct {
+	data       []byte
+	cpOffsets  []int32 // 每个常量tag的位置；下标0和long/double的第二个位置为0
+	headerPos  int     // access_flags的位置
+	fields     []MemberView
+	methods    []MemberView
+	attributes []AttributeView
+
+	cp *ConstantPool // AttributeView.Decode第一次用到时才解析
+}
+
+// MemberView 字段或方法的视图
+type MemberView struct {
+	view       *ClassView
+	pos        int // access_flags的位置
+	attributes []AttributeView
+}
+
+// AttributeView 属性的视图，info直接引用原始数据
+type AttributeView struct {
+	view      *ClassView
+	nameIndex uint16
+	pos       int // attribute_name_index的位置
+	info      []byte
+}
+
+// NewClassView 检查class文件的整体结构并建立索引
+// 结构错误时返回*FormatError；常量之间的引用和属性内容不检查
+func NewClassView(classData []byte) (view *ClassView, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if formatError, ok := r.(*FormatError); ok {
+				view, err = nil, formatError
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	view = &ClassView{data: classData}
+	reader := &ClassReader{data: classData}
+	if magic := reader.readUint32(); magic != classMagic {
+		reader.pos -= 4
+		reader.fail("incompatible magic value %#x", magic)
+	}
+	reader.pos += 4 // minor_version、major_version
+	view.indexConstantPool(reader)
+	view.headerPos = reader.pos
+	reader.need(6)
+	reader.pos += 6 // access_flags、this_class、super_class
+	interfacesCount := int(reader.readUint16())
+	reader.readBytes(uint32(interfacesCount * 2))
+	view.fields = view.indexMembers(reader)
+	view.methods = view.indexMembers(reader)
+	view.attributes = view.indexAttributes(reader)
+	if reader.remaining() != 0 {
+		reader.fail("%d extra bytes at end of class file", reader.remaining())
+	}
+	return view, nil
+}
+
+// 常量的长度由tag决定，只有Utf8要读出长度
+func (classView *ClassView) indexConstantPool(reader *ClassReader) {
+	cpCount := int(reader.readUint16())
+	if cpCount == 0 {
+		reader.fail("constant_pool_count is 0")
+	}
+	reader.need((cpCount - 1) * 3)
+	classView.cpOffsets = make([]int32, cpCount)
+	for i := 1; i < cpCount; i++ {
+		classView.cpOffsets[i] = int32(reader.pos)
+		tag := reader.readUint8()
+		switch tag {
+		case CONSTANT_Class, CONSTANT_String, CONSTANT_MethodType, CONSTANT_Module, CONSTANT_Package:
+			reader.readBytes(2)
+		case CONSTANT_MethodHandle:
+			reader.readBytes(3)
+		case CONSTANT_Integer, CONSTANT_Float, CONSTANT_Fieldref, CONSTANT_Methodref,
+			CONSTANT_InterfaceMethodref, CONSTANT_NameAndType, CONSTANT_Dynamic, CONSTANT_InvokeDynamic:
+			reader.readBytes(4)
+		case CONSTANT_Long, CONSTANT_Double:
+			reader.readBytes(8)
+			i++ // 占两个位置
+			if i == cpCount {
+				reader.fail("long or double constant at the end of constant pool")
+			}
+		case CONSTANT_Utf8:
+			reader.readBytes(uint32(reader.readUint16()))
+		default:
+			reader.pos--
+			reader.fail("invalid constant pool tag %d", tag)
+		}
+	}
+}
+
+func (classView *ClassView) indexMembers(reader *ClassReader) []MemberView {
+	memberCount := int(reader.readUint16())
+	reader.need(memberCount * 8)
+	members := make([]MemberView, memberCount)
+	for i := range members {
+		members[i].view = classView
+		members[i].pos = reader.pos
+		reader.pos += 6
+		members[i].attributes = classView.indexAttributes(reader)
+	}
+	return members
+}
+
+// 只读属性头，属性内容留到用的时候再解析
+func (classView *ClassView) indexAttributes(reader *ClassReader) []AttributeView {
+	attributesCount := int(reader.readUint16())
+	if attributesCount == 0 {
+		return nil
+	}
+	reader.need(attributesCount * 6)
+	attributes := make([]AttributeView, attributesCount)
+	for i := range attributes {
+		pos := reader.pos
+		nameIndex := reader.readUint16()
+		if classView.Tag(nameIndex) != CONSTANT_Utf8 {
+			reader.pos -= 2
+			reader.fail("attribute name index %d is not a CONSTANT_Utf8", nameIndex)
+		}
+		attributes[i] = AttributeView{classView, nameIndex, pos, reader.readBytes(reader.readUint32())}
+	}
+	return attributes
+}
+
+func (classView *ClassView) u2(pos int) uint16 {
+	return binary.BigEndian.Uint16(classView.data[pos:])
+}
+
+// Data 返回class文件的原始数据
+func (classView *ClassView) Data() []byte {
+	return classView.data
+}
+
+func (classView *ClassView) MinorVersion() uint16 {
+	return classView.u2(4)
+}
+
+func (classView *ClassView) MajorVersion() uint16 {
+	return classView.u2(6)
+}
+
+func (classView *ClassView) AccessFlags() uint16 {
+	return classView.u2(classView.headerPos)
+}
+
+func (classView *ClassView) ThisClass() uint16 {
+	return classView.u2(classView.headerPos + 2)
+}
+
+func (classView *ClassView) SuperClass() uint16 {
+	return classView.u2(classView.headerPos + 4)
+}
+
+// ConstantPoolCount 返回constant_pool_count，常量的下标小于它
+func (classView *ClassView) ConstantPoolCount() int {
+	return len(classView.cpOffsets)
+}
+
+// Tag 返回常量的tag，索引无效时返回0
+func (classView *ClassView) Tag(index uint16) uint8 {
+	if int(index) < len(classView.cpOffsets) && classView.cpOffsets[index] != 0 {
+		return classView.data[classView.cpOffsets[index]]
+	}
+	return 0
+}
+
+// 常量tag后面第off个字节开始的u2；tag不符时返回0
+func (classView *ClassView) constantU2(index uint16, tag uint8, off int) uint16 {
+	if classView.Tag(index) != tag {
+		return 0
+	}
+	return classView.u2(int(classView.cpOffsets[index]) + 1 + off)
+}
+
+// Utf8Bytes 返回Utf8常量的原始MUTF-8字节，不复制；不是Utf8常量时返回nil
+func (classView *ClassView) Utf8Bytes(index uint16) []byte {
+	if classView.Tag(index) != CONSTANT_Utf8 {
+		return nil
+	}
+	pos := int(classView.cpOffsets[index]) + 3
+	return classView.data[pos : pos+int(classView.u2(pos-2))]
+}
+
+// Utf8 返回Utf8常量的字符串，和ConstantPool.GetUtf8一样，索引无效时返回空串
+func (classView *ClassView) Utf8(index uint16) string {
+	bytes := classView.Utf8Bytes(index)
+	for _, b := range bytes {
+		if b == 0 || b >= 0x80 {
+			return decodeMUTF8(bytes)
+		}
+	}
+	return string(bytes) // 纯ASCII，MUTF-8和UTF-8相同
+}
+
+// Utf8Equals 比较Utf8常量和s是否相同，结果和ConstantPool.GetUtf8(index) == s一样。
+// 常量是纯ASCII时直接比较字节，不分配内存
+func (classView *ClassView) Utf8Equals(index uint16, s string) bool {
+	bytes := classView.Utf8Bytes(index)
+	if bytes == nil {
+		return false
+	}
+	for _, b := range bytes {
+		if b == 0 || b >= 0x80 {
+			// \u0000、增补字符和非法的字节序列解码以后和原始字节不同，字节相同也不能说明相等
+			return decodeMUTF8(bytes) == s
+		}
+	}
+	return string(bytes) == s
+}
+
+// ClassName 返回Class常量中的类名，索引无效时返回空串
+func (classView *ClassView) ClassName(index uint16) string {
+	return classView.Utf8(classView.constantU2(index, CONSTANT_Class, 0))
+}
+
+// NameAndType 返回NameAndType常量中的名字和描述符
+func (classView *ClassView) NameAndType(index uint16) (string, string) {
+	return classView.Utf8(classView.constantU2(index, CONSTANT_NameAndType, 0)),
+		classView.Utf8(classView.constantU2(index, CONSTANT_NameAndType, 2))
+}
+
+// ThisClassName 返回本类的类名
+func (classView *ClassView) ThisClassName() string {
+	return classView.ClassName(classView.ThisClass())
+}
+
+// SuperClassName 返回超类名，java/lang/Object没有超类，返回空串
+func (classView *ClassView) SuperClassName() string {
+	return classView.ClassName(classView.SuperClass())
+}
+
+func (classView *ClassView) InterfaceCount() int {
+	return int(classView.u2(classView.headerPos + 6))
+}
+
+// InterfaceName 返回第i个接口的名字
+func (classView *ClassView) InterfaceName(i int) string {
+	return classView.ClassName(classView.u2(classView.headerPos + 8 + i*2))
+}
+
+func (classView *ClassView) InterfaceNames() []string {
+	interfaceNames := make([]string, classView.InterfaceCount())
+	for i := range interfaceNames {
+		interfaceNames[i] = classView.InterfaceName(i)
+	}
+	return interfaceNames
+}
+
+func (classView *ClassView) Fields() []MemberView {
+	return classView.fields
+}
+
+func (classView *ClassView) Methods() []MemberView {
+	return classView.methods
+}
+
+func (classView *ClassView) Attributes() []AttributeView {
+	return classView.attributes
+}
+
+// Attribute 按名字找类的属性
+func (classView *ClassView) Attribute(name string) (AttributeView, bool) {
+	return findAttributeView(classView.attributes, name)
+}
+
+// Field 按名字和描述符找字段
+func (classView *ClassView) Field(name, descriptor string) (MemberView, bool) {
+	return findMemberView(classView.fields, name, descriptor)
+}
+
+// Method 按名字和描述符找方法
+func (classView *ClassView) Method(name, descriptor string) (MemberView, bool) {
+	return findMemberView(classView.methods, name, descriptor)
+}
+
+func findMemberView(members []MemberView, name, descriptor string) (MemberView, bool) {
+	for _, member := range members {
+		if member.view.Utf8Equals(member.NameIndex(), name) &&
+			member.view.Utf8Equals(member.DescriptorIndex(), descriptor) {
+			return member, true
+		}
+	}
+	return MemberView{}, false
+}
+
+func findAttributeView(attributes []AttributeView, name string) (AttributeView, bool) {
+	for _, attr := range attributes {
+		if attr.view.Utf8Equals(attr.nameIndex, name) {
+			return attr, true
+		}
+	}
+	return AttributeView{}, false
+}
+
+// ClassFile 把整个class文件解析成ClassFile
+func (classView *ClassView) ClassFile() (*ClassFile, error) {
+	return Parse(classView.data)
+}
+
+// 解码属性时才解析常量池，之后复用
+func (classView *ClassView) constantPool() *ConstantPool {
+	if classView.cp == nil {
+		classView.cp = readConstantPool(&ClassReader{data: classView.data, pos: 8})
+	}
+	return classView.cp
+}
+
+func (memberView MemberView) AccessFlags() uint16 {
+	return memberView.view.u2(memberView.pos)
+}
+
+func (memberView MemberView) NameIndex() uint16 {
+	return memberView.view.u2(memberView.pos + 2)
+}
+
+func (memberView MemberView) DescriptorIndex() uint16 {
+	return memberView.view.u2(memberView.pos + 4)
+}
+
+func (memberView MemberView) Name() string {
+	return memberView.view.Utf8(memberView.NameIndex())
+}
+
+func (memberView MemberView) Descriptor() string {
+	return memberView.view.Utf8(memberView.DescriptorIndex())
+}
+
+func (memberView MemberView) Attributes() []AttributeView {
+	return memberView.attributes
+}
+
+// Attribute 按名字找成员的属性
+func (memberView MemberView) Attribute(name string) (AttributeView, bool) {
+	return findAttributeView(memberView.attributes, name)
+}
+
+// Code 返回方法字节码，不复制；没有Code属性或属性太短时返回nil
+func (memberView MemberView) Code() []byte {
+	attr, ok := memberView.Attribute("Code")
+	if !ok || len(attr.info) < 8 {
+		return nil
+	}
+	codeLength := binary.BigEndian.Uint32(attr.info[4:])
+	if uint64(codeLength) > uint64(len(attr.info)-8) {
+		return nil
+	}
+	return attr.info[8 : 8+codeLength]
+}
+
+func (attributeView AttributeView) NameIndex() uint16 {
+	return attributeView.nameIndex
+}
+
+func (attributeView AttributeView) Name() string {
+	return attributeView.view.Utf8(attributeView.nameIndex)
+}
+
+// Info 返回属性内容，不包括属性头，不复制
+func (attributeView AttributeView) Info() []byte {
+	return attributeView.info
+}
+
+// Decode 按属性名把属性内容解析成和Parse得到的一样的结构体
+// 第一次调用时会解析整个常量池，所以同一个视图的Decode不能并发调用
+func (attributeView AttributeView) Decode() (attr AttributeInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if formatError, ok := r.(*FormatError); ok {
+				attr, err = nil, formatError
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	view := attributeView.view
+	cp := view.constantPool()
+	// 连同属性头一起从原始数据解析，出错时的偏移量和Parse一致
+	end := attributeView.pos + 6 + len(attributeView.info)
+	reader := &ClassReader{data: view.data[:end], pos: attributeView.pos}
+	return readAttribute(reader, cp), nil
+}
@@ -0,0 +1,411 @@
+package classfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// addRawUtf8 把bytes原样作为Utf8常量加到常量池末尾，可以是非法的MUTF-8
+func addRawUtf8(cp *ConstantPool, bytes []byte) uint16 {
+	index := uint16(cp.Count())
+	cp.infos = append(cp.infos, &ConstantUtf8Info{str: decodeMUTF8(bytes), bytes: bytes})
+	return index
+}
+
+// viewTestClass 生成一个类：成员名和属性里有非ASCII字符、\u0000和增补字符，有重载的方法
+func viewTestClass(t *testing.T) []byte {
+	t.Helper()
+	cf := NewClassFile(0, 52)
+	cp := cf.ConstantPool()
+	cf.SetAccessFlags(ACC_PUBLIC | ACC_SUPER)
+	cf.SetThisClass(cp.AddClass("gen/视图"))
+	cf.SetSuperClass(cp.AddClass("java/lang/Object"))
+	cf.SetInterfaces([]uint16{cp.AddClass("java/lang/Runnable"), cp.AddClass("java/io/Serializable")})
+	cf.SetAttributes([]AttributeInfo{NewSourceFileAttribute(cp, "视图.java")})
+
+	name := NewMemberInfo(cp, ACC_PRIVATE, "名字", "Ljava/lang/String;")
+	count := NewMemberInfo(cp, ACC_STATIC|ACC_FINAL, "count", "I")
+	count.SetAttributes([]AttributeInfo{NewConstantValueAttribute(cp.AddInteger(3)), NewDeprecatedAttribute()})
+	cf.SetFields([]*MemberInfo{name, count, NewMemberInfo(cp, ACC_PRIVATE, "a\x00b", "J")})
+
+	method := func(flags uint16, name, descriptor string, maxLocals uint16, code ...byte) *MemberInfo {
+		m := NewMemberInfo(cp, flags, name, descriptor)
+		codeAttr := NewCodeAttribute(cp, 1, maxLocals, code)
+		codeAttr.SetAttributes([]AttributeInfo{NewLineNumberTableAttribute([]*LineNumberTableEntry{NewLineNumberTableEntry(0, 7)})})
+		m.SetAttributes([]AttributeInfo{codeAttr})
+		return m
+	}
+	cf.SetMethods([]*MemberInfo{
+		method(ACC_PUBLIC, "run", "()V", 1, 0xB1),                          // return
+		method(ACC_PUBLIC, "run", "(I)V", 2, 0xB1),                         // return
+		method(ACC_STATIC, "😀", "(I)I", 1, 0x1A, 0xAC),                     // iload_0; ireturn
+		method(ACC_STATIC, "中\x00", "()Ljava/lang/String;", 0, 0x01, 0xB0), // aconst_null; areturn
+	})
+	data, err := cf.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// 解码的结果和Parse一样
+func TestClassViewMatchesParse(t *testing.T) {
+	classes := map[string][]byte{"generated": viewTestClass(t)}
+	for _, name := range []string{"Hello.class", "pkg/Test.class"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes[name] = data
+	}
+	for name, data := range classes {
+		t.Run(name, func(t *testing.T) {
+			view, err := NewClassView(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cf, err := Parse(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if view.MinorVersion() != cf.MinorVersion() || view.MajorVersion() != cf.MajorVersion() ||
+				view.AccessFlags() != cf.AccessFlags() {
+				t.Errorf("header %d.%d %#x, want %d.%d %#x", view.MajorVersion(), view.MinorVersion(), view.AccessFlags(),
+					cf.MajorVersion(), cf.MinorVersion(), cf.AccessFlags())
+			}
+			if view.ThisClassName() != cf.ClassName() || view.SuperClassName() != cf.SuperClassName() {
+				t.Errorf("class %q extends %q, want %q extends %q", view.ThisClassName(), view.SuperClassName(), cf.ClassName(), cf.SuperClassName())
+			}
+			if !slices.Equal(view.InterfaceNames(), cf.InterfaceNames()) {
+				t.Errorf("interfaces %q, want %q", view.InterfaceNames(), cf.InterfaceNames())
+			}
+
+			cp := cf.ConstantPool()
+			if view.ConstantPoolCount() != cp.Count() {
+				t.Fatalf("constant pool count %d, want %d", view.ConstantPoolCount(), cp.Count())
+			}
+			for i := 0; i < cp.Count(); i++ {
+				index := uint16(i)
+				var tag uint8
+				if info := cp.GetConstantInfo(index); info != nil {
+					tag = info.Tag()
+				}
+				if view.Tag(index) != tag {
+					t.Errorf("#%d tag %d, want %d", i, view.Tag(index), tag)
+				}
+				if view.Utf8(index) != cp.GetUtf8(index) || view.ClassName(index) != cp.GetClassName(index) {
+					t.Errorf("#%d = %q %q, want %q %q", i, view.Utf8(index), view.ClassName(index), cp.GetUtf8(index), cp.GetClassName(index))
+				}
+				viewName, viewType := view.NameAndType(index)
+				if name, _type := cp.GetNameAndType(index); viewName != name || viewType != _type {
+					t.Errorf("#%d NameAndType %q %q, want %q %q", i, viewName, viewType, name, _type)
+				}
+			}
+
+			compareMembers(t, cf, "field", view.Fields(), cf.Fields())
+			compareMembers(t, cf, "method", view.Methods(), cf.Methods())
+			compareAttributes(t, cf, "class", view.Attributes(), cf.Attributes())
+		})
+	}
+}
+
+func compareMembers(t *testing.T, cf *ClassFile, kind string, views []MemberView, members []*MemberInfo) {
+	t.Helper()
+	if len(views) != len(members) {
+		t.Errorf("%d %ss, want %d", len(views), kind, len(members))
+		return
+	}
+	for i, member := range members {
+		view := views[i]
+		if view.AccessFlags() != member.AccessFlags() || view.Name() != member.Name() || view.Descriptor() != member.Descriptor() {
+			t.Errorf("%s %d = %#x %q %q, want %#x %q %q", kind, i, view.AccessFlags(), view.Name(), view.Descriptor(),
+				member.AccessFlags(), member.Name(), member.Descriptor())
+		}
+		if code := codeOf(member); !bytes.Equal(view.Code(), code) {
+			t.Errorf("%s %s code % x, want % x", kind, member.Name(), view.Code(), code)
+		}
+		compareAttributes(t, cf, kind+" "+member.Name(), view.Attributes(), member.Attributes())
+	}
+}
+
+// compareAttributes 属性名相同，Decode的结果和Parse得到的用cf的常量池写出来都和原来的一样
+func compareAttributes(t *testing.T, cf *ClassFile, owner string, views []AttributeView, attrs []AttributeInfo) {
+	t.Helper()
+	if len(views) != len(attrs) {
+		t.Errorf("%s has %d attributes, want %d", owner, len(views), len(attrs))
+		return
+	}
+	for i, attr := range attrs {
+		view := views[i]
+		if view.Name() != attributeName(attr) {
+			t.Errorf("%s attribute %d is %q, want %q", owner, i, view.Name(), attributeName(attr))
+			continue
+		}
+		decoded, err := view.Decode()
+		if err != nil {
+			t.Errorf("%s %s: %v", owner, view.Name(), err)
+			continue
+		}
+		got, want := &ClassWriter{cp: cf.ConstantPool()}, &ClassWriter{cp: cf.ConstantPool(), attrNames: cf.attrNameIndexes}
+		decoded.writeInfo(got)
+		attr.writeInfo(want)
+		if !bytes.Equal(want.data, view.Info()) || !bytes.Equal(got.data, view.Info()) {
+			t.Errorf("%s %s decoded as % x, parsed % x, want % x", owner, view.Name(), got.data, want.data, view.Info())
+		}
+	}
+}
+
+// Utf8Equals和按Parse解码的字符串比较的结果一样，原始字节相同不能说明相等
+func TestUtf8Equals(t *testing.T) {
+	supplementary := encodeMUTF8("😀") // 代理对，六个字节
+	tests := []struct {
+		name  string
+		bytes []byte
+		s     string
+		want  bool
+	}{
+		{"ascii", []byte("main"), "main", true},
+		{"ascii prefix", []byte("main"), "mai", false},
+		{"empty", []byte{}, "", true},
+		{"empty and non-empty", []byte{}, "a", false},
+		{"chinese", encodeMUTF8("名字"), "名字", true},
+		{"chinese mismatch", encodeMUTF8("名字"), "名", false},
+		{"nul", []byte{'a', 0xC0, 0x80}, "a\x00", true},
+		{"nul raw bytes", []byte{'a', 0xC0, 0x80}, "a\xc0\x80", false},
+		{"supplementary", supplementary, "😀", true},
+		{"supplementary raw bytes", supplementary, string(supplementary), false},
+		{"unpaired surrogate", []byte{0xED, 0xA0, 0x80}, "\xed\xa0\x80", true},
+		{"four-byte UTF-8", []byte("😀"), "😀", false},
+		{"zero byte", []byte{'a', 0, 'b'}, "a\x00b", false},
+		{"overlong", []byte{0xC1, 0x81}, "A", true},
+		{"overlong raw bytes", []byte{0xC1, 0x81}, "\xc1\x81", false},
+	}
+	cf := NewClassFile(0, 52)
+	cp := cf.ConstantPool()
+	cf.SetThisClass(cp.AddClass("gen/Utf8"))
+	indexes := make([]uint16, len(tests))
+	for i, test := range tests {
+		indexes[i] = addRawUtf8(cp, test.bytes)
+	}
+	data, err := cf.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := NewClassView(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, test := range tests {
+		index := indexes[i]
+		if got := view.Utf8Equals(index, test.s); got != test.want {
+			t.Errorf("%s: Utf8Equals(% x, %q) = %v, want %v", test.name, test.bytes, test.s, got, test.want)
+		}
+		if (parsed.ConstantPool().GetUtf8(index) == test.s) != test.want {
+			t.Errorf("%s: GetUtf8 = %q, want it to equal %q: %v", test.name, parsed.ConstantPool().GetUtf8(index), test.s, test.want)
+		}
+		if view.Utf8(index) != parsed.ConstantPool().GetUtf8(index) {
+			t.Errorf("%s: Utf8 = %q, GetUtf8 = %q", test.name, view.Utf8(index), parsed.ConstantPool().GetUtf8(index))
+		}
+	}
+	if view.Utf8Equals(view.ThisClass(), "gen/Utf8") {
+		t.Error("Utf8Equals matched a Class constant")
+	}
+}
+
+// Field和Method按名字和描述符查找，和遍历Parse的结果找到的一样
+func TestClassViewLookup(t *testing.T) {
+	data := viewTestClass(t)
+	view, err := NewClassView(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field      bool
+		name, desc string
+	}{
+		{true, "名字", "Ljava/lang/String;"},
+		{true, "count", "I"},
+		{true, "count", "J"},
+		{true, "a\x00b", "J"},
+		{true, "a\xc0\x80b", "J"},
+		{true, "run", "()V"},
+		{false, "run", "()V"},
+		{false, "run", "(I)V"},
+		{false, "run", "(J)V"},
+		{false, "😀", "(I)I"},
+		{false, string(encodeMUTF8("😀")), "(I)I"},
+		{false, "中\x00", "()Ljava/lang/String;"},
+		{false, "中", "()Ljava/lang/String;"},
+		{false, "main", "([Ljava/lang/String;)V"},
+	}
+	for _, test := range tests {
+		members, find := cf.Methods(), view.Method
+		if test.field {
+			members, find = cf.Fields(), view.Field
+		}
+		var want *MemberInfo
+		for _, member := range members {
+			if member.Name() == test.name && member.Descriptor() == test.desc {
+				want = member
+				break
+			}
+		}
+		got, ok := find(test.name, test.desc)
+		switch {
+		case ok != (want != nil):
+			t.Errorf("find %q %q: found %v, want %v", test.name, test.desc, ok, want != nil)
+		case ok && (got.Name() != want.Name() || got.AccessFlags() != want.AccessFlags() || !bytes.Equal(got.Code(), codeOf(want))):
+			t.Errorf("find %q %q = %#x %q, want %#x %q", test.name, test.desc, got.AccessFlags(), got.Name(), want.AccessFlags(), want.Name())
+		}
+	}
+	if attr, ok := view.Attribute("SourceFile"); !ok || attr.Name() != "SourceFile" {
+		t.Error("class attribute SourceFile not found")
+	}
+	if count, _ := view.Field("count", "I"); !hasAttribute(count, "Deprecated") || hasAttribute(count, "Synthetic") {
+		t.Error("field attributes not found by name")
+	}
+}
+
+func codeOf(member *MemberInfo) []byte {
+	if codeAttr := member.CodeAttribute(); codeAttr != nil {
+		return codeAttr.Code()
+	}
+	return nil
+}
+
+func hasAttribute(member MemberView, name string) bool {
+	_, ok := member.Attribute(name)
+	return ok
+}
+
+// 基准测试扫描rt.jar里的全部类：JAVA_HOME指向JDK 8时使用它的rt.jar，
+// 否则只扫描testdata下的类
+func benchmarkClasses(b *testing.B) ([][]byte, int64) {
+	b.Helper()
+	var classes [][]byte
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+		for _, path := range []string{
+			filepath.Join(javaHome, "jre", "lib", "rt.jar"),
+			filepath.Join(javaHome, "lib", "rt.jar"),
+		} {
+			if _, err := os.Stat(path); err == nil {
+				classes = readJar(b, path)
+				break
+			}
+		}
+	}
+	if classes == nil {
+		b.Log("rt.jar not found, scanning testdata only")
+		for _, name := range []string{"Hello.class", "pkg/Test.class"} {
+			data, err := os.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				b.Fatal(err)
+			}
+			classes = append(classes, data)
+		}
+	}
+	var size int64
+	for _, data := range classes {
+		size += int64(len(data))
+	}
+	return classes, size
+}
+
+func readJar(b *testing.B, path string) [][]byte {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer r.Close()
+	var classes [][]byte
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".class") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			b.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+		classes = append(classes, data)
+	}
+	return classes
+}
+
+// 典型的扫描：类名、超类名，以及每个方法的名字和描述符
+func BenchmarkScanParse(b *testing.B) {
+	classes, size := benchmarkClasses(b)
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range classes {
+			cf, err := Parse(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			cf.ClassName()
+			cf.SuperClassName()
+			for _, method := range cf.Methods() {
+				method.Name()
+				method.Descriptor()
+			}
+		}
+	}
+}
+
+func BenchmarkScanView(b *testing.B) {
+	classes, size := benchmarkClasses(b)
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range classes {
+			view, err := NewClassView(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			view.ThisClassName()
+			view.SuperClassName()
+			for _, method := range view.Methods() {
+				method.Name()
+				method.Descriptor()
+			}
+		}
+	}
+}
+
+// 只找特定的方法，名字用Utf8Equals比较，不需要解码
+func BenchmarkScanViewFindMain(b *testing.B) {
+	classes, size := benchmarkClasses(b)
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range classes {
+			view, err := NewClassView(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			view.Method("main", "([Ljava/lang/String;)V")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package classfile
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ClassWriter 按大端序写出u1/u2/u4，是ClassReader的反过程
+// 表的长度超出u2等无法编码的情况panic一个*WriteError，由Bytes统一recover
+type ClassWriter struct {
+	data      []byte
+	cp        *ConstantPool
+	attrNames map[AttributeInfo]uint16
+}
+
+// WriteError ClassFile无法序列化成合法的class文件
+type WriteError struct {
+	Msg string
+}
+
+func (writeError *WriteError) Error() string {
+	return "cannot write class file: " + writeError.Msg
+}
+
+func (classWriter *ClassWriter) fail(format string, args ...interface{}) {
+	panic(&WriteError{fmt.Sprintf(format, args...)})
+}
+
+// 写属性内容用的子writer，共享常量池
+func (classWriter *ClassWriter) sub() *ClassWriter {
+	return &ClassWriter{cp: classWriter.cp, attrNames: classWriter.attrNames}
+}
+
+// u1
+func (classWriter *ClassWriter) writeUint8(val uint8) {
+	classWriter.data = append(classWriter.data, val)
+}
+
+// u2
+func (classWriter *ClassWriter) writeUint16(val uint16) {
+	classWriter.data = binary.BigEndian.AppendUint16(classWriter.data, val)
+}
+
+// u4
+func (classWriter *ClassWriter) writeUint32(val uint32) {
+	classWriter.data = binary.BigEndian.AppendUint32(classWriter.data, val)
+}
+
+func (classWriter *ClassWriter) writeUint64(val uint64) {
+	classWriter.data = binary.BigEndian.AppendUint64(classWriter.data, val)
+}
+
+// 写表的大小，超出u2时报错
+func (classWriter *ClassWriter) writeCount(n int, what string) {
+	if n > 0xFFFF {
+		classWriter.fail("too many %s: %d", what, n)
+	}
+	classWriter.writeUint16(uint16(n))
+}
+
+// u1大小的表（方法参数个数等）
+func (classWriter *ClassWriter) writeCount8(n int, what string) {
+	if n > 0xFF {
+		classWriter.fail("too many %s: %d", what, n)
+	}
+	classWriter.writeUint8(uint8(n))
+}
+
+// 写u2表，表的大小写在开头
+func (classWriter *ClassWriter) writeUint16s(s []uint16, what string) {
+	classWriter.writeCount(len(s), what)
+	for _, val := range s {
+		classWriter.writeUint16(val)
+	}
+}
+
+func (classWriter *ClassWriter) writeBytes(bytes []byte) {
+	classWriter.data = append(classWriter.data, bytes...)
+}
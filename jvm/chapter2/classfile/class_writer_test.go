@@ -0,0 +1,193 @@
+package classfile
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// 没有修改过的class写出来和解析前逐字节相同
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{"Hello.class", "pkg/Test.class"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf, err := Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		out, err := cf.Bytes()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("%s: round trip differs (%d bytes in, %d bytes out)", name, len(data), len(out))
+		}
+	}
+}
+
+// 修改之后原有常量的索引不变，新常量加在后面，已有的常量不重复添加
+func TestAddConstants(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "Hello.class"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := cf.ConstantPool()
+	size := cp.Count()
+	if index := cp.AddUtf8("java/lang/Object"); cp.GetUtf8(index) != "java/lang/Object" || cp.Count() != size {
+		t.Errorf("AddUtf8 of an existing string added a constant")
+	}
+	longIndex := cp.AddLong(1 << 40)
+	if int(longIndex) != size || cp.Count() != size+2 {
+		t.Errorf("AddLong = %d, pool size %d; want %d, %d", longIndex, cp.Count(), size, size+2)
+	}
+	methodref := cp.AddMethodref("java/io/PrintStream", "flush", "()V")
+	cf.SetAttributes(append(cf.Attributes(), NewSourceFileAttribute(cp, "Renamed.java")))
+
+	out, err := cf.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rcp := reparsed.ConstantPool()
+	for i := 1; i < size; i++ {
+		if info := cp.GetConstantInfo(uint16(i)); info != nil && constantBytes(rcp.GetConstantInfo(uint16(i))) != constantBytes(info) {
+			t.Errorf("constant #%d changed", i)
+		}
+	}
+	if v := rcp.GetConstantInfo(longIndex).(*ConstantLongInfo).Value(); v != 1<<40 {
+		t.Errorf("long constant = %d", v)
+	}
+	ref := rcp.GetConstantInfo(methodref).(*ConstantMethodrefInfo)
+	if name, descriptor := ref.NameAndDescriptor(); ref.ClassName() != "java/io/PrintStream" || name != "flush" || descriptor != "()V" {
+		t.Errorf("methodref = %s.%s:%s", ref.ClassName(), name, descriptor)
+	}
+	attrs := reparsed.Attributes()
+	if sourceFile, ok := attrs[len(attrs)-1].(*SourceFileAttribute); !ok || sourceFile.FileName() != "Renamed.java" {
+		t.Errorf("last attribute = %#v", attrs[len(attrs)-1])
+	}
+	// 再写一次也不变
+	again, err := reparsed.Bytes()
+	if err != nil || !bytes.Equal(again, out) {
+		t.Errorf("second round trip differs: %v", err)
+	}
+}
+
+// 常量池里有几个相同的属性名时，每个没有内容的属性都沿用自己解析时的attribute_name_index
+func TestRoundTripMarkerAttributeNames(t *testing.T) {
+	cf := NewClassFile(0, 52)
+	cp := cf.ConstantPool()
+	cf.SetAccessFlags(ACC_PUBLIC | ACC_SUPER)
+	cf.SetThisClass(cp.AddClass("gen/Markers"))
+	cf.SetSuperClass(cp.AddClass("java/lang/Object"))
+	cf.attrNameIndexes = map[AttributeInfo]uint16{}
+	var fields []*MemberInfo
+	for i, name := range []string{"Deprecated", "Deprecated", "Synthetic", "Synthetic"} {
+		// 写入重复的Utf8常量，属性先用UnparsedAttribute写出去，解析回来才是标记属性
+		index := uint16(cp.Count())
+		cp.infos = append(cp.infos, &ConstantUtf8Info{str: name, bytes: encodeMUTF8(name)})
+		attr := &UnparsedAttribute{name: name}
+		cf.attrNameIndexes[attr] = index
+		field := NewMemberInfo(cp, ACC_PRIVATE, "f"+strconv.Itoa(i), "I")
+		field.SetAttributes([]AttributeInfo{attr})
+		fields = append(fields, field)
+	}
+	cf.SetFields(fields)
+	data, err := cf.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.Fields()[0].Attributes()[0].(*DeprecatedAttribute); !ok {
+		t.Fatalf("attribute parsed as %T", parsed.Fields()[0].Attributes()[0])
+	}
+	out, err := parsed.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("round trip changed the attribute_name_index of marker attributes")
+	}
+}
+
+// 相同的常量只加一次；字节相同但tag不同的常量不是同一个，池里原有的重复常量用第一个
+func TestAddConstantDedup(t *testing.T) {
+	cp := &ConstantPool{}
+	one := cp.AddInteger(1)
+	if cp.AddFloat(math.Float32frombits(1)) == one {
+		t.Error("float with the bits of int 1 shares its constant")
+	}
+	if cp.AddInteger(1) != one {
+		t.Error("AddInteger(1) added a second constant")
+	}
+	count := cp.Count()
+	for i := 0; i < 1000; i++ {
+		cp.AddUtf8(strconv.Itoa(i))
+	}
+	if index := cp.AddUtf8("500"); cp.GetUtf8(index) != "500" || cp.Count() != count+1000 {
+		t.Errorf("AddUtf8(\"500\") = #%d, pool size %d", index, cp.Count())
+	}
+
+	dup := &ConstantPool{infos: []ConstantInfo{nil, &ConstantIntegerInfo{val: 7}, &ConstantIntegerInfo{val: 7}}}
+	if index := dup.AddInteger(7); index != 1 || dup.Count() != 3 {
+		t.Errorf("AddInteger(7) = #%d, pool size %d; want #1, 3", index, dup.Count())
+	}
+}
+
+// 新建的class写出后能解析回来
+func TestWriteNewClass(t *testing.T) {
+	cf := NewClassFile(0, 52)
+	cp := cf.ConstantPool()
+	cf.SetAccessFlags(ACC_PUBLIC | ACC_SUPER)
+	cf.SetThisClass(cp.AddClass("gen/Point"))
+	cf.SetSuperClass(cp.AddClass("java/lang/Object"))
+	cf.SetFields([]*MemberInfo{NewMemberInfo(cp, ACC_PRIVATE, "x", "I")})
+	method := NewMemberInfo(cp, ACC_PUBLIC|ACC_STATIC, "zero", "()I")
+	method.SetAttributes([]AttributeInfo{NewCodeAttribute(cp, 1, 0, []byte{0x03, 0xAC})}) // iconst_0; ireturn
+	cf.SetMethods([]*MemberInfo{method})
+
+	data, err := cf.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ClassName() != "gen/Point" || parsed.SuperClassName() != "java/lang/Object" {
+		t.Errorf("class %s extends %s", parsed.ClassName(), parsed.SuperClassName())
+	}
+	if len(parsed.Fields()) != 1 || parsed.Fields()[0].Name() != "x" {
+		t.Errorf("fields = %v", parsed.Fields())
+	}
+	code := parsed.Methods()[0].CodeAttribute()
+	if code == nil || !bytes.Equal(code.Code(), []byte{0x03, 0xAC}) || code.MaxStack() != 1 {
+		t.Errorf("code = %v", code)
+	}
+}
+
+// 超出u2的表写不出来，返回*WriteError
+func TestWriteTooManyInterfaces(t *testing.T) {
+	cf := NewClassFile(0, 52)
+	cf.SetInterfaces(make([]uint16, 1<<16))
+	if _, err := cf.Bytes(); err == nil {
+		t.Error("expected an error")
+	} else if _, ok := err.(*WriteError); !ok {
+		t.Errorf("error %T, want *WriteError", err)
+	}
+}
@@ -0,0 +1,223 @@
+package classfile
+
+import "math"
+
+// ConstantPool 常量池，下标0不使用；long和double占两个位置，第二个位置为nil
+type ConstantPool struct {
+	infos   []ConstantInfo
+	indexes map[string]uint16 // add查找相同的常量用，键是constantKey，第一次add时建立
+}
+
+func readConstantPool(reader *ClassReader) *ConstantPool {
+	cpCount := int(reader.readUint16())
+	if cpCount == 0 {
+		reader.fail("constant_pool_count is 0")
+	}
+	// 每个常量至少3个字节，先检查剩余数据够不够，避免按伪造的count分配内存
+	reader.need((cpCount - 1) * 3)
+	cp := &ConstantPool{infos: make([]ConstantInfo, cpCount)}
+
+	for i := 1; i < cpCount; i++ { // 注意索引从1开始
+		cp.infos[i] = readConstantInfo(reader, cp)
+		switch cp.infos[i].(type) {
+		case *ConstantLongInfo, *ConstantDoubleInfo:
+			i++ // 占两个位置
+			if i == cpCount {
+				reader.fail("long or double constant at the end of constant pool")
+			}
+		}
+	}
+	return cp
+}
+
+// Count 返回constant_pool_count，常量的下标小于它
+func (constantPool *ConstantPool) Count() int {
+	return len(constantPool.infos)
+}
+
+// GetConstantInfo 按索引取常量，索引无效时返回nil
+func (constantPool *ConstantPool) GetConstantInfo(index uint16) ConstantInfo {
+	if int(index) < len(constantPool.infos) {
+		return constantPool.infos[index]
+	}
+	return nil
+}
+
+// GetNameAndType 返回NameAndType常量中的名字和描述符
+func (constantPool *ConstantPool) GetNameAndType(index uint16) (string, string) {
+	if ntInfo, ok := constantPool.GetConstantInfo(index).(*ConstantNameAndTypeInfo); ok {
+		name := constantPool.GetUtf8(ntInfo.nameIndex)
+		_type := constantPool.GetUtf8(ntInfo.descriptorIndex)
+		return name, _type
+	}
+	return "", ""
+}
+
+// GetClassName 返回Class常量中的类名
+func (constantPool *ConstantPool) GetClassName(index uint16) string {
+	if classInfo, ok := constantPool.GetConstantInfo(index).(*ConstantClassInfo); ok {
+		return constantPool.GetUtf8(classInfo.nameIndex)
+	}
+	return ""
+}
+
+// GetUtf8 返回Utf8常量的字符串
+func (constantPool *ConstantPool) GetUtf8(index uint16) string {
+	if utf8Info, ok := constantPool.GetConstantInfo(index).(*ConstantUtf8Info); ok {
+		return utf8Info.str
+	}
+	return ""
+}
+
+// 解析阶段用到的名字（属性名、成员名）必须是Utf8常量
+func (constantPool *ConstantPool) lookupUtf8(index uint16) (string, bool) {
+	utf8Info, ok := constantPool.GetConstantInfo(index).(*ConstantUtf8Info)
+	if !ok {
+		return "", false
+	}
+	return utf8Info.str, true
+}
+
+func (constantPool *ConstantPool) getClassName(index uint16) string {
+	return constantPool.GetClassName(index)
+}
+
+func (constantPool *ConstantPool) write(writer *ClassWriter) {
+	writer.writeCount(len(constantPool.infos), "constant pool entries")
+	for _, info := range constantPool.infos[1:] {
+		if info == nil {
+			continue // long和double的第二个位置
+		}
+		writer.writeUint8(info.Tag())
+		info.writeInfo(writer)
+	}
+}
+
+// add 把常量加到常量池末尾并返回索引；池里已有相同的常量时直接返回原来的索引，
+// 所以原有常量的索引都不会变
+func (constantPool *ConstantPool) add(info ConstantInfo) uint16 {
+	if len(constantPool.infos) == 0 {
+		constantPool.infos = append(constantPool.infos, nil) // 下标0不使用
+	}
+	if constantPool.indexes == nil {
+		constantPool.indexes = make(map[string]uint16, len(constantPool.infos))
+		for i, c := range constantPool.infos {
+			if c == nil {
+				continue
+			}
+			if key := constantKey(c); constantPool.indexes[key] == 0 {
+				constantPool.indexes[key] = uint16(i) // 有重复的常量时用第一个
+			}
+		}
+	}
+	key := constantKey(info)
+	if index, ok := constantPool.indexes[key]; ok {
+		return index
+	}
+	// 超出u2的部分写出时会报错
+	index := uint16(len(constantPool.infos))
+	constantPool.indexes[key] = index
+	constantPool.infos = append(constantPool.infos, info)
+	switch info.(type) {
+	case *ConstantLongInfo, *ConstantDoubleInfo:
+		constantPool.infos = append(constantPool.infos, nil)
+	}
+	return index
+}
+
+// constantKey tag加上序列化后的字节，相同的常量才有相同的键
+func constantKey(info ConstantInfo) string {
+	return string([]byte{info.Tag()}) + constantBytes(info)
+}
+
+// 常量序列化后的字节
+func constantBytes(info ConstantInfo) string {
+	writer := &ClassWriter{}
+	info.writeInfo(writer)
+	return string(writer.data)
+}
+
+func (constantPool *ConstantPool) AddUtf8(str string) uint16 {
+	return constantPool.add(&ConstantUtf8Info{str: str, bytes: encodeMUTF8(str)})
+}
+
+func (constantPool *ConstantPool) AddInteger(val int32) uint16 {
+	return constantPool.add(&ConstantIntegerInfo{val: val})
+}
+
+func (constantPool *ConstantPool) AddFloat(val float32) uint16 {
+	return constantPool.add(&ConstantFloatInfo{bits: math.Float32bits(val)})
+}
+
+func (constantPool *ConstantPool) AddLong(val int64) uint16 {
+	return constantPool.add(&ConstantLongInfo{val: val})
+}
+
+func (constantPool *ConstantPool) AddDouble(val float64) uint16 {
+	return constantPool.add(&ConstantDoubleInfo{bits: math.Float64bits(val)})
+}
+
+func (constantPool *ConstantPool) AddClass(name string) uint16 {
+	return constantPool.add(&ConstantClassInfo{cp: constantPool, nameIndex: constantPool.AddUtf8(name)})
+}
+
+func (constantPool *ConstantPool) AddString(str string) uint16 {
+	return constantPool.add(&ConstantStringInfo{cp: constantPool, stringIndex: constantPool.AddUtf8(str)})
+}
+
+func (constantPool *ConstantPool) AddNameAndType(name, descriptor string) uint16 {
+	return constantPool.add(&ConstantNameAndTypeInfo{
+		nameIndex:       constantPool.AddUtf8(name),
+		descriptorIndex: constantPool.AddUtf8(descriptor),
+	})
+}
+
+func (constantPool *ConstantPool) memberref(className, name, descriptor string) ConstantMemberrefInfo {
+	return ConstantMemberrefInfo{
+		cp:               constantPool,
+		classIndex:       constantPool.AddClass(className),
+		nameAndTypeIndex: constantPool.AddNameAndType(name, descriptor),
+	}
+}
+
+func (constantPool *ConstantPool) AddFieldref(className, name, descriptor string) uint16 {
+	return constantPool.add(&ConstantFieldrefInfo{constantPool.memberref(className, name, descriptor)})
+}
+
+func (constantPool *ConstantPool) AddMethodref(className, name, descriptor string) uint16 {
+	return constantPool.add(&ConstantMethodrefInfo{constantPool.memberref(className, name, descriptor)})
+}
+
+func (constantPool *ConstantPool) AddInterfaceMethodref(className, name, descriptor string) uint16 {
+	return constantPool.add(&ConstantInterfaceMethodrefInfo{constantPool.memberref(className, name, descriptor)})
+}
+
+// AddMethodHandle referenceIndex指向Fieldref/Methodref/InterfaceMethodref常量
+func (constantPool *ConstantPool) AddMethodHandle(referenceKind uint8, referenceIndex uint16) uint16 {
+	return constantPool.add(&ConstantMethodHandleInfo{
+		cp:             constantPool,
+		referenceKind:  referenceKind,
+		referenceIndex: referenceIndex,
+	})
+}
+
+func (constantPool *ConstantPool) AddMethodType(descriptor string) uint16 {
+	return constantPool.add(&ConstantMethodTypeInfo{cp: constantPool, descriptorIndex: constantPool.AddUtf8(descriptor)})
+}
+
+// AddInvokeDynamic bootstrapMethodAttrIndex是BootstrapMethods属性里的下标
+func (constantPool *ConstantPool) AddInvokeDynamic(bootstrapMethodAttrIndex uint16, name, descriptor string) uint16 {
+	return constantPool.add(&ConstantInvokeDynamicInfo{
+		cp:                       constantPool,
+		bootstrapMethodAttrIndex: bootstrapMethodAttrIndex,
+		nameAndTypeIndex:         constantPool.AddNameAndType(name, descriptor),
+	})
+}
+
+func (constantPool *ConstantPool) AddDynamic(bootstrapMethodAttrIndex uint16, name, descriptor string) uint16 {
+	return constantPool.add(&ConstantDynamicInfo{ConstantInvokeDynamicInfo{
+		cp:                       constantPool,
+		bootstrapMethodAttrIndex: bootstrapMethodAttrIndex,
+		nameAndTypeIndex:         constantPool.AddNameAndType(name, descriptor),
+	}})
+}
@@ -0,0 +1,54 @@
+package classfile
+
+/*
+	CONSTANT_Class_info {
+	    u1 tag;
+	    u2 name_index;
+	}
+*/
+type ConstantClassInfo struct {
+	cp        *ConstantPool
+	nameIndex uint16
+}
+
+func (constantClassInfo *ConstantClassInfo) readInfo(reader *ClassReader) {
+	constantClassInfo.nameIndex = reader.readUint16()
+}
+
+func (constantClassInfo *ConstantClassInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantClassInfo.nameIndex)
+}
+
+func (constantClassInfo *ConstantClassInfo) Tag() uint8 { return CONSTANT_Class }
+
+func (constantClassInfo *ConstantClassInfo) NameIndex() uint16 {
+	return constantClassInfo.nameIndex
+}
+
+func (constantClassInfo *ConstantClassInfo) Name() string {
+	return constantClassInfo.cp.GetUtf8(constantClassInfo.nameIndex)
+}
+
+/*
+	CONSTANT_Module_info {
+	    u1 tag;
+	    u2 name_index;
+	}
+*/
+type ConstantModuleInfo struct {
+	ConstantClassInfo
+}
+
+func (constantModuleInfo *ConstantModuleInfo) Tag() uint8 { return CONSTANT_Module }
+
+/*
+	CONSTANT_Package_info {
+	    u1 tag;
+	    u2 name_index;
+	}
+*/
+type ConstantPackageInfo struct {
+	ConstantClassInfo
+}
+
+func (constantPackageInfo *ConstantPackageInfo) Tag() uint8 { return CONSTANT_Package }
@@ -0,0 +1,128 @@
+package classfile
+
+// MethodHandle的reference_kind
+const (
+	REF_getField         = 1
+	REF_getStatic        = 2
+	REF_putField         = 3
+	REF_putStatic        = 4
+	REF_invokeVirtual    = 5
+	REF_invokeStatic     = 6
+	REF_invokeSpecial    = 7
+	REF_newInvokeSpecial = 8
+	REF_invokeInterface  = 9
+)
+
+/*
+	CONSTANT_MethodHandle_info {
+	    u1 tag;
+	    u1 reference_kind;
+	    u2 reference_index;
+	}
+*/
+type ConstantMethodHandleInfo struct {
+	cp             *ConstantPool
+	referenceKind  uint8
+	referenceIndex uint16
+}
+
+func (constantMethodHandleInfo *ConstantMethodHandleInfo) readInfo(reader *ClassReader) {
+	constantMethodHandleInfo.referenceKind = reader.readUint8()
+	constantMethodHandleInfo.referenceIndex = reader.readUint16()
+}
+
+func (constantMethodHandleInfo *ConstantMethodHandleInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint8(constantMethodHandleInfo.referenceKind)
+	writer.writeUint16(constantMethodHandleInfo.referenceIndex)
+}
+
+func (constantMethodHandleInfo *ConstantMethodHandleInfo) Tag() uint8 { return CONSTANT_MethodHandle }
+
+func (constantMethodHandleInfo *ConstantMethodHandleInfo) ReferenceKind() uint8 {
+	return constantMethodHandleInfo.referenceKind
+}
+
+func (constantMethodHandleInfo *ConstantMethodHandleInfo) ReferenceIndex() uint16 {
+	return constantMethodHandleInfo.referenceIndex
+}
+
+/*
+	CONSTANT_MethodType_info {
+	    u1 tag;
+	    u2 descriptor_index;
+	}
+*/
+type ConstantMethodTypeInfo struct {
+	cp              *ConstantPool
+	descriptorIndex uint16
+}
+
+func (constantMethodTypeInfo *ConstantMethodTypeInfo) readInfo(reader *ClassReader) {
+	constantMethodTypeInfo.descriptorIndex = reader.readUint16()
+}
+
+func (constantMethodTypeInfo *ConstantMethodTypeInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantMethodTypeInfo.descriptorIndex)
+}
+
+func (constantMethodTypeInfo *ConstantMethodTypeInfo) Tag() uint8 { return CONSTANT_MethodType }
+
+func (constantMethodTypeInfo *ConstantMethodTypeInfo) DescriptorIndex() uint16 {
+	return constantMethodTypeInfo.descriptorIndex
+}
+
+func (constantMethodTypeInfo *ConstantMethodTypeInfo) Descriptor() string {
+	return constantMethodTypeInfo.cp.GetUtf8(constantMethodTypeInfo.descriptorIndex)
+}
+
+/*
+	CONSTANT_InvokeDynamic_info {
+	    u1 tag;
+	    u2 bootstrap_method_attr_index;
+	    u2 name_and_type_index;
+	}
+*/
+type ConstantInvokeDynamicInfo struct {
+	cp                       *ConstantPool
+	bootstrapMethodAttrIndex uint16
+	nameAndTypeIndex         uint16
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) readInfo(reader *ClassReader) {
+	constantInvokeDynamicInfo.bootstrapMethodAttrIndex = reader.readUint16()
+	constantInvokeDynamicInfo.nameAndTypeIndex = reader.readUint16()
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantInvokeDynamicInfo.bootstrapMethodAttrIndex)
+	writer.writeUint16(constantInvokeDynamicInfo.nameAndTypeIndex)
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) Tag() uint8 {
+	return CONSTANT_InvokeDynamic
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) BootstrapMethodAttrIndex() uint16 {
+	return constantInvokeDynamicInfo.bootstrapMethodAttrIndex
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) NameAndTypeIndex() uint16 {
+	return constantInvokeDynamicInfo.nameAndTypeIndex
+}
+
+func (constantInvokeDynamicInfo *ConstantInvokeDynamicInfo) NameAndDescriptor() (string, string) {
+	return constantInvokeDynamicInfo.cp.GetNameAndType(constantInvokeDynamicInfo.nameAndTypeIndex)
+}
+
+/*
+	CONSTANT_Dynamic_info {
+	    u1 tag;
+	    u2 bootstrap_method_attr_index;
+	    u2 name_and_type_index;
+	}
+*/
+type ConstantDynamicInfo struct {
+	ConstantInvokeDynamicInfo
+}
+
+func (constantDynamicInfo *ConstantDynamicInfo) Tag() uint8 { return CONSTANT_Dynamic }
@@ -0,0 +1,54 @@
+package classfile
+
+/*
+	CONSTANT_Fieldref_info {
+	    u1 tag;
+	    u2 class_index;
+	    u2 name_and_type_index;
+	}
+
+CONSTANT_Methodref_info和CONSTANT_InterfaceMethodref_info结构相同
+*/
+type ConstantMemberrefInfo struct {
+	cp               *ConstantPool
+	classIndex       uint16
+	nameAndTypeIndex uint16
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) readInfo(reader *ClassReader) {
+	constantMemberrefInfo.classIndex = reader.readUint16()
+	constantMemberrefInfo.nameAndTypeIndex = reader.readUint16()
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantMemberrefInfo.classIndex)
+	writer.writeUint16(constantMemberrefInfo.nameAndTypeIndex)
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) ClassIndex() uint16 {
+	return constantMemberrefInfo.classIndex
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) NameAndTypeIndex() uint16 {
+	return constantMemberrefInfo.nameAndTypeIndex
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) ClassName() string {
+	return constantMemberrefInfo.cp.GetClassName(constantMemberrefInfo.classIndex)
+}
+
+func (constantMemberrefInfo *ConstantMemberrefInfo) NameAndDescriptor() (string, string) {
+	return constantMemberrefInfo.cp.GetNameAndType(constantMemberrefInfo.nameAndTypeIndex)
+}
+
+type ConstantFieldrefInfo struct{ ConstantMemberrefInfo }
+type ConstantMethodrefInfo struct{ ConstantMemberrefInfo }
+type ConstantInterfaceMethodrefInfo struct{ ConstantMemberrefInfo }
+
+func (constantFieldrefInfo *ConstantFieldrefInfo) Tag() uint8 { return CONSTANT_Fieldref }
+
+func (constantMethodrefInfo *ConstantMethodrefInfo) Tag() uint8 { return CONSTANT_Methodref }
+
+func (constantInterfaceMethodrefInfo *ConstantInterfaceMethodrefInfo) Tag() uint8 {
+	return CONSTANT_InterfaceMethodref
+}
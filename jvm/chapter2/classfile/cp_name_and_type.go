@@ -0,0 +1,33 @@
+package classfile
+
+/*
+	CONSTANT_NameAndType_info {
+	    u1 tag;
+	    u2 name_index;
+	    u2 descriptor_index;
+	}
+*/
+type ConstantNameAndTypeInfo struct {
+	nameIndex       uint16
+	descriptorIndex uint16
+}
+
+func (constantNameAndTypeInfo *ConstantNameAndTypeInfo) readInfo(reader *ClassReader) {
+	constantNameAndTypeInfo.nameIndex = reader.readUint16()
+	constantNameAndTypeInfo.descriptorIndex = reader.readUint16()
+}
+
+func (constantNameAndTypeInfo *ConstantNameAndTypeInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantNameAndTypeInfo.nameIndex)
+	writer.writeUint16(constantNameAndTypeInfo.descriptorIndex)
+}
+
+func (constantNameAndTypeInfo *ConstantNameAndTypeInfo) Tag() uint8 { return CONSTANT_NameAndType }
+
+func (constantNameAndTypeInfo *ConstantNameAndTypeInfo) NameIndex() uint16 {
+	return constantNameAndTypeInfo.nameIndex
+}
+
+func (constantNameAndTypeInfo *ConstantNameAndTypeInfo) DescriptorIndex() uint16 {
+	return constantNameAndTypeInfo.descriptorIndex
+}
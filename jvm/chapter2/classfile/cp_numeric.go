@@ -0,0 +1,103 @@
+package classfile
+
+import "math"
+
+/*
+	CONSTANT_Integer_info {
+	    u1 tag;
+	    u4 bytes;
+	}
+*/
+type ConstantIntegerInfo struct {
+	val int32
+}
+
+func (constantIntegerInfo *ConstantIntegerInfo) readInfo(reader *ClassReader) {
+	bytes := reader.readUint32()
+	constantIntegerInfo.val = int32(bytes)
+}
+
+func (constantIntegerInfo *ConstantIntegerInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint32(uint32(constantIntegerInfo.val))
+}
+
+func (constantIntegerInfo *ConstantIntegerInfo) Tag() uint8 { return CONSTANT_Integer }
+
+func (constantIntegerInfo *ConstantIntegerInfo) Value() int32 {
+	return constantIntegerInfo.val
+}
+
+/*
+	CONSTANT_Float_info {
+	    u1 tag;
+	    u4 bytes;
+	}
+*/
+type ConstantFloatInfo struct {
+	bits uint32 // 保留原始位模式，NaN的payload也能原样写回
+}
+
+func (constantFloatInfo *ConstantFloatInfo) readInfo(reader *ClassReader) {
+	constantFloatInfo.bits = reader.readUint32()
+}
+
+func (constantFloatInfo *ConstantFloatInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint32(constantFloatInfo.bits)
+}
+
+func (constantFloatInfo *ConstantFloatInfo) Tag() uint8 { return CONSTANT_Float }
+
+func (constantFloatInfo *ConstantFloatInfo) Value() float32 {
+	return math.Float32frombits(constantFloatInfo.bits)
+}
+
+/*
+	CONSTANT_Long_info {
+	    u1 tag;
+	    u4 high_bytes;
+	    u4 low_bytes;
+	}
+*/
+type ConstantLongInfo struct {
+	val int64
+}
+
+func (constantLongInfo *ConstantLongInfo) readInfo(reader *ClassReader) {
+	bytes := reader.readUint64()
+	constantLongInfo.val = int64(bytes)
+}
+
+func (constantLongInfo *ConstantLongInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint64(uint64(constantLongInfo.val))
+}
+
+func (constantLongInfo *ConstantLongInfo) Tag() uint8 { return CONSTANT_Long }
+
+func (constantLongInfo *ConstantLongInfo) Value() int64 {
+	return constantLongInfo.val
+}
+
+/*
+	CONSTANT_Double_info {
+	    u1 tag;
+	    u4 high_bytes;
+	    u4 low_bytes;
+	}
+*/
+type ConstantDoubleInfo struct {
+	bits uint64
+}
+
+func (constantDoubleInfo *ConstantDoubleInfo) readInfo(reader *ClassReader) {
+	constantDoubleInfo.bits = reader.readUint64()
+}
+
+func (constantDoubleInfo *ConstantDoubleInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint64(constantDoubleInfo.bits)
+}
+
+func (constantDoubleInfo *ConstantDoubleInfo) Tag() uint8 { return CONSTANT_Double }
+
+func (constantDoubleInfo *ConstantDoubleInfo) Value() float64 {
+	return math.Float64frombits(constantDoubleInfo.bits)
+}
@@ -0,0 +1,30 @@
+package classfile
+
+/*
+	CONSTANT_String_info {
+	    u1 tag;
+	    u2 string_index;
+	}
+*/
+type ConstantStringInfo struct {
+	cp          *ConstantPool
+	stringIndex uint16
+}
+
+func (constantStringInfo *ConstantStringInfo) readInfo(reader *ClassReader) {
+	constantStringInfo.stringIndex = reader.readUint16()
+}
+
+func (constantStringInfo *ConstantStringInfo) writeInfo(writer *ClassWriter) {
+	writer.writeUint16(constantStringInfo.stringIndex)
+}
+
+func (constantStringInfo *ConstantStringInfo) Tag() uint8 { return CONSTANT_String }
+
+func (constantStringInfo *ConstantStringInfo) StringIndex() uint16 {
+	return constantStringInfo.stringIndex
+}
+
+func (constantStringInfo *ConstantStringInfo) String() string {
+	return constantStringInfo.cp.GetUtf8(constantStringInfo.stringIndex)
+}
@@ -0,0 +1,52 @@
+package classfile
+
+import "go.buppt.cn/jvm/chapter2/mutf8"
+
+/*
+	CONSTANT_Utf8_info {
+	    u1 tag;
+	    u2 length;
+	    u1 bytes[length];
+	}
+*/
+type ConstantUtf8Info struct {
+	str   string
+	bytes []byte // 原始的MUTF-8字节
+}
+
+func (constantUtf8Info *ConstantUtf8Info) readInfo(reader *ClassReader) {
+	length := uint32(reader.readUint16())
+	constantUtf8Info.bytes = reader.readBytes(length)
+	constantUtf8Info.str = decodeMUTF8(constantUtf8Info.bytes)
+}
+
+func (constantUtf8Info *ConstantUtf8Info) writeInfo(writer *ClassWriter) {
+	if len(constantUtf8Info.bytes) > 0xFFFF {
+		writer.fail("CONSTANT_Utf8 too long: %d bytes", len(constantUtf8Info.bytes))
+	}
+	writer.writeUint16(uint16(len(constantUtf8Info.bytes)))
+	writer.writeBytes(constantUtf8Info.bytes)
+}
+
+func (constantUtf8Info *ConstantUtf8Info) Tag() uint8 { return CONSTANT_Utf8 }
+
+func (constantUtf8Info *ConstantUtf8Info) Str() string {
+	return constantUtf8Info.str
+}
+
+// Bytes 返回原始的MUTF-8字节
+func (constantUtf8Info *ConstantUtf8Info) Bytes() []byte {
+	return constantUtf8Info.bytes
+}
+
+// decodeMUTF8 把Java的Modified UTF-8解码成Go字符串，不成对的代理按mutf8包的约定保留；
+// 遇到非法字节序列时用U+FFFD代替，不会panic
+func decodeMUTF8(bytes []byte) string {
+	return mutf8.ToString(mutf8.Decode(bytes))
+}
+
+// encodeMUTF8 把Go字符串编码成Modified UTF-8：
+// \u0000用两个字节表示，增补字符先拆成代理对再分别编码
+func encodeMUTF8(s string) []byte {
+	return mutf8.Encode(mutf8.FromString(s))
+}
@@ -0,0 +1,753 @@
+package classfile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// 支持的class文件版本，和JDK 8一样是45.0到52.0
+const (
+	MinMajorVersion = 45
+	MaxMajorVersion = 52
+)
+
+// FormatViolation 一条违反JVMS 4.8格式约束的错误
+type FormatViolation struct {
+	CPIndex uint16 // 出错的常量池索引，和常量池无关时为0
+	Member  string // 出错的字段或方法，如 "method main([Ljava/lang/String;)V"，类本身为空
+	Msg     string
+}
+
+func (formatViolation *FormatViolation) String() string {
+	switch {
+	case formatViolation.CPIndex != 0 && formatViolation.Member != "":
+		return fmt.Sprintf("%s, constant pool #%d: %s", formatViolation.Member, formatViolation.CPIndex, formatViolation.Msg)
+	case formatViolation.CPIndex != 0:
+		return fmt.Sprintf("constant pool #%d: %s", formatViolation.CPIndex, formatViolation.Msg)
+	case formatViolation.Member != "":
+		return fmt.Sprintf("%s: %s", formatViolation.Member, formatViolation.Msg)
+	}
+	return formatViolation.Msg
+}
+
+// ClassFormatError 格式检查没通过，Violations包含所有错误
+type ClassFormatError struct {
+	ClassName  string
+	Violations []*FormatViolation
+}
+
+// Error 和HotSpot的ClassFormatError消息一样只说第一个错误
+func (classFormatError *ClassFormatError) Error() string {
+	msg := fmt.Sprintf("%s in class file %s", classFormatError.Violations[0], classFormatError.ClassName)
+	if n := len(classFormatError.Violations) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// CheckFormat 按JVMS 4.8检查class文件的格式，返回nil或者*ClassFormatError
+// 属性长度和内容是否一致在Parse时已经检查过了
+func CheckFormat(classFile *ClassFile) error {
+	checker := &formatChecker{classFile: classFile, cp: classFile.constantPool}
+	checker.check()
+	if len(checker.violations) == 0 {
+		return nil
+	}
+	className, _ := checker.className(classFile.thisClass)
+	if className == "" {
+		className = "<unknown>"
+	}
+	return &ClassFormatError{strings.ReplaceAll(className, "/", "."), checker.violations}
+}
+
+type formatChecker struct {
+	classFile  *ClassFile
+	cp         *ConstantPool
+	member     string // 正在检查的成员
+	bootstrap  *BootstrapMethodsAttribute
+	violations []*FormatViolation
+}
+
+func (checker *formatChecker) fail(format string, args ...interface{}) {
+	checker.failAt(0, format, args...)
+}
+
+func (checker *formatChecker) failAt(cpIndex uint16, format string, args ...interface{}) {
+	checker.violations = append(checker.violations, &FormatViolation{cpIndex, checker.member, fmt.Sprintf(format, args...)})
+}
+
+func (checker *formatChecker) check() {
+	classFile := checker.classFile
+	checker.checkVersion()
+	for _, attr := range classFile.attributes {
+		if bsm, ok := attr.(*BootstrapMethodsAttribute); ok {
+			checker.bootstrap = bsm
+		}
+	}
+	checker.checkConstantPool()
+	checker.checkClass()
+	checker.checkFields()
+	checker.checkMethods()
+	checker.member = ""
+	checker.checkAttributes(classFile.attributes, "class")
+}
+
+func (checker *formatChecker) version() uint16 {
+	return checker.classFile.majorVersion
+}
+
+func (checker *formatChecker) checkVersion() {
+	major, minor := checker.classFile.majorVersion, checker.classFile.minorVersion
+	if major < MinMajorVersion || major > MaxMajorVersion {
+		checker.fail("unsupported major.minor version %d.%d", major, minor)
+	}
+}
+
+// 常量池
+
+// entry 按索引取常量，索引越界或者指向long/double的第二个位置时返回nil
+func (checker *formatChecker) entry(index uint16) ConstantInfo {
+	if index == 0 {
+		return nil
+	}
+	return checker.cp.GetConstantInfo(index)
+}
+
+// utf8 取Utf8常量，不是Utf8时记一条错误
+func (checker *formatChecker) utf8(from, index uint16, what string) (string, bool) {
+	info, ok := checker.entry(index).(*ConstantUtf8Info)
+	if !ok {
+		checker.failAt(from, "%s index %d is not a CONSTANT_Utf8", what, index)
+		return "", false
+	}
+	return info.str, true
+}
+
+func (checker *formatChecker) className(index uint16) (string, bool) {
+	info, ok := checker.entry(index).(*ConstantClassInfo)
+	if !ok {
+		return "", false
+	}
+	name, ok := checker.entry(info.nameIndex).(*ConstantUtf8Info)
+	if !ok {
+		return "", false
+	}
+	return name.str, true
+}
+
+// classRef 检查index是不是Class常量
+func (checker *formatChecker) classRef(from, index uint16, what string) {
+	if _, ok := checker.entry(index).(*ConstantClassInfo); !ok {
+		checker.failAt(from, "%s index %d is not a CONSTANT_Class", what, index)
+	}
+}
+
+func (checker *formatChecker) checkConstantPool() {
+	for i, info := range checker.cp.infos {
+		index := uint16(i)
+		switch c := info.(type) {
+		case *ConstantUtf8Info:
+			if !bytes.Equal(encodeMUTF8(c.str), c.bytes) {
+				checker.failAt(index, "illegal modified UTF-8 string")
+			}
+		case *ConstantModuleInfo, *ConstantPackageInfo:
+			checker.failAt(index, "CONSTANT_Module and CONSTANT_Package are only allowed in module-info")
+		case *ConstantClassInfo:
+			if name, ok := checker.utf8(index, c.nameIndex, "name"); ok && !validClassName(name) {
+				checker.failAt(index, "illegal class name %q", name)
+			}
+		case *ConstantStringInfo:
+			checker.utf8(index, c.stringIndex, "string")
+		case *ConstantNameAndTypeInfo:
+			checker.utf8(index, c.nameIndex, "name")
+			checker.utf8(index, c.descriptorIndex, "descriptor")
+		case *ConstantFieldrefInfo:
+			checker.checkMemberref(index, &c.ConstantMemberrefInfo, false)
+		case *ConstantMethodrefInfo:
+			checker.checkMemberref(index, &c.ConstantMemberrefInfo, true)
+		case *ConstantInterfaceMethodrefInfo:
+			checker.checkMemberref(index, &c.ConstantMemberrefInfo, true)
+		case *ConstantMethodHandleInfo:
+			checker.requireVersion(index, 51, "CONSTANT_MethodHandle")
+			checker.checkMethodHandle(index, c)
+		case *ConstantMethodTypeInfo:
+			checker.requireVersion(index, 51, "CONSTANT_MethodType")
+			if desc, ok := checker.utf8(index, c.descriptorIndex, "descriptor"); ok && !validMethodDescriptor(desc) {
+				checker.failAt(index, "illegal method descriptor %q", desc)
+			}
+		case *ConstantDynamicInfo:
+			checker.requireVersion(index, 55, "CONSTANT_Dynamic")
+		case *ConstantInvokeDynamicInfo:
+			checker.requireVersion(index, 51, "CONSTANT_InvokeDynamic")
+			checker.checkInvokeDynamic(index, c)
+		}
+	}
+}
+
+func (checker *formatChecker) requireVersion(index uint16, major uint16, what string) {
+	if checker.version() < major {
+		checker.failAt(index, "%s requires class file version %d or above", what, major)
+	}
+}
+
+// nameAndType 取NameAndType常量的名字和描述符
+func (checker *formatChecker) nameAndType(from, index uint16) (string, string, bool) {
+	nt, ok := checker.entry(index).(*ConstantNameAndTypeInfo)
+	if !ok {
+		checker.failAt(from, "name_and_type index %d is not a CONSTANT_NameAndType", index)
+		return "", "", false
+	}
+	name, ok1 := checker.entry(nt.nameIndex).(*ConstantUtf8Info)
+	desc, ok2 := checker.entry(nt.descriptorIndex).(*ConstantUtf8Info)
+	if !ok1 || !ok2 {
+		return "", "", false // 在检查NameAndType本身时已经报过
+	}
+	return name.str, desc.str, true
+}
+
+func (checker *formatChecker) checkMemberref(index uint16, ref *ConstantMemberrefInfo, isMethod bool) {
+	checker.classRef(index, ref.classIndex, "class")
+	name, desc, ok := checker.nameAndType(index, ref.nameAndTypeIndex)
+	if !ok {
+		return
+	}
+	if !isMethod {
+		if !validUnqualifiedName(name, false) {
+			checker.failAt(index, "illegal field name %q", name)
+		}
+		if !validFieldDescriptor(desc) {
+			checker.failAt(index, "illegal field descriptor %q", desc)
+		}
+		return
+	}
+	if !validMethodDescriptor(desc) {
+		checker.failAt(index, "illegal method descriptor %q", desc)
+		return
+	}
+	if strings.HasPrefix(name, "<") {
+		if _, isInterface := checker.cp.GetConstantInfo(index).(*ConstantInterfaceMethodrefInfo); name != "<init>" || isInterface {
+			checker.failAt(index, "illegal method name %q", name)
+		} else if !strings.HasSuffix(desc, ")V") {
+			checker.failAt(index, "<init> must return void")
+		}
+	} else if !validUnqualifiedName(name, true) {
+		checker.failAt(index, "illegal method name %q", name)
+	}
+}
+
+// JVMS 4.4.8：reference_kind决定reference_index指向的常量类型
+func (checker *formatChecker) checkMethodHandle(index uint16, mh *ConstantMethodHandleInfo) {
+	ref := checker.entry(mh.referenceIndex)
+	kind := mh.referenceKind
+	var ok bool
+	switch kind {
+	case REF_getField, REF_getStatic, REF_putField, REF_putStatic:
+		_, ok = ref.(*ConstantFieldrefInfo)
+	case REF_invokeVirtual, REF_newInvokeSpecial:
+		_, ok = ref.(*ConstantMethodrefInfo)
+	case REF_invokeStatic, REF_invokeSpecial:
+		_, ok = ref.(*ConstantMethodrefInfo)
+		if _, isInterface := ref.(*ConstantInterfaceMethodrefInfo); isInterface && checker.version() >= 52 {
+			ok = true
+		}
+	case REF_invokeInterface:
+		_, ok = ref.(*ConstantInterfaceMethodrefInfo)
+	default:
+		checker.failAt(index, "illegal reference kind %d", kind)
+		return
+	}
+	if !ok {
+		checker.failAt(index, "reference index %d does not match reference kind %d", mh.referenceIndex, kind)
+		return
+	}
+	if kind < REF_invokeVirtual {
+		return
+	}
+	var name string
+	switch r := ref.(type) {
+	case *ConstantMethodrefInfo:
+		name, _, _ = checker.nameAndType(index, r.nameAndTypeIndex)
+	case *ConstantInterfaceMethodrefInfo:
+		name, _, _ = checker.nameAndType(index, r.nameAndTypeIndex)
+	}
+	if kind == REF_newInvokeSpecial && name != "<init>" {
+		checker.failAt(index, "REF_newInvokeSpecial must refer to <init>")
+	} else if kind != REF_newInvokeSpecial && (name == "<init>" || name == "<clinit>") {
+		checker.failAt(index, "method handle cannot refer to %s", name)
+	}
+}
+
+func (checker *formatChecker) checkInvokeDynamic(index uint16, indy *ConstantInvokeDynamicInfo) {
+	if checker.bootstrap == nil {
+		checker.failAt(index, "CONSTANT_InvokeDynamic without a BootstrapMethods attribute")
+	} else if int(indy.bootstrapMethodAttrIndex) >= len(checker.bootstrap.bootstrapMethods) {
+		checker.failAt(index, "bootstrap method index %d out of range", indy.bootstrapMethodAttrIndex)
+	}
+	name, desc, ok := checker.nameAndType(index, indy.nameAndTypeIndex)
+	if !ok {
+		return
+	}
+	if !validUnqualifiedName(name, true) {
+		checker.failAt(index, "illegal method name %q", name)
+	}
+	if !validMethodDescriptor(desc) {
+		checker.failAt(index, "illegal method descriptor %q", desc)
+	}
+}
+
+// 类
+
+func (checker *formatChecker) checkClass() {
+	classFile := checker.classFile
+	flags := classFile.accessFlags
+	isInterface := flags&ACC_INTERFACE != 0
+	switch {
+	case flags&ACC_MODULE != 0:
+		checker.fail("module-info is not supported")
+	case isInterface && (flags&ACC_ABSTRACT == 0 || flags&(ACC_FINAL|ACC_SUPER|ACC_ENUM) != 0):
+		checker.fail("illegal class modifiers 0x%04x", flags)
+	case flags&ACC_ANNOTATION != 0 && !isInterface:
+		checker.fail("illegal class modifiers 0x%04x", flags)
+	case flags&ACC_FINAL != 0 && flags&ACC_ABSTRACT != 0:
+		checker.fail("illegal class modifiers 0x%04x", flags)
+	}
+
+	checker.classRef(0, classFile.thisClass, "this_class")
+	thisName, _ := checker.className(classFile.thisClass)
+	if classFile.superClass == 0 {
+		if thisName != "java/lang/Object" {
+			checker.fail("super_class is 0 but the class is not java/lang/Object")
+		}
+	} else {
+		checker.classRef(0, classFile.superClass, "super_class")
+		superName, _ := checker.className(classFile.superClass)
+		if strings.HasPrefix(superName, "[") {
+			checker.failAt(classFile.superClass, "superclass %s is an array", superName)
+		}
+		if isInterface && superName != "" && superName != "java/lang/Object" {
+			checker.failAt(classFile.superClass, "interfaces must have java/lang/Object as superclass")
+		}
+	}
+	if strings.HasPrefix(thisName, "[") {
+		checker.failAt(classFile.thisClass, "this_class %s is an array", thisName)
+	}
+
+	seen := map[string]bool{}
+	for _, index := range classFile.interfaces {
+		checker.classRef(0, index, "interface")
+		name, ok := checker.className(index)
+		if !ok {
+			continue
+		}
+		if seen[name] {
+			checker.failAt(index, "duplicate interface %s", name)
+		}
+		seen[name] = true
+	}
+}
+
+// memberName 读出成员的名字和描述符，供报错用
+func (checker *formatChecker) memberName(kind string, member *MemberInfo) (string, string, bool) {
+	name, ok1 := checker.entry(member.nameIndex).(*ConstantUtf8Info)
+	desc, ok2 := checker.entry(member.descriptorIndex).(*ConstantUtf8Info)
+	if !ok1 || !ok2 {
+		checker.member = fmt.Sprintf("%s #%d", kind, member.nameIndex)
+		if !ok1 {
+			checker.fail("name index %d is not a CONSTANT_Utf8", member.nameIndex)
+		}
+		if !ok2 {
+			checker.fail("descriptor index %d is not a CONSTANT_Utf8", member.descriptorIndex)
+		}
+		return "", "", false
+	}
+	if kind == "field" {
+		checker.member = fmt.Sprintf("field %s:%s", name.str, desc.str)
+	} else {
+		checker.member = fmt.Sprintf("method %s%s", name.str, desc.str)
+	}
+	return name.str, desc.str, true
+}
+
+// 至多有一个public/private/protected
+func checkVisibility(flags uint16) bool {
+	n := 0
+	for _, flag := range []uint16{ACC_PUBLIC, ACC_PRIVATE, ACC_PROTECTED} {
+		if flags&flag != 0 {
+			n++
+		}
+	}
+	return n <= 1
+}
+
+func (checker *formatChecker) checkFields() {
+	isInterface := checker.classFile.accessFlags&ACC_INTERFACE != 0
+	seen := map[string]bool{}
+	for _, field := range checker.classFile.fields {
+		name, desc, ok := checker.memberName("field", field)
+		if ok {
+			if !validUnqualifiedName(name, false) {
+				checker.failAt(field.nameIndex, "illegal field name %q", name)
+			}
+			if !validFieldDescriptor(desc) {
+				checker.failAt(field.descriptorIndex, "illegal field descriptor %q", desc)
+			}
+			if seen[name+":"+desc] {
+				checker.fail("duplicate field")
+			}
+			seen[name+":"+desc] = true
+		}
+
+		flags := field.accessFlags
+		legal := checkVisibility(flags) && flags&(ACC_FINAL|ACC_VOLATILE) != ACC_FINAL|ACC_VOLATILE
+		if isInterface {
+			legal = flags&^(ACC_SYNTHETIC) == ACC_PUBLIC|ACC_STATIC|ACC_FINAL
+		}
+		if !legal {
+			checker.fail("illegal field modifiers 0x%04x", flags)
+		}
+		checker.checkAttributes(field.attributes, "field")
+		if cv := field.ConstantValueAttribute(); cv != nil && ok {
+			checker.checkConstantValue(cv, desc)
+		}
+	}
+}
+
+// ConstantValue的常量类型要和字段类型一致
+func (checker *formatChecker) checkConstantValue(cv *ConstantValueAttribute, descriptor string) {
+	var ok bool
+	switch checker.entry(cv.constantValueIndex).(type) {
+	case *ConstantIntegerInfo:
+		ok = strings.Contains("IBCSZ", descriptor) && len(descriptor) == 1
+	case *ConstantLongInfo:
+		ok = descriptor == "J"
+	case *ConstantFloatInfo:
+		ok = descriptor == "F"
+	case *ConstantDoubleInfo:
+		ok = descriptor == "D"
+	case *ConstantStringInfo:
+		ok = descriptor == "Ljava/lang/String;"
+	default:
+		checker.failAt(cv.constantValueIndex, "ConstantValue index %d is not a constant", cv.constantValueIndex)
+		return
+	}
+	if !ok {
+		checker.failAt(cv.constantValueIndex, "ConstantValue does not match field type %s", descriptor)
+	}
+}
+
+func (checker *formatChecker) checkMethods() {
+	classFlags := checker.classFile.accessFlags
+	isInterface := classFlags&ACC_INTERFACE != 0
+	seen := map[string]bool{}
+	for _, method := range checker.classFile.methods {
+		name, desc, ok := checker.memberName("method", method)
+		flags := method.accessFlags
+		if ok {
+			checker.checkMethodName(method, name, desc)
+			if seen[name+desc] {
+				checker.fail("duplicate method")
+			}
+			seen[name+desc] = true
+		}
+
+		if name == "<clinit>" {
+			// 51以后<clinit>必须是static的，其他标志忽略
+			if checker.version() >= 51 && flags&ACC_STATIC == 0 {
+				checker.fail("<clinit> must be static")
+			}
+		} else if !checker.legalMethodFlags(flags, name, isInterface) {
+			checker.fail("illegal method modifiers 0x%04x", flags)
+		}
+
+		checker.checkAttributes(method.attributes, "method")
+		codes := 0
+		for _, attr := range method.attributes {
+			if _, isCode := attr.(*CodeAttribute); isCode {
+				codes++
+			}
+		}
+		needsCode := flags&(ACC_ABSTRACT|ACC_NATIVE) == 0
+		switch {
+		case needsCode && codes == 0:
+			checker.fail("missing Code attribute")
+		case !needsCode && codes > 0:
+			checker.fail("abstract or native method has a Code attribute")
+		case codes > 1:
+			checker.fail("multiple Code attributes")
+		}
+		if code := method.CodeAttribute(); code != nil && ok {
+			checker.checkCode(code, method, desc)
+		}
+	}
+}
+
+func (checker *formatChecker) checkMethodName(method *MemberInfo, name, desc string) {
+	md, err := signature.ParseMethodDescriptor(desc)
+	if err != nil {
+		checker.failAt(method.descriptorIndex, "illegal method descriptor %q", desc)
+	} else {
+		slots := md.ArgSlotCount()
+		if method.accessFlags&ACC_STATIC == 0 {
+			slots++
+		}
+		if slots > 255 {
+			checker.failAt(method.descriptorIndex, "too many arguments in method descriptor")
+		}
+	}
+	switch name {
+	case "<init>":
+		if checker.classFile.accessFlags&ACC_INTERFACE != 0 {
+			checker.failAt(method.nameIndex, "interface cannot have <init>")
+		}
+		if !strings.HasSuffix(desc, ")V") {
+			checker.failAt(method.descriptorIndex, "<init> must return void")
+		}
+	case "<clinit>":
+		if desc != "()V" && checker.version() >= 51 {
+			checker.failAt(method.descriptorIndex, "<clinit> must have descriptor ()V")
+		}
+	default:
+		if !validUnqualifiedName(name, true) {
+			checker.failAt(method.nameIndex, "illegal method name %q", name)
+		}
+	}
+}
+
+// JVMS 4.6中方法访问标志的组合规则
+func (checker *formatChecker) legalMethodFlags(flags uint16, name string, isInterface bool) bool {
+	if !checkVisibility(flags) {
+		return false
+	}
+	if isInterface {
+		if checker.version() < 52 {
+			return flags&(ACC_PUBLIC|ACC_ABSTRACT) == ACC_PUBLIC|ACC_ABSTRACT &&
+				flags&^(ACC_PUBLIC|ACC_ABSTRACT|ACC_VARARGS|ACC_BRIDGE|ACC_SYNTHETIC) == 0
+		}
+		if flags&(ACC_PUBLIC|ACC_PRIVATE) == 0 || flags&(ACC_PROTECTED|ACC_FINAL|ACC_SYNCHRONIZED|ACC_NATIVE) != 0 {
+			return false
+		}
+		return flags&ACC_ABSTRACT == 0 || flags&(ACC_PRIVATE|ACC_STATIC|ACC_STRICT) == 0
+	}
+	if name == "<init>" {
+		return flags&^(ACC_PUBLIC|ACC_PRIVATE|ACC_PROTECTED|ACC_VARARGS|ACC_STRICT|ACC_SYNTHETIC) == 0
+	}
+	if flags&ACC_ABSTRACT != 0 {
+		return flags&(ACC_PRIVATE|ACC_STATIC|ACC_FINAL|ACC_SYNCHRONIZED|ACC_NATIVE|ACC_STRICT) == 0
+	}
+	return true
+}
+
+func (checker *formatChecker) checkCode(code *CodeAttribute, method *MemberInfo, desc string) {
+	n := len(code.code)
+	if n == 0 || n >= 65536 {
+		checker.fail("code length %d out of range", n)
+	}
+	if slots, err := signature.ArgSlotCount(desc); err == nil {
+		if method.accessFlags&ACC_STATIC == 0 {
+			slots++
+		}
+		if int(code.maxLocals) < slots {
+			checker.fail("max_locals %d is less than the size of the arguments %d", code.maxLocals, slots)
+		}
+	}
+	for i, entry := range code.exceptionTable {
+		if entry.startPc >= entry.endPc || int(entry.endPc) > n || int(entry.handlerPc) >= n {
+			checker.fail("exception table entry %d has illegal range [%d, %d) -> %d",
+				i, entry.startPc, entry.endPc, entry.handlerPc)
+		}
+		if entry.catchType != 0 {
+			checker.classRef(0, entry.catchType, "catch_type")
+		}
+	}
+	checker.checkAttributes(code.attributes, "Code")
+	for _, attr := range code.attributes {
+		switch a := attr.(type) {
+		case *LineNumberTableAttribute:
+			for _, entry := range a.lineNumberTable {
+				if int(entry.startPc) >= n {
+					checker.fail("LineNumberTable start_pc %d out of range", entry.startPc)
+				}
+			}
+		case *LocalVariableTableAttribute:
+			checker.checkLocalVariables(a.localVariableTable, n, code.maxLocals, true)
+		case *LocalVariableTypeTableAttribute:
+			checker.checkLocalVariables(a.localVariableTable, n, code.maxLocals, false)
+		}
+	}
+}
+
+func (checker *formatChecker) checkLocalVariables(entries []*LocalVariableTableEntry, codeLength int, maxLocals uint16, isDescriptor bool) {
+	for _, entry := range entries {
+		if int(entry.startPc)+int(entry.length) > codeLength {
+			checker.fail("local variable range [%d, %d) out of code", entry.startPc, int(entry.startPc)+int(entry.length))
+		}
+		if entry.index >= maxLocals {
+			checker.fail("local variable index %d is not less than max_locals %d", entry.index, maxLocals)
+		}
+		if name, ok := checker.utf8(entry.nameIndex, entry.nameIndex, "local variable name"); ok && !validUnqualifiedName(name, false) {
+			checker.failAt(entry.nameIndex, "illegal local variable name %q", name)
+		}
+		desc, ok := checker.utf8(entry.descriptorIndex, entry.descriptorIndex, "local variable descriptor")
+		if ok && isDescriptor && !validFieldDescriptor(desc) {
+			checker.failAt(entry.descriptorIndex, "illegal local variable descriptor %q", desc)
+		}
+	}
+}
+
+// 属性的位置：哪些属性可以出现在哪里，以及至多出现一次的属性
+var attributePlaces = map[string]string{
+	"SourceFile":                           "class",
+	"InnerClasses":                         "class",
+	"EnclosingMethod":                      "class",
+	"BootstrapMethods":                     "class",
+	"ConstantValue":                        "field",
+	"Code":                                 "method",
+	"Exceptions":                           "method",
+	"MethodParameters":                     "method",
+	"AnnotationDefault":                    "method",
+	"RuntimeVisibleParameterAnnotations":   "method",
+	"RuntimeInvisibleParameterAnnotations": "method",
+	"LineNumberTable":                      "Code",
+	"LocalVariableTable":                   "Code",
+	"LocalVariableTypeTable":               "Code",
+	"StackMapTable":                        "Code",
+	"Signature":                            "class field method",
+	"Deprecated":                           "class field method",
+	"Synthetic":                            "class field method",
+	"RuntimeVisibleAnnotations":            "class field method",
+	"RuntimeInvisibleAnnotations":          "class field method",
+}
+
+// 可以出现多次的属性
+var repeatableAttributes = map[string]bool{
+	"LineNumberTable":        true,
+	"LocalVariableTable":     true,
+	"LocalVariableTypeTable": true,
+}
+
+func (checker *formatChecker) checkAttributes(attributes []AttributeInfo, place string) {
+	seen := map[string]bool{}
+	for _, attr := range attributes {
+		name := attributeName(attr)
+		places, known := attributePlaces[name]
+		if !known {
+			continue // 不认识的属性直接忽略
+		}
+		if !strings.Contains(places, place) {
+			checker.fail("%s attribute is not allowed in %s", name, place)
+			continue
+		}
+		if seen[name] && !repeatableAttributes[name] {
+			checker.fail("multiple %s attributes", name)
+		}
+		seen[name] = true
+		checker.checkAttribute(attr, place)
+	}
+}
+
+// 检查属性里的常量池索引
+func (checker *formatChecker) checkAttribute(attr AttributeInfo, place string) {
+	switch a := attr.(type) {
+	case *SourceFileAttribute:
+		checker.utf8(a.sourceFileIndex, a.sourceFileIndex, "SourceFile")
+	case *SignatureAttribute:
+		sig, ok := checker.utf8(a.signatureIndex, a.signatureIndex, "Signature")
+		if !ok {
+			return
+		}
+		var err error
+		switch place {
+		case "class":
+			_, err = signature.ParseClassSignature(sig)
+		case "field":
+			_, err = signature.ParseFieldSignature(sig)
+		case "method":
+			_, err = signature.ParseMethodSignature(sig)
+		}
+		if err != nil {
+			checker.failAt(a.signatureIndex, "illegal %s signature %q", place, sig)
+		}
+	case *ExceptionsAttribute:
+		for _, index := range a.exceptionIndexTable {
+			checker.classRef(0, index, "Exceptions")
+		}
+	case *InnerClassesAttribute:
+		for _, info := range a.classes {
+			checker.classRef(0, info.innerClassInfoIndex, "inner_class_info")
+			if info.outerClassInfoIndex != 0 {
+				checker.classRef(0, info.outerClassInfoIndex, "outer_class_info")
+			}
+			if info.innerNameIndex != 0 {
+				checker.utf8(info.innerNameIndex, info.innerNameIndex, "inner_name")
+			}
+		}
+	case *EnclosingMethodAttribute:
+		checker.classRef(0, a.classIndex, "EnclosingMethod class")
+		if a.methodIndex != 0 {
+			if _, ok := checker.entry(a.methodIndex).(*ConstantNameAndTypeInfo); !ok {
+				checker.failAt(a.methodIndex, "EnclosingMethod method index is not a CONSTANT_NameAndType")
+			}
+		}
+	case *BootstrapMethodsAttribute:
+		for i, bsm := range a.bootstrapMethods {
+			if _, ok := checker.entry(bsm.bootstrapMethodRef).(*ConstantMethodHandleInfo); !ok {
+				checker.fail("bootstrap method %d does not refer to a CONSTANT_MethodHandle", i)
+			}
+			for _, arg := range bsm.bootstrapArguments {
+				if !loadable(checker.entry(arg)) {
+					checker.failAt(arg, "bootstrap argument of method %d is not a loadable constant", i)
+				}
+			}
+		}
+	}
+}
+
+// ldc能加载的常量
+func loadable(info ConstantInfo) bool {
+	switch info.(type) {
+	case *ConstantIntegerInfo, *ConstantFloatInfo, *ConstantLongInfo, *ConstantDoubleInfo,
+		*ConstantClassInfo, *ConstantStringInfo, *ConstantMethodHandleInfo, *ConstantMethodTypeInfo, *ConstantDynamicInfo:
+		return true
+	}
+	return false
+}
+
+// 名字和描述符（JVMS 4.2、4.3）
+
+// validUnqualifiedName 字段名、方法名和局部变量名不能为空，不能包含 . ; [ /，方法名还不能包含 < >
+func validUnqualifiedName(name string, isMethod bool) bool {
+	if name == "" {
+		return false
+	}
+	illegal := ".;[/"
+	if isMethod {
+		illegal = ".;[/<>"
+	}
+	return !strings.ContainsAny(name, illegal)
+}
+
+// validClassName Class常量中的名字：二进制名称的内部形式或者数组的描述符
+func validClassName(name string) bool {
+	if strings.HasPrefix(name, "[") {
+		return validFieldDescriptor(name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if !validUnqualifiedName(part, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func validFieldDescriptor(desc string) bool {
+	_, err := signature.ParseFieldDescriptor(desc)
+	return err == nil
+}
+
+func validMethodDescriptor(desc string) bool {
+	_, err := signature.ParseMethodDescriptor(desc)
+	return err == nil
+}
@@ -0,0 +1,273 @@
+package classfile
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// formatTestClass 一个能通过格式检查的类，测试用例在它上面制造一处错误
+func formatTestClass() *ClassFile {
+	cf := NewClassFile(0, 52)
+	cp := cf.ConstantPool()
+	cf.SetAccessFlags(ACC_PUBLIC | ACC_SUPER)
+	cf.SetThisClass(cp.AddClass("Point"))
+	cf.SetSuperClass(cp.AddClass("java/lang/Object"))
+	cf.SetFields([]*MemberInfo{NewMemberInfo(cp, ACC_PRIVATE, "x", "I")})
+
+	init := NewMemberInfo(cp, ACC_PUBLIC, "<init>", "()V")
+	super := cp.AddMethodref("java/lang/Object", "<init>", "()V")
+	init.SetAttributes([]AttributeInfo{NewCodeAttribute(cp, 1, 1, []byte{0x2A, 0xB7, byte(super >> 8), byte(super), 0xB1})})
+	run := NewMemberInfo(cp, ACC_PUBLIC|ACC_STATIC, "run", "(I)I")
+	run.SetAttributes([]AttributeInfo{NewCodeAttribute(cp, 1, 1, []byte{0x1A, 0xAC})}) // iload_0; ireturn
+	cf.SetMethods([]*MemberInfo{init, run})
+	cf.SetAttributes([]AttributeInfo{NewSourceFileAttribute(cp, "Point.java")})
+	return cf
+}
+
+func TestCheckFormatValid(t *testing.T) {
+	if err := CheckFormat(formatTestClass()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 每个用例违反一条规则，检查报告的常量池索引、成员和消息
+func TestCheckFormat(t *testing.T) {
+	addMethod := func(cf *ClassFile, method *MemberInfo) {
+		cf.SetMethods(append(cf.Methods(), method))
+	}
+	withCode := func(cf *ClassFile, method *MemberInfo, maxLocals uint16) *MemberInfo {
+		method.SetAttributes([]AttributeInfo{NewCodeAttribute(cf.ConstantPool(), 0, maxLocals, []byte{0xB1})})
+		return method
+	}
+	tests := []struct {
+		name   string
+		mutate func(cf *ClassFile, cp *ConstantPool) FormatViolation // 返回期望的错误，Msg是子串
+	}{
+		// 版本和常量池
+		{"version", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetVersion(0, 53)
+			return FormatViolation{0, "", "unsupported major.minor version 53.0"}
+		}},
+		{"module constant", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			index := uint16(cp.Count())
+			cp.infos = append(cp.infos, &ConstantModuleInfo{ConstantClassInfo{cp, cp.AddUtf8("Point")}})
+			return FormatViolation{index, "", "CONSTANT_Module and CONSTANT_Package are only allowed in module-info"}
+		}},
+		{"class name", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddClass("java.lang.Object"), "", `illegal class name "java.lang.Object"`}
+		}},
+		{"string index", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			classIndex := cp.AddClass("Point")
+			index := cp.add(&ConstantStringInfo{cp: cp, stringIndex: classIndex})
+			return FormatViolation{index, "", "string index " + itoa(classIndex) + " is not a CONSTANT_Utf8"}
+		}},
+		{"name and type index", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			classIndex := cp.AddClass("Point")
+			index := cp.add(&ConstantFieldrefInfo{ConstantMemberrefInfo{cp, classIndex, classIndex}})
+			return FormatViolation{index, "", "name_and_type index " + itoa(classIndex) + " is not a CONSTANT_NameAndType"}
+		}},
+		{"fieldref name", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddFieldref("Point", "a;b", "I"), "", `illegal field name "a;b"`}
+		}},
+		{"fieldref descriptor", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddFieldref("Point", "x", "V"), "", `illegal field descriptor "V"`}
+		}},
+		{"methodref <clinit>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddMethodref("Point", "<clinit>", "()V"), "", `illegal method name "<clinit>"`}
+		}},
+		{"interface methodref <init>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddInterfaceMethodref("Point", "<init>", "()V"), "", `illegal method name "<init>"`}
+		}},
+		{"methodref <init> return", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddMethodref("Point", "<init>", "()I"), "", "<init> must return void"}
+		}},
+		{"method handle kind", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			ref := cp.AddMethodref("Point", "run", "(I)I")
+			return FormatViolation{cp.AddMethodHandle(REF_getField, ref), "", "reference index " + itoa(ref) + " does not match reference kind 1"}
+		}},
+		{"method handle <init>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			ref := cp.AddMethodref("Point", "<init>", "()V")
+			return FormatViolation{cp.AddMethodHandle(REF_invokeVirtual, ref), "", "method handle cannot refer to <init>"}
+		}},
+		{"method type version", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetVersion(0, 50)
+			return FormatViolation{cp.AddMethodType("()V"), "", "CONSTANT_MethodType requires class file version 51 or above"}
+		}},
+		{"invokedynamic without bootstrap", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			return FormatViolation{cp.AddInvokeDynamic(0, "run", "()V"), "", "CONSTANT_InvokeDynamic without a BootstrapMethods attribute"}
+		}},
+
+		// 类
+		{"final abstract class", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_ABSTRACT)
+			return FormatViolation{0, "", "illegal class modifiers 0x0411"}
+		}},
+		{"interface superclass", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAccessFlags(ACC_PUBLIC | ACC_INTERFACE | ACC_ABSTRACT)
+			cf.SetMethods(nil)
+			cf.SetFields(nil)
+			cf.SetSuperClass(cp.AddClass("java/lang/Number"))
+			return FormatViolation{cf.superClass, "", "interfaces must have java/lang/Object as superclass"}
+		}},
+		{"array superclass", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetSuperClass(cp.AddClass("[I"))
+			return FormatViolation{cf.superClass, "", "superclass [I is an array"}
+		}},
+		{"no superclass", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetSuperClass(0)
+			return FormatViolation{0, "", "super_class is 0 but the class is not java/lang/Object"}
+		}},
+		{"duplicate interface", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			index := cp.AddClass("java/lang/Runnable")
+			cf.SetInterfaces([]uint16{index, index})
+			return FormatViolation{index, "", "duplicate interface java/lang/Runnable"}
+		}},
+		{"this_class not a class", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			index := cp.AddUtf8("Point")
+			cf.SetThisClass(index)
+			return FormatViolation{0, "", "this_class index " + itoa(index) + " is not a CONSTANT_Class"}
+		}},
+
+		// 字段
+		{"field modifiers", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.Fields()[0].SetAccessFlags(ACC_PUBLIC | ACC_PRIVATE)
+			return FormatViolation{0, "field x:I", "illegal field modifiers 0x0003"}
+		}},
+		{"final volatile field", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.Fields()[0].SetAccessFlags(ACC_FINAL | ACC_VOLATILE)
+			return FormatViolation{0, "field x:I", "illegal field modifiers 0x0050"}
+		}},
+		{"field descriptor", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			field := NewMemberInfo(cp, 0, "y", "Ljava/lang/String")
+			cf.SetFields(append(cf.Fields(), field))
+			return FormatViolation{field.DescriptorIndex(), "field y:Ljava/lang/String", `illegal field descriptor "Ljava/lang/String"`}
+		}},
+		{"field name", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			field := NewMemberInfo(cp, 0, "a.b", "I")
+			cf.SetFields(append(cf.Fields(), field))
+			return FormatViolation{field.NameIndex(), "field a.b:I", `illegal field name "a.b"`}
+		}},
+		{"duplicate field", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetFields(append(cf.Fields(), NewMemberInfo(cp, ACC_PUBLIC, "x", "I")))
+			return FormatViolation{0, "field x:I", "duplicate field"}
+		}},
+		{"constant value type", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			field := NewMemberInfo(cp, ACC_STATIC|ACC_FINAL, "NAME", "I")
+			index := cp.AddString("point")
+			field.SetAttributes([]AttributeInfo{NewConstantValueAttribute(index)})
+			cf.SetFields(append(cf.Fields(), field))
+			return FormatViolation{index, "field NAME:I", "ConstantValue does not match field type I"}
+		}},
+		{"interface field", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAccessFlags(ACC_PUBLIC | ACC_INTERFACE | ACC_ABSTRACT)
+			cf.SetMethods(nil)
+			cf.Fields()[0].SetAccessFlags(ACC_PUBLIC | ACC_STATIC)
+			return FormatViolation{0, "field x:I", "illegal field modifiers 0x0009"}
+		}},
+
+		// 方法
+		{"abstract private method", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, NewMemberInfo(cp, ACC_PRIVATE|ACC_ABSTRACT, "area", "()D"))
+			return FormatViolation{0, "method area()D", "illegal method modifiers 0x0402"}
+		}},
+		{"abstract method with code", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, withCode(cf, NewMemberInfo(cp, ACC_PUBLIC|ACC_ABSTRACT, "area", "()V"), 1))
+			return FormatViolation{0, "method area()V", "abstract or native method has a Code attribute"}
+		}},
+		{"missing code", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, NewMemberInfo(cp, ACC_PUBLIC, "area", "()D"))
+			return FormatViolation{0, "method area()D", "missing Code attribute"}
+		}},
+		{"duplicate method", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, withCode(cf, NewMemberInfo(cp, ACC_PUBLIC|ACC_STATIC, "run", "(I)I"), 1))
+			return FormatViolation{0, "method run(I)I", "duplicate method"}
+		}},
+		{"method name", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			method := withCode(cf, NewMemberInfo(cp, ACC_STATIC, "a<b", "()V"), 0)
+			addMethod(cf, method)
+			return FormatViolation{method.NameIndex(), "method a<b()V", `illegal method name "a<b"`}
+		}},
+		{"method descriptor", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			method := withCode(cf, NewMemberInfo(cp, ACC_STATIC, "m", "(V)V"), 0)
+			addMethod(cf, method)
+			return FormatViolation{method.DescriptorIndex(), "method m(V)V", `illegal method descriptor "(V)V"`}
+		}},
+		{"<init> return type", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			method := withCode(cf, NewMemberInfo(cp, ACC_PUBLIC, "<init>", "(I)I"), 2)
+			addMethod(cf, method)
+			return FormatViolation{method.DescriptorIndex(), "method <init>(I)I", "<init> must return void"}
+		}},
+		{"static <init>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, withCode(cf, NewMemberInfo(cp, ACC_STATIC, "<init>", "(I)V"), 1))
+			return FormatViolation{0, "method <init>(I)V", "illegal method modifiers 0x0008"}
+		}},
+		{"non-static <clinit>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, withCode(cf, NewMemberInfo(cp, 0, "<clinit>", "()V"), 1))
+			return FormatViolation{0, "method <clinit>()V", "<clinit> must be static"}
+		}},
+		{"max_locals", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			addMethod(cf, withCode(cf, NewMemberInfo(cp, ACC_PUBLIC, "move", "(JI)V"), 3))
+			return FormatViolation{0, "method move(JI)V", "max_locals 3 is less than the size of the arguments 4"}
+		}},
+		{"exception table", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			code := cf.Methods()[1].CodeAttribute()
+			code.SetExceptionTable([]*ExceptionTableEntry{NewExceptionTableEntry(1, 1, 0, 0)})
+			return FormatViolation{0, "method run(I)I", "exception table entry 0 has illegal range [1, 1) -> 0"}
+		}},
+		{"interface <init>", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAccessFlags(ACC_PUBLIC | ACC_INTERFACE | ACC_ABSTRACT)
+			cf.SetFields(nil)
+			init := cf.Methods()[0]
+			cf.SetMethods([]*MemberInfo{init})
+			return FormatViolation{init.NameIndex(), "method <init>()V", "interface cannot have <init>"}
+		}},
+
+		// 属性
+		{"attribute place", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAttributes(append(cf.Attributes(), NewConstantValueAttribute(cp.AddInteger(1))))
+			return FormatViolation{0, "", "ConstantValue attribute is not allowed in class"}
+		}},
+		{"repeated attribute", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			cf.SetAttributes(append(cf.Attributes(), NewSourceFileAttribute(cp, "Other.java")))
+			return FormatViolation{0, "", "multiple SourceFile attributes"}
+		}},
+		{"method signature", func(cf *ClassFile, cp *ConstantPool) FormatViolation {
+			run := cf.Methods()[1]
+			sig := NewSignatureAttribute(cp, "<T>(TT;)I")
+			run.SetAttributes(append(run.Attributes(), sig))
+			return FormatViolation{sig.signatureIndex, "method run(I)I", `illegal method signature "<T>(TT;)I"`}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cf := formatTestClass()
+			want := test.mutate(cf, cf.ConstantPool())
+			err, ok := CheckFormat(cf).(*ClassFormatError)
+			if !ok {
+				t.Fatalf("CheckFormat = %v, want a *ClassFormatError", err)
+			}
+			if len(err.Violations) != 1 {
+				t.Fatalf("%d violations: %v", len(err.Violations), err)
+			}
+			got := err.Violations[0]
+			if got.CPIndex != want.CPIndex || got.Member != want.Member || !strings.Contains(got.Msg, want.Msg) {
+				t.Errorf("got {#%d %q %q}, want {#%d %q %q}", got.CPIndex, got.Member, got.Msg, want.CPIndex, want.Member, want.Msg)
+			}
+		})
+	}
+}
+
+func TestClassFormatErrorMessage(t *testing.T) {
+	cf := formatTestClass()
+	cf.SetVersion(0, 60)
+	cf.Fields()[0].SetAccessFlags(ACC_PUBLIC | ACC_PROTECTED)
+	want := "unsupported major.minor version 60.0 in class file Point (and 1 more)"
+	if err := CheckFormat(cf); err == nil || err.Error() != want {
+		t.Errorf("CheckFormat = %v, want %q", err, want)
+	}
+}
+
+func itoa(i uint16) string {
+	return strconv.Itoa(int(i))
+}
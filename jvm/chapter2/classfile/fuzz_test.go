@@ -0,0 +1,364 @@
+package classfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/classpath"
+)
+
+// 解析n字节的输入，分配的内存不能超过 allocFactor*n + allocSlack，耗时不能超过timeLimit
+const (
+	allocFactor = 64
+	allocSlack  = 1 << 20
+	timeLimit   = 2 * time.Second
+)
+
+// 种子：testdata下的类（pkg/Test.class由pkg/Test.j汇编而来），有JDK时再加上一些JDK的类
+var seedClasses = []string{
+	"Hello",
+	"pkg/Test",
+	"java/lang/Object",
+	"java/lang/String",
+	"java/lang/Thread",
+	"java/util/HashMap",
+	"java/util/concurrent/ConcurrentHashMap",
+	"java/lang/invoke/MethodHandles",
+	"java/lang/invoke/LambdaMetafactory",
+}
+
+func seedCorpus(f *testing.F) [][]byte {
+	jre := ""
+	if os.Getenv("JAVA_HOME") == "" {
+		// 没有JDK时用一个空的jre目录，只读testdata
+		jre = f.TempDir()
+		if err := os.MkdirAll(filepath.Join(jre, "lib", "ext"), 0755); err != nil {
+			f.Fatal(err)
+		}
+	}
+	cp := classpath.Parse(jre, "testdata")
+	var seeds [][]byte
+	for _, className := range seedClasses {
+		if data, _, err := cp.ReadClass(className); err == nil {
+			seeds = append(seeds, data)
+		}
+	}
+	if len(seeds) < 2 {
+		f.Fatal("testdata classes not found")
+	}
+	return seeds
+}
+
+// 恶意构造的输入：巨大的计数和长度、深度嵌套
+func hostileInputs() [][]byte {
+	header := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, 0, 52}
+	var inputs [][]byte
+	// constant_pool_count = 65535，后面没有数据
+	inputs = append(inputs, append(append([]byte(nil), header...), 0xFF, 0xFF))
+	// 只有一个Utf8 "Code"，类属性的attribute_length = 0xFFFFFFFF
+	class := append(append([]byte(nil), header...), 0, 2, CONSTANT_Utf8, 0, 4, 'C', 'o', 'd', 'e')
+	class = append(class, 0, 0x21, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0xFF, 0xFF, 0xFF, 0xFF)
+	inputs = append(inputs, class)
+	// Code属性里套Code属性，套很多层
+	inner := []byte{}
+	for i := 0; i < 1000; i++ {
+		attr := binary.BigEndian.AppendUint16(nil, 1)
+		body := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+		body = append(body, inner...)
+		attr = binary.BigEndian.AppendUint32(attr, uint32(len(body)))
+		inner = append(attr, body...)
+	}
+	class = append(append([]byte(nil), header...), 0, 2, CONSTANT_Utf8, 0, 4, 'C', 'o', 'd', 'e')
+	class = append(class, 0, 0x21, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1)
+	inputs = append(inputs, append(class, inner...))
+	return inputs
+}
+
+// withinLimits 运行fn，检查panic、内存分配和耗时
+func withinLimits(t *testing.T, n int, fn func()) {
+	t.Helper()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	done := make(chan string, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Sprintf("panic: %v\n%s", r, debug.Stack())
+				return
+			}
+			done <- ""
+		}()
+		fn()
+	}()
+	select {
+	case msg := <-done:
+		if msg != "" {
+			t.Fatal(msg)
+		}
+	case <-time.After(timeLimit):
+		t.Fatalf("did not finish in %v", timeLimit)
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > uint64(allocFactor*n+allocSlack) {
+		t.Fatalf("allocated %d bytes for %d bytes of input", allocated, n)
+	}
+}
+
+// FuzzParse 整个class文件：解析、格式检查，解析成功的话写回来必须和输入一字不差
+func FuzzParse(f *testing.F) {
+	for _, seed := range seedCorpus(f) {
+		f.Add(seed)
+	}
+	for _, input := range hostileInputs() {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinLimits(t, len(data), func() {
+			view, viewErr := NewClassView(data)
+			if viewErr == nil {
+				walkClassView(view)
+			}
+			cf, err := Parse(data)
+			if err != nil {
+				return
+			}
+			if viewErr != nil {
+				t.Fatalf("Parse succeeded but NewClassView failed: %v", viewErr)
+			}
+			compareClassView(t, view, cf)
+			CheckFormat(cf)
+			walkClassFile(cf)
+			out, err := cf.Bytes()
+			if err != nil {
+				t.Errorf("Bytes: %v", err)
+				return
+			}
+			if !bytes.Equal(out, data) {
+				t.Errorf("round trip changed the class file")
+			}
+		})
+	})
+}
+
+// 调用各种getter，它们在常量池索引无效时也不能panic
+func walkClassFile(cf *ClassFile) {
+	cf.ClassName()
+	cf.SuperClassName()
+	cf.InterfaceNames()
+	cf.SourceFileAttribute()
+	walkConstantPool(cf.ConstantPool())
+	for _, members := range [][]*MemberInfo{cf.Fields(), cf.Methods()} {
+		for _, member := range members {
+			member.Name()
+			member.Descriptor()
+			member.ConstantValueAttribute()
+			member.ExceptionsAttribute()
+			if sig := member.SignatureAttribute(); sig != nil {
+				sig.Signature()
+			}
+			if code := member.CodeAttribute(); code != nil {
+				code.LineNumberTableAttribute()
+				code.StackMapTableAttribute()
+				if lnt := code.LineNumberTableAttribute(); lnt != nil {
+					lnt.GetLineNumber(len(code.Code()) / 2)
+				}
+			}
+		}
+	}
+	for _, attr := range cf.Attributes() {
+		switch a := attr.(type) {
+		case *SourceFileAttribute:
+			a.FileName()
+		case *EnclosingMethodAttribute:
+			a.ClassName()
+			a.MethodNameAndDescriptor()
+		}
+	}
+}
+
+// ClassView只检查了整体结构，各种取值方法在常量无效时也不能panic
+func walkClassView(view *ClassView) {
+	view.ThisClassName()
+	view.SuperClassName()
+	view.InterfaceNames()
+	for i := 0; i < view.ConstantPoolCount(); i++ {
+		view.ClassName(uint16(i))
+		view.NameAndType(uint16(i))
+	}
+	for _, members := range [][]MemberView{view.Fields(), view.Methods()} {
+		for _, member := range members {
+			member.Name()
+			member.Descriptor()
+			member.Code()
+			for _, attr := range member.Attributes() {
+				attr.Name()
+				attr.Decode()
+			}
+		}
+	}
+	for _, attr := range view.Attributes() {
+		attr.Decode()
+	}
+}
+
+// Parse成功时，ClassView读出来的内容要和ClassFile一致
+func compareClassView(t *testing.T, view *ClassView, cf *ClassFile) {
+	if view.ThisClassName() != cf.ClassName() || view.SuperClassName() != cf.SuperClassName() {
+		t.Fatalf("view names %s/%s, parsed %s/%s",
+			view.ThisClassName(), view.SuperClassName(), cf.ClassName(), cf.SuperClassName())
+	}
+	methods := view.Methods()
+	if len(methods) != len(cf.Methods()) || len(view.Fields()) != len(cf.Fields()) {
+		t.Fatalf("view has %d fields and %d methods, parsed %d and %d",
+			len(view.Fields()), len(methods), len(cf.Fields()), len(cf.Methods()))
+	}
+	for i, method := range cf.Methods() {
+		if methods[i].Name() != method.Name() || methods[i].Descriptor() != method.Descriptor() {
+			t.Fatalf("method %d: view %s%s, parsed %s%s", i,
+				methods[i].Name(), methods[i].Descriptor(), method.Name(), method.Descriptor())
+		}
+		if code := method.CodeAttribute(); code != nil && !bytes.Equal(methods[i].Code(), code.Code()) {
+			t.Fatalf("method %s%s: code differs", method.Name(), method.Descriptor())
+		}
+		for j, attr := range methods[i].Attributes() {
+			decoded, err := attr.Decode()
+			if err != nil {
+				t.Fatalf("decoding %s: %v", attr.Name(), err)
+			}
+			if attributeName(decoded) != attributeName(method.Attributes()[j]) {
+				t.Fatalf("attribute %d of %s decoded as %s", j, method.Name(), attributeName(decoded))
+			}
+		}
+	}
+}
+
+func walkConstantPool(cp *ConstantPool) {
+	for i := 0; i < cp.Count(); i++ {
+		index := uint16(i)
+		cp.GetUtf8(index)
+		cp.GetClassName(index)
+		cp.GetNameAndType(index)
+		switch c := cp.GetConstantInfo(index).(type) {
+		case *ConstantClassInfo:
+			c.Name()
+		case *ConstantStringInfo:
+			_ = c.String()
+		case *ConstantFieldrefInfo:
+			c.ClassName()
+			c.NameAndDescriptor()
+		case *ConstantMethodrefInfo:
+			c.ClassName()
+			c.NameAndDescriptor()
+		case *ConstantInterfaceMethodrefInfo:
+			c.ClassName()
+			c.NameAndDescriptor()
+		case *ConstantMethodTypeInfo:
+			c.Descriptor()
+		case *ConstantInvokeDynamicInfo:
+			c.NameAndDescriptor()
+		case *ConstantDynamicInfo:
+			c.NameAndDescriptor()
+		}
+	}
+}
+
+// FuzzConstantPool 只有常量池：constant_pool_count加上各个常量
+func FuzzConstantPool(f *testing.F) {
+	for _, seed := range seedCorpus(f) {
+		f.Add(seed[8:]) // 去掉magic和版本号
+	}
+	f.Add([]byte{0xFF, 0xFF})
+	f.Add([]byte{0, 3, CONSTANT_Long, 0, 0, 0, 0, 0, 0, 0, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinLimits(t, len(data), func() {
+			reader := &ClassReader{data: data}
+			cp, ok := parseOrFormatError(func() *ConstantPool { return readConstantPool(reader) })
+			if !ok {
+				return
+			}
+			walkConstantPool(cp)
+			writer := &ClassWriter{cp: cp}
+			cp.write(writer)
+			if !bytes.Equal(writer.data, data[:reader.pos]) {
+				t.Errorf("round trip changed the constant pool")
+			}
+		})
+	})
+}
+
+// FuzzAttribute 单个属性：name是属性名，info是属性内容
+func FuzzAttribute(f *testing.F) {
+	for _, seed := range seedCorpus(f) {
+		cf, err := Parse(seed)
+		if err != nil {
+			f.Fatal(err)
+		}
+		writer := &ClassWriter{cp: cf.constantPool, attrNames: cf.attrNameIndexes}
+		var attributes []AttributeInfo
+		attributes = append(attributes, cf.attributes...)
+		for _, method := range cf.methods {
+			attributes = append(attributes, method.attributes...)
+			if code := method.CodeAttribute(); code != nil {
+				attributes = append(attributes, code.attributes...)
+			}
+		}
+		for _, field := range cf.fields {
+			attributes = append(attributes, field.attributes...)
+		}
+		for _, attr := range attributes {
+			sub := writer.sub()
+			attr.writeInfo(sub)
+			f.Add(attributeName(attr), sub.data)
+		}
+	}
+	f.Add("RuntimeVisibleAnnotations", []byte{0, 1, 0, 1, 0, 0})
+	f.Add("StackMapTable", []byte{0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, name string, info []byte) {
+		withinLimits(t, len(name)+len(info), func() {
+			// 常量池：#1是属性名，后面是几个常用的常量，让属性里的索引有机会指向有效的常量
+			cp := &ConstantPool{}
+			cp.AddUtf8(name)
+			cp.AddClass("java/lang/Object")
+			cp.AddNameAndType("<init>", "()V")
+			cp.AddInteger(1)
+			cp.AddLong(2)
+
+			data := binary.BigEndian.AppendUint16(nil, 1)
+			data = binary.BigEndian.AppendUint32(data, uint32(len(info)))
+			data = append(data, info...)
+			reader := &ClassReader{data: data, attrNames: map[AttributeInfo]uint16{}}
+			attr, ok := parseOrFormatError(func() AttributeInfo { return readAttribute(reader, cp) })
+			if !ok {
+				return
+			}
+			if _, isUnparsed := attr.(*UnparsedAttribute); !isUnparsed && attributeName(attr) != name {
+				t.Errorf("attribute %q parsed as %s", name, attributeName(attr))
+			}
+			writer := &ClassWriter{cp: cp, attrNames: reader.attrNames}
+			writeAttribute(writer, attr)
+			if !bytes.Equal(writer.data, data) {
+				t.Errorf("round trip changed the %s attribute", name)
+			}
+		})
+	})
+}
+
+// parseOrFormatError 调用解析函数，把*FormatError当作正常的失败
+func parseOrFormatError[T any](parse func() T) (result T, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isFormatError := r.(*FormatError); !isFormatError {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	return parse(), true
+}
@@ -0,0 +1,138 @@
+package classfile
+
+/*
+	field_info {
+	    u2             access_flags;
+	    u2             name_index;
+	    u2             descriptor_index;
+	    u2             attributes_count;
+	    attribute_info attributes[attributes_count];
+	}
+
+	method_info {
+	    u2             access_flags;
+	    u2             name_index;
+	    u2             descriptor_index;
+	    u2             attributes_count;
+	    attribute_info attributes[attributes_count];
+	}
+*/
+type MemberInfo struct {
+	cp              *ConstantPool
+	accessFlags     uint16
+	nameIndex       uint16
+	descriptorIndex uint16
+	attributes      []AttributeInfo
+}
+
+// 读取字段表或方法表
+func readMembers(reader *ClassReader, cp *ConstantPool) []*MemberInfo {
+	memberCount := int(reader.readUint16())
+	reader.need(memberCount * 8)
+	members := make([]*MemberInfo, memberCount)
+	for i := range members {
+		members[i] = readMember(reader, cp)
+	}
+	return members
+}
+
+func readMember(reader *ClassReader, cp *ConstantPool) *MemberInfo {
+	return &MemberInfo{
+		cp:              cp,
+		accessFlags:     reader.readUint16(),
+		nameIndex:       reader.readUint16(),
+		descriptorIndex: reader.readUint16(),
+		attributes:      readAttributes(reader, cp),
+	}
+}
+
+// NewMemberInfo 新建字段或方法，名字和描述符加到常量池里
+func NewMemberInfo(cp *ConstantPool, accessFlags uint16, name, descriptor string) *MemberInfo {
+	return &MemberInfo{
+		cp:              cp,
+		accessFlags:     accessFlags,
+		nameIndex:       cp.AddUtf8(name),
+		descriptorIndex: cp.AddUtf8(descriptor),
+	}
+}
+
+func writeMembers(writer *ClassWriter, members []*MemberInfo, what string) {
+	writer.writeCount(len(members), what)
+	for _, member := range members {
+		writer.writeUint16(member.accessFlags)
+		writer.writeUint16(member.nameIndex)
+		writer.writeUint16(member.descriptorIndex)
+		writeAttributes(writer, member.attributes)
+	}
+}
+
+func (memberInfo *MemberInfo) AccessFlags() uint16 {
+	return memberInfo.accessFlags
+}
+
+func (memberInfo *MemberInfo) NameIndex() uint16 {
+	return memberInfo.nameIndex
+}
+
+func (memberInfo *MemberInfo) DescriptorIndex() uint16 {
+	return memberInfo.descriptorIndex
+}
+
+func (memberInfo *MemberInfo) Name() string {
+	return memberInfo.cp.GetUtf8(memberInfo.nameIndex)
+}
+
+func (memberInfo *MemberInfo) Descriptor() string {
+	return memberInfo.cp.GetUtf8(memberInfo.descriptorIndex)
+}
+
+func (memberInfo *MemberInfo) Attributes() []AttributeInfo {
+	return memberInfo.attributes
+}
+
+func (memberInfo *MemberInfo) SetAccessFlags(accessFlags uint16) {
+	memberInfo.accessFlags = accessFlags
+}
+
+func (memberInfo *MemberInfo) SetNameIndex(nameIndex uint16) {
+	memberInfo.nameIndex = nameIndex
+}
+
+func (memberInfo *MemberInfo) SetDescriptorIndex(descriptorIndex uint16) {
+	memberInfo.descriptorIndex = descriptorIndex
+}
+
+func (memberInfo *MemberInfo) SetAttributes(attributes []AttributeInfo) {
+	memberInfo.attributes = attributes
+}
+
+func (memberInfo *MemberInfo) CodeAttribute() *CodeAttribute {
+	for _, attrInfo := range memberInfo.attributes {
+		if attr, ok := attrInfo.(*CodeAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (memberInfo *MemberInfo) ConstantValueAttribute() *ConstantValueAttribute {
+	for _, attrInfo := range memberInfo.attributes {
+		if attr, ok := attrInfo.(*ConstantValueAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (memberInfo *MemberInfo) ExceptionsAttribute() *ExceptionsAttribute {
+	for _, attrInfo := range memberInfo.attributes {
+		if attr, ok := attrInfo.(*ExceptionsAttribute); ok {
+			return attr
+		}
+	}
+	return nil
+}
+
+func (memberInfo *MemberInfo) SignatureAttribute() *SignatureAttribute {
+	return findSignatureAttribute(memberInfo.attributes)
+}
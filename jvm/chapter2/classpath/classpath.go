@@ -9,25 +9,58 @@ type Classpath struct {
 	bootClasspath Entry
 	extClasspath  Entry
 	userClasspath Entry
+	jreDir        string // 绝对路径
 }
 
 func Parse(jreOption, cpOption string) *Classpath {
 	cp := &Classpath{}
-	cp.parseBootAndExtClasspath(jreOption)
+	cp.parseBootAndExtClasspath(getJreDir(jreOption))
 	cp.parseUserClasspath(cpOption)
 	return cp
 }
 
+// ParseOptionalJre 和Parse一样，但是找不到JRE时启动和扩展类路径是空的，不panic。
+// javap、diff这些工具在没有JDK的机器上也要能用
+func ParseOptionalJre(jreOption, cpOption string) *Classpath {
+	cp := &Classpath{bootClasspath: CompositeEntry{}, extClasspath: CompositeEntry{}}
+	if jreDir, ok := findJreDir(jreOption); ok {
+		cp.parseBootAndExtClasspath(jreDir)
+	}
+	cp.parseUserClasspath(cpOption)
+	return cp
+}
+
+// Layer 类路径分成三层，分别由启动、平台（扩展）和应用类加载器使用
+type Layer int
+
+const (
+	Boot Layer = iota // jre/lib下的jar
+	Ext               // jre/lib/ext下的jar
+	User              // -cp指定的路径，默认是当前目录
+)
+
+// ReadClass 依次在三层里找类，javap等工具用；运行时每个类加载器只读自己那一层
 func (classpath *Classpath) ReadClass(className string) ([]byte, Entry, error) {
-	className = className + ".class"
-	if data, entry, err := classpath.bootClasspath.readClass(className); err == nil {
+	if data, entry, err := classpath.ReadClassFrom(Boot, className); err == nil {
 		return data, entry, err
 	}
 
-	if data, entry, err := classpath.extClasspath.readClass(className); err == nil {
+	if data, entry, err := classpath.ReadClassFrom(Ext, className); err == nil {
 		return data, entry, err
 	}
 
+	return classpath.ReadClassFrom(User, className)
+}
+
+// ReadClassFrom 只在layer这一层里找类
+func (classpath *Classpath) ReadClassFrom(layer Layer, className string) ([]byte, Entry, error) {
+	className = className + ".class"
+	switch layer {
+	case Boot:
+		return classpath.bootClasspath.readClass(className)
+	case Ext:
+		return classpath.extClasspath.readClass(className)
+	}
 	return classpath.userClasspath.readClass(className)
 }
 
@@ -35,8 +68,16 @@ func (classpath *Classpath) String() string {
 	return classpath.userClasspath.String()
 }
 
-func (classpath *Classpath) parseBootAndExtClasspath(jreOption string) {
-	jreDir := getJreDir(jreOption)
+// JreDir 返回找到的JRE目录，系统属性java.home的值；ParseOptionalJre没有找到JRE时是空串
+func (classpath *Classpath) JreDir() string {
+	return classpath.jreDir
+}
+
+func (classpath *Classpath) parseBootAndExtClasspath(jreDir string) {
+	if absDir, err := filepath.Abs(jreDir); err == nil {
+		jreDir = absDir
+	}
+	classpath.jreDir = jreDir
 
 	jreLibPath := filepath.Join(jreDir, "lib", "*")
 	classpath.bootClasspath = newWildcardEntry(jreLibPath)
@@ -45,19 +86,27 @@ func (classpath *Classpath) parseBootAndExtClasspath(jreOption string) {
 }
 
 func getJreDir(jreOption string) string {
+	if jreDir, ok := findJreDir(jreOption); ok {
+		return jreDir
+	}
+	panic("Can not find jre folder!")
+}
+
+// findJreDir 依次找-Xjre指定的目录、当前目录下的jre和JAVA_HOME/jre
+func findJreDir(jreOption string) (string, bool) {
 	if jreOption != "" && exists(jreOption) {
-		return jreOption
+		return jreOption, true
 	}
 
 	if exists("./jre") {
-		return "./jre"
+		return "./jre", true
 	}
 
 	if jh := os.Getenv("JAVA_HOME"); jh != "" {
-		return filepath.Join(jh, "jre")
+		return filepath.Join(jh, "jre"), true
 	}
 
-	panic("Can not find jre folder!")
+	return "", false
 }
 
 func exists(path string) bool {
@@ -76,3 +125,8 @@ func (classpath *Classpath) parseUserClasspath(cpOption string) {
 
 	classpath.userClasspath = newEntry(cpOption)
 }
+
+// AddEntry 把entry加到用户类路径的最前面
+func (classpath *Classpath) AddEntry(entry Entry) {
+	classpath.userClasspath = CompositeEntry{entry, classpath.userClasspath}
+}
@@ -0,0 +1,41 @@
+package classpath
+
+import "testing"
+
+// 没有JRE时工具模式只用用户类路径
+func TestParseOptionalJre(t *testing.T) {
+	t.Setenv("JAVA_HOME", "")
+	cp := ParseOptionalJre("", "../classfile/testdata")
+	if cp.JreDir() != "" {
+		t.Errorf("JreDir = %q, want empty", cp.JreDir())
+	}
+	if _, _, err := cp.ReadClass("pkg/Test"); err != nil {
+		t.Errorf("ReadClass(pkg/Test): %v", err)
+	}
+	if _, _, err := cp.ReadClassFrom(Boot, "java/lang/Object"); err == nil {
+		t.Error("found java/lang/Object without a JRE")
+	}
+}
+
+// AddEntry加的类优先于-cp上的同名类
+func TestAddEntry(t *testing.T) {
+	t.Setenv("JAVA_HOME", "")
+	cp := ParseOptionalJre("", "../classfile/testdata")
+	entry := NewMemoryEntry("<memory>")
+	entry.Add("pkg/Test", []byte{0xCA, 0xFE})
+	entry.Add("Generated", []byte{0xBE, 0xEF})
+	cp.AddEntry(entry)
+
+	for className, want := range map[string]byte{"pkg/Test": 0xCA, "Generated": 0xBE} {
+		data, from, err := cp.ReadClass(className)
+		if err != nil || data[0] != want || from != Entry(entry) {
+			t.Errorf("ReadClass(%s) = % X from %v, %v", className, data, from, err)
+		}
+	}
+	if _, from, err := cp.ReadClass("Hello"); err != nil || from.String() == "<memory>" {
+		t.Errorf("ReadClass(Hello) from %v, %v", from, err)
+	}
+	if _, _, err := cp.ReadClass("Missing"); err == nil {
+		t.Error("found a missing class")
+	}
+}
@@ -1,7 +1,6 @@
 package classpath
 
 import (
-	"fmt"
 	"io/ioutil"
 	"path/filepath"
 )
@@ -15,13 +14,11 @@ func newDirEntry(path string) *DirEntry {
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("--------DirEntry absDir : %s -------\n", absDir)
 	return &DirEntry{absDir}
 }
 
 func (dirEntry *DirEntry) readClass(className string) ([]byte, Entry, error) {
 	fileName := filepath.Join(dirEntry.absDir, className)
-	fmt.Printf("--------DirEntry fileName : %s -------\n", fileName)
 	data, err := ioutil.ReadFile(fileName)
 	return data, dirEntry, err
 }
@@ -0,0 +1,32 @@
+package classpath
+
+import (
+	"errors"
+	"strings"
+)
+
+// MemoryEntry 内存中的类，例如汇编器刚生成的class文件
+type MemoryEntry struct {
+	name    string
+	classes map[string][]byte // 类名（java/lang/Object的形式）-> class文件数据
+}
+
+func NewMemoryEntry(name string) *MemoryEntry {
+	return &MemoryEntry{name: name, classes: map[string][]byte{}}
+}
+
+// Add 加一个类，className是内部形式的类名，不带.class后缀
+func (memoryEntry *MemoryEntry) Add(className string, data []byte) {
+	memoryEntry.classes[className] = data
+}
+
+func (memoryEntry *MemoryEntry) readClass(className string) ([]byte, Entry, error) {
+	if data, ok := memoryEntry.classes[strings.TrimSuffix(className, ".class")]; ok {
+		return data, memoryEntry, nil
+	}
+	return nil, nil, errors.New("class not found:" + className)
+}
+
+func (memoryEntry *MemoryEntry) String() string {
+	return memoryEntry.name
+}
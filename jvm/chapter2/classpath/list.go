@@ -0,0 +1,69 @@
+package classpath
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UserClassNames 列出用户类路径上的所有类，返回排好序的内部形式类名（不带.class后缀）
+// 同名的类只出现一次，和ReadClass一样以先找到的为准
+func (classpath *Classpath) UserClassNames() ([]string, error) {
+	seen := map[string]bool{}
+	if err := listClasses(classpath.userClasspath, seen); err != nil {
+		return nil, err
+	}
+	classNames := make([]string, 0, len(seen))
+	for className := range seen {
+		classNames = append(classNames, className)
+	}
+	sort.Strings(classNames)
+	return classNames, nil
+}
+
+// 和Locate一样按Entry的具体类型处理
+func listClasses(entry Entry, seen map[string]bool) error {
+	add := func(fileName string) {
+		if strings.HasSuffix(fileName, ".class") {
+			seen[strings.TrimSuffix(fileName, ".class")] = true
+		}
+	}
+	switch e := entry.(type) {
+	case CompositeEntry:
+		for _, child := range e {
+			if err := listClasses(child, seen); err != nil {
+				return err
+			}
+		}
+	case *DirEntry:
+		return filepath.Walk(e.absDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				rel, err := filepath.Rel(e.absDir, path)
+				if err != nil {
+					return err
+				}
+				add(filepath.ToSlash(rel))
+			}
+			return nil
+		})
+	case *ZipEntry:
+		r, err := zip.OpenReader(e.absPath)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			add(f.Name)
+		}
+	case *MemoryEntry:
+		for className := range e.classes {
+			seen[className] = true
+		}
+	}
+	return nil
+}
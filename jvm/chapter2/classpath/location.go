@@ -0,0 +1,36 @@
+package classpath
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Locate 返回ReadClass找到的class文件的位置和修改时间，javap打印文件头时用
+// 目录里的类返回文件的绝对路径，jar里的类返回 jar:file:<jar路径>!/<类名>.class
+func Locate(entry Entry, className string) (string, time.Time) {
+	className = className + ".class"
+	switch e := entry.(type) {
+	case *DirEntry:
+		fileName := filepath.Join(e.absDir, className)
+		if info, err := os.Stat(fileName); err == nil {
+			return fileName, info.ModTime()
+		}
+		return fileName, time.Time{}
+	case *ZipEntry:
+		location := "jar:file:" + e.absPath + "!/" + className
+		r, err := zip.OpenReader(e.absPath)
+		if err != nil {
+			return location, time.Time{}
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if f.Name == className {
+				return location, f.Modified
+			}
+		}
+		return location, time.Time{}
+	}
+	return className, time.Time{}
+}
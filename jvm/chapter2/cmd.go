@@ -4,15 +4,29 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
 )
 
 type Cmd struct {
-	helpFlag    bool
-	versionFlag bool
-	cpOption    string
-	XjreOption  string
-	class       string
-	args        []string
+	helpFlag        bool
+	versionFlag     bool
+	cpOption        string
+	XjreOption      string
+	XverifyOption   string // -Xverify:none|remote|all|format 链接时校验哪些加载器的类
+	XmaxDepthOption uint   // -XmaxDepth 线程栈最多的帧数
+	javapFlag       bool   // -javap 不运行类，而是像javap一样打印class文件
+	codeFlag        bool   // -c
+	verboseFlag     bool   // -v
+	privateFlag     bool   // -p
+	asmFlag         bool   // -asm 把参数中的.j文件汇编成class文件
+	outDir          string // -d
+	framesFlag      bool   // -frames
+	diffFlag        bool   // -diff 比较两个类路径上的类
+	jsonFlag        bool   // -json
+	class           string
+	args            []string
 }
 
 func parseCmd() *Cmd {
@@ -24,7 +38,18 @@ func parseCmd() *Cmd {
 	flag.StringVar(&cmd.cpOption, "classpath", "", "classpath")
 	flag.StringVar(&cmd.cpOption, "cp", "", "classpath")
 	flag.StringVar(&cmd.XjreOption, "Xjre", "", "path to jre")
-	flag.Parse()
+	flag.StringVar(&cmd.XverifyOption, "Xverify", "", "-Xverify:none|remote|all|format selects which class loaders' classes are verified")
+	flag.UintVar(&cmd.XmaxDepthOption, "XmaxDepth", rtda.DefaultMaxStackDepth, "maximum number of frames on a thread's stack")
+	flag.BoolVar(&cmd.javapFlag, "javap", false, "disassemble the class like javap")
+	flag.BoolVar(&cmd.codeFlag, "c", false, "javap: disassemble the code")
+	flag.BoolVar(&cmd.verboseFlag, "v", false, "javap: print additional information")
+	flag.BoolVar(&cmd.privateFlag, "p", false, "javap: show all classes and members")
+	flag.BoolVar(&cmd.asmFlag, "asm", false, "assemble .j files into class files")
+	flag.StringVar(&cmd.outDir, "d", ".", "asm: output directory")
+	flag.BoolVar(&cmd.framesFlag, "frames", false, "asm: compute StackMapTable")
+	flag.BoolVar(&cmd.diffFlag, "diff", false, "compare the classes on two classpaths")
+	flag.BoolVar(&cmd.jsonFlag, "json", false, "diff: print the report as JSON")
+	flag.CommandLine.Parse(javaStyleOptions(os.Args[1:]))
 
 	args := flag.Args()
 	if len(args) > 0 {
@@ -34,6 +59,33 @@ func parseCmd() *Cmd {
 	return cmd
 }
 
+// javaStyleOptions 把java风格的 -Xverify:format 改写成flag包能解析的 -Xverify=format，
+// 类名之后是传给main方法的参数，不改写
+func javaStyleOptions(args []string) []string {
+	rewritten := append([]string(nil), args...)
+	for i := 0; i < len(rewritten); i++ {
+		arg := rewritten[i]
+		switch {
+		case strings.HasPrefix(arg, "-Xverify:"):
+			rewritten[i] = "-Xverify=" + strings.TrimPrefix(arg, "-Xverify:")
+		case arg == "-cp" || arg == "-classpath" || arg == "-Xjre" || arg == "-XmaxDepth" || arg == "-d":
+			i++ // 跳过选项的值
+		case !strings.HasPrefix(arg, "-"):
+			return rewritten
+		}
+	}
+	return rewritten
+}
+
 func printUsage() {
 	fmt.Printf("Usage : %s [-options] class [args...] \n", os.Args[0])
+	fmt.Printf("        -Xverify:remote  verify classes from every loader but the bootstrap loader (default)\n")
+	fmt.Printf("        -Xverify:all     verify every class, including those from the bootstrap loader\n")
+	fmt.Printf("        -Xverify:none    do not verify classes\n")
+	fmt.Printf("        -Xverify:format  only check the format of every class as it is linked\n")
+	fmt.Printf("        -XmaxDepth n     limit each thread's stack to n frames (default %d, at most %d)\n",
+		rtda.DefaultMaxStackDepth, rtda.MaxStackDepthLimit)
+	fmt.Printf("   or : %s -javap [-c] [-v] [-p] [-options] class \n", os.Args[0])
+	fmt.Printf("   or : %s -asm [-d dir] [-frames] file.j... \n", os.Args[0])
+	fmt.Printf("   or : %s -diff [-json] [-Xjre jre] oldClasspath newClasspath [class...] \n", os.Args[0])
 }
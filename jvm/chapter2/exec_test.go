@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+testdata/exec下的每个程序检查一组指令的语义，结果不对时抛出RuntimeException，退出码不是0。
+类经过校验再执行，校验器和解释器对同一段字节码的理解要一致
+*/
+func TestExecution(t *testing.T) {
+	cp := testClasspath(t, filepath.Join("testdata", "exec"))
+	for _, mainClass := range []string{"Arith", "Switch", "ClassInit", "Unwind", "Intern"} {
+		t.Run(mainClass, func(t *testing.T) {
+			loader := heap.NewClassLoaders(cp, nil, verifyClass)
+			if exitCode := interpret(loader, mainClass, nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+				t.Fatalf("exit code %d", exitCode)
+			}
+		})
+	}
+}
+
+// TestDispatch testdata/dispatch下是52版本的类，按JVMS 5.4.6选择invokevirtual、invokespecial和
+// invokeinterface调用的方法，包括默认方法和单独编译造成的AbstractMethodError、IncompatibleClassChangeError
+func TestDispatch(t *testing.T) {
+	loader := newTestLoader(t, filepath.Join("testdata", "dispatch"), verifyClass)
+	if exitCode := interpret(loader, "Dispatch", nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+		t.Fatalf("exit code %d", exitCode)
+	}
+}
+
+// TestStaticSynchronizedWithoutMirror 类库里没有java/lang/Class时，调用静态同步方法抛出NoClassDefFoundError
+func TestStaticSynchronizedWithoutMirror(t *testing.T) {
+	dir := t.TempDir()
+	cpDir := filepath.Join(dir, "classes")
+	assembleDir(t, filepath.Join("testdata", "mirror"), cpDir, asm.Options{})
+	loader := heap.NewClassLoaders(classpath.Parse(testJRE(t, dir, "java/lang/Class"), cpDir), nil, verifyClass)
+	if exitCode := interpret(loader, "StaticSync", nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+		t.Fatalf("exit code %d", exitCode)
+	}
+}
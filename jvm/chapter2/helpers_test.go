@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// newTestLoader 用testJRE做类库，srcDir下的类做用户类路径，返回应用类加载器。
+// 启动类加载器不校验，其他加载器用verifier校验，为nil时不校验
+func newTestLoader(t *testing.T, srcDir string, verifier heap.Verifier) *heap.ClassLoader {
+	t.Helper()
+	return heap.NewClassLoaders(testClasspath(t, srcDir), nil, verifier)
+}
+
+// testClasspath 汇编srcDir下的类到临时目录，汇编时计算StackMapTable。几个加载器要共用同一份类时用它
+func testClasspath(t *testing.T, srcDir string) *classpath.Classpath {
+	t.Helper()
+	dir := t.TempDir()
+	cpDir := filepath.Join(dir, "classes")
+	assembleDir(t, srcDir, cpDir, asm.Options{ComputeFrames: true})
+	return classpath.Parse(testJRE(t, dir), cpDir)
+}
+
+// testJRE 把testdata/jmm/lib下的最小类库汇编成dir/jre/lib/rt.jar，without里的类不放进去，返回jre目录
+func testJRE(t *testing.T, dir string, without ...string) string {
+	t.Helper()
+	jreDir := filepath.Join(dir, "jre")
+	lib := assembleClasses(t, filepath.Join("testdata", "jmm", "lib"), asm.Options{})
+	for _, className := range without {
+		delete(lib, className)
+	}
+	writeJar(t, lib, filepath.Join(jreDir, "lib", "rt.jar"))
+	return jreDir
+}
+
+// assembleClasses 汇编srcDir下的全部.j文件，返回类名到class文件数据的映射
+func assembleClasses(t *testing.T, srcDir string, options asm.Options) map[string][]byte {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(srcDir, "*.j"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no .j files in %s: %v", srcDir, err)
+	}
+	classes := map[string][]byte{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		className, data, err := asm.Assemble(file, source, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes[className] = data
+	}
+	return classes
+}
+
+func assembleDir(t *testing.T, srcDir, outDir string, options asm.Options) {
+	t.Helper()
+	for className, data := range assembleClasses(t, srcDir, options) {
+		path := filepath.Join(outDir, filepath.FromSlash(className)+".class")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func assembleJar(t *testing.T, srcDir, jarPath string, options asm.Options) {
+	t.Helper()
+	writeJar(t, assembleClasses(t, srcDir, options), jarPath)
+}
+
+// writeJar 把类按类名写到jar文件里
+func writeJar(t *testing.T, classes map[string][]byte, jarPath string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(jarPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := os.Create(jarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jar.Close()
+	writer := zip.NewWriter(jar)
+	for className, data := range classes {
+		entry, err := writer.Create(className + ".class")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
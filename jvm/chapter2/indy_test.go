@@ -0,0 +1,25 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+testdata/indy下是52版本的类，用invokedynamic创建lambda和拼接字符串，引导方法由native/java/lang/invoke实现。
+结果不对时抛出RuntimeException，退出码不是0
+*/
+func TestInvokeDynamic(t *testing.T) {
+	cp := testClasspath(t, filepath.Join("testdata", "indy"))
+	for _, mainClass := range []string{"Lambdas", "AltMeta", "Concat", "CallSites"} {
+		t.Run(mainClass, func(t *testing.T) {
+			loader := heap.NewClassLoaders(cp, nil, verifyClass)
+			if exitCode := interpret(loader, mainClass, nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+				t.Fatalf("exit code %d", exitCode)
+			}
+		})
+	}
+}
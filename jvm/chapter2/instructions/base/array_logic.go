@@ -0,0 +1,21 @@
+package base
+
+import (
+	"strconv"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// CheckNotNil 引用是null时抛出NullPointerException
+func CheckNotNil(ref *heap.Object) {
+	if ref == nil {
+		panic("java.lang.NullPointerException")
+	}
+}
+
+// CheckIndex 下标越界时抛出ArrayIndexOutOfBoundsException，消息是下标
+func CheckIndex(arr *heap.Object, index int32) {
+	if index < 0 || index >= arr.ArrayLength() {
+		panic("java.lang.ArrayIndexOutOfBoundsException: " + strconv.Itoa(int(index)))
+	}
+}
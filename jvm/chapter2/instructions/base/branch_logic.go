@@ -0,0 +1,12 @@
+package base
+
+import "go.buppt.cn/jvm/chapter2/rtda"
+
+// Branch 跳转到当前指令地址加offset处。往回跳时先检查安全点，循环里的线程也能停下来
+func Branch(frame *rtda.Frame, offset int) {
+	thread := frame.Thread()
+	if offset <= 0 {
+		thread.Safepoint()
+	}
+	frame.SetNextPC(thread.PC() + offset)
+}
@@ -0,0 +1,60 @@
+package base
+
+// BytecodeReader 从方法的字节码中按大端序读取操作数
+type BytecodeReader struct {
+	code []byte
+	pc   int
+}
+
+func (bytecodeReader *BytecodeReader) Reset(code []byte, pc int) {
+	bytecodeReader.code = code
+	bytecodeReader.pc = pc
+}
+
+func (bytecodeReader *BytecodeReader) PC() int {
+	return bytecodeReader.pc
+}
+
+func (bytecodeReader *BytecodeReader) ReadUint8() uint8 {
+	i := bytecodeReader.code[bytecodeReader.pc]
+	bytecodeReader.pc++
+	return i
+}
+
+func (bytecodeReader *BytecodeReader) ReadInt8() int8 {
+	return int8(bytecodeReader.ReadUint8())
+}
+
+func (bytecodeReader *BytecodeReader) ReadUint16() uint16 {
+	byte1 := uint16(bytecodeReader.ReadUint8())
+	byte2 := uint16(bytecodeReader.ReadUint8())
+	return (byte1 << 8) | byte2
+}
+
+func (bytecodeReader *BytecodeReader) ReadInt16() int16 {
+	return int16(bytecodeReader.ReadUint16())
+}
+
+func (bytecodeReader *BytecodeReader) ReadInt32() int32 {
+	byte1 := int32(bytecodeReader.ReadUint8())
+	byte2 := int32(bytecodeReader.ReadUint8())
+	byte3 := int32(bytecodeReader.ReadUint8())
+	byte4 := int32(bytecodeReader.ReadUint8())
+	return (byte1 << 24) | (byte2 << 16) | (byte3 << 8) | byte4
+}
+
+// ReadInt32s tableswitch和lookupswitch的跳转表
+func (bytecodeReader *BytecodeReader) ReadInt32s(n int32) []int32 {
+	ints := make([]int32, n)
+	for i := range ints {
+		ints[i] = bytecodeReader.ReadInt32()
+	}
+	return ints
+}
+
+// SkipPadding tableswitch和lookupswitch的操作数要按4字节对齐
+func (bytecodeReader *BytecodeReader) SkipPadding() {
+	for bytecodeReader.pc%4 != 0 {
+		bytecodeReader.ReadUint8()
+	}
+}
@@ -0,0 +1,64 @@
+package base
+
+import (
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// InitClass 按JVMS 5.5初始化类：先初始化超类和声明了默认方法的超接口，再执行<clinit>。
+// 类已经初始化或者正由当前线程初始化时直接返回，正由别的线程初始化时等它完成，否则返回时初始化已经完成。
+// 初始化抛出异常时类进入错误状态，以后再使用它会抛出NoClassDefFoundError
+func InitClass(thread *rtda.Thread, class *heap.Class) {
+	if class.IsInitialized() || !class.StartInit(thread) {
+		return
+	}
+	depth := thread.StackDepth()
+	defer func() {
+		if r := recover(); r != nil {
+			class.FailInit()
+			for thread.StackDepth() > depth {
+				thread.PopFrame()
+			}
+			panic(initError(thread, r))
+		}
+	}()
+	if !class.IsInterface() {
+		if superClass := class.SuperClass(); superClass != nil {
+			InitClass(thread, superClass)
+		}
+		initSuperInterfaces(thread, class)
+	}
+	if clinit := class.GetClinitMethod(); clinit != nil {
+		RunMethod(thread, clinit)
+	}
+	class.FinishInit()
+}
+
+// 接口的超接口先于接口初始化，只初始化声明了默认方法的接口
+func initSuperInterfaces(thread *rtda.Thread, class *heap.Class) {
+	for _, iface := range class.Interfaces() {
+		initSuperInterfaces(thread, iface)
+		if iface.DeclaresDefaultMethods() {
+			InitClass(thread, iface)
+		}
+	}
+}
+
+// initError 初始化时抛出的Error原样抛出，其他异常包装成ExceptionInInitializerError
+func initError(thread *rtda.Thread, r interface{}) (wrapped interface{}) {
+	ex := ToThrowable(thread, r)
+	if ex == nil || IsError(ex) {
+		return r
+	}
+	defer func() {
+		if recover() != nil {
+			wrapped = ex // 类库里没有ExceptionInInitializerError时抛出原来的异常
+		}
+	}()
+	boot := ex.Class().Loader().Bootstrap()
+	class := boot.LoadClass(thread, "java/lang/ExceptionInInitializerError")
+	InitClass(thread, class)
+	eiie := class.NewObject()
+	RunMethod(thread, getConstructor(class, "(Ljava/lang/Throwable;)V"), eiie, ex)
+	return eiie
+}
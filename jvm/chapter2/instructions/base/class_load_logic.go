@@ -0,0 +1,34 @@
+package base
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// LoadClassByJava 调用Java类加载器对象的loadClass(String)方法，用户定义的加载器加载类时用。
+// name是内部形式的类名；loadClass抛出ClassNotFoundException时返回nil，其他异常继续抛出
+func LoadClassByJava(thread *rtda.Thread, javaLoader *heap.Object, name string) (class *heap.Class) {
+	boot := javaLoader.Class().Loader().Bootstrap()
+	defer func() {
+		if r := recover(); r != nil {
+			ex, ok := r.(*heap.Object)
+			cnfe := boot.FindBootstrapClass(thread, "java/lang/ClassNotFoundException")
+			if !ok || cnfe == nil || !ex.IsInstanceOf(cnfe) {
+				panic(r)
+			}
+			class = nil
+		}
+	}()
+	loadClass := javaLoader.Class().LookupInstanceMethod("loadClass", "(Ljava/lang/String;)Ljava/lang/Class;")
+	if loadClass == nil {
+		panic("java.lang.NoSuchMethodError: " + javaLoader.Class().JavaName() + ".loadClass(Ljava/lang/String;)Ljava/lang/Class;")
+	}
+	jName := heap.JString(thread, boot, strings.Replace(name, "/", ".", -1))
+	jClass := RunMethod(thread, loadClass, javaLoader, jName).PopRef()
+	if jClass == nil {
+		return nil
+	}
+	return jClass.Extra().(*heap.Class)
+}
@@ -0,0 +1,201 @@
+package base
+
+import (
+	"fmt"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+指令和虚拟机内部用panic抛出异常：athrow抛出Java异常对象（*heap.Object），
+虚拟机自己发现的错误抛出以类名开头的字符串，例如"java.lang.NullPointerException"
+或者"java.lang.ArrayIndexOutOfBoundsException: 5"。解释器recover以后由
+HandleException把字符串换成对应异常类的实例，再按异常表展开栈帧
+*/
+
+// HandleException 在线程栈上从栈顶往下找能处理异常的帧，找到时把异常对象压入它的操作数栈，
+// 从处理器继续执行；栈里只剩depth个帧还没找到时弹出的帧都已丢弃，继续panic异常对象。
+// r不能换成异常对象时（比如类库里没有这个异常类）直接继续panic
+func HandleException(thread *rtda.Thread, depth uint, r interface{}) {
+	ex := ToThrowable(thread, r)
+	if ex == nil {
+		panic(r)
+	}
+	for thread.StackDepth() > depth {
+		frame := thread.CurrentFrame()
+		handlerPC, newEx := findExceptionHandler(frame, ex)
+		if newEx != nil {
+			ex = newEx // 解析catch类型时出错，新的异常代替原来的，这个帧不再处理
+		} else if handlerPC >= 0 {
+			stack := frame.OperandStack()
+			stack.Clear()
+			stack.PushRef(ex)
+			frame.SetNextPC(handlerPC)
+			thread.Safepoint() // 处理器可能在抛出异常的指令前面，和往回跳一样
+			return
+		}
+		thread.PopFrame()
+	}
+	panic(ex)
+}
+
+// findExceptionHandler 帧的pc取当前指令里的一个字节：栈顶帧是抛出异常的指令，
+// 下面的帧是正在执行的方法调用指令
+func findExceptionHandler(frame *rtda.Frame, ex *heap.Object) (handlerPC int, newEx *heap.Object) {
+	method := frame.Method()
+	if method == nil {
+		return -1, nil // 垫底帧
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if newEx = ToThrowable(frame.Thread(), r); newEx == nil {
+				panic(r)
+			}
+		}
+	}()
+	return method.FindExceptionHandler(frame.Thread(), ex.Class(), frame.NextPC()-1), nil
+}
+
+// ToThrowable 把panic的值换成Java异常对象，换不了时返回nil
+func ToThrowable(thread *rtda.Thread, r interface{}) *heap.Object {
+	var className, msg string
+	switch e := r.(type) {
+	case *heap.Object:
+		return e
+	case string:
+		if !strings.HasPrefix(e, "java.") {
+			return nil
+		}
+		className = e
+		if i := strings.Index(e, ": "); i >= 0 {
+			className, msg = e[:i], e[i+2:]
+		}
+	case *rtda.StackOverflowError:
+		className = "java.lang.StackOverflowError"
+	case *classfile.ClassFormatError:
+		className, msg = "java.lang.ClassFormatError", e.Error()
+		if len(e.Violations) > 1 {
+			for _, violation := range e.Violations {
+				msg += fmt.Sprintf("\n\t%s", violation)
+			}
+		}
+	default:
+		return nil
+	}
+	return NewThrowable(thread, strings.Replace(className, ".", "/", -1), msg)
+}
+
+// NewThrowable 创建启动类加载器里的异常类的实例并执行构造函数，msg为空时没有详细消息。
+// 异常类不能加载或者构造时出错返回nil。创建在预留的栈空间里进行，栈溢出时也能创建
+func NewThrowable(thread *rtda.Thread, className, msg string) (ex *heap.Object) {
+	defer func() {
+		if r := recover(); r != nil {
+			ex = nil
+		}
+	}()
+	thread.WithReservedStack(func() {
+		boot := bootLoader(thread)
+		class := boot.LoadClass(thread, className)
+		InitClass(thread, class)
+		ex = class.NewObject()
+		if msg == "" {
+			RunMethod(thread, getConstructor(class, "()V"), ex)
+		} else {
+			RunMethod(thread, getConstructor(class, "(Ljava/lang/String;)V"), ex, heap.JString(thread, boot, msg))
+		}
+	})
+	return ex
+}
+
+// bootLoader 通过栈里任意一个方法的类找到启动类加载器
+func bootLoader(thread *rtda.Thread) *heap.ClassLoader {
+	for _, frame := range thread.Frames() {
+		if method := frame.Method(); method != nil {
+			return method.Class().Loader().Bootstrap()
+		}
+	}
+	panic("no Java frame on the stack")
+}
+
+func getConstructor(class *heap.Class, descriptor string) *heap.Method {
+	for _, method := range class.Methods() {
+		if method.Name() == "<init>" && method.Descriptor() == descriptor {
+			return method
+		}
+	}
+	panic("java.lang.NoSuchMethodError: " + class.JavaName() + ".<init>" + descriptor)
+}
+
+// IsError 异常是不是java.lang.Error或者它的子类
+func IsError(ex *heap.Object) bool {
+	for c := ex.Class(); c != nil; c = c.SuperClass() {
+		if c.Name() == "java/lang/Error" && c.Loader().IsBootstrap() {
+			return true
+		}
+	}
+	return false
+}
+
+// StackTraceElement 栈轨迹里的一帧，和java.lang.StackTraceElement对应
+type StackTraceElement struct {
+	ClassName  string // Java形式的类名
+	MethodName string
+	FileName   string // 没有SourceFile属性时是空串
+	LineNumber int    // 本地方法是-2，没有行号时是-1
+}
+
+// String 和JDK 8的StackTraceElement.toString一样
+func (element *StackTraceElement) String() string {
+	switch {
+	case element.LineNumber == -2:
+		return element.ClassName + "." + element.MethodName + "(Native Method)"
+	case element.FileName != "" && element.LineNumber >= 0:
+		return fmt.Sprintf("%s.%s(%s:%d)", element.ClassName, element.MethodName, element.FileName, element.LineNumber)
+	case element.FileName != "":
+		return element.ClassName + "." + element.MethodName + "(" + element.FileName + ")"
+	}
+	return element.ClassName + "." + element.MethodName + "(Unknown Source)"
+}
+
+// FillInStackTrace 记录线程当前的栈轨迹，保存在异常对象的extra里。
+// 栈顶的fillInStackTrace帧和异常对象的构造函数帧不算在内
+func FillInStackTrace(thread *rtda.Thread, ex *heap.Object) {
+	frames := thread.Frames()
+	i := 0
+	for ; i < len(frames); i++ {
+		method := frames[i].Method()
+		if method != nil && !(method.Name() == "fillInStackTrace" && ex.IsInstanceOf(method.Class())) {
+			break
+		}
+	}
+	for ; i < len(frames); i++ {
+		method := frames[i].Method()
+		if method != nil && !(method.Name() == "<init>" && ex.IsInstanceOf(method.Class())) {
+			break
+		}
+	}
+	var stackTrace []*StackTraceElement
+	for _, frame := range frames[i:] {
+		method := frame.Method()
+		if method == nil {
+			continue
+		}
+		class := method.Class()
+		stackTrace = append(stackTrace, &StackTraceElement{
+			ClassName:  class.JavaName(),
+			MethodName: method.Name(),
+			FileName:   class.SourceFile(),
+			LineNumber: method.GetLineNumber(frame.NextPC() - 1),
+		})
+	}
+	ex.SetExtra(stackTrace)
+}
+
+// StackTrace 返回FillInStackTrace记录的栈轨迹
+func StackTrace(ex *heap.Object) []*StackTraceElement {
+	stackTrace, _ := ex.Extra().([]*StackTraceElement)
+	return stackTrace
+}
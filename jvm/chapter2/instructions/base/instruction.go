@@ -0,0 +1,45 @@
+package base
+
+import "go.buppt.cn/jvm/chapter2/rtda"
+
+// Instruction 一条指令：先从字节码中取出操作数，再执行
+type Instruction interface {
+	FetchOperands(reader *BytecodeReader)
+	Execute(frame *rtda.Frame)
+}
+
+// NoOperandsInstruction 没有操作数的指令
+type NoOperandsInstruction struct {
+	// empty
+}
+
+func (noOperandsInstruction *NoOperandsInstruction) FetchOperands(reader *BytecodeReader) {
+	// nothing to do
+}
+
+// BranchInstruction 跳转指令，Offset是相对于指令地址的偏移量
+type BranchInstruction struct {
+	Offset int
+}
+
+func (branchInstruction *BranchInstruction) FetchOperands(reader *BytecodeReader) {
+	branchInstruction.Offset = int(reader.ReadInt16())
+}
+
+// Index8Instruction 操作数是u1局部变量索引的指令
+type Index8Instruction struct {
+	Index uint
+}
+
+func (index8Instruction *Index8Instruction) FetchOperands(reader *BytecodeReader) {
+	index8Instruction.Index = uint(reader.ReadUint8())
+}
+
+// Index16Instruction 操作数是u2常量池索引的指令
+type Index16Instruction struct {
+	Index uint
+}
+
+func (index16Instruction *Index16Instruction) FetchOperands(reader *BytecodeReader) {
+	index16Instruction.Index = uint(reader.ReadUint16())
+}
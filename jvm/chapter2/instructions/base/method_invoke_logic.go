@@ -0,0 +1,93 @@
+package base
+
+import (
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Interpreter 执行线程栈顶的帧，直到栈里只剩下depth个帧
+type Interpreter func(thread *rtda.Thread, depth uint)
+
+var interpreter Interpreter
+
+// SetInterpreter 注册解释器循环，指令执行中要同步运行Java方法（比如<clinit>）时用。
+// 解释器依赖所有的指令，所以由main包在启动时注册，避免包的循环引用
+func SetInterpreter(fn Interpreter) {
+	interpreter = fn
+}
+
+// InvokeMethod 创建method的帧，把参数从调用者的操作数栈移到新帧的局部变量表，
+// 然后压到线程栈顶；当前指令执行完后解释器就开始执行新帧。
+// 同步方法在压栈以后进入this或者类对象的监视器，帧弹出时释放。
+// 静态同步方法的类对象在压栈之前取得，取不到时异常由调用者处理，方法不会在没有加锁时执行。
+// 新帧准备好以后检查安全点，递归调用的线程也能停下来
+func InvokeMethod(invokerFrame *rtda.Frame, method *heap.Method) {
+	thread := invokerFrame.Thread()
+	var jClass *heap.Object
+	if method.IsSynchronized() && method.IsStatic() {
+		jClass = method.Class().JClass(thread)
+	}
+	newFrame := thread.NewMethodFrame(method)
+	for i := int(method.ArgSlotCount()) - 1; i >= 0; i-- {
+		slot := invokerFrame.OperandStack().PopSlot()
+		newFrame.LocalVars().SetSlot(uint(i), slot)
+	}
+	thread.PushFrame(newFrame)
+	if method.IsSynchronized() {
+		if method.IsStatic() {
+			newFrame.Lock(jClass)
+		} else {
+			newFrame.Lock(newFrame.LocalVars().GetThis())
+		}
+	}
+	thread.Safepoint()
+}
+
+// RunMethod 在thread上同步执行method，方法返回后才返回。args是引用类型的参数，实例方法包括this。
+// 先压入一个垫底帧传递参数，返回值留在返回的操作数栈上由调用者按类型弹出。
+// 方法抛出异常时弹出执行期间压入的帧再panic；thread的pc在返回前恢复成调用时的值
+func RunMethod(thread *rtda.Thread, method *heap.Method, args ...*heap.Object) *rtda.OperandStack {
+	return RunMethodWithArgs(thread, method, func(stack *rtda.OperandStack) {
+		for _, arg := range args {
+			stack.PushRef(arg)
+		}
+	})
+}
+
+// RunMethodWithArgs 和RunMethod一样，参数由pushArgs按顺序压入垫底帧的操作数栈，
+// 可以有基本类型的参数，反射调用方法时用
+func RunMethodWithArgs(thread *rtda.Thread, method *heap.Method, pushArgs func(stack *rtda.OperandStack)) *rtda.OperandStack {
+	pc := thread.PC()
+	depth := thread.StackDepth()
+	defer func() {
+		if r := recover(); r != nil {
+			for thread.StackDepth() > depth {
+				thread.PopFrame()
+			}
+			thread.SetPC(pc)
+			panic(r)
+		}
+	}()
+	shimFrame := thread.NewShimFrame(method.ArgSlotCount() + 2)
+	thread.PushFrame(shimFrame)
+	pushArgs(shimFrame.OperandStack())
+	InvokeMethod(shimFrame, method)
+	interpreter(thread, depth+1)
+	thread.PopFrame()
+	thread.SetPC(pc)
+	return shimFrame.OperandStack()
+}
+
+// NewObject 初始化class，创建它的实例并执行描述符是descriptor的构造函数，args是引用类型的参数
+func NewObject(thread *rtda.Thread, class *heap.Class, descriptor string, args ...*heap.Object) *heap.Object {
+	InitClass(thread, class)
+	object := class.NewObject()
+	RunConstructor(thread, object, descriptor, args...)
+	return object
+}
+
+// RunConstructor 对已经创建的对象执行它的类声明的构造函数，虚拟机要在构造前设置字段时用
+func RunConstructor(thread *rtda.Thread, object *heap.Object, descriptor string, args ...*heap.Object) {
+	constructor := getConstructor(object.Class(), descriptor)
+	RunMethod(thread, constructor, append([]*heap.Object{object}, args...)...)
+}
@@ -0,0 +1,37 @@
+package comparisons
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Compare double
+// 有NaN时无法比较，dcmpg压入1，dcmpl压入-1
+type DCMPG struct{ base.NoOperandsInstruction }
+
+func (dcmpg *DCMPG) Execute(frame *rtda.Frame) {
+	_dcmp(frame, true)
+}
+
+type DCMPL struct{ base.NoOperandsInstruction }
+
+func (dcmpl *DCMPL) Execute(frame *rtda.Frame) {
+	_dcmp(frame, false)
+}
+
+func _dcmp(frame *rtda.Frame, gFlag bool) {
+	stack := frame.OperandStack()
+	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
+	if v1 > v2 {
+		stack.PushInt(1)
+	} else if v1 == v2 {
+		stack.PushInt(0)
+	} else if v1 < v2 {
+		stack.PushInt(-1)
+	} else if gFlag {
+		stack.PushInt(1)
+	} else {
+		stack.PushInt(-1)
+	}
+}
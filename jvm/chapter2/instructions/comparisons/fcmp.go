@@ -0,0 +1,37 @@
+package comparisons
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Compare float
+// 有NaN时无法比较，fcmpg压入1，fcmpl压入-1
+type FCMPG struct{ base.NoOperandsInstruction }
+
+func (fcmpg *FCMPG) Execute(frame *rtda.Frame) {
+	_fcmp(frame, true)
+}
+
+type FCMPL struct{ base.NoOperandsInstruction }
+
+func (fcmpl *FCMPL) Execute(frame *rtda.Frame) {
+	_fcmp(frame, false)
+}
+
+func _fcmp(frame *rtda.Frame, gFlag bool) {
+	stack := frame.OperandStack()
+	v2 := stack.PopFloat()
+	v1 := stack.PopFloat()
+	if v1 > v2 {
+		stack.PushInt(1)
+	} else if v1 == v2 {
+		stack.PushInt(0)
+	} else if v1 < v2 {
+		stack.PushInt(-1)
+	} else if gFlag {
+		stack.PushInt(1)
+	} else {
+		stack.PushInt(-1)
+	}
+}
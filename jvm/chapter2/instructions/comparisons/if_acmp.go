@@ -0,0 +1,31 @@
+package comparisons
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Branch if reference comparison succeeds
+type IF_ACMPEQ struct{ base.BranchInstruction }
+
+func (ifAcmpeq *IF_ACMPEQ) Execute(frame *rtda.Frame) {
+	if ref1, ref2 := _acmpPop(frame); ref1 == ref2 {
+		base.Branch(frame, ifAcmpeq.Offset)
+	}
+}
+
+type IF_ACMPNE struct{ base.BranchInstruction }
+
+func (ifAcmpne *IF_ACMPNE) Execute(frame *rtda.Frame) {
+	if ref1, ref2 := _acmpPop(frame); ref1 != ref2 {
+		base.Branch(frame, ifAcmpne.Offset)
+	}
+}
+
+func _acmpPop(frame *rtda.Frame) (ref1, ref2 *heap.Object) {
+	stack := frame.OperandStack()
+	ref2 = stack.PopRef()
+	ref1 = stack.PopRef()
+	return
+}
@@ -0,0 +1,61 @@
+package comparisons
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Branch if int comparison with zero succeeds
+type IFEQ struct{ base.BranchInstruction }
+
+func (ifEq *IFEQ) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val == 0 {
+		base.Branch(frame, ifEq.Offset)
+	}
+}
+
+type IFNE struct{ base.BranchInstruction }
+
+func (ifNe *IFNE) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val != 0 {
+		base.Branch(frame, ifNe.Offset)
+	}
+}
+
+type IFLT struct{ base.BranchInstruction }
+
+func (ifLt *IFLT) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val < 0 {
+		base.Branch(frame, ifLt.Offset)
+	}
+}
+
+type IFLE struct{ base.BranchInstruction }
+
+func (ifLe *IFLE) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val <= 0 {
+		base.Branch(frame, ifLe.Offset)
+	}
+}
+
+type IFGT struct{ base.BranchInstruction }
+
+func (ifGt *IFGT) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val > 0 {
+		base.Branch(frame, ifGt.Offset)
+	}
+}
+
+type IFGE struct{ base.BranchInstruction }
+
+func (ifGe *IFGE) Execute(frame *rtda.Frame) {
+	val := frame.OperandStack().PopInt()
+	if val >= 0 {
+		base.Branch(frame, ifGe.Offset)
+	}
+}
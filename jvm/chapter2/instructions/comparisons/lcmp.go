@@ -0,0 +1,22 @@
+package comparisons
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Compare long
+type LCMP struct{ base.NoOperandsInstruction }
+
+func (lcmp *LCMP) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	if v1 > v2 {
+		stack.PushInt(1)
+	} else if v1 == v2 {
+		stack.PushInt(0)
+	} else {
+		stack.PushInt(-1)
+	}
+}
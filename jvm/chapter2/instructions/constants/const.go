@@ -0,0 +1,111 @@
+package constants
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Push null
+type ACONST_NULL struct{ base.NoOperandsInstruction }
+
+func (aconstNull *ACONST_NULL) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushRef(nil)
+}
+
+// Push double
+type DCONST_0 struct{ base.NoOperandsInstruction }
+
+func (dconst0 *DCONST_0) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushDouble(0.0)
+}
+
+// Push double
+type DCONST_1 struct{ base.NoOperandsInstruction }
+
+func (dconst1 *DCONST_1) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushDouble(1.0)
+}
+
+// Push float
+type FCONST_0 struct{ base.NoOperandsInstruction }
+
+func (fconst0 *FCONST_0) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushFloat(0.0)
+}
+
+// Push float
+type FCONST_1 struct{ base.NoOperandsInstruction }
+
+func (fconst1 *FCONST_1) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushFloat(1.0)
+}
+
+// Push float
+type FCONST_2 struct{ base.NoOperandsInstruction }
+
+func (fconst2 *FCONST_2) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushFloat(2.0)
+}
+
+// Push int constant
+type ICONST_M1 struct{ base.NoOperandsInstruction }
+
+func (iconstM1 *ICONST_M1) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(-1)
+}
+
+// Push int constant
+type ICONST_0 struct{ base.NoOperandsInstruction }
+
+func (iconst0 *ICONST_0) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(0)
+}
+
+// Push int constant
+type ICONST_1 struct{ base.NoOperandsInstruction }
+
+func (iconst1 *ICONST_1) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(1)
+}
+
+// Push int constant
+type ICONST_2 struct{ base.NoOperandsInstruction }
+
+func (iconst2 *ICONST_2) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(2)
+}
+
+// Push int constant
+type ICONST_3 struct{ base.NoOperandsInstruction }
+
+func (iconst3 *ICONST_3) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(3)
+}
+
+// Push int constant
+type ICONST_4 struct{ base.NoOperandsInstruction }
+
+func (iconst4 *ICONST_4) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(4)
+}
+
+// Push int constant
+type ICONST_5 struct{ base.NoOperandsInstruction }
+
+func (iconst5 *ICONST_5) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(5)
+}
+
+// Push long constant
+type LCONST_0 struct{ base.NoOperandsInstruction }
+
+func (lconst0 *LCONST_0) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(0)
+}
+
+// Push long constant
+type LCONST_1 struct{ base.NoOperandsInstruction }
+
+func (lconst1 *LCONST_1) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(1)
+}
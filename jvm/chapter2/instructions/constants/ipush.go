@@ -0,0 +1,34 @@
+package constants
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Push byte
+type BIPUSH struct {
+	val int8
+}
+
+func (bipush *BIPUSH) FetchOperands(reader *base.BytecodeReader) {
+	bipush.val = reader.ReadInt8()
+}
+
+func (bipush *BIPUSH) Execute(frame *rtda.Frame) {
+	i := int32(bipush.val)
+	frame.OperandStack().PushInt(i)
+}
+
+// Push short
+type SIPUSH struct {
+	val int16
+}
+
+func (sipush *SIPUSH) FetchOperands(reader *base.BytecodeReader) {
+	sipush.val = reader.ReadInt16()
+}
+
+func (sipush *SIPUSH) Execute(frame *rtda.Frame) {
+	i := int32(sipush.val)
+	frame.OperandStack().PushInt(i)
+}
@@ -0,0 +1,113 @@
+package constants
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Push item from run-time constant pool
+type LDC struct{ base.Index8Instruction }
+
+func (ldc *LDC) Execute(frame *rtda.Frame) {
+	_ldc(frame, ldc.Index)
+}
+
+// Push item from run-time constant pool (wide index)
+type LDC_W struct{ base.Index16Instruction }
+
+func (ldcW *LDC_W) Execute(frame *rtda.Frame) {
+	_ldc(frame, ldcW.Index)
+}
+
+// 字符串常量取字符串池里的String对象，类常量解析类引用后取它的Class对象，
+// 方法类型和方法句柄常量由类库创建对应的Java对象，同一个常量总是得到同一个对象
+func _ldc(frame *rtda.Frame, index uint) {
+	thread := frame.Thread()
+	stack := frame.OperandStack()
+	class := frame.Method().Class()
+	switch c := class.ConstantPool().GetConstant(index).(type) {
+	case int32:
+		stack.PushInt(c)
+	case float32:
+		stack.PushFloat(c)
+	case string:
+		stack.PushRef(heap.InternedString(thread, class.Loader(), c))
+	case *heap.ClassRef:
+		stack.PushRef(c.ResolvedClass(thread).JClass(thread))
+	case *heap.MethodTypeRef:
+		if c.JType() == nil {
+			c.SetJType(newMethodType(thread, c.ReturnType(thread), c.ParameterTypes(thread)))
+		}
+		stack.PushRef(c.JType())
+	case *heap.MethodHandleRef:
+		if c.JHandle() == nil {
+			c.SetJHandle(linkMethodHandleConstant(thread, class, c))
+		}
+		stack.PushRef(c.JHandle())
+	default:
+		panic(fmt.Sprintf("todo: ldc %T", c))
+	}
+}
+
+// Push long or double from run-time constant pool (wide index)
+type LDC2_W struct{ base.Index16Instruction }
+
+func (ldc2W *LDC2_W) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	switch c := frame.Method().Class().ConstantPool().GetConstant(ldc2W.Index).(type) {
+	case int64:
+		stack.PushLong(c)
+	case float64:
+		stack.PushDouble(c)
+	default:
+		panic("java.lang.ClassFormatError")
+	}
+}
+
+// newMethodType 调用MethodType.methodType(Class, Class[])创建MethodType对象
+func newMethodType(thread *rtda.Thread, returnType *heap.Class, paramTypes []*heap.Class) *heap.Object {
+	boot := returnType.Loader().Bootstrap()
+	methodTypeClass := boot.LoadClass(thread, "java/lang/invoke/MethodType")
+	factory := methodTypeClass.GetStaticMethod("methodType", "(Ljava/lang/Class;[Ljava/lang/Class;)Ljava/lang/invoke/MethodType;")
+	if factory == nil {
+		panic("java.lang.NoSuchMethodError: java.lang.invoke.MethodType.methodType")
+	}
+	jParamTypes := boot.LoadClass(thread, "[Ljava/lang/Class;").NewArray(uint(len(paramTypes)))
+	for i, paramType := range paramTypes {
+		jParamTypes.Refs()[i] = paramType.JClass(thread)
+	}
+	base.InitClass(thread, methodTypeClass)
+	return base.RunMethod(thread, factory, returnType.JClass(thread), jParamTypes).PopRef()
+}
+
+// linkMethodHandleConstant 像HotSpot一样先解析引用的成员，再调用
+// MethodHandleNatives.linkMethodHandleConstant创建MethodHandle对象
+func linkMethodHandleConstant(thread *rtda.Thread, caller *heap.Class, ref *heap.MethodHandleRef) *heap.Object {
+	var member *heap.ClassMember
+	var jType *heap.Object
+	if ref.IsFieldHandle() {
+		field := ref.ResolvedField(thread)
+		member, jType = &field.ClassMember, field.Type(thread).JClass(thread)
+	} else {
+		method := ref.ResolvedMethod(thread)
+		member, jType = &method.ClassMember, newMethodType(thread, method.ReturnType(thread), method.ParameterTypes(thread))
+	}
+	boot := caller.Loader().Bootstrap()
+	nativesClass := boot.LoadClass(thread, "java/lang/invoke/MethodHandleNatives")
+	link := nativesClass.GetStaticMethod("linkMethodHandleConstant",
+		"(Ljava/lang/Class;ILjava/lang/Class;Ljava/lang/String;Ljava/lang/Object;)Ljava/lang/invoke/MethodHandle;")
+	if link == nil {
+		panic("java.lang.NoSuchMethodError: java.lang.invoke.MethodHandleNatives.linkMethodHandleConstant")
+	}
+	base.InitClass(thread, nativesClass)
+	return base.RunMethodWithArgs(thread, link, func(stack *rtda.OperandStack) {
+		stack.PushRef(caller.JClass(thread))
+		stack.PushInt(int32(ref.ReferenceKind()))
+		stack.PushRef(ref.ResolvedClass(thread).JClass(thread))
+		stack.PushRef(heap.InternedString(thread, boot, member.Name()))
+		stack.PushRef(jType)
+	}).PopRef()
+}
@@ -0,0 +1,13 @@
+package constants
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Do nothing
+type NOP struct{ base.NoOperandsInstruction }
+
+func (nop *NOP) Execute(frame *rtda.Frame) {
+	// really do nothing
+}
@@ -0,0 +1,13 @@
+package control
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Branch always
+type GOTO struct{ base.BranchInstruction }
+
+func (_goto *GOTO) Execute(frame *rtda.Frame) {
+	base.Branch(frame, _goto.Offset)
+}
@@ -0,0 +1,32 @@
+package control
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Jump subroutine
+// 老版本javac编译finally用的指令，把下一条指令的地址作为returnAddress压栈后跳转，
+// returnAddress和int一样存在槽位里
+type JSR struct{ base.BranchInstruction }
+
+func (jsr *JSR) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(int32(frame.NextPC()))
+	base.Branch(frame, jsr.Offset)
+}
+
+// Return from subroutine
+type RET struct{ base.Index8Instruction }
+
+func (ret *RET) Execute(frame *rtda.Frame) {
+	_ret(frame, ret.Index)
+}
+
+// _ret 和往回跳的分支指令一样，返回到前面的地址时检查安全点
+func _ret(frame *rtda.Frame, index uint) {
+	nextPC := int(frame.LocalVars().GetInt(index))
+	if thread := frame.Thread(); nextPC <= thread.PC() {
+		thread.Safepoint()
+	}
+	frame.SetNextPC(nextPC)
+}
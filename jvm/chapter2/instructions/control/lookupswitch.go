@@ -0,0 +1,48 @@
+package control
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+/*
+lookupswitch
+<0-3 byte pad>
+defaultbyte1
+defaultbyte2
+defaultbyte3
+defaultbyte4
+npairs1
+npairs2
+npairs3
+npairs4
+match-offset pairs...
+*/
+// Access jump table by key match and jump
+type LOOKUP_SWITCH struct {
+	defaultOffset int32
+	npairs        int32
+	matchOffsets  []int32 // key和offset交替排列
+}
+
+func (lookupSwitch *LOOKUP_SWITCH) FetchOperands(reader *base.BytecodeReader) {
+	reader.SkipPadding()
+	lookupSwitch.defaultOffset = reader.ReadInt32()
+	lookupSwitch.npairs = reader.ReadInt32()
+	if lookupSwitch.npairs < 0 {
+		panic("java.lang.VerifyError: lookupswitch npairs < 0")
+	}
+	lookupSwitch.matchOffsets = reader.ReadInt32s(lookupSwitch.npairs * 2)
+}
+
+func (lookupSwitch *LOOKUP_SWITCH) Execute(frame *rtda.Frame) {
+	key := frame.OperandStack().PopInt()
+	for i := int32(0); i < lookupSwitch.npairs*2; i += 2 {
+		if lookupSwitch.matchOffsets[i] == key {
+			offset := lookupSwitch.matchOffsets[i+1]
+			base.Branch(frame, int(offset))
+			return
+		}
+	}
+	base.Branch(frame, int(lookupSwitch.defaultOffset))
+}
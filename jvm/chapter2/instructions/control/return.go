@@ -0,0 +1,82 @@
+package control
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// 返回指令弹出当前帧，返回值压入调用者的操作数栈；
+// main方法返回后栈就空了，这时没有调用者
+
+// Return void from method
+type RETURN struct{ base.NoOperandsInstruction }
+
+// Execute 声明了final实例变量的类的构造函数返回时冻结它们，见heap.Object.Freeze
+func (_return *RETURN) Execute(frame *rtda.Frame) {
+	if method := frame.Method(); method.Class().HasFinalFields() && method.Name() == "<init>" {
+		if this := frame.LocalVars().GetThis(); this != nil {
+			this.Freeze()
+		}
+	}
+	frame.Thread().PopFrame()
+}
+
+// Return reference from method
+type ARETURN struct{ base.NoOperandsInstruction }
+
+func (areturn *ARETURN) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentFrame := thread.PopFrame()
+	retVal := currentFrame.OperandStack().PopRef()
+	if !thread.IsStackEmpty() {
+		thread.CurrentFrame().OperandStack().PushRef(retVal)
+	}
+}
+
+// Return double from method
+type DRETURN struct{ base.NoOperandsInstruction }
+
+func (dreturn *DRETURN) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentFrame := thread.PopFrame()
+	retVal := currentFrame.OperandStack().PopDouble()
+	if !thread.IsStackEmpty() {
+		thread.CurrentFrame().OperandStack().PushDouble(retVal)
+	}
+}
+
+// Return float from method
+type FRETURN struct{ base.NoOperandsInstruction }
+
+func (freturn *FRETURN) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentFrame := thread.PopFrame()
+	retVal := currentFrame.OperandStack().PopFloat()
+	if !thread.IsStackEmpty() {
+		thread.CurrentFrame().OperandStack().PushFloat(retVal)
+	}
+}
+
+// Return int from method
+type IRETURN struct{ base.NoOperandsInstruction }
+
+func (ireturn *IRETURN) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentFrame := thread.PopFrame()
+	retVal := currentFrame.OperandStack().PopInt()
+	if !thread.IsStackEmpty() {
+		thread.CurrentFrame().OperandStack().PushInt(retVal)
+	}
+}
+
+// Return long from method
+type LRETURN struct{ base.NoOperandsInstruction }
+
+func (lreturn *LRETURN) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentFrame := thread.PopFrame()
+	retVal := currentFrame.OperandStack().PopLong()
+	if !thread.IsStackEmpty() {
+		thread.CurrentFrame().OperandStack().PushLong(retVal)
+	}
+}
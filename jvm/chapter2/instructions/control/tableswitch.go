@@ -0,0 +1,56 @@
+package control
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+/*
+tableswitch
+<0-3 byte pad>
+defaultbyte1
+defaultbyte2
+defaultbyte3
+defaultbyte4
+lowbyte1
+lowbyte2
+lowbyte3
+lowbyte4
+highbyte1
+highbyte2
+highbyte3
+highbyte4
+jump offsets...
+*/
+// Access jump table by index and jump
+type TABLE_SWITCH struct {
+	defaultOffset int32
+	low           int32
+	high          int32
+	jumpOffsets   []int32
+}
+
+func (tableSwitch *TABLE_SWITCH) FetchOperands(reader *base.BytecodeReader) {
+	reader.SkipPadding()
+	tableSwitch.defaultOffset = reader.ReadInt32()
+	tableSwitch.low = reader.ReadInt32()
+	tableSwitch.high = reader.ReadInt32()
+	jumpOffsetsCount := int64(tableSwitch.high) - int64(tableSwitch.low) + 1
+	if jumpOffsetsCount <= 0 {
+		panic("java.lang.VerifyError: tableswitch low > high")
+	}
+	tableSwitch.jumpOffsets = reader.ReadInt32s(int32(jumpOffsetsCount))
+}
+
+func (tableSwitch *TABLE_SWITCH) Execute(frame *rtda.Frame) {
+	index := frame.OperandStack().PopInt()
+
+	var offset int
+	if index >= tableSwitch.low && index <= tableSwitch.high {
+		offset = int(tableSwitch.jumpOffsets[index-tableSwitch.low])
+	} else {
+		offset = int(tableSwitch.defaultOffset)
+	}
+
+	base.Branch(frame, offset)
+}
@@ -0,0 +1,64 @@
+package conversions
+
+import (
+	"math"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Convert double to float
+type D2F struct{ base.NoOperandsInstruction }
+
+func (d2f *D2F) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	d := stack.PopDouble()
+	f := float32(d)
+	stack.PushFloat(f)
+}
+
+// Convert double to int
+type D2I struct{ base.NoOperandsInstruction }
+
+func (d2I *D2I) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	d := stack.PopDouble()
+	i := d2i(d)
+	stack.PushInt(i)
+}
+
+// Convert double to long
+type D2L struct{ base.NoOperandsInstruction }
+
+func (d2L *D2L) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	d := stack.PopDouble()
+	l := d2l(d)
+	stack.PushLong(l)
+}
+
+// Go里超出范围的浮点数转整数结果是未定义的，Java则规定：
+// NaN转成0，超出范围的取最大值或最小值，其余向0取整
+func d2i(d float64) int32 {
+	switch {
+	case math.IsNaN(d):
+		return 0
+	case d >= math.MaxInt32:
+		return math.MaxInt32
+	case d <= math.MinInt32:
+		return math.MinInt32
+	}
+	return int32(d)
+}
+
+func d2l(d float64) int64 {
+	switch {
+	case math.IsNaN(d):
+		return 0
+	case d >= math.MaxInt64: // 2^63，float64表示不了MaxInt64
+		return math.MaxInt64
+	case d <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(d)
+}
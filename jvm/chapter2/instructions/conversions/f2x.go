@@ -0,0 +1,36 @@
+package conversions
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Convert float to double
+type F2D struct{ base.NoOperandsInstruction }
+
+func (f2d *F2D) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	f := stack.PopFloat()
+	d := float64(f)
+	stack.PushDouble(d)
+}
+
+// Convert float to int
+type F2I struct{ base.NoOperandsInstruction }
+
+func (f2i *F2I) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	f := stack.PopFloat()
+	i := d2i(float64(f))
+	stack.PushInt(i)
+}
+
+// Convert float to long
+type F2L struct{ base.NoOperandsInstruction }
+
+func (f2l *F2L) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	f := stack.PopFloat()
+	l := d2l(float64(f))
+	stack.PushLong(l)
+}
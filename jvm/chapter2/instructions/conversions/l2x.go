@@ -0,0 +1,37 @@
+package conversions
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Convert long to double
+type L2D struct{ base.NoOperandsInstruction }
+
+func (l2d *L2D) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	l := stack.PopLong()
+	d := float64(l)
+	stack.PushDouble(d)
+}
+
+// Convert long to float
+type L2F struct{ base.NoOperandsInstruction }
+
+func (l2f *L2F) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	l := stack.PopLong()
+	f := float32(l)
+	stack.PushFloat(f)
+}
+
+// Convert long to int
+// 只保留低32位
+type L2I struct{ base.NoOperandsInstruction }
+
+func (l2i *L2I) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	l := stack.PopLong()
+	i := int32(l)
+	stack.PushInt(i)
+}
@@ -0,0 +1,33 @@
+package extended
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Branch always (wide index)
+type GOTO_W struct {
+	offset int
+}
+
+func (gotoW *GOTO_W) FetchOperands(reader *base.BytecodeReader) {
+	gotoW.offset = int(reader.ReadInt32())
+}
+
+func (gotoW *GOTO_W) Execute(frame *rtda.Frame) {
+	base.Branch(frame, gotoW.offset)
+}
+
+// Jump subroutine (wide index)
+type JSR_W struct {
+	offset int
+}
+
+func (jsrW *JSR_W) FetchOperands(reader *base.BytecodeReader) {
+	jsrW.offset = int(reader.ReadInt32())
+}
+
+func (jsrW *JSR_W) Execute(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(int32(frame.NextPC()))
+	base.Branch(frame, jsrW.offset)
+}
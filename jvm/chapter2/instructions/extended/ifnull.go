@@ -0,0 +1,26 @@
+package extended
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Branch if reference is null
+type IFNULL struct{ base.BranchInstruction }
+
+func (ifnull *IFNULL) Execute(frame *rtda.Frame) {
+	ref := frame.OperandStack().PopRef()
+	if ref == nil {
+		base.Branch(frame, ifnull.Offset)
+	}
+}
+
+// Branch if reference not null
+type IFNONNULL struct{ base.BranchInstruction }
+
+func (ifnonnull *IFNONNULL) Execute(frame *rtda.Frame) {
+	ref := frame.OperandStack().PopRef()
+	if ref != nil {
+		base.Branch(frame, ifnonnull.Offset)
+	}
+}
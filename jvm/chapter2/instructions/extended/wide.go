@@ -0,0 +1,78 @@
+package extended
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/instructions/control"
+	"go.buppt.cn/jvm/chapter2/instructions/loads"
+	"go.buppt.cn/jvm/chapter2/instructions/math"
+	"go.buppt.cn/jvm/chapter2/instructions/stores"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Extend local variable index by additional bytes
+// wide修饰后面的一条指令，把局部变量索引扩展成u2，iinc的增量也扩展成s2
+type WIDE struct {
+	modifiedInstruction base.Instruction
+}
+
+func (wide *WIDE) FetchOperands(reader *base.BytecodeReader) {
+	opcode := reader.ReadUint8()
+	switch opcode {
+	case opcodes.Iload:
+		inst := &loads.ILOAD{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Lload:
+		inst := &loads.LLOAD{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Fload:
+		inst := &loads.FLOAD{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Dload:
+		inst := &loads.DLOAD{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Aload:
+		inst := &loads.ALOAD{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Istore:
+		inst := &stores.ISTORE{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Lstore:
+		inst := &stores.LSTORE{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Fstore:
+		inst := &stores.FSTORE{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Dstore:
+		inst := &stores.DSTORE{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Astore:
+		inst := &stores.ASTORE{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Ret:
+		inst := &control.RET{}
+		inst.Index = uint(reader.ReadUint16())
+		wide.modifiedInstruction = inst
+	case opcodes.Iinc:
+		inst := &math.IINC{}
+		inst.Index = uint(reader.ReadUint16())
+		inst.Const = int32(reader.ReadInt16())
+		wide.modifiedInstruction = inst
+	default:
+		panic("java.lang.VerifyError: illegal wide opcode")
+	}
+}
+
+func (wide *WIDE) Execute(frame *rtda.Frame) {
+	wide.modifiedInstruction.Execute(frame)
+}
@@ -0,0 +1,583 @@
+package instructions
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/instructions/comparisons"
+	"go.buppt.cn/jvm/chapter2/instructions/constants"
+	"go.buppt.cn/jvm/chapter2/instructions/control"
+	"go.buppt.cn/jvm/chapter2/instructions/conversions"
+	"go.buppt.cn/jvm/chapter2/instructions/extended"
+	"go.buppt.cn/jvm/chapter2/instructions/loads"
+	"go.buppt.cn/jvm/chapter2/instructions/math"
+	"go.buppt.cn/jvm/chapter2/instructions/references"
+	"go.buppt.cn/jvm/chapter2/instructions/reserved"
+	"go.buppt.cn/jvm/chapter2/instructions/stack"
+	"go.buppt.cn/jvm/chapter2/instructions/stores"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+)
+
+// 没有操作数的指令没有状态，可以共用一个实例
+var (
+	nop         = &constants.NOP{}
+	aconstNull  = &constants.ACONST_NULL{}
+	iconstM1    = &constants.ICONST_M1{}
+	iconst0     = &constants.ICONST_0{}
+	iconst1     = &constants.ICONST_1{}
+	iconst2     = &constants.ICONST_2{}
+	iconst3     = &constants.ICONST_3{}
+	iconst4     = &constants.ICONST_4{}
+	iconst5     = &constants.ICONST_5{}
+	lconst0     = &constants.LCONST_0{}
+	lconst1     = &constants.LCONST_1{}
+	fconst0     = &constants.FCONST_0{}
+	fconst1     = &constants.FCONST_1{}
+	fconst2     = &constants.FCONST_2{}
+	dconst0     = &constants.DCONST_0{}
+	dconst1     = &constants.DCONST_1{}
+	iload0      = &loads.ILOAD_0{}
+	iload1      = &loads.ILOAD_1{}
+	iload2      = &loads.ILOAD_2{}
+	iload3      = &loads.ILOAD_3{}
+	lload0      = &loads.LLOAD_0{}
+	lload1      = &loads.LLOAD_1{}
+	lload2      = &loads.LLOAD_2{}
+	lload3      = &loads.LLOAD_3{}
+	fload0      = &loads.FLOAD_0{}
+	fload1      = &loads.FLOAD_1{}
+	fload2      = &loads.FLOAD_2{}
+	fload3      = &loads.FLOAD_3{}
+	dload0      = &loads.DLOAD_0{}
+	dload1      = &loads.DLOAD_1{}
+	dload2      = &loads.DLOAD_2{}
+	dload3      = &loads.DLOAD_3{}
+	aload0      = &loads.ALOAD_0{}
+	aload1      = &loads.ALOAD_1{}
+	aload2      = &loads.ALOAD_2{}
+	aload3      = &loads.ALOAD_3{}
+	iaload      = &loads.IALOAD{}
+	laload      = &loads.LALOAD{}
+	faload      = &loads.FALOAD{}
+	daload      = &loads.DALOAD{}
+	aaload      = &loads.AALOAD{}
+	baload      = &loads.BALOAD{}
+	caload      = &loads.CALOAD{}
+	saload      = &loads.SALOAD{}
+	istore0     = &stores.ISTORE_0{}
+	istore1     = &stores.ISTORE_1{}
+	istore2     = &stores.ISTORE_2{}
+	istore3     = &stores.ISTORE_3{}
+	lstore0     = &stores.LSTORE_0{}
+	lstore1     = &stores.LSTORE_1{}
+	lstore2     = &stores.LSTORE_2{}
+	lstore3     = &stores.LSTORE_3{}
+	fstore0     = &stores.FSTORE_0{}
+	fstore1     = &stores.FSTORE_1{}
+	fstore2     = &stores.FSTORE_2{}
+	fstore3     = &stores.FSTORE_3{}
+	dstore0     = &stores.DSTORE_0{}
+	dstore1     = &stores.DSTORE_1{}
+	dstore2     = &stores.DSTORE_2{}
+	dstore3     = &stores.DSTORE_3{}
+	astore0     = &stores.ASTORE_0{}
+	astore1     = &stores.ASTORE_1{}
+	astore2     = &stores.ASTORE_2{}
+	astore3     = &stores.ASTORE_3{}
+	iastore     = &stores.IASTORE{}
+	lastore     = &stores.LASTORE{}
+	fastore     = &stores.FASTORE{}
+	dastore     = &stores.DASTORE{}
+	aastore     = &stores.AASTORE{}
+	bastore     = &stores.BASTORE{}
+	castore     = &stores.CASTORE{}
+	sastore     = &stores.SASTORE{}
+	pop         = &stack.POP{}
+	pop2        = &stack.POP2{}
+	dup         = &stack.DUP{}
+	dupX1       = &stack.DUP_X1{}
+	dupX2       = &stack.DUP_X2{}
+	dup2        = &stack.DUP2{}
+	dup2X1      = &stack.DUP2_X1{}
+	dup2X2      = &stack.DUP2_X2{}
+	swap        = &stack.SWAP{}
+	iadd        = &math.IADD{}
+	ladd        = &math.LADD{}
+	fadd        = &math.FADD{}
+	dadd        = &math.DADD{}
+	isub        = &math.ISUB{}
+	lsub        = &math.LSUB{}
+	fsub        = &math.FSUB{}
+	dsub        = &math.DSUB{}
+	imul        = &math.IMUL{}
+	lmul        = &math.LMUL{}
+	fmul        = &math.FMUL{}
+	dmul        = &math.DMUL{}
+	idiv        = &math.IDIV{}
+	ldiv        = &math.LDIV{}
+	fdiv        = &math.FDIV{}
+	ddiv        = &math.DDIV{}
+	irem        = &math.IREM{}
+	lrem        = &math.LREM{}
+	frem        = &math.FREM{}
+	drem        = &math.DREM{}
+	ineg        = &math.INEG{}
+	lneg        = &math.LNEG{}
+	fneg        = &math.FNEG{}
+	dneg        = &math.DNEG{}
+	ishl        = &math.ISHL{}
+	lshl        = &math.LSHL{}
+	ishr        = &math.ISHR{}
+	lshr        = &math.LSHR{}
+	iushr       = &math.IUSHR{}
+	lushr       = &math.LUSHR{}
+	iand        = &math.IAND{}
+	land        = &math.LAND{}
+	ior         = &math.IOR{}
+	lor         = &math.LOR{}
+	ixor        = &math.IXOR{}
+	lxor        = &math.LXOR{}
+	i2l         = &conversions.I2L{}
+	i2f         = &conversions.I2F{}
+	i2d         = &conversions.I2D{}
+	i2b         = &conversions.I2B{}
+	i2c         = &conversions.I2C{}
+	i2s         = &conversions.I2S{}
+	l2i         = &conversions.L2I{}
+	l2f         = &conversions.L2F{}
+	l2d         = &conversions.L2D{}
+	f2i         = &conversions.F2I{}
+	f2l         = &conversions.F2L{}
+	f2d         = &conversions.F2D{}
+	d2i         = &conversions.D2I{}
+	d2l         = &conversions.D2L{}
+	d2f         = &conversions.D2F{}
+	lcmp        = &comparisons.LCMP{}
+	fcmpl       = &comparisons.FCMPL{}
+	fcmpg       = &comparisons.FCMPG{}
+	dcmpl       = &comparisons.DCMPL{}
+	dcmpg       = &comparisons.DCMPG{}
+	ireturn     = &control.IRETURN{}
+	lreturn     = &control.LRETURN{}
+	freturn     = &control.FRETURN{}
+	dreturn     = &control.DRETURN{}
+	areturn     = &control.ARETURN{}
+	_return     = &control.RETURN{}
+	arrayLength = &references.ARRAY_LENGTH{}
+	athrow      = &references.ATHROW{}
+	impdep1     = &reserved.INVOKE_NATIVE{}
+)
+
+// NewInstruction 根据操作码创建指令
+func NewInstruction(opcode byte) base.Instruction {
+	switch opcode {
+	case opcodes.Nop:
+		return nop
+	case opcodes.AconstNull:
+		return aconstNull
+	case opcodes.IconstM1:
+		return iconstM1
+	case opcodes.Iconst0:
+		return iconst0
+	case opcodes.Iconst1:
+		return iconst1
+	case opcodes.Iconst2:
+		return iconst2
+	case opcodes.Iconst3:
+		return iconst3
+	case opcodes.Iconst4:
+		return iconst4
+	case opcodes.Iconst5:
+		return iconst5
+	case opcodes.Lconst0:
+		return lconst0
+	case opcodes.Lconst1:
+		return lconst1
+	case opcodes.Fconst0:
+		return fconst0
+	case opcodes.Fconst1:
+		return fconst1
+	case opcodes.Fconst2:
+		return fconst2
+	case opcodes.Dconst0:
+		return dconst0
+	case opcodes.Dconst1:
+		return dconst1
+	case opcodes.Bipush:
+		return &constants.BIPUSH{}
+	case opcodes.Sipush:
+		return &constants.SIPUSH{}
+	case opcodes.Ldc:
+		return &constants.LDC{}
+	case opcodes.LdcW:
+		return &constants.LDC_W{}
+	case opcodes.Ldc2W:
+		return &constants.LDC2_W{}
+	case opcodes.Iload:
+		return &loads.ILOAD{}
+	case opcodes.Lload:
+		return &loads.LLOAD{}
+	case opcodes.Fload:
+		return &loads.FLOAD{}
+	case opcodes.Dload:
+		return &loads.DLOAD{}
+	case opcodes.Aload:
+		return &loads.ALOAD{}
+	case opcodes.Iload0:
+		return iload0
+	case opcodes.Iload1:
+		return iload1
+	case opcodes.Iload2:
+		return iload2
+	case opcodes.Iload3:
+		return iload3
+	case opcodes.Lload0:
+		return lload0
+	case opcodes.Lload1:
+		return lload1
+	case opcodes.Lload2:
+		return lload2
+	case opcodes.Lload3:
+		return lload3
+	case opcodes.Fload0:
+		return fload0
+	case opcodes.Fload1:
+		return fload1
+	case opcodes.Fload2:
+		return fload2
+	case opcodes.Fload3:
+		return fload3
+	case opcodes.Dload0:
+		return dload0
+	case opcodes.Dload1:
+		return dload1
+	case opcodes.Dload2:
+		return dload2
+	case opcodes.Dload3:
+		return dload3
+	case opcodes.Aload0:
+		return aload0
+	case opcodes.Aload1:
+		return aload1
+	case opcodes.Aload2:
+		return aload2
+	case opcodes.Aload3:
+		return aload3
+	case opcodes.Iaload:
+		return iaload
+	case opcodes.Laload:
+		return laload
+	case opcodes.Faload:
+		return faload
+	case opcodes.Daload:
+		return daload
+	case opcodes.Aaload:
+		return aaload
+	case opcodes.Baload:
+		return baload
+	case opcodes.Caload:
+		return caload
+	case opcodes.Saload:
+		return saload
+	case opcodes.Istore:
+		return &stores.ISTORE{}
+	case opcodes.Lstore:
+		return &stores.LSTORE{}
+	case opcodes.Fstore:
+		return &stores.FSTORE{}
+	case opcodes.Dstore:
+		return &stores.DSTORE{}
+	case opcodes.Astore:
+		return &stores.ASTORE{}
+	case opcodes.Istore0:
+		return istore0
+	case opcodes.Istore1:
+		return istore1
+	case opcodes.Istore2:
+		return istore2
+	case opcodes.Istore3:
+		return istore3
+	case opcodes.Lstore0:
+		return lstore0
+	case opcodes.Lstore1:
+		return lstore1
+	case opcodes.Lstore2:
+		return lstore2
+	case opcodes.Lstore3:
+		return lstore3
+	case opcodes.Fstore0:
+		return fstore0
+	case opcodes.Fstore1:
+		return fstore1
+	case opcodes.Fstore2:
+		return fstore2
+	case opcodes.Fstore3:
+		return fstore3
+	case opcodes.Dstore0:
+		return dstore0
+	case opcodes.Dstore1:
+		return dstore1
+	case opcodes.Dstore2:
+		return dstore2
+	case opcodes.Dstore3:
+		return dstore3
+	case opcodes.Astore0:
+		return astore0
+	case opcodes.Astore1:
+		return astore1
+	case opcodes.Astore2:
+		return astore2
+	case opcodes.Astore3:
+		return astore3
+	case opcodes.Iastore:
+		return iastore
+	case opcodes.Lastore:
+		return lastore
+	case opcodes.Fastore:
+		return fastore
+	case opcodes.Dastore:
+		return dastore
+	case opcodes.Aastore:
+		return aastore
+	case opcodes.Bastore:
+		return bastore
+	case opcodes.Castore:
+		return castore
+	case opcodes.Sastore:
+		return sastore
+	case opcodes.Pop:
+		return pop
+	case opcodes.Pop2:
+		return pop2
+	case opcodes.Dup:
+		return dup
+	case opcodes.DupX1:
+		return dupX1
+	case opcodes.DupX2:
+		return dupX2
+	case opcodes.Dup2:
+		return dup2
+	case opcodes.Dup2X1:
+		return dup2X1
+	case opcodes.Dup2X2:
+		return dup2X2
+	case opcodes.Swap:
+		return swap
+	case opcodes.Iadd:
+		return iadd
+	case opcodes.Ladd:
+		return ladd
+	case opcodes.Fadd:
+		return fadd
+	case opcodes.Dadd:
+		return dadd
+	case opcodes.Isub:
+		return isub
+	case opcodes.Lsub:
+		return lsub
+	case opcodes.Fsub:
+		return fsub
+	case opcodes.Dsub:
+		return dsub
+	case opcodes.Imul:
+		return imul
+	case opcodes.Lmul:
+		return lmul
+	case opcodes.Fmul:
+		return fmul
+	case opcodes.Dmul:
+		return dmul
+	case opcodes.Idiv:
+		return idiv
+	case opcodes.Ldiv:
+		return ldiv
+	case opcodes.Fdiv:
+		return fdiv
+	case opcodes.Ddiv:
+		return ddiv
+	case opcodes.Irem:
+		return irem
+	case opcodes.Lrem:
+		return lrem
+	case opcodes.Frem:
+		return frem
+	case opcodes.Drem:
+		return drem
+	case opcodes.Ineg:
+		return ineg
+	case opcodes.Lneg:
+		return lneg
+	case opcodes.Fneg:
+		return fneg
+	case opcodes.Dneg:
+		return dneg
+	case opcodes.Ishl:
+		return ishl
+	case opcodes.Lshl:
+		return lshl
+	case opcodes.Ishr:
+		return ishr
+	case opcodes.Lshr:
+		return lshr
+	case opcodes.Iushr:
+		return iushr
+	case opcodes.Lushr:
+		return lushr
+	case opcodes.Iand:
+		return iand
+	case opcodes.Land:
+		return land
+	case opcodes.Ior:
+		return ior
+	case opcodes.Lor:
+		return lor
+	case opcodes.Ixor:
+		return ixor
+	case opcodes.Lxor:
+		return lxor
+	case opcodes.Iinc:
+		return &math.IINC{}
+	case opcodes.I2l:
+		return i2l
+	case opcodes.I2f:
+		return i2f
+	case opcodes.I2d:
+		return i2d
+	case opcodes.I2b:
+		return i2b
+	case opcodes.I2c:
+		return i2c
+	case opcodes.I2s:
+		return i2s
+	case opcodes.L2i:
+		return l2i
+	case opcodes.L2f:
+		return l2f
+	case opcodes.L2d:
+		return l2d
+	case opcodes.F2i:
+		return f2i
+	case opcodes.F2l:
+		return f2l
+	case opcodes.F2d:
+		return f2d
+	case opcodes.D2i:
+		return d2i
+	case opcodes.D2l:
+		return d2l
+	case opcodes.D2f:
+		return d2f
+	case opcodes.Lcmp:
+		return lcmp
+	case opcodes.Fcmpl:
+		return fcmpl
+	case opcodes.Fcmpg:
+		return fcmpg
+	case opcodes.Dcmpl:
+		return dcmpl
+	case opcodes.Dcmpg:
+		return dcmpg
+	case opcodes.Ifeq:
+		return &comparisons.IFEQ{}
+	case opcodes.Ifne:
+		return &comparisons.IFNE{}
+	case opcodes.Iflt:
+		return &comparisons.IFLT{}
+	case opcodes.Ifge:
+		return &comparisons.IFGE{}
+	case opcodes.Ifgt:
+		return &comparisons.IFGT{}
+	case opcodes.Ifle:
+		return &comparisons.IFLE{}
+	case opcodes.IfIcmpeq:
+		return &comparisons.IF_ICMPEQ{}
+	case opcodes.IfIcmpne:
+		return &comparisons.IF_ICMPNE{}
+	case opcodes.IfIcmplt:
+		return &comparisons.IF_ICMPLT{}
+	case opcodes.IfIcmpge:
+		return &comparisons.IF_ICMPGE{}
+	case opcodes.IfIcmpgt:
+		return &comparisons.IF_ICMPGT{}
+	case opcodes.IfIcmple:
+		return &comparisons.IF_ICMPLE{}
+	case opcodes.IfAcmpeq:
+		return &comparisons.IF_ACMPEQ{}
+	case opcodes.IfAcmpne:
+		return &comparisons.IF_ACMPNE{}
+	case opcodes.Goto:
+		return &control.GOTO{}
+	case opcodes.Jsr:
+		return &control.JSR{}
+	case opcodes.Ret:
+		return &control.RET{}
+	case opcodes.Tableswitch:
+		return &control.TABLE_SWITCH{}
+	case opcodes.Lookupswitch:
+		return &control.LOOKUP_SWITCH{}
+	case opcodes.Ireturn:
+		return ireturn
+	case opcodes.Lreturn:
+		return lreturn
+	case opcodes.Freturn:
+		return freturn
+	case opcodes.Dreturn:
+		return dreturn
+	case opcodes.Areturn:
+		return areturn
+	case opcodes.Return:
+		return _return
+	case opcodes.Getstatic:
+		return &references.GET_STATIC{}
+	case opcodes.Putstatic:
+		return &references.PUT_STATIC{}
+	case opcodes.Getfield:
+		return &references.GET_FIELD{}
+	case opcodes.Putfield:
+		return &references.PUT_FIELD{}
+	case opcodes.Invokevirtual:
+		return &references.INVOKE_VIRTUAL{}
+	case opcodes.Invokespecial:
+		return &references.INVOKE_SPECIAL{}
+	case opcodes.Invokestatic:
+		return &references.INVOKE_STATIC{}
+	case opcodes.Invokeinterface:
+		return &references.INVOKE_INTERFACE{}
+	case opcodes.Invokedynamic:
+		return &references.INVOKE_DYNAMIC{}
+	case opcodes.New:
+		return &references.NEW{}
+	case opcodes.Newarray:
+		return &references.NEW_ARRAY{}
+	case opcodes.Anewarray:
+		return &references.ANEW_ARRAY{}
+	case opcodes.Arraylength:
+		return arrayLength
+	case opcodes.Athrow:
+		return athrow
+	case opcodes.Checkcast:
+		return &references.CHECK_CAST{}
+	case opcodes.Instanceof:
+		return &references.INSTANCE_OF{}
+	case opcodes.Monitorenter:
+		return &references.MONITOR_ENTER{}
+	case opcodes.Monitorexit:
+		return &references.MONITOR_EXIT{}
+	case opcodes.Multianewarray:
+		return &references.MULTI_ANEW_ARRAY{}
+	case opcodes.Wide:
+		return &extended.WIDE{}
+	case opcodes.Ifnull:
+		return &extended.IFNULL{}
+	case opcodes.Ifnonnull:
+		return &extended.IFNONNULL{}
+	case opcodes.GotoW:
+		return &extended.GOTO_W{}
+	case opcodes.JsrW:
+		return &extended.JSR_W{}
+	case opcodes.Impdep1:
+		return impdep1
+	default:
+		panic(fmt.Errorf("Unsupported opcode: 0x%x (%s)!", opcode, opcodes.Name(opcode)))
+	}
+}
@@ -0,0 +1,88 @@
+package loads
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Load int from array
+type IALOAD struct{ base.NoOperandsInstruction }
+
+func (iaload *IALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushInt(arr.Ints()[index])
+}
+
+// Load long from array
+type LALOAD struct{ base.NoOperandsInstruction }
+
+func (laload *LALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushLong(arr.Longs()[index])
+}
+
+// Load float from array
+type FALOAD struct{ base.NoOperandsInstruction }
+
+func (faload *FALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushFloat(arr.Floats()[index])
+}
+
+// Load double from array
+type DALOAD struct{ base.NoOperandsInstruction }
+
+func (daload *DALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushDouble(arr.Doubles()[index])
+}
+
+// Load reference from array
+type AALOAD struct{ base.NoOperandsInstruction }
+
+func (aaload *AALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushRef(arr.Refs()[index])
+}
+
+// Load byte or boolean from array
+type BALOAD struct{ base.NoOperandsInstruction }
+
+func (baload *BALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushInt(int32(arr.Bytes()[index]))
+}
+
+// Load char from array
+type CALOAD struct{ base.NoOperandsInstruction }
+
+func (caload *CALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushInt(int32(arr.Chars()[index]))
+}
+
+// Load short from array
+type SALOAD struct{ base.NoOperandsInstruction }
+
+func (saload *SALOAD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arr, index := popArrayAndIndex(stack)
+	stack.PushInt(int32(arr.Shorts()[index]))
+}
+
+// popArrayAndIndex 弹出下标和数组引用，检查null和下标越界
+func popArrayAndIndex(stack *rtda.OperandStack) (*heap.Object, int32) {
+	index := stack.PopInt()
+	arr := stack.PopRef()
+	base.CheckNotNil(arr)
+	base.CheckIndex(arr, index)
+	return arr, index
+}
@@ -0,0 +1,50 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Add int
+type IADD struct{ base.NoOperandsInstruction }
+
+func (iadd *IADD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	result := v1 + v2
+	stack.PushInt(result)
+}
+
+// Add long
+type LADD struct{ base.NoOperandsInstruction }
+
+func (ladd *LADD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	result := v1 + v2
+	stack.PushLong(result)
+}
+
+// Add float
+type FADD struct{ base.NoOperandsInstruction }
+
+func (fadd *FADD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopFloat()
+	v1 := stack.PopFloat()
+	result := v1 + v2
+	stack.PushFloat(result)
+}
+
+// Add double
+type DADD struct{ base.NoOperandsInstruction }
+
+func (dadd *DADD) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
+	result := v1 + v2
+	stack.PushDouble(result)
+}
@@ -0,0 +1,60 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Divide int
+// Integer.MIN_VALUE / -1 溢出，结果还是Integer.MIN_VALUE，Go的整数除法也是这样
+type IDIV struct{ base.NoOperandsInstruction }
+
+func (idiv *IDIV) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	if v2 == 0 {
+		panic("java.lang.ArithmeticException: / by zero")
+	}
+
+	result := v1 / v2
+	stack.PushInt(result)
+}
+
+// Divide long
+type LDIV struct{ base.NoOperandsInstruction }
+
+func (ldiv *LDIV) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	if v2 == 0 {
+		panic("java.lang.ArithmeticException: / by zero")
+	}
+
+	result := v1 / v2
+	stack.PushLong(result)
+}
+
+// Divide float
+// 浮点数除以0得到无穷大或NaN，不抛异常
+type FDIV struct{ base.NoOperandsInstruction }
+
+func (fdiv *FDIV) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopFloat()
+	v1 := stack.PopFloat()
+	result := v1 / v2
+	stack.PushFloat(result)
+}
+
+// Divide double
+type DDIV struct{ base.NoOperandsInstruction }
+
+func (ddiv *DDIV) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
+	result := v1 / v2
+	stack.PushDouble(result)
+}
@@ -0,0 +1,50 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Multiply int
+type IMUL struct{ base.NoOperandsInstruction }
+
+func (imul *IMUL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	result := v1 * v2
+	stack.PushInt(result)
+}
+
+// Multiply long
+type LMUL struct{ base.NoOperandsInstruction }
+
+func (lmul *LMUL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	result := v1 * v2
+	stack.PushLong(result)
+}
+
+// Multiply float
+type FMUL struct{ base.NoOperandsInstruction }
+
+func (fmul *FMUL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopFloat()
+	v1 := stack.PopFloat()
+	result := v1 * v2
+	stack.PushFloat(result)
+}
+
+// Multiply double
+type DMUL struct{ base.NoOperandsInstruction }
+
+func (dmul *DMUL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
+	result := v1 * v2
+	stack.PushDouble(result)
+}
@@ -0,0 +1,43 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Negate int
+type INEG struct{ base.NoOperandsInstruction }
+
+func (ineg *INEG) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopInt()
+	stack.PushInt(-val)
+}
+
+// Negate long
+type LNEG struct{ base.NoOperandsInstruction }
+
+func (lneg *LNEG) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopLong()
+	stack.PushLong(-val)
+}
+
+// Negate float
+// 取反只翻转符号位，0.0取反得到-0.0，NaN还是NaN
+type FNEG struct{ base.NoOperandsInstruction }
+
+func (fneg *FNEG) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopFloat()
+	stack.PushFloat(-val)
+}
+
+// Negate double
+type DNEG struct{ base.NoOperandsInstruction }
+
+func (dneg *DNEG) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopDouble()
+	stack.PushDouble(-val)
+}
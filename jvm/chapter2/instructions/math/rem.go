@@ -0,0 +1,62 @@
+package math
+
+import (
+	"math"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Remainder int
+type IREM struct{ base.NoOperandsInstruction }
+
+func (irem *IREM) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	if v2 == 0 {
+		panic("java.lang.ArithmeticException: / by zero")
+	}
+
+	result := v1 % v2
+	stack.PushInt(result)
+}
+
+// Remainder long
+type LREM struct{ base.NoOperandsInstruction }
+
+func (lrem *LREM) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	if v2 == 0 {
+		panic("java.lang.ArithmeticException: / by zero")
+	}
+
+	result := v1 % v2
+	stack.PushLong(result)
+}
+
+// Remainder float
+// Java的frem和C的fmod一样，结果的符号和被除数相同，math.Mod也是如此；
+// float的余数在double里算是精确的，再转回float不会有误差
+type FREM struct{ base.NoOperandsInstruction }
+
+func (frem *FREM) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopFloat()
+	v1 := stack.PopFloat()
+	result := float32(math.Mod(float64(v1), float64(v2)))
+	stack.PushFloat(result)
+}
+
+// Remainder double
+type DREM struct{ base.NoOperandsInstruction }
+
+func (drem *DREM) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
+	result := math.Mod(v1, v2)
+	stack.PushDouble(result)
+}
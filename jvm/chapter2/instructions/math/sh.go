@@ -0,0 +1,80 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// 移位的位数只取低5位（int）或低6位（long）
+
+// Shift left int
+type ISHL struct{ base.NoOperandsInstruction }
+
+func (ishl *ISHL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	s := uint32(v2) & 0x1f
+	result := v1 << s
+	stack.PushInt(result)
+}
+
+// Arithmetic shift right int
+type ISHR struct{ base.NoOperandsInstruction }
+
+func (ishr *ISHR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	s := uint32(v2) & 0x1f
+	result := v1 >> s
+	stack.PushInt(result)
+}
+
+// Logical shift right int
+type IUSHR struct{ base.NoOperandsInstruction }
+
+func (iushr *IUSHR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	s := uint32(v2) & 0x1f
+	result := int32(uint32(v1) >> s)
+	stack.PushInt(result)
+}
+
+// Shift left long
+type LSHL struct{ base.NoOperandsInstruction }
+
+func (lshl *LSHL) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopLong()
+	s := uint32(v2) & 0x3f
+	result := v1 << s
+	stack.PushLong(result)
+}
+
+// Arithmetic shift right long
+type LSHR struct{ base.NoOperandsInstruction }
+
+func (lshr *LSHR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopLong()
+	s := uint32(v2) & 0x3f
+	result := v1 >> s
+	stack.PushLong(result)
+}
+
+// Logical shift right long
+type LUSHR struct{ base.NoOperandsInstruction }
+
+func (lushr *LUSHR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopLong()
+	s := uint32(v2) & 0x3f
+	result := int64(uint64(v1) >> s)
+	stack.PushLong(result)
+}
@@ -0,0 +1,28 @@
+package math
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Boolean XOR int
+type IXOR struct{ base.NoOperandsInstruction }
+
+func (ixor *IXOR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopInt()
+	v1 := stack.PopInt()
+	result := v1 ^ v2
+	stack.PushInt(result)
+}
+
+// Boolean XOR long
+type LXOR struct{ base.NoOperandsInstruction }
+
+func (lxor *LXOR) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	v2 := stack.PopLong()
+	v1 := stack.PopLong()
+	result := v1 ^ v2
+	stack.PushLong(result)
+}
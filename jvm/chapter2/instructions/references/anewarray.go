@@ -0,0 +1,19 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Create new array of reference
+type ANEW_ARRAY struct{ base.Index16Instruction }
+
+func (anewArray *ANEW_ARRAY) Execute(frame *rtda.Frame) {
+	cp := frame.Method().Class().ConstantPool()
+	classRef := cp.GetConstant(anewArray.Index).(*heap.ClassRef)
+	componentClass := classRef.ResolvedClass(frame.Thread())
+	stack := frame.OperandStack()
+	count := popCount(stack)
+	stack.PushRef(componentClass.ArrayClass(frame.Thread()).NewArray(count))
+}
@@ -0,0 +1,25 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Get length of array
+type ARRAY_LENGTH struct{ base.NoOperandsInstruction }
+
+func (arrayLength *ARRAY_LENGTH) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	arrRef := popObject(stack)
+	stack.PushInt(arrRef.ArrayLength())
+}
+
+// popObject 弹出对象引用，引用是null时抛出NullPointerException
+func popObject(stack *rtda.OperandStack) *heap.Object {
+	ref := stack.PopRef()
+	if ref == nil {
+		panic("java.lang.NullPointerException")
+	}
+	return ref
+}
@@ -0,0 +1,18 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Throw exception or error
+type ATHROW struct{ base.NoOperandsInstruction }
+
+// Execute 异常对象由解释器recover，再按异常表找处理器，见base.HandleException
+func (athrow *ATHROW) Execute(frame *rtda.Frame) {
+	ex := frame.OperandStack().PopRef()
+	if ex == nil {
+		panic("java.lang.NullPointerException")
+	}
+	panic(ex)
+}
@@ -0,0 +1,26 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Check whether object is of given type
+type CHECK_CAST struct{ base.Index16Instruction }
+
+func (checkCast *CHECK_CAST) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	ref := stack.PopRef()
+	stack.PushRef(ref)
+	if ref == nil {
+		return // null可以转换成任何引用类型
+	}
+
+	cp := frame.Method().Class().ConstantPool()
+	class := cp.GetConstant(checkCast.Index).(*heap.ClassRef).ResolvedClass(frame.Thread())
+	if !ref.IsInstanceOf(class) {
+		panic("java.lang.ClassCastException: " + ref.Class().JavaName() +
+			" cannot be cast to " + class.JavaName())
+	}
+}
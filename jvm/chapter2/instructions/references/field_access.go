@@ -0,0 +1,85 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// getfield、putfield、getstatic和putstatic共用：按字段的类型读写slots里的变量。
+// volatile字段用原子操作读写，见heap.Slots
+
+// pushField 读出字段的值压入操作数栈
+func pushField(stack *rtda.OperandStack, slots heap.Slots, field *heap.Field) {
+	slotId := field.SlotId()
+	if field.IsVolatile() {
+		switch field.Descriptor()[0] {
+		case 'Z', 'B', 'C', 'S', 'I':
+			stack.PushInt(slots.GetIntVolatile(slotId))
+		case 'F':
+			stack.PushFloat(slots.GetFloatVolatile(slotId))
+		case 'J':
+			stack.PushLong(slots.GetLongVolatile(slotId))
+		case 'D':
+			stack.PushDouble(slots.GetDoubleVolatile(slotId))
+		case 'L', '[':
+			stack.PushRef(slots.GetRefVolatile(slotId))
+		}
+		return
+	}
+	switch field.Descriptor()[0] {
+	case 'Z', 'B', 'C', 'S', 'I':
+		stack.PushInt(slots.GetInt(slotId))
+	case 'F':
+		stack.PushFloat(slots.GetFloat(slotId))
+	case 'J':
+		stack.PushLong(slots.GetLong(slotId))
+	case 'D':
+		stack.PushDouble(slots.GetDouble(slotId))
+	case 'L', '[':
+		stack.PushRef(slots.GetRef(slotId))
+	}
+}
+
+// popField 弹出值存入字段，boolean只保留最低位
+func popField(stack *rtda.OperandStack, slots heap.Slots, field *heap.Field) {
+	slotId := field.SlotId()
+	if field.IsVolatile() {
+		switch field.Descriptor()[0] {
+		case 'Z':
+			slots.SetIntVolatile(slotId, stack.PopInt()&1)
+		case 'B', 'C', 'S', 'I':
+			slots.SetIntVolatile(slotId, stack.PopInt())
+		case 'F':
+			slots.SetFloatVolatile(slotId, stack.PopFloat())
+		case 'J':
+			slots.SetLongVolatile(slotId, stack.PopLong())
+		case 'D':
+			slots.SetDoubleVolatile(slotId, stack.PopDouble())
+		case 'L', '[':
+			slots.SetRefVolatile(slotId, stack.PopRef())
+		}
+		return
+	}
+	switch field.Descriptor()[0] {
+	case 'Z':
+		slots.SetInt(slotId, stack.PopInt()&1)
+	case 'B', 'C', 'S', 'I':
+		slots.SetInt(slotId, stack.PopInt())
+	case 'F':
+		slots.SetFloat(slotId, stack.PopFloat())
+	case 'J':
+		slots.SetLong(slotId, stack.PopLong())
+	case 'D':
+		slots.SetDouble(slotId, stack.PopDouble())
+	case 'L', '[':
+		slots.SetRef(slotId, stack.PopRef())
+	}
+}
+
+// fieldSlotCount 字段的值在操作数栈上占的槽位数
+func fieldSlotCount(field *heap.Field) uint {
+	if d := field.Descriptor(); d == "J" || d == "D" {
+		return 2
+	}
+	return 1
+}
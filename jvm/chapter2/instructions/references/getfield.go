@@ -0,0 +1,32 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Fetch field from object
+type GET_FIELD struct{ base.Index16Instruction }
+
+// Execute 读final字段之前和构造函数末尾的冻结配对，见heap.Object.Freeze
+func (getField *GET_FIELD) Execute(frame *rtda.Frame) {
+	field := resolveInstanceField(frame, getField.Index)
+	stack := frame.OperandStack()
+	object := popObject(stack)
+	if field.IsFinal() {
+		object.AcquireFinals()
+	}
+	pushField(stack, object.Fields(), field)
+}
+
+// resolveInstanceField getfield和putfield共用：解析字段引用，字段不能是静态的
+func resolveInstanceField(frame *rtda.Frame, index uint) *heap.Field {
+	cp := frame.Method().Class().ConstantPool()
+	field := cp.GetConstant(index).(*heap.FieldRef).ResolvedField(frame.Thread())
+	if field.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expected non-static field " +
+			field.Class().JavaName() + "." + field.Name())
+	}
+	return field
+}
@@ -0,0 +1,28 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Get static field from class
+type GET_STATIC struct{ base.Index16Instruction }
+
+func (getStatic *GET_STATIC) Execute(frame *rtda.Frame) {
+	field := resolveStaticField(frame, getStatic.Index)
+	class := field.Class()
+	base.InitClass(frame.Thread(), class)
+	pushField(frame.OperandStack(), class.StaticVars(), field)
+}
+
+// resolveStaticField getstatic和putstatic共用：解析字段引用，字段必须是静态的
+func resolveStaticField(frame *rtda.Frame, index uint) *heap.Field {
+	cp := frame.Method().Class().ConstantPool()
+	field := cp.GetConstant(index).(*heap.FieldRef).ResolvedField(frame.Thread())
+	if !field.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expected static field " +
+			field.Class().JavaName() + "." + field.Name())
+	}
+	return field
+}
@@ -0,0 +1,27 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Determine if object is of given type
+type INSTANCE_OF struct{ base.Index16Instruction }
+
+func (instanceOf *INSTANCE_OF) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	ref := stack.PopRef()
+	if ref == nil {
+		stack.PushInt(0)
+		return
+	}
+
+	cp := frame.Method().Class().ConstantPool()
+	class := cp.GetConstant(instanceOf.Index).(*heap.ClassRef).ResolvedClass(frame.Thread())
+	if ref.IsInstanceOf(class) {
+		stack.PushInt(1)
+	} else {
+		stack.PushInt(0)
+	}
+}
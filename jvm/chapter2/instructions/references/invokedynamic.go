@@ -0,0 +1,74 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Invoke a dynamically-computed call site
+type INVOKE_DYNAMIC struct {
+	index uint
+	// zero uint8
+	// zero uint8
+}
+
+func (invokeDynamic *INVOKE_DYNAMIC) FetchOperands(reader *base.BytecodeReader) {
+	invokeDynamic.index = uint(reader.ReadUint16())
+	reader.ReadUint8() // 0
+	reader.ReadUint8() // 0
+}
+
+// Execute 每条invokedynamic指令是一个单独的调用点，即使它们引用同一个常量，链接好的目标记在方法里。
+// 执行引导方法时不持有锁，引导方法可能要等待别的线程初始化类；链接失败的调用点以后每次都抛出同样的异常
+func (invokeDynamic *INVOKE_DYNAMIC) Execute(frame *rtda.Frame) {
+	method, pc := frame.Method(), frame.Thread().PC()
+	target, ok := method.CallSite(pc).(native.CallSiteTarget)
+	if !ok {
+		target = method.LinkCallSite(pc, linkCallSite(frame, invokeDynamic.index)).(native.CallSiteTarget)
+	}
+	target(frame)
+}
+
+// linkCallSite 按JVMS 5.4.3.6解析调用点限定符：解析引导方法句柄，执行引导方法得到调用点的目标。
+// 引导方法抛出的不是Error的异常包装成BootstrapMethodError
+func linkCallSite(frame *rtda.Frame, index uint) (target native.CallSiteTarget) {
+	thread := frame.Thread()
+	defer func() {
+		if r := recover(); r != nil {
+			err := bootstrapMethodError(thread, r)
+			target = func(*rtda.Frame) { panic(err) }
+		}
+	}()
+	callSite, ok := frame.Method().Class().ConstantPool().GetConstant(index).(*heap.InvokeDynamicRef)
+	if !ok {
+		panic("java.lang.VerifyError: invokedynamic of a non-InvokeDynamic constant")
+	}
+	handle, _ := callSite.BootstrapMethod()
+	method := handle.ResolvedMethod(thread)
+	bootstrap := native.FindBootstrap(method.Class().Name(), method.Name(), method.Descriptor())
+	if bootstrap == nil {
+		panic("java.lang.BootstrapMethodError: bootstrap method " + method.String() + " is not supported")
+	}
+	return bootstrap(frame, callSite)
+}
+
+func bootstrapMethodError(thread *rtda.Thread, r interface{}) (err interface{}) {
+	ex := base.ToThrowable(thread, r)
+	if ex == nil {
+		return r
+	}
+	if base.IsError(ex) {
+		return ex
+	}
+	defer func() {
+		if recover() != nil {
+			err = ex // 类库里没有BootstrapMethodError(String, Throwable)时抛出原来的异常
+		}
+	}()
+	bme := ex.Class().Loader().Bootstrap().LoadClass(thread, "java/lang/BootstrapMethodError")
+	base.InitClass(thread, bme)
+	return base.NewObject(thread, bme, "(Ljava/lang/String;Ljava/lang/Throwable;)V",
+		heap.JString(thread, bme.Loader(), "call site initialization exception"), ex)
+}
@@ -0,0 +1,45 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Invoke interface method
+type INVOKE_INTERFACE struct {
+	index uint
+	// count uint8
+	// zero uint8
+}
+
+func (invokeInterface *INVOKE_INTERFACE) FetchOperands(reader *base.BytecodeReader) {
+	invokeInterface.index = uint(reader.ReadUint16())
+	reader.ReadUint8() // count，参数占用的槽位数，可以从描述符算出来
+	reader.ReadUint8() // 0
+}
+
+func (invokeInterface *INVOKE_INTERFACE) Execute(frame *rtda.Frame) {
+	cp := frame.Method().Class().ConstantPool()
+	methodRef, ok := cp.GetConstant(invokeInterface.index).(*heap.InterfaceMethodRef)
+	if !ok {
+		panic("java.lang.VerifyError: invokeinterface of a non-interface-method constant")
+	}
+	resolvedMethod := methodRef.ResolvedInterfaceMethod(frame.Thread())
+	if resolvedMethod.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expected instance not static method " + resolvedMethod.String())
+	}
+
+	ref := frame.OperandStack().GetRefFromTop(resolvedMethod.ArgSlotCount() - 1)
+	if ref == nil {
+		panic("java.lang.NullPointerException")
+	}
+	iface := methodRef.ResolvedClass(frame.Thread())
+	if !ref.Class().IsImplements(iface) {
+		panic("java.lang.IncompatibleClassChangeError: Class " + ref.Class().JavaName() +
+			" does not implement the requested interface " + iface.JavaName())
+	}
+
+	method := ref.Class().SelectMethod(resolvedMethod)
+	base.InvokeMethod(frame, method)
+}
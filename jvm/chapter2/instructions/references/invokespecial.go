@@ -0,0 +1,42 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Invoke instance method; special handling for superclass, private, and instance initialization method invocations
+type INVOKE_SPECIAL struct{ base.Index16Instruction }
+
+func (invokeSpecial *INVOKE_SPECIAL) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	currentClass := frame.Method().Class()
+	cp := currentClass.ConstantPool()
+	var refClass *heap.Class
+	var resolvedMethod *heap.Method
+	switch ref := cp.GetConstant(invokeSpecial.Index).(type) {
+	case *heap.MethodRef:
+		refClass, resolvedMethod = ref.ResolvedClass(thread), ref.ResolvedMethod(thread)
+	case *heap.InterfaceMethodRef: // 调用超接口的默认方法，class文件版本52以后才有
+		refClass, resolvedMethod = ref.ResolvedClass(thread), ref.ResolvedInterfaceMethod(thread)
+	default:
+		panic("java.lang.VerifyError: invokespecial of a non-method constant")
+	}
+	// 构造函数只在引用的类自己声明的方法里找
+	if resolvedMethod.Name() == "<init>" && resolvedMethod.Class() != refClass {
+		panic("java.lang.NoSuchMethodError: " + refClass.JavaName() + ".<init>" + resolvedMethod.Descriptor())
+	}
+	if resolvedMethod.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expecting non-static method " + resolvedMethod.String())
+	}
+
+	ref := frame.OperandStack().GetRefFromTop(resolvedMethod.ArgSlotCount() - 1)
+	if ref == nil {
+		panic("java.lang.NullPointerException")
+	}
+	checkProtectedAccess(currentClass, resolvedMethod, ref)
+
+	method := heap.SelectSpecialMethod(currentClass, refClass, resolvedMethod)
+	base.InvokeMethod(frame, method)
+}
@@ -0,0 +1,29 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Invoke a class (static) method
+type INVOKE_STATIC struct{ base.Index16Instruction }
+
+func (invokeStatic *INVOKE_STATIC) Execute(frame *rtda.Frame) {
+	thread := frame.Thread()
+	cp := frame.Method().Class().ConstantPool()
+	var method *heap.Method
+	switch ref := cp.GetConstant(invokeStatic.Index).(type) {
+	case *heap.MethodRef:
+		method = ref.ResolvedMethod(thread)
+	case *heap.InterfaceMethodRef: // 接口的静态方法，class文件版本52以后才有
+		method = ref.ResolvedInterfaceMethod(thread)
+	default:
+		panic("java.lang.VerifyError: invokestatic of a non-method constant")
+	}
+	if !method.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expected static method " + method.String())
+	}
+	base.InitClass(thread, method.Class())
+	base.InvokeMethod(frame, method)
+}
@@ -0,0 +1,47 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Invoke instance method; dispatch based on class
+type INVOKE_VIRTUAL struct{ base.Index16Instruction }
+
+func (invokeVirtual *INVOKE_VIRTUAL) Execute(frame *rtda.Frame) {
+	currentClass := frame.Method().Class()
+	cp := currentClass.ConstantPool()
+	resolvedMethod := cp.GetConstant(invokeVirtual.Index).(*heap.MethodRef).ResolvedMethod(frame.Thread())
+	if resolvedMethod.IsStatic() {
+		panic("java.lang.IncompatibleClassChangeError: Expecting non-static method " + resolvedMethod.String())
+	}
+
+	ref := frame.OperandStack().GetRefFromTop(resolvedMethod.ArgSlotCount() - 1)
+	if ref == nil {
+		panic("java.lang.NullPointerException")
+	}
+	checkProtectedAccess(currentClass, resolvedMethod, ref)
+
+	method := ref.Class().SelectMethod(resolvedMethod)
+	base.InvokeMethod(frame, method)
+}
+
+// checkProtectedAccess 调用其他包里超类的protected方法时，对象必须是当前类或者它的子类的实例，
+// 数组的clone方法除外。匿名类按宿主类检查
+func checkProtectedAccess(currentClass *heap.Class, method *heap.Method, ref *heap.Object) {
+	currentClass = currentClass.HostClass()
+	declaringClass := method.Class()
+	if !method.IsProtected() || !currentClass.IsSubClassOf(declaringClass) ||
+		(declaringClass.Loader() == currentClass.Loader() && declaringClass.PackageName() == currentClass.PackageName()) {
+		return
+	}
+	refClass := ref.Class()
+	if refClass.IsArray() && method.Name() == "clone" {
+		return
+	}
+	if refClass != currentClass && !refClass.IsSubClassOf(currentClass) {
+		panic("java.lang.IllegalAccessError: tried to access method " + method.String() +
+			" from class " + currentClass.JavaName())
+	}
+}
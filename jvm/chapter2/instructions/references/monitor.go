@@ -0,0 +1,22 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Enter monitor for object
+type MONITOR_ENTER struct{ base.NoOperandsInstruction }
+
+// Execute 别的线程持有监视器时阻塞；进入的监视器记在帧上，方法因为异常结束时释放
+func (monitorEnter *MONITOR_ENTER) Execute(frame *rtda.Frame) {
+	frame.Lock(popObject(frame.OperandStack()))
+}
+
+// Exit monitor for object
+type MONITOR_EXIT struct{ base.NoOperandsInstruction }
+
+// Execute 当前线程不是持有者时抛出IllegalMonitorStateException
+func (monitorExit *MONITOR_EXIT) Execute(frame *rtda.Frame) {
+	frame.Unlock(popObject(frame.OperandStack()))
+}
@@ -0,0 +1,32 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Create new multidimensional array
+type MULTI_ANEW_ARRAY struct {
+	index      uint16
+	dimensions uint8
+}
+
+func (multiAnewArray *MULTI_ANEW_ARRAY) FetchOperands(reader *base.BytecodeReader) {
+	multiAnewArray.index = reader.ReadUint16()
+	multiAnewArray.dimensions = reader.ReadUint8()
+}
+
+func (multiAnewArray *MULTI_ANEW_ARRAY) Execute(frame *rtda.Frame) {
+	cp := frame.Method().Class().ConstantPool()
+	classRef := cp.GetConstant(uint(multiAnewArray.index)).(*heap.ClassRef)
+	arrClass := classRef.ResolvedClass(frame.Thread())
+
+	// 先弹出所有维的长度，任何一维是负数都不创建数组
+	stack := frame.OperandStack()
+	counts := make([]int32, multiAnewArray.dimensions)
+	for i := len(counts) - 1; i >= 0; i-- {
+		counts[i] = stack.PopInt()
+	}
+	stack.PushRef(arrClass.NewMultiArray(counts))
+}
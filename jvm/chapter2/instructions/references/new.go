@@ -0,0 +1,21 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Create new object
+type NEW struct{ base.Index16Instruction }
+
+func (_new *NEW) Execute(frame *rtda.Frame) {
+	cp := frame.Method().Class().ConstantPool()
+	classRef := cp.GetConstant(_new.Index).(*heap.ClassRef)
+	class := classRef.ResolvedClass(frame.Thread())
+	if class.IsInterface() || class.IsAbstract() {
+		panic("java.lang.InstantiationError: " + class.JavaName())
+	}
+	base.InitClass(frame.Thread(), class)
+	frame.OperandStack().PushRef(class.NewObject())
+}
@@ -0,0 +1,39 @@
+package references
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Create new array of primitive type
+type NEW_ARRAY struct {
+	atype uint8
+}
+
+func (newArray *NEW_ARRAY) FetchOperands(reader *base.BytecodeReader) {
+	newArray.atype = reader.ReadUint8()
+}
+
+func (newArray *NEW_ARRAY) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	count := popCount(stack)
+	typeName := opcodes.ArrayTypeName(int(newArray.atype))
+	if typeName == "" {
+		panic(fmt.Sprintf("java.lang.VerifyError: Bad array type %d", newArray.atype))
+	}
+	loader := frame.Method().Class().Loader()
+	arrClass := loader.PrimitiveClass(typeName).ArrayClass(frame.Thread())
+	stack.PushRef(arrClass.NewArray(count))
+}
+
+// popCount 弹出数组长度，长度是负数时抛出NegativeArraySizeException
+func popCount(stack *rtda.OperandStack) uint {
+	count := stack.PopInt()
+	if count < 0 {
+		panic("java.lang.NegativeArraySizeException")
+	}
+	return uint(count)
+}
@@ -0,0 +1,37 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Set field in object
+type PUT_FIELD struct{ base.Index16Instruction }
+
+func (putField *PUT_FIELD) Execute(frame *rtda.Frame) {
+	currentMethod := frame.Method()
+	field := resolveInstanceField(frame, putField.Index)
+	class := field.Class()
+	if field.IsFinal() {
+		// final实例变量只能在声明它的类的<init>里赋值
+		fieldName := class.JavaName() + "." + field.Name()
+		if currentMethod.Class() != class {
+			panic("java.lang.IllegalAccessError: Update to non-static final field " + fieldName +
+				" attempted from a different class (" + currentMethod.Class().JavaName() +
+				") than the field's declaring class")
+		}
+		if currentMethod.Name() != "<init>" {
+			panic("java.lang.IllegalAccessError: Update to non-static final field " + fieldName +
+				" attempted from a different method (" + currentMethod.Name() +
+				") than the initializer method <init> ")
+		}
+	}
+
+	stack := frame.OperandStack()
+	object := stack.GetRefFromTop(fieldSlotCount(field))
+	if object == nil {
+		panic("java.lang.NullPointerException")
+	}
+	popField(stack, object.Fields(), field)
+	stack.PopRef()
+}
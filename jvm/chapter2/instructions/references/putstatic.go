@@ -0,0 +1,31 @@
+package references
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Set static field in class
+type PUT_STATIC struct{ base.Index16Instruction }
+
+func (putStatic *PUT_STATIC) Execute(frame *rtda.Frame) {
+	currentMethod := frame.Method()
+	field := resolveStaticField(frame, putStatic.Index)
+	class := field.Class()
+	if field.IsFinal() {
+		// final类变量只能在声明它的类的<clinit>里赋值
+		fieldName := class.JavaName() + "." + field.Name()
+		if currentMethod.Class() != class {
+			panic("java.lang.IllegalAccessError: Update to static final field " + fieldName +
+				" attempted from a different class (" + currentMethod.Class().JavaName() +
+				") than the field's declaring class")
+		}
+		if currentMethod.Name() != "<clinit>" {
+			panic("java.lang.IllegalAccessError: Update to static final field " + fieldName +
+				" attempted from a different method (" + currentMethod.Name() +
+				") than the initializer method <clinit> ")
+		}
+	}
+	base.InitClass(frame.Thread(), class)
+	popField(frame.OperandStack(), class.StaticVars(), field)
+}
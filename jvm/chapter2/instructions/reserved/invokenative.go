@@ -0,0 +1,28 @@
+package reserved
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	_ "go.buppt.cn/jvm/chapter2/native/java/io"
+	_ "go.buppt.cn/jvm/chapter2/native/java/lang"
+	_ "go.buppt.cn/jvm/chapter2/native/java/lang/invoke"
+	_ "go.buppt.cn/jvm/chapter2/native/java/lang/reflect"
+	_ "go.buppt.cn/jvm/chapter2/native/java/security"
+	_ "go.buppt.cn/jvm/chapter2/native/java/util/concurrent/atomic"
+	_ "go.buppt.cn/jvm/chapter2/native/sun/management"
+	_ "go.buppt.cn/jvm/chapter2/native/sun/misc"
+	_ "go.buppt.cn/jvm/chapter2/native/sun/reflect"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Invoke native method，用保留的操作码impdep1实现，只出现在虚拟机给本地方法生成的代码里
+type INVOKE_NATIVE struct{ base.NoOperandsInstruction }
+
+func (invokeNative *INVOKE_NATIVE) Execute(frame *rtda.Frame) {
+	method := frame.Method()
+	nativeMethod := native.FindNativeMethod(method.Class().Name(), method.Name(), method.Descriptor())
+	if nativeMethod == nil {
+		panic("java.lang.UnsatisfiedLinkError: " + method.String())
+	}
+	nativeMethod(frame)
+}
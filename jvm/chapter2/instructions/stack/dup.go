@@ -0,0 +1,95 @@
+package stack
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// dup系列指令只按槽位复制，不关心值的类型；下面的注释里栈顶在右边
+
+// Duplicate the top operand stack value
+type DUP struct{ base.NoOperandsInstruction }
+
+// ..., v1 -> ..., v1, v1
+func (dup *DUP) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot := stack.PopSlot()
+	stack.PushSlot(slot)
+	stack.PushSlot(slot)
+}
+
+// Duplicate the top operand stack value and insert two values down
+type DUP_X1 struct{ base.NoOperandsInstruction }
+
+// ..., v2, v1 -> ..., v1, v2, v1
+func (dupX1 *DUP_X1) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot1 := stack.PopSlot()
+	slot2 := stack.PopSlot()
+	stack.PushSlot(slot1)
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+}
+
+// Duplicate the top operand stack value and insert two or three values down
+type DUP_X2 struct{ base.NoOperandsInstruction }
+
+// ..., v3, v2, v1 -> ..., v1, v3, v2, v1
+func (dupX2 *DUP_X2) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot1 := stack.PopSlot()
+	slot2 := stack.PopSlot()
+	slot3 := stack.PopSlot()
+	stack.PushSlot(slot1)
+	stack.PushSlot(slot3)
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+}
+
+// Duplicate the top one or two operand stack values
+type DUP2 struct{ base.NoOperandsInstruction }
+
+// ..., v2, v1 -> ..., v2, v1, v2, v1
+func (dup2 *DUP2) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot1 := stack.PopSlot()
+	slot2 := stack.PopSlot()
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+}
+
+// Duplicate the top one or two operand stack values and insert two or three values down
+type DUP2_X1 struct{ base.NoOperandsInstruction }
+
+// ..., v3, v2, v1 -> ..., v2, v1, v3, v2, v1
+func (dup2X1 *DUP2_X1) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot1 := stack.PopSlot()
+	slot2 := stack.PopSlot()
+	slot3 := stack.PopSlot()
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+	stack.PushSlot(slot3)
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+}
+
+// Duplicate the top one or two operand stack values and insert two, three, or four values down
+type DUP2_X2 struct{ base.NoOperandsInstruction }
+
+// ..., v4, v3, v2, v1 -> ..., v2, v1, v4, v3, v2, v1
+func (dup2X2 *DUP2_X2) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	slot1 := stack.PopSlot()
+	slot2 := stack.PopSlot()
+	slot3 := stack.PopSlot()
+	slot4 := stack.PopSlot()
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+	stack.PushSlot(slot4)
+	stack.PushSlot(slot3)
+	stack.PushSlot(slot2)
+	stack.PushSlot(slot1)
+}
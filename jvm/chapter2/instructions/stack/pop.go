@@ -0,0 +1,24 @@
+package stack
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Pop the top operand stack value
+type POP struct{ base.NoOperandsInstruction }
+
+func (pop *POP) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	stack.PopSlot()
+}
+
+// Pop the top one or two operand stack values
+// long和double占两个槽位，pop2弹出一个long/double或者两个单槽位的值
+type POP2 struct{ base.NoOperandsInstruction }
+
+func (pop2 *POP2) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	stack.PopSlot()
+	stack.PopSlot()
+}
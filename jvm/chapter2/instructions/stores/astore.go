@@ -0,0 +1,43 @@
+package stores
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Store reference into local variable
+type ASTORE struct{ base.Index8Instruction }
+
+func (astore *ASTORE) Execute(frame *rtda.Frame) {
+	_astore(frame, astore.Index)
+}
+
+type ASTORE_0 struct{ base.NoOperandsInstruction }
+
+func (astore0 *ASTORE_0) Execute(frame *rtda.Frame) {
+	_astore(frame, 0)
+}
+
+type ASTORE_1 struct{ base.NoOperandsInstruction }
+
+func (astore1 *ASTORE_1) Execute(frame *rtda.Frame) {
+	_astore(frame, 1)
+}
+
+type ASTORE_2 struct{ base.NoOperandsInstruction }
+
+func (astore2 *ASTORE_2) Execute(frame *rtda.Frame) {
+	_astore(frame, 2)
+}
+
+type ASTORE_3 struct{ base.NoOperandsInstruction }
+
+func (astore3 *ASTORE_3) Execute(frame *rtda.Frame) {
+	_astore(frame, 3)
+}
+
+// astore也用来保存jsr压入的returnAddress，所以按槽位整个复制
+func _astore(frame *rtda.Frame, index uint) {
+	slot := frame.OperandStack().PopSlot()
+	frame.LocalVars().SetSlot(index, slot)
+}
@@ -0,0 +1,42 @@
+package stores
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+// Store double into local variable
+type DSTORE struct{ base.Index8Instruction }
+
+func (dstore *DSTORE) Execute(frame *rtda.Frame) {
+	_dstore(frame, dstore.Index)
+}
+
+type DSTORE_0 struct{ base.NoOperandsInstruction }
+
+func (dstore0 *DSTORE_0) Execute(frame *rtda.Frame) {
+	_dstore(frame, 0)
+}
+
+type DSTORE_1 struct{ base.NoOperandsInstruction }
+
+func (dstore1 *DSTORE_1) Execute(frame *rtda.Frame) {
+	_dstore(frame, 1)
+}
+
+type DSTORE_2 struct{ base.NoOperandsInstruction }
+
+func (dstore2 *DSTORE_2) Execute(frame *rtda.Frame) {
+	_dstore(frame, 2)
+}
+
+type DSTORE_3 struct{ base.NoOperandsInstruction }
+
+func (dstore3 *DSTORE_3) Execute(frame *rtda.Frame) {
+	_dstore(frame, 3)
+}
+
+func _dstore(frame *rtda.Frame, index uint) {
+	val := frame.OperandStack().PopDouble()
+	frame.LocalVars().SetDouble(index, val)
+}
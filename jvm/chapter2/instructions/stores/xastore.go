@@ -0,0 +1,102 @@
+package stores
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// Store into int array
+type IASTORE struct{ base.NoOperandsInstruction }
+
+func (iastore *IASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopInt()
+	arr, index := popArrayAndIndex(stack)
+	arr.Ints()[index] = val
+}
+
+// Store into long array
+type LASTORE struct{ base.NoOperandsInstruction }
+
+func (lastore *LASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopLong()
+	arr, index := popArrayAndIndex(stack)
+	arr.Longs()[index] = val
+}
+
+// Store into float array
+type FASTORE struct{ base.NoOperandsInstruction }
+
+func (fastore *FASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopFloat()
+	arr, index := popArrayAndIndex(stack)
+	arr.Floats()[index] = val
+}
+
+// Store into double array
+type DASTORE struct{ base.NoOperandsInstruction }
+
+func (dastore *DASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopDouble()
+	arr, index := popArrayAndIndex(stack)
+	arr.Doubles()[index] = val
+}
+
+// Store into char array
+type CASTORE struct{ base.NoOperandsInstruction }
+
+func (castore *CASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopInt()
+	arr, index := popArrayAndIndex(stack)
+	arr.Chars()[index] = uint16(val)
+}
+
+// Store into short array
+type SASTORE struct{ base.NoOperandsInstruction }
+
+func (sastore *SASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopInt()
+	arr, index := popArrayAndIndex(stack)
+	arr.Shorts()[index] = int16(val)
+}
+
+// Store into byte or boolean array
+type BASTORE struct{ base.NoOperandsInstruction }
+
+func (bastore *BASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	val := stack.PopInt()
+	arr, index := popArrayAndIndex(stack)
+	if arr.Class().Name() == "[Z" {
+		val &= 1 // boolean数组只保存最低位
+	}
+	arr.Bytes()[index] = int8(val)
+}
+
+// Store into reference array
+type AASTORE struct{ base.NoOperandsInstruction }
+
+func (aastore *AASTORE) Execute(frame *rtda.Frame) {
+	stack := frame.OperandStack()
+	ref := stack.PopRef()
+	arr, index := popArrayAndIndex(stack)
+	if ref != nil && !ref.IsInstanceOf(arr.Class().ComponentClass()) {
+		panic("java.lang.ArrayStoreException: " + ref.Class().JavaName())
+	}
+	arr.Refs()[index] = ref
+}
+
+// popArrayAndIndex 弹出下标和数组引用，检查null和下标越界
+func popArrayAndIndex(stack *rtda.OperandStack) (*heap.Object, int32) {
+	index := stack.PopInt()
+	arr := stack.PopRef()
+	base.CheckNotNil(arr)
+	base.CheckIndex(arr, index)
+	return arr, index
+}
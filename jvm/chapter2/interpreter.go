@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/instructions"
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native/java/lang"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+func init() {
+	base.SetInterpreter(loop)
+	lang.SetUncaughtExceptionReporter(reportUncaught)
+}
+
+// interpret 在一个新线程里初始化类库，然后用应用类加载器加载并初始化主类，执行它的main方法，
+// 返回进程的退出码：方法正常返回时为0，出现未捕获的异常或者类库初始化失败时为1。
+// main方法结束以后像HotSpot的DestroyJavaVM一样等所有非守护线程结束，再执行关闭钩子
+func interpret(loader *heap.ClassLoader, className string, args []string, maxStackDepth uint) (exitCode int) {
+	thread := rtda.NewThread(maxStackDepth)
+	thread.Attach()
+	loader.SetJavaLoadFunc(func(thread heap.Thread, javaLoader *heap.Object, name string) *heap.Class {
+		return base.LoadClassByJava(thread.(*rtda.Thread), javaLoader, name)
+	})
+	if !initVM(thread, loader) {
+		return 1
+	}
+	defer shutdown(thread, loader)
+	defer rtda.WaitNonDaemonThreads(thread)
+	defer lang.ExitThread(thread)
+	defer func() {
+		if r := recover(); r != nil {
+			reportUncaught(thread, r)
+			exitCode = 1
+		}
+	}()
+
+	mainClass := loader.LoadClass(thread, className)
+	mainMethod := getMainMethod(mainClass)
+	if mainMethod == nil {
+		fmt.Printf("Error: Main method not found in class %s, please define the main method as:\n"+
+			"   public static void main(String[] args)\n", mainClass.JavaName())
+		return 1
+	}
+	base.InitClass(thread, mainClass)
+	frame := thread.NewMethodFrame(mainMethod)
+	frame.LocalVars().SetRef(0, newJStringArray(thread, loader, args))
+	thread.PushFrame(frame)
+	loop(thread, 0)
+	return 0
+}
+
+// newJStringArray 把命令行参数做成传给main方法的String[]
+func newJStringArray(thread *rtda.Thread, loader *heap.ClassLoader, goStrs []string) *heap.Object {
+	stringClass := loader.Bootstrap().LoadClass(thread, "java/lang/String")
+	jStrs := stringClass.ArrayClass(thread).NewArray(uint(len(goStrs)))
+	for i, goStr := range goStrs {
+		jStrs.Refs()[i] = heap.JString(thread, loader, goStr)
+	}
+	return jStrs
+}
+
+// getMainMethod 找public static void main(String[])，必须有字节码
+func getMainMethod(class *heap.Class) *heap.Method {
+	m := class.GetStaticMethod("main", "([Ljava/lang/String;)V")
+	if m == nil || !m.IsPublic() || m.Code() == nil {
+		return nil
+	}
+	return m
+}
+
+// loop 取指、译码、执行，直到线程的栈里只剩下depth个帧。
+// 指令抛出的异常由HandleException处理，找到处理器时从处理器继续执行
+func loop(thread *rtda.Thread, depth uint) {
+	for !run(thread, depth) {
+	}
+}
+
+// run 执行到栈里只剩depth个帧时返回true；异常被某个帧处理了返回false，由loop重新进入
+func run(thread *rtda.Thread, depth uint) (done bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			base.HandleException(thread, depth, r)
+		}
+	}()
+	reader := &base.BytecodeReader{}
+	for thread.StackDepth() > depth {
+		frame := thread.CurrentFrame()
+		pc := frame.NextPC()
+		thread.SetPC(pc)
+		method := frame.Method()
+		if pc < 0 || pc >= len(method.Code()) {
+			panic(fmt.Sprintf("java.lang.VerifyError: falling off the end of the code at pc %d", pc))
+		}
+
+		decoded := decode(reader, method, pc)
+		frame.SetNextPC(decoded.nextPC)
+		decoded.inst.Execute(frame)
+	}
+	return true
+}
+
+// decodedInstruction 译码好的指令和下一条指令的地址
+type decodedInstruction struct {
+	inst   base.Instruction
+	nextPC int
+}
+
+// decode 返回method里pc处译码好的指令。每条指令只在第一次执行时译码，结果缓存在方法里，
+// 各个线程共用：指令的Execute不修改指令自己
+func decode(reader *base.BytecodeReader, method *heap.Method, pc int) *decodedInstruction {
+	if decoded, ok := method.Decoded(pc).(*decodedInstruction); ok {
+		return decoded
+	}
+	reader.Reset(method.Code(), pc)
+	inst := instructions.NewInstruction(reader.ReadUint8())
+	inst.FetchOperands(reader)
+	return method.SetDecoded(pc, &decodedInstruction{inst: inst, nextPC: reader.PC()}).(*decodedInstruction)
+}
+
+// reportUncaught 和ThreadGroup.uncaughtException一样打印未捕获的异常和它的栈轨迹、cause链。
+// 没能换成Java异常对象的错误（比如加载主类时还没有任何帧）只打印消息
+func reportUncaught(thread *rtda.Thread, r interface{}) {
+	if ex := base.ToThrowable(thread, r); ex != nil {
+		fmt.Fprintf(os.Stderr, "Exception in thread \"%s\" ", lang.ThreadName(thread))
+		printStackTrace(ex)
+		return
+	}
+	var msg string
+	switch e := r.(type) {
+	case string:
+		msg = e
+	case *classfile.ClassFormatError:
+		msg = "java.lang.ClassFormatError: " + e.Error()
+		if len(e.Violations) > 1 {
+			for _, violation := range e.Violations {
+				msg += fmt.Sprintf("\n\t%s", violation)
+			}
+		}
+	default:
+		msg = fmt.Sprintf("%v", e)
+	}
+	if !strings.HasPrefix(msg, "java.") {
+		msg = fmt.Sprintf("java.lang.InternalError: %s (pc %d)", msg, thread.PC())
+	}
+	fmt.Fprintf(os.Stderr, "Exception in thread \"%s\" %s\n", lang.ThreadName(thread), msg)
+}
+
+// printStackTrace 按Throwable.printStackTrace的格式打印：和外层异常相同的栈底部分省略成"... n more"
+func printStackTrace(ex *heap.Object) {
+	fmt.Fprintln(os.Stderr, throwableToString(ex))
+	trace := base.StackTrace(ex)
+	for _, element := range trace {
+		fmt.Fprintf(os.Stderr, "\tat %s\n", element)
+	}
+	seen := map[*heap.Object]bool{ex: true}
+	for cause := getCause(ex); cause != nil && !seen[cause]; cause = getCause(cause) {
+		seen[cause] = true
+		causeTrace := base.StackTrace(cause)
+		m, n := len(causeTrace)-1, len(trace)-1
+		for m >= 0 && n >= 0 && *causeTrace[m] == *trace[n] {
+			m--
+			n--
+		}
+		fmt.Fprintf(os.Stderr, "Caused by: %s\n", throwableToString(cause))
+		for _, element := range causeTrace[:m+1] {
+			fmt.Fprintf(os.Stderr, "\tat %s\n", element)
+		}
+		if framesInCommon := len(causeTrace) - 1 - m; framesInCommon != 0 {
+			fmt.Fprintf(os.Stderr, "\t... %d more\n", framesInCommon)
+		}
+		trace = causeTrace
+	}
+}
+
+// throwableToString 和Throwable.toString一样：类名，有详细消息时再加上": 消息"
+func throwableToString(ex *heap.Object) string {
+	s := ex.Class().JavaName()
+	if msg := ex.GetRefVar("detailMessage", "Ljava/lang/String;"); msg != nil {
+		s += ": " + heap.GoString(msg)
+	}
+	return s
+}
+
+// getCause 和Throwable.getCause一样，cause字段是自己时表示还没有设置；
+// ExceptionInInitializerError的getCause返回它的exception字段
+func getCause(ex *heap.Object) *heap.Object {
+	if ex.Class().Name() == "java/lang/ExceptionInInitializerError" {
+		return ex.GetRefVar("exception", "Ljava/lang/Throwable;")
+	}
+	if cause := ex.GetRefVar("cause", "Ljava/lang/Throwable;"); cause != ex {
+		return cause
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package javap
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+type attributeWriter struct {
+	*constantWriter
+	codeWriter *codeWriter
+	options    Options
+	method     *classfile.MemberInfo // 当前方法，Code属性要用
+}
+
+func (attributeWriter *attributeWriter) writeAll(attributes []classfile.AttributeInfo) {
+	for _, attr := range attributes {
+		attributeWriter.write(attr)
+	}
+}
+
+func (attributeWriter *attributeWriter) write(attrInfo classfile.AttributeInfo) {
+	switch attr := attrInfo.(type) {
+	case *classfile.CodeAttribute:
+		attributeWriter.codeWriter.write(attributeWriter.method, attr)
+	case *classfile.ConstantValueAttribute:
+		attributeWriter.print("ConstantValue: ")
+		attributeWriter.constantWriter.write(attr.ConstantValueIndex())
+		attributeWriter.println()
+	case *classfile.DeprecatedAttribute:
+		attributeWriter.printLine("Deprecated: true")
+	case *classfile.SyntheticAttribute:
+		attributeWriter.printLine("Synthetic: true")
+	case *classfile.SourceFileAttribute:
+		attributeWriter.printLine(`SourceFile: "` + attr.FileName() + `"`)
+	case *classfile.SignatureAttribute:
+		attributeWriter.print(fmt.Sprintf("Signature: #%d", attr.SignatureIndex()))
+		attributeWriter.tab()
+		attributeWriter.printLine("// " + attr.Signature())
+	case *classfile.ExceptionsAttribute:
+		attributeWriter.writeExceptions(attr)
+	case *classfile.LineNumberTableAttribute:
+		attributeWriter.printLine("LineNumberTable:")
+		attributeWriter.indent(+1)
+		for _, entry := range attr.Entries() {
+			attributeWriter.printLine(fmt.Sprintf("line %d: %d", entry.LineNumber(), entry.StartPc()))
+		}
+		attributeWriter.indent(-1)
+	case *classfile.LocalVariableTypeTableAttribute:
+		attributeWriter.writeLocalVariables("LocalVariableTypeTable:", attr.Entries())
+	case *classfile.LocalVariableTableAttribute:
+		attributeWriter.writeLocalVariables("LocalVariableTable:", attr.Entries())
+	case *classfile.StackMapTableAttribute:
+		attributeWriter.writeStackMapTable(attr)
+	case *classfile.InnerClassesAttribute:
+		attributeWriter.writeInnerClasses(attr)
+	case *classfile.EnclosingMethodAttribute:
+		attributeWriter.print(fmt.Sprintf("EnclosingMethod: #%d.#%d", attr.ClassIndex(), attr.MethodIndex()))
+		attributeWriter.tab()
+		attributeWriter.print("// " + javaName(attr.ClassName()))
+		if attr.MethodIndex() != 0 {
+			name, _ := attr.MethodNameAndDescriptor()
+			attributeWriter.print("." + name)
+		}
+		attributeWriter.println()
+	case *classfile.BootstrapMethodsAttribute:
+		attributeWriter.writeBootstrapMethods(attr)
+	case *classfile.MethodParametersAttribute:
+		attributeWriter.writeMethodParameters(attr)
+	case *classfile.AnnotationsAttribute:
+		attributeWriter.printLine(attr.Name() + ":")
+		attributeWriter.indent(+1)
+		for i, annotation := range attr.Annotations() {
+			attributeWriter.print(strconv.Itoa(i) + ": ")
+			attributeWriter.writeAnnotation(annotation)
+			attributeWriter.println()
+		}
+		attributeWriter.indent(-1)
+	case *classfile.ParameterAnnotationsAttribute:
+		attributeWriter.printLine(attr.Name() + ":")
+		attributeWriter.indent(+1)
+		for param, annotations := range attr.ParameterAnnotations() {
+			attributeWriter.printLine(fmt.Sprintf("parameter %d: ", param))
+			attributeWriter.indent(+1)
+			for i, annotation := range annotations {
+				attributeWriter.print(strconv.Itoa(i) + ": ")
+				attributeWriter.writeAnnotation(annotation)
+				attributeWriter.println()
+			}
+			attributeWriter.indent(-1)
+		}
+		attributeWriter.indent(-1)
+	case *classfile.AnnotationDefaultAttribute:
+		attributeWriter.printLine("AnnotationDefault:")
+		attributeWriter.indent(+1)
+		attributeWriter.print("default_value: ")
+		attributeWriter.writeElementValue(attr.DefaultValue())
+		attributeWriter.println()
+		attributeWriter.indent(-1)
+	case *classfile.UnparsedAttribute:
+		attributeWriter.writeUnparsed(attr)
+	}
+}
+
+func (attributeWriter *attributeWriter) writeExceptions(attr *classfile.ExceptionsAttribute) {
+	attributeWriter.printLine("Exceptions:")
+	attributeWriter.indent(+1)
+	attributeWriter.print("throws ")
+	for i, index := range attr.ExceptionIndexTable() {
+		if i > 0 {
+			attributeWriter.print(", ")
+		}
+		attributeWriter.print(javaName(attributeWriter.cp.GetClassName(index)))
+	}
+	attributeWriter.println()
+	attributeWriter.indent(-1)
+}
+
+func (attributeWriter *attributeWriter) writeLocalVariables(header string, entries []*classfile.LocalVariableTableEntry) {
+	attributeWriter.printLine(header)
+	attributeWriter.indent(+1)
+	attributeWriter.printLine("Start  Length  Slot  Name   Signature")
+	for _, entry := range entries {
+		attributeWriter.printLine(fmt.Sprintf("%5d %7d %5d %5s   %s",
+			entry.StartPc(), entry.Length(), entry.Index(),
+			attributeWriter.stringValueAt(entry.NameIndex()),
+			attributeWriter.stringValueAt(entry.DescriptorIndex())))
+	}
+	attributeWriter.indent(-1)
+}
+
+func (attributeWriter *attributeWriter) writeStackMapTable(attr *classfile.StackMapTableAttribute) {
+	attributeWriter.printLine(fmt.Sprintf("StackMapTable: number_of_entries = %d", len(attr.Entries())))
+	attributeWriter.indent(+1)
+	for _, frame := range attr.Entries() {
+		t := frame.FrameType()
+		header := func(extra string) {
+			attributeWriter.print(fmt.Sprintf("frame_type = %d ", t))
+			attributeWriter.printLine(extra)
+		}
+		switch {
+		case t <= classfile.SameFrameMax:
+			header("/* same */")
+		case t <= classfile.SameLocals1StackItemFrameMax:
+			header("/* same_locals_1_stack_item */")
+			attributeWriter.indent(+1)
+			attributeWriter.writeVerificationTypes("stack", frame.Stack())
+			attributeWriter.indent(-1)
+		case t == classfile.SameLocals1StackItemFrameExtend:
+			header("/* same_locals_1_stack_item_frame_extended */")
+			attributeWriter.indent(+1)
+			attributeWriter.printLine(fmt.Sprintf("offset_delta = %d", frame.OffsetDelta()))
+			attributeWriter.writeVerificationTypes("stack", frame.Stack())
+			attributeWriter.indent(-1)
+		case t <= classfile.ChopFrameMax:
+			header("/* chop */")
+			attributeWriter.indent(+1)
+			attributeWriter.printLine(fmt.Sprintf("offset_delta = %d", frame.OffsetDelta()))
+			attributeWriter.indent(-1)
+		case t == classfile.SameFrameExtended:
+			header("/* same_frame_extended */")
+			attributeWriter.indent(+1)
+			attributeWriter.printLine(fmt.Sprintf("offset_delta = %d", frame.OffsetDelta()))
+			attributeWriter.indent(-1)
+		case t <= classfile.AppendFrameMax:
+			header("/* append */")
+			attributeWriter.indent(+1)
+			attributeWriter.printLine(fmt.Sprintf("offset_delta = %d", frame.OffsetDelta()))
+			attributeWriter.writeVerificationTypes("locals", frame.Locals())
+			attributeWriter.indent(-1)
+		default:
+			header("/* full_frame */")
+			attributeWriter.indent(+1)
+			attributeWriter.printLine(fmt.Sprintf("offset_delta = %d", frame.OffsetDelta()))
+			attributeWriter.writeVerificationTypes("locals", frame.Locals())
+			attributeWriter.writeVerificationTypes("stack", frame.Stack())
+			attributeWriter.indent(-1)
+		}
+	}
+	attributeWriter.indent(-1)
+}
+
+var verificationTypeNames = [...]string{
+	classfile.ITEM_Top:               "top",
+	classfile.ITEM_Integer:           "int",
+	classfile.ITEM_Float:             "float",
+	classfile.ITEM_Double:            "double",
+	classfile.ITEM_Long:              "long",
+	classfile.ITEM_Null:              "null",
+	classfile.ITEM_UninitializedThis: "this",
+	classfile.ITEM_Object:            "CP",
+	classfile.ITEM_Uninitialized:     "uninitialized",
+}
+
+func (attributeWriter *attributeWriter) writeVerificationTypes(name string, types []classfile.VerificationTypeInfo) {
+	attributeWriter.print(name + " = [")
+	for i, info := range types {
+		switch info.Tag() {
+		case classfile.ITEM_Object:
+			attributeWriter.print(" ")
+			attributeWriter.constantWriter.write(info.CpoolIndex())
+		case classfile.ITEM_Uninitialized:
+			attributeWriter.print(" " + verificationTypeNames[info.Tag()])
+			attributeWriter.print(fmt.Sprintf(" %d", info.Offset()))
+		default:
+			attributeWriter.print(" " + verificationTypeNames[info.Tag()])
+		}
+		if i == len(types)-1 {
+			attributeWriter.print(" ")
+		} else {
+			attributeWriter.print(",")
+		}
+	}
+	attributeWriter.printLine("]")
+}
+
+func (attributeWriter *attributeWriter) writeInnerClasses(attr *classfile.InnerClassesAttribute) {
+	first := true
+	for _, info := range attr.Classes() {
+		flags := info.InnerClassAccessFlags()
+		if !attributeWriter.options.checkAccess(flags) {
+			continue
+		}
+		if first {
+			attributeWriter.printLine("InnerClasses:")
+			attributeWriter.indent(+1)
+			first = false
+		}
+		attributeWriter.print("   ")
+		if flags&classfile.ACC_INTERFACE != 0 {
+			flags &^= classfile.ACC_ABSTRACT
+		}
+		for _, modifier := range flagNames(flags, innerClassModifiers) {
+			attributeWriter.print(modifier + " ")
+		}
+		if info.InnerNameIndex() != 0 {
+			attributeWriter.print(fmt.Sprintf("#%d= ", info.InnerNameIndex()))
+		}
+		attributeWriter.print(fmt.Sprintf("#%d", info.InnerClassInfoIndex()))
+		if info.OuterClassInfoIndex() != 0 {
+			attributeWriter.print(fmt.Sprintf(" of #%d", info.OuterClassInfoIndex()))
+		}
+		attributeWriter.print("; //")
+		if info.InnerNameIndex() != 0 {
+			attributeWriter.print(attributeWriter.cp.GetUtf8(info.InnerNameIndex()) + "=")
+		}
+		attributeWriter.constantWriter.write(info.InnerClassInfoIndex())
+		if info.OuterClassInfoIndex() != 0 {
+			attributeWriter.print(" of ")
+			attributeWriter.constantWriter.write(info.OuterClassInfoIndex())
+		}
+		attributeWriter.println()
+	}
+	if !first {
+		attributeWriter.indent(-1)
+	}
+}
+
+func (attributeWriter *attributeWriter) writeBootstrapMethods(attr *classfile.BootstrapMethodsAttribute) {
+	attributeWriter.printLine("BootstrapMethods:")
+	for i, bsm := range attr.BootstrapMethods() {
+		attributeWriter.indent(+1)
+		attributeWriter.print(fmt.Sprintf("%d: #%d ", i, bsm.BootstrapMethodRef()))
+		attributeWriter.printLine(attributeWriter.stringValueAt(bsm.BootstrapMethodRef()))
+		attributeWriter.indent(+1)
+		attributeWriter.printLine("Method arguments:")
+		attributeWriter.indent(+1)
+		for _, arg := range bsm.BootstrapArguments() {
+			attributeWriter.print(fmt.Sprintf("#%d ", arg))
+			attributeWriter.printLine(attributeWriter.stringValueAt(arg))
+		}
+		attributeWriter.indent(-3)
+	}
+}
+
+func (attributeWriter *attributeWriter) writeMethodParameters(attr *classfile.MethodParametersAttribute) {
+	const format = "%-31s%s"
+	attributeWriter.printLine("MethodParameters:")
+	attributeWriter.indent(+1)
+	attributeWriter.printLine(fmt.Sprintf(format, "Name", "Flags"))
+	for _, param := range attr.Parameters() {
+		name := "<no name>"
+		if param.NameIndex() != 0 {
+			name = attributeWriter.stringValueAt(param.NameIndex())
+		}
+		flags := ""
+		if param.AccessFlags()&classfile.ACC_FINAL != 0 {
+			flags += "final "
+		}
+		if param.AccessFlags()&classfile.ACC_MANDATED != 0 {
+			flags += "mandated "
+		}
+		if param.AccessFlags()&classfile.ACC_SYNTHETIC != 0 {
+			flags += "synthetic"
+		}
+		attributeWriter.printLine(fmt.Sprintf(format, name, flags))
+	}
+	attributeWriter.indent(-1)
+}
+
+// JDK 8的javap只打印注解的常量池索引，例如 #17(#18=s#19)
+func (attributeWriter *attributeWriter) writeAnnotation(annotation *classfile.Annotation) {
+	attributeWriter.print(fmt.Sprintf("#%d(", annotation.TypeIndex()))
+	for i, pair := range annotation.ElementValuePairs() {
+		if i > 0 {
+			attributeWriter.print(",")
+		}
+		attributeWriter.print(fmt.Sprintf("#%d=", pair.ElementNameIndex()))
+		attributeWriter.writeElementValue(pair.Value())
+	}
+	attributeWriter.print(")")
+}
+
+func (attributeWriter *attributeWriter) writeElementValue(value *classfile.ElementValue) {
+	switch value.Tag() {
+	case 'e':
+		attributeWriter.print(fmt.Sprintf("e#%d.#%d", value.ConstValueIndex(), value.ConstNameIndex()))
+	case '@':
+		attributeWriter.print("@")
+		attributeWriter.writeAnnotation(value.AnnotationValue())
+	case '[':
+		attributeWriter.print("[")
+		for i, v := range value.ArrayValue() {
+			if i > 0 {
+				attributeWriter.print(",")
+			}
+			attributeWriter.writeElementValue(v)
+		}
+		attributeWriter.print("]")
+	default:
+		attributeWriter.print(fmt.Sprintf("%c#%d", value.Tag(), value.ConstValueIndex()))
+	}
+}
+
+var lineBreaks = regexp.MustCompile("[\r\n]+")
+
+// 不认识的属性按十六进制打印内容
+func (attributeWriter *attributeWriter) writeUnparsed(attr *classfile.UnparsedAttribute) {
+	data := attr.Info()
+	if attr.Name() == "SourceDebugExtension" {
+		attributeWriter.printLine("SourceDebugExtension:")
+		attributeWriter.indent(+1)
+		for _, line := range lineBreaks.Split(string(data), -1) {
+			attributeWriter.printLine(line)
+		}
+		attributeWriter.indent(-1)
+		return
+	}
+	attributeWriter.print("  ")
+	attributeWriter.print(attr.Name())
+	attributeWriter.print(": ")
+	attributeWriter.printLine("length = 0x" + strings.ToUpper(strconv.FormatInt(int64(len(data)), 16)))
+	attributeWriter.print("   ")
+	for i, b := range data {
+		attributeWriter.print(fmt.Sprintf("%02X", b))
+		if (i+1)%16 == 0 {
+			attributeWriter.println()
+			attributeWriter.print("   ")
+		} else {
+			attributeWriter.print(" ")
+		}
+	}
+	attributeWriter.println()
+}
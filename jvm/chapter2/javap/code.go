@@ -0,0 +1,122 @@
+package javap
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+type codeWriter struct {
+	*constantWriter
+	attrWriter *attributeWriter
+}
+
+// write 是-v时Code属性的完整输出
+func (codeWriter *codeWriter) write(method *classfile.MemberInfo, code *classfile.CodeAttribute) {
+	codeWriter.printLine("Code:")
+	codeWriter.indent(+1)
+	codeWriter.writeVerboseHeader(method, code)
+	codeWriter.writeInstrs(code)
+	codeWriter.writeExceptionTable(code)
+	codeWriter.attrWriter.writeAll(code.Attributes())
+	codeWriter.indent(-1)
+}
+
+// args_size是参数个数（不是slot数），实例方法还要加上this
+func (codeWriter *codeWriter) writeVerboseHeader(method *classfile.MemberInfo, code *classfile.CodeAttribute) {
+	argCount := "???"
+	if method == nil {
+		// 不在方法里的Code属性，格式本身就有问题
+	} else if md, err := signature.ParseMethodDescriptor(method.Descriptor()); err == nil {
+		n := len(md.Params)
+		if method.AccessFlags()&classfile.ACC_STATIC == 0 {
+			n++
+		}
+		argCount = strconv.Itoa(n)
+	}
+	codeWriter.printLine(fmt.Sprintf("stack=%d, locals=%d, args_size=%s",
+		code.MaxStack(), code.MaxLocals(), argCount))
+}
+
+func (codeWriter *codeWriter) writeInstrs(code *classfile.CodeAttribute) {
+	bytecode := code.Code()
+	for pc := 0; pc < len(bytecode); {
+		instr, err := opcodes.Decode(bytecode, pc)
+		if err != nil {
+			codeWriter.printLine(fmt.Sprintf("%4d: error: %v", pc, err))
+			return
+		}
+		codeWriter.writeInstr(instr)
+		pc += instr.Length
+	}
+}
+
+func (codeWriter *codeWriter) writeInstr(instr *opcodes.Instruction) {
+	codeWriter.print(fmt.Sprintf("%4d: %-13s ", instr.PC, instr.Name()))
+	// 多行指令（switch）的缩进：宽度6的"%4d: "折算成缩进级数
+	indent := (6 + indentWidth - 1) / indentWidth
+	switch opcodes.Kind(instr.Opcode) {
+	case opcodes.Byte, opcodes.Short:
+		codeWriter.print(strconv.Itoa(instr.Value))
+	case opcodes.CPRefByte, opcodes.CPRef:
+		codeWriter.print(fmt.Sprintf("#%d", instr.Index))
+		codeWriter.tab()
+		codeWriter.print("// ")
+		codeWriter.constantWriter.write(uint16(instr.Index))
+	case opcodes.CPRefCountZero, opcodes.CPRefZeroZero, opcodes.CPRefDims:
+		codeWriter.print(fmt.Sprintf("#%d,  %d", instr.Index, instr.Value))
+		codeWriter.tab()
+		codeWriter.print("// ")
+		codeWriter.constantWriter.write(uint16(instr.Index))
+	case opcodes.Local:
+		codeWriter.print(strconv.Itoa(instr.Index))
+	case opcodes.LocalByte:
+		codeWriter.print(fmt.Sprintf("%d, %d", instr.Index, instr.Value))
+	case opcodes.Branch, opcodes.BranchWide:
+		codeWriter.print(strconv.Itoa(instr.Branch))
+	case opcodes.ArrayType:
+		codeWriter.print(" " + opcodes.ArrayTypeName(instr.Value))
+	case opcodes.TableSwitch:
+		high := int(instr.Low) + len(instr.Targets) - 1
+		codeWriter.print(fmt.Sprintf("{ // %d to %d", instr.Low, high))
+		codeWriter.indent(indent)
+		for i, target := range instr.Targets {
+			codeWriter.print(fmt.Sprintf("\n%12d: %d", int(instr.Low)+i, target))
+		}
+		codeWriter.print(fmt.Sprintf("\n     default: %d\n}", instr.Default))
+		codeWriter.indent(-indent)
+	case opcodes.LookupSwitch:
+		codeWriter.print(fmt.Sprintf("{ // %d", len(instr.Keys)))
+		codeWriter.indent(indent)
+		for i, key := range instr.Keys {
+			codeWriter.print(fmt.Sprintf("\n%12d: %d", key, instr.Targets[i]))
+		}
+		codeWriter.print(fmt.Sprintf("\n     default: %d\n}", instr.Default))
+		codeWriter.indent(-indent)
+	}
+	codeWriter.println()
+}
+
+func (codeWriter *codeWriter) writeExceptionTable(code *classfile.CodeAttribute) {
+	table := code.ExceptionTable()
+	if len(table) == 0 {
+		return
+	}
+	codeWriter.printLine("Exception table:")
+	codeWriter.indent(+1)
+	codeWriter.printLine(" from    to  target type")
+	for _, entry := range table {
+		codeWriter.print(fmt.Sprintf(" %5d %5d %5d", entry.StartPc(), entry.EndPc(), entry.HandlerPc()))
+		codeWriter.print("   ")
+		if entry.CatchType() == 0 {
+			codeWriter.printLine("any")
+		} else {
+			codeWriter.print("Class ")
+			codeWriter.printLine(codeWriter.stringValueAt(entry.CatchType()))
+		}
+	}
+	codeWriter.indent(-1)
+}
@@ -0,0 +1,222 @@
+package javap
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// 常量池列表里用的tag名
+func cpTagName(info classfile.ConstantInfo) string {
+	switch info.(type) {
+	case *classfile.ConstantUtf8Info:
+		return "Utf8"
+	case *classfile.ConstantIntegerInfo:
+		return "Integer"
+	case *classfile.ConstantFloatInfo:
+		return "Float"
+	case *classfile.ConstantLongInfo:
+		return "Long"
+	case *classfile.ConstantDoubleInfo:
+		return "Double"
+	case *classfile.ConstantClassInfo:
+		return "Class"
+	case *classfile.ConstantStringInfo:
+		return "String"
+	case *classfile.ConstantFieldrefInfo:
+		return "Fieldref"
+	case *classfile.ConstantMethodrefInfo:
+		return "Methodref"
+	case *classfile.ConstantInterfaceMethodrefInfo:
+		return "InterfaceMethodref"
+	case *classfile.ConstantNameAndTypeInfo:
+		return "NameAndType"
+	case *classfile.ConstantMethodHandleInfo:
+		return "MethodHandle"
+	case *classfile.ConstantMethodTypeInfo:
+		return "MethodType"
+	case *classfile.ConstantInvokeDynamicInfo:
+		return "InvokeDynamic"
+	case *classfile.ConstantDynamicInfo:
+		return "Dynamic"
+	case *classfile.ConstantModuleInfo:
+		return "Module"
+	case *classfile.ConstantPackageInfo:
+		return "Package"
+	}
+	return "Unknown"
+}
+
+// 指令注释和属性里引用常量时用的tag名
+func tagName(tag uint8) string {
+	switch tag {
+	case classfile.CONSTANT_Utf8:
+		return "Utf8"
+	case classfile.CONSTANT_Integer:
+		return "int"
+	case classfile.CONSTANT_Float:
+		return "float"
+	case classfile.CONSTANT_Long:
+		return "long"
+	case classfile.CONSTANT_Double:
+		return "double"
+	case classfile.CONSTANT_Class:
+		return "class"
+	case classfile.CONSTANT_String:
+		return "String"
+	case classfile.CONSTANT_Fieldref:
+		return "Field"
+	case classfile.CONSTANT_MethodHandle:
+		return "MethodHandle"
+	case classfile.CONSTANT_MethodType:
+		return "MethodType"
+	case classfile.CONSTANT_Methodref:
+		return "Method"
+	case classfile.CONSTANT_InterfaceMethodref:
+		return "InterfaceMethod"
+	case classfile.CONSTANT_InvokeDynamic:
+		return "InvokeDynamic"
+	case classfile.CONSTANT_Dynamic:
+		return "Dynamic"
+	case classfile.CONSTANT_NameAndType:
+		return "NameAndType"
+	}
+	return fmt.Sprintf("(unknown tag %d)", tag)
+}
+
+type constantWriter struct {
+	*lineWriter
+	cf *classfile.ClassFile
+	cp *classfile.ConstantPool
+}
+
+func (constantWriter *constantWriter) writeConstantPool() {
+	cp := constantWriter.cp
+	constantWriter.printLine("Constant pool:")
+	constantWriter.indent(+1)
+	width := len(strconv.Itoa(cp.Count())) + 1
+	for i := 1; i < cp.Count(); i++ {
+		info := cp.GetConstantInfo(uint16(i))
+		if info == nil {
+			continue // long和double的第二个位置
+		}
+		constantWriter.print(fmt.Sprintf("%*s", width, "#"+strconv.Itoa(i)))
+		constantWriter.print(fmt.Sprintf(" = %-18s ", cpTagName(info)))
+		switch c := info.(type) {
+		case *classfile.ConstantClassInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d", c.NameIndex()), info)
+		case *classfile.ConstantModuleInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d", c.NameIndex()), info)
+		case *classfile.ConstantPackageInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d", c.NameIndex()), info)
+		case *classfile.ConstantStringInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d", c.StringIndex()), info)
+		case *classfile.ConstantFieldrefInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d.#%d", c.ClassIndex(), c.NameAndTypeIndex()), info)
+		case *classfile.ConstantMethodrefInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d.#%d", c.ClassIndex(), c.NameAndTypeIndex()), info)
+		case *classfile.ConstantInterfaceMethodrefInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d.#%d", c.ClassIndex(), c.NameAndTypeIndex()), info)
+		case *classfile.ConstantNameAndTypeInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d:#%d", c.NameIndex(), c.DescriptorIndex()), info)
+		case *classfile.ConstantMethodHandleInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d:#%d", c.ReferenceKind(), c.ReferenceIndex()), info)
+		case *classfile.ConstantMethodTypeInfo:
+			// JDK 8的javap这里多打了一个空格
+			constantWriter.print(fmt.Sprintf("#%d", c.DescriptorIndex()))
+			constantWriter.tab()
+			constantWriter.printLine("//  " + constantWriter.stringValue(info))
+		case *classfile.ConstantInvokeDynamicInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d:#%d", c.BootstrapMethodAttrIndex(), c.NameAndTypeIndex()), info)
+		case *classfile.ConstantDynamicInfo:
+			constantWriter.printRef(fmt.Sprintf("#%d:#%d", c.BootstrapMethodAttrIndex(), c.NameAndTypeIndex()), info)
+		default:
+			constantWriter.printLine(constantWriter.stringValue(info))
+		}
+	}
+	constantWriter.indent(-1)
+}
+
+func (constantWriter *constantWriter) printRef(ref string, info classfile.ConstantInfo) {
+	constantWriter.print(ref)
+	constantWriter.tab()
+	constantWriter.printLine("// " + constantWriter.stringValue(info))
+}
+
+// write 打印"tag 值"的形式，例如 Method java/lang/Object."<init>":()V
+// 引用本类成员时省略类名
+func (constantWriter *constantWriter) write(index uint16) {
+	if index == 0 {
+		constantWriter.print("#0")
+		return
+	}
+	info := constantWriter.cp.GetConstantInfo(index)
+	if info == nil {
+		constantWriter.print(fmt.Sprintf("#%d", index))
+		return
+	}
+	value := constantWriter.stringValue(info)
+	if ref, ok := memberref(info); ok && ref.ClassIndex() == constantWriter.cf.ThisClass() {
+		value = constantWriter.stringValueAt(ref.NameAndTypeIndex())
+	}
+	constantWriter.print(tagName(info.Tag()) + " " + value)
+}
+
+func memberref(info classfile.ConstantInfo) (*classfile.ConstantMemberrefInfo, bool) {
+	switch c := info.(type) {
+	case *classfile.ConstantFieldrefInfo:
+		return &c.ConstantMemberrefInfo, true
+	case *classfile.ConstantMethodrefInfo:
+		return &c.ConstantMemberrefInfo, true
+	case *classfile.ConstantInterfaceMethodrefInfo:
+		return &c.ConstantMemberrefInfo, true
+	}
+	return nil, false
+}
+
+func (constantWriter *constantWriter) stringValueAt(index uint16) string {
+	info := constantWriter.cp.GetConstantInfo(index)
+	if info == nil {
+		return fmt.Sprintf("<invalid constant pool index %d>", index)
+	}
+	return constantWriter.stringValue(info)
+}
+
+func (constantWriter *constantWriter) stringValue(info classfile.ConstantInfo) string {
+	cp := constantWriter.cp
+	switch c := info.(type) {
+	case *classfile.ConstantUtf8Info:
+		return escapeUtf8(c.Str())
+	case *classfile.ConstantIntegerInfo:
+		return strconv.Itoa(int(c.Value()))
+	case *classfile.ConstantFloatInfo:
+		return javaFloat(c.Value()) + "f"
+	case *classfile.ConstantLongInfo:
+		return strconv.FormatInt(c.Value(), 10) + "l"
+	case *classfile.ConstantDoubleInfo:
+		return javaDouble(c.Value()) + "d"
+	case *classfile.ConstantClassInfo:
+		return checkName(c.Name())
+	case *classfile.ConstantModuleInfo:
+		return checkName(c.Name())
+	case *classfile.ConstantPackageInfo:
+		return checkName(c.Name())
+	case *classfile.ConstantStringInfo:
+		return constantWriter.stringValueAt(c.StringIndex())
+	case *classfile.ConstantNameAndTypeInfo:
+		return checkName(cp.GetUtf8(c.NameIndex())) + ":" + constantWriter.stringValueAt(c.DescriptorIndex())
+	case *classfile.ConstantMethodHandleInfo:
+		return refKindNames[c.ReferenceKind()] + " " + constantWriter.stringValueAt(c.ReferenceIndex())
+	case *classfile.ConstantMethodTypeInfo:
+		return constantWriter.stringValueAt(c.DescriptorIndex())
+	case *classfile.ConstantInvokeDynamicInfo:
+		return fmt.Sprintf("#%d:", c.BootstrapMethodAttrIndex()) + constantWriter.stringValueAt(c.NameAndTypeIndex())
+	case *classfile.ConstantDynamicInfo:
+		return fmt.Sprintf("#%d:", c.BootstrapMethodAttrIndex()) + constantWriter.stringValueAt(c.NameAndTypeIndex())
+	}
+	if ref, ok := memberref(info); ok {
+		return checkName(ref.ClassName()) + "." + constantWriter.stringValueAt(ref.NameAndTypeIndex())
+	}
+	return "???"
+}
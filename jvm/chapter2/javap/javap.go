@@ -0,0 +1,281 @@
+package javap
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// Options 对应javap的-c、-v、-p选项
+type Options struct {
+	Code    bool // -c 反汇编方法的字节码
+	Verbose bool // -v 打印常量池、版本号、所有属性等附加信息
+	Private bool // -p 显示所有成员，包括private的
+}
+
+// 默认只显示非private的成员
+func (options Options) checkAccess(flags uint16) bool {
+	return options.Private || flags&classfile.ACC_PRIVATE == 0
+}
+
+// classWriter 把一个class文件按JDK 8的javap格式打印出来
+type classWriter struct {
+	*lineWriter
+	options     Options
+	cf          *classfile.ClassFile
+	data        []byte
+	location    string
+	modTime     time.Time
+	constWriter *constantWriter
+	attrWriter  *attributeWriter
+}
+
+// Print 解析class数据并打印；location和modTime只在-v的文件头里用到
+func Print(out io.Writer, data []byte, location string, modTime time.Time, options Options) error {
+	cf, err := classfile.Parse(data)
+	if err != nil {
+		return err
+	}
+	lw := &lineWriter{out: out}
+	constWriter := &constantWriter{lineWriter: lw, cf: cf, cp: cf.ConstantPool()}
+	attrWriter := &attributeWriter{constantWriter: constWriter, options: options}
+	attrWriter.codeWriter = &codeWriter{constantWriter: constWriter, attrWriter: attrWriter}
+	writer := &classWriter{
+		lineWriter:  lw,
+		options:     options,
+		cf:          cf,
+		data:        data,
+		location:    location,
+		modTime:     modTime,
+		constWriter: constWriter,
+		attrWriter:  attrWriter,
+	}
+	writer.write()
+	return lw.err
+}
+
+func (classWriter *classWriter) style() signature.Style {
+	return signature.Style{ShowObject: classWriter.options.Verbose}
+}
+
+func (classWriter *classWriter) write() {
+	cf := classWriter.cf
+	if classWriter.options.Verbose {
+		classWriter.printLine("Classfile " + classWriter.location)
+		classWriter.indent(+1)
+		if !classWriter.modTime.IsZero() {
+			classWriter.print("Last modified " + classWriter.modTime.Format("Jan 2, 2006") + "; ")
+		}
+		classWriter.printLine(fmt.Sprintf("size %d bytes", len(classWriter.data)))
+		classWriter.printLine(fmt.Sprintf("MD5 checksum %x", md5.Sum(classWriter.data)))
+	}
+	if sourceFile := cf.SourceFileAttribute(); sourceFile != nil {
+		classWriter.printLine(`Compiled from "` + sourceFile.FileName() + `"`)
+	}
+	if classWriter.options.Verbose {
+		classWriter.indent(-1)
+	}
+
+	classWriter.writeClassDecl()
+	if classWriter.options.Verbose {
+		classWriter.println()
+		classWriter.indent(+1)
+		classWriter.printLine(fmt.Sprintf("minor version: %d", cf.MinorVersion()))
+		classWriter.printLine(fmt.Sprintf("major version: %d", cf.MajorVersion()))
+		classWriter.printList("flags: ", flagNames(cf.AccessFlags(), classFlags), "")
+		classWriter.println()
+		classWriter.indent(-1)
+		classWriter.constWriter.writeConstantPool()
+	} else {
+		classWriter.print(" ")
+	}
+
+	classWriter.printLine("{")
+	classWriter.indent(+1)
+	for _, field := range cf.Fields() {
+		classWriter.writeField(field)
+	}
+	for _, method := range cf.Methods() {
+		classWriter.writeMethod(method)
+	}
+	classWriter.indent(-1)
+	classWriter.pendingNewline = false
+	classWriter.printLine("}")
+
+	if classWriter.options.Verbose {
+		classWriter.attrWriter.method = nil
+		classWriter.attrWriter.writeAll(cf.Attributes())
+	}
+}
+
+func (classWriter *classWriter) writeClassDecl() {
+	cf := classWriter.cf
+	flags := cf.AccessFlags()
+	isInterface := flags&classfile.ACC_INTERFACE != 0
+	if isInterface {
+		flags &^= classfile.ACC_ABSTRACT
+	}
+	for _, modifier := range flagNames(flags, classModifiers) {
+		classWriter.print(modifier + " ")
+	}
+	if isInterface {
+		classWriter.print("interface ")
+	} else {
+		classWriter.print("class ")
+	}
+
+	name := javaName(cf.ClassName())
+	if sigAttr := cf.SignatureAttribute(); sigAttr != nil {
+		if sig, err := signature.ParseClassSignature(sigAttr.Signature()); err == nil {
+			classWriter.print(sig.Format(name, isInterface, classWriter.style()))
+			return
+		}
+	}
+
+	classWriter.print(name)
+	if superName := cf.SuperClassName(); superName != "" && superName != "java/lang/Object" {
+		classWriter.print(" extends " + javaName(superName))
+	}
+	for i, iface := range cf.InterfaceNames() {
+		switch {
+		case i > 0:
+			classWriter.print(",")
+		case isInterface:
+			classWriter.print(" extends ")
+		default:
+			classWriter.print(" implements ")
+		}
+		classWriter.print(javaName(iface))
+	}
+}
+
+func (classWriter *classWriter) writeField(field *classfile.MemberInfo) {
+	if !classWriter.options.checkAccess(field.AccessFlags()) {
+		return
+	}
+	for _, modifier := range flagNames(field.AccessFlags(), fieldModifiers) {
+		classWriter.print(modifier + " ")
+	}
+	classWriter.print(classWriter.fieldType(field))
+	classWriter.print(" ")
+	classWriter.print(field.Name())
+	classWriter.printLine(";")
+
+	classWriter.indent(+1)
+	if classWriter.options.Verbose {
+		classWriter.printLine("descriptor: " + field.Descriptor())
+		classWriter.printList("flags: ", flagNames(field.AccessFlags(), fieldFlags), "")
+		classWriter.println()
+		classWriter.attrWriter.method = nil
+		classWriter.attrWriter.writeAll(field.Attributes())
+	}
+	classWriter.indent(-1)
+	classWriter.pendingNewline = classWriter.options.Code || classWriter.options.Verbose
+}
+
+func (classWriter *classWriter) fieldType(field *classfile.MemberInfo) string {
+	if sigAttr := field.SignatureAttribute(); sigAttr != nil {
+		if t, err := signature.ParseFieldSignature(sigAttr.Signature()); err == nil {
+			return signature.Format(t, classWriter.style())
+		}
+	}
+	if t, err := signature.ParseFieldDescriptor(field.Descriptor()); err == nil {
+		return signature.Format(t, classWriter.style())
+	}
+	return field.Descriptor()
+}
+
+func (classWriter *classWriter) writeMethod(method *classfile.MemberInfo) {
+	if !classWriter.options.checkAccess(method.AccessFlags()) {
+		return
+	}
+	classWriter.print(classWriter.methodDecl(method))
+	classWriter.printLine(";")
+
+	classWriter.indent(+1)
+	if classWriter.options.Verbose {
+		classWriter.printLine("descriptor: " + method.Descriptor())
+		classWriter.printList("flags: ", flagNames(method.AccessFlags(), methodFlags), "")
+		classWriter.println()
+		classWriter.attrWriter.method = method
+		classWriter.attrWriter.writeAll(method.Attributes())
+	} else if code := method.CodeAttribute(); code != nil && classWriter.options.Code {
+		codeWriter := classWriter.attrWriter.codeWriter
+		codeWriter.printLine("Code:")
+		codeWriter.writeInstrs(code)
+		codeWriter.writeExceptionTable(code)
+	}
+	classWriter.indent(-1)
+	classWriter.pendingNewline = classWriter.options.Code || classWriter.options.Verbose
+}
+
+// methodDecl 方法声明，有Signature属性时按泛型签名打印
+func (classWriter *classWriter) methodDecl(method *classfile.MemberInfo) string {
+	cf := classWriter.cf
+	flags := method.AccessFlags()
+	name := method.Name()
+	style := classWriter.style()
+
+	var sb strings.Builder
+	for _, modifier := range flagNames(flags, methodModifiers) {
+		sb.WriteString(modifier + " ")
+	}
+	if cf.AccessFlags()&classfile.ACC_INTERFACE != 0 &&
+		flags&(classfile.ACC_ABSTRACT|classfile.ACC_STATIC) == 0 && name != "<clinit>" {
+		sb.WriteString("default ")
+	}
+	if name == "<clinit>" {
+		sb.WriteString("{}")
+		return sb.String()
+	}
+
+	var sig *signature.MethodSignature
+	if sigAttr := method.SignatureAttribute(); sigAttr != nil {
+		sig, _ = signature.ParseMethodSignature(sigAttr.Signature())
+	}
+	if sig == nil {
+		var err error
+		if sig, err = signature.ParseMethodDescriptor(method.Descriptor()); err != nil {
+			sb.WriteString(name + method.Descriptor())
+			return sb.String()
+		}
+	}
+
+	if len(sig.TypeParams) > 0 {
+		sb.WriteString(sig.TypeParamsString(style) + " ")
+	}
+	if name == "<init>" {
+		sb.WriteString(javaName(cf.ClassName()))
+	} else {
+		sb.WriteString(signature.Format(sig.Return, style) + " " + name)
+	}
+
+	params := make([]string, len(sig.Params))
+	for i, param := range sig.Params {
+		params[i] = signature.Format(param, style)
+	}
+	if n := len(params); n > 0 && flags&classfile.ACC_VARARGS != 0 && strings.HasSuffix(params[n-1], "[]") {
+		params[n-1] = strings.TrimSuffix(params[n-1], "[]") + "..."
+	}
+	sb.WriteString("(" + strings.Join(params, ", ") + ")")
+
+	if exceptions := method.ExceptionsAttribute(); exceptions != nil {
+		var throws []string
+		if len(sig.Throws) > 0 {
+			for _, t := range sig.Throws {
+				throws = append(throws, signature.Format(t, style))
+			}
+		} else {
+			for _, index := range exceptions.ExceptionIndexTable() {
+				throws = append(throws, javaName(cf.ConstantPool().GetClassName(index)))
+			}
+		}
+		sb.WriteString(" throws " + strings.Join(throws, ", "))
+	}
+	return sb.String()
+}
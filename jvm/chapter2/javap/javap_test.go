@@ -0,0 +1,60 @@
+package javap
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// 输出和testdata下JDK 8 javap格式的golden文件逐字节比较；改了格式之后用 go test -update 重新生成
+func TestGolden(t *testing.T) {
+	modTime := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		class   string
+		options Options
+		golden  string
+	}{
+		{"Hello.class", Options{}, "Hello.txt"},
+		{"Hello.class", Options{Code: true, Private: true}, "Hello-c-p.txt"},
+		{"pkg/Test.class", Options{}, "Test.txt"},
+		{"pkg/Test.class", Options{Code: true}, "Test-c.txt"},
+		{"pkg/Test.class", Options{Verbose: true, Private: true}, "Test-v-p.txt"},
+	}
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "classfile", "testdata", test.class))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := Print(&out, data, "/classes/"+test.class, modTime, test.options); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", test.golden)
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), want) {
+				t.Errorf("output differs from %s:\n%s", golden, out.String())
+			}
+		})
+	}
+}
+
+func TestPrintMalformed(t *testing.T) {
+	var out bytes.Buffer
+	if err := Print(&out, []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0}, "Bad.class", time.Time{}, Options{}); err == nil {
+		t.Error("expected an error for a truncated class file")
+	}
+}
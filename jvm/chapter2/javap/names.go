@@ -0,0 +1,240 @@
+package javap
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+type flagName struct {
+	flag uint16
+	name string
+}
+
+var classModifiers = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_ABSTRACT, "abstract"},
+}
+
+var fieldModifiers = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_PRIVATE, "private"},
+	{classfile.ACC_PROTECTED, "protected"},
+	{classfile.ACC_STATIC, "static"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_VOLATILE, "volatile"},
+	{classfile.ACC_TRANSIENT, "transient"},
+}
+
+var methodModifiers = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_PRIVATE, "private"},
+	{classfile.ACC_PROTECTED, "protected"},
+	{classfile.ACC_STATIC, "static"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_SYNCHRONIZED, "synchronized"},
+	{classfile.ACC_NATIVE, "native"},
+	{classfile.ACC_ABSTRACT, "abstract"},
+	{classfile.ACC_STRICT, "strictfp"},
+}
+
+var innerClassModifiers = []flagName{
+	{classfile.ACC_PUBLIC, "public"},
+	{classfile.ACC_PRIVATE, "private"},
+	{classfile.ACC_PROTECTED, "protected"},
+	{classfile.ACC_STATIC, "static"},
+	{classfile.ACC_FINAL, "final"},
+	{classfile.ACC_ABSTRACT, "abstract"},
+}
+
+var classFlags = []flagName{
+	{classfile.ACC_PUBLIC, "ACC_PUBLIC"},
+	{classfile.ACC_FINAL, "ACC_FINAL"},
+	{classfile.ACC_SUPER, "ACC_SUPER"},
+	{classfile.ACC_INTERFACE, "ACC_INTERFACE"},
+	{classfile.ACC_ABSTRACT, "ACC_ABSTRACT"},
+	{classfile.ACC_SYNTHETIC, "ACC_SYNTHETIC"},
+	{classfile.ACC_ANNOTATION, "ACC_ANNOTATION"},
+	{classfile.ACC_ENUM, "ACC_ENUM"},
+}
+
+var fieldFlags = []flagName{
+	{classfile.ACC_PUBLIC, "ACC_PUBLIC"},
+	{classfile.ACC_PRIVATE, "ACC_PRIVATE"},
+	{classfile.ACC_PROTECTED, "ACC_PROTECTED"},
+	{classfile.ACC_STATIC, "ACC_STATIC"},
+	{classfile.ACC_FINAL, "ACC_FINAL"},
+	{classfile.ACC_VOLATILE, "ACC_VOLATILE"},
+	{classfile.ACC_TRANSIENT, "ACC_TRANSIENT"},
+	{classfile.ACC_SYNTHETIC, "ACC_SYNTHETIC"},
+	{classfile.ACC_ENUM, "ACC_ENUM"},
+}
+
+var methodFlags = []flagName{
+	{classfile.ACC_PUBLIC, "ACC_PUBLIC"},
+	{classfile.ACC_PRIVATE, "ACC_PRIVATE"},
+	{classfile.ACC_PROTECTED, "ACC_PROTECTED"},
+	{classfile.ACC_STATIC, "ACC_STATIC"},
+	{classfile.ACC_FINAL, "ACC_FINAL"},
+	{classfile.ACC_SYNCHRONIZED, "ACC_SYNCHRONIZED"},
+	{classfile.ACC_BRIDGE, "ACC_BRIDGE"},
+	{classfile.ACC_VARARGS, "ACC_VARARGS"},
+	{classfile.ACC_NATIVE, "ACC_NATIVE"},
+	{classfile.ACC_ABSTRACT, "ACC_ABSTRACT"},
+	{classfile.ACC_STRICT, "ACC_STRICT"},
+	{classfile.ACC_SYNTHETIC, "ACC_SYNTHETIC"},
+}
+
+func flagNames(flags uint16, table []flagName) []string {
+	var names []string
+	for _, fn := range table {
+		if flags&fn.flag != 0 {
+			names = append(names, fn.name)
+		}
+	}
+	return names
+}
+
+// MethodHandle的reference_kind在JDK 8的javap里用小写的指令名表示
+var refKindNames = map[uint8]string{
+	classfile.REF_getField:         "getfield",
+	classfile.REF_getStatic:        "getstatic",
+	classfile.REF_putField:         "putfield",
+	classfile.REF_putStatic:        "putstatic",
+	classfile.REF_invokeVirtual:    "invokevirtual",
+	classfile.REF_invokeStatic:     "invokestatic",
+	classfile.REF_invokeSpecial:    "invokespecial",
+	classfile.REF_newInvokeSpecial: "newinvokespecial",
+	classfile.REF_invokeInterface:  "invokeinterface",
+}
+
+func javaName(internalName string) string {
+	return strings.Replace(internalName, "/", ".", -1)
+}
+
+func isJavaIdentifierStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '$' || c == '_' ||
+		unicode.Is(unicode.Sc, c) || unicode.Is(unicode.Pc, c) || unicode.Is(unicode.Nl, c)
+}
+
+func isJavaIdentifierPart(c rune) bool {
+	return isJavaIdentifierStart(c) || unicode.IsDigit(c) ||
+		unicode.Is(unicode.Mn, c) || unicode.Is(unicode.Mc, c) || unicode.Is(unicode.Cf, c) ||
+		c <= 0x08 || c >= 0x0e && c <= 0x1b || c >= 0x7f && c <= 0x9f
+}
+
+// checkName 名字不是合法的Java标识符（以/分段）时加上引号，例如 "<init>"
+func checkName(name string) string {
+	if name == "" {
+		return `""`
+	}
+	prev := '/'
+	for _, c := range name {
+		if prev == '/' && !isJavaIdentifierStart(c) || c != '/' && !isJavaIdentifierPart(c) {
+			return `"` + addEscapes(name) + `"`
+		}
+		prev = c
+	}
+	return name
+}
+
+func addEscapes(name string) string {
+	var sb strings.Builder
+	for _, c := range name {
+		switch c {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// escapeUtf8 常量池中Utf8常量的打印形式
+func escapeUtf8(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		switch c {
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '"':
+			sb.WriteString(`\"`)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// javaDouble 按Java的Double.toString格式化
+func javaDouble(v float64) string {
+	return javaFloatString(v, 64)
+}
+
+// javaFloat 按Java的Float.toString格式化
+func javaFloat(v float32) string {
+	return javaFloatString(float64(v), 32)
+}
+
+func javaFloatString(v float64, bitSize int) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "Infinity"
+	case math.IsInf(v, -1):
+		return "-Infinity"
+	case v == 0:
+		if math.Signbit(v) {
+			return "-0.0"
+		}
+		return "0.0"
+	}
+
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	// 最短的十进制表示：d.ddddde±xx
+	s := strconv.FormatFloat(v, 'e', -1, bitSize)
+	mantissa, expPart := s[:strings.IndexByte(s, 'e')], s[strings.IndexByte(s, 'e')+1:]
+	exp, _ := strconv.Atoi(expPart)
+	digits := strings.Replace(mantissa, ".", "", 1)
+
+	if v >= 1e-3 && v < 1e7 {
+		var intPart, fracPart string
+		if exp >= 0 {
+			for len(digits) <= exp {
+				digits += "0"
+			}
+			intPart, fracPart = digits[:exp+1], digits[exp+1:]
+		} else {
+			intPart, fracPart = "0", strings.Repeat("0", -exp-1)+digits
+		}
+		if fracPart == "" {
+			fracPart = "0"
+		}
+		return sign + intPart + "." + fracPart
+	}
+
+	fracPart := digits[1:]
+	if fracPart == "" {
+		fracPart = "0"
+	}
+	return sign + digits[:1] + "." + fracPart + "E" + strconv.Itoa(exp)
+}
@@ -0,0 +1,80 @@
+package javap
+
+import (
+	"io"
+	"strings"
+)
+
+const (
+	indentWidth = 2
+	tabColumn   = 40
+)
+
+// lineWriter 模仿javap的LineWriter：空格先挂起，遇到下一个非空字符才真正输出，
+// 所以行尾不会有多余的空格；tab()对齐到当前缩进后的第40列
+type lineWriter struct {
+	out            io.Writer
+	buffer         strings.Builder
+	indentCount    int
+	pendingSpaces  int
+	pendingNewline bool
+	err            error
+}
+
+func (lineWriter *lineWriter) print(s string) {
+	if lineWriter.pendingNewline {
+		lineWriter.pendingNewline = false
+		lineWriter.println()
+	}
+	for _, c := range s {
+		switch c {
+		case ' ':
+			lineWriter.pendingSpaces++
+		case '\n':
+			lineWriter.println()
+		default:
+			if lineWriter.buffer.Len() == 0 {
+				lineWriter.pendingSpaces += lineWriter.indentCount * indentWidth
+			}
+			for ; lineWriter.pendingSpaces > 0; lineWriter.pendingSpaces-- {
+				lineWriter.buffer.WriteByte(' ')
+			}
+			lineWriter.buffer.WriteRune(c)
+		}
+	}
+}
+
+func (lineWriter *lineWriter) println() {
+	lineWriter.pendingSpaces = 0
+	lineWriter.buffer.WriteByte('\n')
+	if lineWriter.err == nil {
+		_, lineWriter.err = io.WriteString(lineWriter.out, lineWriter.buffer.String())
+	}
+	lineWriter.buffer.Reset()
+}
+
+func (lineWriter *lineWriter) printLine(s string) {
+	lineWriter.print(s)
+	lineWriter.println()
+}
+
+// tab 按字符数计算列，和javap一样
+func (lineWriter *lineWriter) tab() {
+	col := lineWriter.indentCount*indentWidth + tabColumn
+	width := len([]rune(lineWriter.buffer.String()))
+	if col <= width {
+		lineWriter.pendingSpaces++
+	} else {
+		lineWriter.pendingSpaces += col - width
+	}
+}
+
+func (lineWriter *lineWriter) indent(delta int) {
+	lineWriter.indentCount += delta
+}
+
+func (lineWriter *lineWriter) printList(prefix string, items []string, suffix string) {
+	lineWriter.print(prefix)
+	lineWriter.print(strings.Join(items, ", "))
+	lineWriter.print(suffix)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+testdata/jmm下是几个多线程的Java程序，检查volatile、final字段、synchronized、
+Thread.start/join和Unsafe的CAS建立的happens-before关系。lib下是各个测试共用的最小类库，
+汇编成jre/lib/rt.jar。程序检查失败时抛出RuntimeException，退出码不是0。
+用go test -race运行时，解释器里没有按JMM同步的读写会被竞争检测器报告
+*/
+func TestMemoryModel(t *testing.T) {
+	cp := testClasspath(t, filepath.Join("testdata", "jmm"))
+	for _, mainClass := range []string{"Volatiles", "Monitors", "UnsafeAtomics", "FinalFields"} {
+		t.Run(mainClass, func(t *testing.T) {
+			loader := heap.NewClassLoaders(cp, nil, nil)
+			if exitCode := interpret(loader, mainClass, nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+				t.Fatalf("exit code %d", exitCode)
+			}
+		})
+	}
+}
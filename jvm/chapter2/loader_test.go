@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+testdata/loader下的Loaders用用户定义的加载器TestLoader定义testdata/loader/defs下的类，
+检查ClassLoader.defineClass的各种错误和加载约束。TestLoader.classBytes由这里注册的本地方法实现，
+defs/bad下的类用bad/加类名取
+*/
+func TestUserDefinedLoader(t *testing.T) {
+	classes := assembleClasses(t, filepath.Join("testdata", "loader", "defs"), asm.Options{})
+	for className, data := range assembleClasses(t, filepath.Join("testdata", "loader", "defs", "bad"), asm.Options{}) {
+		classes["bad/"+className] = data
+	}
+	native.Register("TestLoader", "classBytes", "(Ljava/lang/String;)[B", func(frame *rtda.Frame) {
+		name := strings.Replace(heap.GoString(frame.LocalVars().GetRef(0)), ".", "/", -1)
+		data, ok := classes[name]
+		if !ok {
+			panic("java.lang.ClassNotFoundException: " + name)
+		}
+		bytes := frame.Method().Class().Loader().LoadClass(frame.Thread(), "[B").NewArray(uint(len(data)))
+		for i, b := range data {
+			bytes.Bytes()[i] = int8(b)
+		}
+		frame.OperandStack().PushRef(bytes)
+	})
+
+	loader := newTestLoader(t, filepath.Join("testdata", "loader"), verifyClass)
+	if exitCode := interpret(loader, "Loaders", nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+		t.Fatalf("exit code %d", exitCode)
+	}
+}
@@ -2,9 +2,21 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classdiff"
+	"go.buppt.cn/jvm/chapter2/classfile"
 	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/javap"
+	"go.buppt.cn/jvm/chapter2/native/java/lang"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+	"go.buppt.cn/jvm/chapter2/verifier"
 )
 
 func main() {
@@ -15,6 +27,12 @@ func main() {
 		fmt.Println("version 0.0.1")
 	} else if cmd.helpFlag || cmd.class == "" {
 		printUsage()
+	} else if cmd.javapFlag {
+		startJavap(cmd)
+	} else if cmd.asmFlag {
+		startAsm(cmd)
+	} else if cmd.diffFlag {
+		startDiff(cmd)
 	} else {
 		startJVM(cmd)
 	}
@@ -22,13 +40,143 @@ func main() {
 
 func startJVM(cmd *Cmd) {
 	cp := classpath.Parse(cmd.XjreOption, cmd.cpOption)
-	fmt.Printf("classpath:%v class:%v args:%v\n", cp, cmd.class, cmd.args)
 	className := strings.Replace(cmd.class, ".", "/", -1)
-	_, _, err := cp.ReadClass(className)
+	if _, _, err := cp.ReadClass(className); err != nil {
+		fmt.Printf("Error: Could not find or load main class %s\n", cmd.class)
+		os.Exit(1)
+	}
+	bootVerifier, verifier, ok := classVerifiers(cmd.XverifyOption)
+	if !ok {
+		fmt.Printf("Error: unknown -Xverify option: %s\n", cmd.XverifyOption)
+		os.Exit(1)
+	}
+	if cmd.XmaxDepthOption == 0 || cmd.XmaxDepthOption > rtda.MaxStackDepthLimit {
+		fmt.Printf("Error: invalid -XmaxDepth %d, it must be between 1 and %d\n", cmd.XmaxDepthOption, rtda.MaxStackDepthLimit)
+		os.Exit(1)
+	}
+	lang.SetSystemProperties(systemProperties(cp))
+	loader := heap.NewClassLoaders(cp, bootVerifier, verifier)
+	printThreadDumpOnSigquit()
+	os.Exit(interpret(loader, className, cmd.args, cmd.XmaxDepthOption))
+}
+
+// printThreadDumpOnSigquit 和HotSpot一样，收到SIGQUIT（Ctrl-\）时把线程转储打印到标准输出，然后继续运行
+func printThreadDumpOnSigquit() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGQUIT)
+	go func() {
+		for range signals {
+			lang.PrintThreadDump(os.Stdout, nil)
+		}
+	}()
+}
+
+// classVerifiers -Xverify选项对应的启动类加载器和其他加载器的校验器。
+// 和HotSpot一样默认是remote：启动类加载器加载的类是可信的，不校验
+func classVerifiers(option string) (bootVerifier, verifier heap.Verifier, ok bool) {
+	switch option {
+	case "", "remote":
+		return nil, verifyClass, true
+	case "all":
+		return verifyClass, verifyClass, true
+	case "none":
+		return nil, nil, true
+	case "format":
+		return checkFormat, checkFormat, true
+	}
+	return nil, nil, false
+}
+
+// checkFormat 链接时检查每个类的格式，不通过时像java一样抛出ClassFormatError
+func checkFormat(thread heap.Thread, class *heap.Class) error {
+	return classfile.CheckFormat(class.ClassFile())
+}
+
+// verifyClass 先检查格式，再校验每个方法的字节码，不通过时抛出VerifyError
+func verifyClass(thread heap.Thread, class *heap.Class) error {
+	if err := checkFormat(thread, class); err != nil {
+		return err
+	}
+	return verifier.Verify(thread, class)
+}
+
+// startJavap 和startJVM一样通过Classpath找类，然后按javap的格式打印
+func startJavap(cmd *Cmd) {
+	cp := classpath.ParseOptionalJre(cmd.XjreOption, cmd.cpOption)
+	className := strings.Replace(cmd.class, ".", "/", -1)
+	classData, entry, err := cp.ReadClass(className)
 	if err != nil {
-		fmt.Printf("Could not find or load main class %s\n", cmd.class)
-		return
+		fmt.Printf("Error: class not found: %s\n", cmd.class)
+		os.Exit(1)
+	}
+	location, modTime := classpath.Locate(entry, className)
+	options := javap.Options{Code: cmd.codeFlag, Verbose: cmd.verboseFlag, Private: cmd.privateFlag}
+	if err := javap.Print(os.Stdout, classData, location, modTime, options); err != nil {
+		fmt.Printf("Error: error while reading constant pool for %s: %v\n", cmd.class, err)
+		os.Exit(1)
+	}
+}
+
+// startAsm 汇编命令行上的每个.j文件，class文件按类名写到-d目录下
+func startAsm(cmd *Cmd) {
+	failed := false
+	options := asm.Options{ComputeFrames: cmd.framesFlag}
+	for _, fileName := range append([]string{cmd.class}, cmd.args...) {
+		source, err := os.ReadFile(fileName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			failed = true
+			continue
+		}
+		className, classData, err := asm.Assemble(fileName, source, options)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			failed = true
+			continue
+		}
+		outFile := filepath.Join(cmd.outDir, filepath.FromSlash(className)+".class")
+		if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			failed = true
+			continue
+		}
+		if err := os.WriteFile(outFile, classData, 0644); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// startDiff 比较两个类路径，像diff一样：没有不兼容的变化时退出码为0，有则为1，出错为2
+func startDiff(cmd *Cmd) {
+	if len(cmd.args) == 0 {
+		printUsage()
+		os.Exit(2)
+	}
+	oldCp := classpath.ParseOptionalJre(cmd.XjreOption, cmd.class)
+	newCp := classpath.ParseOptionalJre(cmd.XjreOption, cmd.args[0])
+	var classNames []string
+	for _, className := range cmd.args[1:] {
+		classNames = append(classNames, strings.Replace(className, ".", "/", -1))
+	}
+	report, err := classdiff.Compare(oldCp, newCp, classNames)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	if cmd.jsonFlag {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	if report.Breaking > 0 {
+		os.Exit(1)
 	}
-	fmt.Println("VM starting...")
-	//fmt.Printf("class data:%v\n", classData)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const squareSource = `.source Square.java
+.class public gen/Square
+.super java/lang/Object
+
+.method public static square(I)I
+    iload_0
+    iload_0
+    imul
+    ireturn
+.end method
+
+.method public static main([Ljava/lang/String;)V
+    bipush 12
+    invokestatic gen/Square/square(I)I
+    sipush 144
+    if_icmpne Wrong
+    return
+Wrong:
+    new java/lang/RuntimeException
+    dup
+    ldc "12 * 12 != 144"
+    invokespecial java/lang/RuntimeException/<init>(Ljava/lang/String;)V
+    athrow
+.end method
+`
+
+// TestMemoryEntry 汇编出的类不写文件，直接放到MemoryEntry里加载和执行
+func TestMemoryEntry(t *testing.T) {
+	jreDir := testJRE(t, t.TempDir())
+	className, data, err := asm.Assemble("Square.j", []byte(squareSource), asm.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := classpath.NewMemoryEntry("<memory>")
+	entry.Add(className, data)
+	cp := classpath.Parse(jreDir, t.TempDir())
+	cp.AddEntry(entry)
+
+	loader := heap.NewClassLoaders(cp, nil, nil)
+	class := loader.LoadClass(rtda.NewThread(rtda.DefaultMaxStackDepth), "gen/Square")
+	if class.JavaName() != "gen.Square" || class.SourceFile() != "Square.java" || class.SuperClass().Name() != "java/lang/Object" {
+		t.Errorf("loaded %s extends %s from %s", class.JavaName(), class.SuperClass().Name(), class.SourceFile())
+	}
+	if class.GetStaticMethod("square", "(I)I") == nil {
+		t.Error("square(I)I not found")
+	}
+	if exitCode := interpret(loader, "gen/Square", nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+		t.Fatalf("exit code %d", exitCode)
+	}
+}
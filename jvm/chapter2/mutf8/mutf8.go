@@ -0,0 +1,113 @@
+/*
+Package mutf8 在Java的字符串表示和Go字符串之间转换。
+
+class文件里的字符串是Modified UTF-8（JVMS 4.4.7）：\u0000编码成两个字节，
+增补字符先拆成代理对再分别编码成三个字节。Java的String是UTF-16码元的序列，
+可以包含不成对的代理，Go按UTF-8解码时会把它们变成U+FFFD。
+这里的Go字符串把不成对的代理按三个字节的形式保存（即WTF-8），
+来回转换不丢信息，可以直接用作字符串池等map的键
+*/
+package mutf8
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 代理的范围
+const (
+	surrSelf  = 0x10000
+	surrStart = 0xD800
+	surrEnd   = 0xE000
+)
+
+// Decode 把Modified UTF-8解码成UTF-16码元；遇到非法的字节序列时用U+FFFD代替，不会panic
+func Decode(bytes []byte) []uint16 {
+	chars := make([]uint16, 0, len(bytes))
+	for i := 0; i < len(bytes); {
+		c := bytes[i]
+		switch {
+		case c < 0x80 && c != 0:
+			chars = append(chars, uint16(c))
+			i++
+		case c&0xE0 == 0xC0 && i+1 < len(bytes) && bytes[i+1]&0xC0 == 0x80:
+			chars = append(chars, uint16(c&0x1F)<<6|uint16(bytes[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0 && i+2 < len(bytes) && bytes[i+1]&0xC0 == 0x80 && bytes[i+2]&0xC0 == 0x80:
+			chars = append(chars, uint16(c&0x0F)<<12|uint16(bytes[i+1]&0x3F)<<6|uint16(bytes[i+2]&0x3F))
+			i += 3
+		default:
+			chars = append(chars, utf8.RuneError)
+			i++
+		}
+	}
+	return chars
+}
+
+// Encode 把UTF-16码元编码成Modified UTF-8
+func Encode(chars []uint16) []byte {
+	bytes := make([]byte, 0, len(chars))
+	for _, c := range chars {
+		switch {
+		case c != 0 && c < 0x80:
+			bytes = append(bytes, byte(c))
+		case c < 0x800:
+			bytes = append(bytes, byte(0xC0|c>>6), byte(0x80|c&0x3F))
+		default:
+			bytes = appendThreeBytes(bytes, c)
+		}
+	}
+	return bytes
+}
+
+// ToString 把UTF-16码元转换成Go字符串：代理对合并成增补字符，不成对的代理保留原样
+func ToString(chars []uint16) string {
+	bytes := make([]byte, 0, len(chars))
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
+		switch {
+		case c < surrStart || c >= surrEnd:
+			bytes = utf8.AppendRune(bytes, rune(c))
+		case i+1 < len(chars) && utf16.DecodeRune(rune(c), rune(chars[i+1])) != utf8.RuneError:
+			bytes = utf8.AppendRune(bytes, utf16.DecodeRune(rune(c), rune(chars[i+1])))
+			i++
+		default:
+			bytes = appendThreeBytes(bytes, c)
+		}
+	}
+	return string(bytes)
+}
+
+// FromString 把Go字符串转换成UTF-16码元，是ToString的逆运算；
+// 字符串里不是UTF-8也不是不成对代理的字节变成U+FFFD
+func FromString(s string) []uint16 {
+	chars := make([]uint16, 0, len(s))
+	for i := 0; i < len(s); {
+		if c, ok := surrogateAt(s, i); ok {
+			chars = append(chars, c)
+			i += 3
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r >= surrSelf {
+			r1, r2 := utf16.EncodeRune(r)
+			chars = append(chars, uint16(r1), uint16(r2))
+		} else {
+			chars = append(chars, uint16(r))
+		}
+		i += size
+	}
+	return chars
+}
+
+// surrogateAt s[i:]是不是以按三个字节保存的代理开头
+func surrogateAt(s string, i int) (uint16, bool) {
+	if i+2 >= len(s) || s[i] != 0xED || s[i+1] < 0xA0 || s[i+1] > 0xBF || s[i+2]&0xC0 != 0x80 {
+		return 0, false
+	}
+	return 0xD000 | uint16(s[i+1]&0x3F)<<6 | uint16(s[i+2]&0x3F), true
+}
+
+func appendThreeBytes(bytes []byte, c uint16) []byte {
+	return append(bytes, byte(0xE0|c>>12), byte(0x80|c>>6&0x3F), byte(0x80|c&0x3F))
+}
@@ -0,0 +1,68 @@
+package mutf8
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		chars []uint16
+		bytes []byte
+	}{
+		{[]uint16{'h', 'i'}, []byte("hi")},
+		{[]uint16{0}, []byte{0xC0, 0x80}},
+		{[]uint16{0xE9}, []byte{0xC3, 0xA9}},
+		{[]uint16{0x4E2D}, []byte{0xE4, 0xB8, 0xAD}},
+		// U+1F600，代理对的两个码元分别编码
+		{[]uint16{0xD83D, 0xDE00}, []byte{0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}},
+		// 不成对的代理
+		{[]uint16{'a', 0xD800, 'b'}, []byte{'a', 0xED, 0xA0, 0x80, 'b'}},
+		{[]uint16{0xDC00, 0xD800}, []byte{0xED, 0xB0, 0x80, 0xED, 0xA0, 0x80}},
+	}
+	for _, test := range tests {
+		if got := Encode(test.chars); !bytes.Equal(got, test.bytes) {
+			t.Errorf("Encode(%X) = %X, want %X", test.chars, got, test.bytes)
+		}
+		if got := Decode(test.bytes); !equalChars(got, test.chars) {
+			t.Errorf("Decode(%X) = %X, want %X", test.bytes, got, test.chars)
+		}
+		if got := FromString(ToString(test.chars)); !equalChars(got, test.chars) {
+			t.Errorf("FromString(ToString(%X)) = %X", test.chars, got)
+		}
+	}
+}
+
+func TestToString(t *testing.T) {
+	if s := ToString(utf16.Encode([]rune("中文😀"))); s != "中文😀" {
+		t.Errorf("ToString = %q", s)
+	}
+	// 不成对的代理不能变成U+FFFD，否则不同的Java字符串会对应同一个Go字符串
+	if ToString([]uint16{0xD800}) == ToString([]uint16{0xDBFF}) {
+		t.Error("lone surrogates collapsed")
+	}
+}
+
+func TestDecodeIllegal(t *testing.T) {
+	for _, b := range [][]byte{{0}, {0xC3}, {0xE4, 0xB8}, {0xF0, 0x9F, 0x98, 0x80}} {
+		for _, c := range Decode(b) {
+			if c != 0xFFFD {
+				t.Errorf("Decode(%X) = %X, want U+FFFD", b, Decode(b))
+				break
+			}
+		}
+	}
+}
+
+func equalChars(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package io
+
+import (
+	"os"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jiFileDescriptor = "java/io/FileDescriptor"
+
+func init() {
+	native.Register(jiFileDescriptor, "sync", "()V", sync)
+}
+
+// 还不能打开文件，只有标准输入、标准输出和标准错误三个文件描述符
+var stdFiles = map[int32]*os.File{
+	0: os.Stdin,
+	1: os.Stdout,
+	2: os.Stderr,
+}
+
+// fileOf 返回流对象的fd字段（FileDescriptor）对应的文件
+func fileOf(stream *heap.Object) *os.File {
+	fdObj := stream.GetRefVar("fd", "Ljava/io/FileDescriptor;")
+	if fdObj == nil {
+		panic("java.io.IOException: Stream Closed")
+	}
+	return fdFile(fdObj)
+}
+
+func fdFile(fdObj *heap.Object) *os.File {
+	fd := fdObj.GetIntVar("fd", "I")
+	if fd == -1 {
+		panic("java.io.IOException: Stream Closed")
+	}
+	file, ok := stdFiles[fd]
+	if !ok {
+		panic("java.io.IOException: Bad file descriptor")
+	}
+	return file
+}
+
+// public native void sync() throws SyncFailedException;
+func sync(frame *rtda.Frame) {
+	if err := fdFile(frame.LocalVars().GetThis()).Sync(); err != nil {
+		panic("java.io.SyncFailedException: sync failed")
+	}
+}
+
+// checkBounds 和JDK的io_util.c一样检查数组和下标
+func checkBounds(b *heap.Object, off, length int32) {
+	if b == nil {
+		panic("java.lang.NullPointerException")
+	}
+	if off < 0 || length < 0 || int64(off)+int64(length) > int64(b.ArrayLength()) {
+		panic("java.lang.IndexOutOfBoundsException")
+	}
+}
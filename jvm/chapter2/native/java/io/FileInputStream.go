@@ -0,0 +1,51 @@
+package io
+
+import (
+	"io"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jiFileInputStream = "java/io/FileInputStream"
+
+func init() {
+	native.Register(jiFileInputStream, "readBytes", "([BII)I", readBytes)
+	native.Register(jiFileInputStream, "available", "()I", available)
+	native.Register(jiFileInputStream, "available0", "()I", available)
+}
+
+// private native int readBytes(byte b[], int off, int len) throws IOException;
+// 读到文件末尾时返回-1
+func readBytes(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	b := vars.GetRef(1)
+	off := vars.GetInt(2)
+	length := vars.GetInt(3)
+	checkBounds(b, off, length)
+	if length == 0 {
+		frame.OperandStack().PushInt(0)
+		return
+	}
+	bytes := make([]byte, length)
+	n, err := fileOf(this).Read(bytes)
+	if n == 0 && err == io.EOF {
+		frame.OperandStack().PushInt(-1)
+		return
+	}
+	if err != nil && err != io.EOF {
+		panic("java.io.IOException: " + err.Error())
+	}
+	for i, v := range bytes[:n] {
+		b.Bytes()[int(off)+i] = int8(v)
+	}
+	frame.OperandStack().PushInt(int32(n))
+}
+
+// public native int available() throws IOException;
+// 标准输入不知道还有多少字节，返回0
+func available(frame *rtda.Frame) {
+	fileOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushInt(0)
+}
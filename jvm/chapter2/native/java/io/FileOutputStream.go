@@ -0,0 +1,40 @@
+package io
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jiFileOutputStream = "java/io/FileOutputStream"
+
+func init() {
+	native.Register(jiFileOutputStream, "writeBytes", "([BIIZ)V", writeBytes)
+	native.Register(jiFileOutputStream, "write", "(IZ)V", write)
+}
+
+// private native void writeBytes(byte b[], int off, int len, boolean append) throws IOException;
+func writeBytes(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	b := vars.GetRef(1)
+	off := vars.GetInt(2)
+	length := vars.GetInt(3)
+	checkBounds(b, off, length)
+	bytes := make([]byte, length)
+	for i, v := range b.Bytes()[off : off+length] {
+		bytes[i] = byte(v)
+	}
+	if _, err := fileOf(this).Write(bytes); err != nil {
+		panic("java.io.IOException: " + err.Error())
+	}
+}
+
+// private native void write(int b, boolean append) throws IOException;
+func write(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	b := vars.GetInt(1)
+	if _, err := fileOf(this).Write([]byte{byte(b)}); err != nil {
+		panic("java.io.IOException: " + err.Error())
+	}
+}
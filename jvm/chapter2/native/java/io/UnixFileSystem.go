@@ -0,0 +1,127 @@
+package io
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jiUnixFileSystem = "java/io/UnixFileSystem"
+
+func init() {
+	native.Register(jiUnixFileSystem, "canonicalize0", "(Ljava/lang/String;)Ljava/lang/String;", canonicalize0)
+	native.Register(jiUnixFileSystem, "getBooleanAttributes0", "(Ljava/io/File;)I", getBooleanAttributes0)
+	native.Register(jiUnixFileSystem, "checkAccess", "(Ljava/io/File;I)Z", checkAccess)
+	native.Register(jiUnixFileSystem, "getLastModifiedTime", "(Ljava/io/File;)J", getLastModifiedTime)
+	native.Register(jiUnixFileSystem, "getLength", "(Ljava/io/File;)J", getLength)
+	native.Register(jiUnixFileSystem, "list", "(Ljava/io/File;)[Ljava/lang/String;", list)
+}
+
+// java.io.FileSystem里的常量
+const (
+	baExists    = 0x01
+	baRegular   = 0x02
+	baDirectory = 0x04
+
+	accessExecute = 0x01
+	accessWrite   = 0x02
+	accessRead    = 0x04
+)
+
+// File对象的path字段
+func pathOf(file *heap.Object) string {
+	return heap.GoString(file.GetRefVar("path", "Ljava/lang/String;"))
+}
+
+// private native String canonicalize0(String path) throws IOException;
+// 解析符号链接；路径不存在时只去掉.和..
+func canonicalize0(frame *rtda.Frame) {
+	path := heap.GoString(frame.LocalVars().GetRef(1))
+	canonical, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		canonical = filepath.Clean(path)
+	}
+	if absPath, err := filepath.Abs(canonical); err == nil {
+		canonical = absPath
+	}
+	frame.OperandStack().PushRef(heap.JString(frame.Thread(), frame.Method().Class().Loader(), canonical))
+}
+
+// public native int getBooleanAttributes0(File f);
+// 隐藏文件由Java代码按文件名判断
+func getBooleanAttributes0(frame *rtda.Frame) {
+	info, err := os.Stat(pathOf(frame.LocalVars().GetRef(1)))
+	var attributes int32
+	if err == nil {
+		attributes = baExists
+		if info.Mode().IsRegular() {
+			attributes |= baRegular
+		}
+		if info.IsDir() {
+			attributes |= baDirectory
+		}
+	}
+	frame.OperandStack().PushInt(attributes)
+}
+
+// public native boolean checkAccess(File f, int access);
+// 只看权限位：属主、同组和其他用户中任何一个有权限就算有
+func checkAccess(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	info, err := os.Stat(pathOf(vars.GetRef(1)))
+	var mode os.FileMode
+	switch vars.GetInt(2) {
+	case accessRead:
+		mode = 0444
+	case accessWrite:
+		mode = 0222
+	case accessExecute:
+		mode = 0111
+	}
+	if err == nil && info.Mode().Perm()&mode != 0 {
+		frame.OperandStack().PushInt(1)
+	} else {
+		frame.OperandStack().PushInt(0)
+	}
+}
+
+// public native long getLastModifiedTime(File f);
+// 文件不存在时返回0
+func getLastModifiedTime(frame *rtda.Frame) {
+	var millis int64
+	if info, err := os.Stat(pathOf(frame.LocalVars().GetRef(1))); err == nil {
+		millis = info.ModTime().UnixNano() / 1e6
+	}
+	frame.OperandStack().PushLong(millis)
+}
+
+// public native long getLength(File f);
+func getLength(frame *rtda.Frame) {
+	var length int64
+	if info, err := os.Stat(pathOf(frame.LocalVars().GetRef(1))); err == nil {
+		length = info.Size()
+	}
+	frame.OperandStack().PushLong(length)
+}
+
+// public native String[] list(File f);
+// 不是目录或者不能读时返回null
+func list(frame *rtda.Frame) {
+	thread := frame.Thread()
+	infos, err := ioutil.ReadDir(pathOf(frame.LocalVars().GetRef(1)))
+	if err != nil {
+		frame.OperandStack().PushRef(nil)
+		return
+	}
+	loader := frame.Method().Class().Loader()
+	stringClass := loader.Bootstrap().LoadClass(thread, "java/lang/String")
+	names := stringClass.ArrayClass(thread).NewArray(uint(len(infos)))
+	for i, info := range infos {
+		names.Refs()[i] = heap.JString(thread, loader, info.Name())
+	}
+	frame.OperandStack().PushRef(names)
+}
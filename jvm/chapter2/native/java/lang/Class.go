@@ -0,0 +1,283 @@
+package lang
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlClass = "java/lang/Class"
+
+func init() {
+	native.Register(jlClass, "getPrimitiveClass", "(Ljava/lang/String;)Ljava/lang/Class;", getPrimitiveClass)
+	native.Register(jlClass, "getName0", "()Ljava/lang/String;", getName0)
+	native.Register(jlClass, "desiredAssertionStatus0", "(Ljava/lang/Class;)Z", desiredAssertionStatus0)
+	native.Register(jlClass, "forName0", "(Ljava/lang/String;ZLjava/lang/ClassLoader;Ljava/lang/Class;)Ljava/lang/Class;", forName0)
+	native.Register(jlClass, "isInterface", "()Z", isInterface)
+	native.Register(jlClass, "isArray", "()Z", isArray)
+	native.Register(jlClass, "isPrimitive", "()Z", isPrimitive)
+	native.Register(jlClass, "isInstance", "(Ljava/lang/Object;)Z", isInstance)
+	native.Register(jlClass, "isAssignableFrom", "(Ljava/lang/Class;)Z", isAssignableFrom)
+	native.Register(jlClass, "getSuperclass", "()Ljava/lang/Class;", getSuperclass)
+	native.Register(jlClass, "getInterfaces0", "()[Ljava/lang/Class;", getInterfaces0)
+	native.Register(jlClass, "getComponentType", "()Ljava/lang/Class;", getComponentType)
+	native.Register(jlClass, "getModifiers", "()I", getModifiers)
+	native.Register(jlClass, "getClassLoader0", "()Ljava/lang/ClassLoader;", getClassLoader0)
+	native.Register(jlClass, "getDeclaredFields0", "(Z)[Ljava/lang/reflect/Field;", getDeclaredFields0)
+	native.Register(jlClass, "getDeclaredMethods0", "(Z)[Ljava/lang/reflect/Method;", getDeclaredMethods0)
+	native.Register(jlClass, "getDeclaredConstructors0", "(Z)[Ljava/lang/reflect/Constructor;", getDeclaredConstructors0)
+}
+
+// 反射对象的modifiers只保留Java语言里有意义的访问标志，和HotSpot的
+// JVM_RECOGNIZED_FIELD_MODIFIERS、JVM_RECOGNIZED_METHOD_MODIFIERS一样
+const (
+	fieldModifiers  = 0x50df
+	methodModifiers = 0x1dff
+)
+
+// Class对象的extra是它表示的类
+func classOf(jClass *heap.Object) *heap.Class {
+	return jClass.Extra().(*heap.Class)
+}
+
+// jClassOf 类是nil时返回null
+func jClassOf(thread *rtda.Thread, class *heap.Class) *heap.Object {
+	if class == nil {
+		return nil
+	}
+	return class.JClass(thread)
+}
+
+func pushBoolean(frame *rtda.Frame, b bool) {
+	if b {
+		frame.OperandStack().PushInt(1)
+	} else {
+		frame.OperandStack().PushInt(0)
+	}
+}
+
+// static native Class<?> getPrimitiveClass(String name);
+func getPrimitiveClass(frame *rtda.Frame) {
+	name := heap.GoString(frame.LocalVars().GetRef(0))
+	class := frame.Method().Class().Loader().PrimitiveClass(name)
+	if class == nil {
+		panic("java.lang.IllegalArgumentException: " + name)
+	}
+	frame.OperandStack().PushRef(class.JClass(frame.Thread()))
+}
+
+// private native String getName0();
+func getName0(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushRef(heap.JString(frame.Thread(), class.Loader(), class.JavaName()))
+}
+
+// private static native boolean desiredAssertionStatus0(Class<?> clazz);
+// 不支持-ea，断言总是关闭
+func desiredAssertionStatus0(frame *rtda.Frame) {
+	pushBoolean(frame, false)
+}
+
+// private static native Class<?> forName0(String name, boolean initialize, ClassLoader loader, Class<?> caller);
+// name是Java形式的类名，数组类是[Ljava.lang.String;这样的
+func forName0(frame *rtda.Frame) {
+	thread := frame.Thread()
+	vars := frame.LocalVars()
+	jName := vars.GetRef(0)
+	initialize := vars.GetInt(1) != 0
+	javaLoader := vars.GetRef(2)
+	if jName == nil {
+		panic("java.lang.NullPointerException")
+	}
+	name := heap.GoString(jName)
+	var class *heap.Class
+	if !strings.Contains(name, "/") {
+		loader := frame.Method().Class().Loader().LoaderOf(thread, javaLoader)
+		class = loader.FindClass(thread, strings.Replace(name, ".", "/", -1))
+	}
+	if class == nil || class.IsPrimitive() {
+		panic("java.lang.ClassNotFoundException: " + name)
+	}
+	if initialize {
+		base.InitClass(thread, class)
+	}
+	frame.OperandStack().PushRef(class.JClass(thread))
+}
+
+// public native boolean isInterface();
+func isInterface(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	pushBoolean(frame, class.IsInterface())
+}
+
+// public native boolean isArray();
+func isArray(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	pushBoolean(frame, class.IsArray())
+}
+
+// public native boolean isPrimitive();
+func isPrimitive(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	pushBoolean(frame, class.IsPrimitive())
+}
+
+// public native boolean isInstance(Object obj);
+func isInstance(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	class := classOf(vars.GetThis())
+	obj := vars.GetRef(1)
+	pushBoolean(frame, obj != nil && obj.IsInstanceOf(class))
+}
+
+// public native boolean isAssignableFrom(Class<?> cls);
+func isAssignableFrom(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	class := classOf(vars.GetThis())
+	cls := vars.GetRef(1)
+	if cls == nil {
+		panic("java.lang.NullPointerException")
+	}
+	pushBoolean(frame, class.IsAssignableFrom(classOf(cls)))
+}
+
+// public native Class<? super T> getSuperclass();
+// 接口和基本类型没有超类
+func getSuperclass(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	var superClass *heap.Class
+	if !class.IsInterface() {
+		superClass = class.SuperClass()
+	}
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), superClass))
+}
+
+// private native Class<?>[] getInterfaces0();
+func getInterfaces0(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushRef(jClassArray(frame.Thread(), class.Loader(), class.Interfaces()))
+}
+
+// jClassArray 创建元素是classes的Class对象的Class[]
+func jClassArray(thread *rtda.Thread, loader *heap.ClassLoader, classes []*heap.Class) *heap.Object {
+	jlClassClass := loader.Bootstrap().LoadClass(thread, jlClass)
+	jClasses := jlClassClass.ArrayClass(thread).NewArray(uint(len(classes)))
+	for i, class := range classes {
+		jClasses.Refs()[i] = class.JClass(thread)
+	}
+	return jClasses
+}
+
+// public native Class<?> getComponentType();
+func getComponentType(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), class.ComponentClass()))
+}
+
+// public native int getModifiers();
+func getModifiers(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushInt(class.Modifiers())
+}
+
+// native ClassLoader getClassLoader0();
+// 启动类加载器加载的类返回null
+func getClassLoader0(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetThis())
+	frame.OperandStack().PushRef(class.Loader().JavaLoader(frame.Thread()))
+}
+
+/*
+反射对象和HotSpot一样直接设置字段，不执行构造函数。slot是成员在类的Fields()或者Methods()里的下标，
+Unsafe.objectFieldOffset和反射调用通过clazz和slot找回对应的成员。
+成员名来自字符串池，Class.searchFields等用==比较intern以后的名字
+*/
+
+// private native Field[] getDeclaredFields0(boolean publicOnly);
+func getDeclaredFields0(frame *rtda.Frame) {
+	thread := frame.Thread()
+	vars := frame.LocalVars()
+	class := classOf(vars.GetThis())
+	publicOnly := vars.GetInt(1) != 0
+	fieldClass := reflectClass(frame, "java/lang/reflect/Field")
+	var jFields []*heap.Object
+	for slot, field := range class.Fields() {
+		if publicOnly && !field.IsPublic() {
+			continue
+		}
+		jField := fieldClass.NewObject()
+		jField.SetRefVar("clazz", "Ljava/lang/Class;", class.JClass(thread))
+		jField.SetIntVar("slot", "I", int32(slot))
+		jField.SetRefVar("name", "Ljava/lang/String;", heap.InternedString(thread, class.Loader(), field.Name()))
+		jField.SetRefVar("type", "Ljava/lang/Class;", field.Type(thread).JClass(thread))
+		jField.SetIntVar("modifiers", "I", int32(field.AccessFlags()&fieldModifiers))
+		jFields = append(jFields, jField)
+	}
+	frame.OperandStack().PushRef(jObjectArray(thread, fieldClass, jFields))
+}
+
+// private native Method[] getDeclaredMethods0(boolean publicOnly);
+// 不包括构造函数和类初始化方法
+func getDeclaredMethods0(frame *rtda.Frame) {
+	thread := frame.Thread()
+	vars := frame.LocalVars()
+	class := classOf(vars.GetThis())
+	publicOnly := vars.GetInt(1) != 0
+	methodClass := reflectClass(frame, "java/lang/reflect/Method")
+	var jMethods []*heap.Object
+	for slot, method := range class.Methods() {
+		if (publicOnly && !method.IsPublic()) || method.Name() == "<init>" || method.Name() == "<clinit>" {
+			continue
+		}
+		jMethod := methodClass.NewObject()
+		jMethod.SetRefVar("clazz", "Ljava/lang/Class;", class.JClass(thread))
+		jMethod.SetIntVar("slot", "I", int32(slot))
+		jMethod.SetRefVar("name", "Ljava/lang/String;", heap.InternedString(thread, class.Loader(), method.Name()))
+		jMethod.SetRefVar("returnType", "Ljava/lang/Class;", method.ReturnType(thread).JClass(thread))
+		jMethod.SetRefVar("parameterTypes", "[Ljava/lang/Class;", jClassArray(thread, class.Loader(), method.ParameterTypes(thread)))
+		jMethod.SetRefVar("exceptionTypes", "[Ljava/lang/Class;", jClassArray(thread, class.Loader(), method.ExceptionTypes(thread)))
+		jMethod.SetIntVar("modifiers", "I", int32(method.AccessFlags()&methodModifiers))
+		jMethods = append(jMethods, jMethod)
+	}
+	frame.OperandStack().PushRef(jObjectArray(thread, methodClass, jMethods))
+}
+
+// private native Constructor<T>[] getDeclaredConstructors0(boolean publicOnly);
+func getDeclaredConstructors0(frame *rtda.Frame) {
+	thread := frame.Thread()
+	vars := frame.LocalVars()
+	class := classOf(vars.GetThis())
+	publicOnly := vars.GetInt(1) != 0
+	constructorClass := reflectClass(frame, "java/lang/reflect/Constructor")
+	var jConstructors []*heap.Object
+	for slot, method := range class.Methods() {
+		if (publicOnly && !method.IsPublic()) || method.Name() != "<init>" {
+			continue
+		}
+		jConstructor := constructorClass.NewObject()
+		jConstructor.SetRefVar("clazz", "Ljava/lang/Class;", class.JClass(thread))
+		jConstructor.SetIntVar("slot", "I", int32(slot))
+		jConstructor.SetRefVar("parameterTypes", "[Ljava/lang/Class;", jClassArray(thread, class.Loader(), method.ParameterTypes(thread)))
+		jConstructor.SetRefVar("exceptionTypes", "[Ljava/lang/Class;", jClassArray(thread, class.Loader(), method.ExceptionTypes(thread)))
+		jConstructor.SetIntVar("modifiers", "I", int32(method.AccessFlags()&methodModifiers))
+		jConstructors = append(jConstructors, jConstructor)
+	}
+	frame.OperandStack().PushRef(jObjectArray(thread, constructorClass, jConstructors))
+}
+
+// reflectClass 加载并初始化java.lang.reflect里的类
+func reflectClass(frame *rtda.Frame, name string) *heap.Class {
+	class := frame.Method().Class().Loader().Bootstrap().LoadClass(frame.Thread(), name)
+	base.InitClass(frame.Thread(), class)
+	return class
+}
+
+// jObjectArray 创建元素类型是componentClass、元素是objects的数组
+func jObjectArray(thread *rtda.Thread, componentClass *heap.Class, objects []*heap.Object) *heap.Object {
+	jArray := componentClass.ArrayClass(thread).NewArray(uint(len(objects)))
+	copy(jArray.Refs(), objects)
+	return jArray
+}
@@ -0,0 +1,127 @@
+package lang
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlClassLoader = "java/lang/ClassLoader"
+
+func init() {
+	native.Register(jlClassLoader, "defineClass0", "(Ljava/lang/String;[BIILjava/security/ProtectionDomain;)Ljava/lang/Class;", defineClass)
+	native.Register(jlClassLoader, "defineClass1", "(Ljava/lang/String;[BIILjava/security/ProtectionDomain;Ljava/lang/String;)Ljava/lang/Class;", defineClass)
+	native.Register(jlClassLoader, "findLoadedClass0", "(Ljava/lang/String;)Ljava/lang/Class;", findLoadedClass0)
+	native.Register(jlClassLoader, "findBootstrapClass", "(Ljava/lang/String;)Ljava/lang/Class;", findBootstrapClass)
+	native.Register(jlClassLoader, "resolveClass0", "(Ljava/lang/Class;)V", resolveClass0)
+	native.Register(jlClassLoader, "findBuiltinLib", "(Ljava/lang/String;)Ljava/lang/String;", findBuiltinLib)
+	native.Register(jlNativeLibrary, "load", "(Ljava/lang/String;Z)V", loadLibrary)
+	native.Register(jlNativeLibrary, "load", "(Ljava/lang/String;)V", loadLibrary)
+	native.Register(jlNativeLibrary, "find", "(Ljava/lang/String;)J", findEntry)
+	native.Register(jlNativeLibrary, "unload", "(Ljava/lang/String;Z)V", unloadLibrary)
+}
+
+const jlNativeLibrary = "java/lang/ClassLoader$NativeLibrary"
+
+// 加载器对象对应的类加载器，第一次用到时创建
+func loaderOf(frame *rtda.Frame) *heap.ClassLoader {
+	this := frame.LocalVars().GetThis()
+	return frame.Method().Class().Loader().LoaderOf(frame.Thread(), this)
+}
+
+// Java代码传来的类名是a.b.C形式，换成内部形式
+func internalName(jName *heap.Object) string {
+	return strings.Replace(heap.GoString(jName), ".", "/", -1)
+}
+
+// private native Class<?> defineClass0(String name, byte[] b, int off, int len, ProtectionDomain pd);
+// private native Class<?> defineClass1(String name, byte[] b, int off, int len, ProtectionDomain pd, String source);
+// 保护域和来源不记录
+func defineClass(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	jName := vars.GetRef(1)
+	b := vars.GetRef(2)
+	off := vars.GetInt(3)
+	length := vars.GetInt(4)
+	if b == nil {
+		panic("java.lang.NullPointerException")
+	}
+	bytes := b.Bytes()
+	if off < 0 || length < 0 || int64(off)+int64(length) > int64(len(bytes)) {
+		panic("java.lang.ArrayIndexOutOfBoundsException")
+	}
+	data := make([]byte, length)
+	for i := range data {
+		data[i] = byte(bytes[off+int32(i)])
+	}
+	var name string
+	if jName != nil {
+		name = internalName(jName)
+	}
+	class := loaderOf(frame).DefineClass(frame.Thread(), name, data)
+	frame.OperandStack().PushRef(class.JClass(frame.Thread()))
+}
+
+// private native final Class<?> findLoadedClass0(String name);
+func findLoadedClass0(frame *rtda.Frame) {
+	jName := frame.LocalVars().GetRef(1)
+	var class *heap.Class
+	if jName != nil {
+		class = loaderOf(frame).FindLoadedClass(frame.Thread(), internalName(jName))
+	}
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), class))
+}
+
+// private native Class<?> findBootstrapClass(String name);
+func findBootstrapClass(frame *rtda.Frame) {
+	jName := frame.LocalVars().GetRef(1)
+	var class *heap.Class
+	if jName != nil {
+		class = loaderOf(frame).FindBootstrapClass(frame.Thread(), internalName(jName))
+	}
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), class))
+}
+
+// private native void resolveClass0(Class<?> c);
+// 类在加载时已经链接好了
+func resolveClass0(frame *rtda.Frame) {
+	if frame.LocalVars().GetRef(1) == nil {
+		panic("java.lang.NullPointerException")
+	}
+}
+
+// private static native String findBuiltinLib(String name);
+// 类库的本地方法都由虚拟机用Go实现，所有的库都当作静态链接的内置库：
+// 文件名libzip.so去掉前后缀得到库名zip
+func findBuiltinLib(frame *rtda.Frame) {
+	jName := frame.LocalVars().GetRef(0)
+	if jName == nil {
+		panic("java.lang.Error: NULL filename for native library")
+	}
+	name := heap.GoString(jName)
+	name = strings.TrimPrefix(name, "lib")
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	frame.OperandStack().PushRef(heap.JString(frame.Thread(), frame.Method().Class().Loader(), name))
+}
+
+// native void load(String name, boolean isBuiltin);
+// 没有要加载的动态库，直接标记成已加载
+func loadLibrary(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	this.SetIntVar("loaded", "Z", 1)
+}
+
+// native long find(String name);
+// 没有动态库，找不到任何符号
+func findEntry(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(0)
+}
+
+// native void unload(String name, boolean isBuiltin);
+func unloadLibrary(frame *rtda.Frame) {
+	// do nothing
+}
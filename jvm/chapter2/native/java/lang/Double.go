@@ -0,0 +1,26 @@
+package lang
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jlDouble = "java/lang/Double"
+
+// double和long一样占两个槽位，按位存放，直接搬运位模式
+func init() {
+	native.Register(jlDouble, "doubleToRawLongBits", "(D)J", doubleToRawLongBits)
+	native.Register(jlDouble, "longBitsToDouble", "(J)D", longBitsToDouble)
+}
+
+// public static native long doubleToRawLongBits(double value);
+func doubleToRawLongBits(frame *rtda.Frame) {
+	bits := frame.LocalVars().GetLong(0)
+	frame.OperandStack().PushLong(bits)
+}
+
+// public static native double longBitsToDouble(long bits);
+func longBitsToDouble(frame *rtda.Frame) {
+	bits := frame.LocalVars().GetLong(0)
+	frame.OperandStack().PushLong(bits)
+}
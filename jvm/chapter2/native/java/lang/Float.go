@@ -0,0 +1,26 @@
+package lang
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jlFloat = "java/lang/Float"
+
+// float在局部变量表和操作数栈里本来就按位存成int，直接搬运位模式，NaN的各个位也不变
+func init() {
+	native.Register(jlFloat, "floatToRawIntBits", "(F)I", floatToRawIntBits)
+	native.Register(jlFloat, "intBitsToFloat", "(I)F", intBitsToFloat)
+}
+
+// public static native int floatToRawIntBits(float value);
+func floatToRawIntBits(frame *rtda.Frame) {
+	bits := frame.LocalVars().GetInt(0)
+	frame.OperandStack().PushInt(bits)
+}
+
+// public static native float intBitsToFloat(int bits);
+func intBitsToFloat(frame *rtda.Frame) {
+	bits := frame.LocalVars().GetInt(0)
+	frame.OperandStack().PushInt(bits)
+}
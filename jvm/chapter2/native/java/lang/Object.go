@@ -0,0 +1,68 @@
+package lang
+
+import (
+	"unsafe"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlObject = "java/lang/Object"
+
+func init() {
+	native.Register(jlObject, "getClass", "()Ljava/lang/Class;", getClass)
+	native.Register(jlObject, "hashCode", "()I", hashCode)
+	native.Register(jlObject, "clone", "()Ljava/lang/Object;", clone)
+	native.Register(jlObject, "wait", "(J)V", wait)
+	native.Register(jlObject, "notify", "()V", notify)
+	native.Register(jlObject, "notifyAll", "()V", notifyAll)
+}
+
+// public final native Class<?> getClass();
+func getClass(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushRef(this.Class().JClass(frame.Thread()))
+}
+
+// public native int hashCode();
+func hashCode(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushInt(identityHashCode(this))
+}
+
+// 对象的地址在生命周期里不变，取低32位做标识哈希码
+func identityHashCode(object *heap.Object) int32 {
+	if object == nil {
+		return 0
+	}
+	return int32(uintptr(unsafe.Pointer(object)))
+}
+
+// protected native Object clone() throws CloneNotSupportedException;
+func clone(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	cloneable := this.Class().Loader().Bootstrap().LoadClass(frame.Thread(), "java/lang/Cloneable")
+	if !this.IsInstanceOf(cloneable) {
+		panic("java.lang.CloneNotSupportedException: " + this.Class().JavaName())
+	}
+	frame.OperandStack().PushRef(this.Clone())
+}
+
+// public final native void wait(long timeout) throws InterruptedException;
+// 释放监视器，等到notify、超时或者被中断，再重新进入监视器
+func wait(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	rtda.Wait(frame.Thread(), vars.GetThis(), vars.GetLong(1))
+}
+
+// public final native void notify();
+// 唤醒等待最久的线程
+func notify(frame *rtda.Frame) {
+	rtda.Notify(frame.Thread(), frame.LocalVars().GetThis(), false)
+}
+
+// public final native void notifyAll();
+func notifyAll(frame *rtda.Frame) {
+	rtda.Notify(frame.Thread(), frame.LocalVars().GetThis(), true)
+}
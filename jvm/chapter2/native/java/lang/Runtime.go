@@ -0,0 +1,50 @@
+package lang
+
+import (
+	"math"
+	"runtime"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jlRuntime = "java/lang/Runtime"
+
+func init() {
+	native.Register(jlRuntime, "availableProcessors", "()I", availableProcessors)
+	native.Register(jlRuntime, "freeMemory", "()J", freeMemory)
+	native.Register(jlRuntime, "totalMemory", "()J", totalMemory)
+	native.Register(jlRuntime, "maxMemory", "()J", maxMemory)
+	native.Register(jlRuntime, "gc", "()V", gc)
+}
+
+// public native int availableProcessors();
+func availableProcessors(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(int32(runtime.NumCPU()))
+}
+
+// public native long freeMemory();
+// Java堆就是Go的堆：向操作系统申请了但还没有用掉的部分
+func freeMemory(frame *rtda.Frame) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	frame.OperandStack().PushLong(int64(stats.HeapSys - stats.HeapAlloc))
+}
+
+// public native long totalMemory();
+func totalMemory(frame *rtda.Frame) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	frame.OperandStack().PushLong(int64(stats.HeapSys))
+}
+
+// public native long maxMemory();
+// 堆的大小没有上限，和JDK约定的一样返回Long.MAX_VALUE
+func maxMemory(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(math.MaxInt64)
+}
+
+// public native void gc();
+func gc(frame *rtda.Frame) {
+	runtime.GC()
+}
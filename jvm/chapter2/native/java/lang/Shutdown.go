@@ -0,0 +1,26 @@
+package lang
+
+import (
+	"os"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+const jlShutdown = "java/lang/Shutdown"
+
+func init() {
+	native.Register(jlShutdown, "beforeHalt", "()V", beforeHalt)
+	native.Register(jlShutdown, "halt0", "(I)V", halt0)
+}
+
+// static native void beforeHalt();
+func beforeHalt(frame *rtda.Frame) {
+	// do nothing
+}
+
+// static native void halt0(int status);
+// Runtime.exit执行完关闭钩子以后调用，结束进程
+func halt0(frame *rtda.Frame) {
+	os.Exit(int(frame.LocalVars().GetInt(0)))
+}
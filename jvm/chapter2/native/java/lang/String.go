@@ -0,0 +1,29 @@
+package lang
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const (
+	jlString      = "java/lang/String"
+	jlStringUTF16 = "java/lang/StringUTF16"
+)
+
+func init() {
+	native.Register(jlString, "intern", "()Ljava/lang/String;", intern)
+	native.Register(jlStringUTF16, "isBigEndian", "()Z", isBigEndian)
+}
+
+// public native String intern();
+func intern(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushRef(heap.InternString(frame.Thread(), this))
+}
+
+// private static native boolean isBigEndian();
+// JDK 9以后UTF16编码的String按本机字节序保存字符，虚拟机创建的字符串总是小端，见heap.JStringFromChars
+func isBigEndian(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(0)
+}
@@ -0,0 +1,152 @@
+package lang
+
+import (
+	"runtime"
+	"sort"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlSystem = "java/lang/System"
+
+func init() {
+	native.Register(jlSystem, "arraycopy", "(Ljava/lang/Object;ILjava/lang/Object;II)V", arraycopy)
+	native.Register(jlSystem, "identityHashCode", "(Ljava/lang/Object;)I", systemIdentityHashCode)
+	native.Register(jlSystem, "nanoTime", "()J", nanoTime)
+	native.Register(jlSystem, "currentTimeMillis", "()J", currentTimeMillis)
+	native.Register(jlSystem, "initProperties", "(Ljava/util/Properties;)Ljava/util/Properties;", initProperties)
+	native.Register(jlSystem, "setIn0", "(Ljava/io/InputStream;)V", setIn0)
+	native.Register(jlSystem, "setOut0", "(Ljava/io/PrintStream;)V", setOut0)
+	native.Register(jlSystem, "setErr0", "(Ljava/io/PrintStream;)V", setErr0)
+	native.Register(jlSystem, "mapLibraryName", "(Ljava/lang/String;)Ljava/lang/String;", mapLibraryName)
+}
+
+// 虚拟机启动时确定的系统属性，initProperties把它们放进System.props
+var systemProperties map[string]string
+
+// SetSystemProperties 设置System.initProperties提供的系统属性，要在执行Java代码之前调用
+func SetSystemProperties(props map[string]string) {
+	systemProperties = props
+}
+
+// public static native void arraycopy(Object src, int srcPos, Object dest, int destPos, int length);
+func arraycopy(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	src := vars.GetRef(0)
+	srcPos := vars.GetInt(1)
+	dest := vars.GetRef(2)
+	destPos := vars.GetInt(3)
+	length := vars.GetInt(4)
+
+	if src == nil || dest == nil {
+		panic("java.lang.NullPointerException")
+	}
+	srcClass, destClass := src.Class(), dest.Class()
+	if !srcClass.IsArray() || !destClass.IsArray() {
+		panic("java.lang.ArrayStoreException")
+	}
+	srcComponent, destComponent := srcClass.ComponentClass(), destClass.ComponentClass()
+	if (srcComponent.IsPrimitive() || destComponent.IsPrimitive()) && srcComponent != destComponent {
+		panic("java.lang.ArrayStoreException")
+	}
+	if srcPos < 0 || destPos < 0 || length < 0 ||
+		int64(srcPos)+int64(length) > int64(src.ArrayLength()) ||
+		int64(destPos)+int64(length) > int64(dest.ArrayLength()) {
+		panic("java.lang.ArrayIndexOutOfBoundsException")
+	}
+	if srcComponent.IsPrimitive() || destComponent.IsAssignableFrom(srcComponent) {
+		heap.ArrayCopy(src, dest, srcPos, destPos, length)
+		return
+	}
+	// 元素类型不兼容时逐个检查，遇到不能存入的元素时已经复制的保留
+	srcRefs, destRefs := src.Refs(), dest.Refs()
+	for i := int32(0); i < length; i++ {
+		ref := srcRefs[srcPos+i]
+		if ref != nil && !ref.IsInstanceOf(destComponent) {
+			panic("java.lang.ArrayStoreException")
+		}
+		destRefs[destPos+i] = ref
+	}
+}
+
+// public static native int identityHashCode(Object x);
+func systemIdentityHashCode(frame *rtda.Frame) {
+	x := frame.LocalVars().GetRef(0)
+	frame.OperandStack().PushInt(identityHashCode(x))
+}
+
+// 虚拟机启动时的单调时钟读数，nanoTime从它开始计时
+var startTime = time.Now()
+
+// public static native long nanoTime();
+func nanoTime(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(int64(time.Since(startTime)))
+}
+
+// public static native long currentTimeMillis();
+func currentTimeMillis(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+// private static native Properties initProperties(Properties props);
+// 按键的顺序调用props.setProperty，返回props
+func initProperties(frame *rtda.Frame) {
+	thread := frame.Thread()
+	props := frame.LocalVars().GetRef(0)
+	setProperty := props.Class().LookupInstanceMethod("setProperty", "(Ljava/lang/String;Ljava/lang/String;)Ljava/lang/Object;")
+	if setProperty == nil {
+		panic("java.lang.NoSuchMethodError: " + props.Class().JavaName() + ".setProperty")
+	}
+	keys := make([]string, 0, len(systemProperties))
+	for key := range systemProperties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	loader := frame.Method().Class().Loader()
+	for _, key := range keys {
+		jKey := heap.JString(thread, loader, key)
+		jValue := heap.JString(thread, loader, systemProperties[key])
+		base.RunMethod(thread, setProperty, props, jKey, jValue)
+	}
+	frame.OperandStack().PushRef(props)
+}
+
+// private static native void setIn0(InputStream in);
+func setIn0(frame *rtda.Frame) {
+	in := frame.LocalVars().GetRef(0)
+	frame.Method().Class().SetStaticRefVar("in", "Ljava/io/InputStream;", in)
+}
+
+// private static native void setOut0(PrintStream out);
+func setOut0(frame *rtda.Frame) {
+	out := frame.LocalVars().GetRef(0)
+	frame.Method().Class().SetStaticRefVar("out", "Ljava/io/PrintStream;", out)
+}
+
+// private static native void setErr0(PrintStream err);
+func setErr0(frame *rtda.Frame) {
+	err := frame.LocalVars().GetRef(0)
+	frame.Method().Class().SetStaticRefVar("err", "Ljava/io/PrintStream;", err)
+}
+
+// public static native String mapLibraryName(String libname);
+func mapLibraryName(frame *rtda.Frame) {
+	libname := frame.LocalVars().GetRef(0)
+	if libname == nil {
+		panic("java.lang.NullPointerException")
+	}
+	name := heap.GoString(libname)
+	switch runtime.GOOS {
+	case "windows":
+		name = name + ".dll"
+	case "darwin":
+		name = "lib" + name + ".dylib"
+	default:
+		name = "lib" + name + ".so"
+	}
+	frame.OperandStack().PushRef(heap.JString(frame.Thread(), frame.Method().Class().Loader(), name))
+}
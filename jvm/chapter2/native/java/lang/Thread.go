@@ -0,0 +1,211 @@
+package lang
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlThread = "java/lang/Thread"
+
+func init() {
+	native.Register(jlThread, "currentThread", "()Ljava/lang/Thread;", currentThread)
+	native.Register(jlThread, "yield", "()V", yield)
+	native.Register(jlThread, "sleep", "(J)V", sleep)
+	native.Register(jlThread, "start0", "()V", start0)
+	native.Register(jlThread, "isAlive", "()Z", isAlive)
+	native.Register(jlThread, "isInterrupted", "(Z)Z", isInterrupted)
+	native.Register(jlThread, "interrupt0", "()V", interrupt0)
+	native.Register(jlThread, "setPriority0", "(I)V", setPriority0)
+	native.Register(jlThread, "holdsLock", "(Ljava/lang/Object;)Z", holdsLock)
+}
+
+// UncaughtExceptionReporter 打印线程里未捕获的异常，由main包设置，
+// 类库里没有Thread.dispatchUncaughtException时用
+type UncaughtExceptionReporter func(thread *rtda.Thread, r interface{})
+
+var reportUncaught UncaughtExceptionReporter
+
+func SetUncaughtExceptionReporter(reporter UncaughtExceptionReporter) {
+	reportUncaught = reporter
+}
+
+// public static native Thread currentThread();
+func currentThread(frame *rtda.Frame) {
+	frame.OperandStack().PushRef(frame.Thread().JThread())
+}
+
+// public static native void yield();
+func yield(frame *rtda.Frame) {
+	runtime.Gosched()
+}
+
+// public static native void sleep(long millis) throws InterruptedException;
+// 和HotSpot一样sleep(0)只让出处理器；睡眠中被中断时清除中断标志，抛出InterruptedException
+func sleep(frame *rtda.Frame) {
+	millis := frame.LocalVars().GetLong(0)
+	if millis < 0 {
+		panic("java.lang.IllegalArgumentException: timeout value is negative")
+	}
+	thread := frame.Thread()
+	if thread.IsInterrupted(true) {
+		panic("java.lang.InterruptedException: sleep interrupted")
+	}
+	if millis == 0 {
+		runtime.Gosched()
+		return
+	}
+	thread.SetStatus(rtda.ThreadSleeping)
+	defer thread.SetStatus(rtda.ThreadRunnable)
+	deadline := time.Now().Add(time.Duration(millis) * time.Millisecond)
+	for remaining := time.Until(deadline); remaining > 0; remaining = time.Until(deadline) {
+		thread.Park(remaining)
+		if thread.IsInterrupted(true) {
+			panic("java.lang.InterruptedException: sleep interrupted")
+		}
+	}
+}
+
+// private native void start0();
+// 在新的goroutine里执行run方法，新线程的栈和当前线程的一样大。
+// Thread.start已经检查过threadStatus，这里再防止同一个Thread对象启动两次
+func start0(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	if _, started := this.Extra().(*rtda.Thread); started {
+		panic("java.lang.IllegalThreadStateException")
+	}
+	thread := rtda.NewThread(frame.Thread().MaxStackDepth())
+	thread.SetJThread(this)
+	thread.SetStatus(rtda.ThreadRunnable)
+	daemon := this.GetIntVar("daemon", "Z") != 0
+	thread.Start(daemon, func() { runThread(thread) })
+}
+
+// runThread 执行Thread.run，未捕获的异常交给Thread.dispatchUncaughtException，最后结束线程
+func runThread(thread *rtda.Thread) {
+	jThread := thread.JThread()
+	defer ExitThread(thread)
+	defer func() {
+		if r := recover(); r != nil {
+			dispatchUncaughtException(thread, r)
+		}
+	}()
+	run := jThread.Class().LookupInstanceMethod("run", "()V")
+	base.RunMethod(thread, run, jThread)
+}
+
+// dispatchUncaughtException 像HotSpot一样调用Thread.dispatchUncaughtException，
+// 由线程的UncaughtExceptionHandler或者线程组处理异常；处理器再抛出的异常只打印类名
+func dispatchUncaughtException(thread *rtda.Thread, r interface{}) {
+	jThread := thread.JThread()
+	ex := base.ToThrowable(thread, r)
+	dispatch := threadMethod(jThread, "dispatchUncaughtException", "(Ljava/lang/Throwable;)V")
+	if ex == nil || dispatch == nil {
+		if reportUncaught != nil {
+			reportUncaught(thread, r)
+		}
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			name := fmt.Sprintf("%v", r)
+			if handlerEx := base.ToThrowable(thread, r); handlerEx != nil {
+				name = handlerEx.Class().JavaName()
+			}
+			fmt.Fprintf(os.Stderr, "\nException: %s thrown from the UncaughtExceptionHandler in thread \"%s\"\n",
+				name, ThreadName(thread))
+		}
+	}()
+	base.RunMethod(thread, dispatch, jThread, ex)
+}
+
+/*
+ExitThread 像HotSpot的JavaThread::exit一样结束线程：执行Thread.exit让线程组移除这个线程，
+然后在Thread对象的监视器里把线程标记为已结束，唤醒在Thread对象上wait的线程，Thread.join就是这样返回的。
+main线程的main方法结束后也调用它
+*/
+func ExitThread(thread *rtda.Thread) {
+	jThread := thread.JThread()
+	if jThread == nil {
+		return // 没有初始化类库
+	}
+	if exit := threadMethod(jThread, "exit", "()V"); exit != nil {
+		func() {
+			defer func() {
+				recover()
+			}()
+			base.RunMethod(thread, exit, jThread)
+		}()
+	}
+	rtda.MonitorEnter(thread, jThread)
+	thread.SetStatus(rtda.ThreadTerminated)
+	rtda.Notify(thread, jThread, true)
+	rtda.MonitorExit(thread, jThread)
+}
+
+// threadMethod 在java/lang/Thread里找实例方法，不会找到子类声明的同名方法
+func threadMethod(jThread *heap.Object, name, descriptor string) *heap.Method {
+	class := jThread.Class()
+	for class.Name() != jlThread {
+		class = class.SuperClass()
+	}
+	return class.LookupInstanceMethod(name, descriptor)
+}
+
+// ThreadName 返回Thread.getName的值
+func ThreadName(thread *rtda.Thread) string {
+	if jThread := thread.JThread(); jThread != nil {
+		if name := jThread.GetRefVar("name", "Ljava/lang/String;"); name != nil {
+			return heap.GoString(name)
+		}
+	}
+	return "main"
+}
+
+// public final native boolean isAlive();
+// 启动了还没有结束的线程是活的
+func isAlive(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	thread, started := this.Extra().(*rtda.Thread)
+	pushBoolean(frame, started && thread.Status() != rtda.ThreadTerminated)
+}
+
+// private native boolean isInterrupted(boolean ClearInterrupted);
+// 没有启动或者已经结束的线程没有中断标志
+func isInterrupted(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	clear := vars.GetInt(1) != 0
+	thread, started := this.Extra().(*rtda.Thread)
+	pushBoolean(frame, started && thread.Status() != rtda.ThreadTerminated && thread.IsInterrupted(clear))
+}
+
+// private native void interrupt0();
+// 设置中断标志，正在sleep或者wait的线程醒来抛出InterruptedException
+func interrupt0(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	if thread, started := this.Extra().(*rtda.Thread); started {
+		thread.Interrupt()
+	}
+}
+
+// private native void setPriority0(int newPriority);
+// 优先级已经由Java代码记在priority字段里，只是建议，goroutine的调度不区分优先级
+func setPriority0(frame *rtda.Frame) {
+	// do nothing
+}
+
+// public static native boolean holdsLock(Object obj);
+func holdsLock(frame *rtda.Frame) {
+	obj := frame.LocalVars().GetRef(0)
+	if obj == nil {
+		panic("java.lang.NullPointerException")
+	}
+	pushBoolean(frame, rtda.HoldsLock(frame.Thread(), obj))
+}
@@ -0,0 +1,55 @@
+package lang
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlThrowable = "java/lang/Throwable"
+
+func init() {
+	native.Register(jlThrowable, "fillInStackTrace", "(I)Ljava/lang/Throwable;", fillInStackTrace)
+	native.Register(jlThrowable, "getStackTraceDepth", "()I", getStackTraceDepth)
+	native.Register(jlThrowable, "getStackTraceElement", "(I)Ljava/lang/StackTraceElement;", getStackTraceElement)
+}
+
+// private native Throwable fillInStackTrace(int dummy);
+func fillInStackTrace(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	base.FillInStackTrace(frame.Thread(), this)
+	frame.OperandStack().PushRef(this)
+}
+
+// native int getStackTraceDepth();
+func getStackTraceDepth(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushInt(int32(len(base.StackTrace(this))))
+}
+
+// native StackTraceElement getStackTraceElement(int index);
+func getStackTraceElement(frame *rtda.Frame) {
+	this := frame.LocalVars().GetThis()
+	index := frame.LocalVars().GetInt(1)
+	stackTrace := base.StackTrace(this)
+	if index < 0 || int(index) >= len(stackTrace) {
+		panic("java.lang.IndexOutOfBoundsException")
+	}
+	frame.OperandStack().PushRef(newStackTraceElement(frame, stackTrace[index]))
+}
+
+// 和HotSpot一样直接设置StackTraceElement的字段，不执行构造函数
+func newStackTraceElement(frame *rtda.Frame, element *base.StackTraceElement) *heap.Object {
+	thread := frame.Thread()
+	loader := frame.Method().Class().Loader()
+	class := loader.Bootstrap().LoadClass(thread, "java/lang/StackTraceElement")
+	jElement := class.NewObject()
+	jElement.SetRefVar("declaringClass", "Ljava/lang/String;", heap.JString(thread, loader, element.ClassName))
+	jElement.SetRefVar("methodName", "Ljava/lang/String;", heap.JString(thread, loader, element.MethodName))
+	if element.FileName != "" {
+		jElement.SetRefVar("fileName", "Ljava/lang/String;", heap.JString(thread, loader, element.FileName))
+	}
+	jElement.SetIntVar("lineNumber", "I", int32(element.LineNumber))
+	return jElement
+}
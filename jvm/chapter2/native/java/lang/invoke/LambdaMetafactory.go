@@ -0,0 +1,396 @@
+package invoke
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const (
+	jliLambdaMetafactory     = "java/lang/invoke/LambdaMetafactory"
+	metafactoryDescriptor    = "(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;Ljava/lang/invoke/MethodType;Ljava/lang/invoke/MethodHandle;Ljava/lang/invoke/MethodType;)Ljava/lang/invoke/CallSite;"
+	altMetafactoryDescriptor = "(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;[Ljava/lang/Object;)Ljava/lang/invoke/CallSite;"
+)
+
+// altMetafactory的标志，和LambdaMetafactory.FLAG_SERIALIZABLE等一样
+const (
+	flagSerializable = 1 << 0
+	flagMarkers      = 1 << 1
+	flagBridges      = 1 << 2
+)
+
+// 生成的lambda类的编号，和JDK 8一样类名是调用者的类名加上$$Lambda$n
+var lambdaCounter int32
+
+func init() {
+	native.RegisterBootstrap(jliLambdaMetafactory, "metafactory", metafactoryDescriptor, metafactory)
+	native.RegisterBootstrap(jliLambdaMetafactory, "altMetafactory", altMetafactoryDescriptor, altMetafactory)
+}
+
+// lambdaSpec 生成lambda类需要的信息，和InnerClassLambdaMetafactory的参数对应
+type lambdaSpec struct {
+	caller       *heap.Class
+	name         string     // 要实现的接口方法名
+	factoryType  methodType // 调用点的类型：捕获的参数 -> 函数式接口
+	samType      methodType // 接口方法擦除泛型后的类型
+	instantiated methodType // 接口方法代入类型参数后的类型，拆箱时用
+	impl         *heap.MethodHandleRef
+	interfaces   []string // 函数式接口和标记接口
+	bridges      []methodType
+}
+
+// public static CallSite metafactory(MethodHandles.Lookup caller, String invokedName, MethodType invokedType, MethodType samMethodType, MethodHandle implMethod, MethodType instantiatedMethodType)
+// 生成实现函数式接口的类，调用点的目标返回它的实例
+func metafactory(frame *rtda.Frame, callSite *heap.InvokeDynamicRef) native.CallSiteTarget {
+	_, args := callSite.BootstrapMethod()
+	if len(args) != 3 {
+		panic(fmt.Sprintf("java.lang.invoke.LambdaConversionException: metafactory expects 3 static arguments, got %d", len(args)))
+	}
+	return newLambdaSpec(callSite, args).link(frame.Thread())
+}
+
+// public static CallSite altMetafactory(MethodHandles.Lookup caller, String invokedName, MethodType invokedType, Object... args)
+// args是metafactory的三个参数、标志，然后按标志依次是标记接口和桥方法，都先给出个数
+func altMetafactory(frame *rtda.Frame, callSite *heap.InvokeDynamicRef) native.CallSiteTarget {
+	_, args := callSite.BootstrapMethod()
+	if len(args) < 4 {
+		panic(fmt.Sprintf("java.lang.invoke.LambdaConversionException: altMetafactory expects at least 4 static arguments, got %d", len(args)))
+	}
+	spec := newLambdaSpec(callSite, args[:3])
+	flags := intArg(args[3])
+	rest := args[4:]
+	if flags&flagSerializable != 0 {
+		spec.addInterface("java/io/Serializable")
+	}
+	if flags&flagMarkers != 0 {
+		markers := countedArgs(rest)
+		for _, arg := range markers {
+			classRef, ok := arg.(*heap.ClassRef)
+			if !ok {
+				panic("java.lang.invoke.LambdaConversionException: marker interface is not a class constant")
+			}
+			spec.addInterface(classRef.ResolvedClass(frame.Thread()).Name())
+		}
+		rest = rest[1+len(markers):]
+	}
+	if flags&flagBridges != 0 {
+		for _, arg := range countedArgs(rest) {
+			spec.bridges = append(spec.bridges, parseMethodType(methodTypeArg(arg).Descriptor()))
+		}
+	}
+	return spec.link(frame.Thread())
+}
+
+func newLambdaSpec(callSite *heap.InvokeDynamicRef, args []heap.Constant) *lambdaSpec {
+	spec := &lambdaSpec{
+		caller:       callSite.Class(),
+		name:         callSite.Name(),
+		factoryType:  parseMethodType(callSite.Descriptor()),
+		samType:      parseMethodType(methodTypeArg(args[0]).Descriptor()),
+		instantiated: parseMethodType(methodTypeArg(args[2]).Descriptor()),
+	}
+	impl, ok := args[1].(*heap.MethodHandleRef)
+	if !ok || impl.IsFieldHandle() {
+		panic("java.lang.invoke.LambdaConversionException: implementation is not a method handle to a method")
+	}
+	spec.impl = impl
+	if !isReference(spec.factoryType.ret) || spec.factoryType.ret[0] == '[' {
+		panic("java.lang.invoke.LambdaConversionException: invokedType must return an interface: " + callSite.Descriptor())
+	}
+	spec.addInterface(className(spec.factoryType.ret))
+	return spec
+}
+
+func (spec *lambdaSpec) addInterface(name string) {
+	for _, iface := range spec.interfaces {
+		if iface == name {
+			return
+		}
+	}
+	spec.interfaces = append(spec.interfaces, name)
+}
+
+/*
+link 像JDK 8的InnerClassLambdaMetafactory一样生成并定义lambda类：
+捕获的参数保存在private final字段arg$1、arg$2...里，接口方法和桥方法转换参数后调用实现方法。
+lambda类是调用者的匿名类，能调用调用者的私有方法。
+不捕获参数时调用点总是返回同一个实例，否则调用静态工厂方法get$Lambda创建实例
+*/
+func (spec *lambdaSpec) link(thread *rtda.Thread) native.CallSiteTarget {
+	loader := spec.caller.Loader()
+	for _, name := range spec.interfaces {
+		if iface := loader.LoadClass(thread, name); !iface.IsInterface() {
+			panic("java.lang.invoke.LambdaConversionException: " + iface.JavaName() + " is not an interface")
+		}
+	}
+	implClass, implMethod := spec.impl.ResolvedClass(thread), spec.impl.ResolvedMethod(thread)
+	name := fmt.Sprintf("%s$$Lambda$%d", spec.caller.Name(), atomic.AddInt32(&lambdaCounter, 1))
+	source := spec.generate(name, implClass, implMethod)
+	_, data, err := asm.Assemble(name, []byte(source), asm.Options{})
+	if err != nil {
+		panic("java.lang.InternalError: " + err.Error())
+	}
+	lambdaClass := heap.DefineAnonymousClass(thread, spec.caller, data)
+
+	if len(spec.factoryType.params) == 0 {
+		instance := base.NewObject(thread, lambdaClass, "()V")
+		return func(frame *rtda.Frame) {
+			frame.OperandStack().PushRef(instance)
+		}
+	}
+	factory := lambdaClass.GetStaticMethod("get$Lambda", spec.factoryType.descriptor())
+	return func(frame *rtda.Frame) {
+		base.InitClass(frame.Thread(), lambdaClass)
+		base.InvokeMethod(frame, factory)
+	}
+}
+
+// generate 生成lambda类的汇编源码，见asm包
+func (spec *lambdaSpec) generate(name string, implClass *heap.Class, implMethod *heap.Method) string {
+	captured := spec.factoryType.params
+	w := &codeWriter{}
+	w.line(".bytecode 52.0")
+	w.line(".class final synthetic %s", name)
+	w.line(".super java/lang/Object")
+	for _, iface := range spec.interfaces {
+		w.line(".implements %s", iface)
+	}
+	for i, t := range captured {
+		w.line(".field private final arg$%d %s", i+1, t)
+	}
+
+	w.line(".method private <init>%s", methodType{captured, "V"}.descriptor())
+	w.line("aload_0")
+	w.line("invokespecial java/lang/Object/<init>()V")
+	local := 1
+	for i, t := range captured {
+		w.line("aload_0")
+		w.load(t, local)
+		w.line("putfield %s/arg$%d %s", name, i+1, t)
+		local += slotSize(t)
+	}
+	w.line("return")
+	w.line(".end method")
+
+	if len(captured) > 0 {
+		w.line(".method private static get$Lambda%s", spec.factoryType.descriptor())
+		w.line("new %s", name)
+		w.line("dup")
+		local = 0
+		for _, t := range captured {
+			w.load(t, local)
+			local += slotSize(t)
+		}
+		w.line("invokespecial %s/<init>%s", name, methodType{captured, "V"}.descriptor())
+		w.line("areturn")
+		w.line(".end method")
+	}
+
+	spec.forward(w, name, spec.samType, implClass, implMethod)
+	for _, bridge := range spec.bridges {
+		if bridge.descriptor() != spec.samType.descriptor() {
+			spec.forward(w, name, bridge, implClass, implMethod)
+		}
+	}
+	return w.String()
+}
+
+// forward 生成接口方法：依次取出捕获的参数和接口方法的参数，转换成实现方法的参数类型，
+// 调用实现方法，再把返回值转换成接口方法的返回值类型。implClass是方法句柄引用的类
+func (spec *lambdaSpec) forward(w *codeWriter, name string, sam methodType, implClass *heap.Class, implMethod *heap.Method) {
+	kind := spec.impl.ReferenceKind()
+	implClassName := implClass.Name()
+	implType := parseMethodType(implMethod.Descriptor())
+	implParams := implType.params
+	implRet := implType.ret
+	switch kind {
+	case classfile.REF_invokeVirtual, classfile.REF_invokeSpecial, classfile.REF_invokeInterface:
+		implParams = append([]string{"L" + implClassName + ";"}, implParams...) // 接收者是第一个参数
+	case classfile.REF_newInvokeSpecial:
+		implRet = "L" + implClassName + ";"
+	}
+	captured := spec.factoryType.params
+	if len(captured)+len(sam.params) != len(implParams) || len(sam.params) != len(spec.instantiated.params) {
+		panic(fmt.Sprintf("java.lang.invoke.LambdaConversionException: Incorrect number of parameters for %s method %s%s; %d captured parameters, %d functional interface method parameters",
+			kindName(kind), implMethod.String(), implMethod.Descriptor(), len(captured), len(sam.params)))
+	}
+	if implRet == "V" && sam.ret != "V" {
+		panic("java.lang.invoke.LambdaConversionException: Type mismatch for lambda return: void is not convertible to " + sam.ret)
+	}
+
+	w.line(".method public %s%s", spec.name, sam.descriptor())
+	if kind == classfile.REF_newInvokeSpecial {
+		w.line("new %s", implClassName)
+		w.line("dup")
+	}
+	for i, t := range captured {
+		w.line("aload_0")
+		w.line("getfield %s/arg$%d %s", name, i+1, t)
+	}
+	local := 1
+	for i, t := range sam.params {
+		w.load(t, local)
+		local += slotSize(t)
+		w.convert(t, implParams[len(captured)+i], spec.instantiated.params[i])
+	}
+
+	implRef := implClassName + "/" + implMethod.Name() + implMethod.Descriptor()
+	switch {
+	case kind == classfile.REF_invokeStatic && implClass.IsInterface():
+		w.line("invokestatic interface %s", implRef)
+	case kind == classfile.REF_invokeStatic:
+		w.line("invokestatic %s", implRef)
+	case kind == classfile.REF_invokeInterface:
+		w.line("invokeinterface %s", implRef)
+	case kind == classfile.REF_newInvokeSpecial:
+		w.line("invokespecial %s", implRef)
+	case kind == classfile.REF_invokeSpecial && !implMethod.IsPrivate():
+		w.line("invokespecial %s", implRef)
+	default:
+		// 私有方法和JDK 15以后的隐藏类一样用invokevirtual调用，invokevirtual不会选择其他方法
+		w.line("invokevirtual %s", implRef)
+	}
+
+	switch {
+	case sam.ret == "V" && implRet != "V":
+		if slotSize(implRet) == 2 {
+			w.line("pop2")
+		} else {
+			w.line("pop")
+		}
+	case sam.ret != "V":
+		w.convert(implRet, sam.ret, spec.instantiated.ret)
+	}
+	w.line("%sreturn", typePrefix(sam.ret))
+	w.line(".end method")
+}
+
+func intArg(arg heap.Constant) int {
+	if i, ok := arg.(int32); ok {
+		return int(i)
+	}
+	panic("java.lang.invoke.LambdaConversionException: expected an int argument of altMetafactory")
+}
+
+func methodTypeArg(arg heap.Constant) *heap.MethodTypeRef {
+	if methodTypeRef, ok := arg.(*heap.MethodTypeRef); ok {
+		return methodTypeRef
+	}
+	panic("java.lang.invoke.LambdaConversionException: expected a MethodType argument")
+}
+
+// countedArgs 返回args开头的个数后面的那么多个参数
+func countedArgs(args []heap.Constant) []heap.Constant {
+	if len(args) == 0 {
+		panic("java.lang.invoke.LambdaConversionException: missing argument count of altMetafactory")
+	}
+	n := intArg(args[0])
+	if n < 0 || n > len(args)-1 {
+		panic("java.lang.invoke.LambdaConversionException: bad argument count of altMetafactory")
+	}
+	return args[1 : 1+n]
+}
+
+func kindName(kind uint8) string {
+	names := []string{"", "getField", "getStatic", "putField", "putStatic", "invokeVirtual",
+		"invokeStatic", "invokeSpecial", "newInvokeSpecial", "invokeInterface"}
+	if int(kind) < len(names) {
+		return names[kind]
+	}
+	return "unknown"
+}
+
+// codeWriter 按行拼接汇编源码
+type codeWriter struct {
+	strings.Builder
+}
+
+func (w *codeWriter) line(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+	w.WriteByte('\n')
+}
+
+// load 把局部变量local按类型t压栈
+func (w *codeWriter) load(t string, local int) {
+	w.line("%sload %d", typePrefix(t), local)
+}
+
+/*
+convert 把栈顶from类型的值转换成to类型，和JDK的TypeConvertingMethodAdapter.convertType一样：
+基本类型之间放宽，基本类型装箱，包装类拆箱后放宽，其他引用类型先转换成functional对应的包装类再拆箱，
+引用类型之间用checkcast
+*/
+func (w *codeWriter) convert(from, to, functional string) {
+	switch {
+	case from == to:
+	case !isReference(from) && !isReference(to):
+		w.widen(from, to)
+	case !isReference(from):
+		wrapper := wrappers[from]
+		w.line("invokestatic %s/valueOf(%s)L%s;", wrapper.class, from, wrapper.class)
+		if to != "L"+wrapper.class+";" && to != "Ljava/lang/Object;" {
+			w.line("checkcast %s", className(to))
+		}
+	case !isReference(to):
+		primitive, ok := wrapperPrimitives[from]
+		if !ok {
+			primitive = to
+			if !isReference(functional) {
+				primitive = functional
+			} else if p, ok := wrapperPrimitives[functional]; ok {
+				primitive = p
+			}
+			w.line("checkcast %s", wrappers[primitive].class)
+		}
+		wrapper := wrappers[primitive]
+		w.line("invokevirtual %s/%s()%s", wrapper.class, wrapper.unbox, primitive)
+		w.widen(primitive, to)
+	case to != "Ljava/lang/Object;":
+		w.line("checkcast %s", className(to))
+	}
+}
+
+// widen 基本类型的放宽转换，byte、short、char和boolean在栈上都是int
+func (w *codeWriter) widen(from, to string) {
+	if op := wideningOps[typePrefix(from)+typePrefix(to)]; op != "" {
+		w.line("%s", op)
+	}
+}
+
+var wideningOps = map[string]string{
+	"il": "i2l", "if": "i2f", "id": "i2d",
+	"lf": "l2f", "ld": "l2d",
+	"fd": "f2d",
+}
+
+type wrapper struct {
+	class string
+	unbox string // 拆箱的方法名，例如intValue
+}
+
+var wrappers = map[string]wrapper{
+	"Z": {"java/lang/Boolean", "booleanValue"},
+	"B": {"java/lang/Byte", "byteValue"},
+	"C": {"java/lang/Character", "charValue"},
+	"S": {"java/lang/Short", "shortValue"},
+	"I": {"java/lang/Integer", "intValue"},
+	"J": {"java/lang/Long", "longValue"},
+	"F": {"java/lang/Float", "floatValue"},
+	"D": {"java/lang/Double", "doubleValue"},
+}
+
+// 包装类的描述符到基本类型的描述符
+var wrapperPrimitives = map[string]string{}
+
+func init() {
+	for primitive, w := range wrappers {
+		wrapperPrimitives["L"+w.class+";"] = primitive
+	}
+}
@@ -0,0 +1,240 @@
+package invoke
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/mutf8"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const (
+	jliStringConcatFactory            = "java/lang/invoke/StringConcatFactory"
+	makeConcatDescriptor              = "(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;)Ljava/lang/invoke/CallSite;"
+	makeConcatWithConstantsDescriptor = "(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;Ljava/lang/String;[Ljava/lang/Object;)Ljava/lang/invoke/CallSite;"
+)
+
+// makeConcatWithConstants的配方里代表参数和常量的字符
+const (
+	tagArg   = '\u0001'
+	tagConst = '\u0002'
+)
+
+func init() {
+	native.RegisterBootstrap(jliStringConcatFactory, "makeConcat", makeConcatDescriptor, makeConcat)
+	native.RegisterBootstrap(jliStringConcatFactory, "makeConcatWithConstants", makeConcatWithConstantsDescriptor, makeConcatWithConstants)
+}
+
+// concatPiece 拼接的一段：常量文本，或者第arg个参数
+type concatPiece struct {
+	text []uint16
+	arg  int // -1表示常量
+}
+
+// public static CallSite makeConcat(MethodHandles.Lookup lookup, String name, MethodType concatType)
+// 按顺序拼接所有参数
+func makeConcat(frame *rtda.Frame, callSite *heap.InvokeDynamicRef) native.CallSiteTarget {
+	concatType := parseConcatType(callSite)
+	pieces := make([]concatPiece, len(concatType.params))
+	for i := range pieces {
+		pieces[i].arg = i
+	}
+	return concatTarget(concatType, pieces)
+}
+
+// public static CallSite makeConcatWithConstants(MethodHandles.Lookup lookup, String name, MethodType concatType, String recipe, Object... constants)
+// 配方里的\1换成下一个参数，\2换成下一个常量，其他字符原样保留
+func makeConcatWithConstants(frame *rtda.Frame, callSite *heap.InvokeDynamicRef) native.CallSiteTarget {
+	concatType := parseConcatType(callSite)
+	_, args := callSite.BootstrapMethod()
+	if len(args) == 0 {
+		panic("java.lang.invoke.StringConcatException: missing recipe")
+	}
+	recipe, ok := args[0].(string)
+	if !ok {
+		panic("java.lang.invoke.StringConcatException: recipe is not a string")
+	}
+	constants := args[1:]
+	var pieces []concatPiece
+	var text []uint16
+	nextArg, nextConst := 0, 0
+	for _, c := range mutf8.FromString(recipe) {
+		switch c {
+		case tagArg:
+			if nextArg >= len(concatType.params) {
+				panic(fmt.Sprintf("java.lang.invoke.StringConcatException: Mismatched number of concat arguments: recipe wants %d arguments, but signature provides %d",
+					strings.Count(recipe, string(tagArg)), len(concatType.params)))
+			}
+			if len(text) > 0 {
+				pieces = append(pieces, concatPiece{text: text, arg: -1})
+				text = nil
+			}
+			pieces = append(pieces, concatPiece{arg: nextArg})
+			nextArg++
+		case tagConst:
+			if nextConst >= len(constants) {
+				panic("java.lang.invoke.StringConcatException: Mismatched number of concat constants")
+			}
+			text = append(text, constantChars(constants[nextConst])...)
+			nextConst++
+		default:
+			text = append(text, c)
+		}
+	}
+	if len(text) > 0 {
+		pieces = append(pieces, concatPiece{text: text, arg: -1})
+	}
+	if nextArg != len(concatType.params) {
+		panic(fmt.Sprintf("java.lang.invoke.StringConcatException: Mismatched number of concat arguments: recipe wants %d arguments, but signature provides %d",
+			nextArg, len(concatType.params)))
+	}
+	return concatTarget(concatType, pieces)
+}
+
+func parseConcatType(callSite *heap.InvokeDynamicRef) methodType {
+	concatType := parseMethodType(callSite.Descriptor())
+	if concatType.ret != "Ljava/lang/String;" {
+		panic("java.lang.invoke.StringConcatException: The return type should be compatible with String, but it is " + concatType.ret)
+	}
+	return concatType
+}
+
+// concatTarget 调用点的目标：弹出参数，按String.valueOf转换成字符串后拼接
+func concatTarget(concatType methodType, pieces []concatPiece) native.CallSiteTarget {
+	return func(frame *rtda.Frame) {
+		stack := frame.OperandStack()
+		args := make([]interface{}, len(concatType.params))
+		for i := len(args) - 1; i >= 0; i-- {
+			switch t := concatType.params[i]; t {
+			case "J":
+				args[i] = stack.PopLong()
+			case "F":
+				args[i] = stack.PopFloat()
+			case "D":
+				args[i] = stack.PopDouble()
+			case "Z", "B", "C", "S", "I":
+				args[i] = primitiveChars(t, stack.PopInt())
+			default:
+				args[i] = stack.PopRef()
+			}
+		}
+		var chars []uint16
+		for _, piece := range pieces {
+			if piece.arg < 0 {
+				chars = append(chars, piece.text...)
+				continue
+			}
+			switch arg := args[piece.arg].(type) {
+			case int64:
+				chars = appendASCII(chars, strconv.FormatInt(arg, 10))
+			case float32:
+				chars = appendASCII(chars, javaFloatString(float64(arg), 32))
+			case float64:
+				chars = appendASCII(chars, javaFloatString(arg, 64))
+			case []uint16:
+				chars = append(chars, arg...)
+			case *heap.Object:
+				chars = append(chars, objectChars(frame.Thread(), arg)...)
+			}
+		}
+		loader := frame.Method().Class().Loader()
+		stack.PushRef(heap.JStringFromChars(frame.Thread(), loader, chars))
+	}
+}
+
+// primitiveChars int及更窄的基本类型按String.valueOf转换
+func primitiveChars(descriptor string, val int32) []uint16 {
+	switch descriptor {
+	case "Z":
+		if val != 0 {
+			return appendASCII(nil, "true")
+		}
+		return appendASCII(nil, "false")
+	case "C":
+		return []uint16{uint16(val)}
+	}
+	return appendASCII(nil, strconv.Itoa(int(val)))
+}
+
+// objectChars 和String.valueOf(Object)一样：null是"null"，字符串是它自己，其他对象调用toString
+func objectChars(thread *rtda.Thread, obj *heap.Object) []uint16 {
+	if obj == nil {
+		return appendASCII(nil, "null")
+	}
+	if obj.Class().Name() == "java/lang/String" {
+		return heap.JStringChars(obj)
+	}
+	toString := obj.Class().LookupInstanceMethod("toString", "()Ljava/lang/String;")
+	if toString == nil {
+		panic("java.lang.AbstractMethodError: " + obj.Class().JavaName() + ".toString()Ljava/lang/String;")
+	}
+	str := base.RunMethod(thread, toString, obj).PopRef()
+	if str == nil {
+		return appendASCII(nil, "null")
+	}
+	return heap.JStringChars(str)
+}
+
+// constantChars 配方里的常量转换成字符串
+func constantChars(constant heap.Constant) []uint16 {
+	switch c := constant.(type) {
+	case string:
+		return mutf8.FromString(c)
+	case int32:
+		return appendASCII(nil, strconv.Itoa(int(c)))
+	case int64:
+		return appendASCII(nil, strconv.FormatInt(c, 10))
+	case float32:
+		return appendASCII(nil, javaFloatString(float64(c), 32))
+	case float64:
+		return appendASCII(nil, javaFloatString(c, 64))
+	}
+	panic(fmt.Sprintf("java.lang.invoke.StringConcatException: unsupported constant %T", constant))
+}
+
+func appendASCII(chars []uint16, s string) []uint16 {
+	for i := 0; i < len(s); i++ {
+		chars = append(chars, uint16(s[i]))
+	}
+	return chars
+}
+
+/*
+javaFloatString 和Float.toString、Double.toString的格式一样：
+绝对值在10^-3到10^7之间时写成至少有一位小数的定点形式，例如100.0，
+否则写成科学计数法，例如1.0E10、1.5E-5；数字是能唯一确定这个值的最短形式
+*/
+func javaFloatString(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		if math.Signbit(f) {
+			return "-0.0"
+		}
+		return "0.0"
+	}
+	if abs := math.Abs(f); abs >= 1e-3 && abs < 1e7 {
+		s := strconv.FormatFloat(f, 'f', -1, bitSize)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	}
+	s := strconv.FormatFloat(f, 'e', -1, bitSize)
+	mantissa, exp := s[:strings.IndexByte(s, 'e')], s[strings.IndexByte(s, 'e')+1:]
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	expVal, _ := strconv.Atoi(exp)
+	return mantissa + "E" + strconv.Itoa(expVal)
+}
@@ -0,0 +1,66 @@
+package invoke
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// methodType 拆开的方法描述符，参数和返回值都是类型描述符
+type methodType struct {
+	params []string
+	ret    string
+}
+
+func parseMethodType(descriptor string) methodType {
+	methodSig, err := signature.ParseMethodDescriptor(descriptor)
+	if err != nil {
+		panic("java.lang.ClassFormatError: " + err.Error())
+	}
+	params := make([]string, len(methodSig.Params))
+	for i, param := range methodSig.Params {
+		params[i] = param.Signature()
+	}
+	return methodType{params, methodSig.Return.Signature()}
+}
+
+func (t methodType) descriptor() string {
+	return "(" + strings.Join(t.params, "") + ")" + t.ret
+}
+
+func isReference(descriptor string) bool {
+	return descriptor[0] == 'L' || descriptor[0] == '['
+}
+
+// className 引用类型的描述符对应的类名，数组类的类名就是描述符
+func className(descriptor string) string {
+	if descriptor[0] == 'L' {
+		return descriptor[1 : len(descriptor)-1]
+	}
+	return descriptor
+}
+
+// slotSize 类型在局部变量表和操作数栈上占的槽位数
+func slotSize(descriptor string) int {
+	if descriptor == "J" || descriptor == "D" {
+		return 2
+	}
+	return 1
+}
+
+// typePrefix load、return等指令的类型前缀
+func typePrefix(descriptor string) string {
+	switch descriptor[0] {
+	case 'J':
+		return "l"
+	case 'F':
+		return "f"
+	case 'D':
+		return "d"
+	case 'L', '[':
+		return "a"
+	case 'V':
+		return ""
+	}
+	return "i"
+}
@@ -0,0 +1,64 @@
+package reflect
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jlrArray = "java/lang/reflect/Array"
+
+func init() {
+	native.Register(jlrArray, "newArray", "(Ljava/lang/Class;I)Ljava/lang/Object;", newArray)
+	native.Register(jlrArray, "multiNewArray", "(Ljava/lang/Class;[I)Ljava/lang/Object;", multiNewArray)
+	native.Register(jlrArray, "getLength", "(Ljava/lang/Object;)I", getLength)
+}
+
+// private static native Object newArray(Class<?> componentType, int length);
+func newArray(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	componentType := vars.GetRef(0)
+	length := vars.GetInt(1)
+	if componentType == nil {
+		panic("java.lang.NullPointerException")
+	}
+	frame.OperandStack().PushRef(newMultiArray(frame.Thread(), componentType.Extra().(*heap.Class), []int32{length}))
+}
+
+// private static native Object multiNewArray(Class<?> componentType, int[] dimensions);
+func multiNewArray(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	componentType := vars.GetRef(0)
+	dimensions := vars.GetRef(1)
+	if componentType == nil || dimensions == nil {
+		panic("java.lang.NullPointerException")
+	}
+	if len(dimensions.Ints()) == 0 {
+		panic("java.lang.IllegalArgumentException: Empty dimensions array")
+	}
+	frame.OperandStack().PushRef(newMultiArray(frame.Thread(), componentType.Extra().(*heap.Class), dimensions.Ints()))
+}
+
+// newMultiArray 创建len(counts)维的数组，最内层的元素类型是componentClass
+func newMultiArray(thread *rtda.Thread, componentClass *heap.Class, counts []int32) *heap.Object {
+	if componentClass.Name() == "void" && componentClass.IsPrimitive() {
+		panic("java.lang.IllegalArgumentException")
+	}
+	arrClass := componentClass
+	for range counts {
+		arrClass = arrClass.ArrayClass(thread)
+	}
+	return arrClass.NewMultiArray(counts)
+}
+
+// public static native int getLength(Object array) throws IllegalArgumentException;
+func getLength(frame *rtda.Frame) {
+	array := frame.LocalVars().GetRef(0)
+	if array == nil {
+		panic("java.lang.NullPointerException")
+	}
+	if !array.Class().IsArray() {
+		panic("java.lang.IllegalArgumentException: Argument is not an array")
+	}
+	frame.OperandStack().PushInt(array.ArrayLength())
+}
@@ -0,0 +1,122 @@
+package lang
+
+import (
+	"fmt"
+	"io"
+	"time"
+	"unsafe"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+PrintThreadDump 像jstack一样打印所有Java线程：名字、状态、带行号的栈、每帧持有的监视器和
+栈顶在等的监视器，最后报告监视器的循环等待。self是调用者自己的线程，不是Java线程时为nil。
+收到SIGQUIT和执行诊断命令Thread.print时调用
+*/
+func PrintThreadDump(w io.Writer, self *rtda.Thread) {
+	infos := rtda.DumpThreads(self)
+	fmt.Fprintln(w, time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(w, "Full thread dump jvm (0.0.1 interpreted mode):")
+	for _, info := range infos {
+		if info.Status == rtda.ThreadTerminated {
+			continue // 结束了的线程还在等非守护线程，比如执行完main方法的main线程
+		}
+		fmt.Fprintln(w)
+		printThreadHeader(w, info)
+		printThreadStack(w, info)
+	}
+	fmt.Fprintln(w)
+	printDeadlocks(w, infos)
+}
+
+// 状态对应的线程头部描述和Thread.State，和HotSpot的一样
+var threadStates = map[rtda.ThreadStatus][2]string{
+	rtda.ThreadRunnable:              {"runnable", "RUNNABLE"},
+	rtda.ThreadSleeping:              {"waiting on condition", "TIMED_WAITING (sleeping)"},
+	rtda.ThreadInObjectWait:          {"in Object.wait()", "WAITING (on object monitor)"},
+	rtda.ThreadInObjectWaitTimed:     {"in Object.wait()", "TIMED_WAITING (on object monitor)"},
+	rtda.ThreadBlockedOnMonitorEnter: {"waiting for monitor entry", "BLOCKED (on object monitor)"},
+}
+
+func printThreadHeader(w io.Writer, info *rtda.ThreadInfo) {
+	thread := info.Thread
+	daemon := ""
+	if thread.IsDaemon() {
+		daemon = " daemon"
+	}
+	// 没有初始化类库时main线程的状态一直是NEW，其实在执行
+	state, ok := threadStates[info.Status]
+	if !ok {
+		state = threadStates[rtda.ThreadRunnable]
+	}
+	fmt.Fprintf(w, "\"%s\" #%d%s %s\n", ThreadName(thread), thread.ID(), daemon, state[0])
+	fmt.Fprintf(w, "   java.lang.Thread.State: %s\n", state[1])
+}
+
+func printThreadStack(w io.Writer, info *rtda.ThreadInfo) {
+	if !info.Stopped {
+		fmt.Fprintln(w, "\t- stack not available: the thread did not reach a safepoint")
+		return
+	}
+	for i, frame := range info.Frames {
+		method := frame.Method
+		class := method.Class()
+		element := &base.StackTraceElement{
+			ClassName:  class.JavaName(),
+			MethodName: method.Name(),
+			FileName:   class.SourceFile(),
+			LineNumber: method.GetLineNumber(frame.PC),
+		}
+		fmt.Fprintf(w, "\tat %s\n", element)
+		if i == 0 {
+			if info.BlockedOn != nil {
+				fmt.Fprintf(w, "\t- waiting to lock %s\n", describeMonitor(info.BlockedOn))
+			} else if info.WaitingOn != nil {
+				fmt.Fprintf(w, "\t- waiting on %s\n", describeMonitor(info.WaitingOn))
+			}
+		}
+		for _, object := range frame.Locked {
+			fmt.Fprintf(w, "\t- locked %s\n", describeMonitor(object))
+		}
+	}
+}
+
+// describeMonitor 和HotSpot一样用对象的地址和类名表示监视器，例如<0x000000c0000a2f80> (a java.lang.Object)
+func describeMonitor(object *heap.Object) string {
+	return fmt.Sprintf("<%#016x> (a %s)", uintptr(unsafe.Pointer(object)), object.Class().JavaName())
+}
+
+// printDeadlocks 和HotSpot的格式一样，先列出每个循环里的线程在等谁，再打印它们的栈
+func printDeadlocks(w io.Writer, infos []*rtda.ThreadInfo) {
+	deadlocks := rtda.FindDeadlocks(infos)
+	if len(deadlocks) == 0 {
+		return
+	}
+	for _, cycle := range deadlocks {
+		fmt.Fprintln(w, "Found one Java-level deadlock:")
+		fmt.Fprintln(w, "=============================")
+		for i, info := range cycle {
+			holder := cycle[(i+1)%len(cycle)]
+			fmt.Fprintf(w, "\"%s\":\n", ThreadName(info.Thread))
+			fmt.Fprintf(w, "  waiting to lock monitor %s,\n", describeMonitor(info.BlockedOn))
+			fmt.Fprintf(w, "  which is held by \"%s\"\n", ThreadName(holder.Thread))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Java stack information for the threads listed above:")
+		fmt.Fprintln(w, "===================================================")
+		for _, info := range cycle {
+			fmt.Fprintf(w, "\"%s\":\n", ThreadName(info.Thread))
+			printThreadStack(w, info)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(deadlocks) == 1 {
+		fmt.Fprintln(w, "Found 1 deadlock.")
+	} else {
+		fmt.Fprintf(w, "Found %d deadlocks.\n", len(deadlocks))
+	}
+	fmt.Fprintln(w)
+}
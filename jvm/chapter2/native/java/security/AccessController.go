@@ -0,0 +1,66 @@
+package security
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const jsAccessController = "java/security/AccessController"
+
+/*
+没有安全管理器，也就不检查权限：doPrivileged直接执行action，
+访问控制上下文都是null，Java代码把它当作没有限制
+*/
+func init() {
+	native.Register(jsAccessController, "doPrivileged", "(Ljava/security/PrivilegedAction;)Ljava/lang/Object;", doPrivileged)
+	native.Register(jsAccessController, "doPrivileged", "(Ljava/security/PrivilegedAction;Ljava/security/AccessControlContext;)Ljava/lang/Object;", doPrivileged)
+	native.Register(jsAccessController, "doPrivileged", "(Ljava/security/PrivilegedExceptionAction;)Ljava/lang/Object;", doPrivilegedException)
+	native.Register(jsAccessController, "doPrivileged", "(Ljava/security/PrivilegedExceptionAction;Ljava/security/AccessControlContext;)Ljava/lang/Object;", doPrivilegedException)
+	native.Register(jsAccessController, "getStackAccessControlContext", "()Ljava/security/AccessControlContext;", getStackAccessControlContext)
+	native.Register(jsAccessController, "getInheritedAccessControlContext", "()Ljava/security/AccessControlContext;", getStackAccessControlContext)
+}
+
+// public static native <T> T doPrivileged(PrivilegedAction<T> action);
+// public static native <T> T doPrivileged(PrivilegedAction<T> action, AccessControlContext context);
+func doPrivileged(frame *rtda.Frame) {
+	frame.OperandStack().PushRef(runAction(frame))
+}
+
+// public static native <T> T doPrivileged(PrivilegedExceptionAction<T> action) throws PrivilegedActionException;
+// public static native <T> T doPrivileged(PrivilegedExceptionAction<T> action, AccessControlContext context) throws PrivilegedActionException;
+// action抛出的受检异常包装成PrivilegedActionException
+func doPrivilegedException(frame *rtda.Frame) {
+	thread := frame.Thread()
+	boot := frame.Method().Class().Loader().Bootstrap()
+	defer func() {
+		if r := recover(); r != nil {
+			ex, ok := r.(*heap.Object)
+			if !ok || base.IsError(ex) || ex.IsInstanceOf(boot.LoadClass(thread, "java/lang/RuntimeException")) {
+				panic(r)
+			}
+			panic(base.NewObject(thread, boot.LoadClass(thread, "java/security/PrivilegedActionException"), "(Ljava/lang/Exception;)V", ex))
+		}
+	}()
+	frame.OperandStack().PushRef(runAction(frame))
+}
+
+// runAction 调用action的run方法，返回它的返回值
+func runAction(frame *rtda.Frame) *heap.Object {
+	action := frame.LocalVars().GetRef(0)
+	if action == nil {
+		panic("java.lang.NullPointerException")
+	}
+	run := action.Class().LookupInstanceMethod("run", "()Ljava/lang/Object;")
+	if run == nil {
+		panic("java.lang.AbstractMethodError: " + action.Class().JavaName() + ".run()Ljava/lang/Object;")
+	}
+	return base.RunMethod(frame.Thread(), run, action).PopRef()
+}
+
+// private static native AccessControlContext getStackAccessControlContext();
+// static native AccessControlContext getInheritedAccessControlContext();
+func getStackAccessControlContext(frame *rtda.Frame) {
+	frame.OperandStack().PushRef(nil)
+}
@@ -0,0 +1,16 @@
+package atomic
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+func init() {
+	native.Register("java/util/concurrent/atomic/AtomicLong", "VMSupportsCS8", "()Z", vmSupportsCS8)
+}
+
+// private static native boolean VMSupportsCS8();
+// Unsafe.compareAndSwapLong不需要加锁模拟
+func vmSupportsCS8(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(1)
+}
@@ -0,0 +1,69 @@
+package native
+
+import (
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+NativeMethod 本地方法的Go实现。参数在frame的局部变量表里，实例方法的this在0号；
+返回值按类型压入frame的操作数栈，虚拟机执行本地方法生成的返回指令把它交给调用者。
+抛出异常和指令一样用panic：panic一个Java异常对象，或者"java.lang.X: 消息"形式的字符串
+*/
+type NativeMethod func(frame *rtda.Frame)
+
+// 键是类名、方法名和描述符，例如java/lang/Throwable~fillInStackTrace~(I)Ljava/lang/Throwable;
+var registry = map[string]NativeMethod{}
+
+/*
+Register 注册本地方法的实现，className是内部形式的类名。
+虚拟机自带的实现在native下按Java包分的子包里，init时注册；嵌入虚拟机的程序可以在
+startJVM之前注册自己的实现，注册了同一个方法时后注册的替换先注册的。
+虚拟机开始运行以后不能再注册：执行中的线程读注册表时不加锁
+*/
+func Register(className, methodName, methodDescriptor string, method NativeMethod) {
+	key := className + "~" + methodName + "~" + methodDescriptor
+	registry[key] = method
+}
+
+// FindNativeMethod 查找本地方法的实现，没有注册时返回nil。
+// JDK里很多类在<clinit>里调用registerNatives向JNI注册本地方法，这里不需要，什么也不做
+func FindNativeMethod(className, methodName, methodDescriptor string) NativeMethod {
+	key := className + "~" + methodName + "~" + methodDescriptor
+	if method, ok := registry[key]; ok {
+		return method
+	}
+	if methodDescriptor == "()V" && (methodName == "registerNatives" || methodName == "initIDs") {
+		return emptyNativeMethod
+	}
+	return nil
+}
+
+func emptyNativeMethod(frame *rtda.Frame) {
+	// do nothing
+}
+
+// CallSiteTarget 链接好的invokedynamic调用点：从frame的操作数栈弹出调用点描述符里的参数，压入返回值
+type CallSiteTarget func(frame *rtda.Frame)
+
+/*
+Bootstrap 引导方法的虚拟机内置实现。虚拟机不运行java.lang.invoke的方法句柄机制，
+LambdaMetafactory、StringConcatFactory等引导方法由Go实现，直接返回调用点的目标。
+frame是执行invokedynamic的帧，callSite是调用点限定符，引导方法的静态参数从它取得；
+抛出异常和本地方法一样用panic
+*/
+type Bootstrap func(frame *rtda.Frame, callSite *heap.InvokeDynamicRef) CallSiteTarget
+
+// 键和本地方法的一样
+var bootstraps = map[string]Bootstrap{}
+
+// RegisterBootstrap 注册引导方法的实现，规则和Register一样
+func RegisterBootstrap(className, methodName, methodDescriptor string, bootstrap Bootstrap) {
+	key := className + "~" + methodName + "~" + methodDescriptor
+	bootstraps[key] = bootstrap
+}
+
+// FindBootstrap 查找引导方法的实现，没有注册时返回nil
+func FindBootstrap(className, methodName, methodDescriptor string) Bootstrap {
+	return bootstraps[className+"~"+methodName+"~"+methodDescriptor]
+}
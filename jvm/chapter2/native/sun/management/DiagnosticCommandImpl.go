@@ -0,0 +1,56 @@
+package management
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/native/java/lang"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const smDiagnosticCommandImpl = "sun/management/DiagnosticCommandImpl"
+
+func init() {
+	native.Register(smDiagnosticCommandImpl, "getDiagnosticCommands", "()[Ljava/lang/String;", getDiagnosticCommands)
+	native.Register(smDiagnosticCommandImpl, "executeDiagnosticCommand", "(Ljava/lang/String;)Ljava/lang/String;", executeDiagnosticCommand)
+}
+
+// 支持的诊断命令，和jcmd的命令同名；参数（比如Thread.print -l）不影响结果
+var diagnosticCommands = map[string]func(thread *rtda.Thread) string{
+	"Thread.print": threadPrint,
+}
+
+// private native String[] getDiagnosticCommands();
+func getDiagnosticCommands(frame *rtda.Frame) {
+	thread := frame.Thread()
+	loader := frame.Method().Class().Loader().Bootstrap()
+	names := loader.LoadClass(thread, "java/lang/String").ArrayClass(thread).NewArray(uint(len(diagnosticCommands)))
+	i := 0
+	for name := range diagnosticCommands {
+		names.Refs()[i] = heap.JString(thread, loader, name)
+		i++
+	}
+	frame.OperandStack().PushRef(names)
+}
+
+// private native String executeDiagnosticCommand(String command);
+func executeDiagnosticCommand(frame *rtda.Frame) {
+	jCommand := frame.LocalVars().GetRef(1)
+	if jCommand == nil {
+		panic("java.lang.NullPointerException")
+	}
+	fields := strings.Fields(heap.GoString(jCommand))
+	if len(fields) == 0 || diagnosticCommands[fields[0]] == nil {
+		panic("java.lang.IllegalArgumentException: Unknown diagnostic command")
+	}
+	output := diagnosticCommands[fields[0]](frame.Thread())
+	frame.OperandStack().PushRef(heap.JString(frame.Thread(), frame.Method().Class().Loader(), output))
+}
+
+// threadPrint 和SIGQUIT打印的线程转储一样
+func threadPrint(thread *rtda.Thread) string {
+	var dump strings.Builder
+	lang.PrintThreadDump(&dump, thread)
+	return dump.String()
+}
@@ -0,0 +1,39 @@
+package misc
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const smSignal = "sun/misc/Signal"
+
+func init() {
+	native.Register(smSignal, "findSignal", "(Ljava/lang/String;)I", findSignal)
+	native.Register(smSignal, "handle0", "(IJ)J", handle0)
+}
+
+// 信号名到编号，和Linux的一样
+var signalNumbers = map[string]int32{
+	"HUP":  1,
+	"INT":  2,
+	"QUIT": 3,
+	"KILL": 9,
+	"TERM": 15,
+}
+
+// private static native int findSignal(String sigName);
+// 不认识的信号返回-1
+func findSignal(frame *rtda.Frame) {
+	number, ok := signalNumbers[heap.GoString(frame.LocalVars().GetRef(0))]
+	if !ok {
+		number = -1
+	}
+	frame.OperandStack().PushInt(number)
+}
+
+// private static native long handle0(int sig, long nativeH);
+// 还不把信号交给Java代码处理，总是返回原来的处理方式是默认处理（0）
+func handle0(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(0)
+}
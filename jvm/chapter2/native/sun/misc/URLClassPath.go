@@ -0,0 +1,16 @@
+package misc
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+func init() {
+	native.Register("sun/misc/URLClassPath", "getLookupCacheURLs", "(Ljava/lang/ClassLoader;)[Ljava/net/URL;", getLookupCacheURLs)
+}
+
+// private static native URL[] getLookupCacheURLs(ClassLoader loader);
+// 没有类共享用的查找缓存，返回null
+func getLookupCacheURLs(frame *rtda.Frame) {
+	frame.OperandStack().PushRef(nil)
+}
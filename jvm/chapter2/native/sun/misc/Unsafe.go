@@ -0,0 +1,488 @@
+package misc
+
+import (
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const smUnsafe = "sun/misc/Unsafe"
+
+/*
+对象里的变量没有地址，偏移量换成虚拟机里的位置：普通对象的偏移量是实例变量的SlotId，
+数组的基址是0、每个元素占1，偏移量就是元素下标。JDK按offset = base + index*scale计算，
+只要不假设具体的值就能正常工作。对象是null时偏移量是allocateMemory分配的内存地址
+*/
+func init() {
+	native.Register(smUnsafe, "arrayBaseOffset", "(Ljava/lang/Class;)I", arrayBaseOffset)
+	native.Register(smUnsafe, "arrayIndexScale", "(Ljava/lang/Class;)I", arrayIndexScale)
+	native.Register(smUnsafe, "addressSize", "()I", addressSize)
+	native.Register(smUnsafe, "pageSize", "()I", pageSize)
+	native.Register(smUnsafe, "objectFieldOffset", "(Ljava/lang/reflect/Field;)J", objectFieldOffset)
+	native.Register(smUnsafe, "ensureClassInitialized", "(Ljava/lang/Class;)V", ensureClassInitialized)
+	native.Register(smUnsafe, "shouldBeInitialized", "(Ljava/lang/Class;)Z", shouldBeInitialized)
+	native.Register(smUnsafe, "allocateInstance", "(Ljava/lang/Class;)Ljava/lang/Object;", allocateInstance)
+	native.Register(smUnsafe, "loadFence", "()V", fence)
+	native.Register(smUnsafe, "storeFence", "()V", fence)
+	native.Register(smUnsafe, "fullFence", "()V", fence)
+
+	native.Register(smUnsafe, "compareAndSwapInt", "(Ljava/lang/Object;JII)Z", compareAndSwapInt)
+	native.Register(smUnsafe, "compareAndSwapLong", "(Ljava/lang/Object;JJJ)Z", compareAndSwapLong)
+	native.Register(smUnsafe, "compareAndSwapObject", "(Ljava/lang/Object;JLjava/lang/Object;Ljava/lang/Object;)Z", compareAndSwapObject)
+
+	// volatile的读写用原子操作；putOrdered只要求写之前的写先完成，同样用原子操作的写
+	for _, volatile := range []bool{false, true} {
+		suffix := ""
+		if volatile {
+			suffix = "Volatile"
+		}
+		for _, t := range []string{"Z", "B", "S", "C", "I"} {
+			native.Register(smUnsafe, "get"+typeNames[t]+suffix, "(Ljava/lang/Object;J)"+t, getInt(volatile))
+			native.Register(smUnsafe, "put"+typeNames[t]+suffix, "(Ljava/lang/Object;J"+t+")V", putInt(volatile))
+		}
+		native.Register(smUnsafe, "getLong"+suffix, "(Ljava/lang/Object;J)J", getLong(volatile))
+		native.Register(smUnsafe, "putLong"+suffix, "(Ljava/lang/Object;JJ)V", putLong(volatile))
+		native.Register(smUnsafe, "getFloat"+suffix, "(Ljava/lang/Object;J)F", getFloat(volatile))
+		native.Register(smUnsafe, "putFloat"+suffix, "(Ljava/lang/Object;JF)V", putFloat(volatile))
+		native.Register(smUnsafe, "getDouble"+suffix, "(Ljava/lang/Object;J)D", getDouble(volatile))
+		native.Register(smUnsafe, "putDouble"+suffix, "(Ljava/lang/Object;JD)V", putDouble(volatile))
+		native.Register(smUnsafe, "getObject"+suffix, "(Ljava/lang/Object;J)Ljava/lang/Object;", getObject(volatile))
+		native.Register(smUnsafe, "putObject"+suffix, "(Ljava/lang/Object;JLjava/lang/Object;)V", putObject(volatile))
+	}
+	native.Register(smUnsafe, "putOrderedInt", "(Ljava/lang/Object;JI)V", putInt(true))
+	native.Register(smUnsafe, "putOrderedLong", "(Ljava/lang/Object;JJ)V", putLong(true))
+	native.Register(smUnsafe, "putOrderedObject", "(Ljava/lang/Object;JLjava/lang/Object;)V", putObject(true))
+}
+
+var typeNames = map[string]string{
+	"Z": "Boolean",
+	"B": "Byte",
+	"S": "Short",
+	"C": "Char",
+	"I": "Int",
+}
+
+// public native int arrayBaseOffset(Class<?> arrayClass);
+func arrayBaseOffset(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(0)
+}
+
+// public native int arrayIndexScale(Class<?> arrayClass);
+func arrayIndexScale(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(1)
+}
+
+// public native int addressSize();
+func addressSize(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(8)
+}
+
+// public native int pageSize();
+func pageSize(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(int32(os.Getpagesize()))
+}
+
+// public native long objectFieldOffset(Field f);
+// 反射对象的clazz和slot确定字段，见Class.getDeclaredFields0
+func objectFieldOffset(frame *rtda.Frame) {
+	jField := frame.LocalVars().GetRef(1)
+	if jField == nil {
+		panic("java.lang.NullPointerException")
+	}
+	class := jField.GetRefVar("clazz", "Ljava/lang/Class;").Extra().(*heap.Class)
+	field := class.Fields()[jField.GetIntVar("slot", "I")]
+	frame.OperandStack().PushLong(int64(field.SlotId()))
+}
+
+// public native void ensureClassInitialized(Class<?> c);
+func ensureClassInitialized(frame *rtda.Frame) {
+	class := classArg(frame)
+	base.InitClass(frame.Thread(), class)
+}
+
+// public native boolean shouldBeInitialized(Class<?> c);
+func shouldBeInitialized(frame *rtda.Frame) {
+	pushBoolean(frame, !classArg(frame).IsInitialized())
+}
+
+// public native Object allocateInstance(Class<?> cls) throws InstantiationException;
+// 只创建对象，不执行构造函数
+func allocateInstance(frame *rtda.Frame) {
+	class := classArg(frame)
+	if class.IsInterface() || class.IsAbstract() || class.IsArray() || class.IsPrimitive() {
+		panic("java.lang.InstantiationException: " + class.JavaName())
+	}
+	base.InitClass(frame.Thread(), class)
+	frame.OperandStack().PushRef(class.NewObject())
+}
+
+func classArg(frame *rtda.Frame) *heap.Class {
+	jClass := frame.LocalVars().GetRef(1)
+	if jClass == nil {
+		panic("java.lang.NullPointerException")
+	}
+	return jClass.Extra().(*heap.Class)
+}
+
+// public native void loadFence();
+// public native void storeFence();
+// public native void fullFence();
+func fence(frame *rtda.Frame) {
+	// do nothing
+}
+
+// public final native boolean compareAndSwapInt(Object o, long offset, int expected, int x);
+func compareAndSwapInt(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	obj, offset := vars.GetRef(1), vars.GetLong(2)
+	pushBoolean(frame, casInt(obj, offset, vars.GetInt(4), vars.GetInt(5)))
+}
+
+// public final native boolean compareAndSwapLong(Object o, long offset, long expected, long x);
+func compareAndSwapLong(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	obj, offset := vars.GetRef(1), vars.GetLong(2)
+	pushBoolean(frame, casLong(obj, offset, vars.GetLong(4), vars.GetLong(6)))
+}
+
+// public final native boolean compareAndSwapObject(Object o, long offset, Object expected, Object x);
+func compareAndSwapObject(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	obj, offset := vars.GetRef(1), vars.GetLong(2)
+	pushBoolean(frame, casObject(obj, offset, vars.GetRef(4), vars.GetRef(5)))
+}
+
+func pushBoolean(frame *rtda.Frame, b bool) {
+	if b {
+		frame.OperandStack().PushInt(1)
+	} else {
+		frame.OperandStack().PushInt(0)
+	}
+}
+
+// public native int getInt(Object o, long offset);
+// public native int getIntVolatile(Object o, long offset);
+// boolean、byte、short和char也一样按int读写
+func getInt(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		frame.OperandStack().PushInt(loadInt(vars.GetRef(1), vars.GetLong(2), volatile))
+	}
+}
+
+// public native void putInt(Object o, long offset, int x);
+// public native void putIntVolatile(Object o, long offset, int x);
+func putInt(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		storeInt(vars.GetRef(1), vars.GetLong(2), vars.GetInt(4), volatile)
+	}
+}
+
+// public native long getLong(Object o, long offset);
+// public native long getLongVolatile(Object o, long offset);
+func getLong(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		frame.OperandStack().PushLong(loadLong(vars.GetRef(1), vars.GetLong(2), volatile))
+	}
+}
+
+// public native void putLong(Object o, long offset, long x);
+// public native void putLongVolatile(Object o, long offset, long x);
+func putLong(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		storeLong(vars.GetRef(1), vars.GetLong(2), vars.GetLong(4), volatile)
+	}
+}
+
+// public native float getFloat(Object o, long offset);
+// public native float getFloatVolatile(Object o, long offset);
+// float按位模式当作int读写，double当作long
+func getFloat(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		obj, offset := objectArg(frame)
+		bits := loadInt(obj, offset, volatile)
+		frame.OperandStack().PushFloat(math.Float32frombits(uint32(bits)))
+	}
+}
+
+// public native void putFloat(Object o, long offset, float x);
+// public native void putFloatVolatile(Object o, long offset, float x);
+func putFloat(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		obj, offset := objectArg(frame)
+		bits := math.Float32bits(frame.LocalVars().GetFloat(4))
+		storeInt(obj, offset, int32(bits), volatile)
+	}
+}
+
+// public native double getDouble(Object o, long offset);
+// public native double getDoubleVolatile(Object o, long offset);
+func getDouble(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		obj, offset := objectArg(frame)
+		bits := loadLong(obj, offset, volatile)
+		frame.OperandStack().PushDouble(math.Float64frombits(uint64(bits)))
+	}
+}
+
+// public native void putDouble(Object o, long offset, double x);
+// public native void putDoubleVolatile(Object o, long offset, double x);
+func putDouble(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		obj, offset := objectArg(frame)
+		bits := math.Float64bits(frame.LocalVars().GetDouble(4))
+		storeLong(obj, offset, int64(bits), volatile)
+	}
+}
+
+// public native Object getObject(Object o, long offset);
+// public native Object getObjectVolatile(Object o, long offset);
+func getObject(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		frame.OperandStack().PushRef(loadObject(vars.GetRef(1), vars.GetLong(2), volatile))
+	}
+}
+
+// public native void putObject(Object o, long offset, Object x);
+// public native void putObjectVolatile(Object o, long offset, Object x);
+func putObject(volatile bool) native.NativeMethod {
+	return func(frame *rtda.Frame) {
+		vars := frame.LocalVars()
+		storeObject(vars.GetRef(1), vars.GetLong(2), vars.GetRef(4), volatile)
+	}
+}
+
+// objectArg 取出参数o和offset，float和double只支持对象里的变量
+func objectArg(frame *rtda.Frame) (*heap.Object, int64) {
+	vars := frame.LocalVars()
+	obj := vars.GetRef(1)
+	if obj == nil {
+		panic("java.lang.InternalError: unsupported raw memory access")
+	}
+	return obj, vars.GetLong(2)
+}
+
+/*
+下面的函数按o的类型读写：null是堆外内存，数组是元素，普通对象是实例变量。
+volatile的读写和CAS用原子操作。sync/atomic没有8位和16位的操作，
+boolean、byte、short和char数组的元素用subwordMu保护，int、long、float、double
+和引用数组的元素直接原子操作（float和double按位模式）；堆外内存总是在nativeMemory的锁里读写
+*/
+var subwordMu sync.Mutex
+
+func loadInt(obj *heap.Object, offset int64, volatile bool) int32 {
+	if obj == nil {
+		return int32(memory.getInt(offset))
+	}
+	if !obj.Class().IsArray() {
+		if volatile {
+			return obj.Fields().GetIntVolatile(uint(offset))
+		}
+		return obj.Fields().GetInt(uint(offset))
+	}
+	switch obj.Class().Name() {
+	case "[Z", "[B", "[S", "[C":
+		if volatile {
+			subwordMu.Lock()
+			defer subwordMu.Unlock()
+		}
+		return loadSubword(obj, offset)
+	case "[F":
+		return loadInt32((*int32)(unsafe.Pointer(&obj.Floats()[offset])), volatile)
+	}
+	return loadInt32(&obj.Ints()[offset], volatile)
+}
+
+func storeInt(obj *heap.Object, offset int64, x int32, volatile bool) {
+	if obj == nil {
+		memory.putInt(offset, uint32(x))
+		return
+	}
+	if !obj.Class().IsArray() {
+		if volatile {
+			obj.Fields().SetIntVolatile(uint(offset), x)
+		} else {
+			obj.Fields().SetInt(uint(offset), x)
+		}
+		return
+	}
+	switch obj.Class().Name() {
+	case "[Z", "[B", "[S", "[C":
+		if volatile {
+			subwordMu.Lock()
+			defer subwordMu.Unlock()
+		}
+		storeSubword(obj, offset, x)
+	case "[F":
+		storeInt32((*int32)(unsafe.Pointer(&obj.Floats()[offset])), x, volatile)
+	default:
+		storeInt32(&obj.Ints()[offset], x, volatile)
+	}
+}
+
+func casInt(obj *heap.Object, offset int64, expected, x int32) bool {
+	if obj == nil {
+		return memory.casInt(offset, uint32(expected), uint32(x))
+	}
+	if !obj.Class().IsArray() {
+		return obj.Fields().CompareAndSwapInt(uint(offset), expected, x)
+	}
+	switch obj.Class().Name() {
+	case "[Z", "[B", "[S", "[C":
+		subwordMu.Lock()
+		defer subwordMu.Unlock()
+		if loadSubword(obj, offset) != expected {
+			return false
+		}
+		storeSubword(obj, offset, x)
+		return true
+	case "[F":
+		return atomic.CompareAndSwapInt32((*int32)(unsafe.Pointer(&obj.Floats()[offset])), expected, x)
+	}
+	return atomic.CompareAndSwapInt32(&obj.Ints()[offset], expected, x)
+}
+
+func loadSubword(obj *heap.Object, offset int64) int32 {
+	switch obj.Class().Name() {
+	case "[S":
+		return int32(obj.Shorts()[offset])
+	case "[C":
+		return int32(obj.Chars()[offset])
+	}
+	return int32(obj.Bytes()[offset])
+}
+
+func storeSubword(obj *heap.Object, offset int64, x int32) {
+	switch obj.Class().Name() {
+	case "[S":
+		obj.Shorts()[offset] = int16(x)
+	case "[C":
+		obj.Chars()[offset] = uint16(x)
+	default:
+		obj.Bytes()[offset] = int8(x)
+	}
+}
+
+func loadInt32(addr *int32, volatile bool) int32 {
+	if volatile {
+		return atomic.LoadInt32(addr)
+	}
+	return *addr
+}
+
+func storeInt32(addr *int32, x int32, volatile bool) {
+	if volatile {
+		atomic.StoreInt32(addr, x)
+	} else {
+		*addr = x
+	}
+}
+
+func loadLong(obj *heap.Object, offset int64, volatile bool) int64 {
+	if obj == nil {
+		return int64(memory.getLong(offset))
+	}
+	if !obj.Class().IsArray() {
+		if volatile {
+			return obj.Fields().GetLongVolatile(uint(offset))
+		}
+		return obj.Fields().GetLong(uint(offset))
+	}
+	addr := longElement(obj, offset)
+	if volatile {
+		return atomic.LoadInt64(addr)
+	}
+	return *addr
+}
+
+func storeLong(obj *heap.Object, offset int64, x int64, volatile bool) {
+	if obj == nil {
+		memory.putLong(offset, uint64(x))
+	} else if !obj.Class().IsArray() {
+		if volatile {
+			obj.Fields().SetLongVolatile(uint(offset), x)
+		} else {
+			obj.Fields().SetLong(uint(offset), x)
+		}
+	} else if volatile {
+		atomic.StoreInt64(longElement(obj, offset), x)
+	} else {
+		*longElement(obj, offset) = x
+	}
+}
+
+func casLong(obj *heap.Object, offset int64, expected, x int64) bool {
+	if obj == nil {
+		return memory.casLong(offset, uint64(expected), uint64(x))
+	}
+	if !obj.Class().IsArray() {
+		return obj.Fields().CompareAndSwapLong(uint(offset), expected, x)
+	}
+	return atomic.CompareAndSwapInt64(longElement(obj, offset), expected, x)
+}
+
+// longElement long数组和double数组的元素的地址，double按位模式读写
+func longElement(obj *heap.Object, offset int64) *int64 {
+	if obj.Class().Name() == "[D" {
+		return (*int64)(unsafe.Pointer(&obj.Doubles()[offset]))
+	}
+	return &obj.Longs()[offset]
+}
+
+func loadObject(obj *heap.Object, offset int64, volatile bool) *heap.Object {
+	if obj == nil {
+		panic("java.lang.InternalError: unsupported raw memory access")
+	}
+	if !obj.Class().IsArray() {
+		if volatile {
+			return obj.Fields().GetRefVolatile(uint(offset))
+		}
+		return obj.Fields().GetRef(uint(offset))
+	}
+	if volatile {
+		return (*heap.Object)(atomic.LoadPointer(refElement(obj, offset)))
+	}
+	return obj.Refs()[offset]
+}
+
+func storeObject(obj *heap.Object, offset int64, x *heap.Object, volatile bool) {
+	if obj == nil {
+		panic("java.lang.InternalError: unsupported raw memory access")
+	}
+	if !obj.Class().IsArray() {
+		if volatile {
+			obj.Fields().SetRefVolatile(uint(offset), x)
+		} else {
+			obj.Fields().SetRef(uint(offset), x)
+		}
+	} else if volatile {
+		atomic.StorePointer(refElement(obj, offset), unsafe.Pointer(x))
+	} else {
+		obj.Refs()[offset] = x
+	}
+}
+
+func casObject(obj *heap.Object, offset int64, expected, x *heap.Object) bool {
+	if obj == nil {
+		panic("java.lang.InternalError: unsupported raw memory access")
+	}
+	if !obj.Class().IsArray() {
+		return obj.Fields().CompareAndSwapRef(uint(offset), expected, x)
+	}
+	return atomic.CompareAndSwapPointer(refElement(obj, offset), unsafe.Pointer(expected), unsafe.Pointer(x))
+}
+
+func refElement(obj *heap.Object, offset int64) *unsafe.Pointer {
+	return (*unsafe.Pointer)(unsafe.Pointer(&obj.Refs()[offset]))
+}
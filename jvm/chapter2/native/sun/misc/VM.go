@@ -0,0 +1,16 @@
+package misc
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+func init() {
+	native.Register("sun/misc/VM", "initialize", "()V", initialize)
+}
+
+// private static native void initialize();
+// HotSpot在这里取虚拟机的版本信息，没有需要初始化的
+func initialize(frame *rtda.Frame) {
+	// do nothing
+}
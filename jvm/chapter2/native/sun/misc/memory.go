@@ -0,0 +1,191 @@
+package misc
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+func init() {
+	native.Register(smUnsafe, "allocateMemory", "(J)J", allocateMemory)
+	native.Register(smUnsafe, "reallocateMemory", "(JJ)J", reallocateMemory)
+	native.Register(smUnsafe, "freeMemory", "(J)V", freeMemory)
+	native.Register(smUnsafe, "getByte", "(J)B", getByteRaw)
+	native.Register(smUnsafe, "putByte", "(JB)V", putByteRaw)
+	native.Register(smUnsafe, "getInt", "(J)I", getIntRaw)
+	native.Register(smUnsafe, "putInt", "(JI)V", putIntRaw)
+	native.Register(smUnsafe, "getLong", "(J)J", getLongRaw)
+	native.Register(smUnsafe, "putLong", "(JJ)V", putLongRaw)
+}
+
+/*
+nativeMemory Unsafe分配的堆外内存。每块内存是一个Go切片，地址是虚拟机编的号，
+块与块之间留出空隙，越界访问会找不到内存块而抛出异常。多字节的值按小端序存放。
+所有的分配和读写都在mu里进行，所以堆外内存的读写都是volatile的
+*/
+type nativeMemory struct {
+	mu       sync.Mutex
+	blocks   map[int64][]byte // 键是块的起始地址
+	nextAddr int64
+}
+
+var memory = &nativeMemory{blocks: map[int64][]byte{}, nextAddr: 0x10000}
+
+func (mem *nativeMemory) allocate(size int64) int64 {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	return mem.allocateLocked(size)
+}
+
+func (mem *nativeMemory) allocateLocked(size int64) int64 {
+	if size < 0 {
+		panic("java.lang.IllegalArgumentException")
+	}
+	if size == 0 {
+		return 0
+	}
+	addr := mem.nextAddr
+	mem.blocks[addr] = make([]byte, size)
+	mem.nextAddr += (size+7)/8*8 + 0x1000
+	return addr
+}
+
+func (mem *nativeMemory) free(addr int64) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	delete(mem.blocks, addr)
+}
+
+// reallocate 分配size字节的新内存块，复制addr处的旧内存块再释放它；addr是0时只分配
+func (mem *nativeMemory) reallocate(addr, size int64) int64 {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	newAddr := mem.allocateLocked(size)
+	if addr != 0 {
+		copy(mem.blocks[newAddr], mem.blocks[addr])
+		delete(mem.blocks, addr)
+	}
+	return newAddr
+}
+
+// bytes 返回从addr开始的n个字节，调用时持有mu
+func (mem *nativeMemory) bytes(addr, n int64) []byte {
+	for start, block := range mem.blocks {
+		if addr >= start && addr+n <= start+int64(len(block)) {
+			return block[addr-start : addr-start+n]
+		}
+	}
+	panic("java.lang.InternalError: bad native memory address")
+}
+
+func (mem *nativeMemory) getByte(addr int64) byte {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	return mem.bytes(addr, 1)[0]
+}
+
+func (mem *nativeMemory) putByte(addr int64, x byte) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	mem.bytes(addr, 1)[0] = x
+}
+
+func (mem *nativeMemory) getInt(addr int64) uint32 {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	return binary.LittleEndian.Uint32(mem.bytes(addr, 4))
+}
+
+func (mem *nativeMemory) putInt(addr int64, x uint32) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	binary.LittleEndian.PutUint32(mem.bytes(addr, 4), x)
+}
+
+func (mem *nativeMemory) casInt(addr int64, expected, x uint32) bool {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	b := mem.bytes(addr, 4)
+	if binary.LittleEndian.Uint32(b) != expected {
+		return false
+	}
+	binary.LittleEndian.PutUint32(b, x)
+	return true
+}
+
+func (mem *nativeMemory) getLong(addr int64) uint64 {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	return binary.LittleEndian.Uint64(mem.bytes(addr, 8))
+}
+
+func (mem *nativeMemory) putLong(addr int64, x uint64) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	binary.LittleEndian.PutUint64(mem.bytes(addr, 8), x)
+}
+
+func (mem *nativeMemory) casLong(addr int64, expected, x uint64) bool {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+	b := mem.bytes(addr, 8)
+	if binary.LittleEndian.Uint64(b) != expected {
+		return false
+	}
+	binary.LittleEndian.PutUint64(b, x)
+	return true
+}
+
+// public native long allocateMemory(long bytes);
+func allocateMemory(frame *rtda.Frame) {
+	size := frame.LocalVars().GetLong(1)
+	frame.OperandStack().PushLong(memory.allocate(size))
+}
+
+// public native long reallocateMemory(long address, long bytes);
+// 地址是0时和allocateMemory一样
+func reallocateMemory(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	frame.OperandStack().PushLong(memory.reallocate(vars.GetLong(1), vars.GetLong(3)))
+}
+
+// public native void freeMemory(long address);
+func freeMemory(frame *rtda.Frame) {
+	memory.free(frame.LocalVars().GetLong(1))
+}
+
+// public native byte getByte(long address);
+func getByteRaw(frame *rtda.Frame) {
+	addr := frame.LocalVars().GetLong(1)
+	frame.OperandStack().PushInt(int32(int8(memory.getByte(addr))))
+}
+
+// public native void putByte(long address, byte x);
+func putByteRaw(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	memory.putByte(vars.GetLong(1), byte(vars.GetInt(3)))
+}
+
+// public native int getInt(long address);
+func getIntRaw(frame *rtda.Frame) {
+	frame.OperandStack().PushInt(int32(memory.getInt(frame.LocalVars().GetLong(1))))
+}
+
+// public native void putInt(long address, int x);
+func putIntRaw(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	memory.putInt(vars.GetLong(1), uint32(vars.GetInt(3)))
+}
+
+// public native long getLong(long address);
+func getLongRaw(frame *rtda.Frame) {
+	frame.OperandStack().PushLong(int64(memory.getLong(frame.LocalVars().GetLong(1))))
+}
+
+// public native void putLong(long address, long x);
+func putLongRaw(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	memory.putLong(vars.GetLong(1), uint64(vars.GetLong(3)))
+}
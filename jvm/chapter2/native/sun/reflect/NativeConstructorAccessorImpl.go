@@ -0,0 +1,29 @@
+package reflect
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+)
+
+func init() {
+	native.Register("sun/reflect/NativeConstructorAccessorImpl", "newInstance0",
+		"(Ljava/lang/reflect/Constructor;[Ljava/lang/Object;)Ljava/lang/Object;", newInstance0)
+}
+
+// private static native Object newInstance0(Constructor<?> c, Object[] args)
+// 抽象类不能实例化；构造函数抛出的异常包装成InvocationTargetException
+func newInstance0(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	thread := frame.Thread()
+	constructor := methodOf(vars.GetRef(0))
+	class := constructor.Class()
+	if class.IsAbstract() {
+		panic("java.lang.InstantiationException: " + class.JavaName())
+	}
+	args := unboxArgs(thread, constructor, vars.GetRef(1))
+	base.InitClass(thread, class)
+	obj := class.NewObject()
+	invoke(thread, constructor, obj, args)
+	frame.OperandStack().PushRef(obj)
+}
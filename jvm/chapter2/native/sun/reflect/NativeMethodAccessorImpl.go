@@ -0,0 +1,224 @@
+package reflect
+
+import (
+	"go.buppt.cn/jvm/chapter2/instructions/base"
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+func init() {
+	native.Register("sun/reflect/NativeMethodAccessorImpl", "invoke0",
+		"(Ljava/lang/reflect/Method;Ljava/lang/Object;[Ljava/lang/Object;)Ljava/lang/Object;", invoke0)
+}
+
+// private static native Object invoke0(Method m, Object obj, Object[] args);
+// 实例方法按obj的类重新查找，私有方法直接调用；基本类型的返回值装箱
+func invoke0(frame *rtda.Frame) {
+	vars := frame.LocalVars()
+	thread := frame.Thread()
+	method := methodOf(vars.GetRef(0))
+	obj := vars.GetRef(1)
+	class := method.Class()
+	if method.IsStatic() {
+		base.InitClass(thread, class)
+		obj = nil
+	} else {
+		if obj == nil {
+			panic("java.lang.NullPointerException")
+		}
+		if !obj.IsInstanceOf(class) {
+			panic("java.lang.IllegalArgumentException: object is not an instance of declaring class")
+		}
+		if !method.IsPrivate() {
+			method = obj.Class().LookupInstanceMethod(method.Name(), method.Descriptor())
+		}
+		if method == nil || method.IsAbstract() {
+			panic("java.lang.AbstractMethodError")
+		}
+	}
+	args := unboxArgs(thread, method, vars.GetRef(2))
+	stack := invoke(thread, method, obj, args)
+	frame.OperandStack().PushRef(boxResult(thread, method, stack))
+}
+
+// methodOf 反射对象的clazz和slot字段确定方法，见Class.getDeclaredMethods0
+func methodOf(jMethod *heap.Object) *heap.Method {
+	class := classOf(jMethod.GetRefVar("clazz", "Ljava/lang/Class;"))
+	return class.Methods()[jMethod.GetIntVar("slot", "I")]
+}
+
+// 包装类的类名到基本类型的描述符
+var wrapperTypes = map[string]string{
+	"java/lang/Boolean":   "Z",
+	"java/lang/Byte":      "B",
+	"java/lang/Character": "C",
+	"java/lang/Short":     "S",
+	"java/lang/Integer":   "I",
+	"java/lang/Long":      "J",
+	"java/lang/Float":     "F",
+	"java/lang/Double":    "D",
+}
+
+// 基本类型的描述符到它能拓宽转换成的类型，见JLS 5.1.2
+var widenings = map[string]string{
+	"Z": "Z",
+	"B": "BSIJFD",
+	"C": "CIJFD",
+	"S": "SIJFD",
+	"I": "IJFD",
+	"J": "JFD",
+	"F": "FD",
+	"D": "D",
+}
+
+// unboxArgs 检查参数的个数和类型，返回要压栈的参数：引用类型是*heap.Object，
+// 基本类型拆箱并拓宽成int32、int64、float32或float64
+func unboxArgs(thread *rtda.Thread, method *heap.Method, jArgs *heap.Object) []interface{} {
+	paramTypes := method.ParameterTypes(thread)
+	var argRefs []*heap.Object
+	if jArgs != nil {
+		argRefs = jArgs.Refs()
+	}
+	if len(argRefs) != len(paramTypes) {
+		panic("java.lang.IllegalArgumentException: wrong number of arguments")
+	}
+	args := make([]interface{}, len(paramTypes))
+	for i, paramType := range paramTypes {
+		arg := argRefs[i]
+		if !paramType.IsPrimitive() {
+			if arg != nil && !arg.IsInstanceOf(paramType) {
+				panic("java.lang.IllegalArgumentException: argument type mismatch")
+			}
+			args[i] = arg
+			continue
+		}
+		args[i] = unbox(arg, paramType.Descriptor())
+	}
+	return args
+}
+
+// unbox 取出包装对象的值，转换成descriptor表示的基本类型
+func unbox(arg *heap.Object, descriptor string) interface{} {
+	if arg == nil {
+		panic("java.lang.IllegalArgumentException")
+	}
+	argType, ok := "", false
+	if arg.Class().Loader().IsBootstrap() {
+		argType, ok = wrapperTypes[arg.Class().Name()]
+	}
+	if !ok || !containsType(widenings[argType], descriptor) {
+		panic("java.lang.IllegalArgumentException: argument type mismatch")
+	}
+	slot := valueField(arg.Class()).SlotId()
+	fields := arg.Fields()
+	switch argType {
+	case "J":
+		return convert(float64(fields.GetLong(slot)), fields.GetLong(slot), descriptor)
+	case "F":
+		return convert(float64(fields.GetFloat(slot)), 0, descriptor)
+	case "D":
+		return convert(fields.GetDouble(slot), 0, descriptor)
+	}
+	i := fields.GetInt(slot)
+	return convert(float64(i), int64(i), descriptor)
+}
+
+// convert 拓宽转换：目标是整数类型时用i，是浮点类型时用f
+func convert(f float64, i int64, descriptor string) interface{} {
+	switch descriptor {
+	case "J":
+		return i
+	case "F":
+		return float32(f)
+	case "D":
+		return f
+	}
+	return int32(i)
+}
+
+func containsType(types, descriptor string) bool {
+	for i := range types {
+		if types[i:i+1] == descriptor {
+			return true
+		}
+	}
+	return false
+}
+
+// valueField 包装类保存值的字段
+func valueField(wrapperClass *heap.Class) *heap.Field {
+	for _, field := range wrapperClass.Fields() {
+		if field.Name() == "value" && !field.IsStatic() {
+			return field
+		}
+	}
+	panic("java.lang.NoSuchFieldError: value")
+}
+
+// invoke 执行方法，方法抛出的异常包装成InvocationTargetException
+func invoke(thread *rtda.Thread, method *heap.Method, this *heap.Object, args []interface{}) *rtda.OperandStack {
+	defer func() {
+		if r := recover(); r != nil {
+			ex := base.ToThrowable(thread, r)
+			if ex == nil {
+				panic(r)
+			}
+			boot := method.Class().Loader().Bootstrap()
+			ite := boot.LoadClass(thread, "java/lang/reflect/InvocationTargetException")
+			panic(base.NewObject(thread, ite, "(Ljava/lang/Throwable;)V", ex))
+		}
+	}()
+	return base.RunMethodWithArgs(thread, method, func(stack *rtda.OperandStack) {
+		if this != nil {
+			stack.PushRef(this)
+		}
+		for _, arg := range args {
+			switch v := arg.(type) {
+			case int32:
+				stack.PushInt(v)
+			case int64:
+				stack.PushLong(v)
+			case float32:
+				stack.PushFloat(v)
+			case float64:
+				stack.PushDouble(v)
+			case *heap.Object:
+				stack.PushRef(v)
+			}
+		}
+	})
+}
+
+// boxResult 按返回值类型从栈里取出返回值，基本类型装箱；void返回null
+func boxResult(thread *rtda.Thread, method *heap.Method, stack *rtda.OperandStack) *heap.Object {
+	returnType := method.ReturnType(thread)
+	if !returnType.IsPrimitive() {
+		return stack.PopRef()
+	}
+	descriptor := returnType.Descriptor()
+	if descriptor == "V" {
+		return nil
+	}
+	var wrapperName string
+	for name, wrapperType := range wrapperTypes {
+		if wrapperType == descriptor {
+			wrapperName = name
+		}
+	}
+	wrapperClass := method.Class().Loader().Bootstrap().LoadClass(thread, wrapperName)
+	base.InitClass(thread, wrapperClass)
+	boxed := wrapperClass.NewObject()
+	slot := valueField(wrapperClass).SlotId()
+	switch descriptor {
+	case "J":
+		boxed.Fields().SetLong(slot, stack.PopLong())
+	case "F":
+		boxed.Fields().SetFloat(slot, stack.PopFloat())
+	case "D":
+		boxed.Fields().SetDouble(slot, stack.PopDouble())
+	default:
+		boxed.Fields().SetInt(slot, stack.PopInt())
+	}
+	return boxed
+}
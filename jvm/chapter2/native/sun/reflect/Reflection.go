@@ -0,0 +1,91 @@
+package reflect
+
+import (
+	"go.buppt.cn/jvm/chapter2/native"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+const srReflection = "sun/reflect/Reflection"
+
+func init() {
+	native.Register(srReflection, "getCallerClass", "()Ljava/lang/Class;", getCallerClass)
+	native.Register(srReflection, "getCallerClass", "(I)Ljava/lang/Class;", getCallerClassDepth)
+	native.Register(srReflection, "getClassAccessFlags", "(Ljava/lang/Class;)I", getClassAccessFlags)
+}
+
+// public static native Class<?> getCallerClass();
+// 栈顶是getCallerClass自己，下面是调用它的@CallerSensitive方法，再往下第一个不属于反射实现的帧
+// 就是调用者；没有时返回null
+func getCallerClass(frame *rtda.Frame) {
+	frames := frame.Thread().Frames()
+	var caller *heap.Class
+	if len(frames) > 2 {
+		for _, f := range frames[2:] {
+			if !isIgnoredFrame(f) {
+				caller = f.Method().Class()
+				break
+			}
+		}
+	}
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), caller))
+}
+
+// public static native Class<?> getCallerClass(int depth);
+// 不算反射实现的帧，第0帧是Reflection自己
+func getCallerClassDepth(frame *rtda.Frame) {
+	depth := frame.LocalVars().GetInt(0)
+	var caller *heap.Class
+	for i, f := range frame.Thread().Frames() {
+		if i > 0 && isIgnoredFrame(f) {
+			continue
+		}
+		if depth == 0 {
+			caller = f.Method().Class()
+			break
+		}
+		depth--
+	}
+	frame.OperandStack().PushRef(jClassOf(frame.Thread(), caller))
+}
+
+// isIgnoredFrame 和HotSpot一样，找调用者时跳过Method.invoke和MethodAccessorImpl的子类，
+// 还有虚拟机执行Java方法时垫底的帧
+func isIgnoredFrame(frame *rtda.Frame) bool {
+	method := frame.Method()
+	if method == nil {
+		return true
+	}
+	class := method.Class()
+	if !class.Loader().IsBootstrap() {
+		return false
+	}
+	if class.Name() == "java/lang/reflect/Method" && method.Name() == "invoke" {
+		return true
+	}
+	for c := class; c != nil; c = c.SuperClass() {
+		if c.Name() == "sun/reflect/MethodAccessorImpl" {
+			return true
+		}
+	}
+	return false
+}
+
+// public static native int getClassAccessFlags(Class<?> c);
+// 类文件里的访问标志，不看InnerClasses属性
+func getClassAccessFlags(frame *rtda.Frame) {
+	class := classOf(frame.LocalVars().GetRef(0))
+	frame.OperandStack().PushInt(int32(class.AccessFlags()))
+}
+
+// Class对象的extra是它表示的类
+func classOf(jClass *heap.Object) *heap.Class {
+	return jClass.Extra().(*heap.Class)
+}
+
+func jClassOf(thread *rtda.Thread, class *heap.Class) *heap.Object {
+	if class == nil {
+		return nil
+	}
+	return class.JClass(thread)
+}
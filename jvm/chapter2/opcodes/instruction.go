@@ -0,0 +1,305 @@
+package opcodes
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// OperandKind 描述指令操作数的格式
+type OperandKind uint8
+
+const (
+	NoOperands     OperandKind = iota
+	Byte                       // bipush
+	Short                      // sipush
+	CPRefByte                  // ldc，u1常量池索引
+	CPRef                      // u2常量池索引
+	CPRefCountZero             // invokeinterface，u2索引 + count + 0
+	CPRefZeroZero              // invokedynamic，u2索引 + 0 + 0
+	CPRefDims                  // multianewarray，u2索引 + 维数
+	Local                      // u1局部变量索引，wide时为u2
+	LocalByte                  // iinc，wide时为u2索引 + s2常量
+	Branch                     // s2跳转偏移
+	BranchWide                 // s4跳转偏移
+	ArrayType                  // newarray的atype
+	TableSwitch
+	LookupSwitch
+	WidePrefix
+)
+
+// newarray的atype
+const (
+	TBoolean = 4
+	TChar    = 5
+	TFloat   = 6
+	TDouble  = 7
+	TByte    = 8
+	TShort   = 9
+	TInt     = 10
+	TLong    = 11
+)
+
+var arrayTypeNames = map[int]string{
+	TBoolean: "boolean",
+	TChar:    "char",
+	TFloat:   "float",
+	TDouble:  "double",
+	TByte:    "byte",
+	TShort:   "short",
+	TInt:     "int",
+	TLong:    "long",
+}
+
+// Name 返回操作码的助记符，未定义的操作码返回空串
+func Name(opcode uint8) string {
+	return names[opcode]
+}
+
+// Kind 返回操作码的操作数格式
+func Kind(opcode uint8) OperandKind {
+	return kinds[opcode]
+}
+
+// Lookup 根据助记符查找操作码
+func Lookup(name string) (uint8, bool) {
+	opcode, ok := byName[name]
+	return opcode, ok
+}
+
+// ArrayTypeName 返回newarray的atype对应的基本类型名
+func ArrayTypeName(atype int) string {
+	return arrayTypeNames[atype]
+}
+
+// ArrayTypeByName 根据基本类型名查找newarray的atype
+func ArrayTypeByName(name string) (int, bool) {
+	for atype, n := range arrayTypeNames {
+		if n == name {
+			return atype, true
+		}
+	}
+	return 0, false
+}
+
+var byName = map[string]uint8{}
+
+func init() {
+	for i, name := range names {
+		if name != "" {
+			byName[name] = uint8(i)
+		}
+	}
+}
+
+// Instruction 是解码后的一条指令
+// Index是常量池索引或局部变量索引，Value是立即数、iinc的增量、维数或invokeinterface的count
+type Instruction struct {
+	PC      int
+	Opcode  uint8
+	Wide    bool // 是否带wide前缀，此时PC指向wide
+	Length  int
+	Index   int
+	Value   int
+	Branch  int   // 跳转目标的绝对地址
+	Default int   // switch的default目标
+	Low     int32 // tableswitch的下界
+	Keys    []int32
+	Targets []int // switch各分支的绝对地址
+}
+
+// Name 返回助记符，wide形式的指令加_w后缀，和javap一致
+func (instruction *Instruction) Name() string {
+	if instruction.Wide {
+		return names[instruction.Opcode] + "_w"
+	}
+	return names[instruction.Opcode]
+}
+
+// Decode 解码code[pc]处的一条指令
+func Decode(code []byte, pc int) (*Instruction, error) {
+	if pc < 0 || pc >= len(code) {
+		return nil, fmt.Errorf("pc %d out of code range", pc)
+	}
+	instr := &Instruction{PC: pc, Opcode: code[pc]}
+	if names[instr.Opcode] == "" {
+		return nil, fmt.Errorf("illegal opcode 0x%02x at %d", instr.Opcode, pc)
+	}
+	need := func(n int) error {
+		if pc+n > len(code) {
+			return fmt.Errorf("truncated %s at %d", names[instr.Opcode], pc)
+		}
+		return nil
+	}
+	u1 := func(off int) int { return int(code[pc+off]) }
+	u2 := func(off int) int { return int(binary.BigEndian.Uint16(code[pc+off:])) }
+	s4 := func(off int) int32 { return int32(binary.BigEndian.Uint32(code[pc+off:])) }
+
+	switch kinds[instr.Opcode] {
+	case NoOperands:
+		instr.Length = 1
+	case Byte:
+		instr.Length = 2
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		instr.Value = int(int8(code[pc+1]))
+	case Short:
+		instr.Length = 3
+		if err := need(3); err != nil {
+			return nil, err
+		}
+		instr.Value = int(int16(u2(1)))
+	case CPRefByte, Local, ArrayType:
+		instr.Length = 2
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		instr.Index = u1(1)
+		if kinds[instr.Opcode] == ArrayType {
+			instr.Index, instr.Value = 0, u1(1)
+		}
+	case CPRef:
+		instr.Length = 3
+		if err := need(3); err != nil {
+			return nil, err
+		}
+		instr.Index = u2(1)
+	case CPRefCountZero, CPRefZeroZero:
+		instr.Length = 5
+		if err := need(5); err != nil {
+			return nil, err
+		}
+		instr.Index = u2(1)
+		instr.Value = u1(3)
+	case CPRefDims:
+		instr.Length = 4
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		instr.Index = u2(1)
+		instr.Value = u1(3)
+	case LocalByte:
+		instr.Length = 3
+		if err := need(3); err != nil {
+			return nil, err
+		}
+		instr.Index = u1(1)
+		instr.Value = int(int8(code[pc+2]))
+	case Branch:
+		instr.Length = 3
+		if err := need(3); err != nil {
+			return nil, err
+		}
+		instr.Branch = pc + int(int16(u2(1)))
+	case BranchWide:
+		instr.Length = 5
+		if err := need(5); err != nil {
+			return nil, err
+		}
+		instr.Branch = pc + int(s4(1))
+	case TableSwitch, LookupSwitch:
+		return decodeSwitch(code, instr)
+	case WidePrefix:
+		return decodeWide(code, instr)
+	}
+	return instr, nil
+}
+
+// tableswitch和lookupswitch的操作数要按4字节对齐
+func decodeSwitch(code []byte, instr *Instruction) (*Instruction, error) {
+	pc := instr.PC
+	off := (pc + 4) &^ 3
+	s4 := func() (int32, error) {
+		if off+4 > len(code) {
+			return 0, fmt.Errorf("truncated %s at %d", names[instr.Opcode], pc)
+		}
+		v := int32(binary.BigEndian.Uint32(code[off:]))
+		off += 4
+		return v, nil
+	}
+	def, err := s4()
+	if err != nil {
+		return nil, err
+	}
+	instr.Default = pc + int(def)
+
+	if instr.Opcode == Tableswitch {
+		low, err := s4()
+		if err != nil {
+			return nil, err
+		}
+		high, err := s4()
+		if err != nil {
+			return nil, err
+		}
+		if low > high {
+			return nil, fmt.Errorf("tableswitch at %d has low %d > high %d", pc, low, high)
+		}
+		n := int64(high) - int64(low) + 1
+		if int64(off)+n*4 > int64(len(code)) {
+			return nil, fmt.Errorf("truncated tableswitch at %d", pc)
+		}
+		instr.Low = low
+		instr.Targets = make([]int, n)
+		for i := range instr.Targets {
+			offset, _ := s4()
+			instr.Targets[i] = pc + int(offset)
+		}
+	} else {
+		npairs, err := s4()
+		if err != nil {
+			return nil, err
+		}
+		if npairs < 0 || int64(off)+int64(npairs)*8 > int64(len(code)) {
+			return nil, fmt.Errorf("bad lookupswitch at %d", pc)
+		}
+		instr.Keys = make([]int32, npairs)
+		instr.Targets = make([]int, npairs)
+		for i := range instr.Keys {
+			instr.Keys[i], _ = s4()
+			offset, _ := s4()
+			instr.Targets[i] = pc + int(offset)
+		}
+	}
+	instr.Length = off - pc
+	return instr, nil
+}
+
+func decodeWide(code []byte, instr *Instruction) (*Instruction, error) {
+	pc := instr.PC
+	if pc+1 >= len(code) {
+		return nil, fmt.Errorf("truncated wide at %d", pc)
+	}
+	instr.Opcode = code[pc+1]
+	instr.Wide = true
+	switch kinds[instr.Opcode] {
+	case Local:
+		instr.Length = 4
+	case LocalByte:
+		instr.Length = 6
+	default:
+		return nil, fmt.Errorf("illegal wide opcode 0x%02x at %d", instr.Opcode, pc)
+	}
+	if pc+instr.Length > len(code) {
+		return nil, fmt.Errorf("truncated wide at %d", pc)
+	}
+	instr.Index = int(binary.BigEndian.Uint16(code[pc+2:]))
+	if instr.Length == 6 {
+		instr.Value = int(int16(binary.BigEndian.Uint16(code[pc+4:])))
+	}
+	return instr, nil
+}
+
+// DecodeAll 顺序解码整段字节码
+func DecodeAll(code []byte) ([]*Instruction, error) {
+	var instrs []*Instruction
+	for pc := 0; pc < len(code); {
+		instr, err := Decode(code, pc)
+		if err != nil {
+			return instrs, err
+		}
+		instrs = append(instrs, instr)
+		pc += instr.Length
+	}
+	return instrs, nil
+}
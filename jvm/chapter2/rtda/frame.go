@@ -0,0 +1,78 @@
+package rtda
+
+import "go.buppt.cn/jvm/chapter2/rtda/heap"
+
+// Frame 栈帧，每次方法调用创建一个
+type Frame struct {
+	lower        *Frame // 链表实现Java虚拟机栈，指向调用者的帧
+	localVars    LocalVars
+	operandStack *OperandStack
+	thread       *Thread
+	nextPC       int            // 下一条要执行的指令
+	method       *heap.Method   // 正在执行的方法
+	monitors     []*heap.Object // 在这个帧里进入的监视器：同步方法的锁和monitorenter的对象
+}
+
+func newFrame(thread *Thread, maxLocals, maxStack uint) *Frame {
+	return &Frame{
+		thread:       thread,
+		localVars:    newLocalVars(maxLocals),
+		operandStack: newOperandStack(maxStack),
+	}
+}
+
+func (frame *Frame) LocalVars() LocalVars {
+	return frame.localVars
+}
+
+func (frame *Frame) OperandStack() *OperandStack {
+	return frame.operandStack
+}
+
+func (frame *Frame) Thread() *Thread {
+	return frame.thread
+}
+
+func (frame *Frame) NextPC() int {
+	return frame.nextPC
+}
+
+func (frame *Frame) SetNextPC(nextPC int) {
+	frame.nextPC = nextPC
+}
+
+func (frame *Frame) Method() *heap.Method {
+	return frame.method
+}
+
+// Lock 进入object的监视器，帧弹出时还没有退出的由PopFrame退出
+func (frame *Frame) Lock(object *heap.Object) {
+	MonitorEnter(frame.thread, object)
+	frame.monitors = append(frame.monitors, object)
+}
+
+// Unlock 退出object的监视器，当前线程不是持有者时抛出IllegalMonitorStateException
+func (frame *Frame) Unlock(object *heap.Object) {
+	MonitorExit(frame.thread, object)
+	for i := len(frame.monitors) - 1; i >= 0; i-- {
+		if frame.monitors[i] == object {
+			frame.monitors = append(frame.monitors[:i], frame.monitors[i+1:]...)
+			break
+		}
+	}
+}
+
+// Monitors 返回帧持有的监视器，先进入的在前
+func (frame *Frame) Monitors() []*heap.Object {
+	return frame.monitors
+}
+
+// releaseMonitors 后进入的先退出；已经不持有的监视器跳过
+func (frame *Frame) releaseMonitors() {
+	for i := len(frame.monitors) - 1; i >= 0; i-- {
+		if HoldsLock(frame.thread, frame.monitors[i]) {
+			MonitorExit(frame.thread, frame.monitors[i])
+		}
+	}
+	frame.monitors = nil
+}
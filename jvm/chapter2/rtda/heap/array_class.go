@@ -0,0 +1,172 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+// IsArray 是否数组类，数组类的名字是描述符形式，例如[I、[[Ljava/lang/String;
+func (class *Class) IsArray() bool {
+	return class.name[0] == '['
+}
+
+// ComponentClass 返回数组的组件类型，例如[[I的组件类型是[I
+func (class *Class) ComponentClass() *Class {
+	return class.componentClass
+}
+
+// NewArray 创建这个数组类的实例，元素都是零值；count不能是负数
+func (class *Class) NewArray(count uint) *Object {
+	if !class.IsArray() {
+		panic("Not array class: " + class.name)
+	}
+	var data interface{}
+	switch class.name[1] {
+	case 'Z', 'B':
+		data = make([]int8, count)
+	case 'C':
+		data = make([]uint16, count)
+	case 'S':
+		data = make([]int16, count)
+	case 'I':
+		data = make([]int32, count)
+	case 'J':
+		data = make([]int64, count)
+	case 'F':
+		data = make([]float32, count)
+	case 'D':
+		data = make([]float64, count)
+	default:
+		data = make([]*Object, count)
+	}
+	return &Object{class: class, data: data}
+}
+
+// NewMultiArray 和multianewarray指令一样逐维创建这个数组类的实例，第i维的长度是counts[i]。
+// counts可以比维数少，剩下的维元素是null；任何一维是负数都不创建数组
+func (class *Class) NewMultiArray(counts []int32) *Object {
+	for _, count := range counts {
+		if count < 0 {
+			panic("java.lang.NegativeArraySizeException")
+		}
+	}
+	return class.newMultiArray(counts)
+}
+
+func (class *Class) newMultiArray(counts []int32) *Object {
+	arr := class.NewArray(uint(counts[0]))
+	if len(counts) > 1 {
+		refs := arr.Refs()
+		for i := range refs {
+			refs[i] = class.componentClass.newMultiArray(counts[1:])
+		}
+	}
+	return arr
+}
+
+// ArrayClass 返回元素类型是这个类的数组类
+func (class *Class) ArrayClass(thread Thread) *Class {
+	return class.loader.LoadClass(thread, "["+class.Descriptor())
+}
+
+// Descriptor 返回这个类型的描述符，例如I、[I、Ljava/lang/Object;
+func (class *Class) Descriptor() string {
+	if class.IsArray() {
+		return class.name
+	}
+	if descriptor, ok := primitiveDescriptors[class.name]; ok && class.IsPrimitive() {
+		return descriptor
+	}
+	return "L" + class.name + ";"
+}
+
+// IsPrimitive 是否基本类型（包括void）的类，例如int.class
+func (class *Class) IsPrimitive() bool {
+	return class.loader.shared.primitives[class.name] == class
+}
+
+// 基本类型的名字和描述符
+var primitiveDescriptors = map[string]string{
+	"void":    "V",
+	"boolean": "Z",
+	"byte":    "B",
+	"short":   "S",
+	"int":     "I",
+	"long":    "J",
+	"char":    "C",
+	"float":   "F",
+	"double":  "D",
+}
+
+// 描述符到基本类型名，void不能作为数组元素
+var primitiveNames = map[string]string{
+	"Z": "boolean",
+	"B": "byte",
+	"S": "short",
+	"I": "int",
+	"J": "long",
+	"C": "char",
+	"F": "float",
+	"D": "double",
+}
+
+// 基本类型的类由启动类加载器创建，没有超类，不需要初始化
+func newPrimitiveClasses(boot *ClassLoader) map[string]*Class {
+	primitives := make(map[string]*Class, len(primitiveDescriptors))
+	for name := range primitiveDescriptors {
+		primitives[name] = &Class{
+			accessFlags: classfile.ACC_PUBLIC | classfile.ACC_FINAL | classfile.ACC_ABSTRACT,
+			name:        name,
+			loader:      boot,
+			initState:   fullyInitialized,
+		}
+	}
+	return primitives
+}
+
+// PrimitiveClass 按名字返回基本类型的类，例如int，Class.getPrimitiveClass用
+func (classLoader *ClassLoader) PrimitiveClass(name string) *Class {
+	return classLoader.shared.primitives[name]
+}
+
+// loadArrayClass 按JVMS 5.3.3创建数组类：组件类型是引用类型时，由加载组件类型的加载器定义，
+// 否则由启动类加载器定义；数组类的超类是java/lang/Object，实现Cloneable和Serializable。
+// 组件类型找不到时返回nil
+func (classLoader *ClassLoader) loadArrayClass(thread Thread, name string) *Class {
+	var component *Class
+	descriptor := name[1:]
+	switch descriptor[0] {
+	case '[':
+		component = classLoader.loadClass(thread, descriptor)
+	case 'L':
+		if len(descriptor) < 3 || descriptor[len(descriptor)-1] != ';' {
+			return nil
+		}
+		component = classLoader.loadClass(thread, descriptor[1:len(descriptor)-1])
+	default:
+		if primitiveName, ok := primitiveNames[descriptor]; ok {
+			component = classLoader.shared.primitives[primitiveName]
+		}
+	}
+	if component == nil {
+		return nil
+	}
+	loader := component.loader
+	if class, ok := loader.classMap[name]; ok {
+		return class
+	}
+	boot := classLoader.shared.boot
+	class := &Class{
+		accessFlags:    component.accessFlags&classfile.ACC_PUBLIC | classfile.ACC_FINAL | classfile.ACC_ABSTRACT,
+		name:           name,
+		loader:         loader,
+		initState:      fullyInitialized,
+		componentClass: component,
+		superClass:     boot.LoadClass(thread, "java/lang/Object"),
+		interfaces: []*Class{
+			boot.LoadClass(thread, "java/lang/Cloneable"),
+			boot.LoadClass(thread, "java/io/Serializable"),
+		},
+	}
+	class.vtable = class.superClass.vtable
+	buildItable(class)
+	loader.recordClass(name, class)
+	return class
+}
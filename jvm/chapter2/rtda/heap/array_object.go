@@ -0,0 +1,84 @@
+package heap
+
+// 数组的元素按类型存成Go切片：boolean和byte数组都是[]int8，
+// char数组是[]uint16，引用类型的数组是[]*Object
+
+func (object *Object) Bytes() []int8 {
+	return object.data.([]int8)
+}
+
+func (object *Object) Shorts() []int16 {
+	return object.data.([]int16)
+}
+
+func (object *Object) Chars() []uint16 {
+	return object.data.([]uint16)
+}
+
+func (object *Object) Ints() []int32 {
+	return object.data.([]int32)
+}
+
+func (object *Object) Longs() []int64 {
+	return object.data.([]int64)
+}
+
+func (object *Object) Floats() []float32 {
+	return object.data.([]float32)
+}
+
+func (object *Object) Doubles() []float64 {
+	return object.data.([]float64)
+}
+
+func (object *Object) Refs() []*Object {
+	return object.data.([]*Object)
+}
+
+// ArrayLength 对象必须是数组
+func (object *Object) ArrayLength() int32 {
+	switch data := object.data.(type) {
+	case []int8:
+		return int32(len(data))
+	case []int16:
+		return int32(len(data))
+	case []uint16:
+		return int32(len(data))
+	case []int32:
+		return int32(len(data))
+	case []int64:
+		return int32(len(data))
+	case []float32:
+		return int32(len(data))
+	case []float64:
+		return int32(len(data))
+	case []*Object:
+		return int32(len(data))
+	}
+	panic("Not array!")
+}
+
+// ArrayCopy 把src[srcPos:srcPos+length]复制到dst[dstPos:]，两个数组的元素类型必须一样，
+// 下标由调用者检查过；同一个数组里重叠复制时和先复制到临时数组的结果一样
+func ArrayCopy(src, dst *Object, srcPos, dstPos, length int32) {
+	switch s := src.data.(type) {
+	case []int8:
+		copy(dst.data.([]int8)[dstPos:], s[srcPos:srcPos+length])
+	case []int16:
+		copy(dst.data.([]int16)[dstPos:], s[srcPos:srcPos+length])
+	case []uint16:
+		copy(dst.data.([]uint16)[dstPos:], s[srcPos:srcPos+length])
+	case []int32:
+		copy(dst.data.([]int32)[dstPos:], s[srcPos:srcPos+length])
+	case []int64:
+		copy(dst.data.([]int64)[dstPos:], s[srcPos:srcPos+length])
+	case []float32:
+		copy(dst.data.([]float32)[dstPos:], s[srcPos:srcPos+length])
+	case []float64:
+		copy(dst.data.([]float64)[dstPos:], s[srcPos:srcPos+length])
+	case []*Object:
+		copy(dst.data.([]*Object)[dstPos:], s[srcPos:srcPos+length])
+	default:
+		panic("Not array!")
+	}
+}
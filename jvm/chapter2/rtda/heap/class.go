@@ -0,0 +1,255 @@
+package heap
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// Class 方法区中的类，由ClassLoader从class文件创建
+type Class struct {
+	accessFlags       uint16
+	name              string // 内部形式，例如java/lang/Object
+	superClassName    string
+	interfaceNames    []string
+	constantPool      *ConstantPool
+	fields            []*Field
+	methods           []*Method
+	loader            *ClassLoader
+	superClass        *Class
+	interfaces        []*Class
+	instanceSlotCount uint
+	staticSlotCount   uint
+	staticVars        Slots
+	initState         initState
+	initThread        Thread                 // 正在初始化这个类的线程，见StartInit
+	sourceFile        string                 // SourceFile属性，栈轨迹里用
+	classFile         *classfile.ClassFile   // 数组类和基本类型的类没有
+	componentClass    *Class                 // 数组类的组件类型
+	vtable            []*Method              // 虚方法表，见method_table.go
+	itable            []itableEntry          // 接口方法表
+	jClass            atomic.Pointer[Object] // Java代码看到的java.lang.Class对象，第一次用到时创建
+	hostClass         *Class                 // 匿名类的宿主类，见DefineAnonymousClass
+	finalFields       bool                   // 声明了final实例变量，构造函数返回时要冻结它们
+}
+
+func newClass(cf *classfile.ClassFile) *Class {
+	class := &Class{}
+	class.accessFlags = cf.AccessFlags()
+	class.name = cf.ClassName()
+	class.superClassName = cf.SuperClassName()
+	class.interfaceNames = cf.InterfaceNames()
+	class.constantPool = newConstantPool(class, cf.ConstantPool())
+	class.fields = newFields(class, cf.Fields())
+	for _, field := range class.fields {
+		if field.IsFinal() && !field.IsStatic() {
+			class.finalFields = true
+		}
+	}
+	class.methods = newMethods(class, cf.Methods())
+	class.sourceFile = getSourceFile(cf)
+	class.classFile = cf
+	return class
+}
+
+// 没有SourceFile属性时是空串，栈轨迹里显示为Unknown Source
+func getSourceFile(cf *classfile.ClassFile) string {
+	if sfAttr := cf.SourceFileAttribute(); sfAttr != nil {
+		return sfAttr.FileName()
+	}
+	return ""
+}
+
+func (class *Class) IsPublic() bool {
+	return class.accessFlags&classfile.ACC_PUBLIC != 0
+}
+
+func (class *Class) IsFinal() bool {
+	return class.accessFlags&classfile.ACC_FINAL != 0
+}
+
+func (class *Class) IsSuper() bool {
+	return class.accessFlags&classfile.ACC_SUPER != 0
+}
+
+func (class *Class) IsInterface() bool {
+	return class.accessFlags&classfile.ACC_INTERFACE != 0
+}
+
+func (class *Class) IsAbstract() bool {
+	return class.accessFlags&classfile.ACC_ABSTRACT != 0
+}
+
+func (class *Class) IsSynthetic() bool {
+	return class.accessFlags&classfile.ACC_SYNTHETIC != 0
+}
+
+func (class *Class) IsAnnotation() bool {
+	return class.accessFlags&classfile.ACC_ANNOTATION != 0
+}
+
+func (class *Class) IsEnum() bool {
+	return class.accessFlags&classfile.ACC_ENUM != 0
+}
+
+func (class *Class) AccessFlags() uint16 {
+	return class.accessFlags
+}
+
+// Name 返回内部形式的类名
+func (class *Class) Name() string {
+	return class.name
+}
+
+// JavaName 返回Java语言里的类名，例如java.lang.Object，异常消息里用
+func (class *Class) JavaName() string {
+	return strings.Replace(class.name, "/", ".", -1)
+}
+
+func (class *Class) ConstantPool() *ConstantPool {
+	return class.constantPool
+}
+
+func (class *Class) Fields() []*Field {
+	return class.fields
+}
+
+func (class *Class) Methods() []*Method {
+	return class.methods
+}
+
+func (class *Class) Loader() *ClassLoader {
+	return class.loader
+}
+
+// SuperClass java/lang/Object和接口以外的类都有超类；接口的超类在class文件里是java/lang/Object
+func (class *Class) SuperClass() *Class {
+	return class.superClass
+}
+
+func (class *Class) Interfaces() []*Class {
+	return class.interfaces
+}
+
+func (class *Class) StaticVars() Slots {
+	return class.staticVars
+}
+
+// SourceFile 返回源文件名，没有时是空串
+func (class *Class) SourceFile() string {
+	return class.sourceFile
+}
+
+// ClassFile 返回定义这个类的class文件，校验和打印调试信息时用
+func (class *Class) ClassFile() *classfile.ClassFile {
+	return class.classFile
+}
+
+// PackageName 包名，默认包是空串
+func (class *Class) PackageName() string {
+	if i := strings.LastIndex(class.name, "/"); i >= 0 {
+		return class.name[:i]
+	}
+	return ""
+}
+
+// 同一个运行时包：包名相同，并且由同一个类加载器定义
+func (class *Class) isSamePackage(other *Class) bool {
+	return class.loader == other.loader && class.PackageName() == other.PackageName()
+}
+
+// isAccessibleTo 按JVMS 5.4.4判断other能否访问这个类
+func (class *Class) isAccessibleTo(other *Class) bool {
+	return class.IsPublic() || class.isSamePackage(other.HostClass())
+}
+
+// HasFinalFields 类自己是否声明了final实例变量
+func (class *Class) HasFinalFields() bool {
+	return class.finalFields
+}
+
+// HostClass 访问控制时代表这个类的类：匿名类是它的宿主类，其他类是它自己
+func (class *Class) HostClass() *Class {
+	if class.hostClass != nil {
+		return class.hostClass
+	}
+	return class
+}
+
+// NewObject 创建这个类的实例，实例变量都是零值
+func (class *Class) NewObject() *Object {
+	return newObject(class)
+}
+
+// JClass 返回这个类的java.lang.Class对象，它的extra是类本身。
+// Class对象由启动类加载器加载的java/lang/Class创建，不执行构造函数
+// 创建以后不再改变，静态同步方法每次调用都要用它加锁，所以先不加锁读一次。
+// 类库里没有java/lang/Class时抛出NoClassDefFoundError，不会返回nil
+func (class *Class) JClass(thread Thread) *Object {
+	if jClass := class.jClass.Load(); jClass != nil {
+		return jClass
+	}
+	class.loader.shared.lock.lock(thread)
+	defer class.loader.shared.lock.unlock()
+	if jClass := class.jClass.Load(); jClass != nil {
+		return jClass
+	}
+	jClass := class.loader.shared.boot.LoadClass(thread, "java/lang/Class").NewObject()
+	jClass.extra = class
+	class.jClass.Store(jClass)
+	return jClass
+}
+
+// Modifiers 返回Class.getModifiers的值：成员类用InnerClasses属性里的访问标志，
+// ACC_SUPER不算修饰符
+func (class *Class) Modifiers() int32 {
+	flags := class.accessFlags
+	if cf := class.classFile; cf != nil {
+		if innerClasses := cf.InnerClassesAttribute(); innerClasses != nil {
+			for _, info := range innerClasses.Classes() {
+				if info.InnerClassInfoIndex() != 0 && cf.ConstantPool().GetClassName(info.InnerClassInfoIndex()) == class.name {
+					flags = info.InnerClassAccessFlags()
+				}
+			}
+		}
+	}
+	return int32(flags &^ classfile.ACC_SUPER)
+}
+
+// getField 在类和超类里按名字和描述符找字段，找不到时panic
+func (class *Class) getField(name, descriptor string, isStatic bool) *Field {
+	for c := class; c != nil; c = c.superClass {
+		for _, field := range c.fields {
+			if field.IsStatic() == isStatic && field.name == name && field.descriptor == descriptor {
+				return field
+			}
+		}
+	}
+	panic("java.lang.NoSuchFieldError: " + name)
+}
+
+// SetStaticRefVar 按名字和描述符给引用类型的类变量赋值，例如System.setOut0设置System.out
+func (class *Class) SetStaticRefVar(name, descriptor string, ref *Object) {
+	field := class.getField(name, descriptor, true)
+	if field.IsVolatile() {
+		field.class.staticVars.SetRefVolatile(field.slotId, ref)
+	} else {
+		field.class.staticVars.SetRef(field.slotId, ref)
+	}
+}
+
+// GetStaticMethod 在类自己声明的方法里找静态方法，不找超类
+func (class *Class) GetStaticMethod(name, descriptor string) *Method {
+	for _, method := range class.methods {
+		if method.IsStatic() && method.name == name && method.descriptor == descriptor {
+			return method
+		}
+	}
+	return nil
+}
+
+// GetClinitMethod 返回类初始化方法，没有时返回nil
+func (class *Class) GetClinitMethod() *Method {
+	return class.GetStaticMethod("<clinit>", "()V")
+}
@@ -0,0 +1,53 @@
+package heap
+
+// IsAssignableFrom other类型的值能否赋给这个类型，规则见JVMS checkcast指令。
+// 数组类的超类是java/lang/Object，并且实现了Cloneable和Serializable，
+// 所以只有两边都是数组时需要比较组件类型
+func (class *Class) IsAssignableFrom(other *Class) bool {
+	if class == other {
+		return true
+	}
+	if class.IsArray() && other.IsArray() {
+		sc, tc := other.componentClass, class.componentClass
+		return !sc.IsPrimitive() && !tc.IsPrimitive() && tc.IsAssignableFrom(sc)
+	}
+	if class.IsArray() || class.IsPrimitive() || other.IsPrimitive() {
+		return false
+	}
+	if class.IsInterface() {
+		return other.IsImplements(class)
+	}
+	return other.IsSubClassOf(class)
+}
+
+// IsSubClassOf 是否直接或间接继承了other
+func (class *Class) IsSubClassOf(other *Class) bool {
+	for c := class.superClass; c != nil; c = c.superClass {
+		if c == other {
+			return true
+		}
+	}
+	return false
+}
+
+// IsImplements 类或者它的超类是否实现了接口iface
+func (class *Class) IsImplements(iface *Class) bool {
+	for c := class; c != nil; c = c.superClass {
+		for _, i := range c.interfaces {
+			if i == iface || i.IsSubInterfaceOf(iface) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsSubInterfaceOf 接口是否直接或间接扩展了iface
+func (class *Class) IsSubInterfaceOf(iface *Class) bool {
+	for _, superInterface := range class.interfaces {
+		if superInterface == iface || superInterface.IsSubInterfaceOf(iface) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package heap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// initState 类的初始化状态，见JVMS 5.5
+type initState uint32
+
+const (
+	notInitialized   initState = iota // 已经链接，还没有初始化
+	beingInitialized                  // 正在执行<clinit>
+	fullyInitialized                  // 初始化完成，可以使用
+	erroneous                         // 初始化失败，以后不能再使用
+)
+
+// 所有类共用的初始化锁：类正由别的线程初始化时，在initCond上等它完成
+var (
+	initMu   sync.Mutex
+	initCond = sync.NewCond(&initMu)
+)
+
+func (class *Class) state() initState {
+	return initState(atomic.LoadUint32((*uint32)(&class.initState)))
+}
+
+func (class *Class) setState(state initState) {
+	atomic.StoreUint32((*uint32)(&class.initState), uint32(state))
+}
+
+// IsInitialized 初始化是否已经完成
+func (class *Class) IsInitialized() bool {
+	return class.state() == fullyInitialized
+}
+
+// StartInit 开始初始化，thread是当前线程。返回true时由调用者执行初始化，结束时必须调用FinishInit或者FailInit；
+// 类正由当前线程初始化（<clinit>递归地用到了这个类）或者已经初始化完时返回false；
+// 类正由别的线程初始化时等它结束，和等待进入监视器一样算作阻塞；类处于错误状态时抛出NoClassDefFoundError
+func (class *Class) StartInit(thread Thread) bool {
+	initMu.Lock()
+	if class.state() == beingInitialized && class.initThread != thread {
+		thread.BeginBlocking()
+		for class.state() == beingInitialized && class.initThread != thread {
+			initCond.Wait()
+		}
+		// 和monitor.acquire一样先放开initMu再拿回栈，不然线程转储拿着栈的时候别的线程会在initMu上等
+		initMu.Unlock()
+		thread.EndBlocking()
+		initMu.Lock()
+	}
+	switch class.state() {
+	case notInitialized:
+		class.setState(beingInitialized)
+		class.initThread = thread
+		initMu.Unlock()
+		class.initStringConstants(thread)
+		return true
+	case erroneous:
+		initMu.Unlock()
+		panic("java.lang.NoClassDefFoundError: Could not initialize class " + class.JavaName())
+	}
+	initMu.Unlock()
+	return false
+}
+
+// initStringConstants 给有ConstantValue属性的String类变量赋值，值是字符串池里的对象。
+// 和HotSpot一样在执行<clinit>之前赋值，所以<clinit>里就能用到它们
+func (class *Class) initStringConstants(thread Thread) {
+	for _, field := range class.fields {
+		if field.IsStatic() && field.constValueIndex > 0 && field.descriptor == "Ljava/lang/String;" {
+			goStr := class.constantPool.GetConstant(uint(field.constValueIndex)).(string)
+			class.staticVars.SetRef(field.slotId, InternedString(thread, class.loader, goStr))
+		}
+	}
+}
+
+// FinishInit <clinit>正常结束，唤醒等待这个类初始化的线程
+func (class *Class) FinishInit() {
+	class.endInit(fullyInitialized)
+}
+
+// FailInit 超类的初始化或者<clinit>抛出了异常，类进入错误状态
+func (class *Class) FailInit() {
+	class.endInit(erroneous)
+}
+
+func (class *Class) endInit(state initState) {
+	initMu.Lock()
+	class.setState(state)
+	class.initThread = nil
+	initCond.Broadcast()
+	initMu.Unlock()
+}
+
+// DeclaresDefaultMethods 接口是否声明了非抽象的实例方法；
+// 实现类初始化时只初始化这样的超接口
+func (class *Class) DeclaresDefaultMethods() bool {
+	for _, method := range class.methods {
+		if !method.IsAbstract() && !method.IsStatic() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,467 @@
+package heap
+
+import (
+	"fmt"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/classpath"
+)
+
+// Verifier 链接时校验类的钩子。返回*classfile.ClassFormatError时原样抛出，
+// 其他错误作为java.lang.VerifyError抛出
+type Verifier func(thread Thread, class *Class) error
+
+// JavaLoadFunc 调用Java类加载器对象的loadClass方法，返回加载到的类，找不到时返回nil
+type JavaLoadFunc func(thread Thread, javaLoader *Object, name string) *Class
+
+/*
+ClassLoader 类加载器：读取class文件、解析成Class、加载超类和接口，
+然后链接（校验和准备）。类初始化由执行引擎在第一次主动使用时进行。
+
+内置的加载器和JDK一样分三层，每层只从类路径的一部分加载类，加载前先委托父加载器：
+启动类加载器加载jre/lib下的类，平台类加载器加载jre/lib/ext下的类，
+应用类加载器加载用户类路径上的类。Java代码创建的类加载器是用户定义的加载器，
+怎样加载由它的loadClass方法决定，类通过defineClass定义。
+运行时的类由类名和定义它的类加载器共同确定
+*/
+type ClassLoader struct {
+	name       string // 内置的是bootstrap、platform或app，用户定义的是加载器对象的类名
+	parent     *ClassLoader
+	cp         *classpath.Classpath // 用户定义的加载器没有
+	layer      classpath.Layer
+	verifier   Verifier
+	shared     *loaderShared
+	classMap   map[string]*Class // 以这个加载器为初始加载器的类，包括委托其他加载器加载的，键是内部形式的类名
+	loading    map[string]bool   // 正在加载超类和接口的类，用来发现循环继承
+	javaLoader *Object           // Java代码看到的java.lang.ClassLoader对象，内置的加载器第一次用到时创建
+}
+
+// loaderShared 同一个虚拟机里所有类加载器共享的数据
+type loaderShared struct {
+	lock          loaderLock
+	boot          *ClassLoader
+	userVerifier  Verifier // 用户定义的加载器使用的校验器
+	constraints   loaderConstraints
+	javaLoadClass JavaLoadFunc
+	primitives    map[string]*Class  // 基本类型的类，键是int等类型名
+	interned      map[string]*Object // 字符串池，键是字符串的内容
+}
+
+func newClassLoader(name string, parent *ClassLoader, shared *loaderShared, verifier Verifier) *ClassLoader {
+	return &ClassLoader{
+		name:     name,
+		parent:   parent,
+		verifier: verifier,
+		shared:   shared,
+		classMap: make(map[string]*Class),
+		loading:  make(map[string]bool),
+	}
+}
+
+// NewClassLoaders 创建启动、平台和应用三个类加载器，返回应用类加载器，
+// 其他两个可以通过Parent得到。启动类加载器用bootVerifier校验，其他加载器包括用户定义的加载器用verifier，
+// 为nil时不校验
+func NewClassLoaders(cp *classpath.Classpath, bootVerifier, verifier Verifier) *ClassLoader {
+	shared := &loaderShared{userVerifier: verifier, constraints: loaderConstraints{}, interned: map[string]*Object{}}
+	boot := newClassLoader("bootstrap", nil, shared, bootVerifier)
+	platform := newClassLoader("platform", boot, shared, verifier)
+	app := newClassLoader("app", platform, shared, verifier)
+	for layer, loader := range []*ClassLoader{boot, platform, app} {
+		loader.cp = cp
+		loader.layer = classpath.Layer(layer)
+	}
+	shared.boot = boot
+	shared.primitives = newPrimitiveClasses(boot)
+	return app
+}
+
+// SetJavaLoadFunc 注册调用Java方法loadClass的函数，用户定义的加载器加载类时用；
+// 没有注册时用户定义的加载器只能找到自己定义过的类
+func (classLoader *ClassLoader) SetJavaLoadFunc(fn JavaLoadFunc) {
+	classLoader.shared.javaLoadClass = fn
+}
+
+func (classLoader *ClassLoader) Name() string {
+	return classLoader.name
+}
+
+// Parent 返回父加载器，启动类加载器和用户定义的加载器没有父加载器
+func (classLoader *ClassLoader) Parent() *ClassLoader {
+	return classLoader.parent
+}
+
+func (classLoader *ClassLoader) Bootstrap() *ClassLoader {
+	return classLoader.shared.boot
+}
+
+func (classLoader *ClassLoader) IsBootstrap() bool {
+	return classLoader == classLoader.shared.boot
+}
+
+// IsUserDefined 是否由Java代码创建
+func (classLoader *ClassLoader) IsUserDefined() bool {
+	return classLoader.cp == nil
+}
+
+func (classLoader *ClassLoader) String() string {
+	return classLoader.name
+}
+
+// LoadClass 按内部形式的类名加载类，找不到时抛出NoClassDefFoundError
+func (classLoader *ClassLoader) LoadClass(thread Thread, name string) *Class {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	class := classLoader.loadClass(thread, name)
+	if class == nil {
+		panic("java.lang.NoClassDefFoundError: " + name)
+	}
+	return class
+}
+
+// FindClass 和LoadClass一样加载类，但找不到时返回nil，Class.forName用
+func (classLoader *ClassLoader) FindClass(thread Thread, name string) *Class {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	return classLoader.loadClass(thread, name)
+}
+
+// FindLoadedClass 返回以这个加载器为初始加载器的类，没有加载过时返回nil，
+// ClassLoader.findLoadedClass0用
+func (classLoader *ClassLoader) FindLoadedClass(thread Thread, name string) *Class {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	return classLoader.classMap[name]
+}
+
+// FindBootstrapClass 只在启动类加载器里找类，找不到时返回nil，ClassLoader.findBootstrapClass用
+func (classLoader *ClassLoader) FindBootstrapClass(thread Thread, name string) *Class {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	return classLoader.shared.boot.loadClass(thread, name)
+}
+
+// DefineClass 用class文件data定义类，这个加载器是定义加载器，ClassLoader.defineClass1用。
+// name为空时用class文件里的类名，不为空时必须一致；加载器已经有同名的类时抛出LinkageError
+func (classLoader *ClassLoader) DefineClass(thread Thread, name string, data []byte) *Class {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	return classLoader.loadNonArrayClass(thread, name, data)
+}
+
+// loadClass 内置的加载器先委托父加载器，父加载器找不到时才自己加载；
+// 用户定义的加载器调用Java的loadClass方法。都找不到时返回nil。
+// 类由哪个加载器定义，沿途的加载器都记为它的初始加载器
+func (classLoader *ClassLoader) loadClass(thread Thread, name string) *Class {
+	if class, ok := classLoader.classMap[name]; ok {
+		return class // 已经加载
+	}
+	var class *Class
+	if strings.HasPrefix(name, "[") {
+		class = classLoader.loadArrayClass(thread, name) // 数组类由虚拟机创建，不经过Java的loadClass
+	} else if classLoader.IsUserDefined() {
+		class = classLoader.loadClassByJava(thread, name)
+	} else {
+		if classLoader.parent != nil {
+			class = classLoader.parent.loadClass(thread, name)
+		}
+		if class == nil {
+			data, _, err := classLoader.cp.ReadClassFrom(classLoader.layer, name)
+			if err != nil {
+				return nil
+			}
+			class = classLoader.loadNonArrayClass(thread, name, data)
+		}
+	}
+	if class != nil {
+		classLoader.recordClass(name, class)
+	}
+	return class
+}
+
+// loadClassByJava Java代码返回的类名必须和要加载的一致。
+// 执行Java代码期间放开类加载锁，Java代码可能加载别的类，也可能等待别的线程
+func (classLoader *ClassLoader) loadClassByJava(thread Thread, name string) *Class {
+	javaLoadClass := classLoader.shared.javaLoadClass
+	if javaLoadClass == nil {
+		return nil
+	}
+	class := func() *Class {
+		lock := &classLoader.shared.lock
+		defer lock.reacquire(thread, lock.release())
+		return javaLoadClass(thread, classLoader.javaLoader, name)
+	}()
+	if loaded, ok := classLoader.classMap[name]; ok {
+		return loaded // 放开锁期间别的线程已经通过这个加载器加载了
+	}
+	if class != nil && class.name != name {
+		panic(fmt.Sprintf("java.lang.NoClassDefFoundError: %s (wrong name: %s)", name, class.name))
+	}
+	return class
+}
+
+// recordClass 把加载器记为class的初始加载器，先检查加载约束
+func (classLoader *ClassLoader) recordClass(name string, class *Class) {
+	if !classLoader.shared.constraints.check(name, classLoader, class) {
+		panic(fmt.Sprintf("java.lang.LinkageError: loader constraint violation: loader %s wants to load class %s. "+
+			"A different class with the same name was previously loaded by another loader",
+			classLoader, class.JavaName()))
+	}
+	classLoader.classMap[name] = class
+}
+
+/*
+DefineAnonymousClass 像JDK 8的Unsafe.defineAnonymousClass一样用data定义一个附属于宿主类host的类：
+类由宿主类的加载器定义，访问控制按宿主类检查，所以能访问宿主类的私有成员。
+虚拟机用它定义实现lambda表达式的类，类名由调用者保证不重复
+*/
+func DefineAnonymousClass(thread Thread, host *Class, data []byte) *Class {
+	host.loader.shared.lock.lock(thread)
+	defer host.loader.shared.lock.unlock()
+	return host.loader.defineAndLink(thread, "", data, host)
+}
+
+func (classLoader *ClassLoader) loadNonArrayClass(thread Thread, name string, data []byte) *Class {
+	return classLoader.defineAndLink(thread, name, data, nil)
+}
+
+func (classLoader *ClassLoader) defineAndLink(thread Thread, name string, data []byte, host *Class) *Class {
+	class := classLoader.defineClass(thread, name, data)
+	class.hostClass = host
+	defer func() {
+		if r := recover(); r != nil {
+			// 链接失败的类不能使用，定义时绑定到加载约束上的也要撤销
+			delete(classLoader.classMap, class.name)
+			classLoader.shared.constraints.unbind(class.name, class)
+			panic(r)
+		}
+	}()
+	link(thread, class)
+	return class
+}
+
+// defineClass 解析class文件，加载超类和接口
+func (classLoader *ClassLoader) defineClass(thread Thread, name string, data []byte) *Class {
+	if classLoader.loading[name] {
+		panic("java.lang.ClassCircularityError: " + name)
+	}
+	cf, err := classfile.Parse(data)
+	if err != nil {
+		panic(fmt.Sprintf("java.lang.ClassFormatError: %v", err))
+	}
+	if name == "" {
+		name = cf.ClassName()
+	} else if cf.ClassName() != name {
+		panic(fmt.Sprintf("java.lang.NoClassDefFoundError: %s (wrong name: %s)", name, cf.ClassName()))
+	}
+	if _, ok := classLoader.classMap[name]; ok {
+		panic(fmt.Sprintf("java.lang.LinkageError: loader %s attempted duplicate class definition for %s",
+			classLoader, strings.Replace(name, "/", ".", -1)))
+	}
+	if !classLoader.IsBootstrap() && strings.HasPrefix(name, "java/") {
+		pkg := name[:strings.LastIndex(name, "/")]
+		panic("java.lang.SecurityException: Prohibited package name: " + strings.Replace(pkg, "/", ".", -1))
+	}
+	class := newClass(cf)
+	class.loader = classLoader
+	classLoader.loading[name] = true
+	defer delete(classLoader.loading, name)
+	resolveSuperClass(thread, class)
+	resolveInterfaces(thread, class)
+	classLoader.recordClass(name, class)
+	return class
+}
+
+// 除了java/lang/Object，每个类都有超类
+func resolveSuperClass(thread Thread, class *Class) {
+	if class.superClassName == "" {
+		return
+	}
+	superClass := class.loader.LoadClass(thread, class.superClassName)
+	switch {
+	case superClass.IsInterface():
+		panic(fmt.Sprintf("java.lang.IncompatibleClassChangeError: class %s has interface %s as super class",
+			class.JavaName(), superClass.JavaName()))
+	case superClass.IsFinal():
+		panic(fmt.Sprintf("java.lang.VerifyError: Cannot inherit from final class %s", superClass.JavaName()))
+	case !superClass.isAccessibleTo(class):
+		panic(fmt.Sprintf("java.lang.IllegalAccessError: class %s cannot access its superclass %s",
+			class.JavaName(), superClass.JavaName()))
+	}
+	class.superClass = superClass
+}
+
+func resolveInterfaces(thread Thread, class *Class) {
+	class.interfaces = make([]*Class, len(class.interfaceNames))
+	for i, interfaceName := range class.interfaceNames {
+		iface := class.loader.LoadClass(thread, interfaceName)
+		switch {
+		case !iface.IsInterface():
+			panic(fmt.Sprintf("java.lang.IncompatibleClassChangeError: class %s can not implement %s, because it is not an interface",
+				class.JavaName(), iface.JavaName()))
+		case !iface.isAccessibleTo(class):
+			panic(fmt.Sprintf("java.lang.IllegalAccessError: class %s cannot access its superinterface %s",
+				class.JavaName(), iface.JavaName()))
+		}
+		class.interfaces[i] = iface
+	}
+}
+
+func link(thread Thread, class *Class) {
+	verify(thread, class)
+	prepare(class)
+}
+
+func verify(thread Thread, class *Class) {
+	verifier := class.loader.verifier
+	if verifier == nil {
+		return
+	}
+	if err := verifier(thread, class); err != nil {
+		if formatError, ok := err.(*classfile.ClassFormatError); ok {
+			panic(formatError)
+		}
+		panic(fmt.Sprintf("java.lang.VerifyError: %v", err))
+	}
+}
+
+// prepare 给字段编号，分配类变量并赋初始值，建立方法表
+func prepare(class *Class) {
+	calcInstanceFieldSlotIds(class)
+	calcStaticFieldSlotIds(class)
+	allocAndInitStaticVars(class)
+	buildVtable(class)
+	buildItable(class)
+}
+
+// 超类的实例变量排在前面
+func calcInstanceFieldSlotIds(class *Class) {
+	slotId := uint(0)
+	if class.superClass != nil {
+		slotId = class.superClass.instanceSlotCount
+	}
+	for _, field := range class.fields {
+		if !field.IsStatic() {
+			field.slotId = slotId
+			slotId++
+			if field.isLongOrDouble() {
+				slotId++
+			}
+		}
+	}
+	class.instanceSlotCount = slotId
+}
+
+func calcStaticFieldSlotIds(class *Class) {
+	slotId := uint(0)
+	for _, field := range class.fields {
+		if field.IsStatic() {
+			field.slotId = slotId
+			slotId++
+			if field.isLongOrDouble() {
+				slotId++
+			}
+		}
+	}
+	class.staticSlotCount = slotId
+}
+
+// 类变量先是零值，有ConstantValue属性的再赋常量值
+func allocAndInitStaticVars(class *Class) {
+	class.staticVars = newSlots(class.staticSlotCount)
+	for _, field := range class.fields {
+		if field.IsStatic() && field.constValueIndex > 0 {
+			initStaticVar(class, field)
+		}
+	}
+}
+
+func initStaticVar(class *Class, field *Field) {
+	vars := class.staticVars
+	slotId := field.slotId
+	val := class.constantPool.GetConstant(uint(field.constValueIndex))
+	ok := false
+	switch field.descriptor {
+	case "Z", "B", "C", "S", "I":
+		var i int32
+		if i, ok = val.(int32); ok {
+			vars.SetInt(slotId, i)
+		}
+	case "J":
+		var l int64
+		if l, ok = val.(int64); ok {
+			vars.SetLong(slotId, l)
+		}
+	case "F":
+		var f float32
+		if f, ok = val.(float32); ok {
+			vars.SetFloat(slotId, f)
+		}
+	case "D":
+		var d float64
+		if d, ok = val.(float64); ok {
+			vars.SetDouble(slotId, d)
+		}
+	case "Ljava/lang/String;":
+		// 创建String对象要加载java/lang/String，所以字符串常量在初始化时赋值，见initStringConstants
+		_, ok = val.(string)
+	}
+	if !ok {
+		panic(fmt.Sprintf("java.lang.ClassFormatError: Inconsistent constant value type in class file %s", class.Name()))
+	}
+}
+
+// JDK 8的sun.misc.Launcher里扩展类加载器和应用类加载器的类
+var javaLoaderClassNames = map[string]string{
+	"platform": "sun/misc/Launcher$ExtClassLoader",
+	"app":      "sun/misc/Launcher$AppClassLoader",
+}
+
+// JavaLoader 返回Java代码看到的类加载器对象，例如Class.getClassLoader()的返回值。
+// 启动类加载器在Java里是null。平台和应用类加载器第一次调用时创建JDK里对应类的实例，
+// 只设置parent字段，不执行构造函数；类库里没有这些类时返回nil
+func (classLoader *ClassLoader) JavaLoader(thread Thread) *Object {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	if classLoader.javaLoader == nil && !classLoader.IsBootstrap() {
+		class := classLoader.shared.boot.loadClass(thread, javaLoaderClassNames[classLoader.name])
+		if class == nil {
+			return nil
+		}
+		javaLoader := class.NewObject()
+		javaLoader.extra = classLoader
+		javaLoader.SetRefVar("parent", "Ljava/lang/ClassLoader;", classLoader.parent.JavaLoader(thread))
+		classLoader.javaLoader = javaLoader
+	}
+	return classLoader.javaLoader
+}
+
+// BindJavaLoader 让内置的加载器使用Java代码创建的加载器对象，例如sun.misc.Launcher创建的
+// AppClassLoader。加载器已经有对象，或者对象已经对应了别的加载器时什么也不做
+func (classLoader *ClassLoader) BindJavaLoader(thread Thread, javaLoader *Object) {
+	classLoader.shared.lock.lock(thread)
+	defer classLoader.shared.lock.unlock()
+	if javaLoader == nil || javaLoader.extra != nil || classLoader.javaLoader != nil || classLoader.IsBootstrap() {
+		return
+	}
+	classLoader.javaLoader = javaLoader
+	javaLoader.extra = classLoader
+}
+
+// LoaderOf 返回Java类加载器对象对应的类加载器：null对应启动类加载器，
+// Java代码创建的加载器对象第一次用到时为它建立一个用户定义的加载器
+func (classLoader *ClassLoader) LoaderOf(thread Thread, javaLoader *Object) *ClassLoader {
+	shared := classLoader.shared
+	if javaLoader == nil {
+		return shared.boot
+	}
+	shared.lock.lock(thread)
+	defer shared.lock.unlock()
+	if loader, ok := javaLoader.extra.(*ClassLoader); ok {
+		return loader
+	}
+	loader := newClassLoader(javaLoader.class.JavaName(), nil, shared, shared.userVerifier)
+	loader.javaLoader = javaLoader
+	javaLoader.extra = loader
+	return loader
+}
@@ -0,0 +1,74 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+// ClassMember 字段和方法共有的信息
+type ClassMember struct {
+	accessFlags uint16
+	name        string
+	descriptor  string
+	class       *Class // 声明这个成员的类
+}
+
+func (classMember *ClassMember) copyMemberInfo(memberInfo *classfile.MemberInfo) {
+	classMember.accessFlags = memberInfo.AccessFlags()
+	classMember.name = memberInfo.Name()
+	classMember.descriptor = memberInfo.Descriptor()
+}
+
+func (classMember *ClassMember) IsPublic() bool {
+	return classMember.accessFlags&classfile.ACC_PUBLIC != 0
+}
+
+func (classMember *ClassMember) IsPrivate() bool {
+	return classMember.accessFlags&classfile.ACC_PRIVATE != 0
+}
+
+func (classMember *ClassMember) IsProtected() bool {
+	return classMember.accessFlags&classfile.ACC_PROTECTED != 0
+}
+
+func (classMember *ClassMember) IsStatic() bool {
+	return classMember.accessFlags&classfile.ACC_STATIC != 0
+}
+
+func (classMember *ClassMember) IsFinal() bool {
+	return classMember.accessFlags&classfile.ACC_FINAL != 0
+}
+
+func (classMember *ClassMember) IsSynthetic() bool {
+	return classMember.accessFlags&classfile.ACC_SYNTHETIC != 0
+}
+
+func (classMember *ClassMember) AccessFlags() uint16 {
+	return classMember.accessFlags
+}
+
+func (classMember *ClassMember) Name() string {
+	return classMember.name
+}
+
+func (classMember *ClassMember) Descriptor() string {
+	return classMember.descriptor
+}
+
+func (classMember *ClassMember) Class() *Class {
+	return classMember.class
+}
+
+// isAccessibleTo 按JVMS 5.4.4判断类other能否访问这个成员；
+// protected实例成员对引用类型的额外要求由指令检查
+func (classMember *ClassMember) isAccessibleTo(other *Class) bool {
+	class := classMember.class
+	if classMember.IsPublic() || other == class {
+		return true
+	}
+	other = other.HostClass()
+	if classMember.IsProtected() {
+		return other == class || other.IsSubClassOf(class) || class.isSamePackage(other)
+	}
+	if !classMember.IsPrivate() {
+		return class.isSamePackage(other)
+	}
+	return other == class
+}
@@ -0,0 +1,71 @@
+package heap
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// Constant 运行时常量：数值和字符串是Go的值，类和成员引用是*ClassRef等符号引用，
+// 方法句柄、方法类型和调用点是*MethodHandleRef、*MethodTypeRef和*InvokeDynamicRef，
+// 动态常量暂时保留class文件里的形式
+type Constant interface{}
+
+// ConstantPool 运行时常量池，和class文件的常量池下标一一对应
+type ConstantPool struct {
+	class  *Class
+	consts []Constant
+}
+
+func newConstantPool(class *Class, cfCp *classfile.ConstantPool) *ConstantPool {
+	consts := make([]Constant, cfCp.Count())
+	rtCp := &ConstantPool{class, consts}
+	for i := range consts {
+		switch cpInfo := cfCp.GetConstantInfo(uint16(i)).(type) {
+		case *classfile.ConstantIntegerInfo:
+			consts[i] = cpInfo.Value()
+		case *classfile.ConstantFloatInfo:
+			consts[i] = cpInfo.Value()
+		case *classfile.ConstantLongInfo:
+			consts[i] = cpInfo.Value()
+		case *classfile.ConstantDoubleInfo:
+			consts[i] = cpInfo.Value()
+		case *classfile.ConstantStringInfo:
+			consts[i] = cpInfo.String()
+		case *classfile.ConstantClassInfo:
+			consts[i] = newClassRef(rtCp, cpInfo)
+		case *classfile.ConstantFieldrefInfo:
+			consts[i] = newFieldRef(rtCp, &cpInfo.ConstantMemberrefInfo)
+		case *classfile.ConstantMethodrefInfo:
+			consts[i] = newMethodRef(rtCp, &cpInfo.ConstantMemberrefInfo)
+		case *classfile.ConstantInterfaceMethodrefInfo:
+			consts[i] = newInterfaceMethodRef(rtCp, &cpInfo.ConstantMemberrefInfo)
+		case *classfile.ConstantMethodHandleInfo:
+			consts[i] = newMethodHandleRef(rtCp, cpInfo)
+		case *classfile.ConstantMethodTypeInfo:
+			consts[i] = &MethodTypeRef{cp: rtCp, descriptor: cpInfo.Descriptor()}
+		case *classfile.ConstantInvokeDynamicInfo:
+			consts[i] = newInvokeDynamicRef(rtCp, cpInfo)
+		case *classfile.ConstantUtf8Info, *classfile.ConstantNameAndTypeInfo, nil:
+			// 只被其他常量引用，不需要转换
+		default:
+			consts[i] = cpInfo
+		}
+	}
+	return rtCp
+}
+
+func (constantPool *ConstantPool) Class() *Class {
+	return constantPool.class
+}
+
+// GetConstant 按索引取常量；索引无效说明class文件有问题，这里不应该发生
+func (constantPool *ConstantPool) GetConstant(index uint) Constant {
+	if index < uint(len(constantPool.consts)) {
+		if c := constantPool.consts[index]; c != nil {
+			return c
+		}
+	}
+	panic(fmt.Sprintf("java.lang.VerifyError: bad constant pool index %d in class %s",
+		index, constantPool.class.JavaName()))
+}
@@ -0,0 +1,14 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+type ClassRef struct {
+	SymRef
+}
+
+func newClassRef(cp *ConstantPool, classInfo *classfile.ConstantClassInfo) *ClassRef {
+	ref := &ClassRef{}
+	ref.cp = cp
+	ref.className = classInfo.Name()
+	return ref
+}
@@ -0,0 +1,62 @@
+package heap
+
+import (
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+type FieldRef struct {
+	MemberRef
+	field atomic.Pointer[Field]
+}
+
+func newFieldRef(cp *ConstantPool, refInfo *classfile.ConstantMemberrefInfo) *FieldRef {
+	ref := &FieldRef{}
+	ref.cp = cp
+	ref.copyMemberRefInfo(refInfo)
+	return ref
+}
+
+// ResolvedField 解析字段引用，失败时panic
+func (fieldRef *FieldRef) ResolvedField(thread Thread) *Field {
+	if field := fieldRef.field.Load(); field != nil {
+		return field
+	}
+	fieldRef.resolve(func() { fieldRef.resolveFieldRef(thread) })
+	return fieldRef.field.Load()
+}
+
+// 按JVMS 5.4.3.2解析
+func (fieldRef *FieldRef) resolveFieldRef(thread Thread) {
+	d := fieldRef.cp.class
+	c := fieldRef.ResolvedClass(thread)
+	field := lookupField(c, fieldRef.name, fieldRef.descriptor)
+	if field == nil {
+		panic("java.lang.NoSuchFieldError: " + fieldRef.name)
+	}
+	if !field.isAccessibleTo(d) {
+		panic("java.lang.IllegalAccessError: tried to access field " +
+			field.class.JavaName() + "." + field.name + " from class " + d.JavaName())
+	}
+	addMemberConstraints(thread, d, &field.ClassMember, "field")
+	fieldRef.field.CompareAndSwap(nil, field)
+}
+
+// lookupField 先找类自己声明的字段，再找超接口，最后找超类
+func lookupField(c *Class, name, descriptor string) *Field {
+	for _, field := range c.fields {
+		if field.name == name && field.descriptor == descriptor {
+			return field
+		}
+	}
+	for _, iface := range c.interfaces {
+		if field := lookupField(iface, name, descriptor); field != nil {
+			return field
+		}
+	}
+	if c.superClass != nil {
+		return lookupField(c.superClass, name, descriptor)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package heap
+
+import (
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+type InterfaceMethodRef struct {
+	MemberRef
+	method atomic.Pointer[Method]
+}
+
+func newInterfaceMethodRef(cp *ConstantPool, refInfo *classfile.ConstantMemberrefInfo) *InterfaceMethodRef {
+	ref := &InterfaceMethodRef{}
+	ref.cp = cp
+	ref.copyMemberRefInfo(refInfo)
+	return ref
+}
+
+// ResolvedInterfaceMethod 解析接口方法引用，失败时panic
+func (interfaceMethodRef *InterfaceMethodRef) ResolvedInterfaceMethod(thread Thread) *Method {
+	if method := interfaceMethodRef.method.Load(); method != nil {
+		return method
+	}
+	interfaceMethodRef.resolve(func() { interfaceMethodRef.resolveInterfaceMethodRef(thread) })
+	return interfaceMethodRef.method.Load()
+}
+
+// 按JVMS 5.4.3.4解析
+func (interfaceMethodRef *InterfaceMethodRef) resolveInterfaceMethodRef(thread Thread) {
+	d := interfaceMethodRef.cp.class
+	c := interfaceMethodRef.ResolvedClass(thread)
+	if !c.IsInterface() {
+		panic("java.lang.IncompatibleClassChangeError: Found class " + c.JavaName() + ", but interface was expected")
+	}
+	method := lookupInterfaceMethod(c, interfaceMethodRef.name, interfaceMethodRef.descriptor)
+	if method == nil {
+		panic("java.lang.NoSuchMethodError: " + c.JavaName() + "." + interfaceMethodRef.name + interfaceMethodRef.descriptor)
+	}
+	if !method.isAccessibleTo(d) {
+		panic("java.lang.IllegalAccessError: tried to access method " + method.String() + " from class " + d.JavaName())
+	}
+	addMemberConstraints(thread, d, &method.ClassMember, "interface method")
+	interfaceMethodRef.method.CompareAndSwap(nil, method)
+}
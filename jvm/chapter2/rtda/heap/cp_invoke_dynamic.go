@@ -0,0 +1,59 @@
+package heap
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// InvokeDynamicRef invokedynamic指令的调用点限定符：调用点的名字、描述符和BootstrapMethods属性里的引导方法
+type InvokeDynamicRef struct {
+	cp                       *ConstantPool
+	bootstrapMethodAttrIndex uint16
+	name                     string
+	descriptor               string
+}
+
+func newInvokeDynamicRef(cp *ConstantPool, info *classfile.ConstantInvokeDynamicInfo) *InvokeDynamicRef {
+	ref := &InvokeDynamicRef{cp: cp, bootstrapMethodAttrIndex: info.BootstrapMethodAttrIndex()}
+	ref.name, ref.descriptor = info.NameAndDescriptor()
+	return ref
+}
+
+func (invokeDynamicRef *InvokeDynamicRef) Name() string {
+	return invokeDynamicRef.name
+}
+
+func (invokeDynamicRef *InvokeDynamicRef) Descriptor() string {
+	return invokeDynamicRef.descriptor
+}
+
+// Class 返回调用点所在的类
+func (invokeDynamicRef *InvokeDynamicRef) Class() *Class {
+	return invokeDynamicRef.cp.class
+}
+
+// BootstrapMethod 返回引导方法的方法句柄和静态参数。参数是运行时常量：
+// int32等数值、string、*ClassRef、*MethodTypeRef或*MethodHandleRef
+func (invokeDynamicRef *InvokeDynamicRef) BootstrapMethod() (*MethodHandleRef, []Constant) {
+	cp := invokeDynamicRef.cp
+	var bootstrapMethods []*classfile.BootstrapMethod
+	if attr := cp.class.classFile.BootstrapMethodsAttribute(); attr != nil {
+		bootstrapMethods = attr.BootstrapMethods()
+	}
+	if int(invokeDynamicRef.bootstrapMethodAttrIndex) >= len(bootstrapMethods) {
+		panic(fmt.Sprintf("java.lang.ClassFormatError: bad bootstrap method index %d in class %s",
+			invokeDynamicRef.bootstrapMethodAttrIndex, cp.class.JavaName()))
+	}
+	bootstrapMethod := bootstrapMethods[invokeDynamicRef.bootstrapMethodAttrIndex]
+	handle, ok := cp.GetConstant(uint(bootstrapMethod.BootstrapMethodRef())).(*MethodHandleRef)
+	if !ok {
+		panic("java.lang.ClassFormatError: bootstrap method is not a CONSTANT_MethodHandle in class " + cp.class.JavaName())
+	}
+	argIndexes := bootstrapMethod.BootstrapArguments()
+	args := make([]Constant, len(argIndexes))
+	for i, index := range argIndexes {
+		args[i] = cp.GetConstant(uint(index))
+	}
+	return handle, args
+}
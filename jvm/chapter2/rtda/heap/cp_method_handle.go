@@ -0,0 +1,118 @@
+package heap
+
+import (
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+// MethodHandleRef 方法句柄常量的符号引用，引用一个字段或者方法，第一次使用时解析
+type MethodHandleRef struct {
+	cp             *ConstantPool
+	referenceKind  uint8
+	referenceIndex uint16
+	field          atomic.Pointer[Field]
+	method         atomic.Pointer[Method]
+	jHandle        atomic.Pointer[Object] // ldc得到的java.lang.invoke.MethodHandle对象
+}
+
+func newMethodHandleRef(cp *ConstantPool, info *classfile.ConstantMethodHandleInfo) *MethodHandleRef {
+	return &MethodHandleRef{
+		cp:             cp,
+		referenceKind:  info.ReferenceKind(),
+		referenceIndex: info.ReferenceIndex(),
+	}
+}
+
+// ReferenceKind 返回classfile.REF_getField等引用类型
+func (methodHandleRef *MethodHandleRef) ReferenceKind() uint8 {
+	return methodHandleRef.referenceKind
+}
+
+// IsFieldHandle 引用的是不是字段
+func (methodHandleRef *MethodHandleRef) IsFieldHandle() bool {
+	return methodHandleRef.referenceKind <= classfile.REF_putStatic
+}
+
+// ResolvedClass 解析并返回引用的成员所在的类
+func (methodHandleRef *MethodHandleRef) ResolvedClass(thread Thread) *Class {
+	switch ref := methodHandleRef.cp.GetConstant(uint(methodHandleRef.referenceIndex)).(type) {
+	case *FieldRef:
+		return ref.ResolvedClass(thread)
+	case *MethodRef:
+		return ref.ResolvedClass(thread)
+	case *InterfaceMethodRef:
+		return ref.ResolvedClass(thread)
+	}
+	panic("java.lang.ClassFormatError: bad CONSTANT_MethodHandle reference in class " + methodHandleRef.cp.class.JavaName())
+}
+
+// ResolvedField 解析字段句柄引用的字段，失败时panic
+func (methodHandleRef *MethodHandleRef) ResolvedField(thread Thread) *Field {
+	if field := methodHandleRef.field.Load(); field != nil {
+		return field
+	}
+	methodHandleRef.resolveFieldHandle(thread)
+	return methodHandleRef.field.Load()
+}
+
+// ResolvedMethod 解析方法句柄引用的方法，失败时panic
+func (methodHandleRef *MethodHandleRef) ResolvedMethod(thread Thread) *Method {
+	if method := methodHandleRef.method.Load(); method != nil {
+		return method
+	}
+	methodHandleRef.resolveMethodHandle(thread)
+	return methodHandleRef.method.Load()
+}
+
+// JHandle 返回ldc得到的MethodHandle对象，还没有创建时返回nil
+func (methodHandleRef *MethodHandleRef) JHandle() *Object {
+	return methodHandleRef.jHandle.Load()
+}
+
+// SetJHandle 记下ldc创建的MethodHandle对象，以后ldc同一个常量得到同一个对象。
+// 几个线程同时创建时只记下第一个
+func (methodHandleRef *MethodHandleRef) SetJHandle(jHandle *Object) {
+	methodHandleRef.jHandle.CompareAndSwap(nil, jHandle)
+}
+
+// 按JVMS 5.4.3.5：先解析字段引用，再检查它是不是和引用类型一致的静态或实例字段
+func (methodHandleRef *MethodHandleRef) resolveFieldHandle(thread Thread) {
+	fieldRef, ok := methodHandleRef.cp.GetConstant(uint(methodHandleRef.referenceIndex)).(*FieldRef)
+	if !ok || !methodHandleRef.IsFieldHandle() {
+		panic("java.lang.ClassFormatError: bad CONSTANT_MethodHandle reference in class " + methodHandleRef.cp.class.JavaName())
+	}
+	field := fieldRef.ResolvedField(thread)
+	isStatic := methodHandleRef.referenceKind == classfile.REF_getStatic || methodHandleRef.referenceKind == classfile.REF_putStatic
+	if field.IsStatic() != isStatic {
+		panic("java.lang.IncompatibleClassChangeError: " + fieldRef.className + "." + field.name)
+	}
+	methodHandleRef.field.CompareAndSwap(nil, field)
+}
+
+// 按JVMS 5.4.3.5：REF_invokeInterface引用接口方法，REF_invokeStatic和REF_invokeSpecial
+// 两种都可以，其他的引用类的方法；REF_newInvokeSpecial引用构造函数，其他的不能引用<init>
+func (methodHandleRef *MethodHandleRef) resolveMethodHandle(thread Thread) {
+	kind := methodHandleRef.referenceKind
+	var method *Method
+	switch ref := methodHandleRef.cp.GetConstant(uint(methodHandleRef.referenceIndex)).(type) {
+	case *MethodRef:
+		if kind != classfile.REF_invokeInterface {
+			method = ref.ResolvedMethod(thread)
+		}
+	case *InterfaceMethodRef:
+		if kind == classfile.REF_invokeInterface || kind == classfile.REF_invokeStatic || kind == classfile.REF_invokeSpecial {
+			method = ref.ResolvedInterfaceMethod(thread)
+		}
+	}
+	if method == nil || kind <= classfile.REF_putStatic || (method.name == "<init>") != (kind == classfile.REF_newInvokeSpecial) {
+		panic("java.lang.ClassFormatError: bad CONSTANT_MethodHandle reference in class " + methodHandleRef.cp.class.JavaName())
+	}
+	if method.IsStatic() != (kind == classfile.REF_invokeStatic) {
+		if method.IsStatic() {
+			panic("java.lang.IncompatibleClassChangeError: Expected instance not static method " + method.String())
+		}
+		panic("java.lang.IncompatibleClassChangeError: Expected static method " + method.String())
+	}
+	methodHandleRef.method.CompareAndSwap(nil, method)
+}
@@ -0,0 +1,40 @@
+package heap
+
+import "sync/atomic"
+
+// MethodTypeRef 方法类型常量，保存方法描述符
+type MethodTypeRef struct {
+	cp         *ConstantPool
+	descriptor string
+	jType      atomic.Pointer[Object] // ldc得到的java.lang.invoke.MethodType对象
+}
+
+func (methodTypeRef *MethodTypeRef) Descriptor() string {
+	return methodTypeRef.descriptor
+}
+
+// ParameterTypes 按JVMS 5.4.3.5解析方法类型：描述符里的类由常量所在类的加载器加载
+func (methodTypeRef *MethodTypeRef) ParameterTypes(thread Thread) []*Class {
+	methodSig := parseMethodDescriptor(methodTypeRef.descriptor)
+	loader := methodTypeRef.cp.class.loader
+	paramTypes := make([]*Class, len(methodSig.Params))
+	for i, param := range methodSig.Params {
+		paramTypes[i] = loader.TypeClass(thread, param.Signature())
+	}
+	return paramTypes
+}
+
+// ReturnType 返回值类型，没有返回值时是void.class
+func (methodTypeRef *MethodTypeRef) ReturnType(thread Thread) *Class {
+	return methodTypeRef.cp.class.loader.TypeClass(thread, parseMethodDescriptor(methodTypeRef.descriptor).Return.Signature())
+}
+
+// JType 返回ldc得到的MethodType对象，还没有创建时返回nil
+func (methodTypeRef *MethodTypeRef) JType() *Object {
+	return methodTypeRef.jType.Load()
+}
+
+// SetJType 记下ldc创建的MethodType对象，几个线程同时创建时只记下第一个
+func (methodTypeRef *MethodTypeRef) SetJType(jType *Object) {
+	methodTypeRef.jType.CompareAndSwap(nil, jType)
+}
@@ -0,0 +1,46 @@
+package heap
+
+import (
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+)
+
+type MethodRef struct {
+	MemberRef
+	method atomic.Pointer[Method]
+}
+
+func newMethodRef(cp *ConstantPool, refInfo *classfile.ConstantMemberrefInfo) *MethodRef {
+	ref := &MethodRef{}
+	ref.cp = cp
+	ref.copyMemberRefInfo(refInfo)
+	return ref
+}
+
+// ResolvedMethod 解析方法引用，失败时panic
+func (methodRef *MethodRef) ResolvedMethod(thread Thread) *Method {
+	if method := methodRef.method.Load(); method != nil {
+		return method
+	}
+	methodRef.resolve(func() { methodRef.resolveMethodRef(thread) })
+	return methodRef.method.Load()
+}
+
+// 按JVMS 5.4.3.3解析
+func (methodRef *MethodRef) resolveMethodRef(thread Thread) {
+	d := methodRef.cp.class
+	c := methodRef.ResolvedClass(thread)
+	if c.IsInterface() {
+		panic("java.lang.IncompatibleClassChangeError: Found interface " + c.JavaName() + ", but class was expected")
+	}
+	method := lookupMethod(c, methodRef.name, methodRef.descriptor)
+	if method == nil {
+		panic("java.lang.NoSuchMethodError: " + c.JavaName() + "." + methodRef.name + methodRef.descriptor)
+	}
+	if !method.isAccessibleTo(d) {
+		panic("java.lang.IllegalAccessError: tried to access method " + method.String() + " from class " + d.JavaName())
+	}
+	addMemberConstraints(thread, d, &method.ClassMember, "method")
+	methodRef.method.CompareAndSwap(nil, method)
+}
@@ -0,0 +1,50 @@
+package heap
+
+import "sync/atomic"
+
+// SymRef 类和成员符号引用共有的部分，第一次使用时才解析。
+// 解析结果用原子操作读写：几个线程可能同时解析同一个引用，得到的结果是一样的
+// （加载器约束保证），只保存第一个
+type SymRef struct {
+	cp         *ConstantPool
+	className  string
+	class      atomic.Pointer[Class]
+	resolveErr atomic.Pointer[interface{}] // 解析失败时抛出的异常，JVMS 5.4.3要求以后每次都抛出同样的异常
+}
+
+func (symRef *SymRef) ClassName() string {
+	return symRef.className
+}
+
+// ResolvedClass 解析类引用，失败时panic
+func (symRef *SymRef) ResolvedClass(thread Thread) *Class {
+	if class := symRef.class.Load(); class != nil {
+		return class
+	}
+	symRef.resolve(func() { symRef.resolveClassRef(thread) })
+	return symRef.class.Load()
+}
+
+// resolve 执行解析，记下第一次失败的原因
+func (symRef *SymRef) resolve(fn func()) {
+	if err := symRef.resolveErr.Load(); err != nil {
+		panic(*err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			symRef.resolveErr.CompareAndSwap(nil, &r)
+			panic(*symRef.resolveErr.Load())
+		}
+	}()
+	fn()
+}
+
+// 按JVMS 5.4.3.1：由引用所在类的加载器加载，再检查访问权限
+func (symRef *SymRef) resolveClassRef(thread Thread) {
+	d := symRef.cp.class
+	c := d.loader.LoadClass(thread, symRef.className)
+	if !c.isAccessibleTo(d) {
+		panic("java.lang.IllegalAccessError: tried to access class " + c.JavaName() + " from class " + d.JavaName())
+	}
+	symRef.class.CompareAndSwap(nil, c)
+}
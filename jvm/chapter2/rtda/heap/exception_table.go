@@ -0,0 +1,56 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+// ExceptionTable 方法的异常处理表，按class文件里的顺序查找
+type ExceptionTable []*ExceptionHandler
+
+// ExceptionHandler 异常处理项：[startPc, endPc)范围内抛出catchType的实例时跳到handlerPc。
+// catchType为nil的项捕获所有异常，编译器用它实现finally
+type ExceptionHandler struct {
+	startPc   int
+	endPc     int
+	handlerPc int
+	catchType *ClassRef
+}
+
+func newExceptionTable(entries []*classfile.ExceptionTableEntry, cp *ConstantPool) ExceptionTable {
+	table := make([]*ExceptionHandler, len(entries))
+	for i, entry := range entries {
+		table[i] = &ExceptionHandler{
+			startPc:   int(entry.StartPc()),
+			endPc:     int(entry.EndPc()),
+			handlerPc: int(entry.HandlerPc()),
+			catchType: getCatchType(uint(entry.CatchType()), cp),
+		}
+	}
+	return table
+}
+
+func getCatchType(index uint, cp *ConstantPool) *ClassRef {
+	if index == 0 {
+		return nil // catch all
+	}
+	if classRef, ok := cp.GetConstant(index).(*ClassRef); ok {
+		return classRef
+	}
+	panic("java.lang.ClassFormatError: Catch type in exception table has bad constant type in class file " + cp.class.name)
+}
+
+// findExceptionHandler 找第一个覆盖pc并且能捕获exClass的处理项。
+// 解析catchType失败时panic，由调用者当作这个方法里抛出的新异常
+func (exceptionTable ExceptionTable) findExceptionHandler(thread Thread, exClass *Class, pc int) *ExceptionHandler {
+	for _, handler := range exceptionTable {
+		if pc < handler.startPc || pc >= handler.endPc {
+			continue
+		}
+		if handler.catchType == nil {
+			return handler
+		}
+		catchClass := handler.catchType.ResolvedClass(thread)
+		if catchClass == exClass || exClass.IsSubClassOf(catchClass) {
+			return handler
+		}
+	}
+	return nil
+}
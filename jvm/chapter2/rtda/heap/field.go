@@ -0,0 +1,47 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+type Field struct {
+	ClassMember
+	constValueIndex uint16 // ConstantValue属性指向的常量，0表示没有
+	slotId          uint   // 在类变量或实例变量里的位置
+}
+
+func newFields(class *Class, cfFields []*classfile.MemberInfo) []*Field {
+	fields := make([]*Field, len(cfFields))
+	for i, cfField := range cfFields {
+		fields[i] = &Field{}
+		fields[i].class = class
+		fields[i].copyMemberInfo(cfField)
+		if valAttr := cfField.ConstantValueAttribute(); valAttr != nil {
+			fields[i].constValueIndex = valAttr.ConstantValueIndex()
+		}
+	}
+	return fields
+}
+
+func (field *Field) IsVolatile() bool {
+	return field.accessFlags&classfile.ACC_VOLATILE != 0
+}
+
+func (field *Field) IsTransient() bool {
+	return field.accessFlags&classfile.ACC_TRANSIENT != 0
+}
+
+func (field *Field) IsEnum() bool {
+	return field.accessFlags&classfile.ACC_ENUM != 0
+}
+
+func (field *Field) ConstValueIndex() uint16 {
+	return field.constValueIndex
+}
+
+func (field *Field) SlotId() uint {
+	return field.slotId
+}
+
+// isLongOrDouble long和double占两个槽位
+func (field *Field) isLongOrDouble() bool {
+	return field.descriptor == "J" || field.descriptor == "D"
+}
@@ -0,0 +1,179 @@
+package heap
+
+import (
+	"fmt"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// loaderConstraint JVMS 5.3.4的加载约束：loaders里的每个加载器加载name时必须得到同一个类
+type loaderConstraint struct {
+	loaders []*ClassLoader
+	class   *Class // 已经有加载器加载了name时是那个类，否则为nil
+}
+
+func (constraint *loaderConstraint) contains(loader *ClassLoader) bool {
+	for _, l := range constraint.loaders {
+		if l == loader {
+			return true
+		}
+	}
+	return false
+}
+
+// loaderConstraints 同一组类加载器共享的约束表，键是内部形式的类名
+type loaderConstraints map[string][]*loaderConstraint
+
+func (constraints loaderConstraints) find(name string, loader *ClassLoader) *loaderConstraint {
+	for _, constraint := range constraints[name] {
+		if constraint.contains(loader) {
+			return constraint
+		}
+	}
+	return nil
+}
+
+// add 加上约束L1(name) = L2(name)，两个加载器已经加载了不同的类时返回false
+func (constraints loaderConstraints) add(name string, l1, l2 *ClassLoader) bool {
+	if l1 == l2 {
+		return true
+	}
+	c1, c2 := constraints.find(name, l1), constraints.find(name, l2)
+	class1, class2 := l1.classMap[name], l2.classMap[name]
+	if class1 == nil && c1 != nil {
+		class1 = c1.class
+	}
+	if class2 == nil && c2 != nil {
+		class2 = c2.class
+	}
+	if class1 != nil && class2 != nil && class1 != class2 {
+		return false
+	}
+	class := class1
+	if class == nil {
+		class = class2
+	}
+	switch {
+	case c1 == nil && c2 == nil:
+		constraints[name] = append(constraints[name], &loaderConstraint{[]*ClassLoader{l1, l2}, class})
+	case c1 == nil:
+		c2.loaders = append(c2.loaders, l1)
+		c2.class = class
+	case c2 == nil:
+		c1.loaders = append(c1.loaders, l2)
+		c1.class = class
+	case c1 != c2:
+		// 合并两个约束
+		c1.loaders = append(c1.loaders, c2.loaders...)
+		c1.class = class
+		constraints.remove(name, c2)
+	}
+	return true
+}
+
+func (constraints loaderConstraints) remove(name string, constraint *loaderConstraint) {
+	list := constraints[name]
+	for i, c := range list {
+		if c == constraint {
+			constraints[name] = append(list[:i], list[i+1:]...)
+			return
+		}
+	}
+}
+
+// check loader成为class的初始加载器之前检查，违反约束时返回false
+func (constraints loaderConstraints) check(name string, loader *ClassLoader, class *Class) bool {
+	constraint := constraints.find(name, loader)
+	if constraint == nil {
+		return true
+	}
+	if constraint.class != nil && constraint.class != class {
+		return false
+	}
+	constraint.class = class
+	return true
+}
+
+// unbind 链接失败的class不再是约束里的类，除非约束里还有别的加载器把它记为初始加载的类
+func (constraints loaderConstraints) unbind(name string, class *Class) {
+	for _, constraint := range constraints[name] {
+		if constraint.class != class {
+			continue
+		}
+		constraint.class = nil
+		for _, loader := range constraint.loaders {
+			if loader.classMap[name] == class {
+				constraint.class = class
+				break
+			}
+		}
+	}
+}
+
+// descriptorClassNames 返回字段或方法描述符里出现的类名，数组取元素类型
+func descriptorClassNames(descriptor string) []string {
+	var types []signature.Type
+	if strings.HasPrefix(descriptor, "(") {
+		if s, err := signature.ParseMethodDescriptor(descriptor); err == nil {
+			types = append(s.Params, s.Return)
+		}
+	} else if t, err := signature.ParseFieldDescriptor(descriptor); err == nil {
+		types = []signature.Type{t}
+	}
+	var names []string
+	for _, t := range types {
+		if arrayType, ok := t.(*signature.ArrayType); ok {
+			t = arrayType.Element()
+		}
+		if classType, ok := t.(*signature.ClassType); ok {
+			names = append(names, classType.InternalName())
+		}
+	}
+	return names
+}
+
+// addMemberConstraints 类d引用了成员member时，d和声明member的类的加载器
+// 对描述符里出现的每个类必须加载出同一个类，否则抛出LinkageError
+func addMemberConstraints(thread Thread, d *Class, member *ClassMember, what string) {
+	l1, l2 := d.loader, member.class.loader
+	if l1 == l2 {
+		return
+	}
+	l1.shared.lock.lock(thread)
+	defer l1.shared.lock.unlock()
+	for _, name := range descriptorClassNames(member.descriptor) {
+		if !l1.shared.constraints.add(name, l1, l2) {
+			panic(fmt.Sprintf("java.lang.LinkageError: loader constraint violation: when resolving %s \"%s.%s%s\" "+
+				"the class loader %s of the current class, %s, and the class loader %s for the %s's defining class, %s, "+
+				"have different Class objects for the type %s used in the signature",
+				what, member.class.JavaName(), member.name, member.descriptor, l1, d.JavaName(), l2, what,
+				member.class.JavaName(), strings.Replace(name, "/", ".", -1)))
+		}
+	}
+}
+
+// addOverrideConstraints method覆盖或实现了另一个加载器定义的overridden时，
+// 两个加载器对描述符里出现的每个类必须加载出同一个类，否则抛出LinkageError
+func addOverrideConstraints(class *Class, method, overridden *Method) {
+	l1, l2 := method.class.loader, overridden.class.loader
+	if l1 == l2 {
+		return
+	}
+	for _, name := range descriptorClassNames(method.descriptor) {
+		if l1.shared.constraints.add(name, l1, l2) {
+			continue
+		}
+		typeName := strings.Replace(name, "/", ".", -1)
+		if overridden.class.IsInterface() {
+			panic(fmt.Sprintf("java.lang.LinkageError: loader constraint violation in interface itable initialization: "+
+				"when resolving method \"%s\" the class loader %s of the current class, %s, and the class loader %s "+
+				"for interface %s have different Class objects for the type %s used in the signature",
+				method, l1, class.JavaName(), l2, overridden.class.JavaName(), typeName))
+		}
+		panic(fmt.Sprintf("java.lang.LinkageError: loader constraint violation: when resolving overridden method \"%s\" "+
+			"the class loader %s of the current class, %s, and its superclass loader %s, "+
+			"have different Class objects for the type %s used in the signature",
+			method, l1, class.JavaName(), l2, typeName))
+	}
+}
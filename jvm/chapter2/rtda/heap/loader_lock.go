@@ -0,0 +1,51 @@
+package heap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/*
+loaderLock 类加载锁，保护所有加载器的类表、加载约束和字符串池。
+同一时刻只有一个线程加载类；锁可以重入，因为加载一个类时要加载它的超类和接口。
+用户定义的加载器调用Java的loadClass期间暂时放开锁，见loadClassByJava
+*/
+type loaderLock struct {
+	mu    sync.Mutex
+	owner uint32 // 持有锁的线程的编号，没有时为0
+	count int    // 重入的次数，只由持有锁的线程访问
+}
+
+func (lock *loaderLock) lock(thread Thread) {
+	id := thread.ID()
+	if atomic.LoadUint32(&lock.owner) == id {
+		lock.count++
+		return
+	}
+	lock.mu.Lock()
+	atomic.StoreUint32(&lock.owner, id)
+	lock.count = 1
+}
+
+func (lock *loaderLock) unlock() {
+	lock.count--
+	if lock.count == 0 {
+		atomic.StoreUint32(&lock.owner, 0)
+		lock.mu.Unlock()
+	}
+}
+
+// release 完全放开当前线程持有的锁，返回重入的次数，之后用reacquire恢复
+func (lock *loaderLock) release() int {
+	count := lock.count
+	lock.count = 0
+	atomic.StoreUint32(&lock.owner, 0)
+	lock.mu.Unlock()
+	return count
+}
+
+func (lock *loaderLock) reacquire(thread Thread, count int) {
+	lock.mu.Lock()
+	atomic.StoreUint32(&lock.owner, thread.ID())
+	lock.count = count
+}
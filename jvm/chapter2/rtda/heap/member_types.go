@@ -0,0 +1,62 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/signature"
+
+// TypeClass 返回描述符表示的类型的类，例如I是int.class，V是void.class；
+// 引用类型由这个加载器加载，找不到时抛出NoClassDefFoundError
+func (classLoader *ClassLoader) TypeClass(thread Thread, descriptor string) *Class {
+	switch descriptor[0] {
+	case 'L':
+		return classLoader.LoadClass(thread, descriptor[1:len(descriptor)-1])
+	case '[':
+		return classLoader.LoadClass(thread, descriptor)
+	}
+	for name, primitiveDescriptor := range primitiveDescriptors {
+		if primitiveDescriptor == descriptor {
+			return classLoader.shared.primitives[name]
+		}
+	}
+	panic("java.lang.ClassFormatError: bad descriptor " + descriptor)
+}
+
+// Type 返回字段的类型，由声明字段的类的加载器加载，反射用
+func (field *Field) Type(thread Thread) *Class {
+	return field.class.loader.TypeClass(thread, field.descriptor)
+}
+
+// ParameterTypes 按顺序返回方法的参数类型，不包括this
+func (method *Method) ParameterTypes(thread Thread) []*Class {
+	methodSig := method.parseDescriptor()
+	paramTypes := make([]*Class, len(methodSig.Params))
+	for i, param := range methodSig.Params {
+		paramTypes[i] = method.class.loader.TypeClass(thread, param.Signature())
+	}
+	return paramTypes
+}
+
+// ReturnType 返回方法的返回值类型，没有返回值时是void.class
+func (method *Method) ReturnType(thread Thread) *Class {
+	return method.class.loader.TypeClass(thread, method.parseDescriptor().Return.Signature())
+}
+
+// ExceptionTypes 返回方法用throws声明的异常类
+func (method *Method) ExceptionTypes(thread Thread) []*Class {
+	exTypes := make([]*Class, len(method.exceptions))
+	for i, index := range method.exceptions {
+		exTypes[i] = method.class.constantPool.GetConstant(uint(index)).(*ClassRef).ResolvedClass(thread)
+	}
+	return exTypes
+}
+
+// parseDescriptor 解析方法描述符，不合法时抛出ClassFormatError
+func (method *Method) parseDescriptor() *signature.MethodSignature {
+	return parseMethodDescriptor(method.descriptor)
+}
+
+func parseMethodDescriptor(descriptor string) *signature.MethodSignature {
+	methodSig, err := signature.ParseMethodDescriptor(descriptor)
+	if err != nil {
+		panic("java.lang.ClassFormatError: " + err.Error())
+	}
+	return methodSig
+}
@@ -0,0 +1,208 @@
+package heap
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+type Method struct {
+	ClassMember
+	maxStack        uint
+	maxLocals       uint
+	code            []byte
+	exceptionTable  ExceptionTable
+	lineNumberTable *classfile.LineNumberTableAttribute
+	argSlotCount    uint      // 参数占用的局部变量表槽位数，实例方法包括this
+	vtableIndex     int       // 在虚方法表里的下标，不在表里的是-1
+	itableIndex     int       // 接口方法在接口方法表表项里的下标，就是它在接口methods里的位置
+	conflicts       []*Method // 默认方法冲突时虚拟机生成的占位方法才有，是冲突的默认方法
+	exceptions      []uint16  // Exceptions属性里声明抛出的异常类，是常量池下标
+
+	callSites pcCache // invokedynamic调用点链接好的目标
+	decoded   pcCache // 解释器译码好的指令
+}
+
+// pcCache 按指令的pc缓存的值，第一次写时分配。读不加锁，写时持有mu
+type pcCache struct {
+	mu    sync.Mutex
+	slots atomic.Pointer[[]atomic.Pointer[interface{}]]
+}
+
+// load 返回pc处的值，还没有时返回nil
+func (cache *pcCache) load(pc int) interface{} {
+	if slots := cache.slots.Load(); slots != nil {
+		if value := (*slots)[pc].Load(); value != nil {
+			return *value
+		}
+	}
+	return nil
+}
+
+// store 在pc处还没有值时记下value；返回pc处最终的值，已经有值时是原来的值。size是代码的长度
+func (cache *pcCache) store(pc, size int, value interface{}) interface{} {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	slots := cache.slots.Load()
+	if slots == nil {
+		s := make([]atomic.Pointer[interface{}], size)
+		slots = &s
+		cache.slots.Store(slots)
+	}
+	if old := (*slots)[pc].Load(); old != nil {
+		return *old
+	}
+	(*slots)[pc].Store(&value)
+	return value
+}
+
+func newMethods(class *Class, cfMethods []*classfile.MemberInfo) []*Method {
+	methods := make([]*Method, len(cfMethods))
+	for i, cfMethod := range cfMethods {
+		methods[i] = &Method{vtableIndex: -1, itableIndex: i}
+		methods[i].class = class
+		methods[i].copyMemberInfo(cfMethod)
+		methods[i].copyAttributes(cfMethod)
+		methods[i].calcArgSlotCount()
+		if methods[i].IsNative() {
+			methods[i].injectCodeAttribute()
+		}
+	}
+	return methods
+}
+
+func (method *Method) copyAttributes(cfMethod *classfile.MemberInfo) {
+	if codeAttr := cfMethod.CodeAttribute(); codeAttr != nil {
+		method.maxStack = codeAttr.MaxStack()
+		method.maxLocals = codeAttr.MaxLocals()
+		method.code = codeAttr.Code()
+		method.exceptionTable = newExceptionTable(codeAttr.ExceptionTable(), method.class.constantPool)
+		method.lineNumberTable = codeAttr.LineNumberTableAttribute()
+	}
+	if exAttr := cfMethod.ExceptionsAttribute(); exAttr != nil {
+		method.exceptions = exAttr.ExceptionIndexTable()
+	}
+}
+
+// injectCodeAttribute 本地方法没有字节码，给它一段由invokenative和返回指令组成的代码，
+// 这样本地方法也有自己的帧，调用和返回跟普通方法一样
+func (method *Method) injectCodeAttribute() {
+	method.maxStack = 4 // long或double返回值，外加本地方法实现里临时用到的
+	method.maxLocals = method.argSlotCount
+	returnType := method.descriptor[strings.IndexByte(method.descriptor, ')')+1:]
+	switch returnType[0] {
+	case 'V':
+		method.code = []byte{opcodes.Impdep1, opcodes.Return}
+	case 'L', '[':
+		method.code = []byte{opcodes.Impdep1, opcodes.Areturn}
+	case 'D':
+		method.code = []byte{opcodes.Impdep1, opcodes.Dreturn}
+	case 'F':
+		method.code = []byte{opcodes.Impdep1, opcodes.Freturn}
+	case 'J':
+		method.code = []byte{opcodes.Impdep1, opcodes.Lreturn}
+	default:
+		method.code = []byte{opcodes.Impdep1, opcodes.Ireturn}
+	}
+}
+
+// 描述符不合法的方法在格式检查时就会被发现，这里按0个参数处理
+func (method *Method) calcArgSlotCount() {
+	if count, err := signature.ArgSlotCount(method.descriptor); err == nil {
+		method.argSlotCount = uint(count)
+	}
+	if !method.IsStatic() {
+		method.argSlotCount++ // this
+	}
+}
+
+func (method *Method) IsSynchronized() bool {
+	return method.accessFlags&classfile.ACC_SYNCHRONIZED != 0
+}
+
+func (method *Method) IsBridge() bool {
+	return method.accessFlags&classfile.ACC_BRIDGE != 0
+}
+
+func (method *Method) IsVarargs() bool {
+	return method.accessFlags&classfile.ACC_VARARGS != 0
+}
+
+func (method *Method) IsNative() bool {
+	return method.accessFlags&classfile.ACC_NATIVE != 0
+}
+
+func (method *Method) IsAbstract() bool {
+	return method.accessFlags&classfile.ACC_ABSTRACT != 0
+}
+
+func (method *Method) IsStrict() bool {
+	return method.accessFlags&classfile.ACC_STRICT != 0
+}
+
+func (method *Method) MaxStack() uint {
+	return method.maxStack
+}
+
+func (method *Method) MaxLocals() uint {
+	return method.maxLocals
+}
+
+// Code 返回字节码，抽象方法没有；本地方法返回虚拟机生成的调用代码
+func (method *Method) Code() []byte {
+	return method.code
+}
+
+// FindExceptionHandler 返回能处理pc处抛出的exClass异常的处理器地址，没有时返回-1
+func (method *Method) FindExceptionHandler(thread Thread, exClass *Class, pc int) int {
+	if handler := method.exceptionTable.findExceptionHandler(thread, exClass, pc); handler != nil {
+		return handler.handlerPc
+	}
+	return -1
+}
+
+// GetLineNumber 返回pc对应的源码行号：本地方法是-2，没有行号信息时是-1
+func (method *Method) GetLineNumber(pc int) int {
+	if method.IsNative() {
+		return -2
+	}
+	if method.lineNumberTable == nil {
+		return -1
+	}
+	return method.lineNumberTable.GetLineNumber(pc)
+}
+
+func (method *Method) ArgSlotCount() uint {
+	return method.argSlotCount
+}
+
+// String 返回异常消息里用的形式，例如java.lang.Object.wait(J)V
+func (method *Method) String() string {
+	return method.class.JavaName() + "." + method.name + method.descriptor
+}
+
+// CallSite 返回pc处的invokedynamic指令链接好的目标，还没有链接时返回nil
+func (method *Method) CallSite(pc int) interface{} {
+	return method.callSites.load(pc)
+}
+
+// LinkCallSite 记下pc处的invokedynamic指令链接好的目标，返回调用点最终采用的目标：
+// 多个线程同时链接同一个调用点时，和JVMS 6.5说的一样只采用第一个完成的结果
+func (method *Method) LinkCallSite(pc int, target interface{}) interface{} {
+	return method.callSites.store(pc, len(method.code), target)
+}
+
+// Decoded 返回解释器缓存的pc处译码好的指令，还没有译码时返回nil
+func (method *Method) Decoded(pc int) interface{} {
+	return method.decoded.load(pc)
+}
+
+// SetDecoded 缓存pc处译码好的指令，返回缓存里最终的值。方法的代码不会变，
+// 各个线程同时译码同一条指令时结果一样，只留第一个
+func (method *Method) SetDecoded(pc int, inst interface{}) interface{} {
+	return method.decoded.store(pc, len(method.code), inst)
+}
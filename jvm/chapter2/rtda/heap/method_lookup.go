@@ -0,0 +1,61 @@
+package heap
+
+// lookupMethod 方法解析：先在类和超类里找，再在超接口里找
+func lookupMethod(class *Class, name, descriptor string) *Method {
+	if method := lookupMethodInClass(class, name, descriptor); method != nil {
+		return method
+	}
+	return lookupMethodInInterfaces(class.interfaces, name, descriptor)
+}
+
+func lookupMethodInClass(class *Class, name, descriptor string) *Method {
+	for c := class; c != nil; c = c.superClass {
+		for _, method := range c.methods {
+			if method.name == name && method.descriptor == descriptor {
+				return method
+			}
+		}
+	}
+	return nil
+}
+
+// 超接口里的private和static方法不会被继承
+func lookupMethodInInterfaces(ifaces []*Class, name, descriptor string) *Method {
+	for _, iface := range ifaces {
+		for _, method := range iface.methods {
+			if method.name == name && method.descriptor == descriptor && !method.IsPrivate() && !method.IsStatic() {
+				return method
+			}
+		}
+		if method := lookupMethodInInterfaces(iface.interfaces, name, descriptor); method != nil {
+			return method
+		}
+	}
+	return nil
+}
+
+// lookupInterfaceMethod 接口方法解析：接口自己声明的方法，java/lang/Object的public实例方法，最后是超接口
+func lookupInterfaceMethod(iface *Class, name, descriptor string) *Method {
+	for _, method := range iface.methods {
+		if method.name == name && method.descriptor == descriptor {
+			return method
+		}
+	}
+	if object := iface.superClass; object != nil {
+		for _, method := range object.methods {
+			if method.name == name && method.descriptor == descriptor && method.IsPublic() && !method.IsStatic() {
+				return method
+			}
+		}
+	}
+	return lookupMethodInInterfaces(iface.interfaces, name, descriptor)
+}
+
+// LookupInstanceMethod 在类、超类和超接口里找实例方法，虚拟机从Go代码调用对象的虚方法
+// （比如ClassLoader.loadClass）时用，class是对象的类；找不到时返回nil
+func (class *Class) LookupInstanceMethod(name, descriptor string) *Method {
+	if method := lookupMethod(class, name, descriptor); method != nil && !method.IsStatic() {
+		return method
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/classfile"
+
+/*
+虚方法表和接口方法表在链接时建立，调用时按下标直接取出要执行的方法，
+不用每次沿着超类链查找。
+
+vtable：超类的表项在前，类声明的实例方法按JVMS 5.4.5能覆盖某个表项时替换它，
+否则追加到表尾。表项总是最后一个覆盖者，所以覆盖的传递性自然成立。
+
+itable：类（包括超类）实现的每个接口一项，表项的methods和接口的methods一一对应，
+按JVMS 5.4.6选出实现方法：先找类和超类里能覆盖它的实例方法，再找最具体的默认方法。
+没有实现时是nil，默认方法冲突时是虚拟机生成的占位方法，调用时分别抛出
+AbstractMethodError和IncompatibleClassChangeError
+*/
+type itableEntry struct {
+	iface   *Class
+	methods []*Method
+}
+
+// buildVtable 超类已经链接，表可以直接复制
+func buildVtable(class *Class) {
+	if class.IsInterface() {
+		return
+	}
+	var vtable []*Method
+	if class.superClass != nil {
+		vtable = append(vtable, class.superClass.vtable...)
+	}
+	for _, method := range class.methods {
+		if method.IsStatic() || method.IsPrivate() || method.name == "<init>" {
+			continue
+		}
+		method.vtableIndex = -1
+		for i, overridden := range vtable {
+			if overridden.name != method.name || overridden.descriptor != method.descriptor ||
+				!canOverride(method, overridden) {
+				continue
+			}
+			if overridden.IsFinal() {
+				panic("java.lang.VerifyError: class " + class.JavaName() + " overrides final method " +
+					overridden.name + "." + overridden.descriptor)
+			}
+			addOverrideConstraints(class, method, overridden)
+			vtable[i] = method
+			if method.vtableIndex < 0 {
+				method.vtableIndex = i
+			}
+		}
+		if method.vtableIndex < 0 {
+			method.vtableIndex = len(vtable)
+			vtable = append(vtable, method)
+		}
+	}
+	class.vtable = vtable
+}
+
+// canOverride JVMS 5.4.5：包私有的方法只能被同一个运行时包里的方法覆盖
+func canOverride(method, overridden *Method) bool {
+	if overridden.IsPublic() || overridden.IsProtected() {
+		return true
+	}
+	return method.class.isSamePackage(overridden.class)
+}
+
+func buildItable(class *Class) {
+	if class.IsInterface() {
+		return
+	}
+	ifaces := allInterfaces(class)
+	class.itable = make([]itableEntry, len(ifaces))
+	for i, iface := range ifaces {
+		methods := make([]*Method, len(iface.methods))
+		for j, ifaceMethod := range iface.methods {
+			if ifaceMethod.IsStatic() || ifaceMethod.IsPrivate() {
+				continue
+			}
+			method := lookupOverridingMethod(class, ifaceMethod.name, ifaceMethod.descriptor)
+			if method == nil {
+				method = selectMaximallySpecificMethod(class, ifaceMethod.name, ifaceMethod.descriptor)
+			}
+			if method != nil && method.conflicts == nil {
+				addOverrideConstraints(class, method, ifaceMethod)
+			}
+			methods[j] = method
+		}
+		class.itable[i] = itableEntry{iface, methods}
+	}
+}
+
+// allInterfaces 返回类和超类直接或间接实现的所有接口；接口返回它的所有超接口
+func allInterfaces(class *Class) []*Class {
+	var ifaces []*Class
+	seen := map[*Class]bool{}
+	var collect func(c *Class)
+	collect = func(c *Class) {
+		for _, iface := range c.interfaces {
+			if !seen[iface] {
+				seen[iface] = true
+				ifaces = append(ifaces, iface)
+				collect(iface)
+			}
+		}
+	}
+	for c := class; c != nil; c = c.superClass {
+		collect(c)
+	}
+	return ifaces
+}
+
+// lookupOverridingMethod 在类和超类里找能覆盖接口方法的实例方法，private和static方法不算
+func lookupOverridingMethod(class *Class, name, descriptor string) *Method {
+	for c := class; c != nil; c = c.superClass {
+		for _, method := range c.methods {
+			if method.name == name && method.descriptor == descriptor && !method.IsStatic() && !method.IsPrivate() {
+				return method
+			}
+		}
+	}
+	return nil
+}
+
+// maximallySpecificMethods JVMS 5.4.3.3：超接口里名字和描述符匹配的非private、非static方法，
+// 去掉声明它的接口是另一个候选所在接口的超接口的
+func maximallySpecificMethods(class *Class, name, descriptor string) []*Method {
+	var candidates []*Method
+	for _, iface := range allInterfaces(class) {
+		for _, method := range iface.methods {
+			if method.name == name && method.descriptor == descriptor && !method.IsStatic() && !method.IsPrivate() {
+				candidates = append(candidates, method)
+			}
+		}
+	}
+	var methods []*Method
+	for _, candidate := range candidates {
+		specific := true
+		for _, other := range candidates {
+			if other.class.IsSubInterfaceOf(candidate.class) {
+				specific = false
+				break
+			}
+		}
+		if specific {
+			methods = append(methods, candidate)
+		}
+	}
+	return methods
+}
+
+// selectMaximallySpecificMethod 最具体的方法里恰好有一个不是抽象的时选它；
+// 都是抽象的时返回nil；有多个默认方法时返回冲突的占位方法
+func selectMaximallySpecificMethod(class *Class, name, descriptor string) *Method {
+	var defaults []*Method
+	for _, method := range maximallySpecificMethods(class, name, descriptor) {
+		if !method.IsAbstract() {
+			defaults = append(defaults, method)
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		return nil
+	case 1:
+		return defaults[0]
+	}
+	conflict := &Method{conflicts: defaults, vtableIndex: -1}
+	conflict.accessFlags = classfile.ACC_PUBLIC | classfile.ACC_ABSTRACT | classfile.ACC_SYNTHETIC
+	conflict.name = name
+	conflict.descriptor = descriptor
+	conflict.class = class
+	return conflict
+}
+
+// SelectMethod 按JVMS 5.4.6为invokevirtual和invokeinterface选择要执行的方法，
+// class是接收者的类，resolved是解析出的方法
+func (class *Class) SelectMethod(resolved *Method) *Method {
+	if resolved.IsPrivate() {
+		return resolved
+	}
+	var method *Method
+	if resolved.class.IsInterface() {
+		entry := class.itableEntry(resolved.class)
+		if entry == nil {
+			panic("java.lang.IncompatibleClassChangeError: Class " + class.JavaName() +
+				" does not implement the requested interface " + resolved.class.JavaName())
+		}
+		method = entry.methods[resolved.itableIndex]
+	} else if resolved.vtableIndex < 0 {
+		method = resolved
+	} else {
+		method = class.vtable[resolved.vtableIndex]
+	}
+	return checkSelected(class, method, resolved)
+}
+
+func (class *Class) itableEntry(iface *Class) *itableEntry {
+	for i := range class.itable {
+		if class.itable[i].iface == iface {
+			return &class.itable[i]
+		}
+	}
+	return nil
+}
+
+// SelectSpecialMethod 按JVMS invokespecial指令的规则选择方法：current是当前类，
+// refClass是方法引用里的类。调用超类方法并且当前类有ACC_SUPER时从直接超类开始找
+func SelectSpecialMethod(current, refClass *Class, resolved *Method) *Method {
+	c := refClass
+	if resolved.name != "<init>" && !refClass.IsInterface() && current.hasSuperSemantics() && current.IsSubClassOf(refClass) {
+		c = current.superClass
+	}
+	var method *Method
+	if c.IsInterface() {
+		method = c.getInstanceMethod(resolved.name, resolved.descriptor)
+		if method == nil && c.superClass != nil {
+			if m := c.superClass.getInstanceMethod(resolved.name, resolved.descriptor); m != nil && m.IsPublic() {
+				method = m
+			}
+		}
+	} else {
+		for k := c; k != nil && method == nil; k = k.superClass {
+			method = k.getInstanceMethod(resolved.name, resolved.descriptor)
+		}
+	}
+	if method == nil {
+		method = selectMaximallySpecificMethod(c, resolved.name, resolved.descriptor)
+	}
+	return checkSelected(c, method, resolved)
+}
+
+// hasSuperSemantics Java SE 8起不管class文件里有没有ACC_SUPER都按有处理，
+// 这里和HotSpot一样只对版本52以前的class文件检查这个标志
+func (class *Class) hasSuperSemantics() bool {
+	return class.IsSuper() || class.classFile == nil || class.classFile.MajorVersion() >= 52
+}
+
+// getInstanceMethod 在类自己声明的方法里找实例方法
+func (class *Class) getInstanceMethod(name, descriptor string) *Method {
+	for _, method := range class.methods {
+		if !method.IsStatic() && method.name == name && method.descriptor == descriptor {
+			return method
+		}
+	}
+	return nil
+}
+
+// checkSelected 没有选出方法或者选出抽象方法时抛出AbstractMethodError，默认方法冲突时抛出IncompatibleClassChangeError
+func checkSelected(class *Class, method, resolved *Method) *Method {
+	if method != nil && method.conflicts != nil {
+		msg := "java.lang.IncompatibleClassChangeError: Conflicting default methods:"
+		for _, m := range method.conflicts {
+			msg += " " + m.class.JavaName() + "." + m.name
+		}
+		panic(msg)
+	}
+	if method == nil || method.IsAbstract() {
+		panic("java.lang.AbstractMethodError: " + class.JavaName() + "." + resolved.name + resolved.descriptor)
+	}
+	return method
+}
@@ -0,0 +1,139 @@
+package heap
+
+import "sync/atomic"
+
+// Object 对象：普通对象的data是实例变量Slots，数组的data是元素切片，见array_object.go
+type Object struct {
+	class    *Class
+	data     interface{}
+	extra    interface{}  // 虚拟机附加在对象上的数据，例如类加载器对象对应的*ClassLoader
+	lockWord uint64       // 对象锁的状态，由rtda的监视器用原子操作读写，见rtda/monitor.go
+	monitor  atomic.Value // 锁膨胀以后的监视器
+	frozen   uint32       // 构造函数已经返回，final字段冻结了，见Freeze
+}
+
+func newObject(class *Class) *Object {
+	return &Object{
+		class: class,
+		data:  newSlots(class.instanceSlotCount),
+	}
+}
+
+func (object *Object) Class() *Class {
+	return object.class
+}
+
+// Fields 返回普通对象的实例变量，按字段的SlotId访问
+func (object *Object) Fields() Slots {
+	return object.data.(Slots)
+}
+
+// IsInstanceOf 对象能否赋值给class类型的变量
+func (object *Object) IsInstanceOf(class *Class) bool {
+	return class.IsAssignableFrom(object.class)
+}
+
+// Extra 返回虚拟机附加在对象上的数据
+func (object *Object) Extra() interface{} {
+	return object.extra
+}
+
+func (object *Object) SetExtra(extra interface{}) {
+	object.extra = extra
+}
+
+// LockWord 返回对象锁字的地址，只能用原子操作访问
+func (object *Object) LockWord() *uint64 {
+	return &object.lockWord
+}
+
+// Monitor 返回锁膨胀以后的监视器，还没有膨胀时返回nil
+func (object *Object) Monitor() interface{} {
+	return object.monitor.Load()
+}
+
+// SetMonitor 锁膨胀时记下监视器，之后才能把锁字改成膨胀状态
+func (object *Object) SetMonitor(monitor interface{}) {
+	object.monitor.Store(monitor)
+}
+
+// SetRefVar 按名字和描述符给引用类型的实例变量赋值，虚拟机直接设置Java对象的字段时用。
+// 这几个方法和字节码一样按volatile读写volatile字段，例如Thread.threadStatus
+func (object *Object) SetRefVar(name, descriptor string, ref *Object) {
+	field := object.class.getField(name, descriptor, false)
+	if field.IsVolatile() {
+		object.Fields().SetRefVolatile(field.slotId, ref)
+	} else {
+		object.Fields().SetRef(field.slotId, ref)
+	}
+}
+
+// GetRefVar 按名字和描述符读取引用类型的实例变量
+func (object *Object) GetRefVar(name, descriptor string) *Object {
+	field := object.class.getField(name, descriptor, false)
+	if field.IsVolatile() {
+		return object.Fields().GetRefVolatile(field.slotId)
+	}
+	return object.Fields().GetRef(field.slotId)
+}
+
+// SetIntVar 按名字和描述符给int、boolean等32位整数类型的实例变量赋值
+func (object *Object) SetIntVar(name, descriptor string, val int32) {
+	field := object.class.getField(name, descriptor, false)
+	if field.IsVolatile() {
+		object.Fields().SetIntVolatile(field.slotId, val)
+	} else {
+		object.Fields().SetInt(field.slotId, val)
+	}
+}
+
+// GetIntVar 按名字和描述符读取32位整数类型的实例变量
+func (object *Object) GetIntVar(name, descriptor string) int32 {
+	field := object.class.getField(name, descriptor, false)
+	if field.IsVolatile() {
+		return object.Fields().GetIntVolatile(field.slotId)
+	}
+	return object.Fields().GetInt(field.slotId)
+}
+
+/*
+Freeze 冻结final字段：声明了final实例变量的类的构造函数返回时调用，原子地写冻结标志。
+读final字段之前先调用AcquireFinals原子地读这个标志，按Go的内存模型，
+读到冻结的线程一定能看到构造函数写入的final字段，即使对象是通过数据竞争得到的，
+这就是JMM 17.5要求的final字段语义
+*/
+func (object *Object) Freeze() {
+	atomic.StoreUint32(&object.frozen, 1)
+}
+
+// AcquireFinals 读final字段之前调用，和构造函数末尾的Freeze配对
+func (object *Object) AcquireFinals() {
+	atomic.LoadUint32(&object.frozen)
+}
+
+// Clone 浅拷贝对象，Object.clone用：数组复制元素，普通对象复制实例变量。
+// 虚拟机附加的数据和锁不复制
+func (object *Object) Clone() *Object {
+	var data interface{}
+	switch elements := object.data.(type) {
+	case Slots:
+		data = append(Slots(nil), elements...)
+	case []int8:
+		data = append([]int8{}, elements...)
+	case []int16:
+		data = append([]int16{}, elements...)
+	case []uint16:
+		data = append([]uint16{}, elements...)
+	case []int32:
+		data = append([]int32{}, elements...)
+	case []int64:
+		data = append([]int64{}, elements...)
+	case []float32:
+		data = append([]float32{}, elements...)
+	case []float64:
+		data = append([]float64{}, elements...)
+	case []*Object:
+		data = append([]*Object{}, elements...)
+	}
+	return &Object{class: object.class, data: data}
+}
@@ -0,0 +1,127 @@
+package heap
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+/*
+Slot 类变量和实例变量的槽位，和局部变量表一样long和double占两个。
+num有64位（int32加上对齐的填充也占8个字节，不多用内存），long和double整个存在第一个槽位里，
+第二个槽位不用：这样volatile的long和double可以用一次原子操作读写，不会读到一半。
+int、float等32位的值符号扩展后存放，CAS比较的也是扩展后的值
+*/
+type Slot struct {
+	num int64
+	ref *Object
+}
+
+type Slots []Slot
+
+func newSlots(slotCount uint) Slots {
+	if slotCount > 0 {
+		return make([]Slot, slotCount)
+	}
+	return nil
+}
+
+func (slots Slots) SetInt(index uint, val int32) {
+	slots[index].num = int64(val)
+}
+
+func (slots Slots) GetInt(index uint) int32 {
+	return int32(slots[index].num)
+}
+
+func (slots Slots) SetFloat(index uint, val float32) {
+	slots.SetInt(index, int32(math.Float32bits(val)))
+}
+
+func (slots Slots) GetFloat(index uint) float32 {
+	return math.Float32frombits(uint32(slots.GetInt(index)))
+}
+
+func (slots Slots) SetLong(index uint, val int64) {
+	slots[index].num = val
+}
+
+func (slots Slots) GetLong(index uint) int64 {
+	return slots[index].num
+}
+
+func (slots Slots) SetDouble(index uint, val float64) {
+	slots.SetLong(index, int64(math.Float64bits(val)))
+}
+
+func (slots Slots) GetDouble(index uint) float64 {
+	return math.Float64frombits(uint64(slots.GetLong(index)))
+}
+
+func (slots Slots) SetRef(index uint, ref *Object) {
+	slots[index].ref = ref
+}
+
+func (slots Slots) GetRef(index uint) *Object {
+	return slots[index].ref
+}
+
+// volatile变量的读写都是sync/atomic的原子操作。Go的原子操作之间是顺序一致的，
+// 正好是JMM对volatile的要求；写之前的普通写对读到这个值的线程可见
+
+func (slots Slots) SetIntVolatile(index uint, val int32) {
+	atomic.StoreInt64(&slots[index].num, int64(val))
+}
+
+func (slots Slots) GetIntVolatile(index uint) int32 {
+	return int32(atomic.LoadInt64(&slots[index].num))
+}
+
+func (slots Slots) SetFloatVolatile(index uint, val float32) {
+	slots.SetIntVolatile(index, int32(math.Float32bits(val)))
+}
+
+func (slots Slots) GetFloatVolatile(index uint) float32 {
+	return math.Float32frombits(uint32(slots.GetIntVolatile(index)))
+}
+
+func (slots Slots) SetLongVolatile(index uint, val int64) {
+	atomic.StoreInt64(&slots[index].num, val)
+}
+
+func (slots Slots) GetLongVolatile(index uint) int64 {
+	return atomic.LoadInt64(&slots[index].num)
+}
+
+func (slots Slots) SetDoubleVolatile(index uint, val float64) {
+	slots.SetLongVolatile(index, int64(math.Float64bits(val)))
+}
+
+func (slots Slots) GetDoubleVolatile(index uint) float64 {
+	return math.Float64frombits(uint64(slots.GetLongVolatile(index)))
+}
+
+func (slots Slots) SetRefVolatile(index uint, ref *Object) {
+	atomic.StorePointer(slots.refPointer(index), unsafe.Pointer(ref))
+}
+
+func (slots Slots) GetRefVolatile(index uint) *Object {
+	return (*Object)(atomic.LoadPointer(slots.refPointer(index)))
+}
+
+// CompareAndSwapInt 变量等于expected时原子地换成val，Unsafe.compareAndSwapInt用
+func (slots Slots) CompareAndSwapInt(index uint, expected, val int32) bool {
+	return atomic.CompareAndSwapInt64(&slots[index].num, int64(expected), int64(val))
+}
+
+func (slots Slots) CompareAndSwapLong(index uint, expected, val int64) bool {
+	return atomic.CompareAndSwapInt64(&slots[index].num, expected, val)
+}
+
+func (slots Slots) CompareAndSwapRef(index uint, expected, ref *Object) bool {
+	return atomic.CompareAndSwapPointer(slots.refPointer(index), unsafe.Pointer(expected), unsafe.Pointer(ref))
+}
+
+func (slots Slots) refPointer(index uint) *unsafe.Pointer {
+	return (*unsafe.Pointer)(unsafe.Pointer(&slots[index].ref))
+}
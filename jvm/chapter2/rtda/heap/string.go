@@ -0,0 +1,120 @@
+package heap
+
+import "go.buppt.cn/jvm/chapter2/mutf8"
+
+// JDK 9以后String的value是byte[]，coder说明编码：
+// LATIN1每个字符一个字节，UTF16每个字符两个字节，按本机字节序（小端）保存
+const (
+	coderLatin1 = 0
+	coderUTF16  = 1
+)
+
+// JString 用Go字符串创建java.lang.String对象。字符串由启动类加载器加载的java/lang/String表示
+func JString(thread Thread, loader *ClassLoader, goStr string) *Object {
+	return JStringFromChars(thread, loader, mutf8.FromString(goStr))
+}
+
+// JStringFromChars 用UTF-16码元创建java.lang.String对象。
+// 按类库的String的布局保存：JDK 8是char[]，JDK 9以后是byte[]加coder，
+// 和HotSpot默认开启CompactStrings一样，字符都在Latin-1范围内时用LATIN1
+func JStringFromChars(thread Thread, loader *ClassLoader, chars []uint16) *Object {
+	boot := loader.shared.boot
+	stringClass := boot.LoadClass(thread, "java/lang/String")
+	jStr := stringClass.NewObject()
+	if !hasByteValue(stringClass) {
+		jChars := &Object{class: boot.LoadClass(thread, "[C"), data: append([]uint16(nil), chars...)}
+		jStr.SetRefVar("value", "[C", jChars)
+		return jStr
+	}
+	bytes, coder := encodeStringBytes(chars)
+	jStr.SetRefVar("value", "[B", &Object{class: boot.LoadClass(thread, "[B"), data: bytes})
+	jStr.SetIntVar("coder", "B", coder)
+	return jStr
+}
+
+// GoString 返回java.lang.String对象对应的Go字符串，不成对的代理按mutf8包的约定保留
+func GoString(jStr *Object) string {
+	return mutf8.ToString(JStringChars(jStr))
+}
+
+// JStringChars 返回java.lang.String对象的UTF-16码元
+func JStringChars(jStr *Object) []uint16 {
+	if !hasByteValue(jStr.class) {
+		return jStr.GetRefVar("value", "[C").Chars()
+	}
+	bytes := jStr.GetRefVar("value", "[B").Bytes()
+	if jStr.GetIntVar("coder", "B") == coderLatin1 {
+		chars := make([]uint16, len(bytes))
+		for i, b := range bytes {
+			chars[i] = uint16(uint8(b))
+		}
+		return chars
+	}
+	chars := make([]uint16, len(bytes)/2)
+	for i := range chars {
+		chars[i] = uint16(uint8(bytes[2*i])) | uint16(uint8(bytes[2*i+1]))<<8
+	}
+	return chars
+}
+
+// hasByteValue String的value字段是不是JDK 9以后的byte[]
+func hasByteValue(stringClass *Class) bool {
+	for _, field := range stringClass.fields {
+		if field.name == "value" && !field.IsStatic() {
+			return field.descriptor == "[B"
+		}
+	}
+	return false
+}
+
+func encodeStringBytes(chars []uint16) ([]int8, int32) {
+	latin1 := true
+	for _, c := range chars {
+		if c > 0xFF {
+			latin1 = false
+			break
+		}
+	}
+	if latin1 {
+		bytes := make([]int8, len(chars))
+		for i, c := range chars {
+			bytes[i] = int8(c)
+		}
+		return bytes, coderLatin1
+	}
+	bytes := make([]int8, 2*len(chars))
+	for i, c := range chars {
+		bytes[2*i] = int8(c)
+		bytes[2*i+1] = int8(c >> 8)
+	}
+	return bytes, coderUTF16
+}
+
+// InternedString 返回字符串池里内容是goStr的String对象，没有时创建一个放进池里。
+// ldc加载的字符串常量、String类型的ConstantValue和虚拟机交给Java代码的成员名都来自字符串池，
+// 相同的内容是同一个对象。字符串池由所有类加载器共享，和String.intern用的是同一个
+func InternedString(thread Thread, loader *ClassLoader, goStr string) *Object {
+	loader.shared.lock.lock(thread)
+	defer loader.shared.lock.unlock()
+	interned := loader.shared.interned
+	if jStr, ok := interned[goStr]; ok {
+		return jStr
+	}
+	jStr := JString(thread, loader, goStr)
+	interned[goStr] = jStr
+	return jStr
+}
+
+// InternString 和String.intern一样：池里有相同内容的字符串时返回池里的，否则把jStr放进池里
+func InternString(thread Thread, jStr *Object) *Object {
+	shared := jStr.class.loader.shared
+	shared.lock.lock(thread)
+	defer shared.lock.unlock()
+	interned := shared.interned
+	goStr := GoString(jStr)
+	if internedStr, ok := interned[goStr]; ok {
+		return internedStr
+	}
+	interned[goStr] = jStr
+	return jStr
+}
@@ -0,0 +1,141 @@
+package heap
+
+import (
+	"slices"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/mutf8"
+)
+
+// testThread 不经过解释器时加载类用的线程
+type testThread uint32
+
+func (thread testThread) ID() uint32     { return uint32(thread) }
+func (thread testThread) BeginBlocking() {}
+func (thread testThread) EndBlocking()   {}
+
+// String的两种布局，Object、Cloneable和Serializable是数组类要用的
+const (
+	jdk8String = `.class public final java/lang/String
+.super java/lang/Object
+.field private final value [C
+.field private hash I`
+	jdk9String = `.class public final java/lang/String
+.super java/lang/Object
+.field private final value [B
+.field private final coder B
+.field private hash I`
+)
+
+var bootSources = []string{
+	".class public java/lang/Object",
+	".class public interface abstract java/lang/Cloneable\n.super java/lang/Object",
+	".class public interface abstract java/io/Serializable\n.super java/lang/Object",
+}
+
+// newStringLoader 返回启动类加载器，类库只有几个类，String用stringSource定义
+func newStringLoader(t *testing.T, stringSource string) *ClassLoader {
+	t.Helper()
+	boot := NewClassLoaders(classpath.ParseOptionalJre(t.TempDir(), t.TempDir()), nil, nil).Bootstrap()
+	for _, source := range append(bootSources, stringSource) {
+		className, data, err := asm.Assemble("boot.j", []byte(source), asm.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		boot.DefineClass(testThread(1), className, data)
+	}
+	return boot
+}
+
+func TestJStringLayout(t *testing.T) {
+	tests := []struct {
+		name  string
+		chars []uint16
+		coder int32
+		bytes []int8 // JDK 9布局的value
+	}{
+		{"empty", []uint16{}, coderLatin1, []int8{}},
+		{"ascii", []uint16{'a', 'b', 'c'}, coderLatin1, []int8{'a', 'b', 'c'}},
+		{"latin1", []uint16{'a', 0xE9, 0xFF}, coderLatin1, []int8{'a', -0x17, -1}},
+		{"utf16", []uint16{'a', 0x4E2D}, coderUTF16, []int8{'a', 0, 0x2D, 0x4E}},
+		{"surrogates", []uint16{0xD83D, 0xDE00}, coderUTF16, []int8{0x3D, -0x28, 0x00, -0x22}},
+	}
+	jdk8, jdk9 := newStringLoader(t, jdk8String), newStringLoader(t, jdk9String)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jStr := JStringFromChars(testThread(1), jdk8, test.chars)
+			value := jStr.GetRefVar("value", "[C")
+			if value.Class().Name() != "[C" || !slices.Equal(value.Chars(), test.chars) {
+				t.Errorf("JDK 8 value = %s %v, want [C %v", value.Class().Name(), value.Chars(), test.chars)
+			}
+			if chars := JStringChars(jStr); !slices.Equal(chars, test.chars) {
+				t.Errorf("JDK 8 JStringChars = %v, want %v", chars, test.chars)
+			}
+
+			jStr = JStringFromChars(testThread(1), jdk9, test.chars)
+			value = jStr.GetRefVar("value", "[B")
+			if value.Class().Name() != "[B" || !slices.Equal(value.Bytes(), test.bytes) {
+				t.Errorf("JDK 9 value = %s %v, want [B %v", value.Class().Name(), value.Bytes(), test.bytes)
+			}
+			if coder := jStr.GetIntVar("coder", "B"); coder != test.coder {
+				t.Errorf("JDK 9 coder = %d, want %d", coder, test.coder)
+			}
+			if chars := JStringChars(jStr); !slices.Equal(chars, test.chars) {
+				t.Errorf("JDK 9 JStringChars = %v, want %v", chars, test.chars)
+			}
+		})
+	}
+}
+
+// 不成对的代理不是合法的UTF-16，经过Go字符串来回转换也要保留原样
+func TestUnpairedSurrogates(t *testing.T) {
+	tests := [][]uint16{
+		{0xD800},
+		{'a', 0xDC00, 'b'},
+		{0xDE00, 0xD83D},
+		{0xD83D, 0xDE00, 0xD83D},
+	}
+	for _, source := range []string{jdk8String, jdk9String} {
+		boot := newStringLoader(t, source)
+		for _, chars := range tests {
+			jStr := JStringFromChars(testThread(1), boot, chars)
+			if got := JStringChars(jStr); !slices.Equal(got, chars) {
+				t.Errorf("JStringChars(%v) = %v", chars, got)
+			}
+			goStr := GoString(jStr)
+			if got := JStringChars(JString(testThread(1), boot, goStr)); !slices.Equal(got, chars) {
+				t.Errorf("JString(GoString(%v)) = %v", chars, got)
+			}
+			if goStr != mutf8.ToString(chars) {
+				t.Errorf("GoString(%v) = %q", chars, goStr)
+			}
+		}
+	}
+}
+
+func TestInternString(t *testing.T) {
+	boot := newStringLoader(t, jdk9String)
+	thread := testThread(1)
+	pooled := InternedString(thread, boot, "pooled")
+	if InternedString(thread, boot, "pooled") != pooled {
+		t.Error("InternedString created a second object for the same content")
+	}
+	if InternString(thread, JString(thread, boot, "pooled")) != pooled {
+		t.Error("InternString did not return the pooled string")
+	}
+
+	fresh := JString(thread, boot, "fresh中")
+	if InternString(thread, fresh) != fresh {
+		t.Error("InternString of new content did not return its argument")
+	}
+	if InternedString(thread, boot, "fresh中") != fresh {
+		t.Error("InternedString did not find the string added by InternString")
+	}
+	// 不成对的代理也是字符串池的键的一部分
+	unpaired := JStringFromChars(thread, boot, []uint16{0xD800})
+	if InternString(thread, unpaired) != unpaired || InternString(thread, JStringFromChars(thread, boot, []uint16{0xDC00})) == unpaired {
+		t.Error("strings with different unpaired surrogates share a pool entry")
+	}
+}
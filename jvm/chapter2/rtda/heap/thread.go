@@ -0,0 +1,10 @@
+package heap
+
+// Thread heap用到的线程操作，由*rtda.Thread实现；rtda依赖heap，所以这里只能用接口
+type Thread interface {
+	// ID 线程的编号，从1开始，类加载锁用它记录持有者
+	ID() uint32
+	// BeginBlocking和EndBlocking包住会阻塞的等待，期间线程转储可以读这个线程的栈
+	BeginBlocking()
+	EndBlocking()
+}
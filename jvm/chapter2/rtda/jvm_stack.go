@@ -0,0 +1,63 @@
+package rtda
+
+// Stack Java虚拟机栈，用链表实现，栈顶是当前帧
+type Stack struct {
+	maxSize uint
+	reserve uint // 处理StackOverflowError时临时允许多用的帧数
+	size    uint
+	_top    *Frame
+}
+
+func newStack(maxSize uint) *Stack {
+	return &Stack{
+		maxSize: maxSize,
+	}
+}
+
+// 帧数超过上限时抛出StackOverflowError，不让无限递归耗尽Go的内存
+func (stack *Stack) push(frame *Frame) {
+	if stack.size >= stack.maxSize+stack.reserve {
+		panic(&StackOverflowError{stack.maxSize})
+	}
+
+	if stack._top != nil {
+		frame.lower = stack._top
+	}
+
+	stack._top = frame
+	stack.size++
+}
+
+func (stack *Stack) pop() *Frame {
+	if stack._top == nil {
+		panic("jvm stack is empty!")
+	}
+
+	top := stack._top
+	stack._top = top.lower
+	top.lower = nil
+	stack.size--
+
+	return top
+}
+
+func (stack *Stack) top() *Frame {
+	if stack._top == nil {
+		panic("jvm stack is empty!")
+	}
+
+	return stack._top
+}
+
+func (stack *Stack) isEmpty() bool {
+	return stack._top == nil
+}
+
+// frames 返回栈里的所有帧，栈顶在前
+func (stack *Stack) frames() []*Frame {
+	frames := make([]*Frame, 0, stack.size)
+	for frame := stack._top; frame != nil; frame = frame.lower {
+		frames = append(frames, frame)
+	}
+	return frames
+}
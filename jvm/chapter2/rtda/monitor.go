@@ -0,0 +1,295 @@
+package rtda
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+对象锁。每个对象的锁字（heap.Object.LockWord）有三种状态：
+
+	0                     没有线程持有
+	持有者编号<<32 | 次数   轻量锁：只有一个线程用过，次数是重入的层数
+	inflatedLock          已经膨胀成Monitor
+
+没有竞争的加锁和解锁只是对锁字的CAS。别的线程来争用、或者持有者要wait时，
+锁膨胀成Monitor：用Go的互斥量和条件变量排队，记录等待集合。膨胀以后不再收缩
+*/
+const (
+	inflatedLock  = ^uint64(0)
+	lockCountMask = 1<<32 - 1
+)
+
+// 锁膨胀时持有，同一时间只有一个线程在膨胀锁，保证一个对象只有一个Monitor
+var inflateMu sync.Mutex
+
+// Monitor 膨胀以后的对象锁
+type Monitor struct {
+	mu      sync.Mutex
+	entry   *sync.Cond   // 等待进入监视器的线程在它上面等
+	object  *heap.Object // 从哪个对象的锁膨胀来的
+	owner   uint32       // 持有者的线程编号，0表示没有；在mu里原子地写，线程转储不加mu原子地读
+	count   int          // 持有者重入的层数
+	waitSet []*Thread    // 在对象上wait的线程，按wait的先后排列
+}
+
+// thinOwner 轻量锁的锁字里持有者的部分
+func thinOwner(thread *Thread) uint64 {
+	return uint64(thread.id) << 32
+}
+
+// MonitorEnter 进入object的监视器，别的线程持有时阻塞，同一个线程可以重入
+func MonitorEnter(thread *Thread, object *heap.Object) {
+	word := object.LockWord()
+	self := thinOwner(thread)
+	for {
+		w := atomic.LoadUint64(word)
+		switch {
+		case w == 0:
+			if atomic.CompareAndSwapUint64(word, 0, self|1) {
+				return
+			}
+		case w == inflatedLock:
+			monitorOf(object).enter(thread)
+			return
+		case w&^lockCountMask == self:
+			if atomic.CompareAndSwapUint64(word, w, w+1) {
+				return
+			}
+		default:
+			inflate(object).enter(thread) // 别的线程持有轻量锁
+			return
+		}
+	}
+}
+
+// MonitorExit 退出object的监视器，当前线程不是持有者时抛出IllegalMonitorStateException
+func MonitorExit(thread *Thread, object *heap.Object) {
+	word := object.LockWord()
+	self := thinOwner(thread)
+	for {
+		w := atomic.LoadUint64(word)
+		switch {
+		case w == inflatedLock:
+			monitorOf(object).exit(thread)
+			return
+		case w&^lockCountMask == self:
+			next := w - 1
+			if w&lockCountMask == 1 {
+				next = 0
+			}
+			// 失败说明别的线程刚把锁膨胀了，重新读锁字
+			if atomic.CompareAndSwapUint64(word, w, next) {
+				return
+			}
+		default:
+			panic("java.lang.IllegalMonitorStateException: current thread is not owner")
+		}
+	}
+}
+
+// HoldsLock 当前线程是否持有object的监视器
+func HoldsLock(thread *Thread, object *heap.Object) bool {
+	w := atomic.LoadUint64(object.LockWord())
+	if w == inflatedLock {
+		monitor := monitorOf(object)
+		monitor.mu.Lock()
+		defer monitor.mu.Unlock()
+		return monitor.owner == thread.id
+	}
+	return w&^lockCountMask == thinOwner(thread)
+}
+
+// lockOwner 返回持有object的监视器的线程编号，没有线程持有时返回0。
+// 不加锁，线程转储在安全点上用，这时持有者不会变
+func lockOwner(object *heap.Object) uint32 {
+	w := atomic.LoadUint64(object.LockWord())
+	if w == inflatedLock {
+		return atomic.LoadUint32(&monitorOf(object).owner)
+	}
+	return uint32(w >> 32)
+}
+
+/*
+Wait 实现Object.wait：释放object的监视器，等到被notify、被中断或者超时，再重新进入监视器。
+timeoutMillis为0时一直等。等待前后发现中断标志时清除它并抛出InterruptedException，
+这时线程已经重新持有监视器
+*/
+func Wait(thread *Thread, object *heap.Object, timeoutMillis int64) {
+	if timeoutMillis < 0 {
+		panic("java.lang.IllegalArgumentException: timeout value is negative")
+	}
+	if !HoldsLock(thread, object) {
+		panic("java.lang.IllegalMonitorStateException: current thread is not owner")
+	}
+	if thread.IsInterrupted(true) {
+		panic("java.lang.InterruptedException")
+	}
+	inflate(object).wait(thread, time.Duration(timeoutMillis)*time.Millisecond)
+	if thread.IsInterrupted(true) {
+		panic("java.lang.InterruptedException")
+	}
+}
+
+// Notify 唤醒一个在object上wait的线程，all为true时唤醒全部，当前线程必须持有监视器
+func Notify(thread *Thread, object *heap.Object, all bool) {
+	w := atomic.LoadUint64(object.LockWord())
+	if w != inflatedLock {
+		if w&^lockCountMask != thinOwner(thread) {
+			panic("java.lang.IllegalMonitorStateException: current thread is not owner")
+		}
+		return // 没有膨胀过的锁上不会有线程在wait
+	}
+	monitorOf(object).notify(thread, all)
+}
+
+func monitorOf(object *heap.Object) *Monitor {
+	return object.Monitor().(*Monitor)
+}
+
+// inflate 把object的锁膨胀成Monitor，轻量锁的持有者和重入次数转移到Monitor里；已经膨胀时直接返回
+func inflate(object *heap.Object) *Monitor {
+	inflateMu.Lock()
+	defer inflateMu.Unlock()
+	word := object.LockWord()
+	for {
+		w := atomic.LoadUint64(word)
+		if w == inflatedLock {
+			return monitorOf(object)
+		}
+		monitor := &Monitor{object: object}
+		monitor.entry = sync.NewCond(&monitor.mu)
+		if w != 0 {
+			monitor.setOwner(uint32(w>>32), int(w&lockCountMask))
+		}
+		// 先记下Monitor再改锁字，看到膨胀状态的线程一定能拿到Monitor；
+		// CAS失败说明持有者刚重入或者解锁了，按新的锁字重来
+		object.SetMonitor(monitor)
+		if atomic.CompareAndSwapUint64(word, w, inflatedLock) {
+			return monitor
+		}
+	}
+}
+
+func (monitor *Monitor) enter(thread *Thread) {
+	monitor.mu.Lock()
+	if monitor.owner == thread.id {
+		monitor.count++
+		monitor.mu.Unlock()
+		return
+	}
+	monitor.acquire(thread, 1)
+}
+
+/*
+acquire 等到没有持有者，然后以count层重入持有监视器。调用时持有mu，返回时已经放开了mu。
+等待期间线程算作阻塞，线程转储可以读它的栈；先放开mu再拿回栈，
+不然线程转储拿着栈的时候，别的线程会在mu上等
+*/
+func (monitor *Monitor) acquire(thread *Thread, count int) {
+	if monitor.owner == 0 {
+		monitor.setOwner(thread.id, count)
+		monitor.mu.Unlock()
+		return
+	}
+	status := thread.Status()
+	thread.SetStatus(ThreadBlockedOnMonitorEnter)
+	thread.blockedOn = monitor.object
+	thread.BeginBlocking()
+	for monitor.owner != 0 {
+		monitor.entry.Wait()
+	}
+	monitor.setOwner(thread.id, count)
+	monitor.mu.Unlock()
+	thread.EndBlocking()
+	thread.blockedOn = nil
+	thread.SetStatus(status)
+}
+
+// setOwner 调用时持有mu，或者Monitor还没有发布
+func (monitor *Monitor) setOwner(owner uint32, count int) {
+	atomic.StoreUint32(&monitor.owner, owner)
+	monitor.count = count
+}
+
+func (monitor *Monitor) exit(thread *Thread) {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	if monitor.owner != thread.id {
+		panic("java.lang.IllegalMonitorStateException: current thread is not owner")
+	}
+	monitor.count--
+	if monitor.count == 0 {
+		monitor.setOwner(0, 0)
+		monitor.entry.Signal()
+	}
+}
+
+// wait 调用者已经检查过当前线程持有监视器。被notify的线程已经从等待集合里移除；
+// 被中断或者超时的线程自己移除
+func (monitor *Monitor) wait(thread *Thread, timeout time.Duration) {
+	monitor.mu.Lock()
+	count := monitor.count
+	monitor.setOwner(0, 0)
+	monitor.waitSet = append(monitor.waitSet, thread)
+	monitor.entry.Signal()
+	status := thread.Status()
+	if timeout > 0 {
+		thread.SetStatus(ThreadInObjectWaitTimed)
+	} else {
+		thread.SetStatus(ThreadInObjectWait)
+	}
+	thread.waitingOn = monitor.object
+	monitor.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var remaining time.Duration
+		if timeout > 0 {
+			remaining = time.Until(deadline)
+		}
+		monitor.mu.Lock()
+		waiting := monitor.removeWaiter(thread, false)
+		if !waiting || thread.IsInterrupted(false) || (timeout > 0 && remaining <= 0) {
+			monitor.removeWaiter(thread, true)
+			break
+		}
+		monitor.mu.Unlock()
+		thread.Park(remaining) // notify和中断都会Unpark
+	}
+	thread.waitingOn = nil
+	thread.SetStatus(status)
+	monitor.acquire(thread, count)
+}
+
+// removeWaiter 返回thread是否在等待集合里，remove为true时把它移除；调用时持有mu
+func (monitor *Monitor) removeWaiter(thread *Thread, remove bool) bool {
+	for i, waiter := range monitor.waitSet {
+		if waiter == thread {
+			if remove {
+				monitor.waitSet = append(monitor.waitSet[:i], monitor.waitSet[i+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (monitor *Monitor) notify(thread *Thread, all bool) {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	if monitor.owner != thread.id {
+		panic("java.lang.IllegalMonitorStateException: current thread is not owner")
+	}
+	for len(monitor.waitSet) > 0 {
+		waiter := monitor.waitSet[0]
+		monitor.waitSet = monitor.waitSet[1:]
+		waiter.Unpark()
+		if !all {
+			break
+		}
+	}
+}
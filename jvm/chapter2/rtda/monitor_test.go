@@ -0,0 +1,113 @@
+package rtda
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+func expectPanic(t *testing.T, want string, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != want {
+			t.Errorf("panic %v, want %q", r, want)
+		}
+	}()
+	fn()
+}
+
+func TestThinLockReentrant(t *testing.T) {
+	thread := NewThread(DefaultMaxStackDepth)
+	object := &heap.Object{}
+	MonitorEnter(thread, object)
+	MonitorEnter(thread, object)
+	if w := atomic.LoadUint64(object.LockWord()); w != thinOwner(thread)|2 {
+		t.Fatalf("lock word %#x, want thin lock held twice", w)
+	}
+	MonitorExit(thread, object)
+	if !HoldsLock(thread, object) {
+		t.Fatal("lock released after the first exit")
+	}
+	MonitorExit(thread, object)
+	if HoldsLock(thread, object) || object.Monitor() != nil {
+		t.Fatal("uncontended lock not released or inflated")
+	}
+	expectPanic(t, "java.lang.IllegalMonitorStateException: current thread is not owner", func() {
+		MonitorExit(thread, object)
+	})
+}
+
+func TestContendedLock(t *testing.T) {
+	object := &heap.Object{}
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			thread := NewThread(DefaultMaxStackDepth)
+			for j := 0; j < 1000; j++ {
+				MonitorEnter(thread, object)
+				MonitorEnter(thread, object)
+				counter++
+				MonitorExit(thread, object)
+				MonitorExit(thread, object)
+			}
+		}()
+	}
+	wg.Wait()
+	if counter != 8000 {
+		t.Fatalf("counter = %d, want 8000", counter)
+	}
+}
+
+func TestWaitNotify(t *testing.T) {
+	object := &heap.Object{}
+	main := NewThread(DefaultMaxStackDepth)
+	waiter := NewThread(DefaultMaxStackDepth)
+	expectPanic(t, "java.lang.IllegalMonitorStateException: current thread is not owner", func() {
+		Wait(main, object, 0)
+	})
+
+	done := make(chan bool)
+	go func() {
+		MonitorEnter(waiter, object)
+		MonitorEnter(waiter, object)
+		Wait(waiter, object, 0)
+		held := HoldsLock(waiter, object)
+		MonitorExit(waiter, object)
+		MonitorExit(waiter, object)
+		done <- held
+	}()
+	for waiter.Status() != ThreadInObjectWait {
+		time.Sleep(time.Millisecond)
+	}
+	MonitorEnter(main, object)
+	Notify(main, object, false)
+	MonitorExit(main, object)
+	if held := <-done; !held {
+		t.Fatal("monitor not reacquired after wait")
+	}
+}
+
+func TestWaitInterruptedAndTimeout(t *testing.T) {
+	object := &heap.Object{}
+	thread := NewThread(DefaultMaxStackDepth)
+	MonitorEnter(thread, object)
+	start := time.Now()
+	Wait(thread, object, 20)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("wait(20) returned after %v", elapsed)
+	}
+	thread.Interrupt()
+	expectPanic(t, "java.lang.InterruptedException", func() {
+		Wait(thread, object, 0)
+	})
+	if !HoldsLock(thread, object) || thread.IsInterrupted(false) {
+		t.Error("interrupted wait must keep the monitor and clear the interrupt flag")
+	}
+	MonitorExit(thread, object)
+}
@@ -0,0 +1,112 @@
+package rtda
+
+import (
+	"math"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// OperandStack 操作数栈，大小由Code属性的max_stack决定
+type OperandStack struct {
+	size  uint
+	slots []Slot
+}
+
+func newOperandStack(maxStack uint) *OperandStack {
+	if maxStack > 0 {
+		return &OperandStack{
+			slots: make([]Slot, maxStack),
+		}
+	}
+	return nil
+}
+
+func (operandStack *OperandStack) PushInt(val int32) {
+	operandStack.slots[operandStack.size].num = val
+	operandStack.size++
+}
+
+func (operandStack *OperandStack) PopInt() int32 {
+	operandStack.size--
+	return operandStack.slots[operandStack.size].num
+}
+
+func (operandStack *OperandStack) PushFloat(val float32) {
+	bits := math.Float32bits(val)
+	operandStack.slots[operandStack.size].num = int32(bits)
+	operandStack.size++
+}
+
+func (operandStack *OperandStack) PopFloat() float32 {
+	operandStack.size--
+	bits := uint32(operandStack.slots[operandStack.size].num)
+	return math.Float32frombits(bits)
+}
+
+// long占两个槽位，低32位先入栈
+func (operandStack *OperandStack) PushLong(val int64) {
+	operandStack.slots[operandStack.size].num = int32(val)
+	operandStack.slots[operandStack.size+1].num = int32(val >> 32)
+	operandStack.size += 2
+}
+
+func (operandStack *OperandStack) PopLong() int64 {
+	operandStack.size -= 2
+	low := uint32(operandStack.slots[operandStack.size].num)
+	high := uint32(operandStack.slots[operandStack.size+1].num)
+	return int64(high)<<32 | int64(low)
+}
+
+func (operandStack *OperandStack) PushDouble(val float64) {
+	bits := math.Float64bits(val)
+	operandStack.PushLong(int64(bits))
+}
+
+func (operandStack *OperandStack) PopDouble() float64 {
+	bits := uint64(operandStack.PopLong())
+	return math.Float64frombits(bits)
+}
+
+func (operandStack *OperandStack) PushRef(ref *heap.Object) {
+	operandStack.slots[operandStack.size].ref = ref
+	operandStack.size++
+}
+
+// PopRef 弹出引用后把槽位清空，让垃圾回收可以回收对象
+func (operandStack *OperandStack) PopRef() *heap.Object {
+	operandStack.size--
+	ref := operandStack.slots[operandStack.size].ref
+	operandStack.slots[operandStack.size].ref = nil
+	return ref
+}
+
+// PushSlot和PopSlot不关心槽位的类型，dup、swap、pop等指令用
+func (operandStack *OperandStack) PushSlot(slot Slot) {
+	operandStack.slots[operandStack.size] = slot
+	operandStack.size++
+}
+
+func (operandStack *OperandStack) PopSlot() Slot {
+	operandStack.size--
+	slot := operandStack.slots[operandStack.size]
+	operandStack.slots[operandStack.size].ref = nil
+	return slot
+}
+
+// GetRefFromTop 返回距栈顶n个槽位的引用，不弹出；n为0时是栈顶
+func (operandStack *OperandStack) GetRefFromTop(n uint) *heap.Object {
+	return operandStack.slots[operandStack.size-1-n].ref
+}
+
+// Size 返回栈中已用的槽位数
+func (operandStack *OperandStack) Size() uint {
+	return operandStack.size
+}
+
+// Clear 清空操作数栈，处理异常时用
+func (operandStack *OperandStack) Clear() {
+	for i := uint(0); i < operandStack.size; i++ {
+		operandStack.slots[i].ref = nil
+	}
+	operandStack.size = 0
+}
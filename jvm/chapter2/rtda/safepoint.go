@@ -0,0 +1,89 @@
+package rtda
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+安全点。线程转储要读别的线程的栈，而栈只由执行它的线程修改，不加锁。
+线程执行Java代码时一直持有自己的stackMu：往回跳的分支、进入异常处理器和方法调用时调用Safepoint，
+有操作在等安全点时放开stackMu，等操作结束再拿回来。线程阻塞（等待进入监视器、wait、sleep）
+期间也放开stackMu，醒来先拿回stackMu再继续，所以阻塞的线程不用走到下一个安全点就能被读栈
+*/
+var safepoint struct {
+	sync.Mutex       // 在安全点上执行的操作持有，同一时间只有一个
+	requested  int32 // 有操作在等线程停下，原子读写
+}
+
+// 等线程停到安全点的最长时间。在本地方法里阻塞的线程（比如读标准输入）停不下来，
+// 也可能在等一个停在安全点上的线程持有的锁，过了这个时间就不再等它们
+const safepointTimeout = 500 * time.Millisecond
+
+// Safepoint 往回跳的分支、进入异常处理器和方法调用时检查，有线程转储在等时停下来。
+// 不往回跳的代码执行的指令数有限，线程总会很快走到下一个检查的地方
+func (thread *Thread) Safepoint() {
+	if atomic.LoadInt32(&safepoint.requested) != 0 && thread.attached {
+		thread.stackMu.Unlock()
+		safepoint.Lock() // 等安全点上的操作结束
+		safepoint.Unlock()
+		thread.stackMu.Lock()
+	}
+}
+
+// BeginBlocking 线程要阻塞了，放开stackMu；和EndBlocking成对调用，期间不能读写栈
+func (thread *Thread) BeginBlocking() {
+	if thread.attached {
+		thread.stackMu.Unlock()
+	}
+}
+
+// EndBlocking 阻塞结束，拿回stackMu，线程转储正在读栈时等它读完
+func (thread *Thread) EndBlocking() {
+	if thread.attached {
+		thread.stackMu.Lock()
+	}
+}
+
+/*
+atSafepoint 让所有活着的Java线程停在安全点上或者阻塞着，然后调用fn。
+self是调用者自己的线程，不是Java线程时为nil；它的栈本来就不会变。
+stopped里是停下来了的线程，fn只能读它们的栈；过了safepointTimeout还没停下的线程不在里面
+*/
+func atSafepoint(self *Thread, fn func(all []*Thread, stopped map[*Thread]bool)) {
+	if self != nil {
+		self.BeginBlocking() // 别的线程正在做线程转储时，不让它等自己
+	}
+	safepoint.Lock()
+	if self != nil {
+		self.EndBlocking()
+	}
+	defer safepoint.Unlock()
+	atomic.StoreInt32(&safepoint.requested, 1)
+	defer atomic.StoreInt32(&safepoint.requested, 0)
+
+	all := liveThreads()
+	stopped := map[*Thread]bool{}
+	deadline := time.Now().Add(safepointTimeout)
+	for _, thread := range all {
+		if thread == self {
+			stopped[thread] = true
+		} else if thread.tryLockStack(deadline) {
+			stopped[thread] = true
+			defer thread.stackMu.Unlock()
+		}
+	}
+	fn(all, stopped)
+}
+
+// tryLockStack 在deadline之前拿到stackMu时返回true
+func (thread *Thread) tryLockStack(deadline time.Time) bool {
+	for !thread.stackMu.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
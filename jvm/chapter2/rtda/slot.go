@@ -0,0 +1,10 @@
+package rtda
+
+import "go.buppt.cn/jvm/chapter2/rtda/heap"
+
+// Slot 局部变量表和操作数栈的一个槽位：int、float和引用各占一个，
+// long和double占两个，低32位在前
+type Slot struct {
+	num int32
+	ref *heap.Object
+}
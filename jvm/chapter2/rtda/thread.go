@@ -0,0 +1,227 @@
+package rtda
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// DefaultMaxStackDepth 线程的Java虚拟机栈默认最多容纳的帧数
+const DefaultMaxStackDepth = 1024
+
+// MaxStackDepthLimit -XmaxDepth允许的最大值。帧在Go的堆上分配，一帧连同局部变量表和操作数栈
+// 通常有几百字节，这个上限让一个线程的栈在一般的递归下不超过一百MB左右
+const MaxStackDepthLimit = 1 << 18
+
+/*
+JVM
+
+	Thread
+	  pc
+	  Stack
+	    Frame
+	      LocalVars
+	      OperandStack
+*/
+type Thread struct {
+	id          uint32 // 线程的编号，从1开始，监视器用它记录持有者
+	pc          int    // pc寄存器，当前指令的地址
+	stack       *Stack
+	jThread     *heap.Object  // Java代码看到的java.lang.Thread对象
+	status      int32         // Thread.threadStatus的值，见ThreadStatus
+	interrupted int32         // 中断标志，1表示被中断
+	wakeup      chan struct{} // 中断和notify唤醒Park中的线程，容量为1
+	daemon      bool          // 守护线程，Start时确定
+
+	// 线程转储用，见safepoint.go。attached只由执行线程的goroutine读写，后两个字段只在持有stackMu时读写
+	stackMu   sync.Mutex   // 执行Java代码时持有，线程转储拿到它才能读栈
+	attached  bool         // 和goroutine关联了，阻塞时要放开stackMu
+	blockedOn *heap.Object // 正在等待进入这个对象的监视器
+	waitingOn *heap.Object // 正在这个对象上wait
+}
+
+// NewThread maxStackDepth是栈中最多的帧数，超过时PushFrame抛出StackOverflowError
+func NewThread(maxStackDepth uint) *Thread {
+	return &Thread{
+		id:     atomic.AddUint32(&lastThreadID, 1),
+		stack:  newStack(maxStackDepth),
+		wakeup: make(chan struct{}, 1),
+	}
+}
+
+// 最后分配的线程编号
+var lastThreadID uint32
+
+func (thread *Thread) ID() uint32 {
+	return thread.id
+}
+
+// IsDaemon 线程是不是守护线程
+func (thread *Thread) IsDaemon() bool {
+	return thread.daemon
+}
+
+// MaxStackDepth 返回栈中最多的帧数，新线程的栈和创建它的线程一样大
+func (thread *Thread) MaxStackDepth() uint {
+	return thread.stack.maxSize
+}
+
+func (thread *Thread) PC() int {
+	return thread.pc
+}
+
+func (thread *Thread) SetPC(pc int) {
+	thread.pc = pc
+}
+
+// JThread 返回线程对应的java.lang.Thread对象，Thread.currentThread的返回值
+func (thread *Thread) JThread() *heap.Object {
+	return thread.jThread
+}
+
+// SetJThread 把线程和Thread对象关联起来，Thread对象的extra是这个线程
+func (thread *Thread) SetJThread(jThread *heap.Object) {
+	thread.jThread = jThread
+	jThread.SetExtra(thread)
+}
+
+// ThreadStatus 和HotSpot一样用JVMTI的线程状态位组合表示线程状态，
+// sun.misc.VM.toThreadState把它换成Thread.State
+type ThreadStatus int32
+
+const (
+	ThreadNew                   ThreadStatus = 0
+	ThreadRunnable              ThreadStatus = 0x0005 // ALIVE | RUNNABLE
+	ThreadSleeping              ThreadStatus = 0x00e1 // ALIVE | WAITING | WAITING_WITH_TIMEOUT | SLEEPING
+	ThreadInObjectWait          ThreadStatus = 0x0191 // ALIVE | WAITING | WAITING_INDEFINITELY | IN_OBJECT_WAIT
+	ThreadInObjectWaitTimed     ThreadStatus = 0x01a1 // ALIVE | WAITING | WAITING_WITH_TIMEOUT | IN_OBJECT_WAIT
+	ThreadBlockedOnMonitorEnter ThreadStatus = 0x0401 // ALIVE | BLOCKED_ON_MONITOR_ENTER
+	ThreadTerminated            ThreadStatus = 0x0002 // TERMINATED
+)
+
+func (thread *Thread) Status() ThreadStatus {
+	return ThreadStatus(atomic.LoadInt32(&thread.status))
+}
+
+// SetStatus 设置线程状态，同时写到Thread对象的threadStatus字段里，Thread.getState读它
+func (thread *Thread) SetStatus(status ThreadStatus) {
+	atomic.StoreInt32(&thread.status, int32(status))
+	if thread.jThread != nil {
+		thread.jThread.SetIntVar("threadStatus", "I", int32(status))
+	}
+}
+
+// Interrupt 设置中断标志，唤醒正在sleep或者wait的线程
+func (thread *Thread) Interrupt() {
+	atomic.StoreInt32(&thread.interrupted, 1)
+	thread.Unpark()
+}
+
+// IsInterrupted 返回中断标志，clear为true时同时清除它
+func (thread *Thread) IsInterrupted(clear bool) bool {
+	if clear {
+		return atomic.SwapInt32(&thread.interrupted, 0) == 1
+	}
+	return atomic.LoadInt32(&thread.interrupted) == 1
+}
+
+// Park 阻塞到Unpark或者超时，timeout为0时不超时。
+// 在Park之前调用的Unpark也会让下一次Park立即返回，所以调用者要自己检查等待的条件
+func (thread *Thread) Park(timeout time.Duration) {
+	thread.BeginBlocking()
+	defer thread.EndBlocking()
+	if timeout <= 0 {
+		<-thread.wakeup
+		return
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-thread.wakeup:
+	case <-timer.C:
+	}
+}
+
+// Unpark 唤醒Park中的线程；线程没有Park时，它的下一次Park立即返回
+func (thread *Thread) Unpark() {
+	select {
+	case thread.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// PushFrame 栈满时panic一个*StackOverflowError，由解释器recover
+func (thread *Thread) PushFrame(frame *Frame) {
+	thread.stack.push(frame)
+}
+
+// PopFrame 弹出栈顶帧，释放这个帧还持有的监视器：同步方法正常返回或者因为异常结束时都在这里释放锁
+func (thread *Thread) PopFrame() *Frame {
+	frame := thread.stack.pop()
+	frame.releaseMonitors()
+	return frame
+}
+
+func (thread *Thread) CurrentFrame() *Frame {
+	return thread.stack.top()
+}
+
+func (thread *Thread) IsStackEmpty() bool {
+	return thread.stack.isEmpty()
+}
+
+// StackDepth 返回栈中的帧数
+func (thread *Thread) StackDepth() uint {
+	return thread.stack.size
+}
+
+// Frames 返回栈里的所有帧，栈顶在前，填写异常的栈轨迹时用
+func (thread *Thread) Frames() []*Frame {
+	return thread.stack.frames()
+}
+
+// stackReserve 栈溢出以后还允许再压入的帧数，足够创建StackOverflowError对象
+const stackReserve = 64
+
+// WithReservedStack 执行fn期间允许栈超出上限stackReserve个帧，
+// 栈已经满了还要执行Java代码（比如异常的构造函数）时用
+func (thread *Thread) WithReservedStack(fn func()) {
+	stack := thread.stack
+	if stack.reserve > 0 {
+		fn() // 已经在用预留的帧了
+		return
+	}
+	stack.reserve = stackReserve
+	defer func() { stack.reserve = 0 }()
+	fn()
+}
+
+// NewFrame 新建属于这个线程的帧，局部变量表和操作数栈的大小取自Code属性
+func (thread *Thread) NewFrame(maxLocals, maxStack uint) *Frame {
+	return newFrame(thread, maxLocals, maxStack)
+}
+
+// NewShimFrame 新建不属于任何方法的垫底帧：虚拟机同步执行Java方法时先压入它，
+// 用它的操作数栈传参数和接收返回值
+func (thread *Thread) NewShimFrame(maxStack uint) *Frame {
+	return newFrame(thread, 0, maxStack)
+}
+
+// NewMethodFrame 新建执行method的帧，局部变量表和操作数栈的大小取自方法的Code属性
+func (thread *Thread) NewMethodFrame(method *heap.Method) *Frame {
+	frame := newFrame(thread, method.MaxLocals(), method.MaxStack())
+	frame.method = method
+	return frame
+}
+
+// StackOverflowError 栈中的帧数超过了上限
+type StackOverflowError struct {
+	MaxDepth uint
+}
+
+func (stackOverflowError *StackOverflowError) Error() string {
+	return fmt.Sprintf("java.lang.StackOverflowError: stack depth exceeds %d frames", stackOverflowError.MaxDepth)
+}
@@ -0,0 +1,94 @@
+package rtda
+
+import "go.buppt.cn/jvm/chapter2/rtda/heap"
+
+// ThreadInfo 线程转储时一个线程的快照
+type ThreadInfo struct {
+	Thread    *Thread
+	Status    ThreadStatus
+	Stopped   bool         // 停到了安全点上；没停下的线程只有Thread和Status
+	Frames    []FrameInfo  // 栈顶在前，不包括虚拟机自己压入的垫底帧
+	BlockedOn *heap.Object // 正在等待进入这个对象的监视器
+	LockOwner uint32       // 拍快照时持有BlockedOn的线程的编号
+	WaitingOn *heap.Object // 正在这个对象上wait
+}
+
+// FrameInfo 栈里的一帧
+type FrameInfo struct {
+	Method *heap.Method
+	PC     int            // 正在执行的指令
+	Locked []*heap.Object // 在这个帧里进入、还没有退出的监视器，后进入的在前
+}
+
+// DumpThreads 在安全点上给所有活着的Java线程拍快照，按线程编号排列；
+// self是调用者自己的线程，不是Java线程时为nil
+func DumpThreads(self *Thread) []*ThreadInfo {
+	var infos []*ThreadInfo
+	atSafepoint(self, func(all []*Thread, stopped map[*Thread]bool) {
+		for _, thread := range all {
+			info := &ThreadInfo{Thread: thread, Status: thread.Status(), Stopped: stopped[thread]}
+			if info.Stopped {
+				if info.BlockedOn = thread.blockedOn; info.BlockedOn != nil {
+					info.LockOwner = lockOwner(info.BlockedOn)
+				}
+				info.WaitingOn = thread.waitingOn
+				for _, frame := range thread.Frames() {
+					if frame.method != nil {
+						info.Frames = append(info.Frames, frame.info())
+					}
+				}
+			}
+			infos = append(infos, info)
+		}
+	})
+	return infos
+}
+
+func (frame *Frame) info() FrameInfo {
+	pc := frame.nextPC - 1 // 调用者的帧停在调用指令之后
+	if pc < 0 {
+		pc = 0 // 同步方法的帧压入以后、执行第一条指令之前在等锁
+	}
+	locked := make([]*heap.Object, len(frame.monitors))
+	for i, object := range frame.monitors {
+		locked[len(locked)-1-i] = object
+	}
+	return FrameInfo{Method: frame.method, PC: pc, Locked: locked}
+}
+
+/*
+FindDeadlocks 像HotSpot的ThreadService::find_deadlocks_at_safepoint一样找监视器的循环等待：
+线程等着进入的监视器由另一个线程持有，那个线程又在等……最后回到开始的线程。
+每个循环报告一次，从循环里最先找到的线程开始，依次是它等的锁的持有者
+*/
+func FindDeadlocks(infos []*ThreadInfo) [][]*ThreadInfo {
+	byID := map[uint32]*ThreadInfo{}
+	for _, info := range infos {
+		byID[info.Thread.id] = info
+	}
+	// waitsFor 返回持有info在等的监视器的线程。线程刚拿到锁、还没来得及清除blockedOn时持有者是自己
+	waitsFor := func(info *ThreadInfo) *ThreadInfo {
+		if info.BlockedOn == nil || info.LockOwner == info.Thread.id {
+			return nil
+		}
+		return byID[info.LockOwner]
+	}
+
+	var deadlocks [][]*ThreadInfo
+	visited := map[*ThreadInfo]bool{}
+	for _, start := range infos {
+		onPath := map[*ThreadInfo]int{}
+		var path []*ThreadInfo
+		info := start
+		for info != nil && !visited[info] {
+			visited[info] = true
+			onPath[info] = len(path)
+			path = append(path, info)
+			info = waitsFor(info)
+		}
+		if i, ok := onPath[info]; ok && info != nil {
+			deadlocks = append(deadlocks, path[i:])
+		}
+	}
+	return deadlocks
+}
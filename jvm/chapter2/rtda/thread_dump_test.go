@@ -0,0 +1,127 @@
+package rtda
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+func waitForStatus(t *testing.T, thread *Thread, status ThreadStatus) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for thread.Status() != status {
+		if time.Now().After(deadline) {
+			t.Fatalf("thread #%d status %#x, want %#x", thread.ID(), thread.Status(), status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func findInfo(infos []*ThreadInfo, thread *Thread) *ThreadInfo {
+	for _, info := range infos {
+		if info.Thread == thread {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestDumpStopsRunningThread(t *testing.T) {
+	thread := NewThread(DefaultMaxStackDepth)
+	var stop int32
+	done := make(chan struct{})
+	thread.Start(true, func() {
+		defer close(done)
+		thread.SetStatus(ThreadRunnable)
+		for atomic.LoadInt32(&stop) == 0 {
+			thread.Safepoint() // 像解释器一样在循环里检查
+		}
+	})
+	waitForStatus(t, thread, ThreadRunnable)
+	info := findInfo(DumpThreads(nil), thread)
+	atomic.StoreInt32(&stop, 1)
+	<-done
+	if info == nil || !info.Stopped {
+		t.Fatalf("running thread not stopped at a safepoint: %+v", info)
+	}
+}
+
+func TestFindDeadlocks(t *testing.T) {
+	a, b := &heap.Object{}, &heap.Object{}
+	t1, t2, t3 := NewThread(DefaultMaxStackDepth), NewThread(DefaultMaxStackDepth), NewThread(DefaultMaxStackDepth)
+	locked, proceed := make(chan struct{}), make(chan struct{})
+	// t1持有a等b，t2持有b等a；t3等a，在死锁的线程后面排队但不在循环里
+	lockBoth := func(thread *Thread, first, second *heap.Object) func() {
+		return func() {
+			MonitorEnter(thread, first)
+			locked <- struct{}{}
+			<-proceed
+			MonitorEnter(thread, second)
+		}
+	}
+	t1.Start(true, lockBoth(t1, a, b))
+	t2.Start(true, lockBoth(t2, b, a))
+	<-locked
+	<-locked
+	close(proceed)
+	t3.Start(true, func() { MonitorEnter(t3, a) })
+	for _, thread := range []*Thread{t1, t2, t3} {
+		waitForStatus(t, thread, ThreadBlockedOnMonitorEnter)
+	}
+
+	// 只看这几个线程，-count大于1时前几次留下的死锁线程还活着
+	all := DumpThreads(nil)
+	infos := []*ThreadInfo{findInfo(all, t1), findInfo(all, t2), findInfo(all, t3)}
+	if info := infos[2]; info == nil || info.BlockedOn != a {
+		t.Fatalf("t3 snapshot %+v, want blocked on a", info)
+	}
+	deadlocks := FindDeadlocks(infos)
+	if len(deadlocks) != 1 || len(deadlocks[0]) != 2 {
+		t.Fatalf("found %d deadlocks %v, want one cycle of two threads", len(deadlocks), deadlocks)
+	}
+	for _, info := range deadlocks[0] {
+		switch info.Thread {
+		case t1:
+			if info.BlockedOn != b || info.LockOwner != t2.ID() {
+				t.Error("t1 should wait for b held by t2")
+			}
+		case t2:
+			if info.BlockedOn != a || info.LockOwner != t1.ID() {
+				t.Error("t2 should wait for a held by t1")
+			}
+		default:
+			t.Errorf("thread #%d is not deadlocked", info.Thread.ID())
+		}
+	}
+}
+
+// 等别的线程初始化类和等监视器一样算作阻塞，线程转储不用等它
+func TestDumpStopsThreadWaitingForClassInit(t *testing.T) {
+	class := &heap.Class{}
+	initializer := NewThread(DefaultMaxStackDepth)
+	if !class.StartInit(initializer) {
+		t.Fatal("StartInit = false for an uninitialized class")
+	}
+	waiter := NewThread(DefaultMaxStackDepth)
+	done := make(chan bool)
+	waiter.Start(true, func() {
+		waiter.SetStatus(ThreadRunnable)
+		done <- class.StartInit(waiter)
+	})
+	waitForStatus(t, waiter, ThreadRunnable)
+	// 不知道waiter什么时候进入等待，多试几次；没有放开栈时每次都要等safepointTimeout
+	var info *ThreadInfo
+	for i := 0; i < 3 && (info == nil || !info.Stopped); i++ {
+		time.Sleep(10 * time.Millisecond)
+		info = findInfo(DumpThreads(nil), waiter)
+	}
+	class.FinishInit()
+	if <-done {
+		t.Error("StartInit = true after another thread finished the initialization")
+	}
+	if info == nil || !info.Stopped {
+		t.Fatalf("thread waiting for class initialization not stopped: %+v", info)
+	}
+}
@@ -0,0 +1,87 @@
+package rtda
+
+import (
+	"sort"
+	"sync"
+)
+
+// 所有活着的Java线程。每个Java线程由一个goroutine执行，键是线程的编号
+var threads = struct {
+	sync.Mutex
+	running   map[uint32]*Thread
+	nonDaemon int // 还没有结束的非守护线程数，不包括main线程
+}{running: map[uint32]*Thread{}}
+
+// nonDaemonExited 最后一个非守护线程结束时通知WaitNonDaemonThreads，和threads共用锁
+var nonDaemonExited = sync.NewCond(&threads.Mutex)
+
+// Attach 把线程和当前goroutine关联起来，线程开始执行Java代码，持有stackMu
+func (thread *Thread) Attach() {
+	thread.stackMu.Lock()
+	thread.attached = true
+	threads.Lock()
+	threads.running[thread.id] = thread
+	threads.Unlock()
+}
+
+// Detach 线程结束，解除和当前goroutine的关联
+func (thread *Thread) Detach() {
+	threads.Lock()
+	delete(threads.running, thread.id)
+	threads.Unlock()
+	thread.attached = false
+	thread.stackMu.Unlock()
+}
+
+// Start 在新的goroutine里执行run，run返回时线程结束。
+// 非守护线程结束之前，WaitNonDaemonThreads不会返回
+func (thread *Thread) Start(daemon bool, run func()) {
+	thread.daemon = daemon
+	if !daemon {
+		threads.Lock()
+		threads.nonDaemon++
+		threads.Unlock()
+	}
+	go func() {
+		if !daemon {
+			defer exitNonDaemon()
+		}
+		thread.Attach()
+		defer thread.Detach()
+		run()
+	}()
+}
+
+// WaitNonDaemonThreads 像HotSpot的DestroyJavaVM一样等待所有非守护线程结束，main线程结束后调用。
+// 等待期间main线程算作阻塞，不妨碍线程转储
+func WaitNonDaemonThreads(main *Thread) {
+	main.BeginBlocking()
+	defer main.EndBlocking()
+	threads.Lock()
+	for threads.nonDaemon > 0 {
+		nonDaemonExited.Wait()
+	}
+	threads.Unlock()
+}
+
+// exitNonDaemon 非守护线程结束，在Detach之后调用
+func exitNonDaemon() {
+	threads.Lock()
+	threads.nonDaemon--
+	if threads.nonDaemon == 0 {
+		nonDaemonExited.Broadcast()
+	}
+	threads.Unlock()
+}
+
+// liveThreads 返回所有和goroutine关联着的Java线程，按编号排列
+func liveThreads() []*Thread {
+	threads.Lock()
+	all := make([]*Thread, 0, len(threads.running))
+	for _, thread := range threads.running {
+		all = append(all, thread)
+	}
+	threads.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].id < all[j].id })
+	return all
+}
@@ -0,0 +1,43 @@
+package rtda
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWaitNonDaemonThreads 非守护线程在结束前启动的非守护线程也要等，守护线程不等
+func TestWaitNonDaemonThreads(t *testing.T) {
+	daemon := NewThread(DefaultMaxStackDepth)
+	stop := make(chan struct{})
+	defer close(stop)
+	daemon.Start(true, func() { <-stop })
+
+	var finished int32
+	first := NewThread(DefaultMaxStackDepth)
+	first.Start(false, func() {
+		time.Sleep(10 * time.Millisecond)
+		second := NewThread(DefaultMaxStackDepth)
+		second.Start(false, func() {
+			time.Sleep(10 * time.Millisecond)
+			atomic.StoreInt32(&finished, 1)
+		})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		main := NewThread(DefaultMaxStackDepth)
+		main.Attach()
+		WaitNonDaemonThreads(main)
+		main.Detach()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitNonDaemonThreads did not return")
+	}
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Error("returned before the second non-daemon thread finished")
+	}
+}
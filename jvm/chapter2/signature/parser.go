@@ -0,0 +1,284 @@
+package signature
+
+import "fmt"
+
+// 字段描述符、方法描述符和Signature属性共用一个递归下降解析器
+// descriptorMode为true时按描述符语法解析：不允许类型变量和类型实参，类名作为整体读取
+type parser struct {
+	input          string
+	pos            int
+	descriptorMode bool
+}
+
+type parseError struct {
+	input string
+	pos   int
+	msg   string
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("invalid signature %q at %d: %s", e.input, e.pos, e.msg)
+}
+
+func (p *parser) fail(format string, args ...interface{}) {
+	panic(&parseError{p.input, p.pos, fmt.Sprintf(format, args...)})
+}
+
+// 把解析中的panic转成error返回
+func (p *parser) run(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if pe, ok := r.(*parseError); ok {
+				err = pe
+				return
+			}
+			panic(r)
+		}
+	}()
+	fn()
+	if p.pos != len(p.input) {
+		p.fail("unexpected trailing characters")
+	}
+	return nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		p.fail("unexpected end of input")
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) next() byte {
+	b := p.peek()
+	p.pos++
+	return b
+}
+
+func (p *parser) expect(b byte) {
+	if c := p.next(); c != b {
+		p.pos--
+		p.fail("expected '%c', found '%c'", b, c)
+	}
+}
+
+// Identifier不能包含 . ; [ / < > :
+func (p *parser) identifier() string {
+	start := p.pos
+	for !p.eof() {
+		switch p.input[p.pos] {
+		case '.', ';', '[', '/', '<', '>', ':':
+			if p.pos == start {
+				p.fail("empty identifier")
+			}
+			return p.input[start:p.pos]
+		}
+		p.pos++
+	}
+	p.fail("unexpected end of input")
+	return ""
+}
+
+// JavaTypeSignature: ReferenceTypeSignature | BaseType
+func (p *parser) javaType() Type {
+	switch c := p.peek(); c {
+	case 'B', 'C', 'D', 'F', 'I', 'J', 'S', 'Z':
+		p.pos++
+		return &BaseType{c}
+	default:
+		return p.referenceType()
+	}
+}
+
+// ReferenceTypeSignature: ClassTypeSignature | TypeVariableSignature | ArrayTypeSignature
+func (p *parser) referenceType() Type {
+	switch c := p.peek(); c {
+	case 'L':
+		return p.classType()
+	case 'T':
+		if p.descriptorMode {
+			p.fail("type variable in descriptor")
+		}
+		return p.typeVariable()
+	case '[':
+		return p.arrayType()
+	default:
+		p.fail("unexpected '%c'", c)
+		return nil
+	}
+}
+
+func (p *parser) arrayType() Type {
+	dims := 0
+	for p.peek() == '[' {
+		p.pos++
+		dims++
+	}
+	if dims > 255 {
+		p.fail("array type has more than 255 dimensions")
+	}
+	var t Type = p.javaType()
+	for i := 0; i < dims; i++ {
+		t = &ArrayType{t}
+	}
+	return t
+}
+
+func (p *parser) typeVariable() *TypeVariable {
+	p.expect('T')
+	name := p.identifier()
+	p.expect(';')
+	return &TypeVariable{name}
+}
+
+// ClassTypeSignature: L [PackageSpecifier] SimpleClassTypeSignature {ClassTypeSignatureSuffix} ;
+func (p *parser) classType() *ClassType {
+	p.expect('L')
+	if p.descriptorMode {
+		return p.binaryClassName()
+	}
+
+	t := &ClassType{}
+	pkgStart := p.pos
+	name := p.identifier()
+	for p.peek() == '/' {
+		p.pos++
+		name = p.identifier()
+	}
+	t.Package = p.input[pkgStart : p.pos-len(name)]
+	t.Parts = append(t.Parts, p.simpleClassType(name))
+	for p.peek() == '.' {
+		p.pos++
+		t.Parts = append(t.Parts, p.simpleClassType(p.identifier()))
+	}
+	p.expect(';')
+	return t
+}
+
+func (p *parser) simpleClassType(name string) *SimpleClassType {
+	t := &SimpleClassType{Name: name}
+	if p.peek() == '<' {
+		t.TypeArgs = p.typeArguments()
+	}
+	return t
+}
+
+// 描述符里的类名是二进制名称，只按 / 切分包名
+func (p *parser) binaryClassName() *ClassType {
+	start := p.pos
+	lastSlash := -1
+	for {
+		switch p.next() {
+		case ';':
+			name := p.input[start : p.pos-1]
+			if name == "" || lastSlash == p.pos-2 {
+				p.fail("empty class name")
+			}
+			pkg, simple := "", name
+			if lastSlash >= 0 {
+				pkg, simple = p.input[start:lastSlash+1], p.input[lastSlash+1:p.pos-1]
+			}
+			return &ClassType{Package: pkg, Parts: []*SimpleClassType{{Name: simple}}}
+		case '/':
+			if p.pos-1 == start || lastSlash == p.pos-2 {
+				p.fail("empty package segment")
+			}
+			lastSlash = p.pos - 1
+		case '.', '[', '<', '>', ':':
+			p.pos--
+			p.fail("illegal character in class name")
+		}
+	}
+}
+
+// TypeArguments: < TypeArgument {TypeArgument} >
+func (p *parser) typeArguments() []*TypeArgument {
+	p.expect('<')
+	var args []*TypeArgument
+	for p.peek() != '>' {
+		switch c := p.peek(); c {
+		case '*':
+			p.pos++
+			args = append(args, &TypeArgument{Wildcard: WildcardAny})
+		case '+', '-':
+			p.pos++
+			args = append(args, &TypeArgument{Wildcard: c, Type: p.referenceType()})
+		default:
+			args = append(args, &TypeArgument{Type: p.referenceType()})
+		}
+	}
+	if len(args) == 0 {
+		p.fail("empty type argument list")
+	}
+	p.pos++
+	return args
+}
+
+// TypeParameters: < TypeParameter {TypeParameter} >
+func (p *parser) typeParameters() []*TypeParameter {
+	if p.eof() || p.peek() != '<' {
+		return nil
+	}
+	p.pos++
+	var params []*TypeParameter
+	for p.peek() != '>' {
+		param := &TypeParameter{Name: p.identifier()}
+		p.expect(':')
+		if c := p.peek(); c == 'L' || c == 'T' || c == '[' {
+			param.ClassBound = p.referenceType()
+		}
+		for p.peek() == ':' {
+			p.pos++
+			param.InterfaceBounds = append(param.InterfaceBounds, p.referenceType())
+		}
+		params = append(params, param)
+	}
+	if len(params) == 0 {
+		p.fail("empty type parameter list")
+	}
+	p.pos++
+	return params
+}
+
+// MethodSignature: [TypeParameters] ( {JavaTypeSignature} ) Result {ThrowsSignature}
+func (p *parser) methodSignature() *MethodSignature {
+	s := &MethodSignature{}
+	if !p.descriptorMode {
+		s.TypeParams = p.typeParameters()
+	}
+	p.expect('(')
+	for p.peek() != ')' {
+		s.Params = append(s.Params, p.javaType())
+	}
+	p.pos++
+	if p.peek() == 'V' {
+		p.pos++
+		s.Return = &BaseType{'V'}
+	} else {
+		s.Return = p.javaType()
+	}
+	for !p.descriptorMode && !p.eof() {
+		p.expect('^')
+		if p.peek() == 'T' {
+			s.Throws = append(s.Throws, p.typeVariable())
+		} else {
+			s.Throws = append(s.Throws, p.classType())
+		}
+	}
+	return s
+}
+
+// ClassSignature: [TypeParameters] SuperclassSignature {SuperinterfaceSignature}
+func (p *parser) classSignature() *ClassSignature {
+	s := &ClassSignature{}
+	s.TypeParams = p.typeParameters()
+	s.Super = p.classType()
+	for !p.eof() {
+		s.Interfaces = append(s.Interfaces, p.classType())
+	}
+	return s
+}
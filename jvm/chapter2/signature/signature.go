@@ -0,0 +1,50 @@
+package signature
+
+// ParseFieldDescriptor 解析字段描述符，例如 [Ljava/lang/String;
+func ParseFieldDescriptor(descriptor string) (Type, error) {
+	var t Type
+	p := &parser{input: descriptor, descriptorMode: true}
+	err := p.run(func() { t = p.javaType() })
+	return t, err
+}
+
+// ParseMethodDescriptor 解析方法描述符，例如 (IJ[Ljava/lang/String;)V
+func ParseMethodDescriptor(descriptor string) (*MethodSignature, error) {
+	var s *MethodSignature
+	p := &parser{input: descriptor, descriptorMode: true}
+	err := p.run(func() { s = p.methodSignature() })
+	return s, err
+}
+
+// ParseClassSignature 解析类的Signature属性
+func ParseClassSignature(signature string) (*ClassSignature, error) {
+	var s *ClassSignature
+	p := &parser{input: signature}
+	err := p.run(func() { s = p.classSignature() })
+	return s, err
+}
+
+// ParseMethodSignature 解析方法的Signature属性
+func ParseMethodSignature(signature string) (*MethodSignature, error) {
+	var s *MethodSignature
+	p := &parser{input: signature}
+	err := p.run(func() { s = p.methodSignature() })
+	return s, err
+}
+
+// ParseFieldSignature 解析字段（或局部变量）的Signature属性
+func ParseFieldSignature(signature string) (Type, error) {
+	var t Type
+	p := &parser{input: signature}
+	err := p.run(func() { t = p.referenceType() })
+	return t, err
+}
+
+// ArgSlotCount 计算方法描述符的参数slot数，不包括this
+func ArgSlotCount(descriptor string) (int, error) {
+	s, err := ParseMethodDescriptor(descriptor)
+	if err != nil {
+		return 0, err
+	}
+	return s.ArgSlotCount(), nil
+}
@@ -0,0 +1,203 @@
+package signature
+
+import "testing"
+
+func TestClassSignature(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      string // Format(name, false, Style{})
+		simple    string // Format(name, false, Style{Simple: true})
+	}{
+		{"Ljava/lang/Object;", "Foo", "Foo"},
+		{"<T:Ljava/lang/Object;>Ljava/lang/Object;Ljava/lang/Comparable<TT;>;",
+			"Foo<T> implements java.lang.Comparable<T>", "Foo<T> implements Comparable<T>"},
+		// 只有接口上界的类型形参，多个上界
+		{"<K::Ljava/lang/Comparable<-TK;>;V:Ljava/lang/Number;:Ljava/io/Serializable;>Ljava/util/AbstractMap<TK;TV;>;",
+			"Foo<K extends java.lang.Comparable<? super K>, V extends java.lang.Number & java.io.Serializable> extends java.util.AbstractMap<K, V>",
+			"Foo<K extends Comparable<? super K>, V extends Number & Serializable> extends AbstractMap<K, V>"},
+		// 嵌套的类型实参和内部类后缀
+		{"Ljava/util/HashMap<Ljava/lang/String;Ljava/util/List<+[Ljava/lang/Number;>;>.Node<*>;",
+			"Foo extends java.util.HashMap<java.lang.String, java.util.List<? extends java.lang.Number[]>>.Node<?>",
+			"Foo extends HashMap<String, List<? extends Number[]>>.Node<?>"},
+	}
+	for _, test := range tests {
+		s, err := ParseClassSignature(test.signature)
+		if err != nil {
+			t.Errorf("ParseClassSignature(%q): %v", test.signature, err)
+			continue
+		}
+		if got := s.Format("Foo", false, Style{}); got != test.want {
+			t.Errorf("ParseClassSignature(%q) = %q, want %q", test.signature, got, test.want)
+		}
+		if got := s.Format("Foo", false, Style{Simple: true}); got != test.simple {
+			t.Errorf("ParseClassSignature(%q) simple = %q, want %q", test.signature, got, test.simple)
+		}
+		if got := s.Signature(); got != test.signature {
+			t.Errorf("ParseClassSignature(%q).Signature() = %q", test.signature, got)
+		}
+	}
+}
+
+func TestClassSignatureShowObject(t *testing.T) {
+	s, err := ParseClassSignature("<T:Ljava/lang/Object;>Ljava/lang/Object;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Foo<T extends java.lang.Object> extends java.lang.Object"
+	if got := s.Format("Foo", false, Style{ShowObject: true}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	iface, err := ParseClassSignature("<E:Ljava/lang/Object;>Ljava/lang/Object;Ljava/util/Collection<TE;>;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := iface.Format("List", true, Style{}); got != "List<E> extends java.util.Collection<E>" {
+		t.Errorf("interface: got %q", got)
+	}
+}
+
+func TestMethodSignature(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      string // Format("m", Style{})
+	}{
+		{"()V", "void m()"},
+		{"(I[JLjava/lang/String;)[[D", "double[][] m(int, long[], java.lang.String)"},
+		{"<T::Ljava/lang/Comparable<-TT;>;>(Ljava/util/List<TT;>;)V",
+			"<T extends java.lang.Comparable<? super T>> void m(java.util.List<T>)"},
+		// throws子句可以是类型变量
+		{"<E:Ljava/lang/Exception;>(Ljava/util/Map$Entry<**>;)TE;^TE;^Ljava/io/IOException;",
+			"<E extends java.lang.Exception> E m(java.util.Map$Entry<?, ?>) throws E, java.io.IOException"},
+		{"(Ljava/util/Map<Ljava/lang/String;Ljava/util/Map$Entry<TK;TV;>;>.Inner;)Z",
+			"boolean m(java.util.Map<java.lang.String, java.util.Map$Entry<K, V>>.Inner)"},
+	}
+	for _, test := range tests {
+		s, err := ParseMethodSignature(test.signature)
+		if err != nil {
+			t.Errorf("ParseMethodSignature(%q): %v", test.signature, err)
+			continue
+		}
+		if got := s.Format("m", Style{}); got != test.want {
+			t.Errorf("ParseMethodSignature(%q) = %q, want %q", test.signature, got, test.want)
+		}
+		if got := s.Signature(); got != test.signature {
+			t.Errorf("ParseMethodSignature(%q).Signature() = %q", test.signature, got)
+		}
+	}
+}
+
+func TestFieldSignature(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      string
+		simple    string
+	}{
+		{"TT;", "T", "T"},
+		{"[TT;", "T[]", "T[]"},
+		{"Ljava/util/List<*>;", "java.util.List<?>", "List<?>"},
+		{"Ljava/util/Map<-Ljava/lang/Integer;[[I>;", "java.util.Map<? super java.lang.Integer, int[][]>", "Map<? super Integer, int[][]>"},
+		{"LOuter<TT;>.Inner<Ljava/lang/String;>.Deepest;", "Outer<T>.Inner<java.lang.String>.Deepest", "Outer<T>.Inner<String>.Deepest"},
+	}
+	for _, test := range tests {
+		typ, err := ParseFieldSignature(test.signature)
+		if err != nil {
+			t.Errorf("ParseFieldSignature(%q): %v", test.signature, err)
+			continue
+		}
+		if got := typ.String(); got != test.want {
+			t.Errorf("ParseFieldSignature(%q) = %q, want %q", test.signature, got, test.want)
+		}
+		if got := SimpleString(typ); got != test.simple {
+			t.Errorf("ParseFieldSignature(%q) simple = %q, want %q", test.signature, got, test.simple)
+		}
+		if got := typ.Signature(); got != test.signature {
+			t.Errorf("ParseFieldSignature(%q).Signature() = %q", test.signature, got)
+		}
+	}
+}
+
+func TestInnerClassParts(t *testing.T) {
+	typ, err := ParseFieldSignature("Ljava/util/Map<TK;TV;>.Entry<TK;TV;>;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := typ.(*ClassType)
+	if ct.Package != "java/util/" || len(ct.Parts) != 2 || ct.InternalName() != "java/util/Map$Entry" {
+		t.Errorf("got package %q, %d parts, internal name %q", ct.Package, len(ct.Parts), ct.InternalName())
+	}
+}
+
+func TestDescriptors(t *testing.T) {
+	md, err := ParseMethodDescriptor("(IDLjava/lang/Thread;[J)Ljava/lang/Object;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := md.ArgSlotCount(); got != 5 {
+		t.Errorf("ArgSlotCount = %d, want 5", got)
+	}
+	if got := md.String(); got != "java.lang.Object (int, double, java.lang.Thread, long[])" {
+		t.Errorf("String = %q", got)
+	}
+	// 描述符里的$是类名的一部分，不是内部类后缀
+	fd, err := ParseFieldDescriptor("[Ljava/util/Map$Entry;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fd.(*ArrayType).Element().(*ClassType).InternalName(); got != "java/util/Map$Entry" {
+		t.Errorf("element = %q", got)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	parsers := map[string]func(string) error{
+		"field descriptor":  func(s string) error { _, err := ParseFieldDescriptor(s); return err },
+		"method descriptor": func(s string) error { _, err := ParseMethodDescriptor(s); return err },
+		"class signature":   func(s string) error { _, err := ParseClassSignature(s); return err },
+		"method signature":  func(s string) error { _, err := ParseMethodSignature(s); return err },
+		"field signature":   func(s string) error { _, err := ParseFieldSignature(s); return err },
+	}
+	tests := []struct {
+		parser string
+		input  string
+	}{
+		{"field descriptor", ""},
+		{"field descriptor", "V"},
+		{"field descriptor", "Q"},
+		{"field descriptor", "Ljava/lang/String"},
+		{"field descriptor", "L;"},
+		{"field descriptor", "Ljava//String;"},
+		{"field descriptor", "Ljava/util/List<TT;>;"},
+		{"field descriptor", "TT;"},
+		{"field descriptor", "II"},
+		{"field descriptor", "[V"},
+		{"method descriptor", "(I"},
+		{"method descriptor", "I)V"},
+		{"method descriptor", "()"},
+		{"method descriptor", "(V)V"},
+		{"method descriptor", "()V^Ljava/lang/Exception;"},
+		{"class signature", ""},
+		{"class signature", "<>Ljava/lang/Object;"},
+		{"class signature", "<T>Ljava/lang/Object;"},
+		{"class signature", "Ljava/util/List<>;"},
+		{"class signature", "I"},
+		{"method signature", "<T:>()V^"},
+		{"method signature", "()V^I"},
+		{"method signature", "(Ljava/util/List<+>;)V"},
+		{"field signature", "I"},
+		{"field signature", "TT"},
+		{"field signature", "Ljava/util/Map<TK;TV;>.;"},
+		{"field signature", "Ljava/util/List<TT;>"},
+	}
+	for _, test := range tests {
+		if err := parsers[test.parser](test.input); err == nil {
+			t.Errorf("%s %q: expected an error", test.parser, test.input)
+		}
+	}
+	arrayDims := make([]byte, 256)
+	for i := range arrayDims {
+		arrayDims[i] = '['
+	}
+	if _, err := ParseFieldDescriptor(string(arrayDims) + "I"); err == nil {
+		t.Error("256 array dimensions: expected an error")
+	}
+}
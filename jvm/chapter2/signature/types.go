@@ -0,0 +1,414 @@
+package signature
+
+import "strings"
+
+// Type 是描述符和泛型签名解析后得到的类型节点
+type Type interface {
+	// Signature 把类型重新编码成描述符/签名形式
+	Signature() string
+	// String 按javap的习惯打印成全限定的Java源码形式
+	String() string
+	javaName(style Style) string
+}
+
+// Style 控制打印成Java源码形式时的细节
+type Style struct {
+	Simple     bool // 省略包名
+	ShowObject bool // 保留 extends java.lang.Object，javap -v 就是这样打印的
+}
+
+// SimpleString 打印成不带包名的Java源码形式，例如 Map<String, ? extends List<T>>
+func SimpleString(t Type) string {
+	return t.javaName(Style{Simple: true})
+}
+
+// Format 按指定的Style打印类型
+func Format(t Type, style Style) string {
+	return t.javaName(style)
+}
+
+// BaseType 基本类型和void
+type BaseType struct {
+	Tag byte // B C D F I J S Z V
+}
+
+var baseTypeNames = map[byte]string{
+	'B': "byte",
+	'C': "char",
+	'D': "double",
+	'F': "float",
+	'I': "int",
+	'J': "long",
+	'S': "short",
+	'Z': "boolean",
+	'V': "void",
+}
+
+func (baseType *BaseType) Signature() string           { return string(baseType.Tag) }
+func (baseType *BaseType) String() string              { return baseType.javaName(Style{}) }
+func (baseType *BaseType) javaName(style Style) string { return baseTypeNames[baseType.Tag] }
+
+// IsVoid 返回类型是否为void
+func (baseType *BaseType) IsVoid() bool { return baseType.Tag == 'V' }
+
+// IsWide 返回是否为占两个slot的long或double
+func (baseType *BaseType) IsWide() bool { return baseType.Tag == 'J' || baseType.Tag == 'D' }
+
+// SimpleClassType 类类型中的一段，外部类和每一层内部类各一段
+type SimpleClassType struct {
+	Name     string
+	TypeArgs []*TypeArgument
+}
+
+func (simpleClassType *SimpleClassType) signature() string {
+	if len(simpleClassType.TypeArgs) == 0 {
+		return simpleClassType.Name
+	}
+	var sb strings.Builder
+	sb.WriteString(simpleClassType.Name)
+	sb.WriteByte('<')
+	for _, arg := range simpleClassType.TypeArgs {
+		sb.WriteString(arg.Signature())
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+func (simpleClassType *SimpleClassType) javaName(style Style) string {
+	if len(simpleClassType.TypeArgs) == 0 {
+		return simpleClassType.Name
+	}
+	args := make([]string, len(simpleClassType.TypeArgs))
+	for i, arg := range simpleClassType.TypeArgs {
+		args[i] = arg.javaName(style)
+	}
+	return simpleClassType.Name + "<" + strings.Join(args, ", ") + ">"
+}
+
+// ClassType 类或接口类型，Package形如"java/util/"，默认包为空串
+type ClassType struct {
+	Package string
+	Parts   []*SimpleClassType
+}
+
+// InternalName 返回二进制名称，例如 java/util/Map$Entry
+func (classType *ClassType) InternalName() string {
+	names := make([]string, len(classType.Parts))
+	for i, part := range classType.Parts {
+		names[i] = part.Name
+	}
+	return classType.Package + strings.Join(names, "$")
+}
+
+func (classType *ClassType) Signature() string {
+	parts := make([]string, len(classType.Parts))
+	for i, part := range classType.Parts {
+		parts[i] = part.signature()
+	}
+	return "L" + classType.Package + strings.Join(parts, ".") + ";"
+}
+
+func (classType *ClassType) String() string { return classType.javaName(Style{}) }
+
+func (classType *ClassType) javaName(style Style) string {
+	parts := make([]string, len(classType.Parts))
+	for i, part := range classType.Parts {
+		parts[i] = part.javaName(style)
+	}
+	name := strings.Join(parts, ".")
+	if style.Simple {
+		return name
+	}
+	return strings.Replace(classType.Package, "/", ".", -1) + name
+}
+
+// TypeVariable 类型变量，例如 T
+type TypeVariable struct {
+	Name string
+}
+
+func (typeVariable *TypeVariable) Signature() string           { return "T" + typeVariable.Name + ";" }
+func (typeVariable *TypeVariable) String() string              { return typeVariable.Name }
+func (typeVariable *TypeVariable) javaName(style Style) string { return typeVariable.Name }
+
+// ArrayType 数组类型
+type ArrayType struct {
+	Component Type
+}
+
+// Dimensions 返回数组维数
+func (arrayType *ArrayType) Dimensions() int {
+	dims := 1
+	for c := arrayType.Component; ; dims++ {
+		arr, ok := c.(*ArrayType)
+		if !ok {
+			return dims
+		}
+		c = arr.Component
+	}
+}
+
+// Element 返回去掉所有维度后的元素类型
+func (arrayType *ArrayType) Element() Type {
+	c := arrayType.Component
+	for {
+		arr, ok := c.(*ArrayType)
+		if !ok {
+			return c
+		}
+		c = arr.Component
+	}
+}
+
+func (arrayType *ArrayType) Signature() string { return "[" + arrayType.Component.Signature() }
+func (arrayType *ArrayType) String() string    { return arrayType.javaName(Style{}) }
+func (arrayType *ArrayType) javaName(style Style) string {
+	return arrayType.Component.javaName(style) + "[]"
+}
+
+// 通配符
+const (
+	WildcardNone    byte = 0
+	WildcardExtends byte = '+'
+	WildcardSuper   byte = '-'
+	WildcardAny     byte = '*'
+)
+
+// TypeArgument 类型实参，Wildcard为WildcardAny时Type为nil
+type TypeArgument struct {
+	Wildcard byte
+	Type     Type
+}
+
+func (typeArgument *TypeArgument) Signature() string {
+	switch typeArgument.Wildcard {
+	case WildcardAny:
+		return "*"
+	case WildcardNone:
+		return typeArgument.Type.Signature()
+	default:
+		return string(typeArgument.Wildcard) + typeArgument.Type.Signature()
+	}
+}
+
+func (typeArgument *TypeArgument) String() string { return typeArgument.javaName(Style{}) }
+
+func (typeArgument *TypeArgument) javaName(style Style) string {
+	switch typeArgument.Wildcard {
+	case WildcardAny:
+		return "?"
+	case WildcardExtends:
+		return "? extends " + typeArgument.Type.javaName(style)
+	case WildcardSuper:
+		return "? super " + typeArgument.Type.javaName(style)
+	default:
+		return typeArgument.Type.javaName(style)
+	}
+}
+
+// TypeParameter 类型形参，ClassBound可以为nil（只有接口上界时）
+type TypeParameter struct {
+	Name            string
+	ClassBound      Type
+	InterfaceBounds []Type
+}
+
+func (typeParameter *TypeParameter) Signature() string {
+	var sb strings.Builder
+	sb.WriteString(typeParameter.Name)
+	sb.WriteByte(':')
+	if typeParameter.ClassBound != nil {
+		sb.WriteString(typeParameter.ClassBound.Signature())
+	}
+	for _, bound := range typeParameter.InterfaceBounds {
+		sb.WriteByte(':')
+		sb.WriteString(bound.Signature())
+	}
+	return sb.String()
+}
+
+func (typeParameter *TypeParameter) String() string { return typeParameter.javaName(Style{}) }
+
+// 和javap一样，默认省略 extends java.lang.Object
+func (typeParameter *TypeParameter) javaName(style Style) string {
+	var bounds []string
+	if typeParameter.ClassBound != nil {
+		if ct, ok := typeParameter.ClassBound.(*ClassType); !ok || !isPlainObject(ct) || style.ShowObject {
+			bounds = append(bounds, typeParameter.ClassBound.javaName(style))
+		}
+	}
+	for _, bound := range typeParameter.InterfaceBounds {
+		bounds = append(bounds, bound.javaName(style))
+	}
+	if len(bounds) == 0 {
+		return typeParameter.Name
+	}
+	return typeParameter.Name + " extends " + strings.Join(bounds, " & ")
+}
+
+func typeParamsSignature(params []*TypeParameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('<')
+	for _, p := range params {
+		sb.WriteString(p.Signature())
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+func typeParamsJavaName(params []*TypeParameter, style Style) string {
+	if len(params) == 0 {
+		return ""
+	}
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.javaName(style)
+	}
+	return "<" + strings.Join(names, ", ") + ">"
+}
+
+func typesJavaName(types []Type, style Style, sep string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.javaName(style)
+	}
+	return strings.Join(names, sep)
+}
+
+// ClassSignature Signature属性中的类签名
+type ClassSignature struct {
+	TypeParams []*TypeParameter
+	Super      *ClassType
+	Interfaces []*ClassType
+}
+
+func (classSignature *ClassSignature) Signature() string {
+	var sb strings.Builder
+	sb.WriteString(typeParamsSignature(classSignature.TypeParams))
+	sb.WriteString(classSignature.Super.Signature())
+	for _, iface := range classSignature.Interfaces {
+		sb.WriteString(iface.Signature())
+	}
+	return sb.String()
+}
+
+// TypeParamsString 打印类型形参列表，例如 <K, V extends java.lang.Comparable<V>>
+func (classSignature *ClassSignature) TypeParamsString(style Style) string {
+	return typeParamsJavaName(classSignature.TypeParams, style)
+}
+
+// Format 打印成类声明的形式，例如 Foo<T> extends Bar<T> implements Baz
+func (classSignature *ClassSignature) Format(name string, isInterface bool, style Style) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteString(typeParamsJavaName(classSignature.TypeParams, style))
+	if isInterface {
+		if len(classSignature.Interfaces) > 0 {
+			sb.WriteString(" extends ")
+			sb.WriteString(classTypesJavaName(classSignature.Interfaces, style))
+		}
+		return sb.String()
+	}
+	if classSignature.Super != nil && (!isPlainObject(classSignature.Super) || style.ShowObject) {
+		sb.WriteString(" extends ")
+		sb.WriteString(classSignature.Super.javaName(style))
+	}
+	if len(classSignature.Interfaces) > 0 {
+		sb.WriteString(" implements ")
+		sb.WriteString(classTypesJavaName(classSignature.Interfaces, style))
+	}
+	return sb.String()
+}
+
+func (classSignature *ClassSignature) String() string {
+	return classSignature.Format("", false, Style{})
+}
+
+func classTypesJavaName(types []*ClassType, style Style) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.javaName(style)
+	}
+	return strings.Join(names, ", ")
+}
+
+func isPlainObject(t *ClassType) bool {
+	return len(t.Parts) == 1 && len(t.Parts[0].TypeArgs) == 0 && t.InternalName() == "java/lang/Object"
+}
+
+// MethodSignature 方法签名；方法描述符解析后也用它表示，此时没有类型形参和throws
+type MethodSignature struct {
+	TypeParams []*TypeParameter
+	Params     []Type
+	Return     Type
+	Throws     []Type
+}
+
+func (methodSignature *MethodSignature) Signature() string {
+	var sb strings.Builder
+	sb.WriteString(typeParamsSignature(methodSignature.TypeParams))
+	sb.WriteByte('(')
+	for _, p := range methodSignature.Params {
+		sb.WriteString(p.Signature())
+	}
+	sb.WriteByte(')')
+	sb.WriteString(methodSignature.Return.Signature())
+	for _, t := range methodSignature.Throws {
+		sb.WriteByte('^')
+		sb.WriteString(t.Signature())
+	}
+	return sb.String()
+}
+
+// TypeParamsString 打印类型形参列表
+func (methodSignature *MethodSignature) TypeParamsString(style Style) string {
+	return typeParamsJavaName(methodSignature.TypeParams, style)
+}
+
+// ParamsString 打印参数列表，例如 (int, java.lang.String[])
+func (methodSignature *MethodSignature) ParamsString(style Style) string {
+	return "(" + typesJavaName(methodSignature.Params, style, ", ") + ")"
+}
+
+// ThrowsString 打印throws子句，没有时返回空串
+func (methodSignature *MethodSignature) ThrowsString(style Style) string {
+	if len(methodSignature.Throws) == 0 {
+		return ""
+	}
+	return " throws " + typesJavaName(methodSignature.Throws, style, ", ")
+}
+
+// Format 打印成方法声明的形式，例如 <T> void sort(java.util.List<T>) throws E
+func (methodSignature *MethodSignature) Format(name string, style Style) string {
+	var sb strings.Builder
+	if len(methodSignature.TypeParams) > 0 {
+		sb.WriteString(typeParamsJavaName(methodSignature.TypeParams, style))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(methodSignature.Return.javaName(style))
+	sb.WriteByte(' ')
+	sb.WriteString(name)
+	sb.WriteString(methodSignature.ParamsString(style))
+	sb.WriteString(methodSignature.ThrowsString(style))
+	return sb.String()
+}
+
+func (methodSignature *MethodSignature) String() string {
+	return methodSignature.Format("", Style{})
+}
+
+// ArgSlotCount 返回参数占用的局部变量表slot数，不包括this
+func (methodSignature *MethodSignature) ArgSlotCount() int {
+	count := 0
+	for _, p := range methodSignature.Params {
+		if bt, ok := p.(*BaseType); ok && bt.IsWide() {
+			count += 2
+		} else {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// TestStackOverflow 深度递归在栈满时抛出StackOverflowError：Recurse捕获它两次，Overflow不捕获
+func TestStackOverflow(t *testing.T) {
+	cp := testClasspath(t, filepath.Join("testdata", "stack"))
+
+	for _, maxDepth := range []uint{16, rtda.DefaultMaxStackDepth, 20000} {
+		loader := heap.NewClassLoaders(cp, nil, nil)
+		if exitCode := interpret(loader, "Recurse", nil, maxDepth); exitCode != 0 {
+			t.Fatalf("-XmaxDepth %d: exit code %d", maxDepth, exitCode)
+		}
+		// 栈里除了down()还有main的帧
+		if depth := staticInt(loader.LoadClass(rtda.NewThread(maxDepth), "Recurse"), "depth"); depth != int32(maxDepth)-1 {
+			t.Errorf("-XmaxDepth %d: recursed %d times, want %d", maxDepth, depth, maxDepth-1)
+		}
+	}
+	if exitCode := interpret(heap.NewClassLoaders(cp, nil, nil), "Overflow", nil, rtda.DefaultMaxStackDepth); exitCode != 1 {
+		t.Errorf("uncaught StackOverflowError: exit code %d, want 1", exitCode)
+	}
+}
+
+func staticInt(class *heap.Class, name string) int32 {
+	for _, field := range class.Fields() {
+		if field.IsStatic() && field.Name() == name {
+			return class.StaticVars().GetInt(field.SlotId())
+		}
+	}
+	panic("no static field " + name)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// TestThreads testdata/thread下的程序检查Thread的sleep、interrupt、join和守护线程。
+// 程序返回时还有一个守护线程在wait，虚拟机不等它，interpret要在限定时间内返回
+func TestThreads(t *testing.T) {
+	loader := heap.NewClassLoaders(testClasspath(t, filepath.Join("testdata", "thread")), nil, verifyClass)
+	done := make(chan int, 1)
+	go func() {
+		done <- interpret(loader, "Threads", nil, rtda.DefaultMaxStackDepth)
+	}()
+	select {
+	case exitCode := <-done:
+		if exitCode != 0 {
+			t.Fatalf("exit code %d", exitCode)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("the VM did not exit: waiting for a daemon thread or a missed interrupt")
+	}
+}
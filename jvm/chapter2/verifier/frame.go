@@ -0,0 +1,162 @@
+package verifier
+
+// frame 一条指令入口的类型状态，对应JVMS 4.10.1.3的frame(Locals, OperandStack, Flags)
+type frame struct {
+	locals     []vtype // 长度是max_locals，没有值的是Top
+	stack      []vtype // long和double占两项，第二项是Top
+	thisUninit bool    // flagThisUninit：构造函数还没有调用超类或本类的构造函数
+}
+
+func newFrame(maxLocals int) *frame {
+	locals := make([]vtype, maxLocals)
+	for i := range locals {
+		locals[i] = topType
+	}
+	return &frame{locals: locals}
+}
+
+func (f *frame) copy() *frame {
+	return &frame{
+		locals:     append([]vtype(nil), f.locals...),
+		stack:      append([]vtype(nil), f.stack...),
+		thisUninit: f.thisUninit,
+	}
+}
+
+// push 压入一个值，long和double占两个slot
+func (checker *methodChecker) push(t vtype) {
+	for _, slot := range t.slots() {
+		if len(checker.frame.stack) >= checker.maxStack {
+			checker.fail("Exceeded max stack size")
+		}
+		checker.frame.stack = append(checker.frame.stack, slot)
+	}
+}
+
+// pop 弹出一个能赋给t的值，返回它的实际类型
+func (checker *methodChecker) pop(t vtype) vtype {
+	stack := checker.frame.stack
+	n := len(stack)
+	if t.isWide() {
+		if n < 2 {
+			checker.fail("Attempt to pop empty stack")
+		}
+		if stack[n-1] != topType || stack[n-2] != t {
+			checker.fail("Bad type on operand stack: type %s (current frame, stack[%d]) is not assignable to %s",
+				stack[n-2], n-2, t)
+		}
+		checker.frame.stack = stack[:n-2]
+		return t
+	}
+	if n < 1 {
+		checker.fail("Attempt to pop empty stack")
+	}
+	actual := stack[n-1]
+	if actual == topType || !checker.isAssignable(actual, t) {
+		checker.fail("Bad type on operand stack: type %s (current frame, stack[%d]) is not assignable to %s", actual, n-1, t)
+	}
+	checker.frame.stack = stack[:n-1]
+	return actual
+}
+
+// popReference 弹出任意引用，包括null和未初始化的对象
+func (checker *methodChecker) popReference() vtype {
+	stack := checker.frame.stack
+	n := len(stack)
+	if n < 1 {
+		checker.fail("Attempt to pop empty stack")
+	}
+	actual := stack[n-1]
+	if !actual.isReference() {
+		checker.fail("Bad type on operand stack: type %s (current frame, stack[%d]) is not a reference", actual, n-1)
+	}
+	checker.frame.stack = stack[:n-1]
+	return actual
+}
+
+// popArray 弹出数组或者null，elementOK判断元素的描述符是否符合要求
+func (checker *methodChecker) popArray(elementOK func(element string) bool, what string) vtype {
+	stack := checker.frame.stack
+	n := len(stack)
+	if n < 1 {
+		checker.fail("Attempt to pop empty stack")
+	}
+	actual := stack[n-1]
+	if actual.tag != nullType.tag && !(actual.isArray() && elementOK(actual.class[1:])) {
+		checker.fail("Bad type on operand stack: type %s (current frame, stack[%d]) is not %s", actual, n-1, what)
+	}
+	checker.frame.stack = stack[:n-1]
+	return actual
+}
+
+/*
+popSlots 按组弹出栈顶的slot，sizes从栈顶开始是每组的slot数。pop、dup、swap这些指令不关心类型，
+但是不能把long或double拆开，也就是每组的第一个slot不能是long或double的后一半
+*/
+func (checker *methodChecker) popSlots(sizes ...int) [][]vtype {
+	stack := checker.frame.stack
+	groups := make([][]vtype, len(sizes))
+	end := len(stack)
+	for i, size := range sizes {
+		start := end - size
+		if start < 0 {
+			checker.fail("Attempt to pop empty stack")
+		}
+		if stack[start] == topType {
+			checker.fail("Bad type on operand stack: stack[%d] is the second half of a long or double", start)
+		}
+		groups[i] = append([]vtype(nil), stack[start:end]...)
+		end = start
+	}
+	checker.frame.stack = stack[:end]
+	return groups
+}
+
+// pushSlots 按顺序压入几组slot
+func (checker *methodChecker) pushSlots(groups ...[]vtype) {
+	for _, group := range groups {
+		for _, slot := range group {
+			if len(checker.frame.stack) >= checker.maxStack {
+				checker.fail("Exceeded max stack size")
+			}
+			checker.frame.stack = append(checker.frame.stack, slot)
+		}
+	}
+}
+
+func (checker *methodChecker) checkLocalIndex(index int, t vtype) {
+	size := len(t.slots())
+	if index < 0 || index+size > checker.maxLocals {
+		checker.fail("Illegal local variable number %d", index)
+	}
+}
+
+// load 取出能赋给t的局部变量，返回它的实际类型
+func (checker *methodChecker) load(index int, t vtype) vtype {
+	checker.checkLocalIndex(index, t)
+	actual := checker.frame.locals[index]
+	if t.isWide() && checker.frame.locals[index+1] != topType || !checker.isAssignable(actual, t) {
+		checker.fail("Bad local variable type: type %s (current frame, locals[%d]) is not assignable to %s", actual, index, t)
+	}
+	return actual
+}
+
+// loadReference aload可以取出任意引用，包括null和未初始化的对象，但是不能取出返回地址
+func (checker *methodChecker) loadReference(index int) vtype {
+	checker.checkLocalIndex(index, topType)
+	actual := checker.frame.locals[index]
+	if !actual.isReference() {
+		checker.fail("Bad local variable type: type %s (current frame, locals[%d]) is not a reference", actual, index)
+	}
+	return actual
+}
+
+// store 把t存到局部变量里，覆盖了long或double的一半时另一半也不能再用
+func (checker *methodChecker) store(index int, t vtype) {
+	checker.checkLocalIndex(index, t)
+	locals := checker.frame.locals
+	if index > 0 && locals[index-1].isWide() {
+		locals[index-1] = topType
+	}
+	copy(locals[index:], t.slots())
+}
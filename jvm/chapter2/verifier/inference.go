@@ -0,0 +1,93 @@
+package verifier
+
+/*
+infer 按JVMS 4.10.2做数据流分析：从方法入口开始执行，每条指令的入口帧是所有能到达它的帧合并的结果，
+入口帧有变化的指令重新执行，直到不再变化。合并时栈的高度必须相同，栈上的类型必须兼容，
+局部变量不兼容时变成Top，之后不能再读
+*/
+func (checker *methodChecker) infer(initial *frame) {
+	checker.frames = make([]*frame, len(checker.instrs))
+	checker.queued = make([]bool, len(checker.instrs))
+	checker.subroutines = map[int]*subroutine{}
+	checker.mergeInto(0, initial)
+	for len(checker.worklist) > 0 {
+		i := checker.worklist[len(checker.worklist)-1]
+		checker.worklist = checker.worklist[:len(checker.worklist)-1]
+		checker.queued[i] = false
+
+		instr := checker.instrs[i]
+		checker.pc = instr.PC
+		checker.frame = checker.frames[i].copy()
+		checker.mergeHandlers(checker.frame)
+		targets, fallThrough := checker.execute(instr)
+		if isStore(instr.Opcode) {
+			checker.mergeHandlers(checker.frame)
+		}
+		for _, target := range targets {
+			checker.mergeInto(checker.index[target], checker.frame)
+		}
+		if fallThrough {
+			checker.mergeNext(i, checker.frame)
+		}
+		checker.followSubroutine(i)
+	}
+}
+
+// mergeNext 合并到下一条指令
+func (checker *methodChecker) mergeNext(i int, f *frame) {
+	if i+1 >= len(checker.instrs) {
+		checker.fail("Falling off the end of the code")
+	}
+	checker.mergeInto(i+1, f)
+}
+
+// mergeHandlers 合并到覆盖当前指令的异常处理代码：局部变量和当前一样，栈上只有异常对象
+func (checker *methodChecker) mergeHandlers(f *frame) {
+	for _, h := range checker.handlers {
+		if checker.pc >= h.start && checker.pc < h.end {
+			exceptionFrame := &frame{locals: f.locals, stack: []vtype{h.catchType}, thisUninit: f.thisUninit}
+			checker.mergeInto(checker.index[h.target], exceptionFrame)
+		}
+	}
+}
+
+// mergeInto 把f合并到第i条指令的入口帧，有变化时重新执行这条指令
+func (checker *methodChecker) mergeInto(i int, f *frame) {
+	old := checker.frames[i]
+	if old == nil {
+		checker.frames[i] = f.copy()
+		checker.enqueue(i)
+		return
+	}
+	if len(old.stack) != len(f.stack) {
+		checker.fail("Inconsistent stack height %d != %d at %d", len(old.stack), len(f.stack), checker.instrs[i].PC)
+	}
+	changed := false
+	for j, t := range f.stack {
+		merged := checker.mergeTypes(old.stack[j], t)
+		if merged == topType && (old.stack[j] != topType || t != topType) {
+			checker.fail("Mismatched stack types at %d: %s and %s", checker.instrs[i].PC, old.stack[j], t)
+		}
+		if merged != old.stack[j] {
+			old.stack[j], changed = merged, true
+		}
+	}
+	for j, t := range f.locals {
+		if merged := checker.mergeTypes(old.locals[j], t); merged != old.locals[j] {
+			old.locals[j], changed = merged, true
+		}
+	}
+	if f.thisUninit && !old.thisUninit {
+		old.thisUninit, changed = true, true
+	}
+	if changed {
+		checker.enqueue(i)
+	}
+}
+
+func (checker *methodChecker) enqueue(i int) {
+	if !checker.queued[i] {
+		checker.queued[i] = true
+		checker.worklist = append(checker.worklist, i)
+	}
+}
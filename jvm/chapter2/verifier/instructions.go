@@ -0,0 +1,630 @@
+package verifier
+
+import (
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// 按opcode排列的类型：I J F D A
+var loadStoreTypes = []vtype{intType, longType, floatType, doubleType, objectType(objectClass)}
+
+// 数组load和store指令的元素描述符：I J F D A B C S，引用类型的数组是空串
+var arrayElements = []string{"I", "J", "F", "D", "", "B", "C", "S"}
+
+var conversions = map[uint8][2]vtype{
+	opcodes.I2l: {intType, longType},
+	opcodes.I2f: {intType, floatType},
+	opcodes.I2d: {intType, doubleType},
+	opcodes.L2i: {longType, intType},
+	opcodes.L2f: {longType, floatType},
+	opcodes.L2d: {longType, doubleType},
+	opcodes.F2i: {floatType, intType},
+	opcodes.F2l: {floatType, longType},
+	opcodes.F2d: {floatType, doubleType},
+	opcodes.D2i: {doubleType, intType},
+	opcodes.D2l: {doubleType, longType},
+	opcodes.D2f: {doubleType, floatType},
+	opcodes.I2b: {intType, intType},
+	opcodes.I2c: {intType, intType},
+	opcodes.I2s: {intType, intType},
+}
+
+var newarrayDescriptors = map[int]string{
+	opcodes.TBoolean: "Z",
+	opcodes.TChar:    "C",
+	opcodes.TFloat:   "F",
+	opcodes.TDouble:  "D",
+	opcodes.TByte:    "B",
+	opcodes.TShort:   "S",
+	opcodes.TInt:     "I",
+	opcodes.TLong:    "J",
+}
+
+/*
+execute 按JVMS 4.10.1.9对checker.frame执行一条指令：弹出的操作数必须是指令要求的类型，
+再压入结果。返回跳转目标（已经检查过是指令的开头）和能否执行到下一条指令
+*/
+func (checker *methodChecker) execute(instr *opcodes.Instruction) (targets []int, fallThrough bool) {
+	op := instr.Opcode
+	fallThrough = true
+	switch {
+	case op == opcodes.Nop:
+	case op == opcodes.AconstNull:
+		checker.push(nullType)
+	case op >= opcodes.IconstM1 && op <= opcodes.Iconst5, op == opcodes.Bipush, op == opcodes.Sipush:
+		checker.push(intType)
+	case op == opcodes.Lconst0 || op == opcodes.Lconst1:
+		checker.push(longType)
+	case op >= opcodes.Fconst0 && op <= opcodes.Fconst2:
+		checker.push(floatType)
+	case op == opcodes.Dconst0 || op == opcodes.Dconst1:
+		checker.push(doubleType)
+	case op == opcodes.Ldc || op == opcodes.LdcW || op == opcodes.Ldc2W:
+		checker.ldc(instr)
+	case op == opcodes.Aload:
+		checker.push(checker.loadReference(instr.Index))
+	case op >= opcodes.Iload && op <= opcodes.Dload:
+		checker.push(checker.load(instr.Index, loadStoreTypes[op-opcodes.Iload]))
+	case op >= opcodes.Aload0 && op <= opcodes.Aload3:
+		checker.push(checker.loadReference(int(op - opcodes.Aload0)))
+	case op >= opcodes.Iload0 && op <= opcodes.Dload3:
+		n := int(op - opcodes.Iload0)
+		checker.push(checker.load(n%4, loadStoreTypes[n/4]))
+	case op >= opcodes.Iaload && op <= opcodes.Saload:
+		checker.arrayLoad(op)
+	case op == opcodes.Astore:
+		checker.astore(instr.Index)
+	case op >= opcodes.Istore && op <= opcodes.Dstore:
+		t := loadStoreTypes[op-opcodes.Istore]
+		checker.store(instr.Index, checker.pop(t))
+	case op >= opcodes.Astore0 && op <= opcodes.Astore3:
+		checker.astore(int(op - opcodes.Astore0))
+	case op >= opcodes.Istore0 && op <= opcodes.Dstore3:
+		n := int(op - opcodes.Istore0)
+		checker.store(n%4, checker.pop(loadStoreTypes[n/4]))
+	case op >= opcodes.Iastore && op <= opcodes.Sastore:
+		checker.arrayStore(op)
+	case op >= opcodes.Pop && op <= opcodes.Swap:
+		checker.stackOp(op)
+	case op >= opcodes.Iadd && op <= opcodes.Drem:
+		t := loadStoreTypes[(op-opcodes.Iadd)%4]
+		checker.pop(t)
+		checker.pop(t)
+		checker.push(t)
+	case op >= opcodes.Ineg && op <= opcodes.Dneg:
+		t := loadStoreTypes[(op-opcodes.Ineg)%4]
+		checker.pop(t)
+		checker.push(t)
+	case op >= opcodes.Ishl && op <= opcodes.Lxor:
+		t := loadStoreTypes[(op-opcodes.Ishl)%2]
+		if op <= opcodes.Lushr {
+			checker.pop(intType) // 移位的位数
+		} else {
+			checker.pop(t)
+		}
+		checker.pop(t)
+		checker.push(t)
+	case op == opcodes.Iinc:
+		checker.load(instr.Index, intType)
+	case op >= opcodes.I2l && op <= opcodes.I2s:
+		conversion := conversions[op]
+		checker.pop(conversion[0])
+		checker.push(conversion[1])
+	case op >= opcodes.Lcmp && op <= opcodes.Dcmpg:
+		t := []vtype{longType, floatType, floatType, doubleType, doubleType}[op-opcodes.Lcmp]
+		checker.pop(t)
+		checker.pop(t)
+		checker.push(intType)
+	case op >= opcodes.Ifeq && op <= opcodes.Ifle:
+		checker.pop(intType)
+		targets = checker.branchTargets(instr.Branch)
+	case op >= opcodes.IfIcmpeq && op <= opcodes.IfIcmple:
+		checker.pop(intType)
+		checker.pop(intType)
+		targets = checker.branchTargets(instr.Branch)
+	case op == opcodes.IfAcmpeq || op == opcodes.IfAcmpne:
+		checker.popReference()
+		checker.popReference()
+		targets = checker.branchTargets(instr.Branch)
+	case op == opcodes.Ifnull || op == opcodes.Ifnonnull:
+		checker.popReference()
+		targets = checker.branchTargets(instr.Branch)
+	case op == opcodes.Goto || op == opcodes.GotoW:
+		targets, fallThrough = checker.branchTargets(instr.Branch), false
+	case op == opcodes.Jsr || op == opcodes.JsrW:
+		checker.requireInferring(op)
+		checker.push(returnAddressType(instr.Branch))
+		targets, fallThrough = checker.branchTargets(instr.Branch), false
+	case op == opcodes.Ret:
+		checker.requireInferring(op)
+		checker.checkLocalIndex(instr.Index, topType)
+		if t := checker.frame.locals[instr.Index]; t.tag != itemReturnAddress {
+			checker.fail("Bad local variable type: type %s (current frame, locals[%d]) is not a return address", t, instr.Index)
+		}
+		fallThrough = false // 返回到哪里见subroutines.go
+	case op == opcodes.Tableswitch || op == opcodes.Lookupswitch:
+		for i := 1; i < len(instr.Keys); i++ {
+			if instr.Keys[i-1] >= instr.Keys[i] {
+				checker.fail("Bad lookupswitch instruction")
+			}
+		}
+		checker.pop(intType)
+		targets, fallThrough = checker.branchTargets(append([]int{instr.Default}, instr.Targets...)...), false
+	case op >= opcodes.Ireturn && op <= opcodes.Return:
+		checker.returnValue(op)
+		fallThrough = false
+	case op >= opcodes.Getstatic && op <= opcodes.Putfield:
+		checker.fieldAccess(instr)
+	case op >= opcodes.Invokevirtual && op <= opcodes.Invokedynamic:
+		checker.invoke(instr)
+	case op == opcodes.New:
+		checker.newObject(instr)
+	case op == opcodes.Newarray:
+		descriptor, ok := newarrayDescriptors[instr.Value]
+		if !ok {
+			checker.fail("Illegal newarray instruction")
+		}
+		checker.pop(intType)
+		checker.push(objectType("[" + descriptor))
+	case op == opcodes.Anewarray:
+		array := "[" + elementDescriptor(checker.className(instr.Index))
+		if strings.LastIndexByte(array, '[') >= 255 {
+			checker.fail("Array with too many dimensions")
+		}
+		checker.pop(intType)
+		checker.push(objectType(array))
+	case op == opcodes.Multianewarray:
+		array := checker.className(instr.Index)
+		if instr.Value < 1 || strings.LastIndexByte(array, '[')+1 < instr.Value {
+			checker.fail("Illegal dimension in multianewarray instruction: %d", instr.Value)
+		}
+		for i := 0; i < instr.Value; i++ {
+			checker.pop(intType)
+		}
+		checker.push(objectType(array))
+	case op == opcodes.Arraylength:
+		checker.popArray(func(string) bool { return true }, "an array")
+		checker.push(intType)
+	case op == opcodes.Athrow:
+		checker.pop(objectType(throwableClass))
+		fallThrough = false
+	case op == opcodes.Checkcast:
+		class := checker.className(instr.Index)
+		checker.pop(objectType(objectClass))
+		checker.push(objectType(class))
+	case op == opcodes.Instanceof:
+		checker.className(instr.Index)
+		checker.pop(objectType(objectClass))
+		checker.push(intType)
+	case op == opcodes.Monitorenter || op == opcodes.Monitorexit:
+		checker.popReference()
+	default:
+		checker.fail("Bad instruction: %s", opcodes.Name(op))
+	}
+	return targets, fallThrough
+}
+
+// branchTargets 跳转目标必须是这个方法里一条指令的开头
+func (checker *methodChecker) branchTargets(targets ...int) []int {
+	for _, target := range targets {
+		if !checker.isInstruction(target) {
+			checker.fail("Illegal target of jump or branch: %d", target)
+		}
+	}
+	return targets
+}
+
+// requireInferring 子程序没法用StackMapTable描述，51及以上版本不能使用jsr和ret
+func (checker *methodChecker) requireInferring(op uint8) {
+	if !checker.inferring {
+		checker.fail("Illegal instruction %s in a class file of version %d", opcodes.Name(op), checker.class.ClassFile().MajorVersion())
+	}
+}
+
+func (checker *methodChecker) ldc(instr *opcodes.Instruction) {
+	info := checker.cp.GetConstantInfo(uint16(instr.Index))
+	major := checker.class.ClassFile().MajorVersion()
+	if instr.Opcode == opcodes.Ldc2W {
+		switch info.(type) {
+		case *classfile.ConstantLongInfo:
+			checker.push(longType)
+		case *classfile.ConstantDoubleInfo:
+			checker.push(doubleType)
+		default:
+			checker.fail("Invalid index in ldc2_w")
+		}
+		return
+	}
+	switch info.(type) {
+	case *classfile.ConstantIntegerInfo:
+		checker.push(intType)
+		return
+	case *classfile.ConstantFloatInfo:
+		checker.push(floatType)
+		return
+	case *classfile.ConstantStringInfo:
+		checker.push(objectType("java/lang/String"))
+		return
+	case *classfile.ConstantClassInfo:
+		if major >= 49 {
+			checker.push(objectType("java/lang/Class"))
+			return
+		}
+	case *classfile.ConstantMethodTypeInfo:
+		if major >= 51 {
+			checker.push(objectType("java/lang/invoke/MethodType"))
+			return
+		}
+	case *classfile.ConstantMethodHandleInfo:
+		if major >= 51 {
+			checker.push(objectType("java/lang/invoke/MethodHandle"))
+			return
+		}
+	}
+	checker.fail("Invalid index in %s", opcodes.Name(instr.Opcode))
+}
+
+// arrayLoad xaload：数组的元素类型必须和指令一致，baload可以用于byte和boolean数组
+func (checker *methodChecker) arrayLoad(op uint8) {
+	element := arrayElements[op-opcodes.Iaload]
+	checker.pop(intType)
+	array := checker.popArray(elementMatcher(element), "an array of "+elementName(element))
+	switch {
+	case element != "":
+		checker.push(descriptorType(element))
+	case array == nullType:
+		checker.push(nullType)
+	default:
+		checker.push(descriptorType(array.class[1:]))
+	}
+}
+
+// arrayStore xastore：aastore只检查数组和值都是引用，元素类型是否兼容在运行时检查
+func (checker *methodChecker) arrayStore(op uint8) {
+	element := arrayElements[op-opcodes.Iastore]
+	if element == "" {
+		checker.pop(objectType(objectClass))
+	} else {
+		checker.pop(descriptorType(element))
+	}
+	checker.pop(intType)
+	checker.popArray(elementMatcher(element), "an array of "+elementName(element))
+}
+
+func elementMatcher(element string) func(string) bool {
+	return func(actual string) bool {
+		switch element {
+		case "":
+			return actual[0] == 'L' || actual[0] == '['
+		case "B":
+			return actual == "B" || actual == "Z"
+		}
+		return actual == element
+	}
+}
+
+func elementName(element string) string {
+	if element == "" {
+		return "references"
+	}
+	return element
+}
+
+// astore可以存任何引用，包括未初始化的对象，还可以存jsr压入的返回地址
+func (checker *methodChecker) astore(index int) {
+	stack := checker.frame.stack
+	if len(stack) > 0 && stack[len(stack)-1].tag == itemReturnAddress {
+		checker.frame.stack = stack[:len(stack)-1]
+		checker.store(index, stack[len(stack)-1])
+		return
+	}
+	checker.store(index, checker.popReference())
+}
+
+func (checker *methodChecker) stackOp(op uint8) {
+	switch op {
+	case opcodes.Pop:
+		checker.popSlots(1)
+	case opcodes.Pop2:
+		checker.popSlots(2)
+	case opcodes.Dup:
+		v := checker.popSlots(1)
+		checker.pushSlots(v[0], v[0])
+	case opcodes.DupX1:
+		v := checker.popSlots(1, 1)
+		checker.pushSlots(v[0], v[1], v[0])
+	case opcodes.DupX2:
+		v := checker.popSlots(1, 2)
+		checker.pushSlots(v[0], v[1], v[0])
+	case opcodes.Dup2:
+		v := checker.popSlots(2)
+		checker.pushSlots(v[0], v[0])
+	case opcodes.Dup2X1:
+		v := checker.popSlots(2, 1)
+		checker.pushSlots(v[0], v[1], v[0])
+	case opcodes.Dup2X2:
+		v := checker.popSlots(2, 2)
+		checker.pushSlots(v[0], v[1], v[0])
+	case opcodes.Swap:
+		v := checker.popSlots(1, 1)
+		if v[0][0] == topType || v[1][0] == topType {
+			checker.fail("Bad type on operand stack in swap")
+		}
+		checker.pushSlots(v[0], v[1])
+	}
+}
+
+// returnValue 返回指令要和方法的返回类型一致；构造函数返回之前必须初始化this
+func (checker *methodChecker) returnValue(op uint8) {
+	if op == opcodes.Return {
+		if checker.returnType != "V" {
+			checker.fail("Method expects a return value")
+		}
+		if checker.frame.thisUninit {
+			checker.fail("Constructor must call super() or this() before return")
+		}
+		return
+	}
+	if checker.returnType == "V" {
+		checker.fail("Method does not expect a return value")
+	}
+	expected := descriptorType(checker.returnType)
+	if expected.tag != loadStoreTypes[op-opcodes.Ireturn].tag {
+		checker.fail("Bad return type: %s cannot return %s", opcodes.Name(op), expected)
+	}
+	checker.pop(expected)
+}
+
+// fieldAccess getstatic、putstatic、getfield和putfield
+func (checker *methodChecker) fieldAccess(instr *opcodes.Instruction) {
+	ref, ok := checker.cp.GetConstantInfo(uint16(instr.Index)).(*classfile.ConstantFieldrefInfo)
+	if !ok {
+		checker.fail("Illegal type at constant pool entry %d", instr.Index)
+	}
+	class := ref.ClassName()
+	name, descriptor := ref.NameAndDescriptor()
+	fieldType := descriptorType(descriptor)
+	classType := objectType(class)
+	switch instr.Opcode {
+	case opcodes.Getstatic:
+		checker.push(fieldType)
+	case opcodes.Putstatic:
+		checker.pop(fieldType)
+	case opcodes.Getfield:
+		object := checker.pop(classType)
+		checker.checkProtected(class, name, descriptor, false, object, "getfield")
+		checker.push(fieldType)
+	case opcodes.Putfield:
+		checker.pop(fieldType)
+		// 构造函数可以在调用超类的构造函数之前给本类声明的字段赋值
+		stack := checker.frame.stack
+		if len(stack) > 0 && stack[len(stack)-1] == uninitThisType &&
+			class == checker.class.Name() && checker.declaresField(name, descriptor) {
+			checker.frame.stack = stack[:len(stack)-1]
+			return
+		}
+		object := checker.pop(classType)
+		checker.checkProtected(class, name, descriptor, false, object, "putfield")
+	}
+}
+
+func (checker *methodChecker) declaresField(name, descriptor string) bool {
+	for _, field := range checker.class.Fields() {
+		if field.Name() == name && field.Descriptor() == descriptor {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+checkProtected JVMS 4.10.1.8：通过另一个运行时包里的超类访问它声明的protected实例成员时，
+对象必须是当前类或者它的子类，不能是超类或者兄弟类的对象。
+数组的clone方法是public的，虽然Object.clone是protected
+*/
+func (checker *methodChecker) checkProtected(class, name, descriptor string, isMethod bool, object vtype, what string) {
+	if !checker.isProtectedAccess(class, name, descriptor, isMethod) ||
+		checker.isAssignable(object, objectType(checker.class.Name())) {
+		return
+	}
+	if isMethod && class == objectClass && name == "clone" && object.isArray() {
+		return
+	}
+	checker.fail("Bad access to protected data in %s", what)
+}
+
+// isProtectedAccess 引用的类是当前类的超类，按名字找到的成员是protected的，并且不在当前类的运行时包里
+func (checker *methodChecker) isProtectedAccess(class, name, descriptor string, isMethod bool) bool {
+	var target *heap.Class
+	for c := checker.class.SuperClass(); c != nil; c = c.SuperClass() {
+		if c.Name() == class {
+			target = c
+			break
+		}
+	}
+	for c := target; c != nil; c = c.SuperClass() {
+		if isMethod {
+			for _, method := range c.Methods() {
+				if method.Name() == name && method.Descriptor() == descriptor {
+					return method.IsProtected() && !checker.isSamePackage(c)
+				}
+			}
+		} else {
+			for _, field := range c.Fields() {
+				if field.Name() == name && field.Descriptor() == descriptor {
+					return field.IsProtected() && !checker.isSamePackage(c)
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isSamePackage 和当前类在同一个运行时包：包名相同，由同一个加载器定义
+func (checker *methodChecker) isSamePackage(other *heap.Class) bool {
+	return other.Loader() == checker.class.Loader() && other.PackageName() == checker.class.PackageName()
+}
+
+// methodRef 方法引用的类、名字和描述符，以及是不是接口方法引用
+func (checker *methodChecker) methodRef(instr *opcodes.Instruction) (class, name, descriptor string, isInterface bool) {
+	var ref *classfile.ConstantMemberrefInfo
+	switch info := checker.cp.GetConstantInfo(uint16(instr.Index)).(type) {
+	case *classfile.ConstantMethodrefInfo:
+		if instr.Opcode != opcodes.Invokeinterface {
+			ref = &info.ConstantMemberrefInfo
+		}
+	case *classfile.ConstantInterfaceMethodrefInfo:
+		// 52版本开始invokespecial和invokestatic可以调用接口的私有方法和静态方法
+		if instr.Opcode == opcodes.Invokeinterface ||
+			instr.Opcode != opcodes.Invokevirtual && checker.class.ClassFile().MajorVersion() >= 52 {
+			ref, isInterface = &info.ConstantMemberrefInfo, true
+		}
+	}
+	if ref == nil {
+		checker.fail("Illegal type at constant pool entry %d", instr.Index)
+	}
+	name, descriptor = ref.NameAndDescriptor()
+	return ref.ClassName(), name, descriptor, isInterface
+}
+
+// invoke 依次弹出参数和接收者，压入返回值
+func (checker *methodChecker) invoke(instr *opcodes.Instruction) {
+	op := instr.Opcode
+	var class, name, descriptor string
+	var isInterface bool
+	if op == opcodes.Invokedynamic {
+		info, ok := checker.cp.GetConstantInfo(uint16(instr.Index)).(*classfile.ConstantInvokeDynamicInfo)
+		if !ok || checker.class.ClassFile().MajorVersion() < 51 {
+			checker.fail("Illegal type at constant pool entry %d", instr.Index)
+		}
+		if checker.code[instr.PC+3] != 0 || checker.code[instr.PC+4] != 0 {
+			checker.fail("Third and fourth operand bytes of invokedynamic must be zero")
+		}
+		name, descriptor = info.NameAndDescriptor()
+	} else {
+		class, name, descriptor, isInterface = checker.methodRef(instr)
+	}
+	if strings.HasPrefix(name, "<") && !(op == opcodes.Invokespecial && name == "<init>") {
+		checker.fail("Illegal call to internal method %s", name)
+	}
+	md, err := signature.ParseMethodDescriptor(descriptor)
+	if err != nil {
+		checker.fail("Illegal method descriptor %s", descriptor)
+	}
+	if op == opcodes.Invokeinterface {
+		if instr.Value != md.ArgSlotCount()+1 {
+			checker.fail("Inconsistent args count operand in invokeinterface")
+		}
+		if checker.code[instr.PC+4] != 0 {
+			checker.fail("Fourth operand byte of invokeinterface must be zero")
+		}
+	}
+	for i := len(md.Params) - 1; i >= 0; i-- {
+		checker.pop(descriptorType(md.Params[i].Signature()))
+	}
+
+	switch {
+	case op == opcodes.Invokespecial && name == "<init>":
+		checker.initialize(class, descriptor)
+	case op == opcodes.Invokespecial:
+		holder := checker.checkInvokespecialClass(class, isInterface)
+		checker.pop(objectType(holder.Name()))
+	case op == opcodes.Invokevirtual:
+		object := checker.pop(objectType(class))
+		checker.checkProtected(class, name, descriptor, true, object, "invokevirtual")
+	case op == opcodes.Invokeinterface:
+		checker.pop(objectType(class))
+	}
+	if returnType := md.Return.Signature(); returnType != "V" {
+		checker.push(descriptorType(returnType))
+	}
+}
+
+/*
+checkInvokespecialClass invokespecial调用的是当前类、超类或者直接实现的接口的方法，
+返回接收者必须属于的类。lambda这样的匿名类可以代替宿主类调用宿主类的方法
+*/
+func (checker *methodChecker) checkInvokespecialClass(class string, isInterface bool) *heap.Class {
+	current := checker.class
+	if class == current.Name() || current.SuperClass() != nil && class == current.SuperClass().Name() ||
+		isDirectInterface(current, class) {
+		return current
+	}
+	holder := current.HostClass()
+	if !checker.isJavaAssignable(holder.Name(), class) {
+		checker.fail("Bad invokespecial instruction: current class isn't assignable to reference class.")
+	}
+	if isInterface && !isDirectInterface(holder, class) {
+		checker.fail("Bad invokespecial instruction: interface method reference is in an indirect superinterface.")
+	}
+	return holder
+}
+
+func isDirectInterface(class *heap.Class, name string) bool {
+	for _, iface := range class.Interfaces() {
+		if iface.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+initialize 调用构造函数：接收者是uninitializedThis时只能调用本类或直接超类的构造函数；
+是new创建的对象时只能调用new的那个类的构造函数。调用之后栈和局部变量里所有同一个对象都变成已初始化的
+*/
+func (checker *methodChecker) initialize(class, descriptor string) {
+	receiver := checker.popReference()
+	var initialized vtype
+	switch receiver.tag {
+	case classfile.ITEM_UninitializedThis:
+		current := checker.class
+		if class != current.Name() && (current.SuperClass() == nil || class != current.SuperClass().Name()) {
+			checker.fail("Bad <init> method call")
+		}
+		initialized = objectType(current.Name())
+		checker.frame.thisUninit = false
+	case classfile.ITEM_Uninitialized:
+		newClass := checker.className(checker.instrs[checker.index[receiver.offset]].Index)
+		if newClass != class {
+			checker.fail("Call to wrong <init> method")
+		}
+		// 另一个包里的超类的protected构造函数只能通过super()调用
+		if checker.isProtectedAccess(class, "<init>", descriptor, true) {
+			checker.fail("Bad access to protected <init> method")
+		}
+		initialized = objectType(newClass)
+	default:
+		checker.fail("Bad operand type when invoking <init>: %s", receiver)
+	}
+	for _, types := range [][]vtype{checker.frame.locals, checker.frame.stack} {
+		for i, t := range types {
+			if t == receiver {
+				types[i] = initialized
+			}
+		}
+	}
+}
+
+// newObject new压入uninitialized(pc)；同一条new指令创建的还没初始化的对象不能还在栈上，局部变量里的变成Top
+func (checker *methodChecker) newObject(instr *opcodes.Instruction) {
+	if strings.HasPrefix(checker.className(instr.Index), "[") {
+		checker.fail("Illegal new instruction")
+	}
+	t := uninitializedType(instr.PC)
+	for _, s := range checker.frame.stack {
+		if s == t {
+			checker.fail("Uninitialized object created by this new instruction is still on the operand stack")
+		}
+	}
+	for i, l := range checker.frame.locals {
+		if l == t {
+			checker.frame.locals[i] = topType
+		}
+	}
+	checker.push(t)
+}
@@ -0,0 +1,86 @@
+package verifier
+
+import (
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+)
+
+/*
+readStackMaps 按JVMS 4.7.4把StackMapTable展开成每个偏移上的完整的帧。
+每一帧都相对于上一帧，第一帧相对于方法入口的帧；chop和append按局部变量的个数增减，
+long和double只算一个，展开时占两个slot
+*/
+func (checker *methodChecker) readStackMaps() {
+	checker.stackMaps = map[int]*frame{}
+	attr := checker.method.CodeAttribute().StackMapTableAttribute()
+	if attr == nil {
+		return
+	}
+	locals := append([]vtype(nil), checker.params...)
+	offset := -1
+	for _, entry := range attr.Entries() {
+		offset += int(entry.OffsetDelta()) + 1
+		if !checker.isInstruction(offset) {
+			checker.fail("StackMapTable error: bad offset %d", offset)
+		}
+		frameType := entry.FrameType()
+		switch {
+		case frameType <= classfile.SameLocals1StackItemFrameExtend || frameType == classfile.SameFrameExtended:
+		case frameType <= classfile.ChopFrameMax:
+			if entry.ChopCount() > len(locals) {
+				checker.fail("StackMapTable error: chop frame at %d removes more locals than there are", offset)
+			}
+			locals = locals[:len(locals)-entry.ChopCount()]
+		case frameType <= classfile.AppendFrameMax:
+			locals = append(locals, checker.stackMapTypes(entry.Locals(), offset)...)
+		default:
+			locals = checker.stackMapTypes(entry.Locals(), offset)
+		}
+		stack := checker.stackMapTypes(entry.Stack(), offset)
+
+		f := newFrame(checker.maxLocals)
+		n := 0
+		for _, t := range locals {
+			for _, slot := range t.slots() {
+				if n >= checker.maxLocals {
+					checker.fail("StackMapTable error: frame at %d has more locals than max_locals", offset)
+				}
+				f.locals[n] = slot
+				n++
+			}
+			if t == uninitThisType {
+				f.thisUninit = true // 局部变量里有uninitializedThis时有flagThisUninit
+			}
+		}
+		for _, t := range stack {
+			f.stack = append(f.stack, t.slots()...)
+		}
+		if len(f.stack) > checker.maxStack {
+			checker.fail("StackMapTable error: frame at %d has more stack items than max_stack", offset)
+		}
+		checker.stackMaps[offset] = f
+	}
+}
+
+// stackMapTypes 把verification_type_info转成验证类型，Uninitialized的偏移必须是一条new指令
+func (checker *methodChecker) stackMapTypes(infos []classfile.VerificationTypeInfo, offset int) []vtype {
+	types := make([]vtype, len(infos))
+	for i, info := range infos {
+		switch info.Tag() {
+		case classfile.ITEM_Object:
+			if _, ok := checker.cp.GetConstantInfo(info.CpoolIndex()).(*classfile.ConstantClassInfo); !ok {
+				checker.fail("StackMapTable error: bad class index %d in frame at %d", info.CpoolIndex(), offset)
+			}
+			types[i] = objectType(checker.cp.GetClassName(info.CpoolIndex()))
+		case classfile.ITEM_Uninitialized:
+			newPC := int(info.Offset())
+			if !checker.isInstruction(newPC) || checker.instrs[checker.index[newPC]].Opcode != opcodes.New {
+				checker.fail("StackMapTable error: bad uninitialized offset %d in frame at %d", newPC, offset)
+			}
+			types[i] = uninitializedType(newPC)
+		default:
+			types[i] = vtype{tag: info.Tag()}
+		}
+	}
+	return types
+}
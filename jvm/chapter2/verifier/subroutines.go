@@ -0,0 +1,135 @@
+package verifier
+
+import "go.buppt.cn/jvm/chapter2/opcodes"
+
+/*
+subroutine jsr调用的子程序，只在类型推导中出现。ret返回到每个调用者的下一条指令：
+子程序用到的局部变量取ret时的类型，没用到的保持调用者在jsr之前的类型，
+这样不同调用者保存在子程序外的局部变量不会在子程序的入口合并成Top
+*/
+type subroutine struct {
+	accessed []bool // 子程序里读写过的局部变量
+	callers  []int  // 调用它的jsr指令的下标
+	rets     []int  // 从它返回的ret指令的下标
+}
+
+// followSubroutine jsr记下调用者，ret合并到每个调用者的下一条指令
+func (checker *methodChecker) followSubroutine(i int) {
+	instr := checker.instrs[i]
+	switch instr.Opcode {
+	case opcodes.Jsr, opcodes.JsrW:
+		sub := checker.subroutine(instr.Branch)
+		if !containsIndex(sub.callers, i) {
+			sub.callers = append(sub.callers, i)
+			for _, ret := range sub.rets {
+				checker.enqueue(ret) // 已经执行过的ret要返回到新的调用者
+			}
+		}
+	case opcodes.Ret:
+		sub := checker.subroutine(checker.frame.locals[instr.Index].offset)
+		if !containsIndex(sub.rets, i) {
+			sub.rets = append(sub.rets, i)
+		}
+		for _, caller := range sub.callers {
+			before := checker.frames[caller]
+			after := &frame{
+				locals:     append([]vtype(nil), checker.frame.locals...),
+				stack:      checker.frame.stack,
+				thisUninit: checker.frame.thisUninit,
+			}
+			for j, accessed := range sub.accessed {
+				if !accessed {
+					after.locals[j] = before.locals[j]
+				}
+			}
+			checker.mergeNext(caller, after)
+		}
+	}
+}
+
+// subroutine 入口在entry的子程序，第一次用到时找出它读写的局部变量
+func (checker *methodChecker) subroutine(entry int) *subroutine {
+	if sub, ok := checker.subroutines[entry]; ok {
+		return sub
+	}
+	sub := &subroutine{accessed: make([]bool, checker.maxLocals)}
+	checker.subroutines[entry] = sub // 先登记，递归调用自己的子程序不会死循环
+
+	visited := make([]bool, len(checker.instrs))
+	pending := []int{checker.index[entry]}
+	for len(pending) > 0 {
+		i := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if i < 0 || i >= len(checker.instrs) || visited[i] {
+			continue
+		}
+		visited[i] = true
+		instr := checker.instrs[i]
+		checker.markAccessed(sub, instr)
+		for _, h := range checker.handlers {
+			if instr.PC >= h.start && instr.PC < h.end {
+				pending = append(pending, checker.index[h.target])
+			}
+		}
+		op := instr.Opcode
+		switch {
+		case op == opcodes.Jsr || op == opcodes.JsrW:
+			for j, accessed := range checker.subroutine(instr.Branch).accessed {
+				sub.accessed[j] = sub.accessed[j] || accessed
+			}
+			pending = append(pending, i+1)
+		case op == opcodes.Ret || op == opcodes.Athrow || op >= opcodes.Ireturn && op <= opcodes.Return:
+		case op == opcodes.Goto || op == opcodes.GotoW:
+			pending = append(pending, checker.index[instr.Branch])
+		case op == opcodes.Tableswitch || op == opcodes.Lookupswitch:
+			pending = append(pending, checker.index[instr.Default])
+			for _, target := range instr.Targets {
+				pending = append(pending, checker.index[target])
+			}
+		default:
+			if op >= opcodes.Ifeq && op <= opcodes.IfAcmpne || op == opcodes.Ifnull || op == opcodes.Ifnonnull {
+				pending = append(pending, checker.index[instr.Branch])
+			}
+			pending = append(pending, i+1)
+		}
+	}
+	return sub
+}
+
+// markAccessed 记下指令读写的局部变量，long和double占两个
+func (checker *methodChecker) markAccessed(sub *subroutine, instr *opcodes.Instruction) {
+	op := instr.Opcode
+	index, size := -1, 1
+	switch {
+	case op >= opcodes.Iload && op <= opcodes.Aload, op >= opcodes.Istore && op <= opcodes.Astore,
+		op == opcodes.Iinc, op == opcodes.Ret:
+		index = instr.Index
+		if op == opcodes.Lload || op == opcodes.Dload || op == opcodes.Lstore || op == opcodes.Dstore {
+			size = 2
+		}
+	case op >= opcodes.Iload0 && op <= opcodes.Aload3:
+		n := int(op - opcodes.Iload0)
+		index = n % 4
+		if n/4 == 1 || n/4 == 3 {
+			size = 2
+		}
+	case op >= opcodes.Istore0 && op <= opcodes.Astore3:
+		n := int(op - opcodes.Istore0)
+		index = n % 4
+		if n/4 == 1 || n/4 == 3 {
+			size = 2
+		}
+	}
+	for j := index; j >= 0 && j < index+size && j < len(sub.accessed); j++ {
+		sub.accessed[j] = true
+	}
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package verifier
+
+import (
+	"fmt"
+	"strings"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+// vtype 验证类型，见JVMS 4.10.1.2。long和double占两个slot，第二个slot是Top
+type vtype struct {
+	tag    uint8  // classfile.ITEM_*或itemReturnAddress
+	class  string // ITEM_Object的类名，数组是描述符
+	offset int    // ITEM_Uninitialized是new指令的地址，返回地址是子程序的入口
+}
+
+// itemReturnAddress jsr压入的返回地址，只在类型推导中出现
+const itemReturnAddress = classfile.ITEM_Uninitialized + 1
+
+const (
+	objectClass    = "java/lang/Object"
+	throwableClass = "java/lang/Throwable"
+)
+
+var (
+	topType        = vtype{tag: classfile.ITEM_Top}
+	intType        = vtype{tag: classfile.ITEM_Integer}
+	floatType      = vtype{tag: classfile.ITEM_Float}
+	longType       = vtype{tag: classfile.ITEM_Long}
+	doubleType     = vtype{tag: classfile.ITEM_Double}
+	nullType       = vtype{tag: classfile.ITEM_Null}
+	uninitThisType = vtype{tag: classfile.ITEM_UninitializedThis}
+)
+
+func objectType(class string) vtype {
+	return vtype{tag: classfile.ITEM_Object, class: class}
+}
+
+func uninitializedType(offset int) vtype {
+	return vtype{tag: classfile.ITEM_Uninitialized, offset: offset}
+}
+
+func returnAddressType(subroutine int) vtype {
+	return vtype{tag: itemReturnAddress, offset: subroutine}
+}
+
+func (t vtype) isWide() bool {
+	return t.tag == classfile.ITEM_Long || t.tag == classfile.ITEM_Double
+}
+
+// isReference 包括null和未初始化的对象
+func (t vtype) isReference() bool {
+	switch t.tag {
+	case classfile.ITEM_Null, classfile.ITEM_Object, classfile.ITEM_UninitializedThis, classfile.ITEM_Uninitialized:
+		return true
+	}
+	return false
+}
+
+func (t vtype) isArray() bool {
+	return t.tag == classfile.ITEM_Object && strings.HasPrefix(t.class, "[")
+}
+
+// String 和HotSpot的VerifyError一样，类名加引号
+func (t vtype) String() string {
+	switch t.tag {
+	case classfile.ITEM_Top:
+		return "top"
+	case classfile.ITEM_Integer:
+		return "integer"
+	case classfile.ITEM_Float:
+		return "float"
+	case classfile.ITEM_Long:
+		return "long"
+	case classfile.ITEM_Double:
+		return "double"
+	case classfile.ITEM_Null:
+		return "null"
+	case classfile.ITEM_UninitializedThis:
+		return "uninitializedThis"
+	case classfile.ITEM_Uninitialized:
+		return fmt.Sprintf("uninitialized(%d)", t.offset)
+	case itemReturnAddress:
+		return "returnAddress"
+	}
+	return "'" + t.class + "'"
+}
+
+// descriptorType 字段描述符对应的类型，boolean、byte、char和short在栈上都是int
+func descriptorType(descriptor string) vtype {
+	switch descriptor[0] {
+	case 'B', 'C', 'S', 'Z', 'I':
+		return intType
+	case 'F':
+		return floatType
+	case 'J':
+		return longType
+	case 'D':
+		return doubleType
+	case 'L':
+		return objectType(descriptor[1 : len(descriptor)-1])
+	}
+	return objectType(descriptor)
+}
+
+// slots 按占用的slot展开，long和double后面跟一个Top
+func (t vtype) slots() []vtype {
+	if t.isWide() {
+		return []vtype{t, topType}
+	}
+	return []vtype{t}
+}
+
+// elementDescriptor 类名或数组描述符转成数组元素的描述符
+func elementDescriptor(class string) string {
+	if strings.HasPrefix(class, "[") {
+		return class
+	}
+	return "L" + class + ";"
+}
+
+// componentClass 引用类型数组的元素的类名或描述符，基本类型的数组返回空串
+func componentClass(array string) string {
+	switch component := array[1:]; component[0] {
+	case 'L':
+		return component[1 : len(component)-1]
+	case '[':
+		return component
+	}
+	return ""
+}
+
+// loadClass 用当前类的加载器加载判断赋值兼容时用到的类，和HotSpot一样找不到时抛出NoClassDefFoundError
+func (checker *methodChecker) loadClass(name string) *heap.Class {
+	if name == checker.class.Name() {
+		return checker.class
+	}
+	return checker.class.Loader().LoadClass(checker.thread, name)
+}
+
+/*
+isAssignable from类型的值能否用在需要to类型的地方，见JVMS 4.10.1.2的子类型关系。
+基本类型只和自己兼容；null可以赋给任何类和数组；未初始化的对象只和自己兼容
+*/
+func (checker *methodChecker) isAssignable(from, to vtype) bool {
+	switch {
+	case from == to, to.tag == classfile.ITEM_Top:
+		return true
+	case to.tag != classfile.ITEM_Object:
+		return false
+	case from.tag == classfile.ITEM_Null:
+		return true
+	case from.tag == classfile.ITEM_Object:
+		return checker.isJavaAssignable(from.class, to.class)
+	}
+	return false
+}
+
+/*
+isJavaAssignable 类或数组from能否赋给to，和HotSpot的VerificationType::is_reference_assignable_from一样：
+任何类型都能赋给Object；接口当作Object，因为值可能来自任何实现了它的类；
+其他类要求from是to的子类；数组要求元素类型兼容，基本类型的数组只和自己兼容
+*/
+func (checker *methodChecker) isJavaAssignable(from, to string) bool {
+	if from == to || to == objectClass {
+		return true
+	}
+	if strings.HasPrefix(to, "[") {
+		if !strings.HasPrefix(from, "[") {
+			return false
+		}
+		fromComponent, toComponent := componentClass(from), componentClass(to)
+		if fromComponent == "" || toComponent == "" {
+			return false // 元素是相同基本类型的在前面已经比较过了
+		}
+		return checker.isJavaAssignable(fromComponent, toComponent)
+	}
+	toClass := checker.loadClass(to)
+	if toClass.IsInterface() {
+		return true
+	}
+	if strings.HasPrefix(from, "[") {
+		return false
+	}
+	return checker.loadClass(from).IsSubClassOf(toClass)
+}
+
+// mergeTypes 类型推导时两条路径在同一条指令汇合，求两个类型的最小公共超类型，不兼容时是Top
+func (checker *methodChecker) mergeTypes(t1, t2 vtype) vtype {
+	switch {
+	case t1 == t2:
+		return t1
+	case t1.tag == classfile.ITEM_Null && t2.tag == classfile.ITEM_Object:
+		return t2
+	case t2.tag == classfile.ITEM_Null && t1.tag == classfile.ITEM_Object:
+		return t1
+	case t1.tag == classfile.ITEM_Object && t2.tag == classfile.ITEM_Object:
+		return objectType(checker.commonSuperClass(t1.class, t2.class))
+	}
+	return topType
+}
+
+// commonSuperClass 两个类最近的公共超类，有一个是接口时是Object；
+// 引用类型的数组按元素类型合并，其他数组只能合并成Object
+func (checker *methodChecker) commonSuperClass(class1, class2 string) string {
+	array1, array2 := strings.HasPrefix(class1, "["), strings.HasPrefix(class2, "[")
+	if array1 || array2 {
+		if !array1 || !array2 {
+			return objectClass
+		}
+		component1, component2 := componentClass(class1), componentClass(class2)
+		if component1 == "" || component2 == "" {
+			return objectClass
+		}
+		return "[" + elementDescriptor(checker.commonSuperClass(component1, component2))
+	}
+	c1, c2 := checker.loadClass(class1), checker.loadClass(class2)
+	if c1.IsInterface() || c2.IsInterface() {
+		return objectClass
+	}
+	for c := c1; c != nil; c = c.SuperClass() {
+		if c == c2 || c2.IsSubClassOf(c) {
+			return c.Name()
+		}
+	}
+	return objectClass
+}
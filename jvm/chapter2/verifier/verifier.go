@@ -0,0 +1,309 @@
+/*
+Package verifier 在链接时校验方法的字节码，保证解释器不用在运行时检查操作数的类型：
+每条指令的操作数栈和局部变量类型正确、栈不溢出、跳转目标是指令的开头、
+构造函数在返回之前初始化了this、对象初始化之后才使用、访问protected成员的对象是当前类或者它的子类。
+
+51及以上版本的class文件按StackMapTable做类型检查（JVMS 4.10.1），每条指令只看一遍；
+更早的版本没有StackMapTable，用数据流分析推导每条指令入口的类型（JVMS 4.10.2）。
+和HotSpot一样，50版本类型检查失败时改用类型推导
+*/
+package verifier
+
+import (
+	"fmt"
+
+	"go.buppt.cn/jvm/chapter2/classfile"
+	"go.buppt.cn/jvm/chapter2/opcodes"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+	"go.buppt.cn/jvm/chapter2/signature"
+)
+
+// 从这个版本开始有StackMapTable
+const typeCheckingVersion = 50
+
+// VerifyError 校验没通过的方法、字节码偏移和原因
+type VerifyError struct {
+	Class  string // 类名，Java形式
+	Method string // 方法名加描述符，例如main([Ljava/lang/String;)V
+	Offset int    // 出错指令的字节码偏移，和具体指令无关的错误是-1
+	Reason string
+}
+
+func (verifyError *VerifyError) Error() string {
+	if verifyError.Offset < 0 {
+		return fmt.Sprintf("(class: %s, method: %s) %s", verifyError.Class, verifyError.Method, verifyError.Reason)
+	}
+	return fmt.Sprintf("(class: %s, method: %s, offset: %d) %s",
+		verifyError.Class, verifyError.Method, verifyError.Offset, verifyError.Reason)
+}
+
+/*
+Verify 校验类里每个有字节码的方法，返回第一个错误，类型是*VerifyError。
+判断类型能否赋值时在thread上用类的加载器加载用到的类，找不到时和HotSpot一样抛出NoClassDefFoundError
+*/
+func Verify(thread heap.Thread, class *heap.Class) error {
+	major := class.ClassFile().MajorVersion()
+	for _, method := range class.ClassFile().Methods() {
+		if method.CodeAttribute() == nil {
+			continue // 抽象方法和本地方法
+		}
+		inferring := major < typeCheckingVersion
+		err := newMethodChecker(thread, class, method, inferring).run()
+		if err != nil && major == typeCheckingVersion {
+			err = newMethodChecker(thread, class, method, true).run()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handler 异常表的一项
+type handler struct {
+	start, end, target int
+	catchType          vtype // 没有catch_type时是Throwable
+}
+
+// methodChecker 校验一个方法
+type methodChecker struct {
+	thread     heap.Thread // 执行链接的线程，加载类时用
+	class      *heap.Class
+	cp         classfile.ConstantPool
+	method     *classfile.MemberInfo
+	code       []byte
+	maxStack   int
+	maxLocals  int
+	instrs     []*opcodes.Instruction
+	index      []int // 字节码偏移到指令下标，不是指令开头的偏移是-1
+	handlers   []*handler
+	inferring  bool    // 类型推导，否则是按StackMapTable做类型检查
+	params     []vtype // this和参数的类型，long和double只占一项
+	returnType string  // 返回值的描述符
+	pc         int     // 正在校验的指令的偏移，和指令无关时是-1
+	frame      *frame  // 正在校验的指令执行过程中的帧
+
+	stackMaps map[int]*frame // 类型检查：StackMapTable里的帧，键是偏移
+
+	frames      []*frame            // 类型推导：每条指令入口的帧，还没执行到的是nil
+	worklist    []int               // 入口帧有变化、要重新执行的指令下标
+	queued      []bool              // 指令是否已经在worklist里
+	subroutines map[int]*subroutine // 子程序，键是入口的偏移
+}
+
+func newMethodChecker(thread heap.Thread, class *heap.Class, method *classfile.MemberInfo, inferring bool) *methodChecker {
+	code := method.CodeAttribute()
+	return &methodChecker{
+		thread:    thread,
+		class:     class,
+		cp:        *class.ClassFile().ConstantPool(),
+		method:    method,
+		code:      code.Code(),
+		maxStack:  int(code.MaxStack()),
+		maxLocals: int(code.MaxLocals()),
+		inferring: inferring,
+		pc:        -1,
+	}
+}
+
+func (checker *methodChecker) fail(format string, args ...interface{}) {
+	panic(&VerifyError{
+		Class:  checker.class.JavaName(),
+		Method: checker.method.Name() + checker.method.Descriptor(),
+		Offset: checker.pc,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (checker *methodChecker) run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			verifyError, ok := r.(*VerifyError)
+			if !ok {
+				panic(r) // 加载类时的错误原样抛出
+			}
+			err = verifyError
+		}
+	}()
+	checker.decode()
+	initial := checker.initialFrame()
+	checker.readHandlers()
+	if checker.inferring {
+		checker.infer(initial)
+	} else {
+		checker.typeCheck(initial)
+	}
+	return nil
+}
+
+// decode 解码全部指令，记下每条指令开头的偏移
+func (checker *methodChecker) decode() {
+	instrs, err := opcodes.DecodeAll(checker.code)
+	if err != nil {
+		checker.fail("Bad instruction: %v", err)
+	}
+	checker.instrs = instrs
+	checker.index = make([]int, len(checker.code))
+	for i := range checker.index {
+		checker.index[i] = -1
+	}
+	for i, instr := range instrs {
+		checker.index[instr.PC] = i
+	}
+}
+
+// isInstruction 偏移是不是一条指令的开头
+func (checker *methodChecker) isInstruction(pc int) bool {
+	return pc >= 0 && pc < len(checker.code) && checker.index[pc] >= 0
+}
+
+// initialFrame 方法入口的帧：局部变量是this和参数，构造函数的this还没有初始化
+func (checker *methodChecker) initialFrame() *frame {
+	method := checker.method
+	if method.AccessFlags()&classfile.ACC_STATIC == 0 {
+		if method.Name() == "<init>" && checker.class.Name() != objectClass {
+			checker.params = append(checker.params, uninitThisType)
+		} else {
+			checker.params = append(checker.params, objectType(checker.class.Name()))
+		}
+	}
+	md, err := signature.ParseMethodDescriptor(method.Descriptor())
+	if err != nil {
+		checker.fail("Illegal method descriptor %s", method.Descriptor())
+	}
+	for _, param := range md.Params {
+		checker.params = append(checker.params, descriptorType(param.Signature()))
+	}
+	checker.returnType = md.Return.Signature()
+
+	f := newFrame(checker.maxLocals)
+	n := 0
+	for _, t := range checker.params {
+		for _, slot := range t.slots() {
+			if n >= checker.maxLocals {
+				checker.fail("Arguments can't fit into locals")
+			}
+			f.locals[n] = slot
+			n++
+		}
+		if t == uninitThisType {
+			f.thisUninit = true
+		}
+	}
+	return f
+}
+
+// readHandlers 检查异常表：范围和处理代码都在指令的开头，捕获的类型是Throwable的子类
+func (checker *methodChecker) readHandlers() {
+	for i, entry := range checker.method.CodeAttribute().ExceptionTable() {
+		h := &handler{start: int(entry.StartPc()), end: int(entry.EndPc()), target: int(entry.HandlerPc())}
+		if !checker.isInstruction(h.start) || h.end <= h.start ||
+			h.end != len(checker.code) && !checker.isInstruction(h.end) {
+			checker.fail("Illegal exception table range in exception handler %d", i)
+		}
+		if !checker.isInstruction(h.target) {
+			checker.fail("Illegal exception table handler in exception handler %d", i)
+		}
+		h.catchType = objectType(throwableClass)
+		if entry.CatchType() != 0 {
+			name := checker.className(int(entry.CatchType()))
+			if !checker.isJavaAssignable(name, throwableClass) {
+				checker.fail("Catch type is not a subclass of Throwable in exception handler %d", i)
+			}
+			h.catchType = objectType(name)
+		}
+		checker.handlers = append(checker.handlers, h)
+	}
+}
+
+// className 常量池里的Class常量的类名
+func (checker *methodChecker) className(index int) string {
+	if _, ok := checker.cp.GetConstantInfo(uint16(index)).(*classfile.ConstantClassInfo); !ok {
+		checker.fail("Illegal type at constant pool entry %d", index)
+	}
+	return checker.cp.GetClassName(uint16(index))
+}
+
+/*
+typeCheck 按JVMS 4.10.1从头到尾检查每条指令一遍：有StackMapTable帧的指令，到达它的帧必须能赋给
+StackMapTable里的帧，然后从这一帧开始执行；跳转目标和异常处理代码都必须有帧。
+无条件跳转、返回和athrow之后的指令不能从上一条指令到达，必须有帧
+*/
+func (checker *methodChecker) typeCheck(initial *frame) {
+	checker.readStackMaps()
+	current := initial
+	for _, instr := range checker.instrs {
+		checker.pc = instr.PC
+		if stackMap, ok := checker.stackMaps[instr.PC]; ok {
+			if current != nil {
+				checker.checkFrameAssignable(current, stackMap, "Instruction type does not match stack map")
+			}
+			current = stackMap.copy()
+		} else if current == nil {
+			checker.fail("Expecting a stack map frame")
+		}
+		checker.frame = current
+		checker.checkHandlers(current)
+		targets, fallThrough := checker.execute(instr)
+		if isStore(instr.Opcode) {
+			checker.checkHandlers(current)
+		}
+		for _, target := range targets {
+			stackMap, ok := checker.stackMaps[target]
+			if !ok {
+				checker.fail("Expecting a stackmap frame at branch target %d", target)
+			}
+			checker.checkFrameAssignable(current, stackMap, fmt.Sprintf("Inconsistent stackmap frames at branch target %d", target))
+		}
+		if !fallThrough {
+			current = nil
+		}
+	}
+	if current != nil {
+		checker.fail("Falling off the end of the code")
+	}
+}
+
+// checkHandlers 覆盖当前指令的异常处理代码的帧：局部变量和当前一样，栈上只有异常对象
+func (checker *methodChecker) checkHandlers(f *frame) {
+	for _, h := range checker.handlers {
+		if checker.pc < h.start || checker.pc >= h.end {
+			continue
+		}
+		stackMap, ok := checker.stackMaps[h.target]
+		if !ok {
+			checker.fail("Expecting a stackmap frame at branch target %d", h.target)
+		}
+		exceptionFrame := &frame{locals: f.locals, stack: []vtype{h.catchType}, thisUninit: f.thisUninit}
+		checker.checkFrameAssignable(exceptionFrame, stackMap,
+			fmt.Sprintf("Stack map does not match the one at exception handler %d", h.target))
+	}
+}
+
+// checkFrameAssignable 检查from的每个局部变量和栈上的值都能赋给to的，
+// to的构造函数里this还没有初始化时from也可以没有
+func (checker *methodChecker) checkFrameAssignable(from, to *frame, what string) {
+	if len(from.stack) != len(to.stack) {
+		checker.fail("%s: current frame's stack size %d doesn't match stackmap %d", what, len(from.stack), len(to.stack))
+	}
+	for i, t := range from.locals {
+		if !checker.isAssignable(t, to.locals[i]) {
+			checker.fail("%s: type %s (current frame, locals[%d]) is not assignable to %s (stack map, locals[%d])",
+				what, t, i, to.locals[i], i)
+		}
+	}
+	for i, t := range from.stack {
+		if !checker.isAssignable(t, to.stack[i]) {
+			checker.fail("%s: type %s (current frame, stack[%d]) is not assignable to %s (stack map, stack[%d])",
+				what, t, i, to.stack[i], i)
+		}
+	}
+	if from.thisUninit && !to.thisUninit {
+		checker.fail("%s: current frame's flags are not assignable to stack map frame's", what)
+	}
+}
+
+// isStore 改变局部变量类型的指令。异常处理代码也可能在这些指令执行之后到达
+func isStore(opcode uint8) bool {
+	return opcode >= opcodes.Istore && opcode <= opcodes.Astore3
+}
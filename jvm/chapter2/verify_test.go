@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.buppt.cn/jvm/chapter2/asm"
+	"go.buppt.cn/jvm/chapter2/classpath"
+	"go.buppt.cn/jvm/chapter2/rtda"
+	"go.buppt.cn/jvm/chapter2/rtda/heap"
+)
+
+/*
+testdata/verify下是52版本的类，汇编时计算StackMapTable，校验时做类型检查；old下是没有StackMapTable的
+老版本的类，校验时做类型推导；bad下的每个类都有一处不能通过校验的字节码。类库用testdata/jmm/lib，
+它是45版本的，-Xverify:all时也做类型推导
+*/
+func TestVerifier(t *testing.T) {
+	dir := t.TempDir()
+	jreDir, goodDir, badDir := verifyTestdata(t, dir)
+	cp := classpath.Parse(jreDir, goodDir+string(os.PathListSeparator)+badDir)
+
+	t.Run("Good", func(t *testing.T) {
+		loader := heap.NewClassLoaders(cp, verifyClass, verifyClass)
+		if exitCode := interpret(loader, "Good", nil, rtda.DefaultMaxStackDepth); exitCode != 0 {
+			t.Fatalf("exit code %d", exitCode)
+		}
+	})
+	t.Run("Finally", func(t *testing.T) {
+		if err := linkError(heap.NewClassLoaders(cp, nil, verifyClass), "Finally"); err != "" {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		class string
+		want  string
+	}{
+		{"BadOperand", "method: run()V, offset: 2) Bad type on operand stack: type float"},
+		{"BadLocal", "method: run()V, offset: 0) Bad local variable type: type top"},
+		{"BadReturn", "method: run()I, offset: 0) Method expects a return value"},
+		{"BadConstructor", "method: <init>()V, offset: 0) Constructor must call super() or this() before return"},
+		{"BadUninit", "method: run()V, offset: 3) Bad type on operand stack: type uninitialized(0)"},
+		{"BadWrongInit", "method: run()V, offset: 3) Call to wrong <init> method"},
+		{"BadBranch", "method: run()V, offset: 1) Expecting a stackmap frame at branch target 4"},
+		{"BadStackHeight", "method: run()V, offset: 4) Inconsistent stack height 0 != 1 at 5"},
+		{"BadInvokespecial", "method: run(Ljava/lang/String;)I, offset: 1) Bad invokespecial instruction"},
+		{"BadMaxStack", "method: run()V, offset: 1) Exceeded max stack size"},
+		{"BadJsr", "method: run()V, offset: 0) Illegal instruction jsr"},
+		{"BadFallOff", "method: run()V, offset: 1) Falling off the end of the code"},
+		{"r/Peeker", "method: peek(Lp/Base;)I, offset: 1) Bad access to protected data in getfield"},
+	}
+	for _, test := range tests {
+		t.Run(test.class, func(t *testing.T) {
+			err := linkError(heap.NewClassLoaders(cp, nil, verifyClass), test.class)
+			if !strings.HasPrefix(err, "java.lang.VerifyError: (class: "+strings.Replace(test.class, "/", ".", -1)+", ") ||
+				!strings.Contains(err, test.want) {
+				t.Errorf("got %q, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+// TestVerifyOption -Xverify:none不校验，remote只校验启动类加载器以外的加载器的类，all校验全部
+func TestVerifyOption(t *testing.T) {
+	dir := t.TempDir()
+	jreDir, _, badDir := verifyTestdata(t, dir)
+	appCp := classpath.Parse(jreDir, badDir)
+	// 另一个jre把bad下的类也放到启动类路径上
+	bootJreDir := testJRE(t, filepath.Join(dir, "boot"))
+	assembleJar(t, filepath.Join("testdata", "verify", "bad"), filepath.Join(bootJreDir, "lib", "bad.jar"), asm.Options{})
+	bootCp := classpath.Parse(bootJreDir, t.TempDir())
+
+	tests := []struct {
+		option             string
+		verifyBoot, verify bool
+	}{
+		{"none", false, false},
+		{"remote", false, true},
+		{"", false, true},
+		{"all", true, true},
+		{"format", false, false},
+	}
+	for _, test := range tests {
+		bootVerifier, verifier, ok := classVerifiers(test.option)
+		if !ok {
+			t.Fatalf("-Xverify:%s is not accepted", test.option)
+		}
+		for _, c := range []struct {
+			cp       *classpath.Classpath
+			verified bool
+		}{{bootCp, test.verifyBoot}, {appCp, test.verify}} {
+			err := linkError(heap.NewClassLoaders(c.cp, bootVerifier, verifier), "BadOperand")
+			if verified := strings.HasPrefix(err, "java.lang.VerifyError"); verified != c.verified {
+				t.Errorf("-Xverify:%s: got %q, verified = %v", test.option, err, c.verified)
+			}
+		}
+	}
+	if _, _, ok := classVerifiers("some"); ok {
+		t.Errorf("-Xverify:some is accepted")
+	}
+}
+
+// verifyTestdata 汇编类库和testdata/verify下的类，返回jre目录和两个类路径目录
+func verifyTestdata(t *testing.T, dir string) (jreDir, goodDir, badDir string) {
+	t.Helper()
+	jreDir = testJRE(t, dir)
+	goodDir = filepath.Join(dir, "good")
+	badDir = filepath.Join(dir, "bad")
+	assembleDir(t, filepath.Join("testdata", "verify"), goodDir, asm.Options{ComputeFrames: true})
+	assembleDir(t, filepath.Join("testdata", "verify", "old"), goodDir, asm.Options{})
+	assembleDir(t, filepath.Join("testdata", "verify", "bad"), badDir, asm.Options{})
+	return jreDir, goodDir, badDir
+}
+
+// linkError 加载并链接类，返回抛出的错误，没有错误时返回空串
+func linkError(loader *heap.ClassLoader, className string) (err string) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Sprint(r)
+		}
+	}()
+	loader.LoadClass(rtda.NewThread(rtda.DefaultMaxStackDepth), className)
+	return ""
+}